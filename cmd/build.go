@@ -70,6 +70,7 @@ EXAMPLES
 		SuggestFor: []string{"biuld", "buidl", "built"},
 		PreRunE: bindEnv("image", "path", "builder", "registry", "confirm",
 			"push", "builder-image", "platform", "verbose", "build-timestamp",
+			"build-timeout",
 			"registry-insecure", "username", "password", "token"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBuild(cmd, args, newClient)
@@ -77,7 +78,7 @@ EXAMPLES
 	}
 
 	// Global Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -103,6 +104,10 @@ EXAMPLES
 	cmd.Flags().StringP("registry", "r", cfg.Registry,
 		"Container registry + registry namespace. (ex 'ghcr.io/myuser').  The full image name is automatically determined using this along with function name. ($FUNC_REGISTRY)")
 	cmd.Flags().Bool("registry-insecure", cfg.RegistryInsecure, "Skip TLS certificate verification when communicating in HTTPS with the registry ($FUNC_REGISTRY_INSECURE)")
+	cmd.Flags().StringP("platform", "", "",
+		"Optionally specify a target platform, for example \"linux/amd64\" when using the s2i build strategy.  Defaults to the platform of global config when using s2i ($FUNC_PLATFORM)")
+	cmd.Flags().Duration("build-timeout", cfg.BuildTimeout,
+		"Maximum duration of the build, for example \"10m\".  Zero indicates no timeout. ($FUNC_BUILD_TIMEOUT)")
 
 	// Function-Context Flags:
 	// Options whose value is available on the function with context only
@@ -118,8 +123,6 @@ EXAMPLES
 	// globally configurable nor persisted with the function)
 	cmd.Flags().BoolP("push", "u", false,
 		"Attempt to push the function image to the configured registry after being successfully built")
-	cmd.Flags().StringP("platform", "", "",
-		"Optionally specify a target platform, for example \"linux/amd64\" when using the s2i build strategy")
 	cmd.Flags().StringP("username", "", "",
 		"Username to use when pushing to the registry.")
 	cmd.Flags().StringP("password", "", "",
@@ -188,7 +191,9 @@ func runBuild(cmd *cobra.Command, _ []string, newClient ClientFactory) (err erro
 	if err != nil {
 		return
 	}
-	if f, err = client.Build(cmd.Context(), f, buildOptions...); err != nil {
+	ctx, cancel := cfg.buildContext(cmd.Context())
+	defer cancel()
+	if f, err = client.Build(ctx, f, buildOptions...); err != nil {
 		return
 	}
 	if cfg.Push {
@@ -214,6 +219,15 @@ func (c buildConfig) WithValues(ctx context.Context) context.Context {
 	return ctx
 }
 
+// buildContext returns the context within which to build, bounded by the
+// build timeout when one is configured.
+func (c buildConfig) buildContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.BuildTimeout > 0 {
+		return context.WithTimeout(ctx, c.BuildTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
 type buildConfig struct {
 	// Globals (builder, confirm, registry, verbose)
 	config.Global
@@ -261,11 +275,12 @@ func newBuildConfig() buildConfig {
 			Registry:         registry(), // deferred defaulting
 			Verbose:          viper.GetBool("verbose"),
 			RegistryInsecure: viper.GetBool("registry-insecure"),
+			BuildTimeout:     viper.GetDuration("build-timeout"),
 		},
 		BuilderImage:  viper.GetString("builder-image"),
 		Image:         viper.GetString("image"),
 		Path:          viper.GetString("path"),
-		Platform:      viper.GetString("platform"),
+		Platform:      buildPlatform(viper.GetString("builder")),
 		Push:          viper.GetBool("push"),
 		Username:      viper.GetString("username"),
 		Password:      viper.GetString("password"),
@@ -274,6 +289,20 @@ func newBuildConfig() buildConfig {
 	}
 }
 
+// buildPlatform returns the platform to build for: that requested with
+// --platform, or else the default of global config if the builder supports
+// building for a specific platform.
+func buildPlatform(builder string) string {
+	if p := viper.GetString("platform"); p != "" {
+		return p
+	}
+	if builder != builders.S2I {
+		return ""
+	}
+	cfg, _ := newGlobalConfig()
+	return cfg.Platform
+}
+
 // Configure the given function.  Updates a function struct with all
 // configurable values.  Note that buildConfig already includes function's
 // current values, as they were passed through via flag defaults, so overwriting
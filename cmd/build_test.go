@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/mock"
 	. "knative.dev/func/pkg/testing"
@@ -27,6 +29,35 @@ func TestBuild_ConfigApplied(t *testing.T) {
 	testConfigApplied(NewBuildCmd, t)
 }
 
+// TestBuild_GlobalPlatform ensures the platform of global config is used by
+// the builders which support one, and does not fail the builds of others.
+func TestBuild_GlobalPlatform(t *testing.T) {
+	root := FromTempDirectory(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.CreatePaths(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (config.Global{Platform: "linux/arm64"}).Write(config.File()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewBuildCmd(NewTestClient())
+	cmd.SetArgs([]string{"--builder", "pack"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p := buildPlatform(builders.S2I); p != "linux/arm64" {
+		t.Fatalf("expected the platform of global config for s2i, got %q", p)
+	}
+	if p := buildPlatform(builders.Pack); p != "" {
+		t.Fatalf("expected no platform for pack, got %q", p)
+	}
+}
+
 // TestBuild_ConfigPrecedence ensures that the correct precidence for config
 // are applied: static < global < function context < envs < flags
 func TestBuild_ConfigPrecedence(t *testing.T) {
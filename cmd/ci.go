@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/pipelines/tekton"
+)
+
+func NewCICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Manage the CI workflows of a function",
+		Long: `Manage the CI workflows of a function
+
+Generates the workflows of CI systems, such as GitHub Actions or GitLab CI,
+which build, push and deploy the function in the current directory or from the
+directory specified with --path on each push to its Git repository.
+`,
+	}
+
+	cmd.AddCommand(NewCIGenerateCmd())
+
+	return cmd
+}
+
+func NewCIGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a CI workflow which deploys the function",
+		Long: `Generate a CI workflow which deploys the function
+
+Writes the workflow of the given CI provider into the Git repository of the
+function.  The workflow installs {{rootCmdUse}} and runs a deploy which builds
+the function with the selected builder, pushes its image and deploys it.  It
+runs on pushes to the branch of 'build.git.revision' ("main" by default) which
+change the function, located in the repository as 'build.git.contextDir' or
+else the function's directory.
+
+Supported providers:
+  github   .github/workflows/<function>.yaml
+  gitlab   .gitlab/ci/<function>.yml, included from .gitlab-ci.yml
+  tekton   the Pipelines as Code resources in .tekton
+
+The credentials are taken from secrets of the CI system: KUBECONFIG for the
+cluster, REGISTRY_USERNAME and REGISTRY_PASSWORD for the container registry.
+`,
+		Example: `
+# Generate a GitHub Actions workflow for the function
+{{rootCmdUse}} ci generate
+
+# Generate a GitLab CI job building the function with s2i
+{{rootCmdUse}} ci generate --provider gitlab --builder s2i
+`,
+		Args:    cobra.NoArgs,
+		PreRunE: bindEnv("provider", "builder", "force", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCIGenerate(cmd)
+		},
+	}
+
+	cmd.Flags().String("provider", tekton.CIProviderGitHub,
+		fmt.Sprintf("CI provider of the workflow. Supported providers are %s. ($FUNC_PROVIDER)", strings.Join(tekton.CIProviders(), ", ")))
+	cmd.Flags().StringP("builder", "b", "",
+		fmt.Sprintf("Builder the workflow builds the function with. Default is that of the function. Supported builders are %s. ($FUNC_BUILDER)", KnownBuilders()))
+	cmd.Flags().BoolP("force", "f", false, "Overwrite an existing workflow ($FUNC_FORCE)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, false)
+
+	if err := cmd.RegisterFlagCompletionFunc("builder", CompleteBuilderList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runCIGenerate(cmd *cobra.Command) (err error) {
+	f, err := fn.NewFunction(viper.GetString("path"))
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+
+	if b := viper.GetString("builder"); b != "" {
+		f.Build.Builder = b
+	}
+	if f.Build.Builder == "" {
+		f.Build.Builder = builders.Default
+	}
+
+	out := cmd.OutOrStdout()
+	paths, err := tekton.GenerateCI(f, viper.GetString("provider"), viper.GetBool("force"))
+	var errInclude tekton.ErrCIIncludeRequired
+	if errors.As(err, &errInclude) {
+		// the job is written, but left to the user to include
+		defer fmt.Fprintf(out, "Include the job from the pipeline by adding to the include of %v:\n  - local: %v\n", errInclude.Pipeline, errInclude.Job)
+		err = nil
+	}
+	if err != nil {
+		var errExists tekton.ErrCIFileExists
+		if errors.As(err, &errExists) {
+			return fmt.Errorf("%w. Use --force to overwrite it", err)
+		}
+		return
+	}
+
+	for _, p := range paths {
+		fmt.Fprintf(out, "Wrote %v\n", p)
+	}
+	if viper.GetString("provider") != tekton.CIProviderTekton {
+		fmt.Fprintln(out, "The workflow needs the KUBECONFIG, REGISTRY_USERNAME and REGISTRY_PASSWORD secrets of the CI system")
+	}
+	return
+}
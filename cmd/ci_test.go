@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestCIGenerate ensures the workflow of the requested provider is written,
+// building with the requested builder, and is not overwritten without --force.
+func TestCIGenerate(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generate := func(args ...string) error {
+		cmd := NewCICmd()
+		cmd.SetArgs(append([]string{"generate"}, args...))
+		cmd.SetOut(io.Discard)
+		return cmd.Execute()
+	}
+
+	if err = generate("--provider", "github", "--builder", "s2i"); err != nil {
+		t.Fatal(err)
+	}
+	workflow, err := os.ReadFile(filepath.Join(root, ".github", "workflows", f.Name+".yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(workflow), "--builder=s2i --registry="+TestRegistry) {
+		t.Fatalf("expected the workflow to deploy with s2i:\n%s", workflow)
+	}
+
+	if err = generate("--provider", "github"); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected an error suggesting --force, got %v", err)
+	}
+	if err = generate("--provider", "github", "--force"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = generate("--provider", "tekton"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(root, ".tekton", "pipeline-run-pac.yaml")); err != nil {
+		t.Fatalf("expected the Pipelines as Code resources: %v", err)
+	}
+	if err = generate("--provider", "tekton", "--force"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = generate("--provider", "jenkins"); err == nil {
+		t.Fatal("expected an error for an unsupported provider")
+	}
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -8,13 +10,18 @@ import (
 	"knative.dev/func/cmd/prompt"
 	"knative.dev/func/pkg/builders/buildpacks"
 	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/deployers"
+	"knative.dev/func/pkg/dns"
 	"knative.dev/func/pkg/docker"
 	"knative.dev/func/pkg/docker/creds"
 	fn "knative.dev/func/pkg/functions"
 	fnhttp "knative.dev/func/pkg/http"
 	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/knative"
+	"knative.dev/func/pkg/pipelines"
+	"knative.dev/func/pkg/pipelines/job"
 	"knative.dev/func/pkg/pipelines/tekton"
+	"knative.dev/func/pkg/preflight"
 )
 
 // ClientConfig settings for use with NewClient
@@ -27,6 +34,14 @@ type ClientConfig struct {
 
 	// Allow insecure server connections when using SSL
 	InsecureSkipVerify bool
+
+	// Quiet progress output.  The logs of remote pipelines are not streamed,
+	// only the name of each task as it starts.
+	Quiet bool
+
+	// RemoteBuilder which runs remote builds: Tekton pipelines by default,
+	// or a plain Kubernetes Job on clusters without Tekton.
+	RemoteBuilder string
 }
 
 // ClientFactory defines a constructor which assists in the creation of a Client
@@ -58,17 +73,18 @@ func NewClient(cfg ClientConfig, options ...fn.Option) (*fn.Client, func()) {
 		t  = newTransport(cfg.InsecureSkipVerify)    // may provide a custom impl which proxies
 		c  = newCredentialsProvider(config.Dir(), t) // for accessing registries
 		d  = newKnativeDeployer(cfg.Verbose)
-		pp = newTektonPipelinesProvider(c, cfg.Verbose)
+		pp = newPipelinesProvider(cfg.RemoteBuilder, c, cfg.Verbose, cfg.Quiet, cfg.InsecureSkipVerify)
 		o  = []fn.Option{ // standard (shared) options for all commands
 			fn.WithVerbose(cfg.Verbose),
 			fn.WithTransport(t),
 			fn.WithRepositoriesPath(config.RepositoriesPath()),
 			fn.WithBuilder(buildpacks.NewBuilder(buildpacks.WithVerbose(cfg.Verbose))),
-			fn.WithRemover(knative.NewRemover(cfg.Verbose)),
-			fn.WithDescriber(knative.NewDescriber(cfg.Verbose)),
-			fn.WithLister(knative.NewLister(cfg.Verbose)),
+			fn.WithRemover(newRemover(cfg.Verbose)),
+			fn.WithDescriber(newDescriber(cfg.Verbose)),
+			fn.WithLister(newLister(cfg.Verbose)),
 			fn.WithDeployer(d),
 			fn.WithPipelinesProvider(pp),
+			fn.WithPreflighter(newPreflighter(t)),
 			fn.WithPusher(docker.NewPusher(
 				docker.WithCredentialsProvider(c),
 				docker.WithTransport(t),
@@ -76,6 +92,14 @@ func NewClient(cfg ClientConfig, options ...fn.Option) (*fn.Client, func()) {
 		}
 	)
 
+	if p, err := newDNSProvider(); err != nil {
+		// Deploy rejects an invalid provider during validation, so this is
+		// reached only by commands which do not publish domains.
+		fmt.Fprintf(os.Stderr, "Warning: DNS records will not be updated. %v\n", err)
+	} else if p != nil {
+		o = append(o, fn.WithDNSProvider(p))
+	}
+
 	// Client is constructed with standard options plus any additional options
 	// which either augment or override the defaults.
 	client := fn.New(append(o, options...)...)
@@ -105,45 +129,198 @@ func newCredentialsProvider(configPath string, t http.RoundTripper) docker.Crede
 		creds.WithPromptForCredentials(prompt.NewPromptForCredentials(os.Stdin, os.Stdout, os.Stderr)),
 		creds.WithPromptForCredentialStore(prompt.NewPromptForCredentialStore()),
 		creds.WithTransport(t),
-		creds.WithAdditionalCredentialLoaders(k8s.GetOpenShiftDockerCredentialLoaders()...),
+		creds.WithAdditionalCredentialLoaders(k8s.GetOpenShiftDockerCredentialLoaders(kubeContext())...),
+	}
+
+	// A credential helper chosen in global config is used to store
+	// credentials without prompting.
+	if cfg, _ := newGlobalConfig(); cfg.CredentialHelper != "" {
+		options = append(options, creds.WithPromptForCredentialStore(
+			func([]string) (string, error) { return cfg.CredentialHelper, nil }))
 	}
 
 	// Other cluster variants can be supported here
 	return creds.NewCredentialsProvider(configPath, options...)
 }
 
-func newTektonPipelinesProvider(creds docker.CredentialsProvider, verbose bool) *tekton.PipelinesProvider {
+// newPreflighter returns a checker of functions' deployments, whose
+// credentials provider finds but does not prompt for registry credentials.
+func newPreflighter(t http.RoundTripper) *preflight.Checker {
+	return preflight.NewChecker(
+		preflight.WithTransport(t),
+		preflight.WithCredentialsProvider(creds.NewCredentialsProvider(config.Dir(),
+			creds.WithTransport(t),
+			creds.WithAdditionalCredentialLoaders(k8s.GetOpenShiftDockerCredentialLoaders(kubeContext())...))))
+}
+
+// newDNSProvider returns a provider of the domains of deployed functions
+// which publishes to the backend chosen in global config, or nil if none is
+// chosen.  An invalid backend URL is an error.
+func newDNSProvider() (fn.DNSProvider, error) {
+	cfg, _ := newGlobalConfig()
+	if cfg.DNSProvider == "" {
+		return nil, nil
+	}
+	backend, err := dns.NewBackend(cfg.DNSProvider)
+	if err != nil {
+		return nil, err
+	}
+	return dns.NewProvider(backend), nil
+}
+
+// newPipelinesProvider returns the pipelines provider of the named remote
+// builder, defaulting to Tekton.
+func newPipelinesProvider(remoteBuilder string, creds docker.CredentialsProvider, verbose, quiet, registryInsecure bool) fn.PipelinesProvider {
+	if remoteBuilder == pipelines.RemoteBuilderJob {
+		return job.NewPipelinesProvider(
+			job.WithCredentialsProvider(creds),
+			job.WithVerbose(verbose),
+			job.WithQuiet(quiet),
+			job.WithRegistryInsecure(registryInsecure),
+			job.WithPipelineDecorator(deployDecorator{}),
+			job.WithSecretsKey(config.SecretsKeyFile()))
+	}
+	return newTektonPipelinesProvider(creds, verbose, quiet, registryInsecure)
+}
+
+func newTektonPipelinesProvider(creds docker.CredentialsProvider, verbose, quiet, registryInsecure bool) *tekton.PipelinesProvider {
 	options := []tekton.Opt{
 		tekton.WithCredentialsProvider(creds),
 		tekton.WithVerbose(verbose),
+		tekton.WithQuiet(quiet),
+		tekton.WithRegistryInsecure(registryInsecure),
 		tekton.WithPipelineDecorator(deployDecorator{}),
+		tekton.WithSecretsKey(config.SecretsKeyFile()),
 	}
 
 	return tekton.NewPipelinesProvider(options...)
 }
 
+// newDeployer returns the deployer of the named platform.
+func newDeployer(platform string, verbose bool) (fn.Deployer, error) {
+	switch platform {
+	case "", deployers.Knative:
+		return newKnativeDeployer(verbose), nil
+	case deployers.Kubernetes:
+		return newKubernetesDeployer(verbose), nil
+	case deployers.Docker:
+		return newDockerDeployer(verbose), nil
+	}
+	return nil, deployers.ErrUnknownDeployer{Name: platform, Known: KnownDeployers()}
+}
+
 func newKnativeDeployer(verbose bool) fn.Deployer {
 	options := []knative.DeployerOpt{
 		knative.WithDeployerVerbose(verbose),
 		knative.WithDeployerDecorator(deployDecorator{}),
+		knative.WithDeployerSecretsKey(config.SecretsKeyFile()),
 	}
 
 	return knative.NewDeployer(options...)
 }
 
+func newKubernetesDeployer(verbose bool) fn.Deployer {
+	options := []k8s.DeployerOpt{
+		k8s.WithDeployerVerbose(verbose),
+		k8s.WithDeployerDecorator(deployDecorator{}),
+		k8s.WithDeployerSecretsKey(config.SecretsKeyFile()),
+	}
+
+	return k8s.NewDeployer(options...)
+}
+
+// newLister returns a lister of the functions deployed to all platforms.
+func newLister(verbose bool) fn.Lister {
+	return platformListers{knative.NewLister(verbose), k8s.NewLister(verbose), newDockerDeployer(verbose)}
+}
+
+// newDescriber returns a describer of a function deployed to any platform.
+func newDescriber(verbose bool) fn.Describer {
+	return platformDescribers{k8s.NewDescriber(verbose), knative.NewDescriber(verbose), newDockerDeployer(verbose)}
+}
+
+// newRemover returns a remover of a function deployed to any platform.
+func newRemover(verbose bool) fn.Remover {
+	return platformRemovers{k8s.NewRemover(verbose), knative.NewRemover(verbose), newDockerDeployer(verbose)}
+}
+
+// newDockerDeployer returns the deployer of the docker platform, which is
+// also its lister, describer and remover.
+func newDockerDeployer(verbose bool) *docker.Deployer {
+	return docker.NewDeployer(docker.WithDeployerVerbose(verbose))
+}
+
+// platformListers list the functions deployed to each platform.  Platforms
+// which can not be listed, such as those not installed on the cluster, are
+// skipped unless none can be listed.
+type platformListers []fn.Lister
+
+func (ll platformListers) List(ctx context.Context, namespace string) (items []fn.ListItem, err error) {
+	var firstErr error
+	listed := false
+	for _, l := range ll {
+		found, err := l.List(ctx, namespace)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		listed = true
+		items = append(items, found...)
+	}
+	if !listed {
+		return nil, firstErr
+	}
+	return items, nil
+}
+
+// platformDescribers describe a function deployed to any platform, trying
+// each in turn until the function is found.  Platforms which can not be
+// reached, such as those not installed, are skipped unless none can be.
+type platformDescribers []fn.Describer
+
+func (dd platformDescribers) Describe(ctx context.Context, name, namespace string) (instance fn.Instance, err error) {
+	var reported error // not found if so by any platform, else the first error
+	for _, d := range dd {
+		if instance, err = d.Describe(ctx, name, namespace); err == nil {
+			return
+		} else if reported == nil || errors.Is(err, fn.ErrFunctionNotFound) {
+			reported = err
+		}
+	}
+	return instance, reported
+}
+
+// platformRemovers remove a function deployed to any platform, trying each
+// in turn in the same way as platformDescribers.
+type platformRemovers []fn.Remover
+
+func (rr platformRemovers) Remove(ctx context.Context, name, namespace string) (err error) {
+	var reported error
+	for _, r := range rr {
+		if err = r.Remove(ctx, name, namespace); err == nil {
+			return
+		} else if reported == nil || errors.Is(err, fn.ErrFunctionNotFound) {
+			reported = err
+		}
+	}
+	return reported
+}
+
 type deployDecorator struct {
 	oshDec k8s.OpenshiftMetadataDecorator
 }
 
 func (d deployDecorator) UpdateAnnotations(function fn.Function, annotations map[string]string) map[string]string {
-	if k8s.IsOpenShift() {
+	if k8s.IsOpenShift(kubeContext()) {
 		return d.oshDec.UpdateAnnotations(function, annotations)
 	}
 	return annotations
 }
 
 func (d deployDecorator) UpdateLabels(function fn.Function, labels map[string]string) map[string]string {
-	if k8s.IsOpenShift() {
+	if k8s.IsOpenShift(kubeContext()) {
 		return d.oshDec.UpdateLabels(function, labels)
 	}
 	return labels
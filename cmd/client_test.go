@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	fn "knative.dev/func/pkg/functions"
@@ -53,3 +54,30 @@ func Test_NewTestClient(t *testing.T) {
 	// by commands, allowing tests to "force" a command to use the mocked
 	// implementations.
 }
+
+// Test_platformDescribers ensures a function is described by the platform on
+// which it is found, skipping those which can not be reached, and that it is
+// reported not found if so by any platform.
+func Test_platformDescribers(t *testing.T) {
+	unreachable, missing, found := mock.NewDescriber(), mock.NewDescriber(), mock.NewDescriber()
+	unreachable.DescribeFn = func(context.Context, string, string) (fn.Instance, error) {
+		return fn.Instance{}, errors.New("platform unreachable")
+	}
+	missing.DescribeFn = func(context.Context, string, string) (fn.Instance, error) {
+		return fn.Instance{}, fn.ErrFunctionNotFound
+	}
+	found.DescribeFn = func(_ context.Context, name, _ string) (fn.Instance, error) {
+		return fn.Instance{Name: name}, nil
+	}
+
+	instance, err := platformDescribers{unreachable, missing, found}.Describe(context.Background(), "myfunc", "myns")
+	if err != nil || instance.Name != "myfunc" {
+		t.Fatalf("expected the function to be described, got %v, %v", instance, err)
+	}
+	if _, err = (platformDescribers{unreachable, missing}).Describe(context.Background(), "myfunc", "myns"); !errors.Is(err, fn.ErrFunctionNotFound) {
+		t.Fatalf("expected ErrFunctionNotFound, got %v", err)
+	}
+	if _, err = (platformDescribers{unreachable}).Describe(context.Background(), "myfunc", "myns"); err == nil || errors.Is(err, fn.ErrFunctionNotFound) {
+		t.Fatalf("expected the unreachable platform's error, got %v", err)
+	}
+}
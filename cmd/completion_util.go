@@ -10,12 +10,12 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
-	"knative.dev/func/pkg/knative"
 )
 
 func CompleteFunctionList(cmd *cobra.Command, args []string, toComplete string) (strings []string, directive cobra.ShellCompDirective) {
-	lister := knative.NewLister(false)
+	lister := newLister(false)
 
 	list, err := lister.List(cmd.Context(), "")
 	if err != nil {
@@ -141,6 +141,19 @@ func CompleteBuilderImageList(cmd *cobra.Command, args []string, complete string
 	return
 }
 
+func CompleteDeployerList(cmd *cobra.Command, args []string, complete string) (matches []string, d cobra.ShellCompDirective) {
+	d = cobra.ShellCompDirectiveNoFileComp
+	matches = []string{}
+
+	for _, b := range KnownDeployers() {
+		if strings.HasPrefix(b, complete) {
+			matches = append(matches, b)
+		}
+	}
+
+	return
+}
+
 func CompleteBuilderList(cmd *cobra.Command, args []string, complete string) (matches []string, d cobra.ShellCompDirective) {
 	d = cobra.ShellCompDirectiveNoFileComp
 	matches = []string{}
@@ -158,3 +171,11 @@ func CompleteBuilderList(cmd *cobra.Command, args []string, complete string) (ma
 
 	return
 }
+
+// CompleteGlobalConfigList completes the names of global config settings.
+func CompleteGlobalConfigList(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.List(), cobra.ShellCompDirectiveNoFileComp
+}
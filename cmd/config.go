@@ -57,7 +57,7 @@ or from the directory specified with --path.
 		PreRunE:    bindEnv("path", "verbose"),
 		RunE:       runConfigCmd,
 	}
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -69,6 +69,10 @@ or from the directory specified with --path.
 	cmd.AddCommand(NewConfigLabelsCmd(loadSaver))
 	cmd.AddCommand(NewConfigEnvsCmd(loadSaver))
 	cmd.AddCommand(NewConfigVolumesCmd())
+	cmd.AddCommand(NewConfigHealthCmd(loadSaver))
+	cmd.AddCommand(NewConfigSecretsCmd(loadSaver))
+	cmd.AddCommand(NewConfigConfigMapsCmd(loadSaver))
+	cmd.AddCommand(NewConfigGlobalCmd())
 
 	return cmd
 }
@@ -25,7 +25,7 @@ the current directory or from the directory specified with --path.
 		},
 	}
 	// Global Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -22,17 +22,18 @@ func NewConfigGitRemoveCmd(newClient ClientFactory) *cobra.Command {
 	directory or from the directory specified with --path.
 
 	It also removes any generated resources that are used for Git based build and deployment,
-	such as local generated Pipelines resources and any resources generated on the cluster.
+	such as local generated Pipelines resources, any resources generated on the cluster and
+	the webhook created on the Git repository.
 	`,
 		SuggestFor: []string{"rem", "rmeove", "del", "dle"},
-		PreRunE:    bindEnv("path", "delete-local", "delete-cluster"),
+		PreRunE:    bindEnv("path", "delete-local", "delete-cluster", "delete-remote", "gh-access-token"),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			return runConfigGitRemoveCmd(cmd, newClient)
 		},
 	}
 
 	// Global Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -40,6 +41,11 @@ func NewConfigGitRemoveCmd(newClient ClientFactory) *cobra.Command {
 	// Resources generated related Flags:
 	cmd.Flags().Bool("delete-local", false, "Delete local resources (pipeline templates).")
 	cmd.Flags().Bool("delete-cluster", false, "Delete cluster resources (credentials and config on the cluster).")
+	cmd.Flags().Bool("delete-remote", false, "Delete remote resources (webhook on the Git provider side).")
+
+	// Git provider credentials related Flags:
+	cmd.Flags().String("gh-access-token", "",
+		"Personal Access Token of the Git provider, used to delete the webhook. If not specified, it is read from the cluster.")
 
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
@@ -70,9 +76,11 @@ func newConfigGitRemoveConfig(_ *cobra.Command) (c configGitRemoveConfig) {
 	// - if any parameter is explicitly specified then get value from parameters
 	deleteLocal := true
 	deleteCluster := true
-	if viper.HasChanged("delete-local") || viper.HasChanged("delete-cluster") {
+	deleteRemote := true
+	if viper.HasChanged("delete-local") || viper.HasChanged("delete-cluster") || viper.HasChanged("delete-remote") {
 		deleteLocal = viper.GetBool("delete-local")
 		deleteCluster = viper.GetBool("delete-cluster")
+		deleteRemote = viper.GetBool("delete-remote")
 		flagSet = true
 	}
 
@@ -80,8 +88,11 @@ func newConfigGitRemoveConfig(_ *cobra.Command) (c configGitRemoveConfig) {
 		flagSet: flagSet,
 
 		metadata: pipelines.PacMetadata{
+			PersonalAccessToken: viper.GetString("gh-access-token"),
+
 			ConfigureLocalResources:   deleteLocal,
 			ConfigureClusterResources: deleteCluster,
+			ConfigureRemoteResources:  deleteRemote,
 		},
 	}
 
@@ -121,6 +132,18 @@ func (c configGitRemoveConfig) Prompt(f fn.Function) (configGitRemoveConfig, err
 			return c, err
 		}
 		c.metadata.ConfigureClusterResources = deleteCluster
+
+		deleteRemote := f.Local.Webhook != nil
+		if deleteRemote {
+			if err := survey.AskOne(&survey.Confirm{
+				Message: "Do you want to delete the webhook on the Git repository?",
+				Help:    fmt.Sprintf("Delete the webhook created on the repository %v.", f.Local.Webhook.URL),
+				Default: deleteRemote,
+			}, &deleteRemote, survey.WithValidator(survey.Required)); err != nil {
+				return c, err
+			}
+		}
+		c.metadata.ConfigureRemoteResources = deleteRemote
 	}
 
 	return c, nil
@@ -23,16 +23,20 @@ func NewConfigGitSetCmd(newClient ClientFactory) *cobra.Command {
 
 	Interactive prompt to set Git settings in the function project in the current
 	directory or from the directory specified with --path.
+
+	Configuring the cluster also applies the Secrets and ConfigMaps managed with
+	the function (see '{{rootCmdUse}} config secrets'), which deployments triggered
+	by Pipelines as Code do not.  Run it again to apply later changes to them.
 	`,
 		SuggestFor: []string{"add", "ad", "update", "create", "insert", "append"},
-		PreRunE:    bindEnv("path", "builder", "builder-image", "image", "registry", "git-provider", "git-url", "git-branch", "git-dir", "gh-access-token", "config-local", "config-cluster", "config-remote"),
+		PreRunE:    bindEnv("path", "builder", "builder-image", "image", "registry", "git-provider", "git-url", "git-branch", "git-dir", "git-user", "gh-access-token", "config-local", "config-cluster", "config-remote"),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			return runConfigGitSetCmd(cmd, newClient)
 		},
 	}
 
 	// Global Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -65,7 +69,7 @@ func NewConfigGitSetCmd(newClient ClientFactory) *cobra.Command {
 
 	// Git related Flags:
 	cmd.Flags().String("git-provider", "",
-		fmt.Sprintf("The type of the Git platform provider to setup webhook. This value is usually automatically generated from input URL, use this parameter to override this setting. Currently supported providers are %s.", git.SupportedProvidersList.PrettyString()))
+		fmt.Sprintf("The type of the Git platform provider to setup webhook. This value is usually automatically generated from input URL, use this parameter to override this setting, eg. for self-hosted providers. Currently supported providers are %s.", git.SupportedProvidersList.PrettyString()))
 	cmd.Flags().StringP("git-url", "g", "",
 		"Repository url containing the function to build ($FUNC_GIT_URL)")
 	cmd.Flags().StringP("git-branch", "t", "",
@@ -73,11 +77,13 @@ func NewConfigGitSetCmd(newClient ClientFactory) *cobra.Command {
 	cmd.Flags().StringP("git-dir", "d", "",
 		"Directory in the Git repository containing the function (default is the root) ($FUNC_GIT_DIR)")
 
-	// GitHub related Flags:
+	// Git provider credentials related Flags:
+	cmd.Flags().String("git-user", "",
+		"User of the Personal Access Token, required by Bitbucket unless it is an access token of the repository. ($FUNC_GIT_USER)")
 	cmd.Flags().String("gh-access-token", "",
-		"GitHub Personal Access Token. For public repositories the scope is 'public_repo', for private is 'repo'. If you want to configure the webhook automatically, 'admin:repo_hook' is needed as well. Get more details: https://pipelines-as-code.pages.dev/docs/install/github_webhook/.")
+		"Personal Access Token of the Git provider. On GitHub, for public repositories the scope is 'public_repo', for private is 'repo'. If you want to configure the webhook automatically, 'admin:repo_hook' is needed as well. Get more details: https://pipelines-as-code.pages.dev/docs/install/github_webhook/.")
 	cmd.Flags().String("gh-webhook-secret", "",
-		"GitHub Webhook Secret used for payload validation. If not specified, it will be generated automatically.")
+		"Webhook Secret used for payload validation. If not specified, it will be generated automatically.")
 
 	// Resources generated related Flags:
 	cmd.Flags().Bool("config-local", false, "Configure local resources (pipeline templates).")
@@ -134,6 +140,7 @@ func newConfigGitSetConfig(_ *cobra.Command) (c configGitSetConfig) {
 
 		metadata: pipelines.PacMetadata{
 			GitProvider:         viper.GetString("git-provider"),
+			GitUser:             viper.GetString("git-user"),
 			PersonalAccessToken: viper.GetString("gh-access-token"),
 			WebhookSecret:       viper.GetString("gh-webhook-secret"),
 
@@ -230,12 +237,29 @@ func (c configGitSetConfig) Prompt(f fn.Function) (configGitSetConfig, error) {
 			c.metadata.GitProvider = provider
 		}
 
+		// prompt if the user of a Bitbucket token hasn't been set previously
+		bitbucket := c.metadata.GitProvider == git.BitBucketProvider || c.metadata.GitProvider == git.BitBucketServerProvider
+		if bitbucket && c.metadata.GitUser == "" {
+			var user string
+			if err := survey.AskOne(&survey.Input{
+				Message: "Please enter the Bitbucket user:",
+				Help:    "The user of the App Password (Bitbucket Cloud) or of the HTTP access token (Bitbucket Server). Leave empty for an access token of the repository.",
+			}, &user); err != nil {
+				return c, err
+			}
+			c.metadata.GitUser = user
+		}
+
 		// prompt if PersonalAccessToken hasn't been set previously
 		if c.metadata.PersonalAccessToken == "" {
+			help := "For public repositories the scope is 'public_repo', for private is 'repo'. If you want to configure the webhook automatically 'admin:repo_hook' is needed as well. Get more details: https://pipelines-as-code.pages.dev/docs/install/github_webhook/."
+			if c.metadata.GitProvider != git.GitHubProvider {
+				help = "The token needs permission to read the repository and to administer its webhooks. Get more details: https://pipelines-as-code.pages.dev/docs/install/"
+			}
 			var personalAccessToken string
 			if err := survey.AskOne(&survey.Password{
-				Message: "Please enter the GitHub Personal Access Token:",
-				Help:    "For public repositories the scope is 'public_repo', for private is 'repo'. If you want to configure the webhook automatically 'admin:repo_hook' is needed as well. Get more details: https://pipelines-as-code.pages.dev/docs/install/github_webhook/.",
+				Message: "Please enter the Personal Access Token of the Git provider:",
+				Help:    help,
 			}, &personalAccessToken, survey.WithValidator(survey.Required)); err != nil {
 				return c, err
 			}
@@ -252,6 +276,10 @@ func (c configGitSetConfig) Validate(cmd *cobra.Command) (err error) {
 		return
 	}
 
+	if c.metadata.GitProvider != "" && !git.SupportedProvidersList.Supported(c.metadata.GitProvider) {
+		return fmt.Errorf("unsupported git provider %q, please use one of supported providers: %s", c.metadata.GitProvider, git.SupportedProvidersList.PrettyString())
+	}
+
 	return
 }
 
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/dns"
+)
+
+func NewConfigGlobalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "global",
+		Short: "List and manage global configuration",
+		Long: `List and manage global configuration
+
+Prints the settings of the global configuration file, which provide defaults
+for all functions.  The file is located at ~/.config/func/config.yaml, or at
+$XDG_CONFIG_HOME/func/config.yaml if defined, and may be overridden using
+$FUNC_CONFIG_FILE.
+
+Profiles are named sets of settings which are applied atop the others when
+selected using --profile or $FUNC_PROFILE.  When a profile is selected, the
+commands below read and write the settings of that profile.  A setting set in a
+profile, even to false or empty, overrides that of the file.  Unset, it is that
+of the file.
+`,
+		Example: `
+# Set the default registry
+{{rootCmdUse}} config global set registry quay.io/alice
+
+# Define a profile for a local kind cluster
+{{rootCmdUse}} config global set registry localhost:50000/func --profile kind-local
+{{rootCmdUse}} config global set kubeContext kind-func --profile kind-local
+
+# Deploy using the profile
+{{rootCmdUse}} deploy --profile kind-local
+`,
+		SuggestFor: []string{"globl", "gloabl"},
+		PreRunE:    bindFlags("profile"),
+		RunE:       runConfigGlobalList,
+	}
+
+	listCmd := &cobra.Command{
+		Use:        "list",
+		Short:      "List global configuration settings",
+		Aliases:    []string{"ls"},
+		Args:       cobra.NoArgs,
+		PreRunE:    bindFlags("profile"),
+		RunE:       runConfigGlobalList,
+		SuggestFor: []string{"lsit"},
+	}
+
+	getCmd := &cobra.Command{
+		Use:               "get <name>",
+		Short:             "Print the value of a global configuration setting",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: CompleteGlobalConfigList,
+		PreRunE:           bindFlags("profile"),
+		RunE:              runConfigGlobalGet,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <name> <value>",
+		Short: "Set the value of a global configuration setting",
+		Long: `Set the value of a global configuration setting
+
+Durations (such as buildTimeout) are specified with a unit suffix, for
+example "90s" or "10m".  Booleans are specified as "true" or "false".
+`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: CompleteGlobalConfigList,
+		PreRunE:           bindFlags("profile"),
+		RunE:              runConfigGlobalSet,
+	}
+
+	unsetCmd := &cobra.Command{
+		Use:               "unset <name>",
+		Short:             "Remove a global configuration setting",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: CompleteGlobalConfigList,
+		PreRunE:           bindFlags("profile"),
+		RunE:              runConfigGlobalUnset,
+	}
+
+	// The profile flag is also defined on the root command.  It is redefined
+	// here such that these commands are usable independently.
+	cmd.PersistentFlags().String("profile", config.Profile(), "Global config profile to read and write ($FUNC_PROFILE)")
+
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(getCmd)
+	cmd.AddCommand(setCmd)
+	cmd.AddCommand(unsetCmd)
+
+	return cmd
+}
+
+func runConfigGlobalList(cmd *cobra.Command, _ []string) error {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if cfg, err = cfg.WithProfile(viper.GetString("profile")); err != nil {
+		return err
+	}
+	for _, key := range config.List() {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", key, formatGlobalConfigValue(config.Get(cfg, key)))
+	}
+	return nil
+}
+
+func runConfigGlobalGet(cmd *cobra.Command, args []string) error {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if cfg, err = cfg.WithProfile(viper.GetString("profile")); err != nil {
+		return err
+	}
+	v := config.Get(cfg, args[0])
+	if v == nil {
+		return fmt.Errorf("unrecognized global config setting %q.  Available settings are: %v", args[0], config.List())
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), formatGlobalConfigValue(v))
+	return nil
+}
+
+func runConfigGlobalSet(_ *cobra.Command, args []string) error {
+	return updateGlobalConfig(viper.GetString("profile"), true, func(c config.Global) (config.Global, error) {
+		if args[0] == "dnsProvider" && args[1] != "" {
+			if _, err := dns.NewBackend(args[1]); err != nil {
+				return c, err
+			}
+		}
+		return config.Set(c, args[0], args[1])
+	})
+}
+
+func runConfigGlobalUnset(_ *cobra.Command, args []string) error {
+	return updateGlobalConfig(viper.GetString("profile"), false, func(c config.Global) (config.Global, error) {
+		return config.Unset(c, args[0])
+	})
+}
+
+// updateGlobalConfig applies the given update to the global config file or,
+// if a profile is named, to that profile.  Profiles which do not yet exist are
+// created only if create is true.
+func updateGlobalConfig(profile string, create bool, update func(config.Global) (config.Global, error)) (err error) {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return
+	}
+	if profile == "" {
+		if cfg, err = update(cfg); err != nil {
+			return
+		}
+	} else {
+		p, ok := cfg.Profiles[profile]
+		if !ok && !create {
+			return fmt.Errorf("%w: %q", config.ErrProfileNotFound, profile)
+		}
+		if p, err = update(p); err != nil {
+			return
+		}
+		profiles := make(map[string]config.Global, len(cfg.Profiles)+1)
+		for k, v := range cfg.Profiles {
+			profiles[k] = v
+		}
+		profiles[profile] = p
+		cfg.Profiles = profiles
+	}
+	if err = config.CreatePaths(); err != nil {
+		return
+	}
+	return cfg.Write(config.File())
+}
+
+// loadGlobalConfig loads the global config file exactly as it exists on disk
+// (without static defaults).  A nonexistent file is an empty config.
+func loadGlobalConfig() (config.Global, error) {
+	if _, err := os.Stat(config.File()); errors.Is(err, os.ErrNotExist) {
+		return config.Global{}, nil
+	}
+	return config.Load(config.File())
+}
+
+// formatGlobalConfigValue for display, with unset (zero) values empty.
+func formatGlobalConfigValue(v any) string {
+	if v == nil || reflect.ValueOf(v).IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
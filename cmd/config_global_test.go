@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/k8s"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestConfigGlobal_SetGetUnset ensures that global settings of all kinds can
+// be set, retrieved and unset.
+func TestConfigGlobal_SetGetUnset(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := NewConfigGlobalCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(&out)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(out.String())
+	}
+
+	run("set", "registry", "example.com/alice")
+	run("set", "registryInsecure", "true")
+	run("set", "buildTimeout", "10m")
+
+	if v := run("get", "registry"); v != "example.com/alice" {
+		t.Fatalf("unexpected registry %q", v)
+	}
+	cfg, err := config.NewDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.RegistryInsecure || cfg.BuildTimeout != 10*time.Minute {
+		t.Fatalf("settings not persisted: %+v", cfg)
+	}
+
+	if out := run("list"); !strings.Contains(out, "buildTimeout: 10m0s") {
+		t.Fatalf("unexpected list output:\n%v", out)
+	}
+
+	run("unset", "registry")
+	if v := run("get", "registry"); v != "" {
+		t.Fatalf("expected registry to be unset, got %q", v)
+	}
+
+	// Unknown settings are an error
+	cmd := NewConfigGlobalCmd()
+	cmd.SetArgs([]string{"set", "reg", "example.com/alice"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error setting an unknown setting")
+	}
+
+	// An invalid DNS provider is an error
+	cmd = NewConfigGlobalCmd()
+	cmd.SetArgs([]string{"set", "dnsProvider", "route53://example.com"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error setting an invalid DNS provider")
+	}
+	run("set", "dnsProvider", "hosts:///etc/hosts")
+}
+
+// TestConfigGlobal_Profile ensures that settings are written to and read from
+// the selected profile, and that the profile is applied to global defaults
+// when selected via FUNC_PROFILE.
+func TestConfigGlobal_Profile(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	for _, args := range [][]string{
+		{"set", "registry", "example.com/alice"},
+		{"set", "registry", "localhost:50000/func", "--profile", "kind-local"},
+		{"set", "kubeContext", "kind-func", "--profile", "kind-local"},
+	} {
+		cmd := NewConfigGlobalCmd()
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Registry != "example.com/alice" || cfg.KubeContext != "" {
+		t.Fatalf("profile should not be applied unless selected: %+v", cfg)
+	}
+
+	t.Setenv("FUNC_PROFILE", "kind-local")
+	if cfg, err = config.NewDefault(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Registry != "localhost:50000/func" || cfg.KubeContext != "kind-func" {
+		t.Fatalf("profile not applied: %+v", cfg)
+	}
+
+	// Unsetting a value of a profile which does not exist is an error
+	cmd := NewConfigGlobalCmd()
+	cmd.SetArgs([]string{"unset", "registry", "--profile", "work-cluster"})
+	if err := cmd.Execute(); !errors.Is(err, config.ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+// TestConfigGlobal_ProfileKubeContext ensures that commands are run against
+// the kubeconfig context of the selected profile, and that selecting a
+// profile which does not exist is an error.
+func TestConfigGlobal_ProfileKubeContext(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	cmd := NewConfigGlobalCmd()
+	cmd.SetArgs([]string{"set", "kubeContext", "kind-func", "--profile", "kind-local"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("FUNC_PROFILE", "kind-local")
+	cmd = &cobra.Command{}
+	cmd.SetContext(context.Background())
+	if err := bindEnv()(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c := k8s.KubeContext(cmd.Context()); c != "kind-func" {
+		t.Fatalf("expected kubeconfig context 'kind-func', got %q", c)
+	}
+
+	t.Setenv("FUNC_PROFILE", "work-cluster")
+	if err := bindEnv()(cmd, nil); !errors.Is(err, config.ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// healthProbes are the names of the probes which may be configured.
+var healthProbes = []string{"liveness", "readiness", "startup"}
+
+func NewConfigHealthCmd(loadSaver functionLoaderSaver) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "List and manage health probes of a function",
+		Long: `List and manage health probes of a function
+
+Prints the liveness, readiness and startup probes of the function project
+present in the current directory or from the directory specified with --path.
+Probes not configured use the defaults of the function's language pack.  A
+startup probe is only set when configured.
+
+The time a deployed function may take to become ready is set by the
+run.startTimeout field of func.yaml.
+`,
+		Example: `
+# Check readiness every 5 seconds, allowing 6 failures
+{{rootCmdUse}} config health set readiness --period=5 --failure-threshold=6
+
+# Use a startup probe for a slow-starting function
+{{rootCmdUse}} config health set startup --probe-path=/health/startup --period=10 --failure-threshold=30
+
+# Check liveness with a command run in the container
+{{rootCmdUse}} config health set liveness --exec=/bin/check --exec=--quick
+
+# Restore the default readiness probe
+{{rootCmdUse}} config health remove readiness
+`,
+		Aliases:    []string{"probes"},
+		SuggestFor: []string{"healht"},
+		PreRunE:    bindEnv("path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			f, err := initConfigCommand(loadSaver)
+			if err != nil {
+				return err
+			}
+			for _, name := range healthProbes {
+				p := healthProbe(&f, name)
+				if p.IsZero() {
+					fmt.Fprintf(cmd.OutOrStdout(), "%v: default\n", name)
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%v: %v\n", name, describeProbe(*p))
+			}
+			return nil
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <liveness|readiness|startup>",
+		Short: "Set a health probe",
+		Long: `Set a health probe
+
+Replaces the named probe with that defined by the given flags.  The probe
+makes an HTTP GET request to --probe-path (which defaults to the language pack's
+endpoint), or runs the command given by --exec, or makes a GRPC health check
+on --grpc-port.
+`,
+		Args:       cobra.ExactArgs(1),
+		ValidArgs:  healthProbes,
+		SuggestFor: []string{"add", "update"},
+		PreRunE:    bindEnv("path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigHealthSet(cmd, args, loadSaver)
+		},
+	}
+	setCmd.Flags().String("probe-path", "", "HTTP path to request")
+	setCmd.Flags().Int32("port", 0, "HTTP port to request, defaulting to that of the function")
+	setCmd.Flags().StringArray("header", []string{}, "HTTP header to set on the request, as NAME=VALUE")
+	setCmd.Flags().StringArray("exec", []string{}, "Command (and arguments, when repeated) to run in the container")
+	setCmd.Flags().Int32("grpc-port", 0, "Port of a GRPC health service to check")
+	setCmd.Flags().String("grpc-service", "", "Name of the service to check with --grpc-port")
+	setCmd.Flags().Int32("initial-delay", 0, "Seconds after the container starts before probing")
+	setCmd.Flags().Int32("period", 0, "Seconds between probes")
+	setCmd.Flags().Int32("timeout", 0, "Seconds after which a probe fails")
+	setCmd.Flags().Int32("failure-threshold", 0, "Consecutive failed probes after which the function is unhealthy")
+
+	removeCmd := &cobra.Command{
+		Use:   "remove <liveness|readiness|startup>",
+		Short: "Remove a health probe, restoring its default",
+		Long: `Remove a health probe, restoring its default
+
+Removes the named probe from the function, such that the default of the
+function's language pack is used.  A startup probe has no default and is no
+longer set.
+`,
+		Args:       cobra.ExactArgs(1),
+		ValidArgs:  healthProbes,
+		Aliases:    []string{"rm"},
+		SuggestFor: []string{"del", "delete", "unset"},
+		PreRunE:    bindEnv("path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := initConfigCommand(loadSaver)
+			if err != nil {
+				return err
+			}
+			p := healthProbe(&f, args[0])
+			if p == nil {
+				return fmt.Errorf("unknown probe %q. Expected one of %v", args[0], strings.Join(healthProbes, ", "))
+			}
+			*p = fn.Probe{}
+			if err = loadSaver.Save(f); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %v probe\n", args[0])
+			return nil
+		},
+	}
+
+	cfg, err := newGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+	for _, c := range []*cobra.Command{cmd, setCmd, removeCmd} {
+		addPathFlag(c)
+		addVerboseFlag(c, cfg.Verbose)
+	}
+
+	cmd.AddCommand(setCmd)
+	cmd.AddCommand(removeCmd)
+
+	return cmd
+}
+
+func runConfigHealthSet(cmd *cobra.Command, args []string, loadSaver functionLoaderSaver) (err error) {
+	f, err := initConfigCommand(loadSaver)
+	if err != nil {
+		return
+	}
+	p := healthProbe(&f, args[0])
+	if p == nil {
+		return fmt.Errorf("unknown probe %q. Expected one of %v", args[0], strings.Join(healthProbes, ", "))
+	}
+
+	flags := cmd.Flags()
+	probe := fn.Probe{}
+	if probe.Path, err = flags.GetString("probe-path"); err != nil {
+		return
+	}
+	if probe.Port, err = flags.GetInt32("port"); err != nil {
+		return
+	}
+	headers, err := flags.GetStringArray("header")
+	if err != nil {
+		return
+	}
+	for _, h := range headers {
+		k, v, ok := strings.Cut(h, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("invalid header %q. Expected NAME=VALUE", h)
+		}
+		if probe.Headers == nil {
+			probe.Headers = map[string]string{}
+		}
+		probe.Headers[k] = v
+	}
+	if probe.Exec, err = flags.GetStringArray("exec"); err != nil {
+		return
+	}
+	grpcPort, err := flags.GetInt32("grpc-port")
+	if err != nil {
+		return
+	}
+	grpcService, err := flags.GetString("grpc-service")
+	if err != nil {
+		return
+	}
+	if grpcPort != 0 || grpcService != "" {
+		probe.GRPC = &fn.GRPCProbe{Port: grpcPort, Service: grpcService}
+	}
+	for flag, v := range map[string]*int32{
+		"initial-delay":     &probe.InitialDelaySeconds,
+		"period":            &probe.PeriodSeconds,
+		"timeout":           &probe.TimeoutSeconds,
+		"failure-threshold": &probe.FailureThreshold,
+	} {
+		if *v, err = flags.GetInt32(flag); err != nil {
+			return
+		}
+	}
+	if probe.IsZero() {
+		return fmt.Errorf("the probe is not defined.  Use --probe-path, --exec, --grpc-port or the probe's timing flags")
+	}
+
+	*p = probe
+	if err = f.Validate(); err != nil {
+		return
+	}
+	if err = loadSaver.Save(f); err != nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Set %v probe: %v\n", args[0], describeProbe(probe))
+	return
+}
+
+// healthProbe returns the named probe of the function, or nil if the name is
+// not that of a probe.
+func healthProbe(f *fn.Function, name string) *fn.Probe {
+	switch name {
+	case "liveness":
+		return &f.Deploy.HealthEndpoints.Liveness
+	case "readiness":
+		return &f.Deploy.HealthEndpoints.Readiness
+	case "startup":
+		return &f.Deploy.HealthEndpoints.Startup
+	}
+	return nil
+}
+
+// describeProbe returns the probe's handler followed by its timing settings.
+func describeProbe(p fn.Probe) string {
+	s := p.String()
+	for _, setting := range []struct {
+		name  string
+		value int32
+	}{
+		{"initialDelaySeconds", p.InitialDelaySeconds},
+		{"periodSeconds", p.PeriodSeconds},
+		{"timeoutSeconds", p.TimeoutSeconds},
+		{"failureThreshold", p.FailureThreshold},
+	} {
+		if setting.value != 0 {
+			s += fmt.Sprintf(" %v=%v", setting.name, setting.value)
+		}
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestConfigHealth ensures probes can be set, listed and removed.
+func TestConfigHealth(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := NewConfigHealthCmd(defaultLoaderSaver)
+		cmd.SetArgs(args)
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		err := cmd.Execute()
+		return strings.TrimSpace(out.String()), err
+	}
+
+	if _, err := run("set", "startup", "--probe-path=/health/startup", "--period=10", "--failure-threshold=30"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fn.Probe{Path: "/health/startup", PeriodSeconds: 10, FailureThreshold: 30}
+	if s := f.Deploy.HealthEndpoints.Startup; s.Path != expected.Path || s.PeriodSeconds != 10 || s.FailureThreshold != 30 {
+		t.Fatalf("expected startup probe %+v, got %+v", expected, s)
+	}
+
+	out, err := run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "startup: GET /health/startup periodSeconds=10 failureThreshold=30") {
+		t.Fatalf("unexpected list output:\n%v", out)
+	}
+
+	if _, err = run("remove", "startup"); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if !f.Deploy.HealthEndpoints.Startup.IsZero() {
+		t.Fatalf("expected the startup probe to be removed, got %+v", f.Deploy.HealthEndpoints.Startup)
+	}
+
+	for _, args := range [][]string{
+		{"set", "unknown", "--period=1"},                           // unknown probe
+		{"set", "liveness"},                                        // undefined probe
+		{"set", "liveness", "--probe-path=/l", "--exec=/bin/true"}, // two handlers
+		{"set", "liveness", "--header=novalue"},                    // malformed header
+	} {
+		if _, err = run(args...); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
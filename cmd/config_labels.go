@@ -81,7 +81,7 @@ directory or from the directory specified with --path.
 		},
 	}
 
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(configLabelsCmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
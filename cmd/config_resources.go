@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/crypt"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewConfigSecretsCmd(loader functionLoader) *cobra.Command {
+	return newConfigResourcesCmd(loader, fn.SecretResource, "secret", "Secrets", `
+Secrets are stored in the function's .funcresources/secrets directory with
+their values encrypted with NaCl secretbox using the key at
+~/.config/func/secrets.key (or the path set by $FUNC_SECRETS_KEY_FILE), which
+is created when the first secret is added.  The same key is required to deploy
+the function, and should be shared with others who deploy it.  The encrypted
+files may be committed with the function, and are not part of its image.`)
+}
+
+func NewConfigConfigMapsCmd(loader functionLoader) *cobra.Command {
+	return newConfigResourcesCmd(loader, fn.ConfigMapResource, "configmap", "ConfigMaps", `
+ConfigMaps are stored in the function's .funcresources/configmaps directory.`)
+}
+
+// newConfigResourcesCmd returns the command for managing resources of the
+// given kind, which differ only in their naming and description.
+func newConfigResourcesCmd(loader functionLoader, kind fn.ResourceKind, singular, plural, description string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   string(kind),
+		Short: fmt.Sprintf("List and manage %v deployed with a function", plural),
+		Long: fmt.Sprintf(`List and manage %v deployed with a function
+
+Prints the %v managed with the function project present in the current
+directory or from the directory specified with --path.  These are created or
+updated in the cluster when the function is deployed, at which time those
+removed from the function are deleted, and removed along with the function by
+'delete --all'.  They can then be referenced by the
+function's environment variables and volumes.
+
+They are applied by the client, including for deployments with --remote, as
+the pipelines on cluster can not decrypt secrets.  Deployments triggered by
+Pipelines as Code on a git push do not apply them: they are applied when
+'{{rootCmdUse}} config git set' configures the cluster.  Run it again, or deploy
+the function, to apply later changes.
+%v
+`, plural, plural, description),
+		Example: fmt.Sprintf(`
+# Add a %[1]v with two keys
+{{rootCmdUse}} config %[2]v add mydata --from-literal=user=alice --from-file=config.json
+
+# Reference it from an environment variable
+{{rootCmdUse}} config envs add --name=USER --value='{{"{{"}} %[1]v:mydata:user {{"}}"}}'
+
+# Remove a single key, and then the %[1]v
+{{rootCmdUse}} config %[2]v remove mydata user
+{{rootCmdUse}} config %[2]v remove mydata
+`, configMapOrSecret(kind), kind),
+		Aliases: []string{singular},
+		PreRunE: bindEnv("path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runConfigResourcesList(cmd, loader, kind)
+		},
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: fmt.Sprintf("Add or update a %v", singular),
+		Long: fmt.Sprintf(`Add or update a %[1]v
+
+Sets the given keys of the named %[1]v, creating it if necessary.  Values are
+set using --from-literal=KEY=VALUE, or read from a file using
+--from-file=[KEY=]PATH (the key defaulting to the file's name).  Both flags may
+be repeated.
+`, singular),
+		Args:       cobra.ExactArgs(1),
+		SuggestFor: []string{"ad", "create", "set"},
+		PreRunE:    bindEnv("path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigResourcesAdd(cmd, args, loader, kind)
+		},
+	}
+	addCmd.Flags().StringArray("from-literal", []string{}, "Key and literal value to set, as KEY=VALUE")
+	addCmd.Flags().StringArray("from-file", []string{}, "Key and file from which to read its value, as [KEY=]PATH")
+
+	removeCmd := &cobra.Command{
+		Use:   "remove <name> [key...]",
+		Short: fmt.Sprintf("Remove a %v, or keys of a %v", singular, singular),
+		Long: fmt.Sprintf(`Remove a %[1]v, or keys of a %[1]v
+
+Removes the named %[1]v from the function.  If keys are provided, only those
+keys are removed.  The %[1]v is removed from the cluster when the function is
+next deployed.
+`, singular),
+		Args:       cobra.MinimumNArgs(1),
+		Aliases:    []string{"rm"},
+		SuggestFor: []string{"del", "delete", "rmeove"},
+		PreRunE:    bindEnv("path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigResourcesRemove(cmd, args, loader, kind)
+		},
+	}
+
+	cfg, err := newGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+	for _, c := range []*cobra.Command{cmd, addCmd, removeCmd} {
+		addPathFlag(c)
+		addVerboseFlag(c, cfg.Verbose)
+	}
+
+	cmd.AddCommand(addCmd)
+	cmd.AddCommand(removeCmd)
+
+	return cmd
+}
+
+func runConfigResourcesList(cmd *cobra.Command, loader functionLoader, kind fn.ResourceKind) error {
+	f, err := initConfigCommand(loader)
+	if err != nil {
+		return err
+	}
+	rr, err := f.Resources(kind)
+	if err != nil {
+		return err
+	}
+	if len(rr) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "There are no %v managed with this function.\n", kind)
+		return nil
+	}
+	for _, r := range rr {
+		fmt.Fprintf(cmd.OutOrStdout(), "%v: %v\n", r.Name, strings.Join(r.Keys(), ", "))
+	}
+	return nil
+}
+
+func runConfigResourcesAdd(cmd *cobra.Command, args []string, loader functionLoader, kind fn.ResourceKind) (err error) {
+	f, err := initConfigCommand(loader)
+	if err != nil {
+		return
+	}
+
+	literals, err := cmd.Flags().GetStringArray("from-literal")
+	if err != nil {
+		return
+	}
+	files, err := cmd.Flags().GetStringArray("from-file")
+	if err != nil {
+		return
+	}
+	values, err := resourceValues(literals, files)
+	if err != nil {
+		return
+	}
+	if len(values) == 0 {
+		return errors.New("at least one value is required.  Use --from-literal or --from-file")
+	}
+
+	// Secret values are encrypted, creating the key if necessary.
+	var key crypt.Key
+	if kind == fn.SecretResource {
+		keyFile := config.SecretsKeyFile()
+		if _, err = os.Stat(keyFile); errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(cmd.OutOrStdout(), "Creating secrets key %v\nThis key is required to deploy the function.  Keep it safe.\n", keyFile)
+		}
+		if key, err = crypt.LoadKey(keyFile, true); err != nil {
+			return
+		}
+	}
+
+	r, err := f.Resource(kind, args[0])
+	if err != nil && !errors.Is(err, fn.ErrResourceNotFound) {
+		return
+	}
+	for k, v := range values {
+		if kind == fn.SecretResource {
+			if v, err = key.Encrypt([]byte(v)); err != nil {
+				return
+			}
+		}
+		r.Data[k] = v
+	}
+	if err = f.WriteResource(kind, r); err != nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Updated %v %q\n", configMapOrSecret(kind), r.Name)
+	return
+}
+
+func runConfigResourcesRemove(cmd *cobra.Command, args []string, loader functionLoader, kind fn.ResourceKind) (err error) {
+	f, err := initConfigCommand(loader)
+	if err != nil {
+		return
+	}
+	name, keys := args[0], args[1:]
+
+	if len(keys) == 0 {
+		if err = f.RemoveResource(kind, name); err != nil {
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %v %q\n", configMapOrSecret(kind), name)
+		return
+	}
+
+	r, err := f.Resource(kind, name)
+	if err != nil {
+		return
+	}
+	for _, k := range keys {
+		if _, ok := r.Data[k]; !ok {
+			return fmt.Errorf("%v %q has no key %q", configMapOrSecret(kind), name, k)
+		}
+		delete(r.Data, k)
+	}
+	if err = f.WriteResource(kind, r); err != nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %v from %v %q\n", strings.Join(keys, ", "), configMapOrSecret(kind), name)
+	return
+}
+
+// resourceValues returns the values defined by --from-literal (KEY=VALUE)
+// and --from-file ([KEY=]PATH) flags.
+func resourceValues(literals, files []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, l := range literals {
+		k, v, ok := strings.Cut(l, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid literal %q.  Expected KEY=VALUE", l)
+		}
+		values[k] = v
+	}
+	for _, f := range files {
+		k, path, ok := strings.Cut(f, "=")
+		if !ok {
+			k, path = filepath.Base(f), f
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		values[k] = string(b)
+	}
+	return values, nil
+}
+
+// configMapOrSecret returns the name by which resources of the given kind are
+// referenced (for example in '{{ configMap:name }}').
+func configMapOrSecret(kind fn.ResourceKind) string {
+	if kind == fn.ConfigMapResource {
+		return "configMap"
+	}
+	return "secret"
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"knative.dev/func/pkg/crypt"
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestConfigSecrets ensures that secrets can be added, listed and removed,
+// and that their values are stored encrypted using the user's key.
+func TestConfigSecrets(t *testing.T) {
+	root := FromTempDirectory(t)
+	keyFile := filepath.Join(t.TempDir(), "secrets.key")
+	t.Setenv("FUNC_SECRETS_KEY_FILE", keyFile)
+
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile("config.json", []byte(`{"debug":true}`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := NewConfigSecretsCmd(defaultLoaderSaver)
+		cmd.SetArgs(args)
+		cmd.SetOut(&out)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(out.String())
+	}
+
+	run("add", "creds", "--from-literal=user=alice", "--from-literal=password=s3cr3t", "--from-file=config.json")
+
+	// Values are encrypted using the created key
+	r, err := f.Resource(fn.SecretResource, "creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(r.Data["password"], "s3cr3t") {
+		t.Fatal("secret value stored in plain text")
+	}
+	key, err := crypt.LoadKey(keyFile, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, expected := range map[string]string{"user": "alice", "password": "s3cr3t", "config.json": `{"debug":true}`} {
+		v, err := key.Decrypt(r.Data[k])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(v) != expected {
+			t.Fatalf("expected %v to be %q, got %q", k, expected, v)
+		}
+	}
+
+	if out := run(); out != "creds: config.json, password, user" {
+		t.Fatalf("unexpected list output %q", out)
+	}
+
+	run("remove", "creds", "password")
+	if out := run(); out != "creds: config.json, user" {
+		t.Fatalf("unexpected list output %q", out)
+	}
+
+	run("remove", "creds")
+	if _, err = f.Resource(fn.SecretResource, "creds"); !errors.Is(err, fn.ErrResourceNotFound) {
+		t.Fatalf("expected the secret to be removed, got %v", err)
+	}
+}
+
+// TestConfigConfigMaps ensures that config maps are stored in plain text and
+// that invalid values are rejected.
+func TestConfigConfigMaps(t *testing.T) {
+	root := FromTempDirectory(t)
+	keyFile := filepath.Join(t.TempDir(), "secrets.key")
+	t.Setenv("FUNC_SECRETS_KEY_FILE", keyFile)
+
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewConfigConfigMapsCmd(defaultLoaderSaver)
+	cmd.SetArgs([]string{"add", "settings", "--from-literal=LOG_LEVEL=debug"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := f.Resource(fn.ConfigMapResource, "settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Data["LOG_LEVEL"] != "debug" {
+		t.Fatalf("unexpected config map data %v", r.Data)
+	}
+	if _, err = os.Stat(keyFile); !os.IsNotExist(err) {
+		t.Fatal("a secrets key should not be created for config maps")
+	}
+
+	for _, args := range [][]string{
+		{"add", "settings"},                               // no values
+		{"add", "settings", "--from-literal=novalue"},     // malformed literal
+		{"add", "Settings", "--from-literal=LEVEL=debug"}, // invalid name
+		{"remove", "settings", "missing"},                 // missing key
+		{"remove", "missing"},                             // missing config map
+	} {
+		cmd := NewConfigConfigMapsCmd(defaultLoaderSaver)
+		cmd.SetArgs(args)
+		if err = cmd.Execute(); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
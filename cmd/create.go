@@ -37,7 +37,7 @@ NAME
 
 SYNOPSIS
 	{{.Name}} create [-l|--language] [-t|--template] [-r|--repository]
-	            [-c|--confirm]  [-v|--verbose]  [path]
+	            [--adopt] [-c|--confirm]  [-v|--verbose]  [path]
 
 DESCRIPTION
 	Creates a new function project.
@@ -53,6 +53,16 @@ DESCRIPTION
 	To complete this command interactively, use --confirm (-c):
 	  $ {{.Name}} create -c
 
+	To bring existing source code under management as a function, use --adopt.
+	The directory need not be empty, and no template files are written; only
+	the function's metadata (func.yaml) and a .funcignore are created.  The
+	language is detected from the project manifest (go.mod, package.json,
+	pyproject.toml, Cargo.toml or pom.xml) unless provided with --language,
+	and the function signature is detected from the source where supported.
+	The defaults of the template given with --template (and --repository), or
+	else of the detected invocation, are applied to the function's metadata:
+	  $ {{.Name}} init --adopt
+
 	Available Language Runtimes and Templates:
 {{ .Options | indent 2 " " | indent 1 "\t" }}
 
@@ -69,9 +79,12 @@ EXAMPLES
 
 	o Create a Go function which handles CloudEvents in ./myfunc.
 	  $ {{.Name}} create -l go -t cloudevents myfunc
+
+	o Adopt the existing source code in ./myservice as a function.
+	  $ {{.Name}} init --adopt myservice
 		`,
 		SuggestFor: []string{"vreate", "creaet", "craete", "new"},
-		PreRunE:    bindEnv("language", "template", "repository", "confirm", "verbose"),
+		PreRunE:    bindEnv("language", "template", "repository", "adopt", "confirm", "verbose"),
 		Aliases:    []string{"init"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runCreate(cmd, args, newClient)
@@ -79,7 +92,7 @@ EXAMPLES
 	}
 
 	// Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -87,7 +100,8 @@ EXAMPLES
 	// Flags
 	cmd.Flags().StringP("language", "l", cfg.Language, "Language Runtime (see help text for list) ($FUNC_LANGUAGE)")
 	cmd.Flags().StringP("template", "t", fn.DefaultTemplate, "Function template. (see help text for list) ($FUNC_TEMPLATE)")
-	cmd.Flags().StringP("repository", "r", "", "URI to a Git repository containing the specified template ($FUNC_REPOSITORY)")
+	cmd.Flags().StringP("repository", "r", cfg.TemplateRepository, "URI to a Git repository containing the specified template ($FUNC_REPOSITORY)")
+	cmd.Flags().Bool("adopt", false, "Adopt the existing source code at the path as a function, writing only its metadata. Language is detected if not provided. ($FUNC_ADOPT)")
 
 	addConfirmFlag(cmd, cfg.Confirm)
 	// TODO: refactor to use --path like all the other commands
@@ -131,6 +145,21 @@ func runCreate(cmd *cobra.Command, args []string, newClient ClientFactory) (err
 		return
 	}
 
+	// Adopt
+	if cfg.Adopt {
+		f, err := client.Adopt(fn.Function{
+			Name:     cfg.Name,
+			Root:     cfg.Path,
+			Runtime:  cfg.Runtime,
+			Template: cfg.Template,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStderr(), "Adopted %v function in %v\n", f.Runtime, cfg.Path)
+		return nil
+	}
+
 	// Create
 	_, err = client.Init(fn.Function{
 		Name:     cfg.Name,
@@ -152,6 +181,7 @@ type createConfig struct {
 	Repository string // Repository URI (overrides builtin and installed)
 	Verbose    bool   // Verbose output
 	Confirm    bool   // Confirm values via an interactive prompt
+	Adopt      bool   // Adopt existing source rather than writing a template
 
 	// Template is the code written into the new function project, including
 	// an implementation adhering to one of the supported function signatures.
@@ -195,9 +225,15 @@ func newCreateConfig(cmd *cobra.Command, args []string, newClient ClientFactory)
 		Repository: viper.GetString("repository"),
 		Runtime:    viper.GetString("language"), // users refer to it is language
 		Template:   viper.GetString("template"),
+		Adopt:      viper.GetBool("adopt"),
 		Confirm:    viper.GetBool("confirm"),
 		Verbose:    viper.GetBool("verbose"),
 	}
+	// When adopting, the template defaults to that of the detected invocation
+	// (http or cloudevents) unless one was explicitly requested.
+	if cfg.Adopt && !viper.IsSet("template") {
+		cfg.Template = ""
+	}
 	// If not in confirm/prompting mode, this cfg structure is complete.
 	if !cfg.Confirm {
 		return
@@ -244,6 +280,9 @@ func singleCommand(cmd *cobra.Command, args []string, cfg createConfig) string {
 	if cmd.Flags().Lookup("repository").Changed {
 		b.WriteString(" -r " + cfg.Repository)
 	}
+	if cfg.Adopt {
+		b.WriteString(" --adopt")
+	}
 	if cmd.Flags().Lookup("verbose").Changed {
 		b.WriteString(fmt.Sprintf(" -v %v", cfg.Verbose))
 	}
@@ -284,6 +323,19 @@ func (c createConfig) Validate(client *fn.Client) (err error) {
 	// for a CLI it behooves us to be more verbose, including valid options for
 	// each.  So here, we check that the values entered (if any) are both valid
 	// and valid together.
+	// When adopting existing source, the runtime is detected if not provided,
+	// and the template's defaults are applied without writing its files.
+	if c.Adopt {
+		if c.Runtime != "" && c.Repository == "" && !isValidRuntime(client, c.Runtime) {
+			return newInvalidRuntimeError(client, c.Runtime)
+		}
+		if c.Runtime != "" && c.Template != "" && c.Repository == "" &&
+			!isValidTemplate(client, c.Runtime, c.Template) {
+			return newInvalidTemplateError(client, c.Runtime, c.Template)
+		}
+		return
+	}
+
 	if c.Runtime == "" {
 		return noRuntimeError(client)
 	}
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	fn "knative.dev/func/pkg/functions"
 	. "knative.dev/func/pkg/testing"
 	"knative.dev/func/pkg/utils"
 )
@@ -21,6 +24,71 @@ func TestCreate_Execute(t *testing.T) {
 	}
 }
 
+// TestCreate_Adopt ensures that existing source can be adopted as a function
+// without providing a language.
+func TestCreate_Adopt(t *testing.T) {
+	root := filepath.Join(FromTempDirectory(t), "myfunc")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module myfunc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "f.go"), []byte("package f\nfunc Handle() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--adopt", "myfunc"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Initialized() || f.Runtime != "go" {
+		t.Fatalf("expected an initialized go function, got runtime %q", f.Runtime)
+	}
+}
+
+// TestCreate_AdoptTemplate ensures that an explicitly requested template is
+// applied when adopting existing source, and that it is validated.
+func TestCreate_AdoptTemplate(t *testing.T) {
+	root := filepath.Join(FromTempDirectory(t), "myfunc")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module myfunc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "f.go"), []byte("package f\nfunc Handle() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--adopt", "--language", "go", "--template", "invalid", "myfunc"})
+	var e ErrInvalidTemplate
+	if err := cmd.Execute(); !errors.As(err, &e) {
+		t.Fatalf("expected ErrInvalidTemplate, got %v", err)
+	}
+
+	cmd = NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--adopt", "--template", "cloudevents", "myfunc"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Invoke != "cloudevent" {
+		t.Fatalf("expected the cloudevents template's invoke hint, got %q", f.Invoke)
+	}
+}
+
 // TestCreate_NoRuntime ensures that an invocation of create must be
 // done with a runtime.
 func TestCreate_NoRuntime(t *testing.T) {
@@ -29,11 +29,15 @@ No local files are deleted.
 
 # Undeploy the function 'myfunc' in namespace 'apps'
 {{rootCmdUse}} delete myfunc --namespace apps
+
+# Undeploy the function defined in the local directory from its "staging"
+# environment
+{{rootCmdUse}} delete --environment staging
 `,
 		SuggestFor:        []string{"remove", "del"},
 		Aliases:           []string{"rm"},
 		ValidArgsFunction: CompleteFunctionList,
-		PreRunE:           bindEnv("path", "confirm", "all", "namespace", "verbose"),
+		PreRunE:           bindEnv("path", "confirm", "all", "namespace", "environment", "verbose"),
 		SilenceUsage:      true, // no usage dump on error
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDelete(cmd, args, newClient)
@@ -41,7 +45,7 @@ No local files are deleted.
 	}
 
 	// Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -50,6 +54,7 @@ No local files are deleted.
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(fn.Function{}, false), "The namespace when deleting by name. ($FUNC_NAMESPACE)")
 	cmd.Flags().StringP("all", "a", "true", "Delete all resources created for a function, eg. Pipelines, Secrets, etc. ($FUNC_ALL) (allowed values: \"true\", \"false\")")
 	addConfirmFlag(cmd, cfg.Confirm)
+	addEnvironmentFlag(cmd)
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
@@ -65,26 +70,44 @@ func runDelete(cmd *cobra.Command, args []string, newClient ClientFactory) (err
 		return
 	}
 
-	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
-	defer done()
-
 	if cfg.Name != "" { // Delete by name if provided
+		client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
+		defer done()
+
 		return client.Remove(cmd.Context(), cfg.Name, cfg.Namespace, fn.Function{}, cfg.All)
 	} else { // Otherwise; delete the function at path (cwd by default)
 		f, err := fn.NewFunction(cfg.Path)
 		if err != nil {
 			return err
 		}
-		return client.Remove(cmd.Context(), "", "", f, cfg.All)
+		if f, err = f.WithEnvironment(cfg.Environment); err != nil {
+			return err
+		}
+
+		// The resources of remote builds are removed by the builder last used
+		client, done := newClient(ClientConfig{Verbose: cfg.Verbose, RemoteBuilder: f.Local.RemoteBuilder})
+		defer done()
+
+		if err = client.Remove(cmd.Context(), "", "", f, cfg.All); err != nil {
+			return err
+		}
+		if cfg.Environment != "" {
+			// The environment is no longer deployed
+			f.Deploy.Namespace = ""
+			f.Deploy.Image = ""
+			return f.Write()
+		}
+		return nil
 	}
 }
 
 type deleteConfig struct {
-	Name      string
-	Namespace string
-	Path      string
-	All       bool
-	Verbose   bool
+	Name        string
+	Namespace   string
+	Environment string
+	Path        string
+	All         bool
+	Verbose     bool
 }
 
 // newDeleteConfig returns a config populated from the current execution context
@@ -95,11 +118,12 @@ func newDeleteConfig(cmd *cobra.Command, args []string) (cfg deleteConfig, err e
 		name = args[0]
 	}
 	cfg = deleteConfig{
-		All:       viper.GetBool("all"),
-		Name:      name, // args[0] or derived
-		Namespace: viper.GetString("namespace"),
-		Path:      viper.GetString("path"),
-		Verbose:   viper.GetBool("verbose"), // defined on root
+		All:         viper.GetBool("all"),
+		Name:        name, // args[0] or derived
+		Namespace:   viper.GetString("namespace"),
+		Environment: viper.GetString("environment"),
+		Path:        viper.GetString("path"),
+		Verbose:     viper.GetBool("verbose"), // defined on root
 	}
 	if cfg.Name == "" && cmd.Flags().Changed("namespace") {
 		// logicially inconsistent to supply only a namespace.
@@ -113,6 +137,10 @@ func newDeleteConfig(cmd *cobra.Command, args []string) (cfg deleteConfig, err e
 		// a name and a namespace to ignore any local function source.
 		err = fmt.Errorf("only one of --path and [NAME] should be provided")
 	}
+	if cfg.Name != "" && cfg.Environment != "" {
+		// environments are defined by the function's local source.
+		err = fmt.Errorf("only one of --environment and [NAME] should be provided")
+	}
 	return
 }
 
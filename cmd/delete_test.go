@@ -63,6 +63,37 @@ func TestDelete_Default(t *testing.T) {
 	}
 }
 
+// TestDelete_RemoteBuilder ensures that the function is deleted with the
+// remote builder it was last deployed with, such that the resources of its
+// remote builds are removed.
+func TestDelete_RemoteBuilder(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry,
+		Deploy: fn.DeploySpec{Namespace: "testns"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Local.RemoteBuilder = "job"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	var remoteBuilder string
+	newClient := func(cfg ClientConfig, _ ...fn.Option) (*fn.Client, func()) {
+		remoteBuilder = cfg.RemoteBuilder
+		return fn.New(fn.WithRemover(mock.NewRemover())), func() {}
+	}
+	cmd := NewDeleteCmd(newClient)
+	cmd.SetArgs([]string{})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if remoteBuilder != "job" {
+		t.Fatalf("expected the job remote builder, got %q", remoteBuilder)
+	}
+}
+
 // TestDelete_ByName ensures that running delete specifying the name of the
 // function explicitly as an argument invokes the remover appropriately.
 func TestDelete_ByName(t *testing.T) {
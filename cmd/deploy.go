@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -17,8 +19,10 @@ import (
 
 	"knative.dev/func/pkg/builders"
 	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/deployers"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/pipelines"
 )
 
 func NewDeployCmd(newClient ClientFactory) *cobra.Command {
@@ -34,8 +38,8 @@ SYNOPSIS
 	             [-e|--env] [-g|--git-url] [-t|--git-branch] [-d|--git-dir]
 	             [-b|--build] [--builder] [--builder-image] [-p|--push]
 	             [--domain] [--platform] [--build-timestamp] [--pvc-size]
-	             [--service-account] [-c|--confirm] [-v|--verbose]
-	             [--registry-insecure]
+	             [--cache-pvc-size] [--remote-builder] [--service-account] [--environment] [--preflight]
+	             [-q|--quiet] [-c|--confirm] [-v|--verbose] [--registry-insecure]
 
 DESCRIPTION
 
@@ -87,6 +91,41 @@ DESCRIPTION
 	  selectors. Note that the domain specified must be one of those configured
 	  or the flag will be ignored.
 
+	Environments
+	  A function may define named environments (for example "staging" and
+	  "prod") in the 'environments' section of its func.yaml, each of which
+	  can override the namespace, registry, envs, volumes, options and labels
+	  of the function.  Use --environment to deploy to a named environment.
+	  The state of each environment's deployment is tracked separately.
+
+	Allow List
+	  The 'deploy.allowFrom' of func.yaml restricts the namespaces and
+	  functions from which the function may be reached on the knative
+	  platform.  It is enforced by a NetworkPolicy, which only restricts direct
+	  connections to the function's pods: requests routed by Knative reach them
+	  from its activator and gateways, so are allowed from any client, which
+	  is warned of when deploying.  Set 'deploy.allowFromPolicy' to "istio" to
+	  also restrict these with an Istio AuthorizationPolicy.  That policy is
+	  created in the istio-system namespace, which requires permissions
+	  usually granted only to cluster administrators.
+
+	Preflight
+	  The --preflight flag checks, before the function is deployed, that the
+	  cluster is reachable and has the required APIs installed, that the
+	  namespace exists and permits deploying, that the image can be pushed
+	  (unless --push=false) and pulled, and that the resources referenced by
+	  the function exist.  If any check fails the deployment is aborted
+	  without changing the cluster.  When deploying locally the checks are
+	  made before the function is built, except that the image can be pulled,
+	  which is checked once it is pushed.
+	  See '{{rootCmdUse}} doctor' to run the checks alone.
+
+	Remote Logs
+	  When deploying with --remote, the logs of each step of the pipeline are
+	  streamed as they are produced, prefixed by the names of the task and
+	  step.  Use --quiet to print only the name of each task as it starts.
+	  The logs of past runs are printed by '{{rootCmdUse}} pipeline logs'.
+
 EXAMPLES
 
 	o Deploy the function
@@ -104,6 +143,9 @@ EXAMPLES
 	  the final image name and target cluster namespace.
 	  $ {{rootCmdUse}} deploy --image ghcr.io/alice/myfunc --namespace myns
 
+	o Deploy the function to its "staging" environment, as defined in func.yaml
+	  $ {{rootCmdUse}} deploy --environment staging
+
 	o Deploy the current function's source code by sending it to the cluster to
 	  be built and deployed:
 	  $ {{rootCmdUse}} deploy --remote
@@ -120,6 +162,9 @@ EXAMPLES
 	  local filesystem.
 	  $ {{rootCmdUse}} deploy --build=false
 
+	o Deploy the function only if the preflight checks pass.
+	  $ {{rootCmdUse}} deploy --preflight
+
 	o Redeploy a function which has already been built and pushed. Works without
 	  the use of a local container engine.  For example, if the function was
 	  manually deleted from the cluster, it can be quickly redeployed with:
@@ -127,20 +172,23 @@ EXAMPLES
 
 `,
 		SuggestFor: []string{"delpoy", "deplyo"},
-		PreRunE:    bindEnv("build", "build-timestamp", "builder", "builder-image", "confirm", "domain", "env", "git-branch", "git-dir", "git-url", "image", "namespace", "path", "platform", "push", "pvc-size", "service-account", "registry", "registry-insecure", "remote", "username", "password", "token", "verbose"),
+		PreRunE:    bindEnv("build", "build-timeout", "build-timestamp", "builder", "builder-image", "cache-pvc-size", "confirm", "deployer", "domain", "env", "environment", "git-branch", "git-dir", "git-url", "image", "namespace", "path", "platform", "preflight", "push", "pvc-size", "quiet", "service-account", "registry", "registry-insecure", "remote", "remote-builder", "username", "password", "token", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDeploy(cmd, newClient)
 		},
 	}
 
 	// Global Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
 
 	// Function Context
+	// The requested environment, if any, is applied such that flag defaults
+	// reflect its settings.  Errors are reported when the command is run.
 	f, _ := fn.NewFunction(effectivePath())
+	f, _ = f.WithEnvironment(effectiveEnvironment())
 	if f.Initialized() {
 		cfg = cfg.Apply(f)
 	}
@@ -155,6 +203,10 @@ EXAMPLES
 	cmd.Flags().StringP("registry", "r", cfg.Registry,
 		"Container registry + registry namespace. (ex 'ghcr.io/myuser').  The full image name is automatically determined using this along with function name. ($FUNC_REGISTRY)")
 	cmd.Flags().Bool("registry-insecure", cfg.RegistryInsecure, "Skip TLS certificate verification when communicating in HTTPS with the registry ($FUNC_REGISTRY_INSECURE)")
+	cmd.Flags().String("platform", "",
+		"Optionally specify a specific platform to build for (e.g. linux/amd64).  Defaults to the platform of global config when using s2i. ($FUNC_PLATFORM)")
+	cmd.Flags().Duration("build-timeout", cfg.BuildTimeout,
+		"Maximum duration of the build, for example \"10m\".  Zero indicates no timeout. ($FUNC_BUILD_TIMEOUT)")
 
 	// Function-Context Flags:
 	// Options whose value is available on the function with context only
@@ -181,8 +233,14 @@ EXAMPLES
 		"Trigger a remote deployment. Default is to deploy and build from the local system ($FUNC_REMOTE)")
 	cmd.Flags().String("pvc-size", f.Build.PVCSize,
 		"When triggering a remote deployment, set a custom volume size to allocate for the build operation ($FUNC_PVC_SIZE)")
+	cmd.Flags().String("cache-pvc-size", f.Build.CachePVCSize,
+		"When triggering a remote deployment, set a custom volume size to allocate for the build caches, such as of Go modules, npm or Maven ($FUNC_CACHE_PVC_SIZE)")
+	cmd.Flags().String("remote-builder", defaultRemoteBuilder(f),
+		fmt.Sprintf("When triggering a remote deployment, run it with Tekton pipelines or, on clusters without Tekton, a Kubernetes Job. Supported remote builders are %s. ($FUNC_REMOTE_BUILDER)", strings.Join(pipelines.RemoteBuilders(), ", ")))
 	cmd.Flags().String("service-account", f.Deploy.ServiceAccountName,
 		"Service account to be used in the deployed function ($FUNC_SERVICE_ACCOUNT)")
+	cmd.Flags().String("deployer", f.Deploy.Platform,
+		fmt.Sprintf("Platform to which the function is deployed. Currently supported deployers are %s. Default is %q. ($FUNC_DEPLOYER)", KnownDeployers(), deployers.Default))
 	// Static Flags:
 	// Options which have static defaults only (not globally configurable nor
 	// persisted with the function)
@@ -191,14 +249,16 @@ EXAMPLES
 	cmd.Flags().Lookup("build").NoOptDefVal = "true" // register `--build` as equivalient to `--build=true`
 	cmd.Flags().BoolP("push", "u", true,
 		"Push the function image to registry before deploying. ($FUNC_PUSH)")
-	cmd.Flags().String("platform", "",
-		"Optionally specify a specific platform to build for (e.g. linux/amd64). ($FUNC_PLATFORM)")
 	cmd.Flags().StringP("username", "", "",
 		"Username to use when pushing to the registry.")
 	cmd.Flags().StringP("password", "", "",
 		"Password to use when pushing to the registry.")
 	cmd.Flags().StringP("token", "", "",
 		"Token to use when pushing to the registry.")
+	cmd.Flags().Bool("preflight", false,
+		"Check the function can be deployed before making any changes to the cluster, aborting if any check fails. ($FUNC_PREFLIGHT)")
+	cmd.Flags().BoolP("quiet", "q", false,
+		"Do not stream the logs of the remote pipeline, printing only the name of each task as it starts. ($FUNC_QUIET)")
 	cmd.Flags().BoolP("build-timestamp", "", false, "Use the actual time as the created time for the docker image. This is only useful for buildpacks builder.")
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(f, false),
 		"Deploy into a specific namespace. Will use the function's current namespace by default if already deployed, and the currently active context if it can be determined. ($FUNC_NAMESPACE)")
@@ -215,6 +275,7 @@ EXAMPLES
 
 	// Oft-shared flags:
 	addConfirmFlag(cmd, cfg.Confirm)
+	addEnvironmentFlag(cmd)
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
@@ -227,6 +288,10 @@ EXAMPLES
 		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
 	}
 
+	if err := cmd.RegisterFlagCompletionFunc("deployer", CompleteDeployerList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
 	return cmd
 }
 
@@ -247,9 +312,16 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	if !f.Initialized() {
 		return fn.NewErrNotInitialized(f.Root)
 	}
+	if f, err = f.WithEnvironment(cfg.Environment); err != nil {
+		return
+	}
 	if f, err = cfg.Configure(f); err != nil { // Updates f with deploy cfg
 		return
 	}
+	// Fail before deploying if flag changes can not be saved to the environment
+	if err = f.ValidateEnvironment(); err != nil {
+		return
+	}
 	cmd.SetContext(cfg.WithValues(cmd.Context())) // Some optional settings are passed via context
 
 	changingNamespace := func(f fn.Function) bool {
@@ -263,7 +335,7 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	// also update the registry because there is a registry per namespace,
 	// and their name includes the namespace.
 	// This saves needing a manual flag ``--registry={destination namespace registry}``
-	if changingNamespace(f) && k8s.IsOpenShift() {
+	if changingNamespace(f) && k8s.IsOpenShift(cmd.Context()) {
 		// TODO(lkingland): this appears to force use of the openshift
 		// internal registry.
 		f.Registry = "image-registry.openshift-image-registry.svc:5000/" + f.Namespace
@@ -281,12 +353,18 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	if err != nil {
 		return
 	}
-	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure}, clientOptions...)
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure, Quiet: cfg.Quiet, RemoteBuilder: cfg.RemoteBuilder}, clientOptions...)
 	defer done()
 
 	// Deploy
 	if cfg.Remote {
 		var url string
+		// The pipeline changes the cluster, so preflight before it is run
+		if cfg.Preflight {
+			if err = runPreflight(cmd.Context(), cmd, client, f); err != nil {
+				return
+			}
+		}
 		// Invoke a remote build/push/deploy pipeline
 		// Returned is the function with fields like Registry, f.Deploy.Image &
 		// f.Deploy.Namespace populated.
@@ -306,6 +384,19 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 			justPushed bool
 		)
 
+		// Preflight before building and pushing, leaving only the check that
+		// the pushed image can be pulled to be made when deploying.  The push
+		// credentials are not checked if the image is not pushed.
+		ctx := cmd.Context()
+		if cfg.Preflight {
+			preflightCtx := context.WithValue(ctx, fn.PreflightScopeKey{}, fn.PreflightBeforeBuild)
+			preflightCtx = context.WithValue(preflightCtx, fn.PreflightPushKey{}, cfg.Push)
+			if err = runPreflight(preflightCtx, cmd, client, f); err != nil {
+				return
+			}
+			ctx = context.WithValue(ctx, fn.PreflightScopeKey{}, fn.PreflightImage)
+		}
+
 		// Validate the image and check whether its digested or not
 		if cfg.Image != "" {
 			digested, err = isDigested(cfg.Image)
@@ -325,7 +416,7 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 			f.Deploy.Image = cfg.Image
 		} else {
 			// NOT digested, build & push the Function unless specified otherwise
-			if f, justBuilt, err = build(cmd, cfg.Build, f, client, buildOptions); err != nil {
+			if f, justBuilt, err = build(cmd, cfg.Build, f, client, buildOptions, cfg.buildConfig); err != nil {
 				return
 			}
 			if cfg.Push {
@@ -341,7 +432,9 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 				f.Deploy.Image = f.Build.Image
 			}
 		}
-		if f, err = client.Deploy(cmd.Context(), f, fn.WithDeploySkipBuildCheck(cfg.Build == "false")); err != nil {
+		if f, err = client.Deploy(ctx, f,
+			fn.WithDeploySkipBuildCheck(cfg.Build == "false"),
+			fn.WithDeployPreflight(cfg.Preflight)); err != nil {
 			return
 		}
 	}
@@ -364,19 +457,21 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 // message verbeage suitable for both Deploy and Run commands which feature an
 // optional build step. Boolean return value signifies if the image has gone
 // through a build process.
-func build(cmd *cobra.Command, flag string, f fn.Function, client *fn.Client, buildOptions []fn.BuildOption) (fn.Function, bool, error) {
+func build(cmd *cobra.Command, flag string, f fn.Function, client *fn.Client, buildOptions []fn.BuildOption, cfg buildConfig) (fn.Function, bool, error) {
 	var err error
+	ctx, cancel := cfg.buildContext(cmd.Context())
+	defer cancel()
 	if flag == "auto" {
 		if f.Built() {
 			fmt.Fprintln(cmd.OutOrStdout(), "function up-to-date. Force rebuild with --build")
 			return f, false, nil
 		} else {
-			if f, err = client.Build(cmd.Context(), f, buildOptions...); err != nil {
+			if f, err = client.Build(ctx, f, buildOptions...); err != nil {
 				return f, false, err
 			}
 		}
 	} else if build, _ := strconv.ParseBool(flag); build {
-		if f, err = client.Build(cmd.Context(), f, buildOptions...); err != nil {
+		if f, err = client.Build(ctx, f, buildOptions...); err != nil {
 			return f, false, err
 		}
 	} else if _, err = strconv.ParseBool(flag); err != nil {
@@ -446,6 +541,36 @@ func KnownBuilders() builders.Known {
 	return builders.All()
 }
 
+// ValidateDeployer ensures that the given deployer is one that the CLI
+// knows how to instantiate, returning a deployers.ErrUnknownDeployer
+// otherwise.  Empty indicates the default.
+func ValidateDeployer(name string) error {
+	if name == "" {
+		return nil
+	}
+	for _, known := range KnownDeployers() {
+		if name == known {
+			return nil
+		}
+	}
+	return deployers.ErrUnknownDeployer{Name: name, Known: KnownDeployers()}
+}
+
+// KnownDeployers are a typed string slice of deployer short names (the
+// platforms to which a function may be deployed) which this CLI understands.
+func KnownDeployers() deployers.Known {
+	return deployers.All()
+}
+
+// defaultRemoteBuilder returns the remote builder last used by the function,
+// or Tekton.
+func defaultRemoteBuilder(f fn.Function) string {
+	if f.Local.RemoteBuilder != "" {
+		return f.Local.RemoteBuilder
+	}
+	return pipelines.DefaultRemoteBuilder
+}
+
 type deployConfig struct {
 	buildConfig // further embeds config.Global
 
@@ -454,9 +579,17 @@ type deployConfig struct {
 	// 'true', 'false, '1' or '0'.
 	Build string
 
+	// Deployer is the platform to which the function is deployed, such as
+	// "knative" or "kubernetes".  Empty indicates the default.
+	Deployer string
+
 	// Env variables.  May include removals using a "-"
 	Env []string
 
+	// Environment is the name of the function's environment to which it
+	// should be deployed.  Empty indicates the function's own settings.
+	Environment string
+
 	// Domain to use for the function's route.  Default is to let the cluster
 	// apply its default.  If configured to use domain matching, the given domain
 	// will be used.  This configuration, in short, is to configure the
@@ -520,6 +653,20 @@ type deployConfig struct {
 	// PVCSize configures the PVC size used by the pipeline if --remote flag is set.
 	PVCSize string
 
+	// CachePVCSize configures the size of the PVC holding the build caches
+	// if --remote flag is set.
+	CachePVCSize string
+
+	// RemoteBuilder runs the remote deployment: Tekton or a Kubernetes Job.
+	RemoteBuilder string
+
+	// Preflight checks the function can be deployed before making changes
+	// to the cluster.
+	Preflight bool
+
+	// Quiet disables the streaming of the remote pipeline's logs.
+	Quiet bool
+
 	// Timestamp the built contaienr with the current date and time.
 	// This is currently only supported by the Pack builder.
 	Timestamp bool
@@ -531,7 +678,9 @@ func newDeployConfig(cmd *cobra.Command) deployConfig {
 	cfg := deployConfig{
 		buildConfig:        newBuildConfig(),
 		Build:              viper.GetString("build"),
+		Deployer:           viper.GetString("deployer"),
 		Env:                viper.GetStringSlice("env"),
+		Environment:        viper.GetString("environment"),
 		Domain:             viper.GetString("domain"),
 		GitBranch:          viper.GetString("git-branch"),
 		GitDir:             viper.GetString("git-dir"),
@@ -539,6 +688,10 @@ func newDeployConfig(cmd *cobra.Command) deployConfig {
 		Namespace:          viper.GetString("namespace"),
 		Remote:             viper.GetBool("remote"),
 		PVCSize:            viper.GetString("pvc-size"),
+		CachePVCSize:       viper.GetString("cache-pvc-size"),
+		RemoteBuilder:      viper.GetString("remote-builder"),
+		Preflight:          viper.GetBool("preflight"),
+		Quiet:              viper.GetBool("quiet"),
 		Timestamp:          viper.GetBool("build-timestamp"),
 		ServiceAccountName: viper.GetString("service-account"),
 	}
@@ -574,7 +727,12 @@ func (c deployConfig) Configure(f fn.Function) (fn.Function, error) {
 	f.Build.Git.ContextDir = c.GitDir
 	f.Build.Git.Revision = c.GitBranch // TODO: should match; perhaps "refSpec"
 	f.Deploy.ServiceAccountName = c.ServiceAccountName
+	f.Deploy.Platform = c.Deployer
 	f.Local.Remote = c.Remote
+	f.Local.RemoteBuilder = c.RemoteBuilder
+	if c.RemoteBuilder == pipelines.DefaultRemoteBuilder {
+		f.Local.RemoteBuilder = ""
+	}
 
 	// PVCSize
 	// If a specific value is requested, ensure it parses as a resource.Quantity
@@ -584,11 +742,17 @@ func (c deployConfig) Configure(f fn.Function) (fn.Function, error) {
 		}
 		f.Build.PVCSize = c.PVCSize
 	}
+	if c.CachePVCSize != "" {
+		if _, err = resource.ParseQuantity(c.CachePVCSize); err != nil {
+			return f, fmt.Errorf("cannot parse cache PVC size %q. %w", c.CachePVCSize, err)
+		}
+		f.Build.CachePVCSize = c.CachePVCSize
+	}
 
 	// Envs
 	// Preprocesses any Envs provided (which may include removals) into a final
 	// set
-	f.Run.Envs, err = applyEnvs(f.Run.Envs, c.Env)
+	f.Run.Envs, err = applyEnvs(f.Root, f.Run.Envs, c.Env)
 	if err != nil {
 		return f, err
 	}
@@ -604,9 +768,23 @@ func (c deployConfig) Configure(f fn.Function) (fn.Function, error) {
 	return f, nil
 }
 
+// clientOptions returns those of the build config plus the deployer of the
+// chosen platform.
+func (c deployConfig) clientOptions() ([]fn.Option, error) {
+	o, err := c.buildConfig.clientOptions()
+	if err != nil {
+		return o, err
+	}
+	d, err := newDeployer(c.Deployer, c.Verbose)
+	if err != nil {
+		return o, err
+	}
+	return append(o, fn.WithDeployer(d)), nil
+}
+
 // Apply Env additions/removals to a set of extant envs, returning the final
 // merged list.
-func applyEnvs(current []fn.Env, args []string) (final []fn.Env, err error) {
+func applyEnvs(root string, current []fn.Env, args []string) (final []fn.Env, err error) {
 	// TODO: validate env test cases completely validate this functionality
 
 	// Parse and Merge
@@ -614,7 +792,7 @@ func applyEnvs(current []fn.Env, args []string) (final []fn.Env, err error) {
 	if err != nil {
 		return
 	}
-	final, _, err = mergeEnvs(current, inserts, removals)
+	final, _, err = mergeEnvs(root, current, inserts, removals)
 	return
 }
 
@@ -680,6 +858,23 @@ func (c deployConfig) Validate(cmd *cobra.Command) (err error) {
 		return
 	}
 
+	// Deployer value must refer to a known deployer short name
+	if err = ValidateDeployer(c.Deployer); err != nil {
+		return
+	}
+
+	// The DNS provider of global config, if any, must be valid, such that the
+	// function's domains are published once it is deployed.
+	if _, err = newDNSProvider(); err != nil {
+		return
+	}
+
+	// The docker deployer targets the local container engine, which a remote
+	// build on cluster can not reach.
+	if c.Deployer == deployers.Docker && c.Remote {
+		return errors.New("the docker deployer can not be used with --remote")
+	}
+
 	// Check Image Digest was included
 	var digest bool
 	if c.Image != "" {
@@ -718,6 +913,13 @@ func (c deployConfig) Validate(cmd *cobra.Command) (err error) {
 		return errors.New("git settings (--git-url --git-dir and --git-branch) are only applicable when triggering remote deployments (--remote)")
 	}
 
+	if !slices.Contains(pipelines.RemoteBuilders(), c.RemoteBuilder) {
+		return fmt.Errorf("unrecognized value for --remote-builder %q. Accepts %s", c.RemoteBuilder, strings.Join(pipelines.RemoteBuilders(), ", "))
+	}
+	if !c.Remote && cmd.Flags().Changed("remote-builder") {
+		return errors.New("the remote builder (--remote-builder) is only applicable when triggering remote deployments (--remote)")
+	}
+
 	// Git URL can contain at maximum one '#'
 	urlParts := strings.Split(c.GitURL, "#")
 	if len(urlParts) > 2 {
@@ -757,7 +959,7 @@ func printDeployMessages(out io.Writer, f fn.Function) {
 	// If the target namespace is provided but differs from active, warn because
 	// the function won't be visible to other commands such as kubectl unless
 	// context namespace is switched.
-	activeNamespace, err := k8s.GetDefaultNamespace()
+	activeNamespace, err := k8s.GetDefaultNamespace(kubeContext())
 	if err == nil && targetNamespace != "" && targetNamespace != activeNamespace {
 		fmt.Fprintf(out, "Warning: namespace chosen is '%s', but currently active namespace is '%s'. Continuing with deployment to '%s'.\n", targetNamespace, activeNamespace, targetNamespace)
 	}
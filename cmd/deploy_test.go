@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/deployers"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/mock"
@@ -136,6 +137,47 @@ func testBuilderValidated(cmdFn commandConstructor, t *testing.T) {
 
 }
 
+// TestDeploy_DeployerPersists ensures the --deployer flag is validated and
+// persisted as the function's platform, and is retained on subsequent deploys.
+func TestDeploy_DeployerPersists(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewDeployCmd(NewTestClient(fn.WithRegistry(TestRegistry)))
+	cmd.SetArgs([]string{"--deployer=invalid"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error using an invalid --deployer not received")
+	}
+
+	viper.Reset()
+	cmd.SetArgs([]string{"--deployer", deployers.Kubernetes})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Platform != deployers.Kubernetes {
+		t.Fatalf("expected platform %q, got %q", deployers.Kubernetes, f.Deploy.Platform)
+	}
+
+	viper.Reset()
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Platform != deployers.Kubernetes {
+		t.Fatalf("platform not retained when --deployer not provided, got %q", f.Deploy.Platform)
+	}
+}
+
 // TestDeploy_ConfigApplied ensures that the deploy command applies config
 // settings at each level (static, global, function, envs, flags)
 func TestDeploy_ConfigApplied(t *testing.T) {
@@ -361,6 +403,34 @@ func testDefault(cmdFn commandConstructor, t *testing.T) {
 	}
 }
 
+// TestDeploy_PreflightBeforeBuild ensures that a failed preflight check
+// aborts a local deployment before the function is built.
+func TestDeploy_PreflightBeforeBuild(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		builder     = mock.NewBuilder()
+		preflighter = mock.NewPreflighter()
+	)
+	preflighter.PreflightFn = func(fn.Function) (report fn.PreflightReport, err error) {
+		report.Add("Namespace", fn.PreflightFail, "not found")
+		return
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithBuilder(builder), fn.WithPreflighter(preflighter)))
+	cmd.SetArgs([]string{"--preflight"})
+	if err = cmd.Execute(); !errors.Is(err, fn.ErrPreflightFailed) {
+		t.Fatalf("expected the preflight to fail, got %v", err)
+	}
+	if builder.BuildInvoked {
+		t.Fatal("expected the function not to be built")
+	}
+}
+
 // TestDeploy_Envs ensures that environment variable for the function, provided
 // as arguments, are correctly evaluated.  This includes:
 // - Multiple Envs are supported (flag can be provided multiple times)
@@ -1052,6 +1122,137 @@ func TestDeploy_Namespace(t *testing.T) {
 	}
 }
 
+// TestDeploy_Environment ensures that deploying to a named environment uses
+// that environment's settings and records its deployment state separately
+// from the function's own.
+func TestDeploy_Environment(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Runtime: "go", Registry: TestRegistry}
+	f, err := fn.New().Init(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = map[string]fn.Environment{
+		"staging": {Namespace: "staging"},
+	}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deploy to the default environment
+	deployer := mock.NewDeployer()
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deploy to the staging environment
+	t.Setenv("FUNC_ENVIRONMENT", "staging")
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (result fn.DeploymentResult, err error) {
+		if f.Environment != "staging" {
+			t.Errorf("expected environment 'staging' applied, got %q", f.Environment)
+		}
+		result.Namespace = f.Namespace
+		return
+	}
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, _ = fn.NewFunction(root)
+	if f.Deploy.Namespace != "func" {
+		t.Errorf("expected the default deployment to remain in 'func', got %q", f.Deploy.Namespace)
+	}
+	if ns := f.Environments["staging"].Deployed.Namespace; ns != "staging" {
+		t.Errorf("expected staging deployment namespace 'staging', got %q", ns)
+	}
+
+	// Envs given with an environment applied are saved to that environment
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--env", "FOO=bar"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = fn.NewFunction(root)
+	if len(f.Run.Envs) != 0 {
+		t.Errorf("expected the function envs to be unchanged, got %v", f.Run.Envs)
+	}
+	if envs := f.Environments["staging"].Envs; len(envs) != 1 || *envs[0].Name != "FOO" {
+		t.Errorf("expected FOO saved to the staging environment, got %v", envs)
+	}
+
+	// Removing an env of the function with an environment applied is an error
+	// raised before deploying
+	name, value := "BAR", "baz"
+	f.Run.Envs = fn.Envs{{Name: &name, Value: &value}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (result fn.DeploymentResult, err error) {
+		t.Error("expected the function not to be deployed")
+		return
+	}
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--env", "BAR-"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected an error removing an env with an environment applied")
+	}
+
+	// Unknown environments are an error
+	t.Setenv("FUNC_ENVIRONMENT", "prod")
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); !errors.Is(err, fn.ErrEnvironmentNotFound) {
+		t.Errorf("expected ErrEnvironmentNotFound, got %v", err)
+	}
+}
+
+// TestDeploy_EnvironmentRemote ensures that a remote deployment to a named
+// environment runs the pipeline with that environment applied, and records
+// its deployment state to the environment.
+func TestDeploy_EnvironmentRemote(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Runtime: "go", Registry: TestRegistry}
+	f, err := fn.New().Init(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = map[string]fn.Environment{
+		"staging": {Namespace: "staging"},
+	}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	pipeliner := mock.NewPipelinesProvider()
+	pipeliner.RunFn = func(f fn.Function) (string, fn.Function, error) {
+		if f.Environment != "staging" {
+			t.Errorf("expected environment 'staging' applied, got %q", f.Environment)
+		}
+		f.Deploy.Namespace = f.Namespace
+		return "", f, nil
+	}
+	t.Setenv("FUNC_ENVIRONMENT", "staging")
+	cmd := NewDeployCmd(NewTestClient(fn.WithPipelinesProvider(pipeliner)))
+	cmd.SetArgs([]string{"--remote"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !pipeliner.RunInvoked {
+		t.Fatal("expected the pipeline to be run")
+	}
+
+	f, _ = fn.NewFunction(root)
+	if ns := f.Environments["staging"].Deployed.Namespace; ns != "staging" {
+		t.Errorf("expected staging deployment namespace 'staging', got %q", ns)
+	}
+}
+
 // TestDeploy_NamespaceDefaultsToK8sContext ensures that when not specified, a
 // users's active kubernetes context is used for the namespace if available.
 func TestDeploy_NamespaceDefaultsToK8sContext(t *testing.T) {
@@ -1215,7 +1416,7 @@ func TestDeploy_NamespaceUpdateWarning(t *testing.T) {
 
 	time.Sleep(1 * time.Second)
 
-	activeNamespace, err := k8s.GetDefaultNamespace()
+	activeNamespace, err := k8s.GetDefaultNamespace(context.Background())
 	if err != nil {
 		t.Fatalf("Couldnt get active namespace, got error: %v", err)
 	}
@@ -2171,3 +2372,56 @@ func Test_isDigested(t *testing.T) {
 		t.Fatal("did not report image reference has digest")
 	}
 }
+
+// TestDeploy_RemoteBuilder ensures the --remote-builder flag is validated,
+// and is persisted such that subsequent remote deploys use it.
+func TestDeploy_RemoteBuilder(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewDeployCmd(NewTestClient(fn.WithRegistry(TestRegistry)))
+	cmd.SetArgs([]string{"--remote", "--remote-builder=invalid"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for an invalid remote builder")
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithRegistry(TestRegistry)))
+	cmd.SetArgs([]string{"--remote-builder=job"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for a remote builder without --remote")
+	}
+
+	var cfg ClientConfig
+	newClient := func(c ClientConfig, _ ...fn.Option) (*fn.Client, func()) {
+		cfg = c
+		return fn.New(fn.WithRegistry(TestRegistry)), func() {}
+	}
+	cmd = NewDeployCmd(newClient)
+	cmd.SetArgs([]string{"--remote", "--remote-builder=job"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RemoteBuilder != "job" {
+		t.Fatalf("expected the job remote builder, got %q", cfg.RemoteBuilder)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Local.RemoteBuilder != "job" {
+		t.Fatalf("value of remote builder flag not persisted")
+	}
+
+	// Deploy again without the flag: the persisted value is used
+	cmd = NewDeployCmd(newClient)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RemoteBuilder != "job" {
+		t.Fatalf("expected the persisted remote builder, got %q", cfg.RemoteBuilder)
+	}
+}
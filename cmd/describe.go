@@ -30,19 +30,22 @@ the current directory or from the directory specified with --path.
 
 # Show the details of the function in the directory with yaml output
 {{rootCmdUse}} describe --output yaml --path myotherfunc
+
+# Show the details of the function as deployed to its "staging" environment
+{{rootCmdUse}} describe --environment staging
 `,
 		SuggestFor: []string{"ifno", "fino", "get"},
 
 		ValidArgsFunction: CompleteFunctionList,
 		Aliases:           []string{"info", "desc"},
-		PreRunE:           bindEnv("output", "path", "namespace", "verbose"),
+		PreRunE:           bindEnv("output", "path", "namespace", "environment", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDescribe(cmd, args, newClient)
 		},
 	}
 
 	// Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -50,6 +53,7 @@ the current directory or from the directory specified with --path.
 	// Flags
 	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|xml|yaml|url) ($FUNC_OUTPUT)")
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(fn.Function{}, false), "The namespace in which to look for the named function. ($FUNC_NAMESPACE)")
+	addEnvironmentFlag(cmd)
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
@@ -81,6 +85,9 @@ func runDescribe(cmd *cobra.Command, args []string, newClient ClientFactory) (er
 		if err != nil {
 			return err
 		}
+		if f, err = f.WithEnvironment(cfg.Environment); err != nil {
+			return err
+		}
 		details, err = client.Describe(cmd.Context(), "", "", f)
 		if err != nil {
 			return err
@@ -95,11 +102,12 @@ func runDescribe(cmd *cobra.Command, args []string, newClient ClientFactory) (er
 // ------------------------------
 
 type describeConfig struct {
-	Name      string
-	Namespace string
-	Output    string
-	Path      string
-	Verbose   bool
+	Name        string
+	Namespace   string
+	Environment string
+	Output      string
+	Path        string
+	Verbose     bool
 }
 
 func newDescribeConfig(cmd *cobra.Command, args []string) (cfg describeConfig, err error) {
@@ -108,11 +116,12 @@ func newDescribeConfig(cmd *cobra.Command, args []string) (cfg describeConfig, e
 		name = args[0]
 	}
 	cfg = describeConfig{
-		Name:      name,
-		Namespace: viper.GetString("namespace"),
-		Output:    viper.GetString("output"),
-		Path:      viper.GetString("path"),
-		Verbose:   viper.GetBool("verbose"),
+		Name:        name,
+		Namespace:   viper.GetString("namespace"),
+		Environment: viper.GetString("environment"),
+		Output:      viper.GetString("output"),
+		Path:        viper.GetString("path"),
+		Verbose:     viper.GetBool("verbose"),
 	}
 	if cfg.Name == "" && cmd.Flags().Changed("namespace") {
 		// logicially inconsistent to supply only a namespace.
@@ -126,6 +135,10 @@ func newDescribeConfig(cmd *cobra.Command, args []string) (cfg describeConfig, e
 		// a name and a namespace to ignore any local function source.
 		err = fmt.Errorf("only one of --path and [NAME] should be provided")
 	}
+	if cfg.Name != "" && cfg.Environment != "" {
+		// environments are defined by the function's local source.
+		err = fmt.Errorf("only one of --environment and [NAME] should be provided")
+	}
 	return
 }
 
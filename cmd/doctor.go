@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewDoctorCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check a function can be deployed",
+		Long: `Check a function can be deployed
+
+Checks, without making any changes, that the function in the current
+directory or at --path can be deployed, printing a report of each check:
+
+  - the cluster of the current kubeconfig context is reachable
+  - Knative Serving, and Knative Eventing or Tekton Pipelines where needed by
+    the function, are installed
+  - the namespace exists, and permits the user to deploy the function
+  - the resources referenced by the function exist in the namespace
+  - the function's allow list, if any, restricts requests routed by Knative,
+    which a NetworkPolicy alone does not (see 'deploy.allowFromPolicy')
+  - credentials permitting the function's image to be pushed are found,
+    unless the function is deployed to the local container engine (docker)
+  - the function's deployed image can be pulled with the image pull secrets
+    of its service account
+
+If the current directory is not a function, only the cluster is checked.
+The command fails if any check fails.  The same checks may be made before
+deploying with '{{rootCmdUse}} deploy --preflight'.
+`,
+		Example: `
+# Check the function in the current directory can be deployed
+{{rootCmdUse}} doctor
+
+# Check the function can be deployed remotely to the "staging" namespace
+{{rootCmdUse}} doctor --remote --namespace staging
+`,
+		SuggestFor: []string{"docter", "check", "preflight"},
+		PreRunE:    bindEnv("environment", "namespace", "output", "path", "remote", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(cmd, newClient)
+		},
+	}
+
+	cfg, err := newGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	f, _ := fn.NewFunction(effectivePath())
+	f, _ = f.WithEnvironment(effectiveEnvironment())
+
+	cmd.Flags().StringP("namespace", "n", defaultNamespace(f, false),
+		"Namespace to which the function would be deployed. ($FUNC_NAMESPACE)")
+	cmd.Flags().BoolP("remote", "R", f.Local.Remote,
+		"Check the function can be deployed remotely, with a Tekton pipeline. ($FUNC_REMOTE)")
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|xml|yaml) ($FUNC_OUTPUT)")
+	addEnvironmentFlag(cmd)
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	if err := cmd.RegisterFlagCompletionFunc("output", CompleteOutputFormatList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	var (
+		path        = viper.GetString("path")
+		environment = viper.GetString("environment")
+		output      = viper.GetString("output")
+	)
+	if Format(output) == URL {
+		return fmt.Errorf("the url output format is not supported by doctor")
+	}
+	f, err := fn.NewFunction(path)
+	if err != nil {
+		return
+	}
+	if f.Initialized() {
+		if f, err = f.WithEnvironment(environment); err != nil {
+			return
+		}
+	}
+	f.Namespace = viper.GetString("namespace")
+	f.Local.Remote = viper.GetBool("remote")
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose")})
+	defer done()
+
+	report, err := client.Preflight(cmd.Context(), f)
+	if err != nil {
+		return
+	}
+	write(cmd.OutOrStdout(), doctorReport(report), output)
+	if report.Failed() {
+		return fn.ErrPreflightFailed
+	}
+	return
+}
+
+// runPreflight of the function, printing the report and failing if any check
+// failed.
+func runPreflight(ctx context.Context, cmd *cobra.Command, client *fn.Client, f fn.Function) error {
+	report, err := client.Preflight(ctx, f)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), report)
+	if report.Failed() {
+		return fn.ErrPreflightFailed
+	}
+	return nil
+}
+
+// Output Formatting (serializers)
+// -------------------------------
+
+type doctorReport fn.PreflightReport
+
+func (r doctorReport) Human(w io.Writer) error {
+	_, err := fmt.Fprint(w, fn.PreflightReport(r))
+	return err
+}
+
+func (r doctorReport) Plain(w io.Writer) error {
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\n", "CHECK", "STATUS", "MESSAGE")
+	for _, c := range r.Checks {
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\n", c.Name, c.Status, c.Message)
+	}
+	return nil
+}
+
+func (r doctorReport) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+func (r doctorReport) XML(w io.Writer) error {
+	return xml.NewEncoder(w).Encode(r)
+}
+
+func (r doctorReport) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(r)
+}
+
+func (r doctorReport) URL(w io.Writer) error {
+	return fmt.Errorf("the url output format is not supported by doctor")
+}
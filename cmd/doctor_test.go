@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestDoctor ensures the preflight checks of the function are run with the
+// requested namespace, their report is printed, and the command fails if
+// any check failed.
+func TestDoctor(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	preflighter := mock.NewPreflighter()
+	preflighter.PreflightFn = func(f fn.Function) (report fn.PreflightReport, err error) {
+		if f.Namespace != "staging" {
+			t.Fatalf("expected namespace %q, got %q", "staging", f.Namespace)
+		}
+		report.Add("Kubernetes cluster", fn.PreflightPass, "reachable")
+		report.Add("Namespace", fn.PreflightFail, "%q does not exist", f.Namespace)
+		return
+	}
+
+	var out bytes.Buffer
+	cmd := NewDoctorCmd(NewTestClient(fn.WithPreflighter(preflighter)))
+	cmd.SetArgs([]string{"--namespace", "staging"})
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); !errors.Is(err, fn.ErrPreflightFailed) {
+		t.Fatalf("expected ErrPreflightFailed, got %v", err)
+	}
+	if !preflighter.PreflightInvoked {
+		t.Fatal("preflight checks were not run")
+	}
+	if !strings.Contains(out.String(), "Kubernetes cluster: reachable") ||
+		!strings.Contains(out.String(), `Namespace: "staging" does not exist`) {
+		t.Fatalf("unexpected report:\n%v", out.String())
+	}
+}
@@ -42,7 +42,7 @@ DESCRIPTION
 			return runEnvironment(cmd, newClient, version)
 		},
 	}
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -103,14 +103,14 @@ func runEnvironment(cmd *cobra.Command, newClient ClientFactory, v *Version) (er
 	}
 
 	// Get global defaults
-	defaults, err := config.NewDefault()
+	defaults, err := newGlobalConfig()
 	if err != nil {
 		return
 	}
 
 	// Gets the cluster host
 	var host string
-	cc, err := k8s.GetClientConfig().ClientConfig()
+	cc, err := k8s.GetClientConfig(cmd.Context()).ClientConfig()
 	if err != nil {
 		fmt.Printf("error getting client config %v\n", err)
 	} else {
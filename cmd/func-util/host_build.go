@@ -0,0 +1,109 @@
+//go:build exclude_graphdriver_btrfs || !cgo
+// +build exclude_graphdriver_btrfs !cgo
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci"
+)
+
+func hostBuild(ctx context.Context) error {
+	cmd := newHostBuildCmd()
+	err := cmd.ExecuteContext(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot host build: %w", err)
+	}
+	return nil
+}
+
+type hostBuildConfig struct {
+	pathContext string
+	image       string
+	registry    string
+	digestFile  string
+	insecure    bool
+	envVars     []string
+}
+
+func newHostBuildCmd() *cobra.Command {
+	var config hostBuildConfig
+
+	buildCmd := &cobra.Command{
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config.envVars = args
+			return runHostBuild(cmd.Context(), config)
+		},
+	}
+	buildCmd.Flags().StringVar(&config.pathContext, "path-context", ".", "")
+	buildCmd.Flags().StringVar(&config.image, "image", "", "")
+	buildCmd.Flags().StringVar(&config.registry, "registry", "", "")
+	buildCmd.Flags().StringVar(&config.digestFile, "digest-file", "", "")
+	buildCmd.Flags().BoolVar(&config.insecure, "insecure", false, "")
+
+	return buildCmd
+}
+
+// runHostBuild builds the function with the host (OCI) builder and pushes
+// it, writing the digest of the pushed image to the digest file.  The Go
+// toolchain is located using FUNC_GO_PATH, and registry credentials are read
+// from the config.json in DOCKER_CONFIG.
+func runHostBuild(ctx context.Context, c hostBuildConfig) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot get working directory: %w", err)
+	}
+
+	f, err := fn.NewFunction(filepath.Join(wd, c.pathContext))
+	if err != nil {
+		return fmt.Errorf("cannot load function: %w", err)
+	}
+	if c.registry != "" {
+		f.Registry = c.registry
+	}
+	if c.image != "" {
+		f.Build.Image = c.image
+	}
+	if f.Build.Image == "" {
+		if f.Build.Image, err = f.ImageName(); err != nil {
+			return fmt.Errorf("cannot determine image name: %w", err)
+		}
+	}
+
+	// Build environment variables are passed to the language toolchain
+	// through the environment of this process.
+	for _, e := range c.envVars {
+		name, value, _ := strings.Cut(e, "=")
+		if name == "" {
+			continue
+		}
+		if err = os.Setenv(name, value); err != nil {
+			return fmt.Errorf("cannot set build environment variable %q: %w", name, err)
+		}
+	}
+
+	if err = oci.NewBuilder(builders.Host, true).Build(ctx, f, nil); err != nil {
+		return fmt.Errorf("cannot build: %w", err)
+	}
+
+	digest, err := oci.NewPusher(c.insecure, false, true).Push(ctx, f)
+	if err != nil {
+		return fmt.Errorf("cannot push: %w", err)
+	}
+
+	if c.digestFile != "" {
+		if err = os.WriteFile(c.digestFile, []byte(digest), 0644); err != nil {
+			return fmt.Errorf("cannot write image digest: %w", err)
+		}
+	}
+	return nil
+}
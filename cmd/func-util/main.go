@@ -11,6 +11,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"slices"
+	"strings"
 	"syscall"
 
 	"golang.org/x/sys/unix"
@@ -19,6 +20,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"knative.dev/func/pkg/builders/s2i"
+	"knative.dev/func/pkg/deployers"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/knative"
@@ -41,6 +43,12 @@ func main() {
 
 	var cmd func(context.Context) error = unknown
 
+	// The command is selected by the name of the symlink with which the
+	// binary was invoked, or by the first argument if invoked directly.
+	if filepath.Base(os.Args[0]) == "func-util" && len(os.Args) > 1 {
+		os.Args = os.Args[1:]
+	}
+
 	switch filepath.Base(os.Args[0]) {
 	case "deploy":
 		cmd = deploy
@@ -54,6 +62,10 @@ func main() {
 		cmd = sh
 	case "s2i-generate":
 		cmd = s2iGenerate
+	case "host-build":
+		cmd = hostBuild
+	case "sync":
+		cmd = syncCmd
 	}
 
 	err := cmd(ctx)
@@ -124,10 +136,6 @@ func s2iCmd(ctx context.Context) error {
 
 func deploy(ctx context.Context) error {
 	var err error
-	deployer := knative.NewDeployer(
-		knative.WithDeployerVerbose(true),
-		knative.WithDeployerDecorator(deployDecorator{}))
-
 	var root string
 	if len(os.Args) > 1 {
 		root = os.Args[1]
@@ -142,12 +150,42 @@ func deploy(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("cannot load function: %w", err)
 	}
+	// The named environment deployed to (by a remote deployment) is applied
+	// as it is by the CLI.
+	if f, err = f.WithEnvironment(os.Getenv("FUNC_ENVIRONMENT")); err != nil {
+		return fmt.Errorf("cannot apply the environment: %w", err)
+	}
 	if len(os.Args) > 2 {
 		f.Deploy.Image = os.Args[2]
 	}
 	if f.Deploy.Image == "" {
 		f.Deploy.Image = f.Image
 	}
+	// The digest of an image built by a previous step (of a remote build
+	// Job) is read from the file named by FUNC_DIGEST_FILE.
+	if digestFile := os.Getenv("FUNC_DIGEST_FILE"); digestFile != "" {
+		digest, err := os.ReadFile(digestFile)
+		if err != nil {
+			return fmt.Errorf("cannot read the image digest: %w", err)
+		}
+		f.Deploy.Image = f.Deploy.Image + "@" + strings.TrimSpace(string(digest))
+	}
+
+	var deployer fn.Deployer
+	switch f.Deploy.Platform {
+	case "", deployers.Knative:
+		deployer = knative.NewDeployer(
+			knative.WithDeployerVerbose(true),
+			knative.WithDeployerDecorator(deployDecorator{}))
+	case deployers.Kubernetes:
+		deployer = k8s.NewDeployer(
+			k8s.WithDeployerVerbose(true),
+			k8s.WithDeployerDecorator(deployDecorator{}))
+	default:
+		// The docker deployer targets a local container engine, so is not
+		// available on cluster.
+		return deployers.ErrUnknownDeployer{Name: f.Deploy.Platform, Known: deployers.Known{deployers.Knative, deployers.Kubernetes}}
+	}
 
 	res, err := deployer.Deploy(ctx, f)
 	if err != nil {
@@ -163,14 +201,14 @@ type deployDecorator struct {
 }
 
 func (d deployDecorator) UpdateAnnotations(function fn.Function, annotations map[string]string) map[string]string {
-	if k8s.IsOpenShift() {
+	if k8s.IsOpenShift(context.Background()) {
 		return d.oshDec.UpdateAnnotations(function, annotations)
 	}
 	return annotations
 }
 
 func (d deployDecorator) UpdateLabels(function fn.Function, labels map[string]string) map[string]string {
-	if k8s.IsOpenShift() {
+	if k8s.IsOpenShift(context.Background()) {
 		return d.oshDec.UpdateLabels(function, labels)
 	}
 	return labels
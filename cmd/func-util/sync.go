@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+
+	"knative.dev/func/pkg/filesync"
+)
+
+// syncCmd applies an incremental sync of the function's sources to the
+// working directory (the mounted volume).
+//
+// The client first sends a newline, upon which the manifest of the last sync
+// is written to stdout.  The manifest is not written before, as it would be
+// lost were the client not yet attached.  The client then sends the stream of
+// changes, which is applied.
+func syncCmd(ctx context.Context) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("cannot get working directory: %w", err)
+	}
+
+	unix.Umask(0)
+
+	m, err := filesync.ReadManifest(wd)
+	if err != nil {
+		// A manifest which cannot be read is that of no sync: all is sent.
+		fmt.Fprintf(os.Stderr, "Warning: cannot read the manifest of the last sync: %v\n", err)
+		m = filesync.Manifest{}
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	if _, err = in.ReadString('\n'); err != nil {
+		return fmt.Errorf("cannot read the request of the manifest: %w", err)
+	}
+	if err = json.NewEncoder(os.Stdout).Encode(m); err != nil {
+		return fmt.Errorf("cannot write the manifest: %w", err)
+	}
+
+	return filesync.Apply(in, wd)
+}
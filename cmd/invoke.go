@@ -24,7 +24,7 @@ NAME
 	{{rootCmdUse}} invoke - test a function by invoking it with test data
 
 SYNOPSIS
-	{{rootCmdUse}} invoke [-t|--target] [-f|--format]
+	{{rootCmdUse}} invoke [-t|--target] [--environment] [-f|--format]
 	             [--id] [--source] [--type] [--data] [--file] [--content-type]
 	             [-s|--save] [-p|--path] [-i|--insecure] [-c|--confirm] [-v|--verbose]
 
@@ -51,6 +51,8 @@ DESCRIPTION
 	  local function instance is chosen if running (see {{rootCmdUse}} run).
 	  To explicitly target the remote (deployed) function:
 	    {{rootCmdUse}} invoke --target=remote
+	  To target the function as deployed to one of its named environments:
+	    {{rootCmdUse}} invoke --environment=staging
 	  To target an arbitrary endpoint, provide a URL:
 	    {{rootCmdUse}} invoke --target=https://myfunction.example.com
 
@@ -102,21 +104,21 @@ EXAMPLES
 
 `,
 		SuggestFor: []string{"emit", "emti", "send", "emit", "exec", "nivoke", "onvoke", "unvoke", "knvoke", "imvoke", "ihvoke", "ibvoke"},
-		PreRunE:    bindEnv("path", "format", "target", "id", "source", "type", "data", "content-type", "file", "insecure", "confirm", "verbose"),
+		PreRunE:    bindEnv("path", "format", "target", "environment", "id", "source", "type", "data", "content-type", "file", "insecure", "confirm", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runInvoke(cmd, args, newClient)
 		},
 	}
 
 	// Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
 
 	// Flags
 	cmd.Flags().StringP("format", "f", "", "Format of message to send, 'http' or 'cloudevent'.  Default is to choose automatically. ($FUNC_FORMAT)")
-	cmd.Flags().StringP("target", "t", "", "Function instance to invoke.  Can be 'local', 'remote' or a URL.  Defaults to auto-discovery if not provided. ($FUNC_TARGET)")
+	cmd.Flags().StringP("target", "t", "", "Function instance to invoke.  Can be 'local', 'remote', a named environment or a URL.  Defaults to auto-discovery if not provided. ($FUNC_TARGET)")
 	cmd.Flags().StringP("id", "", "", "ID for the request data. ($FUNC_ID)")
 	cmd.Flags().StringP("source", "", fn.DefaultInvokeSource, "Source value for the request data. ($FUNC_SOURCE)")
 	cmd.Flags().StringP("type", "", fn.DefaultInvokeType, "Type value for the request data. ($FUNC_TYPE)")
@@ -125,6 +127,7 @@ EXAMPLES
 	cmd.Flags().StringP("file", "", "", "Path to a file to use as data. Overrides --data flag and should be sent with a correct --content-type. ($FUNC_FILE)")
 	cmd.Flags().BoolP("insecure", "i", false, "Allow insecure server connections when using SSL. ($FUNC_INSECURE)")
 	addConfirmFlag(cmd, cfg.Confirm)
+	addEnvironmentFlag(cmd)
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
@@ -151,6 +154,9 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 	if !f.Initialized() {
 		return fn.NewErrNotInitialized(f.Root)
 	}
+	if _, err = f.WithEnvironment(cfg.Environment); err != nil {
+		return // the environment must be defined by the function
+	}
 
 	// Client instance from env vars, flags, args and user prompts (if --confirm)
 	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.Insecure})
@@ -214,6 +220,7 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 type invokeConfig struct {
 	Path        string
 	Target      string
+	Environment string
 	Format      string
 	ID          string
 	Source      string
@@ -230,6 +237,7 @@ func newInvokeConfig() (cfg invokeConfig, err error) {
 	cfg = invokeConfig{
 		Path:        viper.GetString("path"),
 		Target:      viper.GetString("target"),
+		Environment: viper.GetString("environment"),
 		Format:      viper.GetString("format"),
 		ID:          viper.GetString("id"),
 		Source:      viper.GetString("source"),
@@ -242,6 +250,14 @@ func newInvokeConfig() (cfg invokeConfig, err error) {
 		Insecure:    viper.GetBool("insecure"),
 	}
 
+	// A named environment is an invocation target
+	if cfg.Environment != "" {
+		if cfg.Target != "" {
+			return cfg, fmt.Errorf("only one of --target and --environment should be provided")
+		}
+		cfg.Target = cfg.Environment
+	}
+
 	// If file was passed, read it in as data
 	if cfg.File != "" {
 		b, err := os.ReadFile(cfg.File)
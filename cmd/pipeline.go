@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewPipelineCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pipeline",
+		Short: "Manage the remote pipelines of a function",
+		Long: `Manage the remote pipelines of a function
+
+Manages the Tekton pipeline runs which build and deploy the function in the
+current directory or from the directory specified with --path when deployed
+with '{{rootCmdUse}} deploy --remote'.  Runs may be listed, described,
+cancelled, rerun and their logs printed, and the build caches cleared.
+`,
+		Aliases:    []string{"pipelines"},
+		SuggestFor: []string{"pipe", "pipline", "tekton"},
+	}
+
+	cmd.AddCommand(NewPipelineListCmd(newClient))
+	cmd.AddCommand(NewPipelineDescribeCmd(newClient))
+	cmd.AddCommand(NewPipelineLogsCmd(newClient))
+	cmd.AddCommand(NewPipelineCancelCmd(newClient))
+	cmd.AddCommand(NewPipelineRerunCmd(newClient))
+	cmd.AddCommand(NewPipelineCacheCmd(newClient))
+
+	return cmd
+}
+
+func NewPipelineLogsCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs [run]",
+		Short: "Print the logs of a pipeline run",
+		Long: `Print the logs of a pipeline run
+
+Prints the logs of each step of the named pipeline run of the function, or of
+its most recent run if no run is named, prefixed by the names of the task and
+step.  The logs of a run which has not completed are followed until it does.
+`,
+		Example: `
+# Print the logs of the most recent pipeline run of the function
+{{rootCmdUse}} pipeline logs
+
+# Print the logs of a specific pipeline run
+{{rootCmdUse}} pipeline logs my-function-pipeline-run-8xk2p
+`,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: bindEnv("environment", "namespace", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPipelineLogs(cmd, args, newClient)
+		},
+	}
+
+	addPipelineFlags(cmd)
+
+	return cmd
+}
+
+// addPipelineFlags common to the pipeline subcommands.
+func addPipelineFlags(cmd *cobra.Command) {
+	cfg, err := newGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	f, _ := fn.NewFunction(effectivePath())
+	f, _ = f.WithEnvironment(effectiveEnvironment())
+
+	cmd.Flags().StringP("namespace", "n", defaultNamespace(f, false),
+		"Namespace in which the pipeline was run. ($FUNC_NAMESPACE)")
+	addEnvironmentFlag(cmd)
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+}
+
+func runPipelineLogs(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	f, err := newPipelineFunction()
+	if err != nil {
+		return
+	}
+
+	var run string
+	if len(args) > 0 {
+		run = args[0]
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose"), RemoteBuilder: f.Local.RemoteBuilder})
+	defer done()
+
+	return client.PipelineLogs(cmd.Context(), f, run, cmd.OutOrStdout())
+}
+
+// newPipelineFunction loads the function whose pipelines are managed, in the
+// namespace requested.
+func newPipelineFunction() (f fn.Function, err error) {
+	if f, err = fn.NewFunction(viper.GetString("path")); err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return f, fn.NewErrNotInitialized(f.Root)
+	}
+	if f, err = f.WithEnvironment(viper.GetString("environment")); err != nil {
+		return
+	}
+	f.Namespace = viper.GetString("namespace")
+	return
+}
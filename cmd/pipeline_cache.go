@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewPipelineCacheCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the build caches of the remote pipelines of a function",
+		Long: `Manage the build caches of the remote pipelines of a function
+
+The caches of remote builds, such as of Go modules, npm or Maven, are kept on a
+persistent volume of the function, or on one shared by the functions of the
+namespace if 'build.sharedCache' is set in func.yaml.  Its size is set with
+'build.cachePvcSize' or '{{rootCmdUse}} deploy --cache-pvc-size'.
+
+The volume is ReadWriteOnce, so builds sharing it which run on different nodes
+wait for each other.  Set 'build.cacheAccessMode' to ReadWriteMany, if the
+storage class of the cluster supports it, for them to run concurrently.  The
+access mode of an existing volume is changed only once the cache is cleared.
+`,
+	}
+
+	cmd.AddCommand(NewPipelineCacheClearCmd(newClient))
+
+	return cmd
+}
+
+func NewPipelineCacheClearCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Clear the build caches of the remote pipelines of a function",
+		Long: `Clear the build caches of the remote pipelines of a function
+
+Deletes the persistent volume holding the caches of the remote builds of the
+function, such that its next build starts afresh.  The volume is created anew
+by the next remote build.
+
+If the cache is shared by the functions of the namespace ('build.sharedCache'),
+only the function's own caches on it are deleted, which with the pack builder
+are kept in a directory of their own; the caches of other builders are common
+to all the functions.  Use --shared to delete the shared volume as a whole,
+clearing it for all of them.  In an interactive terminal this is to be
+confirmed.
+`,
+		Example: `
+# Clear the build caches of the function
+{{rootCmdUse}} pipeline cache clear
+
+# Delete the build cache volume shared by the functions of the namespace
+{{rootCmdUse}} pipeline cache clear --shared
+`,
+		Args:    cobra.NoArgs,
+		PreRunE: bindEnv("environment", "namespace", "path", "shared", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPipelineCacheClear(cmd, newClient)
+		},
+	}
+
+	addPipelineFlags(cmd)
+	cmd.Flags().Bool("shared", false, "Delete the build cache shared by the functions of the namespace as a whole, clearing it for all of them. ($FUNC_SHARED)")
+
+	return cmd
+}
+
+func runPipelineCacheClear(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	f, err := newPipelineFunction()
+	if err != nil {
+		return
+	}
+
+	ctx := cmd.Context()
+	shared := f.Build.SharedCache && viper.GetBool("shared")
+	if viper.GetBool("shared") && !f.Build.SharedCache {
+		return fmt.Errorf("the build cache of function %v is not shared, remove --shared to clear it", f.Name)
+	}
+	if shared {
+		if interactiveTerminal() {
+			confirmed := false
+			if err = survey.AskOne(&survey.Confirm{
+				Message: "Delete the build cache shared by all the functions of the namespace?",
+				Default: false,
+			}, &confirmed); err != nil {
+				return
+			}
+			if !confirmed {
+				return fmt.Errorf("clearing the shared build cache was not confirmed")
+			}
+		}
+		ctx = context.WithValue(ctx, fn.ClearSharedCacheKey{}, true)
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose"), RemoteBuilder: f.Local.RemoteBuilder})
+	defer done()
+
+	if err = client.ClearPipelineCache(ctx, f); err != nil {
+		return
+	}
+	if shared {
+		fmt.Fprintln(cmd.OutOrStdout(), "Build cache shared by the functions of the namespace cleared")
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Build caches of function %v cleared\n", f.Name)
+	return
+}
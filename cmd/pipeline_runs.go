@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewPipelineListCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the pipeline runs of a function",
+		Long: `List the pipeline runs of a function
+
+Lists the pipeline runs of the function, most recent first, with their status,
+duration, the commit built and the digest of the image built.
+`,
+		Example: `
+# List the pipeline runs of the function
+{{rootCmdUse}} pipeline list
+
+# List the pipeline runs of the function with JSON output
+{{rootCmdUse}} pipeline list --output=json
+`,
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		PreRunE: bindEnv("environment", "namespace", "output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPipelineList(cmd, newClient)
+		},
+	}
+
+	addPipelineFlags(cmd)
+	addPipelineOutputFlag(cmd)
+
+	return cmd
+}
+
+func runPipelineList(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	f, err := newPipelineFunction()
+	if err != nil {
+		return
+	}
+	output := viper.GetString("output")
+	if Format(output) == URL {
+		return fmt.Errorf("the url output format is not supported by pipeline list")
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose"), RemoteBuilder: f.Local.RemoteBuilder})
+	defer done()
+
+	runs, err := client.PipelineRuns(cmd.Context(), f)
+	if err != nil {
+		return
+	}
+	if len(runs) == 0 && Format(output) == Human {
+		fmt.Fprintf(cmd.ErrOrStderr(), "No pipeline runs of function %v\n", f.Name)
+		return
+	}
+	write(cmd.OutOrStdout(), pipelineRuns(runs), output)
+	return
+}
+
+func NewPipelineDescribeCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <run>",
+		Short: "Describe a pipeline run of a function",
+		Long: `Describe a pipeline run of a function
+
+Prints the status, duration, commit and image digest of the named pipeline run
+of the function, and the status and duration of each of its tasks.
+`,
+		Example: `
+# Describe a pipeline run of the function
+{{rootCmdUse}} pipeline describe my-function-pipeline-run-8xk2p
+`,
+		Aliases: []string{"desc"},
+		Args:    cobra.ExactArgs(1),
+		PreRunE: bindEnv("environment", "namespace", "output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPipelineDescribe(cmd, args, newClient)
+		},
+	}
+
+	addPipelineFlags(cmd)
+	addPipelineOutputFlag(cmd)
+
+	return cmd
+}
+
+func runPipelineDescribe(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	f, err := newPipelineFunction()
+	if err != nil {
+		return
+	}
+	output := viper.GetString("output")
+	if Format(output) == URL {
+		return fmt.Errorf("the url output format is not supported by pipeline describe")
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose"), RemoteBuilder: f.Local.RemoteBuilder})
+	defer done()
+
+	run, err := client.DescribePipelineRun(cmd.Context(), f, args[0])
+	if err != nil {
+		return
+	}
+	write(cmd.OutOrStdout(), pipelineRun(run), output)
+	return
+}
+
+func NewPipelineCancelCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel <run>",
+		Short: "Cancel a pipeline run of a function",
+		Long: `Cancel a pipeline run of a function
+
+Cancels the named pipeline run of the function, stopping its running tasks.
+The run and its logs are retained.
+`,
+		Example: `
+# Cancel a pipeline run of the function
+{{rootCmdUse}} pipeline cancel my-function-pipeline-run-8xk2p
+`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: bindEnv("environment", "namespace", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPipelineCancel(cmd, args, newClient)
+		},
+	}
+
+	addPipelineFlags(cmd)
+
+	return cmd
+}
+
+func runPipelineCancel(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	f, err := newPipelineFunction()
+	if err != nil {
+		return
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose"), RemoteBuilder: f.Local.RemoteBuilder})
+	defer done()
+
+	if err = client.CancelPipelineRun(cmd.Context(), f, args[0]); err != nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Pipeline run %v cancelled\n", args[0])
+	return
+}
+
+func NewPipelineRerunCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rerun <run>",
+		Short: "Rerun a pipeline run of a function",
+		Long: `Rerun a pipeline run of a function
+
+Starts a new pipeline run of the function with the parameters of the named run,
+such as to retry a run which failed, and prints the name of the new run.
+`,
+		Example: `
+# Rerun a pipeline run of the function and follow its logs
+{{rootCmdUse}} pipeline rerun my-function-pipeline-run-8xk2p
+{{rootCmdUse}} pipeline logs my-function-pipeline-run-q7d4m
+`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: bindEnv("environment", "namespace", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPipelineRerun(cmd, args, newClient)
+		},
+	}
+
+	addPipelineFlags(cmd)
+
+	return cmd
+}
+
+func runPipelineRerun(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	f, err := newPipelineFunction()
+	if err != nil {
+		return
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose"), RemoteBuilder: f.Local.RemoteBuilder})
+	defer done()
+
+	run, err := client.RerunPipelineRun(cmd.Context(), f, args[0])
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Pipeline run %v started\n", run)
+	fmt.Fprintf(cmd.ErrOrStderr(), "Follow its logs with '%v pipeline logs %v'\n", cmd.Root().Name(), run)
+	return
+}
+
+func addPipelineOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|xml|yaml) ($FUNC_OUTPUT)")
+	if err := cmd.RegisterFlagCompletionFunc("output", CompleteOutputFormatList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+}
+
+// formatDuration of a run for display, empty if it has not started.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// Output Formatting (serializers)
+// -------------------------------
+
+type pipelineRuns []fn.PipelineRun
+
+func (runs pipelineRuns) Human(w io.Writer) error {
+	return runs.Plain(w)
+}
+
+func (runs pipelineRuns) Plain(w io.Writer) error {
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", "NAME", "STATUS", "DURATION", "COMMIT", "IMAGE DIGEST")
+	for _, r := range runs {
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", r.Name, r.Status, formatDuration(r.Duration()), shortCommit(r.Commit), r.ImageDigest)
+	}
+	return nil
+}
+
+func (runs pipelineRuns) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(runs)
+}
+
+func (runs pipelineRuns) XML(w io.Writer) error {
+	return xml.NewEncoder(w).Encode(runs)
+}
+
+func (runs pipelineRuns) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(runs)
+}
+
+func (runs pipelineRuns) URL(w io.Writer) error {
+	return fmt.Errorf("the url output format is not supported by pipeline list")
+}
+
+type pipelineRun fn.PipelineRun
+
+func (r pipelineRun) Human(w io.Writer) error {
+	fmt.Fprintf(w, "Name:         %v\n", r.Name)
+	fmt.Fprintf(w, "Namespace:    %v\n", r.Namespace)
+	fmt.Fprintf(w, "Status:       %v\n", r.Status)
+	if r.Message != "" {
+		fmt.Fprintf(w, "Message:      %v\n", r.Message)
+	}
+	if !r.StartTime.IsZero() {
+		fmt.Fprintf(w, "Started:      %v\n", r.StartTime.Format(time.RFC3339))
+	}
+	if d := formatDuration(fn.PipelineRun(r).Duration()); d != "" {
+		fmt.Fprintf(w, "Duration:     %v\n", d)
+	}
+	if r.Commit != "" {
+		fmt.Fprintf(w, "Commit:       %v\n", r.Commit)
+	}
+	if r.ImageDigest != "" {
+		fmt.Fprintf(w, "Image Digest: %v\n", r.ImageDigest)
+	}
+	if len(r.Tasks) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "Tasks:")
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+	for _, t := range r.Tasks {
+		fmt.Fprintf(tabWriter, "  %s\t%s\t%s\n", t.Name, t.Status, formatDuration(t.Duration()))
+	}
+	return nil
+}
+
+func (r pipelineRun) Plain(w io.Writer) error {
+	fmt.Fprintf(w, "Name %v\n", r.Name)
+	fmt.Fprintf(w, "Namespace %v\n", r.Namespace)
+	fmt.Fprintf(w, "Status %v\n", r.Status)
+	if r.Message != "" {
+		fmt.Fprintf(w, "Message %v\n", r.Message)
+	}
+	if !r.StartTime.IsZero() {
+		fmt.Fprintf(w, "Started %v\n", r.StartTime.Format(time.RFC3339))
+	}
+	if d := formatDuration(fn.PipelineRun(r).Duration()); d != "" {
+		fmt.Fprintf(w, "Duration %v\n", d)
+	}
+	if r.Commit != "" {
+		fmt.Fprintf(w, "Commit %v\n", r.Commit)
+	}
+	if r.ImageDigest != "" {
+		fmt.Fprintf(w, "ImageDigest %v\n", r.ImageDigest)
+	}
+	for _, t := range r.Tasks {
+		fmt.Fprintf(w, "Task %v %v %v\n", t.Name, t.Status, formatDuration(t.Duration()))
+	}
+	return nil
+}
+
+func (r pipelineRun) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+func (r pipelineRun) XML(w io.Writer) error {
+	return xml.NewEncoder(w).Encode(r)
+}
+
+func (r pipelineRun) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(r)
+}
+
+func (r pipelineRun) URL(w io.Writer) error {
+	return fmt.Errorf("the url output format is not supported by pipeline describe")
+}
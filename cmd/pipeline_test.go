@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestPipelineLogs ensures the logs of the named pipeline run of the
+// function, in the requested namespace, are printed.
+func TestPipelineLogs(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	pipelinesProvider := mock.NewPipelinesProvider()
+	pipelinesProvider.LogsFn = func(f fn.Function, run string, w io.Writer) error {
+		if f.Namespace != "staging" {
+			t.Fatalf("expected namespace %q, got %q", "staging", f.Namespace)
+		}
+		fmt.Fprintf(w, "[build : build] logs of %v\n", run)
+		return nil
+	}
+
+	var out bytes.Buffer
+	cmd := NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"logs", "f-run-abc", "--namespace", "staging"})
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !pipelinesProvider.LogsInvoked {
+		t.Fatal("pipeline logs were not requested")
+	}
+	if out.String() != "[build : build] logs of f-run-abc\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+// TestPipelineLogs_NotInitialized ensures logs are not requested outside of
+// a function.
+func TestPipelineLogs_NotInitialized(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	pipelinesProvider := mock.NewPipelinesProvider()
+	cmd := NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"logs"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error outside of a function")
+	}
+	if pipelinesProvider.LogsInvoked {
+		t.Fatal("pipeline logs were unexpectedly requested")
+	}
+}
+
+// TestPipelineList ensures the runs of the function are listed with their
+// status, commit and image digest.
+func TestPipelineList(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	pipelinesProvider := mock.NewPipelinesProvider()
+	pipelinesProvider.RunsFn = func(f fn.Function) ([]fn.PipelineRun, error) {
+		return []fn.PipelineRun{{
+			Name:        "f-run-abc",
+			Status:      "Succeeded",
+			Commit:      "0123456789abcdef",
+			ImageDigest: "sha256:abc",
+		}}, nil
+	}
+
+	var out bytes.Buffer
+	cmd := NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"list", "--namespace", "staging"})
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !pipelinesProvider.RunsInvoked {
+		t.Fatal("pipeline runs were not listed")
+	}
+	for _, s := range []string{"f-run-abc", "Succeeded", "0123456", "sha256:abc"} {
+		if !strings.Contains(out.String(), s) {
+			t.Fatalf("expected %q in output:\n%v", s, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "0123456789abcdef") {
+		t.Fatalf("expected an abbreviated commit in output:\n%v", out.String())
+	}
+}
+
+// TestPipelineDescribe_JSON ensures a run is described in the requested
+// output format.
+func TestPipelineDescribe_JSON(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	pipelinesProvider := mock.NewPipelinesProvider()
+	pipelinesProvider.DescribeRunFn = func(f fn.Function, run string) (fn.PipelineRun, error) {
+		return fn.PipelineRun{Name: run, Status: "Failed", Tasks: []fn.PipelineTaskRun{{Name: "build", Status: "Failed"}}}, nil
+	}
+
+	var out bytes.Buffer
+	cmd := NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"describe", "f-run-abc", "--namespace", "staging", "--output", "json"})
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var run fn.PipelineRun
+	if err := json.Unmarshal(out.Bytes(), &run); err != nil {
+		t.Fatal(err)
+	}
+	if run.Name != "f-run-abc" || len(run.Tasks) != 1 || run.Tasks[0].Name != "build" {
+		t.Fatalf("unexpected run described: %+v", run)
+	}
+}
+
+// TestPipelineCancel ensures the named run is cancelled.
+func TestPipelineCancel(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	pipelinesProvider := mock.NewPipelinesProvider()
+	pipelinesProvider.CancelRunFn = func(f fn.Function, run string) error {
+		if run != "f-run-abc" {
+			t.Fatalf("expected run %q to be cancelled, got %q", "f-run-abc", run)
+		}
+		return nil
+	}
+
+	cmd := NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"cancel", "f-run-abc", "--namespace", "staging"})
+	cmd.SetOut(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !pipelinesProvider.CancelRunInvoked {
+		t.Fatal("pipeline run was not cancelled")
+	}
+}
+
+// TestPipelineRerun ensures the name of the new run is printed.
+func TestPipelineRerun(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	pipelinesProvider := mock.NewPipelinesProvider()
+
+	var out bytes.Buffer
+	cmd := NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"rerun", "f-run-abc", "--namespace", "staging"})
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !pipelinesProvider.RerunInvoked {
+		t.Fatal("pipeline run was not rerun")
+	}
+	if !strings.Contains(out.String(), "f-run-abc-rerun") {
+		t.Fatalf("expected the new run in output:\n%v", out.String())
+	}
+}
+
+// TestPipelineCacheClear ensures the build caches of the function, in the
+// requested namespace, are cleared.
+func TestPipelineCacheClear(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	pipelinesProvider := mock.NewPipelinesProvider()
+	pipelinesProvider.ClearCacheFn = func(f fn.Function) error {
+		if f.Namespace != "staging" {
+			t.Fatalf("expected namespace %q, got %q", "staging", f.Namespace)
+		}
+		return nil
+	}
+
+	cmd := NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"cache", "clear", "--namespace", "staging"})
+	cmd.SetOut(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !pipelinesProvider.ClearCacheInvoked {
+		t.Fatal("build caches were not cleared")
+	}
+}
+
+// TestPipelineCacheClear_Shared ensures a cache shared by the functions of the
+// namespace is only cleared when confirmed with --shared.
+func TestPipelineCacheClear_Shared(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Registry: TestRegistry, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// --shared is rejected if the cache is not shared
+	pipelinesProvider := mock.NewPipelinesProvider()
+	cmd := NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"cache", "clear", "--namespace", "staging", "--shared"})
+	cmd.SetOut(io.Discard)
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error clearing a cache which is not shared with --shared")
+	}
+	if pipelinesProvider.ClearCacheInvoked {
+		t.Fatal("build cache was cleared despite the error")
+	}
+
+	f.Build.SharedCache = true
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the function's own caches are cleared by default
+	cmd = NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"cache", "clear", "--namespace", "staging"})
+	cmd.SetOut(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !pipelinesProvider.ClearCacheInvoked || pipelinesProvider.ClearSharedCache {
+		t.Fatalf("expected only the caches of the function to be cleared, got invoked %v, shared %v",
+			pipelinesProvider.ClearCacheInvoked, pipelinesProvider.ClearSharedCache)
+	}
+
+	// the shared cache is cleared as a whole with --shared
+	pipelinesProvider = mock.NewPipelinesProvider()
+	cmd = NewPipelineCmd(NewTestClient(fn.WithPipelinesProvider(pipelinesProvider)))
+	cmd.SetArgs([]string{"cache", "clear", "--namespace", "staging", "--shared"})
+	cmd.SetOut(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !pipelinesProvider.ClearCacheInvoked || !pipelinesProvider.ClearSharedCache {
+		t.Fatal("shared build cache was not cleared as a whole")
+	}
+}
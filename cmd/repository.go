@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/ory/viper"
@@ -31,6 +34,7 @@ SYNOPSIS
 	{{rootCmdUse}} repo add <name> <url>[-r|--repositories] [-c|--confirm] [-v|--verbose]
 	{{rootCmdUse}} repo rename <old> <new> [-r|--repositories] [-c|--confirm] [-v|--verbose]
 	{{rootCmdUse}} repo remove <name> [-r|--repositories] [-c|--confirm] [-v|--verbose]
+	{{rootCmdUse}} repo validate [path|url] [--json]
 
 DESCRIPTION
 	Manage template repositories installed on disk at either the default location
@@ -103,6 +107,14 @@ COMMANDS
 	  (via the FUNC_REPOSITORIES_PATH environment variable).
 	    $ {{rootCmdUse}} repository remove <name>
 
+	validate
+	  Validate a template repository located at the given path or URL
+	  (default: the current directory) prior to publishing.  Manifests,
+	  builder image references, health endpoints, invocation hints and the
+	  scaffolding coverage of each template's method signature are checked.
+	  Exits with an error if problems are found.
+	    $ {{rootCmdUse}} repository validate ./my-templates
+
 EXAMPLES
 	o Run in confirmation mode (interactive prompts) using the --confirm flag
 	  $ {{rootCmdUse}} repository -c
@@ -152,7 +164,7 @@ EXAMPLES
 		},
 	}
 
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -163,6 +175,7 @@ EXAMPLES
 	cmd.AddCommand(NewRepositoryAddCmd(newClient))
 	cmd.AddCommand(NewRepositoryRenameCmd(newClient))
 	cmd.AddCommand(NewRepositoryRemoveCmd(newClient))
+	cmd.AddCommand(NewRepositoryValidateCmd())
 
 	return cmd
 }
@@ -178,7 +191,7 @@ func NewRepositoryListCmd(newClient ClientFactory) *cobra.Command {
 		},
 	}
 
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -199,7 +212,7 @@ func NewRepositoryAddCmd(newClient ClientFactory) *cobra.Command {
 		},
 	}
 
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -220,7 +233,7 @@ func NewRepositoryRenameCmd(newClient ClientFactory) *cobra.Command {
 		},
 	}
 
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -242,7 +255,7 @@ func NewRepositoryRemoveCmd(newClient ClientFactory) *cobra.Command {
 		},
 	}
 
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -252,6 +265,39 @@ func NewRepositoryRemoveCmd(newClient ClientFactory) *cobra.Command {
 	return cmd
 }
 
+func NewRepositoryValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Short: "Validate a template repository",
+		Use:   "validate [path|url]",
+		Long: `Validate a template repository
+
+Checks the template repository at the given path or git URL (default: the
+current directory) for problems which would otherwise only surface when a
+function is created or built from one of its templates:
+
+  o manifest.yaml files which do not conform to the manifest schema
+  o invalid builder image and buildpack references
+  o health endpoints which are not absolute paths
+  o invalid invocation hints, or hints which do not match the template source
+  o template method signatures for which the runtime provides no scaffolding
+
+Errors cause the command to exit non-zero, making it suitable for use in CI.
+`,
+		Example: `
+# Validate the template repository in the current directory
+{{rootCmdUse}} repository validate
+
+# Validate a remote template repository as JSON
+{{rootCmdUse}} repository validate https://github.com/knative-extensions/func-tastic --json
+`,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: bindEnv("json"),
+		RunE:    runRepositoryValidate,
+	}
+	cmd.Flags().Bool("json", false, "Print the report in JSON format ($FUNC_JSON)")
+	return cmd
+}
+
 // command implementations
 // -----------------------
 
@@ -662,3 +708,49 @@ func (c repositoryConfig) prompt() (repositoryConfig, error) {
 	err := survey.Ask(qs, &c)
 	return c, err
 }
+
+// Validate
+func runRepositoryValidate(cmd *cobra.Command, args []string) (err error) {
+	uri := "."
+	if len(args) > 0 {
+		uri = args[0]
+	}
+	if uri, err = repositoryURI(uri); err != nil {
+		return
+	}
+
+	report, err := fn.ValidateRepository(uri)
+	if err != nil {
+		return
+	}
+
+	if viper.GetBool("json") {
+		var b []byte
+		if b, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(b))
+	} else {
+		for _, f := range report.Findings {
+			fmt.Fprintln(cmd.OutOrStdout(), f)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%v errors, %v warnings\n", report.Errors(), report.Warnings())
+	}
+	if !report.Valid() {
+		return fmt.Errorf("template repository %v is invalid", uri)
+	}
+	return
+}
+
+// repositoryURI returns the given repository location as a URI, converting
+// local paths to the file URIs expected by the repository loader.
+func repositoryURI(location string) (string, error) {
+	if location == "" || strings.Contains(location, "://") {
+		return location, nil
+	}
+	path, err := filepath.Abs(location)
+	if err != nil {
+		return "", err
+	}
+	return "file://" + filepath.ToSlash(path), nil
+}
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	. "knative.dev/func/pkg/testing"
@@ -164,3 +168,39 @@ func TestRepository_Remove(t *testing.T) {
 		t.Fatalf("expected:\n'%v'\ngot:\n'%v'\n", expect, output)
 	}
 }
+
+// TestRepository_Validate ensures that the 'validate' subcommand reports the
+// problems of a repository at a local path, failing if any are errors.
+func TestRepository_Validate(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	// A runtime with a template whose invocation hint is invalid
+	if err := os.MkdirAll(filepath.Join(root, "go", "http"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go", "http", "manifest.yaml"), []byte("invoke: event\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := NewRepositoryValidateCmd()
+	cmd.SetArgs([]string{"."})
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected the invalid repository to fail validation")
+	}
+	if !strings.Contains(out.String(), `error: go/http/manifest.yaml: invalid invocation hint "event"`) {
+		t.Fatalf("unexpected output:\n%v", out.String())
+	}
+
+	// Corrected, the repository is valid
+	if err := os.WriteFile(filepath.Join(root, "go", "http", "manifest.yaml"), []byte("invoke: http\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	cmd = NewRepositoryValidateCmd()
+	cmd.SetArgs([]string{root})
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
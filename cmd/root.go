@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -69,6 +71,14 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 	if _, err := os.ReadFile(cp); os.IsPermission(err) {
 		fmt.Fprintf(os.Stderr, "Warning: Insufficient permissions to read config file at '%s' - continuing without it\n", cp)
 	}
+
+	// Global Config Profile
+	// Subcommands read the global config when defining their flags, so the
+	// profile flag is preparsed (see effectiveProfile) such that the selected
+	// profile is applied to the defaults they calculate.  A profile which does
+	// not exist is an error upon execution of any subcommand (see bindEnv).
+	cmd.PersistentFlags().String("profile", config.Profile(), "Global config profile to apply ($FUNC_PROFILE)")
+
 	// Client
 	// Use the provided ClientFactory or default to NewClient
 	newClient := cfg.NewClient
@@ -95,6 +105,9 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 				NewRunCmd(newClient),
 				NewInvokeCmd(newClient),
 				NewBuildCmd(newClient),
+				NewPipelineCmd(newClient),
+				NewCICmd(),
+				NewWorkspaceCmd(newClient),
 			},
 		},
 		{
@@ -105,6 +118,7 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 				NewTemplatesCmd(newClient),
 				NewRepositoryCmd(newClient),
 				NewEnvironmentCmd(newClient, &cfg.Version),
+				NewDoctorCmd(newClient),
 			},
 		},
 		{
@@ -133,7 +147,7 @@ func registry() string {
 	if r := viper.GetString("registry"); r != "" {
 		return r
 	}
-	cfg, _ := config.NewDefault()
+	cfg, _ := newGlobalConfig()
 	return cfg.RegistryDefault()
 }
 
@@ -161,6 +175,63 @@ func effectivePath() (path string) {
 	return path
 }
 
+// effectiveEnvironment to use is that which was provided by --environment or
+// FUNC_ENVIRONMENT.  Like effectivePath, flags are manually parsed such that
+// this can be used during flag definition to apply the named environment
+// to the function from which flag defaults are derived.
+func effectiveEnvironment() (environment string) {
+	var (
+		env = os.Getenv("FUNC_ENVIRONMENT")
+		fs  = pflag.NewFlagSet("", pflag.ContinueOnError)
+		e   = fs.String("environment", "", "")
+	)
+	fs.SetOutput(io.Discard)
+	fs.ParseErrorsWhitelist.UnknownFlags = true // wokeignore:rule=whitelist
+	_ = fs.Parse(os.Args[1:])
+	if env != "" {
+		environment = env
+	}
+	if *e != "" {
+		environment = *e
+	}
+	return environment
+}
+
+// newGlobalConfig returns the global config with the effective profile
+// applied atop it.
+func newGlobalConfig() (config.Global, error) {
+	return config.NewDefaultWithProfile(effectiveProfile())
+}
+
+// kubeContext returns a context selecting the kubeconfig context of the
+// global config, for use where no command context is available, such as
+// when calculating flag defaults.
+func kubeContext() context.Context {
+	cfg, _ := newGlobalConfig()
+	return k8s.WithKubeContext(context.Background(), cfg.KubeContext)
+}
+
+// effectiveProfile to use is that which was provided by --profile or
+// FUNC_PROFILE.  Like effectivePath, flags are manually parsed such that
+// the profile can be applied to global config defaults during flag definition.
+func effectiveProfile() (profile string) {
+	var (
+		env = os.Getenv("FUNC_PROFILE")
+		fs  = pflag.NewFlagSet("", pflag.ContinueOnError)
+		p   = fs.String("profile", "", "")
+	)
+	fs.SetOutput(io.Discard)
+	fs.ParseErrorsWhitelist.UnknownFlags = true // wokeignore:rule=whitelist
+	_ = fs.Parse(os.Args[1:])
+	if env != "" {
+		profile = env
+	}
+	if *p != "" {
+		profile = *p
+	}
+	return profile
+}
+
 // defaultNamespace to use when none is provided explicitly.
 // This requires a bit more logic than normal flag defaults, which rely
 // on the order of precedence Static Config -> Global Config -> Current Func ->
@@ -179,7 +250,7 @@ func defaultNamespace(f fn.Function, verbose bool) string {
 	}
 
 	// Active K8S namespace
-	namespace, err := k8s.GetDefaultNamespace()
+	namespace, err := k8s.GetDefaultNamespace(kubeContext())
 	if err != nil {
 		if verbose {
 			fmt.Fprintf(os.Stderr, "Unable to get current active kubernetes namespace.  Defaults will be used. %v", err)
@@ -189,7 +260,7 @@ func defaultNamespace(f fn.Function, verbose bool) string {
 	}
 
 	// Globally-defined default in ~/.config/func/config.yaml is next
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading global config at '%v'. %v\n", config.File(), err)
 	} else if cfg.Namespace != "" {
@@ -210,8 +281,29 @@ func interactiveTerminal() bool {
 // bindFunc which conforms to the cobra PreRunE method signature
 type bindFunc func(*cobra.Command, []string) error
 
-// bindEnv returns a bindFunc that binds env vars to the named flags.
+// bindEnv returns a bindFunc that binds env vars to the named flags and
+// applies the selected global config profile: the command's context selects
+// the profile's kubeconfig context.  A profile which does not exist is an
+// error.
 func bindEnv(flags ...string) bindFunc {
+	bind := bindFlags(flags...)
+	return func(cmd *cobra.Command, args []string) error {
+		if err := bind(cmd, args); err != nil {
+			return err
+		}
+		cfg, err := newGlobalConfig()
+		if errors.Is(err, config.ErrProfileNotFound) {
+			return err
+		}
+		cmd.SetContext(k8s.WithKubeContext(cmd.Context(), cfg.KubeContext))
+		return nil
+	}
+}
+
+// bindFlags returns a bindFunc that binds env vars to the named flags only.
+// Used by commands which must run regardless of the selected profile, such
+// as those which create it.
+func bindFlags(flags ...string) bindFunc {
 	return func(cmd *cobra.Command, args []string) (err error) {
 		for _, flag := range flags {
 			if err = viper.BindPFlag(flag, cmd.Flags().Lookup(flag)); err != nil {
@@ -265,7 +357,7 @@ func deriveNameAndAbsolutePathFromPath(path string) (string, string) {
 	return pathParts[len(pathParts)-1], absPath
 }
 
-func mergeEnvs(envs []fn.Env, envToUpdate *util.OrderedMap, envToRemove []string) ([]fn.Env, int, error) {
+func mergeEnvs(root string, envs []fn.Env, envToUpdate *util.OrderedMap, envToRemove []string) ([]fn.Env, int, error) {
 	updated := sets.NewString()
 
 	var counter int
@@ -301,7 +393,7 @@ func mergeEnvs(envs []fn.Env, envToUpdate *util.OrderedMap, envToRemove []string
 		}
 	}
 
-	errMsg := fn.ValidateEnvs(envs)
+	errMsg := fn.ValidateEnvsIn(root, envs)
 	if len(errMsg) > 0 {
 		return []fn.Env{}, 0, fmt.Errorf("error(s) while validating envs: %s", strings.Join(errMsg, "\n"))
 	}
@@ -319,6 +411,12 @@ func addPathFlag(cmd *cobra.Command) {
 	cmd.Flags().StringP("path", "p", "", "Path to the function.  Default is current directory ($FUNC_PATH)")
 }
 
+// addEnvironmentFlag ensures common text/wording when the --environment flag
+// is used
+func addEnvironmentFlag(cmd *cobra.Command) {
+	cmd.Flags().String("environment", "", "Named environment, as defined in the function's environments, to target ($FUNC_ENVIRONMENT)")
+}
+
 // addVerboseFlag ensures common text/wording when the --path flag is used
 func addVerboseFlag(cmd *cobra.Command, dflt bool) {
 	cmd.Flags().BoolP("verbose", "v", dflt, "Print verbose logs ($FUNC_VERBOSE)")
@@ -64,14 +64,14 @@ EXAMPLES
 	  $ {{rootCmdUse}} run --container=false
 `,
 		SuggestFor: []string{"rnu"},
-		PreRunE:    bindEnv("build", "builder", "builder-image", "confirm", "container", "env", "image", "path", "registry", "start-timeout", "verbose"),
+		PreRunE:    bindEnv("build", "build-timeout", "builder", "builder-image", "confirm", "container", "env", "image", "path", "registry", "start-timeout", "verbose"),
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return runRun(cmd, newClient)
 		},
 	}
 
 	// Global Config
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -89,6 +89,8 @@ EXAMPLES
 		fmt.Sprintf("Builder to use when creating the function's container. Currently supported builders are %s.", KnownBuilders()))
 	cmd.Flags().StringP("registry", "r", cfg.Registry,
 		"Container registry + registry namespace. (ex 'ghcr.io/myuser').  The full image name is automatically determined using this along with function name. ($FUNC_REGISTRY)")
+	cmd.Flags().Duration("build-timeout", cfg.BuildTimeout,
+		"Maximum duration of the build, for example \"10m\".  Zero indicates no timeout. ($FUNC_BUILD_TIMEOUT)")
 
 	// Function-Context Flags:
 	//   Options whose value is available on the function with context only
@@ -202,7 +204,7 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 			f.Build.Image = cfg.Image
 		} else {
 
-			if f, _, err = build(cmd, cfg.Build, f, client, buildOptions); err != nil {
+			if f, _, err = build(cmd, cfg.Build, f, client, buildOptions, cfg.buildConfig); err != nil {
 				return err
 			}
 		}
@@ -305,7 +307,7 @@ func (c runConfig) Configure(f fn.Function) (fn.Function, error) {
 
 	f.Run.StartTimeout = c.StartTimeout
 
-	f.Run.Envs, err = applyEnvs(f.Run.Envs, c.Env)
+	f.Run.Envs, err = applyEnvs(f.Root, f.Run.Envs, c.Env)
 
 	// The other members; build, path, and container; are not part of function
 	// state, so are not mentioned here in Configure.
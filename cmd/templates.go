@@ -1,18 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
 
+	"knative.dev/func/pkg/builders"
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci"
+	"knative.dev/func/pkg/scaffolding"
 )
 
 // ErrTemplateRepoDoesNotExist is a sentinel error if a template repository responds with 404 status code
@@ -28,6 +34,7 @@ NAME
 
 SYNOPSIS
 	{{rootCmdUse}} templates [language] [--json] [-r|--repository]
+	{{rootCmdUse}} templates test [language] [--json] [-r|--repository]
 
 DESCRIPTION
 	List all templates available, optionally for a specific language runtime.
@@ -41,6 +48,10 @@ DESCRIPTION
 
 	To see all available language runtimes, see the 'languages' command.
 
+	To create a function from each template and build it using the host
+	builder, use the 'test' subcommand.  This is intended for authors of
+	template repositories.
+
 
 EXAMPLES
 
@@ -56,13 +67,16 @@ EXAMPLES
 	o Return Go templates in a specific repository
 		$ {{rootCmdUse}} templates go --repository=https://github.com/boson-project/templates
 `,
+		// Arbitrary such that a language is not mistaken for an unknown
+		// subcommand.
+		Args:    cobra.ArbitraryArgs,
 		PreRunE: bindEnv("json", "repository", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runTemplates(cmd, args, newClient)
 		},
 	}
 
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -71,6 +85,48 @@ EXAMPLES
 	cmd.Flags().StringP("repository", "r", "", "URI to a specific repository to consider ($FUNC_REPOSITORY)")
 	addVerboseFlag(cmd, cfg.Verbose)
 
+	cmd.AddCommand(NewTemplatesTestCmd(newClient))
+
+	return cmd
+}
+
+func NewTemplatesTestCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [language]",
+		Short: "Create and build a function from each template",
+		Long: `Create and build a function from each template
+
+Creates a function from each template of a repository (optionally only those
+of the given language runtime) in a temporary directory, and builds it using
+the host builder.  Templates of runtimes not yet supported by the host builder
+are created only, and are reported as skipped.
+
+Intended for authors of template repositories, the command exits non-zero if
+any template fails, and a JSON report can be requested for use in CI.
+`,
+		Example: `
+# Test the templates of a repository in the current directory
+{{rootCmdUse}} templates test --repository .
+
+# Test the Go templates of a remote repository with a JSON report
+{{rootCmdUse}} templates test go --repository https://github.com/knative-extensions/func-tastic --json
+`,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: bindEnv("json", "repository", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTemplatesTest(cmd, args, newClient)
+		},
+	}
+
+	cfg, err := newGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().Bool("json", false, "Set output to JSON format. (Env: $FUNC_JSON)")
+	cmd.Flags().StringP("repository", "r", "", "Path or URI of the repository to test.  Default is the embedded repository ($FUNC_REPOSITORY)")
+	addVerboseFlag(cmd, cfg.Verbose)
+
 	return cmd
 }
 
@@ -178,3 +234,119 @@ func newTemplatesConfig() (cfg templatesConfig, err error) {
 
 	return
 }
+
+// templatesTestRegistry is the registry used when naming the images built by
+// 'templates test'.  Images are built only; they are never pushed.
+const templatesTestRegistry = "localhost/func-templates-test"
+
+// Results of testing a template.
+const (
+	templateTestOK      = "ok"
+	templateTestSkipped = "skipped"
+	templateTestFailed  = "failed"
+)
+
+type templateTestResult struct {
+	Runtime  string `json:"runtime"`
+	Template string `json:"template"`
+	Result   string `json:"result"`
+	Message  string `json:"message,omitempty"`
+}
+
+func runTemplatesTest(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	cfg, err := newTemplatesConfig()
+	if err != nil {
+		return
+	}
+	if cfg.Repository, err = repositoryURI(cfg.Repository); err != nil {
+		return
+	}
+
+	client, done := newClient(
+		ClientConfig{Verbose: cfg.Verbose},
+		fn.WithRepository(cfg.Repository),
+		fn.WithRegistry(templatesTestRegistry),
+		fn.WithBuilder(oci.NewBuilder(builders.Host, cfg.Verbose)))
+	defer done()
+
+	runtimes := args
+	if len(runtimes) == 0 {
+		if runtimes, err = client.Runtimes(); err != nil {
+			return
+		}
+	}
+
+	results := []templateTestResult{}
+	for _, runtime := range runtimes {
+		templates, err := client.Templates().List(runtime)
+		if err != nil {
+			return err
+		}
+		for _, template := range templates {
+			results = append(results, testTemplate(cmd.Context(), client, runtime, template))
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Result == templateTestFailed {
+			failed++
+		}
+	}
+
+	if cfg.JSON {
+		s, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(s))
+	} else {
+		writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+		fmt.Fprint(writer, "LANGUAGE\tTEMPLATE\tRESULT\tMESSAGE\n")
+		for _, r := range results {
+			fmt.Fprintf(writer, "%v\t%v\t%v\t%v\n", r.Runtime, r.Template, r.Result, r.Message)
+		}
+		writer.Flush()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%v of %v templates failed", failed, len(results))
+	}
+	return
+}
+
+// testTemplate creates a function from the given template in a temporary
+// directory and builds it.  Builds which are not possible for the template's
+// runtime are reported as skipped.
+func testTemplate(ctx context.Context, client *fn.Client, runtime, template string) templateTestResult {
+	result := templateTestResult{Runtime: runtime, Template: template, Result: templateTestOK}
+	fail := func(err error) templateTestResult {
+		result.Result = templateTestFailed
+		result.Message = err.Error()
+		return result
+	}
+
+	root, err := os.MkdirTemp("", "func-templates-test")
+	if err != nil {
+		return fail(err)
+	}
+	defer os.RemoveAll(root)
+	root = filepath.Join(root, "function")
+
+	f, err := client.Init(fn.Function{Root: root, Runtime: runtime, Template: template})
+	if err != nil {
+		return fail(fmt.Errorf("create failed. %w", err))
+	}
+	if _, err = client.Build(ctx, f); err != nil {
+		if errors.As(err, &oci.ErrRuntimeNotSupported{}) ||
+			errors.Is(err, scaffolding.ErrScaffoldingNotFound) ||
+			errors.As(err, &scaffolding.ErrRuntimeNotRecognized{}) ||
+			errors.As(err, &scaffolding.ErrDetectorNotImplemented{}) {
+			result.Result = templateTestSkipped
+			result.Message = err.Error()
+			return result
+		}
+		return fail(fmt.Errorf("build failed. %w", err))
+	}
+	return result
+}
@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"gotest.tools/v3/assert"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	"knative.dev/func/pkg/oci"
 	. "knative.dev/func/pkg/testing"
 )
 
@@ -153,3 +159,53 @@ func TestTemplates_WrongRepositoryUrl(t *testing.T) {
 	err := cmd.Execute()
 	assert.Assert(t, err != nil)
 }
+
+// TestTemplates_Test ensures that each template is created and built, that
+// builds unsupported for a runtime are reported as skipped, and that failures
+// are reported and cause the command to fail.
+func TestTemplates_Test(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	builder := mock.NewBuilder()
+	builder.BuildFn = func(f fn.Function) error {
+		switch f.Runtime {
+		case "node":
+			return oci.ErrRuntimeNotSupported{Runtime: f.Runtime}
+		case "rust":
+			if f.Invoke == "cloudevent" {
+				return errors.New("compilation failed")
+			}
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	cmd := NewTemplatesTestCmd(NewTestClient(fn.WithBuilder(builder), fn.WithRegistry(TestRegistry)))
+	cmd.SetArgs([]string{"--json"})
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected the failed template to fail the command")
+	}
+	if !builder.BuildInvoked {
+		t.Fatal("builder was not invoked")
+	}
+
+	results := []templateTestResult{}
+	if err := json.NewDecoder(&out).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"go/http":          templateTestOK,
+		"node/http":        templateTestSkipped,
+		"rust/http":        templateTestOK,
+		"rust/cloudevents": templateTestFailed,
+	}
+	for _, r := range results {
+		if e, ok := expected[r.Runtime+"/"+r.Template]; ok && r.Result != e {
+			t.Errorf("expected %v/%v to be %v, got %v (%v)", r.Runtime, r.Template, e, r.Result, r.Message)
+		}
+	}
+	if len(results) != 16 {
+		t.Fatalf("expected 16 templates to be tested, got %v", len(results))
+	}
+}
@@ -37,7 +37,7 @@ DESCRIPTION
 			runVersion(cmd, version)
 		},
 	}
-	cfg, err := config.NewDefault()
+	cfg, err := newGlobalConfig()
 	if err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
 	}
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// defaultWorkspaceParallel is the number of functions of a workspace which are
+// built or deployed in parallel by default.
+const defaultWorkspaceParallel = 4
+
+func NewWorkspaceCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Manage the functions of a workspace, such as a monorepo",
+		Long: `Manage the functions of a workspace, such as a monorepo
+
+The functions of a workspace are those initialized in the current directory or
+that specified with --path, or in any of their subdirectories.  Hidden
+directories and node_modules are not searched.
+
+Functions which must be built and deployed before a function are listed in its
+'build.dependsOn', as paths relative to it.  A function is rebuilt whenever a
+function it depends on is.  Functions which are unchanged since they were last
+built, as per their fingerprint, are skipped.
+`,
+		Aliases: []string{"workspaces", "ws"},
+	}
+
+	cmd.AddCommand(NewWorkspaceListCmd())
+	cmd.AddCommand(NewWorkspaceBuildCmd(newClient))
+	cmd.AddCommand(NewWorkspaceDeployCmd(newClient))
+
+	return cmd
+}
+
+func NewWorkspaceListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the functions of a workspace in the order they are built",
+		Long: `List the functions of a workspace in the order they are built
+
+Lists the functions of the workspace, each after the functions it depends on,
+and whether they changed since they were last built.
+`,
+		Example: `
+# List the functions of the workspace in the current directory
+{{rootCmdUse}} workspace list
+`,
+		Aliases: []string{"ls"},
+		Args:    cobra.NoArgs,
+		PreRunE: bindEnv("path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkspaceList(cmd)
+		},
+	}
+
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, false)
+
+	return cmd
+}
+
+func NewWorkspaceBuildCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Build the functions of a workspace",
+		Long: `Build the functions of a workspace
+
+Builds the functions of the workspace which changed since they were last built,
+or whose dependencies did, each with its own builder, and optionally pushes
+their images.  Functions are built in parallel once the functions they depend
+on are, and a function is not built if one it depends on failed.  A table of
+the results is printed once all are done.
+`,
+		Example: `
+# Build the changed functions of the workspace in the current directory
+{{rootCmdUse}} workspace build
+
+# Build and push all functions of the workspace, 8 at a time
+{{rootCmdUse}} workspace build --push --force --parallel 8
+`,
+		Args: cobra.NoArgs,
+		PreRunE: bindEnv("path", "registry", "registry-insecure", "push",
+			"parallel", "force", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkspaceBuild(cmd, newClient)
+		},
+	}
+
+	addWorkspaceFlags(cmd)
+	cmd.Flags().BoolP("push", "u", false,
+		"Push the images of the functions built to the registry ($FUNC_PUSH)")
+
+	return cmd
+}
+
+func NewWorkspaceDeployCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Build and deploy the functions of a workspace",
+		Long: `Build and deploy the functions of a workspace
+
+Builds, pushes and deploys the functions of the workspace which changed since
+they were last deployed, or whose dependencies did, each with its own builder
+and to its own namespace.  Functions are deployed in parallel once the
+functions they depend on are, and a function is not deployed if one it depends
+on failed.  A table of the results is printed once all are done.
+`,
+		Example: `
+# Deploy the changed functions of the workspace in the current directory
+{{rootCmdUse}} workspace deploy
+
+# Deploy all functions of the workspace under ./functions, one at a time
+{{rootCmdUse}} workspace deploy --path ./functions --force --parallel 1
+`,
+		Args: cobra.NoArgs,
+		PreRunE: bindEnv("path", "registry", "registry-insecure", "parallel",
+			"force", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorkspaceDeploy(cmd, newClient)
+		},
+	}
+
+	addWorkspaceFlags(cmd)
+
+	return cmd
+}
+
+// addWorkspaceFlags adds the flags shared by the workspace build and deploy
+// commands.
+func addWorkspaceFlags(cmd *cobra.Command) {
+	cfg, err := newGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().StringP("registry", "r", cfg.Registry,
+		"Container registry + registry namespace of the functions which have none. (ex 'ghcr.io/myuser') ($FUNC_REGISTRY)")
+	cmd.Flags().Bool("registry-insecure", cfg.RegistryInsecure, "Skip TLS certificate verification when communicating in HTTPS with the registry ($FUNC_REGISTRY_INSECURE)")
+	cmd.Flags().IntP("parallel", "j", defaultWorkspaceParallel,
+		"Number of functions built in parallel ($FUNC_PARALLEL)")
+	cmd.Flags().BoolP("force", "f", false,
+		"Include the functions which are unchanged since they were last built ($FUNC_FORCE)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+}
+
+type workspaceConfig struct {
+	// Globals (registry, verbose)
+	config.Global
+
+	// Path of the root of the workspace.
+	Path string
+
+	// Parallel is the maximum number of functions built at once.
+	Parallel int
+
+	// Force building the functions which are unchanged.
+	Force bool
+
+	// Push the images of the functions built.
+	Push bool
+}
+
+func newWorkspaceConfig() workspaceConfig {
+	return workspaceConfig{
+		Global: config.Global{
+			Registry:         registry(), // deferred defaulting
+			Verbose:          viper.GetBool("verbose"),
+			RegistryInsecure: viper.GetBool("registry-insecure"),
+		},
+		Path:     viper.GetString("path"),
+		Parallel: viper.GetInt("parallel"),
+		Force:    viper.GetBool("force"),
+		Push:     viper.GetBool("push"),
+	}
+}
+
+// newClient returns a client building the function with its builder, and
+// deploying it to its platform.
+func (c workspaceConfig) newClient(f fn.Function, newClient ClientFactory) (*fn.Client, func(), error) {
+	dc := deployConfig{buildConfig: buildConfig{Global: c.Global}}
+	dc.Builder = f.Build.Builder
+	if dc.Builder == "" {
+		dc.Builder = builders.Default
+	}
+	dc.Deployer = f.Deploy.Platform
+	clientOptions, err := dc.clientOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	client, done := newClient(ClientConfig{Verbose: c.Verbose, InsecureSkipVerify: c.RegistryInsecure}, clientOptions...)
+	return client, done, nil
+}
+
+// build the function unless it is unchanged, and push it if requested and
+// not yet pushed.  Returned is whether it was built or pushed.
+func (c workspaceConfig) build(ctx context.Context, client *fn.Client, f fn.Function, dependenciesChanged, push bool) (fn.Function, bool, error) {
+	var (
+		err     error
+		changed = c.Force || dependenciesChanged || !f.Built()
+		pushed  = false
+	)
+	if !changed {
+		pushed, _ = isDigested(f.Build.Image)
+	}
+	if changed {
+		if f, err = client.Build(ctx, f); err != nil {
+			return f, false, err
+		}
+	}
+	if push && !pushed {
+		if f, _, err = client.Push(ctx, f); err != nil {
+			return f, false, err
+		}
+		changed = true
+	}
+	if !changed {
+		return f, false, nil
+	}
+	if err = f.Write(); err != nil {
+		return f, false, err
+	}
+	return f, true, f.Stamp()
+}
+
+func runWorkspaceList(cmd *cobra.Command) (err error) {
+	w, err := fn.NewWorkspace(viper.GetString("path"))
+	if err != nil {
+		return
+	}
+	if len(w.Functions) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No functions found in %v\n", w.Root)
+		return
+	}
+
+	tabWriter := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\t%s\n", "PATH", "NAME", "RUNTIME", "BUILDER", "DEPENDS ON", "STATUS")
+	for i, f := range w.Functions {
+		var deps []string
+		for _, d := range w.Dependencies(i) {
+			deps = append(deps, w.Path(d))
+		}
+		status := "changed"
+		if f.Built() {
+			status = string(fn.WorkspaceUnchanged)
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\t%s\n", w.Path(f), f.Name, f.Runtime, f.Build.Builder, strings.Join(deps, ","), status)
+	}
+	return
+}
+
+func runWorkspaceBuild(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	cfg := newWorkspaceConfig()
+	w, err := fn.NewWorkspace(cfg.Path)
+	if err != nil {
+		return
+	}
+
+	out := cmd.OutOrStdout()
+	results := w.Run(cmd.Context(), cfg.Parallel, func(ctx context.Context, f fn.Function, dependenciesChanged bool) (fn.Function, bool, error) {
+		client, done, err := cfg.newClient(f, newClient)
+		if err != nil {
+			return f, false, err
+		}
+		defer done()
+
+		fmt.Fprintf(out, "Building %v\n", w.Path(f))
+		f, built, err := cfg.build(ctx, client, f, dependenciesChanged, cfg.Push)
+		return f, !built, err
+	})
+	return printWorkspaceResults(out, w, results, func(f fn.Function) string { return f.Build.Image })
+}
+
+func runWorkspaceDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	cfg := newWorkspaceConfig()
+	w, err := fn.NewWorkspace(cfg.Path)
+	if err != nil {
+		return
+	}
+
+	out := cmd.OutOrStdout()
+	results := w.Run(cmd.Context(), cfg.Parallel, func(ctx context.Context, f fn.Function, dependenciesChanged bool) (fn.Function, bool, error) {
+		client, done, err := cfg.newClient(f, newClient)
+		if err != nil {
+			return f, false, err
+		}
+		defer done()
+
+		fmt.Fprintf(out, "Deploying %v\n", w.Path(f))
+		f, built, err := cfg.build(ctx, client, f, dependenciesChanged, true)
+		if err != nil {
+			return f, false, err
+		}
+		// Unchanged functions whose image was deployed already are skipped
+		if !built && f.Deploy.Image != "" && f.Deploy.Image == f.Build.Image {
+			return f, true, nil
+		}
+		f.Deploy.Image = f.Build.Image
+		if f, err = client.Deploy(ctx, f); err != nil {
+			return f, false, err
+		}
+		if err = f.Write(); err != nil {
+			return f, false, err
+		}
+		return f, false, f.Stamp()
+	})
+	return printWorkspaceResults(out, w, results, func(f fn.Function) string { return f.Deploy.Image })
+}
+
+// printWorkspaceResults prints the results as a table, with the given detail
+// of the functions which succeeded, and the error of those which did not.
+// Errors if any function did not succeed.
+func printWorkspaceResults(w io.Writer, ws fn.Workspace, results []fn.WorkspaceResult, detail func(fn.Function) string) error {
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", "PATH", "NAME", "STATUS", "DURATION", "DETAIL")
+	failed := 0
+	for _, r := range results {
+		d := ""
+		switch r.Status {
+		case fn.WorkspaceSucceeded:
+			d = detail(r.Function)
+		case fn.WorkspaceFailed, fn.WorkspaceBlocked:
+			d = r.Err.Error()
+			failed++
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", ws.Path(r.Function), r.Function.Name, r.Status, formatDuration(r.Duration.Round(10*time.Millisecond)), d)
+	}
+	tabWriter.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%v of %v functions failed", failed, len(results))
+	}
+	return nil
+}
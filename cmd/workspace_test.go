@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestWorkspaceDeploy ensures the functions of a workspace are deployed, each
+// after those it depends on, that unchanged functions are skipped on
+// subsequent deployments, and that those depending on changed functions are
+// redeployed.
+func TestWorkspaceDeploy(t *testing.T) {
+	root := FromTempDirectory(t)
+	for p, deps := range map[string][]string{"users": nil, "api": {"../users"}} {
+		f := fn.Function{Runtime: "go", Registry: TestRegistry, Root: filepath.Join(root, p), Build: fn.BuildSpec{DependsOn: deps}}
+		if err := os.Mkdir(f.Root, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fn.New().Init(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var built, deployed []string
+	builder := mock.NewBuilder()
+	builder.BuildFn = func(f fn.Function) error {
+		built = append(built, f.Name)
+		return nil
+	}
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(context.Context, fn.Function) (string, error) {
+		return "sha256:" + strings.Repeat("a", 64), nil
+	}
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		deployed = append(deployed, f.Name)
+		return fn.DeploymentResult{Namespace: "default"}, nil
+	}
+	newClient := NewTestClient(fn.WithBuilder(builder), fn.WithPusher(pusher), fn.WithDeployer(deployer))
+
+	deploy := func() string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := NewWorkspaceCmd(newClient)
+		cmd.SetArgs([]string{"deploy", "--parallel", "1"})
+		cmd.SetOut(&out)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	out := deploy()
+	if strings.Join(built, ",") != "users,api" || strings.Join(deployed, ",") != "users,api" {
+		t.Fatalf("expected users then api to be deployed, built %v and deployed %v", built, deployed)
+	}
+	if strings.Count(out, string(fn.WorkspaceSucceeded)) != 2 {
+		t.Fatalf("expected both functions to succeed:\n%s", out)
+	}
+
+	built, deployed = nil, nil
+	out = deploy()
+	if len(built) != 0 || len(deployed) != 0 {
+		t.Fatalf("expected unchanged functions to be skipped, built %v and deployed %v", built, deployed)
+	}
+	if strings.Count(out, string(fn.WorkspaceUnchanged)) != 2 {
+		t.Fatalf("expected both functions unchanged:\n%s", out)
+	}
+
+	// a change of users rebuilds api, which depends on it
+	built, deployed = nil, nil
+	if err := os.WriteFile(filepath.Join(root, "users", "handle.go"), []byte("package function\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deploy()
+	if strings.Join(built, ",") != "users,api" || strings.Join(deployed, ",") != "users,api" {
+		t.Fatalf("expected users and its dependents to be redeployed, built %v and deployed %v", built, deployed)
+	}
+}
+
+// TestWorkspaceBuild_Failed ensures a failed build fails the command, and
+// blocks the functions which depend on it.
+func TestWorkspaceBuild_Failed(t *testing.T) {
+	root := FromTempDirectory(t)
+	for p, deps := range map[string][]string{"users": nil, "api": {"../users"}} {
+		f := fn.Function{Runtime: "go", Registry: TestRegistry, Root: filepath.Join(root, p), Build: fn.BuildSpec{DependsOn: deps}}
+		if err := os.Mkdir(f.Root, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fn.New().Init(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	builder := mock.NewBuilder()
+	builder.BuildFn = func(f fn.Function) error {
+		if f.Name == "users" {
+			return os.ErrPermission
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	cmd := NewWorkspaceCmd(NewTestClient(fn.WithBuilder(builder)))
+	cmd.SetArgs([]string{"build"})
+	cmd.SetOut(&out)
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "2 of 2 functions failed") {
+		t.Fatalf("expected the build to fail, got %v", err)
+	}
+	if !strings.Contains(out.String(), string(fn.WorkspaceBlocked)) {
+		t.Fatalf("expected api to be blocked:\n%s", out.String())
+	}
+}
+
+// TestWorkspace_NewClientPlatform ensures each function of a workspace is
+// deployed with the deployer of its own platform.
+func TestWorkspace_NewClientPlatform(t *testing.T) {
+	c := workspaceConfig{}
+	for _, platform := range []string{"", "knative", "kubernetes", "docker"} {
+		if _, _, err := c.newClient(fn.Function{Deploy: fn.DeploySpec{Platform: platform}}, NewTestClient()); err != nil {
+			t.Errorf("unexpected error for platform %q: %v", platform, err)
+		}
+	}
+	if _, _, err := c.newClient(fn.Function{Deploy: fn.DeploySpec{Platform: "unknown"}}, NewTestClient()); err == nil {
+		t.Error("expected an error for an unknown platform")
+	}
+}
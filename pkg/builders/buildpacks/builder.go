@@ -150,6 +150,8 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		}
 		excludes = strings.Split(buf.String(), "\n")
 	}
+	// Neither runtime data nor the function's managed Secrets are built.
+	excludes = append(excludes, "/"+fn.RunDataDir, "/"+fn.ResourcesDir)
 	// Pack build options
 	opts := pack.BuildOptions{
 		AppPath:        f.Root,
@@ -171,7 +173,7 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		now := time.Now()
 		opts.CreationTime = &now
 	}
-	if opts.Env, err = fn.Interpolate(f.Build.BuildEnvs); err != nil {
+	if opts.Env, err = fn.InterpolateIn(f.Root, f.Build.BuildEnvs); err != nil {
 		return err
 	}
 	if runtime.GOOS == "linux" {
@@ -117,8 +117,8 @@ func TestBuild_BuilderImageConfigurable(t *testing.T) {
 	}
 }
 
-// TestBuild_BuilderImageExclude ensures that ignored files are not added to the func
-// image
+// TestBuild_BuilderImageExclude ensures that ignored files, and the runtime
+// data directory, are not added to the func image
 func TestBuild_BuilderImageExclude(t *testing.T) {
 	var (
 		i = &mockImpl{} // mock underlying implementation
@@ -142,11 +142,17 @@ hello.txt`)
 	}
 
 	i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
-		if len(opts.ProjectDescriptor.Build.Exclude) != 2 {
-			t.Fatalf("expected 2 lines of exclusions , got %v", len(opts.ProjectDescriptor.Build.Exclude))
+		if len(opts.ProjectDescriptor.Build.Exclude) != 4 {
+			t.Fatalf("expected 4 lines of exclusions , got %v", len(opts.ProjectDescriptor.Build.Exclude))
 		}
 		if opts.ProjectDescriptor.Build.Exclude[1] != expected[0] {
-			t.Fatalf("expected excluded file to be '%v', got '%v'", expected[1], opts.ProjectDescriptor.Build.Exclude[1])
+			t.Fatalf("expected excluded file to be '%v', got '%v'", expected[0], opts.ProjectDescriptor.Build.Exclude[1])
+		}
+		if opts.ProjectDescriptor.Build.Exclude[2] != "/.func" {
+			t.Fatalf("expected the runtime data directory to be excluded, got '%v'", opts.ProjectDescriptor.Build.Exclude[2])
+		}
+		if opts.ProjectDescriptor.Build.Exclude[3] != "/.funcresources" {
+			t.Fatalf("expected the managed resources to be excluded, got '%v'", opts.ProjectDescriptor.Build.Exclude[3])
 		}
 		return nil
 	}
@@ -207,7 +207,8 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 	}
 
 	// Excludes
-	// Do not include .git, .env, .func or any language-specific cache directories
+	// Do not include .git, .env, .func (which also matches .funcresources, the
+	// function's managed Secrets) or any language-specific cache directories
 	// (node_modules, etc) in the tar file sent to the builder, as this both
 	// bloats the build process and can cause unexpected errors in the resultant
 	// function.
@@ -216,7 +217,7 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 	// Environment variables
 	// Build Envs have local env var references interpolated then added to the
 	// config as an S2I EnvironmentList struct
-	buildEnvs, err := fn.Interpolate(f.Build.BuildEnvs)
+	buildEnvs, err := fn.InterpolateIn(f.Root, f.Build.BuildEnvs)
 	if err != nil {
 		return err
 	}
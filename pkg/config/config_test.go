@@ -1,10 +1,12 @@
 package config_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
@@ -337,6 +339,177 @@ func TestSet_ValidTyped(t *testing.T) {
 	// include types of additional values.
 }
 
+// TestSet_Duration ensures that durations can be set from both their string
+// and typed values.
+func TestSet_Duration(t *testing.T) {
+	cfg, err := config.Set(config.Global{}, "buildTimeout", "10m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BuildTimeout != 10*time.Minute {
+		t.Fatalf("unexpected build timeout: %v", cfg.BuildTimeout)
+	}
+	if _, err = config.Set(cfg, "buildTimeout", "ten minutes"); err == nil {
+		t.Fatal("expected an error setting an invalid duration")
+	}
+	if cfg, err = config.SetDuration(cfg, "buildTimeout", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if config.Get(cfg, "buildTimeout") != time.Hour {
+		t.Fatalf("unexpected build timeout: %v", cfg.BuildTimeout)
+	}
+}
+
+// TestGet_ExactName ensures that members are matched by their full name
+// rather than by prefix.
+func TestGet_ExactName(t *testing.T) {
+	cfg := config.Global{RegistryInsecure: true}
+	if v := config.Get(cfg, "registryInsecure"); v != true {
+		t.Fatalf("unexpected value for registryInsecure: %v", v)
+	}
+	if v := config.Get(cfg, "reg"); v != nil {
+		t.Fatalf("expected a partial name to return nil, got: %v", v)
+	}
+	if v := config.Get(cfg, "profiles"); v != nil {
+		t.Fatalf("expected profiles to not be accessible, got: %v", v)
+	}
+}
+
+// TestUnset ensures that unsetting a member returns it to its zero value.
+func TestUnset(t *testing.T) {
+	cfg := config.Global{Builder: "pack", Confirm: true}
+	cfg, err := config.Unset(cfg, "builder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = config.Unset(cfg, "confirm"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Builder != "" || cfg.Confirm {
+		t.Fatalf("values not unset: %+v", cfg)
+	}
+	if _, err = config.Unset(cfg, "invalid"); err == nil {
+		t.Fatal("did not receive expected error unsetting a nonexistent field")
+	}
+}
+
+// TestNewDefault_Profile ensures that the settings of the profile selected
+// by FUNC_PROFILE are applied atop those of the config file.
+func TestNewDefault_Profile(t *testing.T) {
+	root, rm := Mktemp(t)
+	t.Cleanup(rm)
+	t.Setenv("XDG_CONFIG_HOME", root)
+	if err := config.CreatePaths(); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Global{
+		Language:  "go",
+		Registry:  "example.com/alice",
+		Namespace: "default",
+		Profiles: map[string]config.Global{
+			"kind-local": {
+				Registry:     "localhost:50000/func",
+				KubeContext:  "kind-func",
+				BuildTimeout: 5 * time.Minute,
+			},
+		},
+	}
+	if err := cfg.Write(config.File()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("FUNC_PROFILE", "kind-local")
+	cfg, err := config.NewDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Registry != "localhost:50000/func" || cfg.KubeContext != "kind-func" || cfg.BuildTimeout != 5*time.Minute {
+		t.Fatalf("profile not applied: %+v", cfg)
+	}
+	if cfg.Language != "go" || cfg.Namespace != "default" {
+		t.Fatalf("unset profile values should retain those of the file: %+v", cfg)
+	}
+
+	t.Setenv("FUNC_PROFILE", "work-cluster")
+	if _, err = config.NewDefault(); !errors.Is(err, config.ErrProfileNotFound) {
+		t.Fatalf("expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+// TestNewDefault_ProfileZeroValues ensures that settings a profile defines as
+// false or empty are applied, whether defined in the file or set, and that
+// those it leaves undefined retain the values of the file.
+func TestNewDefault_ProfileZeroValues(t *testing.T) {
+	root, rm := Mktemp(t)
+	t.Cleanup(rm)
+	t.Setenv("XDG_CONFIG_HOME", root)
+	if err := config.CreatePaths(); err != nil {
+		t.Fatal(err)
+	}
+	file := `confirm: true
+kubeContext: work
+platform: linux/arm64
+registry: example.com/alice
+profiles:
+  local:
+    confirm: false
+    kubeContext: ""
+`
+	if err := os.WriteFile(config.File(), []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("FUNC_PROFILE", "local")
+	cfg, err := config.NewDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Confirm || cfg.KubeContext != "" {
+		t.Fatalf("zero values of the profile not applied: %+v", cfg)
+	}
+	if cfg.Platform != "linux/arm64" || cfg.Registry != "example.com/alice" {
+		t.Fatalf("undefined profile values should retain those of the file: %+v", cfg)
+	}
+
+	// Clearing a setting of the profile persists it as explicitly empty
+	cfg, err = config.Load(config.File())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := config.Set(cfg.Profiles["local"], "platform", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Profiles["local"] = p
+	if err = cfg.Write(config.File()); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = config.NewDefault(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Confirm || cfg.KubeContext != "" || cfg.Platform != "" {
+		t.Fatalf("zero values of the profile not persisted: %+v", cfg)
+	}
+
+	// Unsetting a setting of the profile reverts it to that of the file
+	if cfg, err = config.Load(config.File()); err != nil {
+		t.Fatal(err)
+	}
+	if p, err = config.Unset(cfg.Profiles["local"], "confirm"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Profiles["local"] = p
+	if err = cfg.Write(config.File()); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = config.NewDefault(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Confirm {
+		t.Fatalf("unset profile value should be that of the file: %+v", cfg)
+	}
+}
+
 // TestSet_ValidStrings ensures that setting valid attribute names using
 // the string representation of their values succeeds.
 func TestSet_ValidStrings(t *testing.T) {
@@ -372,12 +545,18 @@ func TestSet_ValidStrings(t *testing.T) {
 func TestList(t *testing.T) {
 	values := config.List()
 	expected := []string{
+		"buildTimeout",
 		"builder",
 		"confirm",
+		"credentialHelper",
+		"dnsProvider",
+		"kubeContext",
 		"language",
 		"namespace",
+		"platform",
 		"registry",
 		"registryInsecure",
+		"templateRepository",
 		"verbose",
 	}
 
@@ -0,0 +1,106 @@
+/*
+Package crypt provides the symmetric encryption of the values of Secrets
+managed with a function.
+
+Values are encrypted individually (such that changes to a single value are
+apparent in version control) using NaCl secretbox with a key held in the
+user's config directory, and are stored as strings of the form:
+
+	ENC[secretbox,<base64 nonce and ciphertext>]
+*/
+package crypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+const (
+	prefix = "ENC[secretbox,"
+	suffix = "]"
+)
+
+// ErrKeyNotFound indicates the key file does not exist.
+var ErrKeyNotFound = errors.New("secrets key not found")
+
+// ErrDecrypt indicates a value could not be decrypted using the key: either
+// the value was encrypted using a different key, or it has been altered.
+var ErrDecrypt = errors.New("unable to decrypt value")
+
+// Key used to encrypt and decrypt values.
+type Key [32]byte
+
+// NewKey returns a new random Key.
+func NewKey() (k Key, err error) {
+	_, err = io.ReadFull(rand.Reader, k[:])
+	return
+}
+
+// LoadKey from the given file, in which it is stored base64 encoded.  If the
+// file does not exist and create is true, a new key is written to the file.
+func LoadKey(path string, create bool) (k Key, err error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if !create {
+			return k, fmt.Errorf("%w at %v", ErrKeyNotFound, path)
+		}
+		if k, err = NewKey(); err != nil {
+			return
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return
+		}
+		return k, os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(k[:])+"\n"), 0600)
+	} else if err != nil {
+		return
+	}
+	d, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(d) != len(k) {
+		return k, fmt.Errorf("invalid secrets key at %v", path)
+	}
+	copy(k[:], d)
+	return
+}
+
+// Encrypt the given value.
+func (k Key) Encrypt(value []byte) (string, error) {
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return "", err
+	}
+	key := [32]byte(k)
+	sealed := secretbox.Seal(nonce[:], value, &nonce, &key)
+	return prefix + base64.StdEncoding.EncodeToString(sealed) + suffix, nil
+}
+
+// Decrypt the given value.  Values which are not encrypted are an error.
+func (k Key) Decrypt(value string) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return nil, fmt.Errorf("%w: value is not encrypted", ErrDecrypt)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(value[len(prefix) : len(value)-len(suffix)])
+	if err != nil || len(sealed) < 24 {
+		return nil, fmt.Errorf("%w: value is malformed", ErrDecrypt)
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed[:24])
+	key := [32]byte(k)
+	opened, ok := secretbox.Open(nil, sealed[24:], &nonce, &key)
+	if !ok {
+		return nil, ErrDecrypt
+	}
+	return opened, nil
+}
+
+// IsEncrypted returns true if the value is of the form of an encrypted value.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix)
+}
@@ -0,0 +1,73 @@
+//go:build !integration
+// +build !integration
+
+package crypt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestKey_EncryptDecrypt ensures that values round-trip, are not stored in
+// plain text, and can not be decrypted with a different key.
+func TestKey_EncryptDecrypt(t *testing.T) {
+	key, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := key.Encrypt([]byte("s3cr3t"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Fatalf("expected an encrypted value, got %q", encrypted)
+	}
+	decrypted, err := key.Decrypt(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decrypted) != "s3cr3t" {
+		t.Fatalf("expected 's3cr3t', got %q", decrypted)
+	}
+
+	other, err := NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = other.Decrypt(encrypted); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt using a different key, got %v", err)
+	}
+	if _, err = key.Decrypt("s3cr3t"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt of a plain value, got %v", err)
+	}
+}
+
+// TestLoadKey ensures that a key is created only if requested, and that the
+// same key is subsequently loaded.
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "func", "secrets.key")
+
+	if _, err := LoadKey(path, false); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+	created, err := LoadKey(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("expected key file mode 0600, got %v", fi.Mode().Perm())
+	}
+	loaded, err := LoadKey(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != created {
+		t.Fatal("loaded key differs from that created")
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Package deployers provides constants for deployer implementation short names
+(the platforms to which a function may be deployed) and shared error types.
+*/
+package deployers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	Knative    = "knative"
+	Kubernetes = "kubernetes"
+	Docker     = "docker"
+	Default    = Knative
+)
+
+// Known deployer names with a pretty-printed string representation
+type Known []string
+
+func All() Known {
+	return Known([]string{Knative, Kubernetes, Docker})
+}
+
+func (k Known) String() string {
+	var b strings.Builder
+	for i, v := range k {
+		if i < len(k)-2 {
+			b.WriteString(strconv.Quote(v) + ", ")
+		} else if i < len(k)-1 {
+			b.WriteString(strconv.Quote(v) + " and ")
+		} else {
+			b.WriteString(strconv.Quote(v))
+		}
+	}
+	return b.String()
+}
+
+// ErrUnknownDeployer may be used by whomever is choosing a concrete
+// implementation of a deployer to invoke based on potentially invalid input.
+type ErrUnknownDeployer struct {
+	Name  string
+	Known Known
+}
+
+func (e ErrUnknownDeployer) Error() string {
+	if len(e.Known) == 0 {
+		return fmt.Sprintf("\"%v\" is not a known deployer", e.Name)
+	}
+	if len(e.Known) == 1 {
+		return fmt.Sprintf("\"%v\" is not a known deployer. The available deployer is %v", e.Name, e.Known)
+	}
+	return fmt.Sprintf("\"%v\" is not a known deployer. Available deployers are %s", e.Name, e.Known)
+}
@@ -0,0 +1,28 @@
+package deployers_test
+
+import (
+	"testing"
+
+	"knative.dev/func/pkg/deployers"
+)
+
+// Test_ErrUnknownDeployer ensures that the error properly formats.
+func Test_ErrUnknownDeployer(t *testing.T) {
+	var tests = []struct {
+		Known    []string
+		Expected string
+	}{
+		{[]string{},
+			`"test" is not a known deployer`},
+		{[]string{"knative"},
+			`"test" is not a known deployer. The available deployer is "knative"`},
+		{[]string{"knative", "kubernetes"},
+			`"test" is not a known deployer. Available deployers are "knative" and "kubernetes"`},
+	}
+	for _, test := range tests {
+		e := deployers.ErrUnknownDeployer{Name: "test", Known: test.Known}
+		if e.Error() != test.Expected {
+			t.Fatalf("expected error \"%v\". got \"%v\"", test.Expected, e.Error())
+		}
+	}
+}
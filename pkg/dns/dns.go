@@ -0,0 +1,118 @@
+/*
+Package dns provides a DNS provider which publishes the custom domains of
+deployed functions (deploy.domains) to a pluggable backend, such as a hosts
+file for local clusters or a DNS server accepting RFC 2136 dynamic updates.
+*/
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+const (
+	// DefaultAddress to which the hosts of functions are resolved if none is
+	// configured, suitable for local clusters with a forwarded ingress port.
+	DefaultAddress = "127.0.0.1"
+
+	// DefaultHostsFile updated by the hosts backend.
+	DefaultHostsFile = "/etc/hosts"
+
+	// DefaultTTL of records published with dynamic updates.
+	DefaultTTL = 300 * time.Second
+)
+
+// Backend to which the records of functions are written.
+type Backend interface {
+	// Update the records of the owner (a deployed function) such that exactly
+	// the given hosts resolve to the backend's address.
+	Update(ctx context.Context, owner string, hosts []string) error
+}
+
+// Provider publishes the domains of functions to a backend.
+type Provider struct {
+	backend Backend
+}
+
+var _ fn.DNSProvider = (*Provider)(nil)
+
+// NewProvider creates a provider which publishes records to the given
+// backend.
+func NewProvider(backend Backend) *Provider {
+	return &Provider{backend: backend}
+}
+
+// Provide the function's domains by resolving them to the backend's
+// address.  Domains removed from the function are unpublished where the
+// backend supports it.
+func (p *Provider) Provide(f fn.Function) error {
+	hosts := make([]string, 0, len(f.Deploy.Domains))
+	for _, d := range f.Deploy.Domains {
+		hosts = append(hosts, d.Host)
+	}
+	owner := f.Name + "." + f.Deploy.Namespace
+	if err := p.backend.Update(context.Background(), owner, hosts); err != nil {
+		return fmt.Errorf("unable to provide the DNS records of %v: %w", owner, err)
+	}
+	return nil
+}
+
+// NewBackend parses a backend from its URL, which is one of:
+//
+//	hosts:///etc/hosts?address=127.0.0.1
+//	rfc2136://[keyname:secret@]server[:port]/zone?address=192.0.2.1[&ttl=5m][&algorithm=hmac-sha256]
+//
+// The address is that to which hosts resolve, and may be a hostname to which
+// an RFC 2136 backend publishes a CNAME.  The secret of a TSIG key is base64
+// encoded, as in a BIND key file.
+func NewBackend(rawURL string) (Backend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNS provider %q: %w", rawURL, err)
+	}
+	q := u.Query()
+	address := q.Get("address")
+	if address == "" {
+		address = DefaultAddress
+	}
+
+	switch u.Scheme {
+	case "hosts":
+		path := u.Path
+		if path == "" {
+			path = DefaultHostsFile
+		}
+		return NewHostsFile(path, address), nil
+	case "rfc2136":
+		zone := strings.Trim(u.Path, "/")
+		if u.Host == "" || zone == "" {
+			return nil, fmt.Errorf("invalid DNS provider %q: a server and zone are required, as rfc2136://server/zone", rawURL)
+		}
+		server := u.Host
+		if u.Port() == "" {
+			server = net.JoinHostPort(u.Hostname(), "53")
+		}
+		ttl := DefaultTTL
+		if v := q.Get("ttl"); v != "" {
+			if ttl, err = time.ParseDuration(v); err != nil {
+				return nil, fmt.Errorf("invalid DNS provider ttl %q: %w", v, err)
+			}
+		}
+		backend := NewRFC2136(server, zone, address, ttl)
+		if u.User != nil {
+			secret, _ := u.User.Password()
+			if err = backend.SetKey(u.User.Username(), secret, q.Get("algorithm")); err != nil {
+				return nil, err
+			}
+		}
+		return backend, nil
+	}
+	return nil, fmt.Errorf("invalid DNS provider %q: expected a scheme of %v or %v", rawURL, strconv.Quote("hosts"), strconv.Quote("rfc2136"))
+}
@@ -0,0 +1,70 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// hostsMarker suffixes the lines of a hosts file written for an owner.
+const hostsMarker = "# func:"
+
+// HostsFile is a backend which resolves hosts by writing them to a hosts
+// file, for clusters reachable only from the local machine.  Each line is
+// marked with its owner, such that lines of others are left untouched.
+type HostsFile struct {
+	path    string
+	address string
+}
+
+// NewHostsFile creates a backend which writes to the hosts file at path,
+// resolving hosts to address.
+func NewHostsFile(path, address string) *HostsFile {
+	return &HostsFile{path: path, address: address}
+}
+
+// Update the owner's lines of the hosts file.
+func (h *HostsFile) Update(_ context.Context, owner string, hosts []string) error {
+	mode := fs.FileMode(0644)
+	content, err := os.ReadFile(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if len(hosts) == 0 {
+			return nil
+		}
+	} else if err != nil {
+		return err
+	} else if info, err := os.Stat(h.path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	marker := hostsMarker + owner
+	var (
+		out     bytes.Buffer
+		changed bool
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, " "+marker) {
+			changed = true
+			continue
+		}
+		out.WriteString(line + "\n")
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		fmt.Fprintf(&out, "%v\t%v %v\n", h.address, host, marker)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return os.WriteFile(h.path, out.Bytes(), mode)
+}
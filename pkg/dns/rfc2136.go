@@ -0,0 +1,358 @@
+package dns
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// opcodeUpdate is the DNS opcode of dynamic updates (RFC 2136).
+	opcodeUpdate = dnsmessage.OpCode(5)
+
+	// typeTSIG is the resource record type of transaction signatures.
+	typeTSIG = 250
+
+	// tsigFudge is the permitted clock skew of signed requests, in seconds.
+	tsigFudge = 300
+
+	// ownerLabel prefixes the name of the TXT record of an owner's hosts.
+	ownerLabel = "_func"
+)
+
+// tsigAlgorithms supported for signing updates, by name.
+var tsigAlgorithms = map[string]func() hash.Hash{
+	"hmac-sha1":   sha1.New,
+	"hmac-sha256": sha256.New,
+	"hmac-sha512": sha512.New,
+}
+
+// RFC2136 is a backend which publishes hosts with dynamic DNS updates
+// (RFC 2136), such as to BIND, optionally signed with a TSIG key (RFC 8945).
+// Each host is replaced with an A or AAAA record of the address, or a CNAME
+// if the address is a hostname.  The hosts published for an owner are
+// recorded in a TXT record of the zone (see ownerRecord), such that those
+// since removed are deleted.
+type RFC2136 struct {
+	server  string
+	zone    string
+	address string
+	ttl     time.Duration
+
+	keyName   string
+	keySecret []byte
+	algorithm string
+}
+
+// NewRFC2136 creates a backend which sends updates of the zone to the
+// server (host:port), resolving hosts to address.
+func NewRFC2136(server, zone, address string, ttl time.Duration) *RFC2136 {
+	return &RFC2136{server: server, zone: zone, address: address, ttl: ttl}
+}
+
+// SetKey with which updates are signed.  The secret is base64 encoded, and
+// the algorithm defaults to hmac-sha256.
+func (r *RFC2136) SetKey(name, secret, algorithm string) (err error) {
+	if algorithm == "" {
+		algorithm = "hmac-sha256"
+	}
+	if _, ok := tsigAlgorithms[algorithm]; !ok {
+		return fmt.Errorf("unsupported TSIG algorithm %q", algorithm)
+	}
+	if r.keySecret, err = base64.StdEncoding.DecodeString(secret); err != nil {
+		return fmt.Errorf("invalid TSIG secret of key %q: %w", name, err)
+	}
+	r.keyName = name
+	r.algorithm = algorithm
+	return
+}
+
+// Update the records of each host, and delete those of the hosts published
+// for the owner before which are not among them.  The changes are sent as a
+// single update, which also records the hosts of the owner.
+func (r *RFC2136) Update(ctx context.Context, owner string, hosts []string) error {
+	for _, host := range hosts {
+		if !r.inZone(host) {
+			return fmt.Errorf("host %q is not in zone %q", host, r.zone)
+		}
+	}
+	published, err := r.published(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("unable to read the hosts published for %v: %w", owner, err)
+	}
+	wanted := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		wanted[host] = true
+	}
+	var removed []string
+	for _, host := range published {
+		// Hosts of the record not in the zone are not deleted, as the record
+		// is not necessarily this backend's.
+		if !wanted[host] && r.inZone(host) {
+			removed = append(removed, host)
+		}
+	}
+	if len(hosts) == 0 && len(published) == 0 {
+		return nil
+	}
+
+	msg, err := r.updateMessage(owner, hosts, removed, uint16(rand.Intn(1<<16)), time.Now())
+	if err != nil {
+		return err
+	}
+	if _, err = r.send(ctx, msg); err != nil {
+		return fmt.Errorf("dynamic update of %v failed: %w", owner, err)
+	}
+	return nil
+}
+
+// inZone returns true if the host is the zone or one of its subdomains.
+func (r *RFC2136) inZone(host string) bool {
+	return host == r.zone || strings.HasSuffix(host, "."+r.zone)
+}
+
+// ownerRecord is the name of the TXT record of the hosts published for the
+// owner, such as _func.myfunc.ns.example.com for function myfunc of namespace
+// ns.
+func (r *RFC2136) ownerRecord(owner string) string {
+	return ownerLabel + "." + owner + "." + r.zone
+}
+
+// published returns the hosts recorded for the owner by the last update,
+// which are none if there is no record.
+func (r *RFC2136) published(ctx context.Context, owner string) (hosts []string, err error) {
+	name, err := dnsmessage.NewName(fqdn(r.ownerRecord(owner)))
+	if err != nil {
+		return nil, err
+	}
+	id := uint16(rand.Intn(1 << 16))
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id})
+	if err = b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err = b.Question(dnsmessage.Question{Name: name, Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		return nil, err
+	}
+	if r.keyName != "" {
+		msg = r.sign(msg, id, time.Now())
+	}
+
+	res, err := r.send(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	var p dnsmessage.Parser
+	if _, err = p.Start(res); err != nil {
+		return nil, err
+	}
+	if err = p.SkipAllQuestions(); err != nil {
+		return nil, err
+	}
+	for {
+		h, err := p.AnswerHeader()
+		if err == dnsmessage.ErrSectionDone {
+			return hosts, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if h.Type != dnsmessage.TypeTXT {
+			if err = p.SkipAnswer(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		txt, err := p.TXTResource()
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, txt.TXT...)
+	}
+}
+
+// updateMessage replacing all records of each host with one of the address,
+// deleting all records of the removed hosts, and replacing the record of the
+// owner's hosts, which is deleted if there are none.  It is signed if a key
+// is set.
+func (r *RFC2136) updateMessage(owner string, hosts, removed []string, id uint16, now time.Time) ([]byte, error) {
+	zone, err := dnsmessage.NewName(fqdn(r.zone))
+	if err != nil {
+		return nil, err
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, OpCode: opcodeUpdate})
+	if err = b.StartQuestions(); err != nil { // the zone section
+		return nil, err
+	}
+	if err = b.Question(dnsmessage.Question{Name: zone, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	if err = b.StartAuthorities(); err != nil { // the update section
+		return nil, err
+	}
+	// Delete all RRsets of the name
+	deleteAll := func(host string) (dnsmessage.Name, error) {
+		name, err := dnsmessage.NewName(fqdn(host))
+		if err != nil {
+			return name, err
+		}
+		return name, b.UnknownResource(
+			dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassANY},
+			dnsmessage.UnknownResource{Type: dnsmessage.TypeALL})
+	}
+	for _, host := range removed {
+		if _, err = deleteAll(host); err != nil {
+			return nil, err
+		}
+	}
+	for _, host := range hosts {
+		name, err := deleteAll(host)
+		if err != nil {
+			return nil, err
+		}
+		// Add the record of the address
+		h := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: uint32(r.ttl.Seconds())}
+		if ip := net.ParseIP(r.address); ip == nil {
+			target, err := dnsmessage.NewName(fqdn(r.address))
+			if err != nil {
+				return nil, err
+			}
+			err = b.CNAMEResource(h, dnsmessage.CNAMEResource{CNAME: target})
+			if err != nil {
+				return nil, err
+			}
+		} else if ip4 := ip.To4(); ip4 != nil {
+			if err = b.AResource(h, dnsmessage.AResource{A: [4]byte(ip4)}); err != nil {
+				return nil, err
+			}
+		} else if err = b.AAAAResource(h, dnsmessage.AAAAResource{AAAA: [16]byte(ip)}); err != nil {
+			return nil, err
+		}
+	}
+	// Record the hosts of the owner
+	name, err := deleteAll(r.ownerRecord(owner))
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) > 0 {
+		h := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: uint32(r.ttl.Seconds())}
+		if err = b.TXTResource(h, dnsmessage.TXTResource{TXT: hosts}); err != nil {
+			return nil, err
+		}
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		return nil, err
+	}
+	if r.keyName == "" {
+		return msg, nil
+	}
+	return r.sign(msg, id, now), nil
+}
+
+// sign the message by appending a TSIG record (RFC 8945 section 4).
+func (r *RFC2136) sign(msg []byte, id uint16, now time.Time) []byte {
+	var (
+		keyName   = wireName(r.keyName)
+		algorithm = wireName(r.algorithm)
+		timeFudge = make([]byte, 8) // time signed (48 bits) and fudge
+	)
+	binary.BigEndian.PutUint64(timeFudge, uint64(now.Unix())<<16|tsigFudge)
+
+	// The MAC is of the message and the TSIG variables: key name, class ANY,
+	// TTL 0, algorithm, time signed, fudge, error 0 and no other data.
+	mac := hmac.New(tsigAlgorithms[r.algorithm], r.keySecret)
+	mac.Write(msg)
+	mac.Write(keyName)
+	mac.Write([]byte{0, byte(dnsmessage.ClassANY), 0, 0, 0, 0})
+	mac.Write(algorithm)
+	mac.Write(timeFudge)
+	mac.Write([]byte{0, 0, 0, 0})
+	sum := mac.Sum(nil)
+
+	rdata := append([]byte{}, algorithm...)
+	rdata = append(rdata, timeFudge...)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(sum)))
+	rdata = append(rdata, sum...)
+	rdata = binary.BigEndian.AppendUint16(rdata, id)
+	rdata = append(rdata, 0, 0, 0, 0) // error and other length
+
+	signed := append(msg, keyName...)
+	signed = binary.BigEndian.AppendUint16(signed, typeTSIG)
+	signed = binary.BigEndian.AppendUint16(signed, uint16(dnsmessage.ClassANY))
+	signed = binary.BigEndian.AppendUint32(signed, 0)
+	signed = binary.BigEndian.AppendUint16(signed, uint16(len(rdata)))
+	signed = append(signed, rdata...)
+
+	// Increment the additional record count of the header
+	binary.BigEndian.PutUint16(signed[10:], binary.BigEndian.Uint16(signed[10:])+1)
+	return signed
+}
+
+// send the message to the server over UDP, returning the response, or an
+// error unless it is successful.  A query of a name which does not exist is
+// successful, its response having no answers.
+func (r *RFC2136) send(ctx context.Context, msg []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", r.server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(10 * time.Second)
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write(msg); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	var p dnsmessage.Parser
+	h, err := p.Start(buf[:n])
+	if err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if h.ID != binary.BigEndian.Uint16(msg) {
+		return nil, fmt.Errorf("response ID %v does not match request", h.ID)
+	}
+	if h.RCode != dnsmessage.RCodeSuccess && !(h.OpCode == 0 && h.RCode == dnsmessage.RCodeNameError) {
+		return nil, fmt.Errorf("server responded %v", h.RCode)
+	}
+	return buf[:n], nil
+}
+
+// fqdn of the name, with a trailing dot.
+func fqdn(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
+
+// wireName is the uncompressed, lower case wire format of the name.
+func wireName(name string) (b []byte) {
+	for _, label := range strings.Split(strings.ToLower(strings.TrimSuffix(name, ".")), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
@@ -0,0 +1,196 @@
+//go:build !integration
+// +build !integration
+
+package dns
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// TestRFC2136_updateMessage ensures an update replaces all records of the
+// host with one of the address, records the hosts of the owner, and is
+// signed with a TSIG record when a key is set.
+func TestRFC2136_updateMessage(t *testing.T) {
+	r := NewRFC2136("127.0.0.1:53", "example.com", "192.0.2.1", time.Minute)
+	unsigned, err := r.updateMessage("myfunc.ns", []string{"www.example.com"}, nil, 42, time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p dnsmessage.Parser
+	h, err := p.Start(unsigned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.ID != 42 || h.OpCode != opcodeUpdate {
+		t.Fatalf("unexpected header %+v", h)
+	}
+	q, err := p.Question()
+	if err != nil || q.Name.String() != "example.com." || q.Type != dnsmessage.TypeSOA {
+		t.Fatalf("unexpected zone %v: %v", q, err)
+	}
+	if err = p.SkipAllQuestions(); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.SkipAllAnswers(); err != nil { // the prerequisite section
+		t.Fatal(err)
+	}
+	deletion, err := p.AuthorityHeader()
+	if err != nil || deletion.Type != dnsmessage.TypeALL || deletion.Class != dnsmessage.ClassANY {
+		t.Fatalf("expected the deletion of all RRsets, got %v: %v", deletion, err)
+	}
+	if err = p.SkipAuthority(); err != nil {
+		t.Fatal(err)
+	}
+	addition, err := p.AuthorityHeader()
+	if err != nil || addition.Type != dnsmessage.TypeA || addition.TTL != 60 {
+		t.Fatalf("expected an A record, got %v: %v", addition, err)
+	}
+	if a, err := p.AResource(); err != nil || a.A != [4]byte{192, 0, 2, 1} {
+		t.Fatalf("expected an A record of 192.0.2.1, got %v: %v", a, err)
+	}
+	if deletion, err = p.AuthorityHeader(); err != nil || deletion.Name.String() != "_func.myfunc.ns.example.com." || deletion.Type != dnsmessage.TypeALL {
+		t.Fatalf("expected the deletion of the owner record, got %v: %v", deletion, err)
+	}
+	if err = p.SkipAuthority(); err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := p.AuthorityHeader(); err != nil || owner.Type != dnsmessage.TypeTXT {
+		t.Fatalf("expected the TXT record of the owner, got %v: %v", owner, err)
+	}
+	if txt, err := p.TXTResource(); err != nil || len(txt.TXT) != 1 || txt.TXT[0] != "www.example.com" {
+		t.Fatalf("expected the owner's hosts to be recorded, got %v: %v", txt, err)
+	}
+
+	if err = r.SetKey("func-key", "c2VjcmV0", ""); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := r.updateMessage("myfunc.ns", []string{"www.example.com"}, nil, 42, time.Unix(1700000000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signed[12:len(unsigned)], unsigned[12:]) {
+		t.Fatal("expected the signed message to extend the unsigned message")
+	}
+	p = dnsmessage.Parser{}
+	if h, err = p.Start(signed); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.SkipAllQuestions(); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.SkipAllAnswers(); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.SkipAllAuthorities(); err != nil {
+		t.Fatal(err)
+	}
+	tsig, err := p.AdditionalHeader()
+	if err != nil || tsig.Type != typeTSIG || tsig.Name.String() != "func-key." {
+		t.Fatalf("expected a TSIG record, got %v: %v", tsig, err)
+	}
+}
+
+// TestRFC2136_Update ensures updates are sent to the server, that the hosts
+// last published for the owner which were since removed are deleted, and
+// that hosts outside the zone or refused updates are an error.
+func TestRFC2136_Update(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var (
+		rcode     atomic.Uint32          // of responses, initially success
+		published atomic.Pointer[string] // host of the owner's TXT record, if any
+		deleted   = make(chan string, 10)
+	)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var p dnsmessage.Parser
+			h, err := p.Start(buf[:n])
+			if err != nil {
+				return
+			}
+			q, err := p.Question()
+			if err != nil {
+				return
+			}
+			b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: h.ID, Response: true, OpCode: h.OpCode, RCode: dnsmessage.RCode(rcode.Load())})
+			if h.OpCode == opcodeUpdate {
+				// Record the deletions of hosts
+				_ = p.SkipAllQuestions()
+				_ = p.SkipAllAnswers()
+				for {
+					rh, err := p.AuthorityHeader()
+					if err != nil {
+						break
+					}
+					if rh.Class == dnsmessage.ClassANY && rh.Type == dnsmessage.TypeALL {
+						deleted <- rh.Name.String()
+					}
+					_ = p.SkipAuthority()
+				}
+			} else if host := published.Load(); host != nil {
+				_ = b.StartQuestions()
+				_ = b.Question(q)
+				_ = b.StartAnswers()
+				_ = b.TXTResource(dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET}, dnsmessage.TXTResource{TXT: []string{*host}})
+			} else {
+				b = dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: h.ID, Response: true, RCode: dnsmessage.RCodeNameError})
+			}
+			res, _ := b.Finish()
+			_, _ = conn.WriteTo(res, addr)
+		}
+	}()
+	deletions := func() (names []string) {
+		for {
+			select {
+			case name := <-deleted:
+				names = append(names, name)
+			default:
+				return
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	r := NewRFC2136(conn.LocalAddr().String(), "example.com", "ingress.example.com", time.Minute)
+	if err = r.Update(ctx, "myfunc.ns", []string{"www.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := deletions(); len(got) != 2 || got[0] != "www.example.com." || got[1] != "_func.myfunc.ns.example.com." {
+		t.Fatalf("expected the records of the host and owner to be replaced, got %v", got)
+	}
+
+	// The host last published for the owner, since removed, is deleted.
+	old := "old.example.com"
+	published.Store(&old)
+	if err = r.Update(ctx, "myfunc.ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := deletions(); len(got) != 2 || got[0] != "old.example.com." || got[1] != "_func.myfunc.ns.example.com." {
+		t.Fatalf("expected the removed host and owner record to be deleted, got %v", got)
+	}
+
+	if err = r.Update(ctx, "myfunc.ns", []string{"www.example.org"}); err == nil {
+		t.Fatal("expected an error updating a host outside the zone")
+	}
+	rcode.Store(uint32(dnsmessage.RCodeRefused))
+	if err = r.Update(ctx, "myfunc.ns", []string{"www.example.com"}); err == nil {
+		t.Fatal("expected an error when the update is refused")
+	}
+}
@@ -0,0 +1,481 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"knative.dev/func/pkg/deployers"
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+const (
+	// DefaultNetwork shared by deployed functions, on which each may reach
+	// the others at name.namespace:8080.
+	DefaultNetwork = "func"
+
+	// DefaultNamespace of functions deployed without one.  The container
+	// engine has no namespaces of its own: a function's namespace is a label
+	// and part of the name of its container.
+	DefaultNamespace = "default"
+
+	// functionPort is the port on which the function listens in its container.
+	functionPort = nat.Port("8080/tcp")
+)
+
+// DeployerDockerClient is sub-interface of client.CommonAPIClient required by
+// the Deployer.
+type DeployerDockerClient interface {
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error)
+	ContainerInspect(ctx context.Context, container string) (types.ContainerJSON, error)
+	ContainerList(ctx context.Context, options container.ListOptions) ([]types.Container, error)
+	ContainerRemove(ctx context.Context, container string, options container.RemoveOptions) error
+	ContainerRename(ctx context.Context, container, newContainerName string) error
+	ContainerStart(ctx context.Context, container string, options container.StartOptions) error
+	ContainerStop(ctx context.Context, container string, options container.StopOptions) error
+	ImageInspectWithRaw(ctx context.Context, image string) (types.ImageInspect, []byte, error)
+	ImagePull(ctx context.Context, ref string, options image.PullOptions) (io.ReadCloser, error)
+	NetworkCreate(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error)
+	NetworkInspect(ctx context.Context, network string, options network.InspectOptions) (network.Inspect, error)
+	Close() error
+}
+
+type DeployerDockerClientFactory func() (DeployerDockerClient, error)
+
+type DeployerOpt func(*Deployer)
+
+// Deployer of functions as long-running containers of the local container
+// engine, each with a persistent name and port.  It is also the Lister,
+// Describer and Remover of the functions it deploys.
+type Deployer struct {
+	verbose             bool
+	dockerClientFactory DeployerDockerClientFactory
+}
+
+var (
+	_ fn.Deployer  = (*Deployer)(nil)
+	_ fn.Lister    = (*Deployer)(nil)
+	_ fn.Describer = (*Deployer)(nil)
+	_ fn.Remover   = (*Deployer)(nil)
+)
+
+// NewDeployer creates an instance of a docker-backed deployer.
+func NewDeployer(opts ...DeployerOpt) *Deployer {
+	d := &Deployer{
+		dockerClientFactory: func() (DeployerDockerClient, error) {
+			c, _, err := NewClient(client.DefaultDockerHost)
+			return c, err
+		},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func WithDeployerVerbose(verbose bool) DeployerOpt {
+	return func(d *Deployer) {
+		d.verbose = verbose
+	}
+}
+
+func WithDeployerDockerClientFactory(dockerClientFactory DeployerDockerClientFactory) DeployerOpt {
+	return func(d *Deployer) {
+		d.dockerClientFactory = dockerClientFactory
+	}
+}
+
+// Deploy the function as a container on the shared network, replacing that
+// of a previous deployment while retaining its port.
+func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (result fn.DeploymentResult, err error) {
+	img := f.Deploy.Image
+	if img == "" {
+		img = f.Build.Image
+	}
+	if img == "" {
+		return result, errors.New("function has no associated image. Has it been built?")
+	}
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	name := ContainerName(f.Name, namespace)
+
+	c, err := d.dockerClientFactory()
+	if err != nil {
+		return result, fmt.Errorf("failed to create Docker API client: %w", err)
+	}
+	defer c.Close()
+
+	if err = ensureNetwork(ctx, c); err != nil {
+		return
+	}
+	if err = ensureImage(ctx, c, img, d.verbose); err != nil {
+		return
+	}
+
+	// Replace the container of a previous deployment, retaining its port.
+	// The new container is created under a temporary name, and the previous
+	// is only stopped (its port being needed) once the new one is created.
+	// The previous is removed when the new one has started, or is restarted
+	// should the new one fail to.
+	result.Status = fn.Deployed
+	port := ""
+	createName := name
+	existing, err := c.ContainerInspect(ctx, name)
+	if err == nil {
+		if existing.Config.Labels[fnlabels.FunctionPlatformKey] != deployers.Docker {
+			return result, fmt.Errorf("container %q exists and was not deployed as a function", name)
+		}
+		port = hostPort(existing)
+		createName = name + ".next"
+		result.Status = fn.Updated
+	} else if !client.IsErrNotFound(err) {
+		return
+	}
+	if port == "" {
+		port = choosePort(DefaultHost, DefaultPort, DefaultDialTimeout)
+	}
+
+	containerCfg, err := newDeployContainerConfig(f, img, namespace, d.verbose)
+	if err != nil {
+		return
+	}
+	hostCfg, err := newDeployHostConfig(f, port)
+	if err != nil {
+		return
+	}
+	networkCfg := network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			DefaultNetwork: {Aliases: []string{name}},
+		},
+	}
+	if result.Status == fn.Updated {
+		// Left over by an interrupted deployment
+		if err = c.ContainerRemove(ctx, createName, container.RemoveOptions{Force: true}); err != nil && !client.IsErrNotFound(err) {
+			return result, fmt.Errorf("error removing container %v: %w", createName, err)
+		}
+	}
+	if d.verbose {
+		fmt.Fprintf(os.Stderr, "Creating container %v of image %v\n", name, img)
+	}
+	if _, err = c.ContainerCreate(ctx, &containerCfg, &hostCfg, &networkCfg, nil, createName); err != nil {
+		return result, fmt.Errorf("docker deployer unable to create container: %w", err)
+	}
+	if result.Status == fn.Updated {
+		timeoutSecs := int(DefaultStopTimeout.Seconds())
+		if err = c.ContainerStop(ctx, name, container.StopOptions{Timeout: &timeoutSecs}); err != nil {
+			_ = c.ContainerRemove(ctx, createName, container.RemoveOptions{Force: true})
+			return result, fmt.Errorf("error stopping container %v: %w", name, err)
+		}
+	}
+
+	timeout := f.Run.StartTimeout
+	if timeout == 0 {
+		timeout = fn.DefaultStartTimeout
+	}
+	if err = c.ContainerStart(ctx, createName, container.StartOptions{}); err != nil {
+		err = fmt.Errorf("docker deployer unable to start container: %w", err)
+	} else {
+		err = waitForContainer(ctx, c, createName, port, timeout)
+	}
+	if err != nil {
+		if result.Status == fn.Updated {
+			err = restoreContainer(ctx, c, name, createName, err)
+		}
+		return
+	}
+	if result.Status == fn.Updated {
+		if err = c.ContainerRemove(ctx, name, container.RemoveOptions{}); err != nil {
+			return result, fmt.Errorf("error removing container %v: %w", name, err)
+		}
+		if err = c.ContainerRename(ctx, createName, name); err != nil {
+			return result, fmt.Errorf("error renaming container %v to %v: %w", createName, name, err)
+		}
+	}
+
+	result.URL = functionURL(port)
+	result.Namespace = namespace
+	return
+}
+
+// List the functions deployed to the container engine, optionally only
+// those of a namespace.
+func (d *Deployer) List(ctx context.Context, namespace string) (items []fn.ListItem, err error) {
+	c, err := d.dockerClientFactory()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	args := filters.NewArgs(
+		filters.Arg("label", fnlabels.FunctionKey+"="+fnlabels.FunctionValue),
+		filters.Arg("label", fnlabels.FunctionPlatformKey+"="+deployers.Docker))
+	if namespace != "" {
+		args.Add("label", fnlabels.FunctionNamespaceKey+"="+namespace)
+	}
+	containers, err := c.ContainerList(ctx, container.ListOptions{All: true, Filters: args})
+	if err != nil {
+		return
+	}
+
+	for _, ctr := range containers {
+		url := ""
+		for _, p := range ctr.Ports {
+			if fmt.Sprintf("%v/%v", p.PrivatePort, p.Type) == string(functionPort) && p.PublicPort != 0 {
+				url = functionURL(fmt.Sprint(p.PublicPort))
+			}
+		}
+		ready := "False"
+		if ctr.State == "running" {
+			ready = "True"
+		}
+		items = append(items, fn.ListItem{
+			Name:      ctr.Labels[fnlabels.FunctionNameKey],
+			Namespace: ctr.Labels[fnlabels.FunctionNamespaceKey],
+			Runtime:   ctr.Labels[fnlabels.FunctionRuntimeKey],
+			URL:       url,
+			Ready:     ready,
+		})
+	}
+	return
+}
+
+// Describe a function by name.  Returns fn.ErrFunctionNotFound if there is no
+// such function deployed to the container engine.
+func (d *Deployer) Describe(ctx context.Context, name, namespace string) (description fn.Instance, err error) {
+	if namespace == "" {
+		err = fmt.Errorf("function namespace is required when describing %q", name)
+		return
+	}
+	c, err := d.dockerClientFactory()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	ctr, err := inspectFunction(ctx, c, ContainerName(name, namespace))
+	if err != nil {
+		return
+	}
+
+	route := functionURL(hostPort(ctr))
+	description.Name = name
+	description.Namespace = namespace
+	description.Route = route
+	description.Routes = []string{route}
+	description.Image = ctr.Config.Image
+	description.Subscriptions = []fn.Subscription{}
+	return
+}
+
+// Remove the function's container.  Returns fn.ErrFunctionNotFound if there
+// is no such function deployed to the container engine.
+func (d *Deployer) Remove(ctx context.Context, name, namespace string) (err error) {
+	if namespace == "" {
+		return fn.ErrNamespaceRequired
+	}
+	c, err := d.dockerClientFactory()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	containerName := ContainerName(name, namespace)
+	if _, err = inspectFunction(ctx, c, containerName); err != nil {
+		return
+	}
+	return removeContainer(ctx, c, containerName)
+}
+
+// ContainerName of a deployed function, which is also its hostname on the
+// shared network.
+func ContainerName(name, namespace string) string {
+	return name + "." + namespace
+}
+
+// functionURL at which a deployed function is exposed on the given host port.
+func functionURL(port string) string {
+	if port == "" {
+		return ""
+	}
+	return "http://" + net.JoinHostPort(DefaultHost, port)
+}
+
+// hostPort to which the function's port is bound, or empty if not bound.
+func hostPort(ctr types.ContainerJSON) string {
+	if ctr.HostConfig == nil {
+		return ""
+	}
+	for _, b := range ctr.HostConfig.PortBindings[functionPort] {
+		if b.HostPort != "" {
+			return b.HostPort
+		}
+	}
+	return ""
+}
+
+// inspectFunction returns the named container, or fn.ErrFunctionNotFound if
+// it does not exist or is not a deployed function.
+func inspectFunction(ctx context.Context, c DeployerDockerClient, name string) (ctr types.ContainerJSON, err error) {
+	ctr, err = c.ContainerInspect(ctx, name)
+	if client.IsErrNotFound(err) {
+		return ctr, fn.ErrFunctionNotFound
+	} else if err != nil {
+		return
+	}
+	if ctr.Config == nil || ctr.Config.Labels[fnlabels.FunctionPlatformKey] != deployers.Docker {
+		return ctr, fn.ErrFunctionNotFound
+	}
+	return
+}
+
+// ensureNetwork creates the shared network if it does not exist.
+func ensureNetwork(ctx context.Context, c DeployerDockerClient) error {
+	_, err := c.NetworkInspect(ctx, DefaultNetwork, network.InspectOptions{})
+	if err == nil || !client.IsErrNotFound(err) {
+		return err
+	}
+	_, err = c.NetworkCreate(ctx, DefaultNetwork, network.CreateOptions{
+		Labels: map[string]string{fnlabels.FunctionKey: fnlabels.FunctionValue},
+	})
+	if err != nil {
+		return fmt.Errorf("docker deployer unable to create network %q: %w", DefaultNetwork, err)
+	}
+	return nil
+}
+
+// ensureImage pulls the image if it is not present locally.
+func ensureImage(ctx context.Context, c DeployerDockerClient, img string, verbose bool) error {
+	_, _, err := c.ImageInspectWithRaw(ctx, img)
+	if err == nil || !client.IsErrNotFound(err) {
+		return err
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Pulling image %v\n", img)
+	}
+	r, err := c.ImagePull(ctx, img, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("docker deployer unable to pull image %q: %w", img, err)
+	}
+	defer r.Close()
+	_, err = io.Copy(io.Discard, r)
+	return err
+}
+
+// removeContainer stops and removes the named container.
+func removeContainer(ctx context.Context, c DeployerDockerClient, name string) error {
+	timeoutSecs := int(DefaultStopTimeout.Seconds())
+	if err := c.ContainerStop(ctx, name, container.StopOptions{Timeout: &timeoutSecs}); err != nil {
+		return fmt.Errorf("error stopping container %v: %w", name, err)
+	}
+	if err := c.ContainerRemove(ctx, name, container.RemoveOptions{}); err != nil {
+		return fmt.Errorf("error removing container %v: %w", name, err)
+	}
+	return nil
+}
+
+// restoreContainer removes the container which failed to start in place of
+// the named one, and restarts the named one, returning the cause of the
+// failure.
+func restoreContainer(ctx context.Context, c DeployerDockerClient, name, failed string, cause error) error {
+	if err := c.ContainerRemove(ctx, failed, container.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("%w (error removing container %v: %v)", cause, failed, err)
+	}
+	if err := c.ContainerStart(ctx, name, container.StartOptions{}); err != nil {
+		return fmt.Errorf("%w (error restarting previous container %v: %v)", cause, name, err)
+	}
+	return cause
+}
+
+// waitForContainer polls until the function accepts connections on its host
+// port, failing if the container exits or the timeout elapses.
+func waitForContainer(ctx context.Context, c DeployerDockerClient, name, port string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		ctr, err := c.ContainerInspect(ctx, name)
+		if err != nil {
+			return err
+		}
+		if ctr.State != nil && !ctr.State.Running && !ctr.State.Restarting {
+			return fmt.Errorf("container %v exited with code %v", name, ctr.State.ExitCode)
+		}
+		if dial(DefaultHost, port, DefaultDialTimeout) == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for container %v to accept connections on port %v", name, port)
+		case <-ticker.C:
+		}
+	}
+}
+
+// newDeployContainerConfig is that of the runner, labelled as a deployed
+// function and detached from the caller's stdio.
+func newDeployContainerConfig(f fn.Function, img, namespace string, verbose bool) (c container.Config, err error) {
+	if c, err = newContainerConfig(f, "", verbose); err != nil {
+		return
+	}
+	labels, err := f.LabelsMap()
+	if err != nil {
+		return
+	}
+	labels[fnlabels.FunctionKey] = fnlabels.FunctionValue
+	labels[fnlabels.FunctionNameKey] = f.Name
+	labels[fnlabels.FunctionNamespaceKey] = namespace
+	labels[fnlabels.FunctionRuntimeKey] = f.Runtime
+	labels[fnlabels.FunctionPlatformKey] = deployers.Docker
+	c.Image = img
+	c.Labels = labels
+	c.AttachStdout = false
+	c.AttachStderr = false
+	return
+}
+
+// newDeployHostConfig binds the function to the given host port, restarting
+// it on failure, and limits its CPU and memory to those of the function.
+func newDeployHostConfig(f fn.Function, port string) (c container.HostConfig, err error) {
+	if c, err = newHostConfig(port); err != nil {
+		return
+	}
+	c.RestartPolicy = container.RestartPolicy{Name: container.RestartPolicyOnFailure}
+	if r := f.Deploy.Options.Resources; r != nil && r.Limits != nil {
+		if r.Limits.CPU != nil {
+			q, err := resource.ParseQuantity(*r.Limits.CPU)
+			if err != nil {
+				return c, fmt.Errorf("invalid cpu limit %q: %w", *r.Limits.CPU, err)
+			}
+			c.Resources.NanoCPUs = q.MilliValue() * 1_000_000
+		}
+		if r.Limits.Memory != nil {
+			q, err := resource.ParseQuantity(*r.Limits.Memory)
+			if err != nil {
+				return c, fmt.Errorf("invalid memory limit %q: %w", *r.Limits.Memory, err)
+			}
+			c.Resources.Memory = q.Value()
+		}
+	}
+	return
+}
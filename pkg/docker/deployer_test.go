@@ -0,0 +1,246 @@
+//go:build !integration
+// +build !integration
+
+package docker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// TestDeployer ensures a function is deployed as a labelled container which
+// restarts on failure, retains its port when redeployed, and is listed,
+// described and removed.  A redeployment which fails to start leaves the
+// previous deployment running.
+func TestDeployer(t *testing.T) {
+	var (
+		ctx = context.Background()
+		c   = newMockDeployerDockerClient()
+		d   = docker.NewDeployer(docker.WithDeployerDockerClientFactory(
+			func() (docker.DeployerDockerClient, error) { return c, nil }))
+		f = fn.Function{Name: "myfunc", Runtime: "go", Namespace: "myns",
+			Deploy: fn.DeploySpec{Image: "example.com/alice/myfunc"}}
+	)
+	defer c.closeListeners()
+
+	result, err := d.Deploy(ctx, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != fn.Deployed || result.Namespace != "myns" || !strings.HasPrefix(result.URL, "http://127.0.0.1:") {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if !c.networkCreated || !c.imagePulled {
+		t.Fatal("expected the shared network to be created and the image pulled")
+	}
+	ctr, ok := c.containers["myfunc.myns"]
+	if !ok {
+		t.Fatalf("expected container myfunc.myns, got %v", c.containers)
+	}
+	if ctr.Config.Labels[fnlabels.FunctionPlatformKey] != "docker" || ctr.Config.Labels[fnlabels.FunctionNamespaceKey] != "myns" {
+		t.Fatalf("unexpected labels %v", ctr.Config.Labels)
+	}
+	if ctr.HostConfig.RestartPolicy.Name != container.RestartPolicyOnFailure {
+		t.Fatalf("expected restart on failure, got %v", ctr.HostConfig.RestartPolicy)
+	}
+
+	updated, err := d.Deploy(ctx, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != fn.Updated || updated.URL != result.URL {
+		t.Fatalf("expected an update at %v, got %+v", result.URL, updated)
+	}
+
+	if len(c.containers) != 1 {
+		t.Fatalf("expected only the updated container, got %v", c.containers)
+	}
+
+	// A deployment which fails to start leaves the previous one running
+	c.failStart = "myfunc.myns.next"
+	if _, err = d.Deploy(ctx, f); err == nil {
+		t.Fatal("expected an error deploying a container which fails to start")
+	}
+	if ctr, ok := c.containers["myfunc.myns"]; !ok || !ctr.State.Running || len(c.containers) != 1 {
+		t.Fatalf("expected the previous container to be running, got %v", c.containers)
+	}
+	c.failStart = ""
+
+	instance, err := d.Describe(ctx, "myfunc", "myns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instance.Route != result.URL || instance.Image != f.Deploy.Image {
+		t.Fatalf("unexpected instance %+v", instance)
+	}
+
+	items, err := d.List(ctx, "myns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "myfunc" || items[0].Ready != "True" {
+		t.Fatalf("unexpected list %+v", items)
+	}
+
+	if err = d.Remove(ctx, "myfunc", "myns"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d.Describe(ctx, "myfunc", "myns"); !errors.Is(err, fn.ErrFunctionNotFound) {
+		t.Fatalf("expected ErrFunctionNotFound, got %v", err)
+	}
+}
+
+// mockDeployerDockerClient keeps containers in memory, listening on the host
+// port of those which are started.
+type mockDeployerDockerClient struct {
+	containers     map[string]types.ContainerJSON
+	listeners      map[string]net.Listener
+	networkCreated bool
+	imagePulled    bool
+	failStart      string // name of a container which fails to start
+}
+
+func newMockDeployerDockerClient() *mockDeployerDockerClient {
+	return &mockDeployerDockerClient{
+		containers: map[string]types.ContainerJSON{},
+		listeners:  map[string]net.Listener{},
+	}
+}
+
+func (m *mockDeployerDockerClient) closeListeners() {
+	for _, l := range m.listeners {
+		l.Close()
+	}
+}
+
+func (m *mockDeployerDockerClient) ContainerCreate(_ context.Context, config *container.Config, hostConfig *container.HostConfig, _ *network.NetworkingConfig, _ *ocispec.Platform, name string) (container.CreateResponse, error) {
+	m.containers[name] = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{Name: name, HostConfig: hostConfig, State: &types.ContainerState{}},
+		Config:            config,
+	}
+	return container.CreateResponse{ID: name}, nil
+}
+
+func (m *mockDeployerDockerClient) ContainerInspect(_ context.Context, name string) (types.ContainerJSON, error) {
+	ctr, ok := m.containers[name]
+	if !ok {
+		return ctr, errdefs.NotFound(errors.New("no such container"))
+	}
+	return ctr, nil
+}
+
+func (m *mockDeployerDockerClient) ContainerList(_ context.Context, options container.ListOptions) (cc []types.Container, err error) {
+	for name, ctr := range m.containers {
+		matches := true
+		for _, label := range options.Filters.Get("label") {
+			k, v, _ := strings.Cut(label, "=")
+			matches = matches && ctr.Config.Labels[k] == v
+		}
+		if !matches {
+			continue
+		}
+		state := "exited"
+		if ctr.State.Running {
+			state = "running"
+		}
+		cc = append(cc, types.Container{Names: []string{"/" + name}, Labels: ctr.Config.Labels, State: state})
+	}
+	return
+}
+
+func (m *mockDeployerDockerClient) ContainerRemove(_ context.Context, name string, _ container.RemoveOptions) error {
+	if _, ok := m.containers[name]; !ok {
+		return errdefs.NotFound(errors.New("no such container"))
+	}
+	if l, ok := m.listeners[name]; ok {
+		l.Close()
+		delete(m.listeners, name)
+	}
+	delete(m.containers, name)
+	return nil
+}
+
+func (m *mockDeployerDockerClient) ContainerRename(_ context.Context, name, newName string) error {
+	ctr, ok := m.containers[name]
+	if !ok {
+		return errdefs.NotFound(errors.New("no such container"))
+	}
+	if _, ok = m.containers[newName]; ok {
+		return errdefs.Conflict(errors.New("name in use"))
+	}
+	ctr.Name = newName
+	m.containers[newName] = ctr
+	delete(m.containers, name)
+	if l, ok := m.listeners[name]; ok {
+		m.listeners[newName] = l
+		delete(m.listeners, name)
+	}
+	return nil
+}
+
+func (m *mockDeployerDockerClient) ContainerStart(_ context.Context, name string, _ container.StartOptions) error {
+	if name == m.failStart {
+		return errors.New("failed to start")
+	}
+	ctr := m.containers[name]
+	port := ctr.HostConfig.PortBindings["8080/tcp"][0].HostPort
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", port))
+	if err != nil {
+		return err
+	}
+	m.listeners[name] = l
+	ctr.State.Running = true
+	return nil
+}
+
+func (m *mockDeployerDockerClient) ContainerStop(_ context.Context, name string, _ container.StopOptions) error {
+	if l, ok := m.listeners[name]; ok {
+		l.Close()
+		delete(m.listeners, name)
+	}
+	m.containers[name].State.Running = false
+	return nil
+}
+
+func (m *mockDeployerDockerClient) ImageInspectWithRaw(context.Context, string) (types.ImageInspect, []byte, error) {
+	if !m.imagePulled {
+		return types.ImageInspect{}, nil, errdefs.NotFound(errors.New("no such image"))
+	}
+	return types.ImageInspect{}, nil, nil
+}
+
+func (m *mockDeployerDockerClient) ImagePull(context.Context, string, image.PullOptions) (io.ReadCloser, error) {
+	m.imagePulled = true
+	return io.NopCloser(strings.NewReader("{}")), nil
+}
+
+func (m *mockDeployerDockerClient) NetworkCreate(context.Context, string, network.CreateOptions) (network.CreateResponse, error) {
+	m.networkCreated = true
+	return network.CreateResponse{}, nil
+}
+
+func (m *mockDeployerDockerClient) NetworkInspect(context.Context, string, network.InspectOptions) (network.Inspect, error) {
+	if !m.networkCreated {
+		return network.Inspect{}, errdefs.NotFound(errors.New("no such network"))
+	}
+	return network.Inspect{}, nil
+}
+
+func (m *mockDeployerDockerClient) Close() error {
+	return nil
+}
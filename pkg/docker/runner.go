@@ -209,9 +209,14 @@ func newContainerConfig(f fn.Function, _ string, verbose bool) (c container.Conf
 	}
 
 	// Environment Variables
-	// Interpolate references to local environment variables and convert to a
-	// simple string slice for use with container.Config
-	envs, err := fn.Interpolate(f.Run.Envs)
+	// Include those of the function's env files, interpolate references to
+	// local environment variables and files, and convert to a simple string
+	// slice for use with container.Config
+	runEnvs, err := f.RunEnvs()
+	if err != nil {
+		return
+	}
+	envs, err := fn.InterpolateIn(f.Root, runEnvs)
 	if err != nil {
 		return
 	}
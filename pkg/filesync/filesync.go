@@ -0,0 +1,185 @@
+// Package filesync synchronizes a tree of files to a remote directory
+// incrementally, sending only the files which changed since the last sync.
+//
+// The tree is described by a Manifest of the digests of its files.  The
+// manifest of the last sync is stored in the remote directory, and compared
+// with that of the local tree to determine the files to send and those to
+// delete.  Changes are sent as a stream of a JSON encoded Header, followed by
+// a tar stream of the changed files, which is applied with Apply.
+package filesync
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"knative.dev/func/pkg/tar"
+)
+
+// ManifestFile is the name of the file in the remote directory in which the
+// manifest of the last sync is stored.
+const ManifestFile = ".func-sync-manifest.json"
+
+// Manifest of a tree of files, mapping the slash separated path of each file,
+// directory and link relative to the root of the tree to its digest.  The
+// digest of a file covers its mode and content, of a link its target.
+type Manifest map[string]string
+
+// NewManifest of the tree at root, less the paths (relative to root) which
+// are ignored.  Directories are ignored if matched with or without a trailing
+// slash, as are the directory patterns of a .gitignore, and their contents
+// are not walked.
+func NewManifest(root string, ignored func(string) bool) (Manifest, error) {
+	m := Manifest{}
+	err := filepath.Walk(root, func(p string, fi fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if fi.IsDir() && (ignored(rel) || ignored(rel+"/")) {
+			return filepath.SkipDir
+		}
+		if ignored(rel) {
+			return nil
+		}
+		digest, err := digestOf(p, fi)
+		if err != nil {
+			return err
+		}
+		m[filepath.ToSlash(rel)] = digest
+		return nil
+	})
+	return m, err
+}
+
+func digestOf(p string, fi fs.FileInfo) (string, error) {
+	switch {
+	case fi.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(p)
+		if err != nil {
+			return "", err
+		}
+		return fi.Mode().String() + ":" + target, nil
+	case fi.Mode().IsRegular():
+		file, err := os.Open(p)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		h := sha256.New()
+		if _, err = io.Copy(h, file); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%v:sha256:%x", fi.Mode(), h.Sum(nil)), nil
+	}
+	return fi.Mode().String(), nil
+}
+
+// Diff the manifest with that of the previous sync, returning the paths which
+// are new or changed, and those which must be deleted, in lexical order.
+// Paths whose type changed, such as from a directory to a file, are both
+// deleted and changed.
+func (m Manifest) Diff(previous Manifest) (changed, deleted []string) {
+	for p, digest := range m {
+		prior, ok := previous[p]
+		if ok && prior == digest {
+			continue
+		}
+		changed = append(changed, p)
+		if ok && prior[0] != digest[0] { // the type of the mode
+			deleted = append(deleted, p)
+		}
+	}
+	for p := range previous {
+		if _, ok := m[p]; !ok {
+			deleted = append(deleted, p)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	return
+}
+
+// ReadManifest of the last sync to the directory, which is empty if the
+// directory was not synced.
+func ReadManifest(dir string) (Manifest, error) {
+	m := Manifest{}
+	bb, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return m, err
+	}
+	return m, json.Unmarshal(bb, &m)
+}
+
+// Header of a stream of changes.
+type Header struct {
+	// Manifest of the tree once the changes are applied.
+	Manifest Manifest `json:"manifest"`
+	// Deleted paths, relative to the directory to which the changes are
+	// applied.  They are deleted before the changed files are extracted.
+	Deleted []string `json:"deleted,omitempty"`
+}
+
+// NewStream of the changes described by the header, whose changed files are
+// the given tar stream.
+func NewStream(h Header, content io.Reader) (io.Reader, error) {
+	bb, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return io.MultiReader(strings.NewReader(string(bb)+"\n"), content), nil
+}
+
+// Apply the stream of changes to the directory: deleting the paths deleted,
+// extracting those changed and storing the new manifest.  The manifest is
+// stored last, such that a sync which was interrupted is sent again in full.
+func Apply(r io.Reader, dir string) error {
+	br := bufio.NewReader(r)
+	var h Header
+	line, err := br.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("cannot read the header of the changes: %w", err)
+	}
+	if err = json.Unmarshal(line, &h); err != nil {
+		return fmt.Errorf("cannot decode the header of the changes: %w", err)
+	}
+
+	// Remove the manifest first, such that it is absent if the sync fails.
+	if err = os.Remove(filepath.Join(dir, ManifestFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for _, p := range h.Deleted {
+		if clean := path.Clean(p); p == "" || path.IsAbs(p) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("invalid path to delete: %q", p)
+		}
+		if err = os.RemoveAll(filepath.Join(dir, filepath.FromSlash(p))); err != nil {
+			return fmt.Errorf("cannot delete %q: %w", p, err)
+		}
+	}
+	if err = tar.ExtractOver(br, dir); err != nil {
+		return err
+	}
+
+	bb, err := json.Marshal(h.Manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFile), bb, 0644)
+}
@@ -0,0 +1,220 @@
+//go:build !integration
+// +build !integration
+
+package filesync_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"knative.dev/func/pkg/filesync"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func noneIgnored(string) bool { return false }
+
+// TestManifest_Diff ensures only new and changed paths are sent, and removed
+// paths deleted.
+func TestManifest_Diff(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":          "package main",
+		"vendor/a/a.go":    "package a",
+		"vendor/b/b.go":    "package b",
+		"ignored/file.txt": "ignored",
+	})
+	ignored := func(p string) bool { return strings.HasPrefix(p, "ignored") }
+
+	previous, err := filesync.NewManifest(root, ignored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := previous["ignored/file.txt"]; ok {
+		t.Fatal("ignored file in manifest")
+	}
+
+	writeFiles(t, root, map[string]string{
+		"main.go": "package main // changed",
+		"new.go":  "package main",
+	})
+	if err = os.RemoveAll(filepath.Join(root, "vendor", "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := filesync.NewManifest(root, ignored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, deleted := current.Diff(previous)
+	if !reflect.DeepEqual(changed, []string{"main.go", "new.go"}) {
+		t.Errorf("unexpected changed paths: %v", changed)
+	}
+	if !reflect.DeepEqual(deleted, []string{"vendor/b", "vendor/b/b.go"}) {
+		t.Errorf("unexpected deleted paths: %v", deleted)
+	}
+}
+
+// TestNewManifest_IgnoredDir ensures the contents of ignored directories are
+// not walked.
+func TestNewManifest_IgnoredDir(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":             "package main",
+		"node_modules/a/a.js": "a",
+	})
+	var walked []string
+	ignored := func(p string) bool {
+		walked = append(walked, filepath.ToSlash(p))
+		return p == "node_modules"
+	}
+	m, err := filesync.NewManifest(root, ignored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(walked, []string{"main.go", "node_modules"}) {
+		t.Errorf("unexpected paths walked: %v", walked)
+	}
+	if len(m) != 1 {
+		t.Errorf("unexpected manifest: %v", m)
+	}
+}
+
+// TestManifest_DiffTypeChange ensures a path which changed from a directory
+// to a file is deleted before it is sent.
+func TestManifest_DiffTypeChange(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"data/file.txt": "file"})
+	previous, err := filesync.NewManifest(root, noneIgnored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.RemoveAll(filepath.Join(root, "data")); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{"data": "now a file"})
+	current, err := filesync.NewManifest(root, noneIgnored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, deleted := current.Diff(previous)
+	if !reflect.DeepEqual(changed, []string{"data"}) {
+		t.Errorf("unexpected changed paths: %v", changed)
+	}
+	if !reflect.DeepEqual(deleted, []string{"data", "data/file.txt"}) {
+		t.Errorf("unexpected deleted paths: %v", deleted)
+	}
+}
+
+// TestApply ensures changes are applied over the prior contents of the
+// directory, and the manifest stored.
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"source/kept.txt":    "kept",
+		"source/changed.txt": "before",
+		"source/deleted.txt": "deleted",
+	})
+
+	var content bytes.Buffer
+	tw := tar.NewWriter(&content)
+	if err := tw.WriteHeader(&tar.Header{Name: "source/changed.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("after")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := filesync.Manifest{"kept.txt": "-rw-r--r--:sha256:1", "changed.txt": "-rw-r--r--:sha256:2"}
+	stream, err := filesync.NewStream(filesync.Header{Manifest: manifest, Deleted: []string{"source/deleted.txt"}}, &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = filesync.Apply(stream, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{"source/kept.txt": "kept", "source/changed.txt": "after"} {
+		bb, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bb) != expected {
+			t.Errorf("unexpected content of %v: %q", name, bb)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "source", "deleted.txt")); !os.IsNotExist(err) {
+		t.Error("deleted file was not deleted")
+	}
+
+	stored, err := filesync.ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stored, manifest) {
+		t.Errorf("unexpected manifest stored: %v", stored)
+	}
+}
+
+// TestApply_InvalidDeletion ensures paths outside of the directory are not
+// deleted.
+func TestApply_InvalidDeletion(t *testing.T) {
+	for _, p := range []string{"../outside", "..", "source/../../outside", "/outside", ""} {
+		stream, err := filesync.NewStream(filesync.Header{Deleted: []string{p}}, &bytes.Buffer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = filesync.Apply(stream, t.TempDir()); err == nil {
+			t.Fatalf("expected an error deleting %q", p)
+		}
+	}
+}
+
+// TestApply_DotDotName ensures paths within the directory whose names start
+// with dot-dot are deleted.
+func TestApply_DotDotName(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"..env":      "env",
+		"..data/x":   "x",
+		"source/..y": "y",
+	})
+
+	var empty bytes.Buffer
+	if err := tar.NewWriter(&empty).Close(); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := filesync.NewStream(filesync.Header{Deleted: []string{"..env", "..data/x", "source/..y"}}, &empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = filesync.Apply(stream, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"..env", "..data/x", "source/..y"} {
+		if _, err = os.Stat(filepath.Join(dir, filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("%v was not deleted", name)
+		}
+	}
+}
@@ -72,6 +72,7 @@ type Client struct {
 	lister            Lister            // Lists remote services
 	describer         Describer         // Describes function instances
 	dnsProvider       DNSProvider       // Provider of DNS services
+	preflighter       Preflighter       // Checks a function can be deployed
 	registry          string            // default registry for OCI image tags
 	repositories      *Repositories     // Repositories management
 	templates         *Templates        // Templates management
@@ -192,16 +193,31 @@ type Subscription struct {
 
 // DNSProvider exposes DNS services necessary for serving the function.
 type DNSProvider interface {
-	// Provide the given name by routing requests to address.
+	// Provide the given name by routing requests to address.  A function
+	// without domains has its previously provided records removed.
 	Provide(Function) error
 }
 
+// Preflighter checks, without making any changes, that a function can be
+// deployed.
+type Preflighter interface {
+	// Preflight the function, returning a report of the checks made.  An
+	// error is returned only if the checks could not be run at all.
+	Preflight(context.Context, Function) (PreflightReport, error)
+}
+
 // PipelinesProvider manages lifecyle of CI/CD pipelines used by a function
 type PipelinesProvider interface {
 	Run(context.Context, Function) (string, Function, error)
 	Remove(context.Context, Function) error
 	ConfigurePAC(context.Context, Function, any) error
 	RemovePAC(context.Context, Function, any) error
+	Logs(context.Context, Function, string, io.Writer) error
+	Runs(context.Context, Function) ([]PipelineRun, error)
+	DescribeRun(context.Context, Function, string) (PipelineRun, error)
+	CancelRun(context.Context, Function, string) error
+	Rerun(context.Context, Function, string) (string, error)
+	ClearCache(context.Context, Function) error
 }
 
 // New client for function management.
@@ -215,6 +231,7 @@ func New(options ...Option) *Client {
 		lister:            &noopLister{output: os.Stdout},
 		describer:         &noopDescriber{output: os.Stdout},
 		dnsProvider:       &noopDNSProvider{output: os.Stdout},
+		preflighter:       &noopPreflighter{},
 		pipelinesProvider: &noopPipelinesProvider{},
 		transport:         http.DefaultTransport,
 		startTimeout:      DefaultStartTimeout,
@@ -319,6 +336,14 @@ func WithDNSProvider(provider DNSProvider) Option {
 	}
 }
 
+// WithPreflighter provides the checks run by Preflight, and by Deploy when
+// requested with WithDeployPreflight.
+func WithPreflighter(p Preflighter) Option {
+	return func(c *Client) {
+		c.preflighter = p
+	}
+}
+
 // WithRepositoriesPath sets the location on disk to use for extensible template
 // repositories.  Extensible template repositories are additional templates
 // that exist on disk and are not built into the binary.
@@ -613,6 +638,85 @@ func (c *Client) Init(cfg Function) (Function, error) {
 	return NewFunction(oldRoot)
 }
 
+// Adopt existing source code at cfg.Root as a function.  Unlike Init, the
+// directory is not required to be empty, and no template files are written.
+// Only the function's metadata (func.yaml) and a .funcignore are created.
+//
+// <runtime> is detected from the project manifest (go.mod, package.json etc)
+// if not provided, and <invoke> is detected from the source where the runtime
+// supports it.  The defaults of the template of the invocation (http or
+// cloudevents) are applied.  The source must implement a supported function
+// signature for runtimes whose signature detectors are available.
+func (c *Client) Adopt(cfg Function) (f Function, err error) {
+	oldRoot := cfg.Root
+	if cfg.Root, err = filepath.Abs(cfg.Root); err != nil {
+		return cfg, err
+	}
+	if _, err = os.Stat(cfg.Root); err != nil {
+		return cfg, fmt.Errorf("unable to adopt source at '%v'. %w", cfg.Root, err)
+	}
+	hasFunc, err := hasInitializedFunction(cfg.Root)
+	if err != nil {
+		return cfg, err
+	}
+	if hasFunc {
+		return cfg, fmt.Errorf("function at '%v' already initialized", cfg.Root)
+	}
+	if cfg.Name == "" {
+		cfg.Name = nameFromPath(cfg.Root)
+	}
+
+	// Runtime
+	if cfg.Runtime == "" {
+		if cfg.Runtime, err = scaffolding.DetectRuntime(cfg.Root); err != nil {
+			return cfg, err
+		}
+	}
+
+	// Invocation hint
+	if cfg.Invoke == "" {
+		invoke, err := scaffolding.DetectInvoke(cfg.Root, cfg.Runtime)
+		if err != nil && !errors.As(err, &scaffolding.ErrDetectorNotImplemented{}) {
+			return cfg, err
+		}
+		if invoke != "http" {
+			cfg.Invoke = invoke // http is the default and thus left implicit
+		}
+	}
+
+	// Template of the invocation, whose defaults are applied below
+	if cfg.Template == "" && cfg.Invoke == "cloudevent" {
+		cfg.Template = "cloudevents"
+	}
+
+	// Signature
+	_, err = scaffolding.DetectSignature(cfg.Root, cfg.Runtime, cfg.Invoke)
+	if err != nil && !errors.As(err, &scaffolding.ErrDetectorNotImplemented{}) {
+		return cfg, fmt.Errorf("unable to adopt the %v source at '%v'. %w", cfg.Runtime, cfg.Root, err)
+	}
+
+	f = NewFunctionWith(cfg)
+	f.SpecVersion = LastSpecVersion()
+
+	if err = ensureRunDataDir(f.Root); err != nil {
+		return
+	}
+	if err = ensureFuncIgnore(f.Root); err != nil {
+		return
+	}
+
+	// Apply the defaults of the runtime's template without writing its files.
+	if err = c.Templates().Apply(&f); err != nil {
+		return
+	}
+
+	f.Created = time.Now()
+	if err = f.Write(); err != nil {
+		return
+	}
+	return NewFunction(oldRoot)
+}
+
 type BuildOptions struct {
 	Platforms []Platform
 }
@@ -726,6 +830,7 @@ func (c *Client) printBuildActivity(ctx context.Context) {
 
 type DeployOptions struct {
 	skipBuiltCheck bool
+	preflight      bool
 }
 type DeployOption func(f *DeployOptions)
 
@@ -735,6 +840,14 @@ func WithDeploySkipBuildCheck(skipBuiltCheck bool) DeployOption {
 	}
 }
 
+// WithDeployPreflight runs the preflight checks before deploying, failing
+// without making any changes to the cluster if any of them fail.
+func WithDeployPreflight(preflight bool) DeployOption {
+	return func(f *DeployOptions) {
+		f.preflight = preflight
+	}
+}
+
 // Deploy the function at path.
 // Errors if the function has not been built unless explicitly instructed
 // to ignore this build check.
@@ -761,6 +874,18 @@ func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (Fu
 		return f, ErrNameRequired
 	}
 
+	// Check the function can be deployed before changing anything
+	if options.preflight {
+		report, err := c.Preflight(ctx, f)
+		if err != nil {
+			return f, err
+		}
+		fmt.Fprint(os.Stderr, report)
+		if report.Failed() {
+			return f, ErrPreflightFailed
+		}
+	}
+
 	// Warn if moving
 	changingNamespace := func(f Function) bool {
 		// We're changing namespace if:
@@ -802,6 +927,11 @@ func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (Fu
 	// Update the function to reflect the new deployed state of the Function
 	f.Deploy.Namespace = result.Namespace
 
+	// Ensure the function's domains resolve with the configured DNS provider.
+	if err = c.dnsProvider.Provide(f); err != nil {
+		return f, err
+	}
+
 	if result.Status == Deployed {
 		fmt.Fprintf(os.Stderr, "✅ Function deployed in namespace %q and exposed at URL: \n   %v\n", result.Namespace, result.URL)
 	} else if result.Status == Updated {
@@ -811,6 +941,12 @@ func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (Fu
 	return f, nil
 }
 
+// Preflight checks, without making any changes, that the function can be
+// deployed, returning a report of the checks made.
+func (c *Client) Preflight(ctx context.Context, f Function) (PreflightReport, error) {
+	return c.preflighter.Preflight(ctx, f)
+}
+
 // RunPipeline runs a Pipeline to build and deploy the function.
 // Returned function contains applicable registry and deployed image name.
 // String is the default route.
@@ -821,7 +957,16 @@ func (c *Client) RunPipeline(ctx context.Context, f Function) (string, Function,
 	}
 
 	// Build and deploy function using Pipeline
-	return c.pipelinesProvider.Run(ctx, f)
+	url, f, err := c.pipelinesProvider.Run(ctx, f)
+	if err != nil {
+		return url, f, err
+	}
+
+	// Ensure the function's domains resolve with the configured DNS provider.
+	if err = c.dnsProvider.Provide(f); err != nil {
+		return url, f, err
+	}
+	return url, f, nil
 }
 
 // ConfigurePAC generates Pipeline resources on the local filesystem,
@@ -869,6 +1014,48 @@ func (c *Client) RemovePAC(ctx context.Context, f Function, metadata any) error
 	return nil
 }
 
+// PipelineLogs writes the logs of the named pipeline run of the function, or
+// of its most recent run if no name is provided, to w.  The logs of a run
+// which has not completed are followed until it does.
+func (c *Client) PipelineLogs(ctx context.Context, f Function, run string, w io.Writer) error {
+	return c.pipelinesProvider.Logs(ctx, f, run, w)
+}
+
+// PipelineRuns of the function, most recent first.
+func (c *Client) PipelineRuns(ctx context.Context, f Function) ([]PipelineRun, error) {
+	return c.pipelinesProvider.Runs(ctx, f)
+}
+
+// DescribePipelineRun returns the details of the named pipeline run of the
+// function, including its tasks.
+func (c *Client) DescribePipelineRun(ctx context.Context, f Function, run string) (PipelineRun, error) {
+	return c.pipelinesProvider.DescribeRun(ctx, f, run)
+}
+
+// CancelPipelineRun cancels the named pipeline run of the function.  The run
+// and its logs are retained.
+func (c *Client) CancelPipelineRun(ctx context.Context, f Function, run string) error {
+	return c.pipelinesProvider.CancelRun(ctx, f, run)
+}
+
+// RerunPipelineRun starts a new run of the function's pipeline with the
+// parameters of the named run, returning the name of the new run.
+func (c *Client) RerunPipelineRun(ctx context.Context, f Function, run string) (string, error) {
+	return c.pipelinesProvider.Rerun(ctx, f, run)
+}
+
+// ClearSharedCacheKey is a type available for use as a context key for
+// indicating (with a bool) that ClearPipelineCache is to delete a build cache
+// shared by the functions of the namespace as a whole, clearing it for all of
+// them.  Only the function's own caches are cleared if not set.
+type ClearSharedCacheKey struct{}
+
+// ClearPipelineCache deletes the caches of the function's remote builds, such
+// as of Go modules, npm or Maven, such that its next build starts afresh.
+func (c *Client) ClearPipelineCache(ctx context.Context, f Function) error {
+	return c.pipelinesProvider.ClearCache(ctx, f)
+}
+
 // Route returns the current primary route to the function at root.
 //
 // Note that local instances of the Function created by the .Run
@@ -879,17 +1066,11 @@ func (c *Client) RemovePAC(ctx context.Context, f Function, metadata any) error
 // For access to these local test function instances routes, use the instances
 // manager directly ( see .Instances().Get() ).
 func (c *Client) Route(ctx context.Context, f Function) (string, Function, error) {
-	// Ensure that the allocated final address is enabled with the
-	// configured DNS provider.
 	// NOTE:
-	// DNS and TLS are provisioned by Knative Serving + cert-manager,
-	// but DNS subdomain CNAME to the Kourier Load Balancer is
-	// still manual, and the initial cluster config to suppot the TLD
-	// is still manual.
-	if err := c.dnsProvider.Provide(f); err != nil {
-		return "", f, err
-	}
-
+	// The function's custom domains are provided by the configured DNS
+	// provider when it is deployed.  TLS for the platform's own routes is
+	// provisioned by Knative Serving + cert-manager, and the initial cluster
+	// config to support the TLD is still manual.
 	if f.Deploy.Namespace == "" {
 		return "", Function{}, errors.New("Unable to route function without a namespace.  Is it deployed?")
 	}
@@ -1030,19 +1211,14 @@ func (c *Client) Remove(ctx context.Context, name, namespace string, f Function,
 	}
 	serviceRemovalError := <-serviceRemovalErrCh
 
+	// Unpublish the function's domains, which are owned by its name and
+	// namespace, such that they no longer resolve.  This is done even if the
+	// service was not found, as its records may have been left behind.
+	dnsRemovalError := c.dnsProvider.Provide(
+		Function{Name: name, Deploy: DeploySpec{Namespace: namespace}})
+
 	// Return a combined error
-	return func(e1, e2 error) error {
-		if e1 == nil && e2 == nil {
-			return nil
-		}
-		if e1 != nil && e2 != nil {
-			return errors.New(e1.Error() + "\n" + e2.Error())
-		}
-		if e1 != nil {
-			return e1
-		}
-		return e2
-	}(serviceRemovalError, resourceRemovalError)
+	return errors.Join(serviceRemovalError, resourceRemovalError, dnsRemovalError)
 }
 
 // Invoke is a convenience method for triggering the execution of a function
@@ -1210,8 +1386,8 @@ func Fingerprint(root string) (hash, log string, err error) {
 		if path == root {
 			return nil
 		}
-		// Always ignore .func, .git (TODO: .funcignore)
-		if info.IsDir() && (info.Name() == RunDataDir || info.Name() == ".git") {
+		// Always ignore .func, .git and the managed resources (TODO: .funcignore)
+		if info.IsDir() && (info.Name() == RunDataDir || info.Name() == ".git" || info.Name() == ResourcesDir) {
 			return filepath.SkipDir
 		}
 		fmt.Fprintf(h, "%v:%v:", path, info.ModTime().UnixNano())   // Write to the Hasher
@@ -1364,8 +1540,31 @@ func (n *noopPipelinesProvider) ConfigurePAC(ctx context.Context, _ Function, _
 func (n *noopPipelinesProvider) RemovePAC(ctx context.Context, _ Function, _ any) error {
 	return nil
 }
+func (n *noopPipelinesProvider) Logs(ctx context.Context, _ Function, _ string, _ io.Writer) error {
+	return nil
+}
+func (n *noopPipelinesProvider) Runs(ctx context.Context, _ Function) ([]PipelineRun, error) {
+	return nil, nil
+}
+func (n *noopPipelinesProvider) DescribeRun(ctx context.Context, _ Function, run string) (PipelineRun, error) {
+	return PipelineRun{Name: run}, nil
+}
+func (n *noopPipelinesProvider) CancelRun(ctx context.Context, _ Function, _ string) error {
+	return nil
+}
+func (n *noopPipelinesProvider) Rerun(ctx context.Context, _ Function, _ string) (string, error) {
+	return "", nil
+}
+func (n *noopPipelinesProvider) ClearCache(ctx context.Context, _ Function) error { return nil }
 
 // DNSProvider
 type noopDNSProvider struct{ output io.Writer }
 
 func (n *noopDNSProvider) Provide(_ Function) error { return nil }
+
+// Preflighter
+type noopPreflighter struct{}
+
+func (n *noopPreflighter) Preflight(context.Context, Function) (PreflightReport, error) {
+	return PreflightReport{}, nil
+}
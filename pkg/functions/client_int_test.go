@@ -205,7 +205,7 @@ func TestUpdateWithAnnotationsAndLabels(t *testing.T) {
 	defer Within(t, "testdata/example.com/"+functionName)()
 	verbose := false
 
-	servingClient, err := knative.NewServingClient(DefaultNamespace)
+	servingClient, err := knative.NewServingClient(context.Background(), DefaultNamespace)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -339,6 +339,96 @@ func TestClient_New_HiddenFilesIgnored(t *testing.T) {
 	}
 }
 
+// TestClient_Adopt ensures that existing source code can be adopted as a
+// function: the runtime and invocation are detected, no template files are
+// written, and the existing source is left untouched.
+func TestClient_Adopt(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	src := `package service
+
+import "github.com/cloudevents/sdk-go/v2/event"
+
+func Handle(e event.Event) {}
+`
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module service\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "service.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := fn.New(fn.WithRegistry(TestRegistry))
+	f, err := client.Adopt(fn.Function{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Runtime != "go" || f.Invoke != "cloudevent" {
+		t.Fatalf("unexpected runtime %q or invoke %q", f.Runtime, f.Invoke)
+	}
+	if _, err := os.Stat(filepath.Join(root, "handle.go")); !os.IsNotExist(err) {
+		t.Fatal("template files should not be written when adopting")
+	}
+	if _, err := os.Stat(filepath.Join(root, ".funcignore")); err != nil {
+		t.Fatal(err)
+	}
+	bb, _ := os.ReadFile(filepath.Join(root, "service.go"))
+	if string(bb) != src {
+		t.Fatal("existing source was modified")
+	}
+
+	// Adopting again should fail, as should source without a signature.
+	if _, err = client.Adopt(fn.Function{Root: root}); err == nil {
+		t.Fatal("expected an error adopting an initialized function")
+	}
+	other, rm2 := Mktemp(t)
+	defer rm2()
+	if err := os.WriteFile(filepath.Join(other, "go.mod"), []byte("module other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Adopt(fn.Function{Root: other}); err == nil {
+		t.Fatal("expected an error adopting source without a function signature")
+	}
+}
+
+// TestClient_Adopt_Springboot ensures that Spring Boot projects, whose
+// functions are beans rather than methods of a detectable signature, can be
+// adopted, and that their invocation is detected.
+func TestClient_Adopt_Springboot(t *testing.T) {
+	for _, template := range []string{"http", "cloudevents"} {
+		t.Run(template, func(t *testing.T) {
+			root, rm := Mktemp(t)
+			defer rm()
+			src := filepath.Join(t.TempDir(), "src")
+
+			// Source a project from the embedded template, without func.yaml
+			client := fn.New(fn.WithRegistry(TestRegistry))
+			if _, err := client.Init(fn.Function{Root: src, Runtime: "springboot", Template: template}); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.CopyFS(root, os.DirFS(src)); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Remove(filepath.Join(root, fn.FunctionFile)); err != nil {
+				t.Fatal(err)
+			}
+			_ = os.RemoveAll(filepath.Join(root, fn.RunDataDir))
+
+			f, err := client.Adopt(fn.Function{Root: root})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Runtime != "springboot" {
+				t.Fatalf("expected runtime springboot, got %q", f.Runtime)
+			}
+			if (template == "cloudevents") != (f.Invoke == "cloudevent") {
+				t.Fatalf("unexpected invoke %q for the %v template", f.Invoke, template)
+			}
+		})
+	}
+}
+
 // TestClient_New_RepositoriesExtensible ensures that templates are extensible
 // using a custom path to template repositories on disk. The custom repositories
 // location is not defined herein but expected to be provided because, for
@@ -1046,6 +1136,122 @@ func TestClient_Deploy_NamespaceUpdate(t *testing.T) {
 	}
 }
 
+// TestClient_Deploy_DNSProvided ensures that the DNS provider is invoked with
+// the deployed function, such that its domains resolve.
+func TestClient_Deploy_DNSProvided(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	var (
+		ctx         = context.Background()
+		dnsProvider = mock.NewDNSProvider()
+		provided    fn.Function
+	)
+	dnsProvider.ProvideFn = func(f fn.Function) error {
+		provided = f
+		return nil
+	}
+	client := fn.New(
+		fn.WithRegistry("example.com/alice"),
+		fn.WithDeployer(mock.NewDeployer()),
+		fn.WithDNSProvider(dnsProvider),
+	)
+
+	f, err := client.Init(fn.Function{Runtime: "go", Name: "f", Namespace: "ns", Root: root,
+		Deploy: fn.DeploySpec{Domains: []fn.Domain{{Host: "www.example.com"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Deploy(ctx, f, fn.WithDeploySkipBuildCheck(true)); err != nil {
+		t.Fatal(err)
+	}
+	if !dnsProvider.ProvideInvoked {
+		t.Fatal("dns provider was not invoked")
+	}
+	if provided.Deploy.Namespace != "ns" || len(provided.Deploy.Domains) != 1 {
+		t.Fatalf("dns provider expected the deployed function, got namespace %q and domains %v", provided.Deploy.Namespace, provided.Deploy.Domains)
+	}
+}
+
+// TestClient_RunPipeline_DNSProvided ensures that the DNS provider is invoked
+// with the function deployed remotely, as it is for local deployments.
+func TestClient_RunPipeline_DNSProvided(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	dnsProvider := mock.NewDNSProvider()
+	pipelinesProvider := mock.NewPipelinesProvider()
+	pipelinesProvider.RunFn = func(f fn.Function) (string, fn.Function, error) {
+		f.Deploy.Namespace = "ns"
+		return "http://f.ns.example.com", f, nil
+	}
+	client := fn.New(
+		fn.WithRegistry("example.com/alice"),
+		fn.WithPipelinesProvider(pipelinesProvider),
+		fn.WithDNSProvider(dnsProvider),
+	)
+
+	f, err := client.Init(fn.Function{Runtime: "go", Name: "f", Root: root,
+		Deploy: fn.DeploySpec{Domains: []fn.Domain{{Host: "www.example.com"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = client.RunPipeline(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !dnsProvider.ProvideInvoked {
+		t.Fatal("dns provider was not invoked")
+	}
+}
+
+// TestClient_Deploy_Preflight ensures the preflight checks are run before
+// deploying only when requested, and that a failed check aborts the
+// deployment.
+func TestClient_Deploy_Preflight(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	var (
+		ctx         = context.Background()
+		deployer    = mock.NewDeployer()
+		preflighter = mock.NewPreflighter()
+	)
+	client := fn.New(
+		fn.WithRegistry("example.com/alice"),
+		fn.WithDeployer(deployer),
+		fn.WithPreflighter(preflighter),
+	)
+	f, err := client.Init(fn.Function{Runtime: "go", Name: "f", Namespace: "ns", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not requested
+	if _, err = client.Deploy(ctx, f, fn.WithDeploySkipBuildCheck(true)); err != nil {
+		t.Fatal(err)
+	}
+	if preflighter.PreflightInvoked {
+		t.Fatal("preflight checks were run without being requested")
+	}
+
+	// Requested and failing
+	preflighter.PreflightFn = func(fn.Function) (report fn.PreflightReport, err error) {
+		report.Add("Namespace", fn.PreflightFail, "%q does not exist", "ns")
+		return
+	}
+	deployer.DeployInvoked = false
+	_, err = client.Deploy(ctx, f, fn.WithDeploySkipBuildCheck(true), fn.WithDeployPreflight(true))
+	if !errors.Is(err, fn.ErrPreflightFailed) {
+		t.Fatalf("expected ErrPreflightFailed, got %v", err)
+	}
+	if !preflighter.PreflightInvoked {
+		t.Fatal("preflight checks were not run")
+	}
+	if deployer.DeployInvoked {
+		t.Fatal("function was deployed despite a failed preflight check")
+	}
+}
+
 // TestClient_Remove_ByPath ensures that the remover is invoked to remove
 // the function with the name of the function at the provided root.
 func TestClient_Remove_ByPath(t *testing.T) {
@@ -1085,6 +1291,35 @@ func TestClient_Remove_ByPath(t *testing.T) {
 
 }
 
+// TestClient_Remove_DNSUnpublished ensures that the DNS provider is invoked
+// without domains when a function is removed, such that its records in the
+// namespace from which it was removed no longer resolve.
+func TestClient_Remove_DNSUnpublished(t *testing.T) {
+	var (
+		dnsProvider = mock.NewDNSProvider()
+		provided    fn.Function
+	)
+	dnsProvider.ProvideFn = func(f fn.Function) error {
+		provided = f
+		return nil
+	}
+	client := fn.New(
+		fn.WithRemover(mock.NewRemover()),
+		fn.WithDNSProvider(dnsProvider))
+
+	f := fn.Function{Name: "f", Deploy: fn.DeploySpec{Namespace: "ns",
+		Domains: []fn.Domain{{Host: "www.example.com"}}}}
+	if err := client.Remove(context.Background(), "", "", f, false); err != nil {
+		t.Fatal(err)
+	}
+	if !dnsProvider.ProvideInvoked {
+		t.Fatal("dns provider was not invoked")
+	}
+	if provided.Name != "f" || provided.Deploy.Namespace != "ns" || len(provided.Deploy.Domains) != 0 {
+		t.Fatalf("dns provider expected f in ns without domains, got %q in %q with domains %v", provided.Name, provided.Deploy.Namespace, provided.Deploy.Domains)
+	}
+}
+
 // TestClient_Remove_DeleteAll ensures that the remover is invoked to remove
 // and that dependent resources are removed as well -> pipeline provider is invoked
 // the function with the name of the function at the provided root.
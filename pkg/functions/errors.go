@@ -14,6 +14,7 @@ var (
 	ErrNamespaceRequired         = errors.New("namespace required")
 	ErrNotBuilt                  = errors.New("not built")
 	ErrNotRunning                = errors.New("function not running")
+	ErrPreflightFailed           = errors.New("preflight checks failed")
 	ErrRepositoriesNotDefined    = errors.New("custom template repositories location not specified")
 	ErrRepositoryNotFound        = errors.New("repository not found")
 	ErrRootRequired              = errors.New("function root path is required")
@@ -40,6 +40,27 @@ type Local struct {
 	// Remote indicates the deployment (and possibly build) process are to
 	// be triggered in a remote environment rather than run locally.
 	Remote bool `yaml:"remote,omitempty"`
+
+	// RemoteBuilder which runs remote deployments, eg. "job" on clusters
+	// without Tekton.  Tekton is used if empty.
+	RemoteBuilder string `yaml:"remoteBuilder,omitempty"`
+
+	// Webhook created on the Git provider by Pipelines as Code configuration,
+	// retained such that it is deleted along with the other resources.
+	Webhook *Webhook `yaml:"webhook,omitempty"`
+}
+
+// Webhook on a Git repository.
+type Webhook struct {
+	// Provider of the repository, eg. "github" or "gitea".
+	Provider string `yaml:"provider"`
+	// URL of the repository.
+	URL string `yaml:"url"`
+	// ID of the webhook, as assigned by the provider.
+	ID string `yaml:"id"`
+	// User of the credentials with which the webhook was created, if the
+	// provider requires one (Bitbucket).
+	User string `yaml:"user,omitempty"`
 }
 
 // Function
@@ -105,6 +126,14 @@ type Function struct {
 	// Deploy defines the deployment properties for a function
 	Deploy DeploySpec `yaml:"deploy,omitempty"`
 
+	// Environments are named sets of deployment settings which can be
+	// selected to override those of the function.  See WithEnvironment.
+	Environments map[string]Environment `yaml:"environments,omitempty"`
+
+	// Environment is the name of the environment currently applied to the
+	// function, if any.  See WithEnvironment.
+	Environment string `yaml:"-"`
+
 	Local Local `yaml:"-"`
 }
 
@@ -137,10 +166,39 @@ type BuildSpec struct {
 	// Build Env variables to be set
 	BuildEnvs Envs `yaml:"buildEnvs,omitempty"`
 
+	// Stages are additional tasks of the pipeline which builds and deploys
+	// the function on cluster, such as tests, linters or scans, which run
+	// before it is built or deployed.
+	Stages []Stage `yaml:"stages,omitempty"`
+
+	// DependsOn are the paths, relative to the function's root, of other
+	// functions of its workspace (such as a monorepo) which are built and
+	// deployed before it by the workspace commands.  The function is rebuilt
+	// whenever a function it depends on is.
+	DependsOn []string `yaml:"dependsOn,omitempty"`
+
 	// PVCSize specifies the size of persistent volume claim used to store function
 	// when using deployment and remote build process (only relevant when Remote is true).
 	PVCSize string `yaml:"pvcSize,omitempty"`
 
+	// CachePVCSize specifies the size of the persistent volume claim holding
+	// the caches of remote builds, such as of Go modules, npm or Maven.
+	CachePVCSize string `yaml:"cachePvcSize,omitempty"`
+
+	// SharedCache indicates the caches of remote builds are held by a
+	// persistent volume claim shared by the functions of the namespace,
+	// rather than by one of the function's own.  With the default access
+	// mode, builds of different functions which run on different nodes
+	// wait for each other to release the volume.
+	SharedCache bool `yaml:"sharedCache,omitempty"`
+
+	// CacheAccessMode of the persistent volume claim holding the caches of
+	// remote builds: ReadWriteOnce (the default), or ReadWriteMany such that
+	// builds sharing the cache run concurrently on any node.  ReadWriteMany
+	// requires a storage class which supports it.  The mode of an existing
+	// claim is not changed until the cache is cleared.
+	CacheAccessMode string `yaml:"cacheAccessMode,omitempty" jsonschema:"enum=ReadWriteOnce,enum=ReadWriteMany"`
+
 	// Image stores last built image name NOT in func.yaml, but instead
 	// in .func/built-image
 	Image string `yaml:"-"`
@@ -154,15 +212,24 @@ type RunSpec struct {
 	// Env variables to be set
 	Envs Envs `yaml:"envs,omitempty"`
 
+	// EnvFiles are paths, relative to the function's root, of dotenv files
+	// whose variables are set in addition to Envs.  Variables from later files
+	// take precedence over earlier ones, and those of Envs over all.
+	EnvFiles []string `yaml:"envFiles,omitempty"`
+
 	// StartTimeout specifies that this function should have a custom timeout
-	// when starting. This setting is currently respected by the host runner,
-	// with containerized docker runner and deployed Knative service integration
-	// in development.
+	// when starting. This setting is respected by the host runner and, as the
+	// revision's progress deadline, by deployed Knative services, with
+	// containerized docker runner integration in development.
 	StartTimeout time.Duration `yaml:"startTimeout,omitempty"`
 }
 
 // DeploySpec
 type DeploySpec struct {
+	// Platform to which the function is deployed, such as "knative" (the
+	// default), "kubernetes" or "docker".
+	Platform string `yaml:"platform,omitempty" jsonschema:"enum=knative,enum=kubernetes,enum=docker"`
+
 	// Namespace into which the function was deployed on supported platforms.
 	Namespace string `yaml:"namespace,omitempty"`
 
@@ -189,12 +256,58 @@ type DeploySpec struct {
 	ServiceAccountName string `yaml:"serviceAccountName,omitempty"`
 
 	Subscriptions []KnativeSubscription `yaml:"subscriptions,omitempty"`
+
+	// Sidecars are additional containers deployed alongside the function.
+	Sidecars []Container `yaml:"sidecars,omitempty"`
+
+	// InitContainers are run to completion, in order, before the function
+	// starts.
+	InitContainers []Container `yaml:"initContainers,omitempty"`
+
+	// Scheduling constrains the nodes on which the function is run.
+	Scheduling Scheduling `yaml:"scheduling,omitempty"`
+
+	// Expose the function outside the cluster on platforms which do not
+	// route to it themselves.
+	Expose Expose `yaml:"expose,omitempty"`
+
+	// Domains are custom hostnames at which the function is served, mapped
+	// to it with Knative DomainMappings.
+	Domains []Domain `yaml:"domains,omitempty"`
+
+	// Visibility of the function: "public" (the default) or "cluster-local"
+	// to serve it only within the cluster.
+	Visibility string `yaml:"visibility,omitempty" jsonschema:"enum=public,enum=cluster-local"`
+
+	// AllowFrom restricts ingress to the function to the listed namespaces
+	// and functions with a NetworkPolicy, and also with an Istio
+	// AuthorizationPolicy if AllowFromPolicy is "istio".  The NetworkPolicy
+	// alone only restricts direct connections to the function's pods, as
+	// requests routed by Knative reach them from its activator and gateways.
+	// If empty, ingress is unrestricted.
+	AllowFrom []AllowFrom `yaml:"allowFrom,omitempty"`
+
+	// AllowFromPolicy by which the allow list is enforced: "networkpolicy"
+	// (the default), or "istio" to also deny requests routed through the
+	// activator and gateways with an Istio AuthorizationPolicy.  The policy
+	// is created in the istio-system namespace, so deploying requires the
+	// permission to manage AuthorizationPolicies there, which users limited
+	// to their own namespace are usually not granted.
+	AllowFromPolicy string `yaml:"allowFromPolicy,omitempty" jsonschema:"enum=networkpolicy,enum=istio"`
+
+	// IngressNamespaces from which requests routed by Knative reach a
+	// function with an allow list: those of its activator and networking
+	// layer.  Defaults to knative-serving, istio-system, kourier-system,
+	// contour-external and contour-internal.
+	IngressNamespaces []string `yaml:"ingressNamespaces,omitempty"`
 }
 
-// HealthEndpoints specify the liveness and readiness endpoints for a Runtime
+// HealthEndpoints specify the liveness, readiness and startup probes for a
+// Runtime.  See Probe.
 type HealthEndpoints struct {
-	Liveness  string `yaml:"liveness,omitempty"`
-	Readiness string `yaml:"readiness,omitempty"`
+	Liveness  Probe `yaml:"liveness,omitempty"`
+	Readiness Probe `yaml:"readiness,omitempty"`
+	Startup   Probe `yaml:"startup,omitempty"`
 }
 
 // BuildConfig defines builders and buildpacks
@@ -309,11 +422,24 @@ func (f Function) Validate() error {
 	var ctr int
 	errs := [][]string{
 		validateVolumes(f.Run.Volumes),
-		ValidateBuildEnvs(f.Build.BuildEnvs),
-		ValidateEnvs(f.Run.Envs),
+		validateVolumesPlatform(f.Deploy.Platform, f.Run.Volumes),
+		ValidateBuildEnvsIn(f.Root, f.Build.BuildEnvs),
+		ValidateEnvsIn(f.Root, f.Run.Envs),
+		ValidateEnvFiles(f.Root, f.Run.EnvFiles),
 		validateOptions(f.Deploy.Options),
 		ValidateLabels(f.Deploy.Labels),
+		validateHealthEndpoints(f.Deploy.HealthEndpoints),
+		validateContainers(f.Root, f.Deploy.Platform, f.Deploy.Sidecars, f.Deploy.InitContainers, f.Run.Volumes),
+		validateScheduling(f.Deploy.Platform, f.Deploy.Scheduling),
+		validateExpose(f.Deploy.Expose),
+		validateDomains(f.Deploy.Platform, f.Deploy.Domains),
+		validateVisibility(f.Deploy.Platform, f.Deploy.Visibility),
+		validateAllowFrom(f.Deploy.Platform, f.Deploy.AllowFrom),
+		validateAllowFromPolicy(f.Deploy.AllowFromPolicy, f.Deploy.IngressNamespaces),
 		validateGit(f.Build.Git),
+		validateCacheAccessMode(f.Build.CacheAccessMode),
+		validateStages(f.Root, f.Build.Stages),
+		validateEnvironments(f.Root, f.Environments),
 	}
 
 	var b strings.Builder
@@ -341,11 +467,20 @@ var envPattern = regexp.MustCompile(`^{{\s*(\w+)\s*:(\w+)\s*}}$`)
 // Interpolate Env slice
 // Values with no special format are preserved as simple values.
 // Values which do include the interpolation format (begin with {{) but are not
-// keyed as "env" are also preserved as is.
+// keyed as "env" or "file" are also preserved as is.
 // Values properly formatted as {{ env:NAME }} are interpolated (substituted)
 // with the value of the local environment variable "NAME", and an error is
 // returned if that environment variable does not exist.
+// Values formatted as {{ file:PATH }} are substituted with the contents of the
+// file at PATH, which is relative to the current working directory.  See
+// InterpolateIn for resolving paths relative to a function's root.
 func Interpolate(ee []Env) (map[string]string, error) {
+	return InterpolateIn("", ee)
+}
+
+// InterpolateIn is Interpolate, with {{ file:PATH }} references resolved
+// relative to the given root directory.
+func InterpolateIn(root string, ee []Env) (map[string]string, error) {
 	envs := make(map[string]string, len(ee))
 	for _, e := range ee {
 		// Assert non-nil name.
@@ -365,6 +500,15 @@ func Interpolate(ee []Env) (map[string]string, error) {
 			continue
 		}
 
+		// File references are substituted with the file's contents.
+		if fileValue, ok, err := EnvFileValue(root, v); ok {
+			if err != nil {
+				return envs, err
+			}
+			envs[k] = fileValue
+			continue
+		}
+
 		// Values not matching the interpolation pattern are preserved.
 		// If not in the form "{{ env:XYZ }}" then return the value as-is for
 		//                     0  1   2   3
@@ -406,6 +550,18 @@ func nameFromPath(path string) string {
 
 // Write Function struct (metadata) to Disk at f.Root
 func (f Function) Write() (err error) {
+	// Persist any applied environment's settings to that environment rather
+	// than to the function itself.  Its built image is recorded first, as it
+	// is replaced by that of the function.
+	if f.Environment != "" {
+		if err = f.WriteRuntimeBuiltImage(false); err != nil {
+			return
+		}
+	}
+	if f, err = f.withoutEnvironment(); err != nil {
+		return
+	}
+
 	// Skip writing (and dirtying the work tree) if there were no modifications.
 	f1, _ := NewFunction(f.Root)
 	if reflect.DeepEqual(f, f1) {
@@ -644,6 +800,7 @@ var (
 	regWholeConfigMap   = regexp.MustCompile(`^{{\s*configMap:((?:\w|['-]\w)+)\s*}}$`)
 	regKeyFromConfigMap = regexp.MustCompile(`^{{\s*configMap:((?:\w|['-]\w)+):([-._a-zA-Z0-9]+)\s*}}$`)
 	regLocalEnv         = regexp.MustCompile(`^{{\s*env:(\w+)\s*}}$`)
+	regFile             = regexp.MustCompile(`^{{\s*file:(.+?)\s*}}$`)
 )
 
 // Built returns true if the function is considered built.
@@ -727,7 +884,7 @@ func (f Function) newLocal() (localConfig Local, err error) {
 // WriteRuntimeBuiltImage writes built image name into runtime metadata
 // directory (.func/) from f.Build.Image
 func (f Function) WriteRuntimeBuiltImage(verbose bool) error {
-	path := filepath.Join(f.Root, RunDataDir, BuiltImage)
+	path := f.builtImagePath()
 
 	// dont write if empty (not built)
 	if f.Build.Image == "" {
@@ -744,7 +901,7 @@ func (f Function) WriteRuntimeBuiltImage(verbose bool) error {
 // getLastBuiltImage reads .func/built-image and returns its value or empty string
 // if the file doesnt exist (not built yet). Other errors are returned as usual.
 func (f Function) getLastBuiltImage() (string, error) {
-	path := filepath.Join(f.Root, RunDataDir, BuiltImage)
+	path := f.builtImagePath()
 	if _, err := os.Stat(path); err != nil {
 		if os.IsNotExist(err) {
 			return "", nil
@@ -759,6 +916,16 @@ func (f Function) getLastBuiltImage() (string, error) {
 	return string(b), nil
 }
 
+// builtImagePath returns the path of the file holding the last built image.
+// Images built with an environment applied are kept apart from those of the
+// function, as they differ in registry.
+func (f Function) builtImagePath() string {
+	if f.Environment != "" {
+		return filepath.Join(f.Root, RunDataDir, BuiltImage+"-"+f.Environment)
+	}
+	return filepath.Join(f.Root, RunDataDir, BuiltImage)
+}
+
 // ImageNameWithDigest works with f.Build.Image and image digest. if the func
 // parameter newDigest is empty, just return the image name as is.
 // TODO: This function is a temporary one for a workaround for a current
@@ -0,0 +1,27 @@
+package functions
+
+import (
+	"fmt"
+)
+
+const (
+	// CacheAccessModeReadWriteOnce caches are mounted by the builds of a
+	// single node at a time.
+	CacheAccessModeReadWriteOnce = "ReadWriteOnce"
+
+	// CacheAccessModeReadWriteMany caches are mounted by builds on any node
+	// concurrently.
+	CacheAccessModeReadWriteMany = "ReadWriteMany"
+)
+
+// validateCacheAccessMode checks that the access mode of the build cache is
+// one supported.
+// Returns array of error messages, empty if no errors are found
+func validateCacheAccessMode(mode string) (errors []string) {
+	switch mode {
+	case "", CacheAccessModeReadWriteOnce, CacheAccessModeReadWriteMany:
+	default:
+		errors = append(errors, fmt.Sprintf("cacheAccessMode %q is not valid, it must be %q or %q", mode, CacheAccessModeReadWriteOnce, CacheAccessModeReadWriteMany))
+	}
+	return
+}
@@ -0,0 +1,22 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import "testing"
+
+// Test_validateCacheAccessMode ensures only ReadWriteOnce and ReadWriteMany
+// are accepted.
+func Test_validateCacheAccessMode(t *testing.T) {
+	for mode, errs := range map[string]int{
+		"":              0,
+		"ReadWriteOnce": 0,
+		"ReadWriteMany": 0,
+		"ReadOnlyMany":  1,
+		"readwritemany": 1,
+	} {
+		if got := validateCacheAccessMode(mode); len(got) != errs {
+			t.Errorf("mode %q: expected %v errors, got %v", mode, errs, got)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package functions
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Container is an additional container deployed with the function, either as
+// a sidecar running alongside it (for example an authenticating proxy), or
+// as an init container which runs to completion before it starts (for
+// example a database migration).  The function's own container remains that
+// which serves requests.
+type Container struct {
+	// Name of the container, unique within the function.
+	Name string `yaml:"name"`
+
+	// Image of the container.
+	Image string `yaml:"image"`
+
+	// Command overriding the image's entrypoint.
+	Command []string `yaml:"command,omitempty"`
+
+	// Args to the command.
+	Args []string `yaml:"args,omitempty"`
+
+	// Envs of the container, which may be set in the same ways as those of
+	// the function itself.
+	Envs Envs `yaml:"envs,omitempty"`
+
+	// Ports on which the container listens.  Only permitted of sidecars on
+	// platforms other than Knative, which permits only the serving container
+	// (the function's) to declare a port.
+	Ports []int32 `yaml:"ports,omitempty"`
+
+	// VolumeMounts of the function's volumes (see RunSpec.Volumes) into the
+	// container.
+	VolumeMounts []VolumeMount `yaml:"volumeMounts,omitempty"`
+}
+
+// VolumeMount shares one of the function's volumes with a container.
+type VolumeMount struct {
+	// Volume is the path at which the volume is mounted in the function,
+	// identifying it among the function's volumes.
+	Volume string `yaml:"volume"`
+
+	// Path at which to mount the volume in the container, defaulting to that
+	// of the function.
+	Path string `yaml:"path,omitempty"`
+
+	// ReadOnly mounts the volume read-only.
+	ReadOnly bool `yaml:"readOnly,omitempty"`
+}
+
+// MountPath of the volume in the container.
+func (m VolumeMount) MountPath() string {
+	if m.Path != "" {
+		return m.Path
+	}
+	return m.Volume
+}
+
+// regContainerName is that of a Kubernetes container name (DNS-1123 label).
+var regContainerName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// reservedContainerNames may not be used by sidecars or init containers.
+var reservedContainerNames = map[string]bool{
+	"user-container": true, // the function's container on Knative
+	"queue-proxy":    true, // Knative's sidecar
+}
+
+// validateContainers checks that the function's sidecars and init containers
+// have unique, valid names, an image, valid envs and ports, and that their
+// volume mounts reference the function's volumes.  Sidecars may not declare
+// ports when deployed to Knative (the default platform), and the docker
+// platform, which runs the function's container alone, supports neither
+// sidecars nor init containers.
+// Returns array of error messages, empty if no errors are found
+func validateContainers(root, platform string, sidecars, initContainers []Container, volumes []Volume) (errors []string) {
+	if platform == "docker" {
+		if len(sidecars) > 0 {
+			errors = append(errors, "sidecars are not supported by the docker platform")
+		}
+		if len(initContainers) > 0 {
+			errors = append(errors, "init containers are not supported by the docker platform")
+		}
+	}
+	paths := map[string]bool{}
+	for _, v := range volumes {
+		if v.Path != nil {
+			paths[*v.Path] = true
+		}
+	}
+	names := map[string]bool{}
+	for _, group := range []struct {
+		kind       string
+		containers []Container
+	}{
+		{"sidecar", sidecars},
+		{"init container", initContainers},
+	} {
+		for i, c := range group.containers {
+			id := fmt.Sprintf("%v #%d (%v)", group.kind, i, c.Name)
+			switch {
+			case c.Name == "":
+				errors = append(errors, fmt.Sprintf("%v is missing a name", id))
+			case len(c.Name) > 63 || !regContainerName.MatchString(c.Name):
+				errors = append(errors, fmt.Sprintf("%v has an invalid name, it must consist of lower case alphanumeric characters or '-'", id))
+			case reservedContainerNames[c.Name]:
+				errors = append(errors, fmt.Sprintf("%v has a reserved name", id))
+			case names[c.Name]:
+				errors = append(errors, fmt.Sprintf("%v has the same name as another container", id))
+			}
+			names[c.Name] = true
+
+			if c.Image == "" {
+				errors = append(errors, fmt.Sprintf("%v is missing an image", id))
+			}
+			if group.kind == "sidecar" && len(c.Ports) > 0 && (platform == "" || platform == "knative") {
+				errors = append(errors, fmt.Sprintf("%v declares ports, which Knative permits only of the function's container", id))
+			}
+			for _, p := range c.Ports {
+				if p <= 0 || p > 65535 {
+					errors = append(errors, fmt.Sprintf("%v has an invalid port %d", id, p))
+				}
+			}
+			for _, m := range c.VolumeMounts {
+				if !paths[m.Volume] {
+					errors = append(errors, fmt.Sprintf("%v mounts volume %q, which is not a volume of the function", id, m.Volume))
+				}
+			}
+			for _, err := range ValidateEnvsIn(root, c.Envs) {
+				errors = append(errors, fmt.Sprintf("%v: %v", id, err))
+			}
+		}
+	}
+	return
+}
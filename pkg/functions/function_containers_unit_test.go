@@ -0,0 +1,49 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import "testing"
+
+// Test_validateContainers ensures sidecars and init containers are named
+// uniquely, have an image and mount only the function's volumes, that
+// sidecars declare ports only where the platform permits, and that there are
+// none for the docker platform.
+func Test_validateContainers(t *testing.T) {
+	secret, path := "credentials", "/etc/credentials"
+	volumes := []Volume{{Secret: &secret, Path: &path}}
+
+	tests := []struct {
+		name           string
+		platform       string
+		sidecars       []Container
+		initContainers []Container
+		errs           int
+	}{
+		{"none", "", nil, nil, 0},
+		{"valid", "",
+			[]Container{{Name: "proxy", Image: "example.com/proxy", VolumeMounts: []VolumeMount{{Volume: path}}}},
+			[]Container{{Name: "migrate", Image: "example.com/migrate"}},
+			0},
+		{"sidecar port", "kubernetes", []Container{{Name: "proxy", Image: "proxy", Ports: []int32{8443}}}, nil, 0},
+		{"sidecar port on knative", "knative", []Container{{Name: "proxy", Image: "proxy", Ports: []int32{8443}}}, nil, 1},
+		{"sidecar on docker", "docker", []Container{{Name: "proxy", Image: "proxy"}}, nil, 1},
+		{"init container on docker", "docker", nil, []Container{{Name: "migrate", Image: "migrate"}}, 1},
+		{"missing name and image", "", []Container{{}}, nil, 2},
+		{"invalid name", "", []Container{{Name: "Proxy", Image: "proxy"}}, nil, 1},
+		{"reserved name", "", []Container{{Name: "user-container", Image: "proxy"}}, nil, 1},
+		{"duplicate name", "",
+			[]Container{{Name: "proxy", Image: "proxy"}},
+			[]Container{{Name: "proxy", Image: "migrate"}},
+			1},
+		{"invalid port", "kubernetes", []Container{{Name: "proxy", Image: "proxy", Ports: []int32{0}}}, nil, 1},
+		{"unknown volume", "", nil, []Container{{Name: "migrate", Image: "migrate", VolumeMounts: []VolumeMount{{Volume: "/data"}}}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateContainers("", tt.platform, tt.sidecars, tt.initContainers, volumes); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
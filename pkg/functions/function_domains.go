@@ -0,0 +1,40 @@
+package functions
+
+import (
+	"fmt"
+)
+
+// Domain is a custom hostname at which the function is served, in addition
+// to the route assigned by the platform.
+type Domain struct {
+	// Host is the full hostname, for example www.example.com.
+	Host string `yaml:"host"`
+
+	// TLSSecret is the name of a Secret of type kubernetes.io/tls with the
+	// certificate of the host.  If not set, the host is served over HTTP
+	// unless the platform provisions a certificate itself.
+	TLSSecret string `yaml:"tlsSecret,omitempty"`
+}
+
+// validateDomains checks that each domain is a valid and unique hostname,
+// with a valid TLS secret name if defined.  Domains are mapped by Knative
+// only, so are not valid for functions deployed to other platforms.
+// Returns array of error messages, empty if no errors are found
+func validateDomains(platform string, domains []Domain) (errors []string) {
+	if len(domains) > 0 && platform != "" && platform != "knative" {
+		errors = append(errors, fmt.Sprintf("domains are supported by the knative platform only, not %q", platform))
+	}
+	hosts := make(map[string]bool, len(domains))
+	for i, d := range domains {
+		if d.Host == "" || len(d.Host) > 253 || !resourceNameRegex.MatchString(d.Host) {
+			errors = append(errors, fmt.Sprintf("domain entry #%d has invalid host %q, it must be a lower case hostname such as www.example.com", i, d.Host))
+		} else if hosts[d.Host] {
+			errors = append(errors, fmt.Sprintf("domain entry #%d has duplicate host %q", i, d.Host))
+		}
+		hosts[d.Host] = true
+		if d.TLSSecret != "" && !resourceNameRegex.MatchString(d.TLSSecret) {
+			errors = append(errors, fmt.Sprintf("domain entry #%d has invalid tlsSecret %q, it must consist of lower case alphanumeric characters, '-' or '.'", i, d.TLSSecret))
+		}
+	}
+	return
+}
@@ -0,0 +1,35 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import "testing"
+
+// Test_validateDomains ensures invalid and duplicate hosts, invalid TLS
+// secret names, and domains of platforms other than Knative are reported.
+func Test_validateDomains(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		domains  []Domain
+		errs     int
+	}{
+		{"none", "", nil, 0},
+		{"valid", "", []Domain{{Host: "www.example.com", TLSSecret: "www-tls"}, {Host: "api.example.com"}}, 0},
+		{"knative", "knative", []Domain{{Host: "www.example.com"}}, 0},
+		{"kubernetes", "kubernetes", []Domain{{Host: "www.example.com"}}, 1},
+		{"docker", "docker", []Domain{{Host: "www.example.com"}}, 1},
+		{"kubernetes without domains", "kubernetes", nil, 0},
+		{"missing host", "", []Domain{{TLSSecret: "www-tls"}}, 1},
+		{"invalid host", "", []Domain{{Host: "WWW.example.com"}}, 1},
+		{"duplicate host", "", []Domain{{Host: "www.example.com"}, {Host: "www.example.com"}}, 1},
+		{"invalid tls secret", "", []Domain{{Host: "www.example.com", TLSSecret: "www_tls"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateDomains(tt.platform, tt.domains); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package functions
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Environment is a named set of deployment settings which, when selected,
+// are overlaid atop those defined at the root of the function.  This allows
+// a single function to be deployed to, for example, both a "staging" and a
+// "prod" environment which differ in namespace, registry or configuration:
+//
+//	environments:
+//	  staging:
+//	    namespace: myfunc-staging
+//	    envs:
+//	    - name: LOG_LEVEL
+//	      value: debug
+//	  prod:
+//	    namespace: myfunc
+//	    registry: quay.io/alice-prod
+//
+// Each environment also tracks the state of its own most recent deployment,
+// independently of the function's default Deploy.Namespace and Deploy.Image.
+type Environment struct {
+	// Namespace into which the function should be deployed when targeting
+	// this environment.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Registry to use for this environment's function images.
+	Registry string `yaml:"registry,omitempty"`
+
+	// Envs which are added to (or override, by name) those of the function.
+	Envs Envs `yaml:"envs,omitempty"`
+
+	// Volumes which are added to (or override, by path) those of the function.
+	Volumes []Volume `yaml:"volumes,omitempty"`
+
+	// Options which override those of the function.  Scale and Resources
+	// are each replaced in their entirety if defined.
+	Options Options `yaml:"options,omitempty"`
+
+	// Labels which are added to (or override, by key) those of the function.
+	Labels []Label `yaml:"labels,omitempty"`
+
+	// Deployed records the state of the most recent deployment of the
+	// function to this environment.  It is maintained by the system.
+	Deployed EnvironmentDeployment `yaml:"deployed,omitempty"`
+}
+
+// EnvironmentDeployment is the deployment state of a function in a single
+// named environment.
+type EnvironmentDeployment struct {
+	// Namespace into which the function was deployed.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Image is the deployed image including sha256.
+	Image string `yaml:"image,omitempty"`
+}
+
+var regEnvironmentName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// WithEnvironment returns the function with the settings of the named
+// environment applied.  Deployment state (.Deploy.Namespace and
+// .Deploy.Image) and the last built image (.Build.Image) are those of the
+// environment.  An empty name returns the
+// function unchanged.  Names which are not defined in .Environments are
+// an ErrEnvironmentNotFound.
+//
+// Writing a function with an environment applied persists the resultant
+// deployment state, built image, namespace, registry and any changed envs,
+// volumes, options or labels to the environment, leaving the function's own
+// settings intact.  Removing an env, volume, option or label while an
+// environment is applied can not be expressed by the environment, and is an
+// error on write.
+func (f Function) WithEnvironment(name string) (Function, error) {
+	if name == "" {
+		return f, nil
+	}
+	if f.Environment != "" {
+		return f, fmt.Errorf("environment %q is already applied", f.Environment)
+	}
+	e, ok := f.Environments[name]
+	if !ok {
+		return f, fmt.Errorf("%w: %q", ErrEnvironmentNotFound, name)
+	}
+
+	if e.Namespace != "" {
+		f.Namespace = e.Namespace
+	}
+	if e.Registry != "" {
+		f.Registry = e.Registry
+	}
+	f.Run.Envs = overlayEnvs(f.Run.Envs, e.Envs)
+	f.Run.Volumes = overlayVolumes(f.Run.Volumes, e.Volumes)
+	if e.Options.Scale != nil {
+		f.Deploy.Options.Scale = e.Options.Scale
+	}
+	if e.Options.Resources != nil {
+		f.Deploy.Options.Resources = e.Options.Resources
+	}
+	f.Deploy.Labels = overlayLabels(f.Deploy.Labels, e.Labels)
+	f.Deploy.Namespace = e.Deployed.Namespace
+	f.Deploy.Image = e.Deployed.Image
+	f.Environment = name
+	// The last built image is also that of the environment
+	var err error
+	if f.Build.Image, err = f.getLastBuiltImage(); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// withoutEnvironment returns the function as it should be persisted: with
+// the settings of any applied environment replaced by those of the function
+// as it exists on disk, and the deployment state and any changed settings
+// recorded on the environment.
+func (f Function) withoutEnvironment() (Function, error) {
+	if f.Environment == "" {
+		return f, nil
+	}
+	base, err := NewFunction(f.Root)
+	if err != nil {
+		return f, err
+	}
+	base.Environments = f.Environments
+	applied, err := base.WithEnvironment(f.Environment)
+	if err != nil {
+		return f, err
+	}
+
+	e := f.Environments[f.Environment]
+	e.Deployed = EnvironmentDeployment{
+		Namespace: f.Deploy.Namespace,
+		Image:     f.Deploy.Image,
+	}
+	if f.Namespace != applied.Namespace {
+		e.Namespace = f.Namespace
+	}
+	if f.Registry != applied.Registry {
+		e.Registry = f.Registry
+	}
+
+	// Envs, volumes, options and labels changed since the environment was
+	// applied (for example with --env) are added to those of the environment.
+	// Those removed can not be, as an environment only overlays the function.
+	var removed []string
+	envs, envsRemoved := changedItems(applied.Run.Envs, f.Run.Envs, func(e Env) *string { return e.Name })
+	e.Envs = overlayEnvs(e.Envs, envs)
+	volumes, volumesRemoved := changedItems(applied.Run.Volumes, f.Run.Volumes, func(v Volume) *string { return v.Path })
+	e.Volumes = overlayVolumes(e.Volumes, volumes)
+	labels, labelsRemoved := changedItems(applied.Deploy.Labels, f.Deploy.Labels, func(l Label) *string { return l.Key })
+	e.Labels = overlayLabels(e.Labels, labels)
+	for _, v := range envsRemoved {
+		removed = append(removed, v.String())
+	}
+	for _, v := range volumesRemoved {
+		removed = append(removed, v.String())
+	}
+	for _, v := range labelsRemoved {
+		removed = append(removed, v.String())
+	}
+	if !reflect.DeepEqual(f.Deploy.Options.Scale, applied.Deploy.Options.Scale) {
+		if f.Deploy.Options.Scale == nil {
+			removed = append(removed, "Scale options")
+		}
+		e.Options.Scale = f.Deploy.Options.Scale
+	}
+	if !reflect.DeepEqual(f.Deploy.Options.Resources, applied.Deploy.Options.Resources) {
+		if f.Deploy.Options.Resources == nil {
+			removed = append(removed, "Resources options")
+		}
+		e.Options.Resources = f.Deploy.Options.Resources
+	}
+	if len(removed) > 0 {
+		return f, fmt.Errorf("unable to remove settings with environment %q applied, remove them from the function or the environment instead: %v", f.Environment, strings.Join(removed, ", "))
+	}
+
+	environments := make(map[string]Environment, len(f.Environments))
+	for k, v := range f.Environments {
+		environments[k] = v
+	}
+	environments[f.Environment] = e
+
+	f.Environments = environments
+	f.Namespace = base.Namespace
+	f.Registry = base.Registry
+	f.Run.Envs = base.Run.Envs
+	f.Run.Volumes = base.Run.Volumes
+	f.Deploy.Options = base.Deploy.Options
+	f.Deploy.Labels = base.Deploy.Labels
+	f.Deploy.Namespace = base.Deploy.Namespace
+	f.Deploy.Image = base.Deploy.Image
+	f.Build.Image = base.Build.Image
+	f.Environment = ""
+	return f, nil
+}
+
+// ValidateEnvironment returns an error if the changes made to the function
+// since its environment was applied can not be persisted to that environment
+// when written.  A function without an environment applied is always valid.
+func (f Function) ValidateEnvironment() error {
+	_, err := f.withoutEnvironment()
+	return err
+}
+
+// changedItems returns the items of current which are not in applied, either
+// by key or, for those without a key, in their entirety, and those of applied
+// which are no longer in current.
+func changedItems[T any](applied, current []T, key func(T) *string) (changed, removed []T) {
+	contains := func(items []T, item T) bool {
+		for _, i := range items {
+			if k, ik := key(item), key(i); k != nil && ik != nil && *k == *ik {
+				return reflect.DeepEqual(item, i)
+			}
+		}
+		for _, i := range items {
+			if reflect.DeepEqual(item, i) {
+				return true
+			}
+		}
+		return false
+	}
+	has := func(items []T, item T) bool {
+		for _, i := range items {
+			if k, ik := key(item), key(i); k != nil && ik != nil && *k == *ik {
+				return true
+			}
+		}
+		return contains(items, item)
+	}
+	for _, item := range current {
+		if !contains(applied, item) {
+			changed = append(changed, item)
+		}
+	}
+	for _, item := range applied {
+		if !has(current, item) {
+			removed = append(removed, item)
+		}
+	}
+	return
+}
+
+// overlayEnvs returns a new list of envs consisting of base with those in
+// overlay either replacing those of the same name or appended.
+func overlayEnvs(base, overlay Envs) Envs {
+	if len(overlay) == 0 {
+		return base
+	}
+	result := append(Envs{}, base...)
+	for _, e := range overlay {
+		replaced := false
+		for i := range result {
+			if e.Name != nil && result[i].Name != nil && *e.Name == *result[i].Name {
+				result[i] = e
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// overlayVolumes returns a new list of volumes consisting of base with those
+// in overlay either replacing those mounted at the same path or appended.
+func overlayVolumes(base, overlay []Volume) []Volume {
+	if len(overlay) == 0 {
+		return base
+	}
+	result := append([]Volume{}, base...)
+	for _, v := range overlay {
+		replaced := false
+		for i := range result {
+			if v.Path != nil && result[i].Path != nil && *v.Path == *result[i].Path {
+				result[i] = v
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// overlayLabels returns a new list of labels consisting of base with those
+// in overlay either replacing those of the same key or appended.
+func overlayLabels(base, overlay []Label) []Label {
+	if len(overlay) == 0 {
+		return base
+	}
+	result := append([]Label{}, base...)
+	for _, l := range overlay {
+		replaced := false
+		for i := range result {
+			if l.Key != nil && result[i].Key != nil && *l.Key == *result[i].Key {
+				result[i] = l
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+// validateEnvironments checks that each named environment has a valid name
+// and contains valid settings.
+// Returns array of error messages, empty if no errors are found
+func validateEnvironments(root string, environments map[string]Environment) (errors []string) {
+	names := make([]string, 0, len(environments))
+	for name := range environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == EnvironmentLocal || name == EnvironmentRemote {
+			errors = append(errors, fmt.Sprintf("environment name %q is reserved", name))
+			continue
+		}
+		if !regEnvironmentName.MatchString(name) {
+			errors = append(errors, fmt.Sprintf("environment name %q is invalid, it must consist of lower case alphanumeric characters or '-'", name))
+			continue
+		}
+		e := environments[name]
+		for _, ee := range [][]string{
+			validateVolumes(e.Volumes),
+			ValidateEnvsIn(root, e.Envs),
+			validateOptions(e.Options),
+			ValidateLabels(e.Labels),
+		} {
+			for _, err := range ee {
+				errors = append(errors, fmt.Sprintf("environment %q: %s", name, err))
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,275 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"knative.dev/pkg/ptr"
+
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestWithEnvironment_Overlay ensures that the settings of a named environment
+// are applied atop those of the function.
+func TestWithEnvironment_Overlay(t *testing.T) {
+	f := Function{
+		Namespace: "default-ns",
+		Registry:  "example.com/alice",
+		Run: RunSpec{
+			Envs: Envs{
+				{Name: ptr.String("A"), Value: ptr.String("a")},
+				{Name: ptr.String("B"), Value: ptr.String("b")},
+			},
+			Volumes: []Volume{
+				{Secret: ptr.String("s1"), Path: ptr.String("/s")},
+			},
+		},
+		Deploy: DeploySpec{
+			Namespace: "default-ns",
+			Image:     "example.com/alice/f@sha256:1",
+			Labels:    []Label{{Key: ptr.String("tier"), Value: ptr.String("dev")}},
+			Options:   Options{Scale: &ScaleOptions{Min: ptr.Int64(0)}},
+		},
+		Environments: map[string]Environment{
+			"prod": {
+				Namespace: "prod-ns",
+				Registry:  "example.com/prod",
+				Envs:      Envs{{Name: ptr.String("B"), Value: ptr.String("B")}, {Name: ptr.String("C"), Value: ptr.String("c")}},
+				Volumes:   []Volume{{Secret: ptr.String("s2"), Path: ptr.String("/s")}},
+				Labels:    []Label{{Key: ptr.String("tier"), Value: ptr.String("prod")}},
+				Options:   Options{Scale: &ScaleOptions{Min: ptr.Int64(2)}},
+				Deployed:  EnvironmentDeployment{Namespace: "prod-ns", Image: "example.com/prod/f@sha256:2"},
+			},
+		},
+	}
+
+	p, err := f.WithEnvironment("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Environment != "prod" {
+		t.Errorf("expected environment 'prod', got %q", p.Environment)
+	}
+	if p.Namespace != "prod-ns" || p.Registry != "example.com/prod" {
+		t.Errorf("unexpected namespace/registry %q %q", p.Namespace, p.Registry)
+	}
+	if p.Deploy.Namespace != "prod-ns" || p.Deploy.Image != "example.com/prod/f@sha256:2" {
+		t.Errorf("unexpected deploy state %q %q", p.Deploy.Namespace, p.Deploy.Image)
+	}
+	if len(p.Run.Envs) != 3 || *p.Run.Envs[1].Value != "B" || *p.Run.Envs[2].Name != "C" {
+		t.Errorf("unexpected envs %v", p.Run.Envs)
+	}
+	if len(p.Run.Volumes) != 1 || *p.Run.Volumes[0].Secret != "s2" {
+		t.Errorf("unexpected volumes %v", p.Run.Volumes)
+	}
+	if len(p.Deploy.Labels) != 1 || *p.Deploy.Labels[0].Value != "prod" {
+		t.Errorf("unexpected labels %v", p.Deploy.Labels)
+	}
+	if *p.Deploy.Options.Scale.Min != 2 {
+		t.Errorf("unexpected scale min %v", *p.Deploy.Options.Scale.Min)
+	}
+
+	// The original function should not have been modified
+	if *f.Run.Envs[1].Value != "b" || *f.Run.Volumes[0].Secret != "s1" {
+		t.Errorf("base function was mutated")
+	}
+
+	// Unknown environments are an ErrEnvironmentNotFound
+	if _, err := f.WithEnvironment("staging"); !errors.Is(err, ErrEnvironmentNotFound) {
+		t.Errorf("expected ErrEnvironmentNotFound, got %v", err)
+	}
+}
+
+// TestWithEnvironment_Write ensures that writing a function with an applied
+// environment records the deployment state on that environment, leaving the
+// function's own settings unchanged.
+func TestWithEnvironment_Write(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	f, err := New().Init(Function{Runtime: "go", Root: root, Registry: "example.com/alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Namespace = "default-ns"
+	f.Environments = map[string]Environment{
+		"staging": {Namespace: "staging-ns"},
+	}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = f.WithEnvironment("staging"); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a deployment
+	f.Deploy.Namespace = f.Namespace
+	f.Deploy.Image = "example.com/alice/f@sha256:1"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Namespace != "default-ns" || f.Deploy.Image != "" {
+		t.Errorf("function deploy state changed: %q %q", f.Deploy.Namespace, f.Deploy.Image)
+	}
+	e := f.Environments["staging"]
+	if e.Deployed.Namespace != "staging-ns" || e.Deployed.Image != "example.com/alice/f@sha256:1" {
+		t.Errorf("environment deploy state not recorded: %+v", e.Deployed)
+	}
+	if e.Registry != "" {
+		t.Errorf("unchanged registry should not be persisted to the environment, got %q", e.Registry)
+	}
+}
+
+// TestWithEnvironment_WriteChanges ensures that envs, volumes, options and
+// labels changed with an environment applied are persisted to that
+// environment, and that removing those of the function is an error.
+func TestWithEnvironment_WriteChanges(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	f, err := New().Init(Function{Runtime: "go", Root: root, Registry: "example.com/alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Run.Envs = Envs{{Name: ptr.String("A"), Value: ptr.String("a")}}
+	f.Environments = map[string]Environment{
+		"staging": {Envs: Envs{{Name: ptr.String("B"), Value: ptr.String("b")}}},
+	}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = f.WithEnvironment("staging"); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate --env B=B --env C=c and --label tier=staging
+	f.Run.Envs = Envs{
+		{Name: ptr.String("A"), Value: ptr.String("a")},
+		{Name: ptr.String("B"), Value: ptr.String("B")},
+		{Name: ptr.String("C"), Value: ptr.String("c")},
+	}
+	f.Deploy.Labels = []Label{{Key: ptr.String("tier"), Value: ptr.String("staging")}}
+	f.Deploy.Options.Scale = &ScaleOptions{Min: ptr.Int64(1)}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Run.Envs) != 1 || len(f.Deploy.Labels) != 0 || f.Deploy.Options.Scale != nil {
+		t.Errorf("function settings changed: %v %v %v", f.Run.Envs, f.Deploy.Labels, f.Deploy.Options)
+	}
+	e := f.Environments["staging"]
+	if len(e.Envs) != 2 || *e.Envs[0].Value != "B" || *e.Envs[1].Name != "C" {
+		t.Errorf("unexpected environment envs %v", e.Envs)
+	}
+	if len(e.Labels) != 1 || *e.Labels[0].Value != "staging" {
+		t.Errorf("unexpected environment labels %v", e.Labels)
+	}
+	if e.Options.Scale == nil || *e.Options.Scale.Min != 1 {
+		t.Errorf("unexpected environment options %+v", e.Options)
+	}
+
+	// Removing an env of the function can not be persisted to the environment
+	if f, err = f.WithEnvironment("staging"); err != nil {
+		t.Fatal(err)
+	}
+	f.Run.Envs = f.Run.Envs[1:]
+	if err = f.Write(); err == nil {
+		t.Fatal("expected an error removing an env with an environment applied")
+	}
+}
+
+// TestWithEnvironment_Build ensures that the image built with an environment
+// applied is recorded for that environment, and that the function's own last
+// built image is restored, when written and reloaded.
+func TestWithEnvironment_Build(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	client := New()
+	f, err := client.Init(Function{Runtime: "go", Root: root, Registry: "example.com/alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Environments = map[string]Environment{
+		"prod": {Registry: "example.com/prod"},
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	built := f.Build.Image
+
+	if f, err = f.WithEnvironment("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err = NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Build.Image != built {
+		t.Errorf("expected the function's built image %q, got %q", built, f.Build.Image)
+	}
+	if !f.Built() {
+		t.Error("expected the function to be built")
+	}
+	if f, err = f.WithEnvironment("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if f.Build.Image != "example.com/prod/"+f.Name+":latest" {
+		t.Errorf("unexpected built image of the environment %q", f.Build.Image)
+	}
+	if !f.Built() {
+		t.Error("expected the function to be built for the environment")
+	}
+}
+
+// Test_validateEnvironments ensures environment names and their settings
+// are validated.
+func Test_validateEnvironments(t *testing.T) {
+	tests := []struct {
+		name         string
+		environments map[string]Environment
+		errs         int
+	}{
+		{"no environments", nil, 0},
+		{"valid environment", map[string]Environment{"staging": {Namespace: "s"}}, 0},
+		{"reserved name", map[string]Environment{"local": {}}, 1},
+		{"invalid name", map[string]Environment{"Staging_1": {}}, 1},
+		{"invalid settings", map[string]Environment{"prod": {
+			Volumes: []Volume{{Path: ptr.String("/p")}},
+			Envs:    Envs{{Name: ptr.String("1INVALID"), Value: ptr.String("v")}},
+		}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateEnvironments("", tt.environments); len(errs) != tt.errs {
+				t.Errorf("validateEnvironments() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package functions
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"knative.dev/func/pkg/utils"
@@ -63,6 +67,10 @@ func (e Env) String() string {
 		if len(match) == 2 {
 			return fmt.Sprintf("Env \"%s\" with value set from local env variable \"%s\"", *e.Name, match[1])
 		}
+		match = regFile.FindStringSubmatch(*e.Value)
+		if len(match) == 2 {
+			return fmt.Sprintf("Env \"%s\" with value set from local file \"%s\"", *e.Name, match[1])
+		}
 
 		return fmt.Sprintf("Env \"%s\" with value \"%s\"", *e.Name, *e.Value)
 	}
@@ -99,7 +107,19 @@ func (e Env) KeyValuePair() string {
 //   - name: EXAMPLE4
 //     value: {{ configMap:configMapName:key }}   	# ENV from a key in configMap
 //   - value: {{ configMap:configMapName }}          	# all key-pair values from configMap are set as ENV
+//   - name: EXAMPLE5
+//     value: {{ file:path/to/file }}       			# ENV from the contents of a local file
+//
+// Files referenced must be readable, and are relative to the current working
+// directory.  See ValidateEnvsIn for resolving them relative to a function's
+// root.
 func ValidateEnvs(envs []Env) (errors []string) {
+	return ValidateEnvsIn("", envs)
+}
+
+// ValidateEnvsIn is ValidateEnvs, with files referenced resolved relative to
+// the given root directory.
+func ValidateEnvsIn(root string, envs []Env) (errors []string) {
 	for i, env := range envs {
 		if env.Name == nil && env.Value == nil {
 			errors = append(errors, fmt.Sprintf("env entry #%d is not properly set", i))
@@ -121,10 +141,16 @@ func ValidateEnvs(envs []Env) (errors []string) {
 				// ENV from the local ENV var; {{ env:MY_ENV }}
 				// or
 				// ENV from a key in secret/configMap;  {{ secret:secretName:key }} or {{ configMap:configMapName:key }}
-				if !regLocalEnv.MatchString(*env.Value) && !regKeyFromSecret.MatchString(*env.Value) && !regKeyFromConfigMap.MatchString(*env.Value) {
+				// or
+				// ENV from a local file; {{ file:path }}
+				if _, ok, err := EnvFileValue(root, *env.Value); ok {
+					if err != nil {
+						errors = append(errors, fmt.Sprintf("env entry #%d with name '%s' references a file which can not be read: %v", i, *env.Name, err))
+					}
+				} else if !regLocalEnv.MatchString(*env.Value) && !regKeyFromSecret.MatchString(*env.Value) && !regKeyFromConfigMap.MatchString(*env.Value) {
 					errors = append(errors,
 						fmt.Sprintf(
-							"env entry #%d with name '%s' has invalid value field set, it has '%s', but allowed is only '{{ env:MY_ENV }}', '{{ secret:secretName:key }}' or '{{ configMap:configMapName:key }}'",
+							"env entry #%d with name '%s' has invalid value field set, it has '%s', but allowed is only '{{ env:MY_ENV }}', '{{ file:path }}', '{{ secret:secretName:key }}' or '{{ configMap:configMapName:key }}'",
 							i, *env.Name, *env.Value))
 				}
 			}
@@ -142,7 +168,19 @@ func ValidateEnvs(envs []Env) (errors []string) {
 //     value: value1
 //   - name: EXAMPLE2                 				# ENV from the local ENV var
 //     value: {{ env:MY_ENV }}
+//   - name: EXAMPLE3                 				# ENV from the contents of a local file
+//     value: {{ file:path/to/file }}
+//
+// Files referenced must be readable, and are relative to the current working
+// directory.  See ValidateBuildEnvsIn for resolving them relative to a
+// function's root.
 func ValidateBuildEnvs(envs []Env) (errors []string) {
+	return ValidateBuildEnvsIn("", envs)
+}
+
+// ValidateBuildEnvsIn is ValidateBuildEnvs, with files referenced resolved
+// relative to the given root directory.
+func ValidateBuildEnvsIn(root string, envs []Env) (errors []string) {
 	for i, env := range envs {
 		if env.Name == nil && env.Value == nil {
 			errors = append(errors, fmt.Sprintf("env entry #%d is not properly set", i))
@@ -156,10 +194,16 @@ func ValidateBuildEnvs(envs []Env) (errors []string) {
 
 			if strings.HasPrefix(*env.Value, "{{") {
 				// ENV from the local ENV var; {{ env:MY_ENV }}
-				if !regLocalEnv.MatchString(*env.Value) {
+				// or
+				// ENV from a local file; {{ file:path }}
+				if _, ok, err := EnvFileValue(root, *env.Value); ok {
+					if err != nil {
+						errors = append(errors, fmt.Sprintf("env entry #%d with name '%s' references a file which can not be read: %v", i, *env.Name, err))
+					}
+				} else if !regLocalEnv.MatchString(*env.Value) {
 					errors = append(errors,
 						fmt.Sprintf(
-							"env entry #%d with name '%s' has invalid value field set, it has '%s', but allowed is only '{{ env:MY_ENV }}'",
+							"env entry #%d with name '%s' has invalid value field set, it has '%s', but allowed is only '{{ env:MY_ENV }}' or '{{ file:path }}'",
 							i, *env.Name, *env.Value))
 				}
 			}
@@ -168,3 +212,99 @@ func ValidateBuildEnvs(envs []Env) (errors []string) {
 
 	return
 }
+
+// ValidateEnvFiles checks that each of the given dotenv files, relative to
+// root, can be read and contains only valid variable declarations.
+// Returns array of error messages, empty if no errors are found
+func ValidateEnvFiles(root string, files []string) (errors []string) {
+	for i, file := range files {
+		if strings.TrimSpace(file) == "" {
+			errors = append(errors, fmt.Sprintf("envFiles entry #%d is empty", i))
+			continue
+		}
+		if _, err := LoadEnvFiles(root, []string{file}); err != nil {
+			errors = append(errors, fmt.Sprintf("envFiles entry #%d is not valid: %v", i, err))
+		}
+	}
+	return
+}
+
+// LoadEnvFiles returns the envs declared in the given dotenv files, relative
+// to root, in order.  Each non-empty line of a file which is not a comment
+// (beginning with #) is a declaration of the form NAME=VALUE, optionally
+// preceded by "export".  Values may be enclosed in single or double quotes,
+// and are otherwise used as-is.
+func LoadEnvFiles(root string, files []string) (envs []Env, err error) {
+	envs = []Env{}
+	for _, file := range files {
+		b, err := os.ReadFile(envFilePath(root, file))
+		if err != nil {
+			return envs, fmt.Errorf("unable to read env file %q. %w", file, err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+			name, value, ok := strings.Cut(line, "=")
+			name = strings.TrimSpace(name)
+			if !ok {
+				return envs, fmt.Errorf("env file %q line %d: expected NAME=VALUE", file, n)
+			}
+			if err := utils.ValidateEnvVarName(name); err != nil {
+				return envs, fmt.Errorf("env file %q line %d: invalid name %q; %w", file, n, name, err)
+			}
+			value = unquoteEnvValue(strings.TrimSpace(value))
+			envs = append(envs, Env{Name: &name, Value: &value})
+		}
+		if err := scanner.Err(); err != nil {
+			return envs, fmt.Errorf("unable to read env file %q. %w", file, err)
+		}
+	}
+	return envs, nil
+}
+
+// RunEnvs returns the envs declared in the function's env files followed by
+// those of Run.Envs, such that later declarations of the same name take
+// precedence when interpolated or deployed.
+func (f Function) RunEnvs() (Envs, error) {
+	envs, err := LoadEnvFiles(f.Root, f.Run.EnvFiles)
+	if err != nil {
+		return nil, err
+	}
+	return append(envs, f.Run.Envs...), nil
+}
+
+// unquoteEnvValue removes matching single or double quotes enclosing a value.
+func unquoteEnvValue(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// envFilePath returns the path of a file referenced by an env, which is
+// relative to root unless absolute.
+func envFilePath(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
+
+// EnvFileValue returns the contents of the file referenced by an env value of
+// the form {{ file:path }}, relative to root unless absolute.  The returned
+// bool indicates whether the value is such a reference.
+func EnvFileValue(root, value string) (string, bool, error) {
+	match := regFile.FindStringSubmatch(value)
+	if len(match) != 2 {
+		return "", false, nil
+	}
+	b, err := os.ReadFile(envFilePath(root, match[1]))
+	if err != nil {
+		return "", true, fmt.Errorf("unable to read file %q referenced by env. %w", match[1], err)
+	}
+	return string(b), true, nil
+}
@@ -5,6 +5,9 @@ package functions
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -737,3 +740,79 @@ func Test_validateEnvString(t *testing.T) {
 		})
 	}
 }
+
+// Test_validateEnvs_Files ensures that env values referencing files are
+// resolved relative to the root, and that missing files are reported.
+func Test_validateEnvs_Files(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "token"), []byte("s3cr3t"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	name := "TOKEN"
+	present := "{{ file:token }}"
+	missing := "{{ file:missing }}"
+
+	if errs := ValidateEnvsIn(root, []Env{{Name: &name, Value: &present}}); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if errs := ValidateBuildEnvsIn(root, []Env{{Name: &name, Value: &present}}); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if errs := ValidateEnvsIn(root, []Env{{Name: &name, Value: &missing}}); len(errs) != 1 {
+		t.Fatalf("expected an error for a missing file, got %v", errs)
+	}
+	if errs := ValidateBuildEnvsIn(root, []Env{{Name: &name, Value: &missing}}); len(errs) != 1 {
+		t.Fatalf("expected an error for a missing file, got %v", errs)
+	}
+
+	envs, err := InterpolateIn(root, []Env{{Name: &name, Value: &present}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envs[name] != "s3cr3t" {
+		t.Fatalf("expected the file's contents, got %q", envs[name])
+	}
+}
+
+// Test_LoadEnvFiles ensures dotenv files are parsed in order, and that the
+// function's explicit envs follow them.
+func Test_LoadEnvFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".env", "# defaults\nA=1\n\nexport B='two words'\nC=\"3\"\n")
+	write(".env.local", "A=override\n")
+	write(".env.invalid", "NOT A DECLARATION\n")
+
+	a := "A"
+	explicit := "explicit"
+	f := Function{Root: root, Run: RunSpec{
+		EnvFiles: []string{".env", ".env.local"},
+		Envs:     []Env{{Name: &a, Value: &explicit}},
+	}}
+	envs, err := f.RunEnvs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"A=1", "B=two words", "C=3", "A=override", "A=explicit"}; !reflect.DeepEqual(envs.Slice(), expected) {
+		t.Fatalf("expected %v, got %v", expected, envs.Slice())
+	}
+	interpolated, err := InterpolateIn(root, envs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interpolated["A"] != "explicit" {
+		t.Fatalf("expected explicit envs to take precedence, got %q", interpolated["A"])
+	}
+
+	if errs := ValidateEnvFiles(root, f.Run.EnvFiles); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if errs := ValidateEnvFiles(root, []string{".env.missing", ".env.invalid", ""}); len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %v", errs)
+	}
+}
@@ -0,0 +1,68 @@
+package functions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expose the function outside the cluster via an Ingress or, when a Gateway
+// is named, a Gateway API HTTPRoute.  Used by platforms such as "kubernetes"
+// which do not route to the function themselves.
+type Expose struct {
+	// Host at which the function is exposed, for example www.example.com.
+	Host string `yaml:"host,omitempty"`
+
+	// IngressClassName of the Ingress, defaulting to that of the cluster.
+	IngressClassName string `yaml:"ingressClassName,omitempty"`
+
+	// Gateway to which an HTTPRoute is attached instead of creating an
+	// Ingress, as [namespace/]name.
+	Gateway string `yaml:"gateway,omitempty"`
+
+	// TLSSecret containing the certificate of the Ingress's host.
+	TLSSecret string `yaml:"tlsSecret,omitempty"`
+}
+
+// IsZero returns true if the function is not exposed.
+func (e Expose) IsZero() bool {
+	return e == Expose{}
+}
+
+// GatewayRef returns the namespace (empty if that of the function) and name
+// of the Gateway.
+func (e Expose) GatewayRef() (namespace, name string) {
+	if ns, n, ok := strings.Cut(e.Gateway, "/"); ok {
+		return ns, n
+	}
+	return "", e.Gateway
+}
+
+// validateExpose checks that the host, gateway and secret names are valid,
+// and that the Ingress settings are not combined with a gateway.
+// Returns array of error messages, empty if no errors are found
+func validateExpose(e Expose) (errors []string) {
+	if e.Host != "" && (len(e.Host) > 253 || !resourceNameRegex.MatchString(e.Host)) {
+		errors = append(errors, fmt.Sprintf("expose host %q is not a valid hostname", e.Host))
+	}
+	if e.Gateway != "" {
+		ns, name := e.GatewayRef()
+		if (strings.Contains(e.Gateway, "/") && !resourceNameRegex.MatchString(ns)) || !resourceNameRegex.MatchString(name) {
+			errors = append(errors, fmt.Sprintf("expose gateway %q is invalid, expected [namespace/]name", e.Gateway))
+		}
+		if e.IngressClassName != "" || e.TLSSecret != "" {
+			errors = append(errors, "expose gateway may not be combined with ingressClassName or tlsSecret, which configure an Ingress")
+		}
+	}
+	for _, named := range []struct{ field, name string }{
+		{"ingressClassName", e.IngressClassName},
+		{"tlsSecret", e.TLSSecret},
+	} {
+		if named.name != "" && !resourceNameRegex.MatchString(named.name) {
+			errors = append(errors, fmt.Sprintf("expose %v %q is invalid, it must consist of lower case alphanumeric characters, '-' or '.'", named.field, named.name))
+		}
+	}
+	if e.TLSSecret != "" && e.Host == "" {
+		errors = append(errors, "expose tlsSecret requires a host")
+	}
+	return
+}
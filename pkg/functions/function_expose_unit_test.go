@@ -0,0 +1,31 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import "testing"
+
+// Test_validateExpose ensures invalid hosts and names, and Ingress settings
+// combined with a Gateway, are reported.
+func Test_validateExpose(t *testing.T) {
+	tests := []struct {
+		name   string
+		expose Expose
+		errs   int
+	}{
+		{"none", Expose{}, 0},
+		{"ingress", Expose{Host: "www.example.com", IngressClassName: "nginx", TLSSecret: "www-tls"}, 0},
+		{"gateway", Expose{Host: "www.example.com", Gateway: "infra/public"}, 0},
+		{"invalid host", Expose{Host: "www_example.com"}, 1},
+		{"invalid gateway", Expose{Gateway: "infra/Public"}, 1},
+		{"gateway with ingress settings", Expose{Host: "www.example.com", Gateway: "public", IngressClassName: "nginx"}, 1},
+		{"tls without host", Expose{TLSSecret: "www-tls"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateExpose(tt.expose); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
@@ -271,11 +271,11 @@ func migrateToSpecsStructure(f1 Function, m migration) (Function, error) {
 		f1.Deploy.Labels = append(f1.Deploy.Labels, f0.Labels...)
 	}
 
-	if f0.HealthEndpoints.Readiness != "" {
+	if !f0.HealthEndpoints.Readiness.IsZero() {
 		f1.Deploy.HealthEndpoints.Readiness = f0.HealthEndpoints.Readiness
 	}
 
-	if f0.HealthEndpoints.Liveness != "" {
+	if !f0.HealthEndpoints.Liveness.IsZero() {
 		f1.Deploy.HealthEndpoints.Liveness = f0.HealthEndpoints.Liveness
 	}
 
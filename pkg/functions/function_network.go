@@ -0,0 +1,92 @@
+package functions
+
+import (
+	"fmt"
+)
+
+const (
+	// VisibilityPublic functions are reachable from outside the cluster.
+	VisibilityPublic = "public"
+
+	// VisibilityClusterLocal functions are only reachable from within the
+	// cluster.
+	VisibilityClusterLocal = "cluster-local"
+)
+
+const (
+	// AllowFromNetworkPolicy enforces the allow list with a NetworkPolicy.
+	AllowFromNetworkPolicy = "networkpolicy"
+
+	// AllowFromIstio enforces the allow list with an Istio
+	// AuthorizationPolicy as well as a NetworkPolicy.
+	AllowFromIstio = "istio"
+)
+
+// AllowFrom is a source from which ingress to the function is allowed when
+// the function defines an allow list.  Exactly one of Namespace or Function
+// is defined, or both to allow a function of another namespace.
+type AllowFrom struct {
+	// Namespace from which all pods are allowed.  In combination with
+	// Function, the namespace of the allowed function.
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Function which is allowed, in the namespace of this function unless a
+	// Namespace is also defined.
+	Function string `yaml:"function,omitempty"`
+}
+
+// validateVisibility checks that the visibility is empty (public), public or
+// cluster-local, and that it is only set for the knative platform, which is
+// the only one to apply it.
+// Returns array of error messages, empty if no errors are found
+func validateVisibility(platform, visibility string) (errors []string) {
+	if visibility != "" && platform != "" && platform != "knative" {
+		errors = append(errors, fmt.Sprintf("visibility is supported by the knative platform only, not %q", platform))
+	}
+	switch visibility {
+	case "", VisibilityPublic, VisibilityClusterLocal:
+	default:
+		errors = append(errors, fmt.Sprintf("visibility %q is not valid, it must be %q or %q", visibility, VisibilityPublic, VisibilityClusterLocal))
+	}
+	return
+}
+
+// validateAllowFrom checks that each entry names a valid namespace, function,
+// or both, and that the allow list is only set for the knative platform,
+// which is the only one to enforce it.
+// Returns array of error messages, empty if no errors are found
+func validateAllowFrom(platform string, allowFrom []AllowFrom) (errors []string) {
+	if len(allowFrom) > 0 && platform != "" && platform != "knative" {
+		errors = append(errors, fmt.Sprintf("allowFrom is supported by the knative platform only, not %q", platform))
+	}
+	for i, a := range allowFrom {
+		if a.Namespace == "" && a.Function == "" {
+			errors = append(errors, fmt.Sprintf("allowFrom entry #%d must define a namespace, a function, or both", i))
+			continue
+		}
+		if a.Namespace != "" && !resourceNameRegex.MatchString(a.Namespace) {
+			errors = append(errors, fmt.Sprintf("allowFrom entry #%d has invalid namespace %q, it must consist of lower case alphanumeric characters, '-' or '.'", i, a.Namespace))
+		}
+		if a.Function != "" && !resourceNameRegex.MatchString(a.Function) {
+			errors = append(errors, fmt.Sprintf("allowFrom entry #%d has invalid function %q, it must consist of lower case alphanumeric characters, '-' or '.'", i, a.Function))
+		}
+	}
+	return
+}
+
+// validateAllowFromPolicy checks that the policy is empty (networkpolicy),
+// networkpolicy or istio, and that the ingress namespaces are valid names.
+// Returns array of error messages, empty if no errors are found
+func validateAllowFromPolicy(policy string, ingressNamespaces []string) (errors []string) {
+	switch policy {
+	case "", AllowFromNetworkPolicy, AllowFromIstio:
+	default:
+		errors = append(errors, fmt.Sprintf("allowFromPolicy %q is not valid, it must be %q or %q", policy, AllowFromNetworkPolicy, AllowFromIstio))
+	}
+	for _, ns := range ingressNamespaces {
+		if !resourceNameRegex.MatchString(ns) {
+			errors = append(errors, fmt.Sprintf("ingressNamespaces has invalid namespace %q, it must consist of lower case alphanumeric characters, '-' or '.'", ns))
+		}
+	}
+	return
+}
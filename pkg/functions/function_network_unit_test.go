@@ -0,0 +1,78 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import "testing"
+
+// Test_validateVisibility ensures only public and cluster-local are accepted,
+// and only for the knative platform.
+func Test_validateVisibility(t *testing.T) {
+	for visibility, errs := range map[string]int{
+		"":              0,
+		"public":        0,
+		"cluster-local": 0,
+		"private":       1,
+	} {
+		if got := validateVisibility("", visibility); len(got) != errs {
+			t.Errorf("visibility %q: expected %v errors, got %v", visibility, errs, got)
+		}
+	}
+	if got := validateVisibility("knative", "cluster-local"); len(got) != 0 {
+		t.Errorf("expected no errors for the knative platform, got %v", got)
+	}
+	if got := validateVisibility("kubernetes", "cluster-local"); len(got) != 1 {
+		t.Errorf("expected an error for the kubernetes platform, got %v", got)
+	}
+}
+
+// Test_validateAllowFrom ensures empty entries, invalid names and allow lists
+// of platforms other than Knative are reported.
+func Test_validateAllowFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		platform  string
+		allowFrom []AllowFrom
+		errs      int
+	}{
+		{"none", "", nil, 0},
+		{"valid", "", []AllowFrom{{Namespace: "frontend"}, {Function: "gateway"}, {Namespace: "other", Function: "client"}}, 0},
+		{"knative", "knative", []AllowFrom{{Namespace: "frontend"}}, 0},
+		{"kubernetes", "kubernetes", []AllowFrom{{Namespace: "frontend"}}, 1},
+		{"docker", "docker", []AllowFrom{{Namespace: "frontend"}}, 1},
+		{"empty entry", "", []AllowFrom{{}}, 1},
+		{"invalid namespace", "", []AllowFrom{{Namespace: "Front_End"}}, 1},
+		{"invalid function", "", []AllowFrom{{Function: "my_func"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateAllowFrom(tt.platform, tt.allowFrom); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
+
+// Test_validateAllowFromPolicy ensures unknown policies and invalid ingress
+// namespaces are reported.
+func Test_validateAllowFromPolicy(t *testing.T) {
+	tests := []struct {
+		name              string
+		policy            string
+		ingressNamespaces []string
+		errs              int
+	}{
+		{"default", "", nil, 0},
+		{"networkpolicy", "networkpolicy", nil, 0},
+		{"istio", "istio", []string{"knative-serving", "istio-system"}, 0},
+		{"unknown policy", "linkerd", nil, 1},
+		{"invalid namespace", "", []string{"Kourier_System"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateAllowFromPolicy(tt.policy, tt.ingressNamespaces); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package functions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Probe defines how the health of a function is checked by the platform on
+// which it is deployed.  The probe is one of an HTTP GET request (the
+// default, to Path), an Exec command run within the container, or a GRPC
+// health check.
+//
+// A probe which defines only an HTTP path may be written in shorthand as
+// just the path, for example:
+//
+//	healthEndpoints:
+//	  liveness: /health/liveness
+//	  readiness:
+//	    path: /health/readiness
+//	    periodSeconds: 5
+//	    failureThreshold: 6
+type Probe struct {
+	// Path of the HTTP endpoint to request.
+	Path string `yaml:"path,omitempty"`
+
+	// Port of the HTTP endpoint, defaulting to that on which the function
+	// listens.
+	Port int32 `yaml:"port,omitempty"`
+
+	// Headers to set on the HTTP request.
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	// Exec is a command, run within the container, which indicates the
+	// function is healthy by exiting with status zero.
+	Exec []string `yaml:"exec,omitempty"`
+
+	// GRPC health check of the function.
+	GRPC *GRPCProbe `yaml:"grpc,omitempty"`
+
+	// InitialDelaySeconds after the container starts before probing.
+	InitialDelaySeconds int32 `yaml:"initialDelaySeconds,omitempty"`
+
+	// PeriodSeconds between probes.
+	PeriodSeconds int32 `yaml:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds after which a probe is considered failed.
+	TimeoutSeconds int32 `yaml:"timeoutSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failed probes after which
+	// the function is considered unhealthy.
+	FailureThreshold int32 `yaml:"failureThreshold,omitempty"`
+}
+
+// GRPCProbe is a GRPC health check (grpc.health.v1.Health).
+type GRPCProbe struct {
+	// Port of the GRPC service.
+	Port int32 `yaml:"port"`
+
+	// Service name to check, defaulting to the server's overall health.
+	Service string `yaml:"service,omitempty"`
+}
+
+// IsZero returns true if no part of the probe is defined.
+func (p Probe) IsZero() bool {
+	return p.Path == "" && p.Port == 0 && len(p.Headers) == 0 && len(p.Exec) == 0 &&
+		p.GRPC == nil && p.InitialDelaySeconds == 0 && p.PeriodSeconds == 0 &&
+		p.TimeoutSeconds == 0 && p.FailureThreshold == 0
+}
+
+// pathOnly returns true if the probe defines only an HTTP path, and can
+// therefore be written in its shorthand form.
+func (p Probe) pathOnly() bool {
+	rest := p
+	rest.Path = ""
+	return p.Path != "" && rest.IsZero()
+}
+
+// probe is Probe without its custom (un)marshalling.
+type probe Probe
+
+// UnmarshalYAML accepts either the shorthand form (just a path) or the full
+// definition of a probe.
+func (p *Probe) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		*p = Probe{Path: path}
+		return nil
+	}
+	var full probe
+	if err := unmarshal(&full); err != nil {
+		return err
+	}
+	*p = Probe(full)
+	return nil
+}
+
+// MarshalYAML writes probes which define only a path in shorthand form.
+func (p Probe) MarshalYAML() (interface{}, error) {
+	if p.pathOnly() {
+		return p.Path, nil
+	}
+	return probe(p), nil
+}
+
+// String representation of the probe's handler, such as "GET :8080/health".
+func (p Probe) String() string {
+	switch {
+	case len(p.Exec) > 0:
+		return fmt.Sprintf("exec %v", strings.Join(p.Exec, " "))
+	case p.GRPC != nil:
+		s := fmt.Sprintf("grpc :%v", p.GRPC.Port)
+		if p.GRPC.Service != "" {
+			s += " " + p.GRPC.Service
+		}
+		return s
+	default:
+		s := "GET "
+		if p.Port != 0 {
+			s += fmt.Sprintf(":%v", p.Port)
+		}
+		if p.Path == "" {
+			s += "(default path)"
+		}
+		s += p.Path
+		names := make([]string, 0, len(p.Headers))
+		for k := range p.Headers {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			s += fmt.Sprintf(" %v=%v", k, p.Headers[k])
+		}
+		return s
+	}
+}
+
+// validateHealthEndpoints checks that each defined probe has at most one
+// handler, and that its settings are valid.  A probe with no handler uses
+// the default endpoint of its kind.
+// Returns array of error messages, empty if no errors are found
+func validateHealthEndpoints(h HealthEndpoints) (errors []string) {
+	for _, named := range []struct {
+		name  string
+		probe Probe
+	}{
+		{"liveness", h.Liveness},
+		{"readiness", h.Readiness},
+		{"startup", h.Startup},
+	} {
+		for _, err := range named.probe.validate() {
+			errors = append(errors, fmt.Sprintf("%v probe %v", named.name, err))
+		}
+	}
+	return
+}
+
+func (p Probe) validate() (errors []string) {
+	if p.IsZero() {
+		return
+	}
+	handlers := 0
+	if p.Path != "" || p.Port != 0 || len(p.Headers) > 0 {
+		handlers++
+		if p.Path != "" && !strings.HasPrefix(p.Path, "/") {
+			errors = append(errors, fmt.Sprintf("path %q must be an absolute path", p.Path))
+		}
+	}
+	if len(p.Exec) > 0 {
+		handlers++
+	}
+	if p.GRPC != nil {
+		handlers++
+		if p.GRPC.Port <= 0 || p.GRPC.Port > 65535 {
+			errors = append(errors, fmt.Sprintf("grpc port %v is not valid", p.GRPC.Port))
+		}
+	}
+	if handlers > 1 {
+		errors = append(errors, "may define only one of an HTTP path, exec or grpc")
+	}
+	if p.Port < 0 || p.Port > 65535 {
+		errors = append(errors, fmt.Sprintf("port %v is not valid", p.Port))
+	}
+	for name, value := range map[string]int32{
+		"initialDelaySeconds": p.InitialDelaySeconds,
+		"periodSeconds":       p.PeriodSeconds,
+		"timeoutSeconds":      p.TimeoutSeconds,
+		"failureThreshold":    p.FailureThreshold,
+	} {
+		if value < 0 {
+			errors = append(errors, fmt.Sprintf("%v may not be negative", name))
+		}
+	}
+	sort.Strings(errors)
+	return
+}
@@ -0,0 +1,89 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TestProbe_YAML ensures probes may be written in shorthand as their path,
+// and that probes defining only a path are written in shorthand.
+func TestProbe_YAML(t *testing.T) {
+	src := `liveness: /health/liveness
+readiness:
+  path: /health/readiness
+  headers:
+    X-Probe: "true"
+  periodSeconds: 5
+startup:
+  exec:
+  - /bin/check
+  failureThreshold: 30
+`
+	var h HealthEndpoints
+	if err := yaml.Unmarshal([]byte(src), &h); err != nil {
+		t.Fatal(err)
+	}
+	expected := HealthEndpoints{
+		Liveness:  Probe{Path: "/health/liveness"},
+		Readiness: Probe{Path: "/health/readiness", Headers: map[string]string{"X-Probe": "true"}, PeriodSeconds: 5},
+		Startup:   Probe{Exec: []string{"/bin/check"}, FailureThreshold: 30},
+	}
+	if !reflect.DeepEqual(h, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, h)
+	}
+
+	out, err := yaml.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(out), "liveness: /health/liveness\n") {
+		t.Fatalf("expected liveness in shorthand, got\n%s", out)
+	}
+	var roundtrip HealthEndpoints
+	if err = yaml.Unmarshal(out, &roundtrip); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roundtrip, expected) {
+		t.Fatalf("expected %+v after round trip, got %+v", expected, roundtrip)
+	}
+
+	// Undefined probes are omitted
+	if out, err = yaml.Marshal(HealthEndpoints{Liveness: Probe{Path: "/l"}}); err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "liveness: /l\n" {
+		t.Fatalf("unexpected yaml\n%s", out)
+	}
+}
+
+// Test_validateHealthEndpoints ensures probes define at most one handler
+// with valid settings.
+func Test_validateHealthEndpoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		health HealthEndpoints
+		errs   int
+	}{
+		{"none", HealthEndpoints{}, 0},
+		{"paths", HealthEndpoints{Liveness: Probe{Path: "/l"}, Readiness: Probe{Path: "/r"}}, 0},
+		{"timing only", HealthEndpoints{Startup: Probe{PeriodSeconds: 10, FailureThreshold: 30}}, 0},
+		{"grpc", HealthEndpoints{Liveness: Probe{GRPC: &GRPCProbe{Port: 9090}}}, 0},
+		{"relative path", HealthEndpoints{Liveness: Probe{Path: "health"}}, 1},
+		{"two handlers", HealthEndpoints{Readiness: Probe{Path: "/r", Exec: []string{"check"}}}, 1},
+		{"invalid grpc port", HealthEndpoints{Liveness: Probe{GRPC: &GRPCProbe{}}}, 1},
+		{"negative settings", HealthEndpoints{Startup: Probe{PeriodSeconds: -1, TimeoutSeconds: -1}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateHealthEndpoints(tt.health); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
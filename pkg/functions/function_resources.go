@@ -0,0 +1,151 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ResourcesDir is the directory within a function's root which contains the
+// source of the Secrets and ConfigMaps managed with the function.  Each is a
+// YAML file of key/value pairs, for example:
+//
+//	.funcresources/
+//	  configmaps/
+//	    settings.yaml
+//	  secrets/
+//	    credentials.yaml
+//
+// These are created or updated in the cluster upon deploy, such that
+// references to them by the function's envs and volumes (for example
+// '{{ secret:credentials:password }}') are satisfied.  The values of Secrets
+// are stored encrypted, such that the directory may be committed with the
+// function's sources, unlike RunDataDir.  The builders exclude it from the
+// function's image, and it is not among the sources uploaded for a remote
+// build.
+const ResourcesDir = ".funcresources"
+
+// ResourceKind is the kind of a managed resource, which is also the name of
+// the directory within ResourcesDir containing resources of that kind.
+type ResourceKind string
+
+const (
+	SecretResource    ResourceKind = "secrets"
+	ConfigMapResource ResourceKind = "configmaps"
+)
+
+// ErrResourceNotFound indicates the named managed resource does not exist.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// resourceNameRegex is that of a Kubernetes object name (DNS-1123 subdomain).
+var resourceNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// resourceKeyRegex is that of a valid Secret or ConfigMap data key.
+var resourceKeyRegex = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// Resource is a Secret or ConfigMap managed with a function.
+type Resource struct {
+	Name string
+	Data map[string]string
+}
+
+// Keys of the resource's data, sorted.
+func (r Resource) Keys() []string {
+	keys := make([]string, 0, len(r.Data))
+	for k := range r.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate the resource's name and keys.
+func (r Resource) Validate() error {
+	if len(r.Name) > 253 || !resourceNameRegex.MatchString(r.Name) {
+		return fmt.Errorf("invalid resource name %q: must consist of lower case alphanumeric characters, '-' or '.'", r.Name)
+	}
+	for k := range r.Data {
+		if !resourceKeyRegex.MatchString(k) {
+			return fmt.Errorf("invalid key %q of resource %q: must consist of alphanumeric characters, '-', '_' or '.'", k, r.Name)
+		}
+	}
+	return nil
+}
+
+// resourcePath returns the path of the named resource's file.
+func (f Function) resourcePath(kind ResourceKind, name string) string {
+	return filepath.Join(f.Root, ResourcesDir, string(kind), name+".yaml")
+}
+
+// Resources of the given kind managed with the function, sorted by name.
+func (f Function) Resources(kind ResourceKind) (rr []Resource, err error) {
+	rr = []Resource{}
+	entries, err := os.ReadDir(filepath.Join(f.Root, ResourcesDir, string(kind)))
+	if errors.Is(err, os.ErrNotExist) {
+		return rr, nil
+	} else if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
+			continue
+		}
+		r, err := f.Resource(kind, strings.TrimSuffix(e.Name(), ".yaml"))
+		if err != nil {
+			return rr, err
+		}
+		rr = append(rr, r)
+	}
+	return
+}
+
+// Resource of the given kind and name managed with the function.
+// ErrResourceNotFound is returned if it does not exist.
+func (f Function) Resource(kind ResourceKind, name string) (r Resource, err error) {
+	r = Resource{Name: name, Data: map[string]string{}}
+	b, err := os.ReadFile(f.resourcePath(kind, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return r, fmt.Errorf("%w: %v %q", ErrResourceNotFound, kind, name)
+	} else if err != nil {
+		return
+	}
+	if err = yaml.Unmarshal(b, &r.Data); err != nil {
+		return r, fmt.Errorf("unable to read %v %q. %w", kind, name, err)
+	}
+	if r.Data == nil {
+		r.Data = map[string]string{}
+	}
+	return
+}
+
+// WriteResource of the given kind, replacing any existing of the same name.
+func (f Function) WriteResource(kind ResourceKind, r Resource) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	path := f.resourcePath(kind, r.Name)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// RemoveResource of the given kind and name.
+// ErrResourceNotFound is returned if it does not exist.
+func (f Function) RemoveResource(kind ResourceKind, name string) error {
+	err := os.Remove(f.resourcePath(kind, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %v %q", ErrResourceNotFound, kind, name)
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestFunction_Resources ensures that managed resources can be written, read,
+// listed and removed, and that each kind is distinct.
+func TestFunction_Resources(t *testing.T) {
+	f := Function{Root: t.TempDir()}
+
+	rr, err := f.Resources(SecretResource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rr) != 0 {
+		t.Fatalf("expected no resources, got %v", rr)
+	}
+
+	if err = f.WriteResource(SecretResource, Resource{Name: "creds", Data: map[string]string{"password": "ENC[x]", "user": "ENC[y]"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.WriteResource(ConfigMapResource, Resource{Name: "settings", Data: map[string]string{"level": "debug"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := f.Resource(SecretResource, "creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Keys(), []string{"password", "user"}) {
+		t.Fatalf("unexpected keys %v", r.Keys())
+	}
+	if _, err = f.Resource(SecretResource, "settings"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expected ErrResourceNotFound, got %v", err)
+	}
+
+	if rr, err = f.Resources(ConfigMapResource); err != nil {
+		t.Fatal(err)
+	}
+	if len(rr) != 1 || rr[0].Name != "settings" || rr[0].Data["level"] != "debug" {
+		t.Fatalf("unexpected config maps %v", rr)
+	}
+
+	if err = f.RemoveResource(SecretResource, "creds"); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.RemoveResource(SecretResource, "creds"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expected ErrResourceNotFound, got %v", err)
+	}
+}
+
+// TestResource_Validate ensures that resource names and keys must be valid
+// for the cluster.
+func TestResource_Validate(t *testing.T) {
+	tests := []struct {
+		Resource Resource
+		Valid    bool
+	}{
+		{Resource{Name: "creds", Data: map[string]string{"API_KEY": ""}}, true},
+		{Resource{Name: "my.creds-1", Data: map[string]string{"config.json": ""}}, true},
+		{Resource{Name: "Creds"}, false},
+		{Resource{Name: "creds/other"}, false},
+		{Resource{Name: "creds", Data: map[string]string{"a key": ""}}, false},
+	}
+	for _, test := range tests {
+		if err := test.Resource.Validate(); (err == nil) != test.Valid {
+			t.Errorf("resource %+v: expected valid=%v, got %v", test.Resource, test.Valid, err)
+		}
+	}
+}
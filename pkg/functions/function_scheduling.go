@@ -0,0 +1,279 @@
+package functions
+
+import (
+	"fmt"
+	"reflect"
+
+	"knative.dev/func/pkg/utils"
+)
+
+// Scheduling constrains the nodes on which the function's instances are
+// scheduled.  Its members mirror those of the Kubernetes pod spec of the same
+// names, such that definitions may be copied from Kubernetes manifests, for
+// example:
+//
+//	scheduling:
+//	  nodeSelector:
+//	    node-pool: spot
+//	  tolerations:
+//	  - key: spot
+//	    operator: Exists
+//	    effect: NoSchedule
+//	  runtimeClassName: gvisor
+type Scheduling struct {
+	// NodeSelector labels which a node must have.
+	NodeSelector map[string]string `yaml:"nodeSelector,omitempty"`
+
+	// Tolerations of node taints.
+	Tolerations []Toleration `yaml:"tolerations,omitempty"`
+
+	// Affinity of the function's instances to nodes and other pods.
+	Affinity *Affinity `yaml:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describe how instances are spread across
+	// topology domains such as zones.
+	TopologySpreadConstraints []TopologySpreadConstraint `yaml:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName of the function's pods.
+	PriorityClassName string `yaml:"priorityClassName,omitempty"`
+
+	// RuntimeClassName of the function's pods.
+	RuntimeClassName string `yaml:"runtimeClassName,omitempty"`
+}
+
+// Toleration of a node taint.
+type Toleration struct {
+	Key               string `yaml:"key,omitempty"`
+	Operator          string `yaml:"operator,omitempty" jsonschema:"enum=Exists,enum=Equal"`
+	Value             string `yaml:"value,omitempty"`
+	Effect            string `yaml:"effect,omitempty" jsonschema:"enum=NoSchedule,enum=PreferNoSchedule,enum=NoExecute"`
+	TolerationSeconds *int64 `yaml:"tolerationSeconds,omitempty"`
+}
+
+// Affinity of the function's instances to nodes and other pods.
+type Affinity struct {
+	NodeAffinity    *NodeAffinity `yaml:"nodeAffinity,omitempty"`
+	PodAffinity     *PodAffinity  `yaml:"podAffinity,omitempty"`
+	PodAntiAffinity *PodAffinity  `yaml:"podAntiAffinity,omitempty"`
+}
+
+// NodeAffinity schedules instances on nodes matching its terms.
+type NodeAffinity struct {
+	Required  *NodeSelector             `yaml:"requiredDuringSchedulingIgnoredDuringExecution,omitempty"`
+	Preferred []PreferredSchedulingTerm `yaml:"preferredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+// NodeSelector matches nodes matching any of its terms.
+type NodeSelector struct {
+	NodeSelectorTerms []NodeSelectorTerm `yaml:"nodeSelectorTerms"`
+}
+
+// NodeSelectorTerm matches nodes matching all of its expressions.
+type NodeSelectorTerm struct {
+	MatchExpressions []SelectorRequirement `yaml:"matchExpressions,omitempty"`
+}
+
+// PreferredSchedulingTerm is a weighted (1-100) preference for nodes.
+type PreferredSchedulingTerm struct {
+	Weight     int32            `yaml:"weight"`
+	Preference NodeSelectorTerm `yaml:"preference"`
+}
+
+// PodAffinity schedules instances in the same (or, as anti-affinity, a
+// different) topology domain as pods matching its terms.
+type PodAffinity struct {
+	Required  []PodAffinityTerm         `yaml:"requiredDuringSchedulingIgnoredDuringExecution,omitempty"`
+	Preferred []WeightedPodAffinityTerm `yaml:"preferredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+// PodAffinityTerm matches pods by label in the given namespaces (defaulting
+// to that of the function) within the topology domain named by TopologyKey.
+type PodAffinityTerm struct {
+	LabelSelector *LabelSelector `yaml:"labelSelector,omitempty"`
+	Namespaces    []string       `yaml:"namespaces,omitempty"`
+	TopologyKey   string         `yaml:"topologyKey"`
+}
+
+// WeightedPodAffinityTerm is a weighted (1-100) preference for a pod
+// affinity term.
+type WeightedPodAffinityTerm struct {
+	Weight          int32           `yaml:"weight"`
+	PodAffinityTerm PodAffinityTerm `yaml:"podAffinityTerm"`
+}
+
+// TopologySpreadConstraint limits the skew of instances across the topology
+// domains named by TopologyKey.
+type TopologySpreadConstraint struct {
+	MaxSkew           int32          `yaml:"maxSkew"`
+	TopologyKey       string         `yaml:"topologyKey"`
+	WhenUnsatisfiable string         `yaml:"whenUnsatisfiable" jsonschema:"enum=DoNotSchedule,enum=ScheduleAnyway"`
+	LabelSelector     *LabelSelector `yaml:"labelSelector,omitempty"`
+	MinDomains        *int32         `yaml:"minDomains,omitempty"`
+}
+
+// LabelSelector matches labels which match all of MatchLabels and
+// MatchExpressions.
+type LabelSelector struct {
+	MatchLabels      map[string]string     `yaml:"matchLabels,omitempty"`
+	MatchExpressions []SelectorRequirement `yaml:"matchExpressions,omitempty"`
+}
+
+// SelectorRequirement matches a label (or node field) whose value relates to
+// Values by the Operator.
+type SelectorRequirement struct {
+	Key      string   `yaml:"key"`
+	Operator string   `yaml:"operator" jsonschema:"enum=In,enum=NotIn,enum=Exists,enum=DoesNotExist,enum=Gt,enum=Lt"`
+	Values   []string `yaml:"values,omitempty"`
+}
+
+// validateScheduling checks that the scheduling constraints are valid, and
+// that there are none for the docker platform, which has no nodes.
+// Returns array of error messages, empty if no errors are found
+func validateScheduling(platform string, s Scheduling) (errors []string) {
+	if platform == "docker" && !reflect.DeepEqual(s, Scheduling{}) {
+		errors = append(errors, "scheduling is not supported by the docker platform")
+	}
+	for k := range s.NodeSelector {
+		if err := utils.ValidateLabelKey(k); err != nil {
+			errors = append(errors, fmt.Sprintf("scheduling nodeSelector has an invalid key %q: %v", k, err))
+		}
+	}
+	for i, t := range s.Tolerations {
+		switch t.Operator {
+		case "", "Equal":
+			if t.Key == "" {
+				errors = append(errors, fmt.Sprintf("scheduling toleration #%d requires a key unless its operator is Exists", i))
+			}
+		case "Exists":
+			if t.Value != "" {
+				errors = append(errors, fmt.Sprintf("scheduling toleration #%d may not have a value when its operator is Exists", i))
+			}
+		default:
+			errors = append(errors, fmt.Sprintf("scheduling toleration #%d has an invalid operator %q, expected Exists or Equal", i, t.Operator))
+		}
+		switch t.Effect {
+		case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+		default:
+			errors = append(errors, fmt.Sprintf("scheduling toleration #%d has an invalid effect %q, expected NoSchedule, PreferNoSchedule or NoExecute", i, t.Effect))
+		}
+		if t.TolerationSeconds != nil && t.Effect != "NoExecute" {
+			errors = append(errors, fmt.Sprintf("scheduling toleration #%d may only set tolerationSeconds when its effect is NoExecute", i))
+		}
+	}
+	if a := s.Affinity; a != nil {
+		if n := a.NodeAffinity; n != nil {
+			if n.Required != nil {
+				if len(n.Required.NodeSelectorTerms) == 0 {
+					errors = append(errors, "scheduling node affinity requires at least one node selector term")
+				}
+				for i, term := range n.Required.NodeSelectorTerms {
+					errors = append(errors, validateRequirements(fmt.Sprintf("node affinity term #%d", i), term.MatchExpressions, true)...)
+				}
+			}
+			for i, p := range n.Preferred {
+				errors = append(errors, validateWeight(fmt.Sprintf("preferred node affinity term #%d", i), p.Weight)...)
+				errors = append(errors, validateRequirements(fmt.Sprintf("preferred node affinity term #%d", i), p.Preference.MatchExpressions, true)...)
+			}
+		}
+		for _, named := range []struct {
+			kind string
+			p    *PodAffinity
+		}{{"pod affinity", a.PodAffinity}, {"pod anti-affinity", a.PodAntiAffinity}} {
+			kind, p := named.kind, named.p
+			if p == nil {
+				continue
+			}
+			for i, term := range p.Required {
+				errors = append(errors, validatePodAffinityTerm(fmt.Sprintf("%v term #%d", kind, i), term)...)
+			}
+			for i, w := range p.Preferred {
+				id := fmt.Sprintf("preferred %v term #%d", kind, i)
+				errors = append(errors, validateWeight(id, w.Weight)...)
+				errors = append(errors, validatePodAffinityTerm(id, w.PodAffinityTerm)...)
+			}
+		}
+	}
+	for i, c := range s.TopologySpreadConstraints {
+		id := fmt.Sprintf("topology spread constraint #%d", i)
+		if c.MaxSkew < 1 {
+			errors = append(errors, fmt.Sprintf("scheduling %v requires a maxSkew of at least 1", id))
+		}
+		if c.TopologyKey == "" {
+			errors = append(errors, fmt.Sprintf("scheduling %v requires a topologyKey", id))
+		}
+		if c.WhenUnsatisfiable != "DoNotSchedule" && c.WhenUnsatisfiable != "ScheduleAnyway" {
+			errors = append(errors, fmt.Sprintf("scheduling %v has an invalid whenUnsatisfiable %q, expected DoNotSchedule or ScheduleAnyway", id, c.WhenUnsatisfiable))
+		}
+		if c.MinDomains != nil && (*c.MinDomains < 1 || c.WhenUnsatisfiable != "DoNotSchedule") {
+			errors = append(errors, fmt.Sprintf("scheduling %v may only set a minDomains of at least 1 when whenUnsatisfiable is DoNotSchedule", id))
+		}
+		errors = append(errors, validateLabelSelector(id, c.LabelSelector)...)
+	}
+	for _, class := range []struct{ field, name string }{
+		{"priorityClassName", s.PriorityClassName},
+		{"runtimeClassName", s.RuntimeClassName},
+	} {
+		if class.name != "" && (len(class.name) > 253 || !resourceNameRegex.MatchString(class.name)) {
+			errors = append(errors, fmt.Sprintf("scheduling %v %q is invalid, it must consist of lower case alphanumeric characters, '-' or '.'", class.field, class.name))
+		}
+	}
+	return
+}
+
+func validateWeight(id string, weight int32) []string {
+	if weight < 1 || weight > 100 {
+		return []string{fmt.Sprintf("scheduling %v has an invalid weight %d, expected 1-100", id, weight)}
+	}
+	return nil
+}
+
+func validatePodAffinityTerm(id string, term PodAffinityTerm) (errors []string) {
+	if term.TopologyKey == "" {
+		errors = append(errors, fmt.Sprintf("scheduling %v requires a topologyKey", id))
+	}
+	return append(errors, validateLabelSelector(id, term.LabelSelector)...)
+}
+
+func validateLabelSelector(id string, s *LabelSelector) (errors []string) {
+	if s == nil {
+		return
+	}
+	for k, v := range s.MatchLabels {
+		if err := utils.ValidateLabelKey(k); err != nil {
+			errors = append(errors, fmt.Sprintf("scheduling %v has an invalid label key %q: %v", id, k, err))
+		}
+		if err := utils.ValidateLabelValue(v); err != nil {
+			errors = append(errors, fmt.Sprintf("scheduling %v has an invalid label value %q: %v", id, v, err))
+		}
+	}
+	return append(errors, validateRequirements(id, s.MatchExpressions, false)...)
+}
+
+// validateRequirements of a node (which permits the Gt and Lt operators) or
+// label selector.
+func validateRequirements(id string, rr []SelectorRequirement, node bool) (errors []string) {
+	for i, r := range rr {
+		if r.Key == "" {
+			errors = append(errors, fmt.Sprintf("scheduling %v expression #%d requires a key", id, i))
+		}
+		switch r.Operator {
+		case "In", "NotIn":
+			if len(r.Values) == 0 {
+				errors = append(errors, fmt.Sprintf("scheduling %v expression #%d requires values for operator %v", id, i, r.Operator))
+			}
+		case "Exists", "DoesNotExist":
+			if len(r.Values) > 0 {
+				errors = append(errors, fmt.Sprintf("scheduling %v expression #%d may not have values for operator %v", id, i, r.Operator))
+			}
+		case "Gt", "Lt":
+			if !node {
+				errors = append(errors, fmt.Sprintf("scheduling %v expression #%d has an invalid operator %q, expected In, NotIn, Exists or DoesNotExist", id, i, r.Operator))
+			} else if len(r.Values) != 1 {
+				errors = append(errors, fmt.Sprintf("scheduling %v expression #%d requires a single value for operator %v", id, i, r.Operator))
+			}
+		default:
+			errors = append(errors, fmt.Sprintf("scheduling %v expression #%d has an invalid operator %q", id, i, r.Operator))
+		}
+	}
+	return
+}
@@ -0,0 +1,78 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import "testing"
+
+// Test_validateScheduling ensures invalid scheduling constraints are reported.
+func Test_validateScheduling(t *testing.T) {
+	seconds, domains := int64(30), int32(2)
+	tests := []struct {
+		name       string
+		scheduling Scheduling
+		errs       int
+	}{
+		{"none", Scheduling{}, 0},
+		{"valid", Scheduling{
+			NodeSelector: map[string]string{"node-pool": "spot"},
+			Tolerations: []Toleration{
+				{Key: "spot", Operator: "Exists", Effect: "NoSchedule"},
+				{Key: "maintenance", Value: "true", Effect: "NoExecute", TolerationSeconds: &seconds},
+			},
+			Affinity: &Affinity{
+				NodeAffinity: &NodeAffinity{
+					Required: &NodeSelector{NodeSelectorTerms: []NodeSelectorTerm{{
+						MatchExpressions: []SelectorRequirement{{Key: "cpu-count", Operator: "Gt", Values: []string{"4"}}},
+					}}},
+				},
+				PodAntiAffinity: &PodAffinity{
+					Preferred: []WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: PodAffinityTerm{
+						LabelSelector: &LabelSelector{MatchLabels: map[string]string{"app": "testing"}},
+						TopologyKey:   "kubernetes.io/hostname",
+					}}},
+				},
+			},
+			TopologySpreadConstraints: []TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: "DoNotSchedule", MinDomains: &domains}},
+			PriorityClassName:         "high-priority",
+			RuntimeClassName:          "gvisor",
+		}, 0},
+		{"invalid node selector key", Scheduling{NodeSelector: map[string]string{"-pool": "spot"}}, 1},
+		{"toleration without key", Scheduling{Tolerations: []Toleration{{Value: "true"}}}, 1},
+		{"toleration with invalid operator and effect", Scheduling{Tolerations: []Toleration{{Key: "spot", Operator: "Is", Effect: "Never"}}}, 2},
+		{"toleration seconds without NoExecute", Scheduling{Tolerations: []Toleration{{Key: "spot", TolerationSeconds: &seconds}}}, 1},
+		{"node affinity without terms", Scheduling{Affinity: &Affinity{NodeAffinity: &NodeAffinity{Required: &NodeSelector{}}}}, 1},
+		{"preferred node affinity weight", Scheduling{Affinity: &Affinity{NodeAffinity: &NodeAffinity{
+			Preferred: []PreferredSchedulingTerm{{Weight: 0, Preference: NodeSelectorTerm{
+				MatchExpressions: []SelectorRequirement{{Key: "zone", Operator: "In", Values: []string{"a"}}},
+			}}},
+		}}}, 1},
+		{"pod affinity without topology key", Scheduling{Affinity: &Affinity{PodAffinity: &PodAffinity{
+			Required: []PodAffinityTerm{{LabelSelector: &LabelSelector{MatchExpressions: []SelectorRequirement{{Key: "app", Operator: "Gt", Values: []string{"1"}}}}}},
+		}}}, 2},
+		{"invalid topology spread constraint", Scheduling{TopologySpreadConstraints: []TopologySpreadConstraint{{WhenUnsatisfiable: "ScheduleAnyway", MinDomains: &domains}}}, 3},
+		{"invalid class names", Scheduling{PriorityClassName: "High", RuntimeClassName: "g_visor"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateScheduling("", tt.scheduling); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
+
+// Test_validateScheduling_Docker ensures scheduling constraints are rejected
+// for the docker platform only.
+func Test_validateScheduling_Docker(t *testing.T) {
+	s := Scheduling{NodeSelector: map[string]string{"node-pool": "spot"}}
+	if errs := validateScheduling("docker", s); len(errs) != 1 {
+		t.Fatalf("expected an error for the docker platform, got %v", errs)
+	}
+	if errs := validateScheduling("kubernetes", s); len(errs) != 0 {
+		t.Fatalf("expected no errors for the kubernetes platform, got %v", errs)
+	}
+	if errs := validateScheduling("docker", Scheduling{}); len(errs) != 0 {
+		t.Fatalf("expected no errors without constraints, got %v", errs)
+	}
+}
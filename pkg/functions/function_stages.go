@@ -0,0 +1,134 @@
+package functions
+
+import (
+	"fmt"
+)
+
+const (
+	// StageBeforeBuild is the default position of a stage, which runs on
+	// the function's sources before they are built.
+	StageBeforeBuild = "build"
+	// StageBeforeDeploy is the position of a stage which runs once the
+	// function's image is built, before it is deployed.
+	StageBeforeDeploy = "deploy"
+)
+
+// Stage is an additional task of the pipeline which builds and deploys the
+// function on cluster, such as running its unit tests, a linter or a
+// vulnerability scan of the built image.  The pipeline fails, and the
+// function is not deployed, if a stage fails.
+type Stage struct {
+	// Name of the stage, unique within the function.
+	Name string `yaml:"name"`
+
+	// Before is the task of the pipeline which the stage precedes: "build"
+	// (the default), or "deploy" for stages which use the built image.
+	Before string `yaml:"before,omitempty" jsonschema:"enum=build,enum=deploy"`
+
+	// Image in which the script runs.
+	Image string `yaml:"image"`
+
+	// Script run in the function's source directory.  Stages which run
+	// before deploy are given the built image, with its digest, in the
+	// environment variable FUNC_IMAGE.
+	Script string `yaml:"script"`
+
+	// Envs of the stage, which may be set in the same ways as those of the
+	// function itself, other than from local environment variables or files:
+	// the pipeline, which may be committed with the function, would contain
+	// their values.  Secrets are referenced instead.
+	Envs Envs `yaml:"envs,omitempty"`
+
+	// RunAfter are the names of other stages after which the stage runs.
+	// Stages otherwise run in parallel.
+	RunAfter []string `yaml:"runAfter,omitempty"`
+}
+
+// BeforeDeploy returns whether the stage runs after the build, before the
+// function is deployed.
+func (s Stage) BeforeDeploy() bool {
+	return s.Before == StageBeforeDeploy
+}
+
+// reservedStageNames are those of the tasks of the pipelines.
+var reservedStageNames = map[string]bool{
+	"fetch-sources": true,
+	"scaffold":      true,
+	"build":         true,
+	"deploy":        true,
+}
+
+// validateStages checks that the function's stages have unique, valid names,
+// an image and a script, valid envs, and run after other stages which precede
+// the same or an earlier task, without cycles.
+// Returns array of error messages, empty if no errors are found
+func validateStages(root string, stages []Stage) (errors []string) {
+	byName := make(map[string]Stage, len(stages))
+	for _, s := range stages {
+		byName[s.Name] = s
+	}
+
+	names := map[string]bool{}
+	for i, s := range stages {
+		id := fmt.Sprintf("stage #%d (%v)", i, s.Name)
+		switch {
+		case s.Name == "":
+			errors = append(errors, fmt.Sprintf("%v is missing a name", id))
+		case len(s.Name) > 63 || !regContainerName.MatchString(s.Name):
+			errors = append(errors, fmt.Sprintf("%v has an invalid name, it must consist of lower case alphanumeric characters or '-'", id))
+		case reservedStageNames[s.Name]:
+			errors = append(errors, fmt.Sprintf("%v has a reserved name", id))
+		case names[s.Name]:
+			errors = append(errors, fmt.Sprintf("%v has the same name as another stage", id))
+		}
+		names[s.Name] = true
+
+		if s.Before != "" && s.Before != StageBeforeBuild && s.Before != StageBeforeDeploy {
+			errors = append(errors, fmt.Sprintf("%v has an invalid 'before' %q, it must be %q or %q", id, s.Before, StageBeforeBuild, StageBeforeDeploy))
+		}
+		if s.Image == "" {
+			errors = append(errors, fmt.Sprintf("%v is missing an image", id))
+		}
+		if s.Script == "" {
+			errors = append(errors, fmt.Sprintf("%v is missing a script", id))
+		}
+		for _, after := range s.RunAfter {
+			other, ok := byName[after]
+			switch {
+			case !ok:
+				errors = append(errors, fmt.Sprintf("%v runs after %q, which is not a stage of the function", id, after))
+			case after == s.Name:
+				errors = append(errors, fmt.Sprintf("%v runs after itself", id))
+			case other.BeforeDeploy() && !s.BeforeDeploy():
+				errors = append(errors, fmt.Sprintf("%v runs before build, so cannot run after %q, which runs before deploy", id, after))
+			}
+		}
+		if stageCycles(s.Name, byName, map[string]bool{}) {
+			errors = append(errors, fmt.Sprintf("%v runs after a stage which runs after it", id))
+		}
+		for _, err := range ValidateEnvsIn(root, s.Envs) {
+			errors = append(errors, fmt.Sprintf("%v: %v", id, err))
+		}
+		for j, env := range s.Envs {
+			if env.Value != nil && (regLocalEnv.MatchString(*env.Value) || regFile.MatchString(*env.Value)) {
+				errors = append(errors, fmt.Sprintf("%v: env entry #%d may not be set from a local environment variable or file, reference a secret or configMap instead", id, j))
+			}
+		}
+	}
+	return
+}
+
+// stageCycles returns whether the stage transitively runs after itself.
+func stageCycles(name string, byName map[string]Stage, visiting map[string]bool) bool {
+	if visiting[name] {
+		return true
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+	for _, after := range byName[name].RunAfter {
+		if after != name && stageCycles(after, byName, visiting) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+//go:build !integration
+// +build !integration
+
+package functions
+
+import "testing"
+
+// Test_validateStages ensures stages with invalid names, missing images or
+// scripts, invalid dependencies or envs set from local values are reported.
+func Test_validateStages(t *testing.T) {
+	var (
+		name     = "TOKEN"
+		localEnv = "{{ env:TOKEN }}"
+		secret   = "{{ secret:creds:token }}"
+	)
+	tests := []struct {
+		name   string
+		stages []Stage
+		errs   int
+	}{
+		{"none", nil, 0},
+		{"valid", []Stage{
+			{Name: "lint", Image: "golangci/golangci-lint", Script: "golangci-lint run"},
+			{Name: "test", Image: "golang", Script: "go test ./...", RunAfter: []string{"lint"}},
+			{Name: "scan", Before: StageBeforeDeploy, Image: "aquasec/trivy", Script: "trivy image $FUNC_IMAGE", RunAfter: []string{"test"}},
+		}, 0},
+		{"missing name", []Stage{{Image: "golang", Script: "go test"}}, 1},
+		{"invalid name", []Stage{{Name: "Test", Image: "golang", Script: "go test"}}, 1},
+		{"reserved name", []Stage{{Name: "build", Image: "golang", Script: "go test"}}, 1},
+		{"duplicate name", []Stage{{Name: "test", Image: "golang", Script: "go test"}, {Name: "test", Image: "golang", Script: "go vet"}}, 1},
+		{"invalid before", []Stage{{Name: "test", Before: "run", Image: "golang", Script: "go test"}}, 1},
+		{"missing image", []Stage{{Name: "test", Script: "go test"}}, 1},
+		{"missing script", []Stage{{Name: "test", Image: "golang"}}, 1},
+		{"unknown run after", []Stage{{Name: "test", Image: "golang", Script: "go test", RunAfter: []string{"lint"}}}, 1},
+		{"run after later stage", []Stage{
+			{Name: "test", Image: "golang", Script: "go test", RunAfter: []string{"scan"}},
+			{Name: "scan", Before: StageBeforeDeploy, Image: "aquasec/trivy", Script: "trivy image $FUNC_IMAGE"},
+		}, 1},
+		{"env from secret", []Stage{{Name: "test", Image: "golang", Script: "go test", Envs: Envs{{Name: &name, Value: &secret}}}}, 0},
+		{"env from local env", []Stage{{Name: "test", Image: "golang", Script: "go test", Envs: Envs{{Name: &name, Value: &localEnv}}}}, 1},
+		{"cycle", []Stage{
+			{Name: "a", Image: "golang", Script: "true", RunAfter: []string{"b"}},
+			{Name: "b", Image: "golang", Script: "true", RunAfter: []string{"a"}},
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateStages("", tt.stages); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
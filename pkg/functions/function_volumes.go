@@ -119,3 +119,13 @@ func validateVolumes(volumes []Volume) (errors []string) {
 
 	return
 }
+
+// validateVolumesPlatform checks that volumes are only defined for platforms
+// which mount them.  The docker platform does not.
+// Returns array of error messages, empty if no errors are found
+func validateVolumesPlatform(platform string, volumes []Volume) (errors []string) {
+	if platform == "docker" && len(volumes) > 0 {
+		errors = append(errors, "volumes are not supported by the docker platform")
+	}
+	return
+}
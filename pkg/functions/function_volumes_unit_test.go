@@ -264,3 +264,21 @@ func Test_validateVolumesString(t *testing.T) {
 		})
 	}
 }
+
+// Test_validateVolumesPlatform ensures volumes are rejected for the docker
+// platform only.
+func Test_validateVolumesPlatform(t *testing.T) {
+	secret, path := "secret", "/etc/secret"
+	volumes := []Volume{{Secret: &secret, Path: &path}}
+	if got := validateVolumesPlatform("docker", volumes); len(got) != 1 {
+		t.Errorf("expected an error for the docker platform, got %v", got)
+	}
+	for _, platform := range []string{"", "knative", "kubernetes"} {
+		if got := validateVolumesPlatform(platform, volumes); len(got) != 0 {
+			t.Errorf("expected no errors for platform %q, got %v", platform, got)
+		}
+	}
+	if got := validateVolumesPlatform("docker", nil); len(got) != 0 {
+		t.Errorf("expected no errors without volumes, got %v", got)
+	}
+}
@@ -16,6 +16,8 @@ const (
 // InstanceRefs are point-in-time snapshots of a function's runtime state in
 // a given environment.  By default 'local' and 'remote' environmnts are
 // available when a function is run locally and deployed (respectively).
+// Additional named environments are those defined in the function's
+// .Environments.
 type InstanceRefs struct {
 	client *Client
 }
@@ -37,9 +39,20 @@ func (s *InstanceRefs) Get(ctx context.Context, f Function, environment string)
 	case EnvironmentRemote:
 		return s.Remote(ctx, f.Name, f.Deploy.Namespace)
 	default:
-		// Future versions will support additional ad-hoc named environments, such
-		// as for testing. Local and remote remaining the base cases.
-		return Instance{}, ErrEnvironmentNotFound
+		// Named environments defined by the function are remote instances in
+		// the namespace to which the function was last deployed for that
+		// environment.
+		e, ok := f.Environments[environment]
+		if !ok {
+			return Instance{}, ErrEnvironmentNotFound
+		}
+		if f.Environment == environment {
+			e.Deployed.Namespace = f.Deploy.Namespace // applied and possibly newer
+		}
+		if e.Deployed.Namespace == "" {
+			return Instance{}, ErrNotRunning
+		}
+		return s.Remote(ctx, f.Name, e.Deployed.Namespace)
 	}
 }
 
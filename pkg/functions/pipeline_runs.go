@@ -0,0 +1,56 @@
+package functions
+
+import "time"
+
+// PipelineRun is a point-in-time snapshot of a run of the pipeline which
+// builds and deploys a function remotely.
+type PipelineRun struct {
+	Name      string `json:"name" yaml:"name"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Status of the run, such as Running, Succeeded, Failed or Cancelled,
+	// and a message describing it.
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	StartTime      time.Time `json:"startTime,omitempty" yaml:"startTime,omitempty"`
+	CompletionTime time.Time `json:"completionTime,omitempty" yaml:"completionTime,omitempty"`
+
+	// Commit of the function's source which was built, if fetched from Git.
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+
+	// ImageDigest of the image which was built.
+	ImageDigest string `json:"imageDigest,omitempty" yaml:"imageDigest,omitempty"`
+
+	// Tasks of the run which have started, in order of their start.
+	Tasks []PipelineTaskRun `json:"tasks,omitempty" yaml:"tasks,omitempty" xml:"tasks>task"`
+}
+
+// Duration of the run, or of the run so far if it has not completed.
+func (r PipelineRun) Duration() time.Duration {
+	return duration(r.StartTime, r.CompletionTime)
+}
+
+// PipelineTaskRun is a run of one task of a PipelineRun.
+type PipelineTaskRun struct {
+	Name           string    `json:"name" yaml:"name"`
+	Status         string    `json:"status" yaml:"status"`
+	Message        string    `json:"message,omitempty" yaml:"message,omitempty"`
+	StartTime      time.Time `json:"startTime,omitempty" yaml:"startTime,omitempty"`
+	CompletionTime time.Time `json:"completionTime,omitempty" yaml:"completionTime,omitempty"`
+}
+
+// Duration of the task's run, or of its run so far if it has not completed.
+func (r PipelineTaskRun) Duration() time.Duration {
+	return duration(r.StartTime, r.CompletionTime)
+}
+
+func duration(start, completion time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+	if completion.IsZero() {
+		return time.Since(start).Round(time.Second)
+	}
+	return completion.Sub(start)
+}
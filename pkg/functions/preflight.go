@@ -0,0 +1,94 @@
+package functions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PreflightStatus is the outcome of a single preflight check.
+type PreflightStatus string
+
+const (
+	PreflightPass PreflightStatus = "pass"
+	PreflightWarn PreflightStatus = "warn" // could not be verified or may fail
+	PreflightFail PreflightStatus = "fail"
+	PreflightSkip PreflightStatus = "skip" // not applicable to the function
+)
+
+// PreflightScope selects which preflight checks are run.
+type PreflightScope int
+
+const (
+	// PreflightAll checks are run.
+	PreflightAll PreflightScope = iota
+	// PreflightBeforeBuild runs all checks but that the built image can be
+	// pulled, such that a deployment fails before it is built and pushed.
+	PreflightBeforeBuild
+	// PreflightImage runs only the check that the built image can be pulled,
+	// once it has been pushed.
+	PreflightImage
+)
+
+// PreflightScopeKey is a type available for use as a context key for
+// selecting the checks run by preflighters.  All checks are run if not set.
+type PreflightScopeKey struct{}
+
+// PreflightPushKey is a type available for use as a context key for
+// indicating (with a bool) whether the function's image will be pushed, such
+// that preflighters may skip the checks of pushing it.  The image is assumed
+// to be pushed if not set.
+type PreflightPushKey struct{}
+
+// PreflightCheck is the result of checking a single precondition of a
+// deployment.
+type PreflightCheck struct {
+	// Name of the check, such as "Knative Serving".
+	Name string `json:"name" yaml:"name"`
+
+	// Status of the check.
+	Status PreflightStatus `json:"status" yaml:"status"`
+
+	// Message detailing the result, such as a version found or the reason
+	// for a failure.
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// PreflightReport of the checks made before a deployment, in the order in
+// which they were made.
+type PreflightReport struct {
+	Checks []PreflightCheck `json:"checks" yaml:"checks"`
+}
+
+// Add a check to the report.
+func (r *PreflightReport) Add(name string, status PreflightStatus, format string, a ...any) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: status, Message: fmt.Sprintf(format, a...)})
+}
+
+// Failed returns true if any check failed.
+func (r PreflightReport) Failed() bool {
+	for _, c := range r.Checks {
+		if c.Status == PreflightFail {
+			return true
+		}
+	}
+	return false
+}
+
+// String is a human readable report with one line per check.
+func (r PreflightReport) String() string {
+	var b strings.Builder
+	for _, c := range r.Checks {
+		symbol := map[PreflightStatus]string{
+			PreflightPass: "✅",
+			PreflightWarn: "⚠️ ",
+			PreflightFail: "❌",
+			PreflightSkip: "➖",
+		}[c.Status]
+		fmt.Fprintf(&b, "%v %v", symbol, c.Name)
+		if c.Message != "" {
+			fmt.Fprintf(&b, ": %v", c.Message)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -95,16 +95,16 @@ func TestRepository_Inheritance(t *testing.T) {
 	}
 
 	// Assert Template A reflects repo-level settings
-	if fA.Deploy.HealthEndpoints.Readiness != "/repoReadiness" {
-		t.Errorf("Repository-level HealthEndpoint not loaded to template, got %q", fA.Deploy.HealthEndpoints.Readiness)
+	if fA.Deploy.HealthEndpoints.Readiness.Path != "/repoReadiness" {
+		t.Errorf("Repository-level HealthEndpoint not loaded to template, got %q", fA.Deploy.HealthEndpoints.Readiness.Path)
 	}
 	if diff := cmp.Diff([]string{"repoBuildpack"}, fA.Build.Buildpacks); diff != "" {
 		t.Errorf("Repository-level Buildpack differs (-want, +got): %s", diff)
 	}
 
 	// Assert Template B reflects runtime-level settings
-	if fB.Deploy.HealthEndpoints.Readiness != "/runtimeReadiness" {
-		t.Errorf("Runtime-level HealthEndpoint not loaded to template, got %q", fB.Deploy.HealthEndpoints.Readiness)
+	if fB.Deploy.HealthEndpoints.Readiness.Path != "/runtimeReadiness" {
+		t.Errorf("Runtime-level HealthEndpoint not loaded to template, got %q", fB.Deploy.HealthEndpoints.Readiness.Path)
 	}
 	if diff := cmp.Diff([]string{"runtimeBuildpack"}, fB.Build.Buildpacks); diff != "" {
 		t.Errorf("Runtime-level Buildpack differs (-want, +got): %s", diff)
@@ -124,8 +124,8 @@ func TestRepository_Inheritance(t *testing.T) {
 	}
 
 	// Assert Template C reflects template-level settings
-	if fC.Deploy.HealthEndpoints.Readiness != "/templateReadiness" {
-		t.Fatalf("Template-level HealthEndpoint not loaded to template, got %q", fC.Deploy.HealthEndpoints.Readiness)
+	if fC.Deploy.HealthEndpoints.Readiness.Path != "/templateReadiness" {
+		t.Fatalf("Template-level HealthEndpoint not loaded to template, got %q", fC.Deploy.HealthEndpoints.Readiness.Path)
 	}
 	if diff := cmp.Diff([]string{"templateBuildpack"}, fC.Build.Buildpacks); diff != "" {
 		t.Fatalf("Template-level Buildpack differs (-want, +got): %s", diff)
@@ -0,0 +1,313 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/filesystem"
+	"knative.dev/func/pkg/scaffolding"
+)
+
+// knownBuilderImageKeys are the builders for which a repository manifest may
+// define builder images.
+var knownBuilderImageKeys = []string{"host", "pack", "s2i"}
+
+// Finding of a repository validation, pertaining to the given path within
+// the repository.  Findings which are not warnings are errors: the repository
+// will not function as expected.
+type Finding struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"`
+}
+
+func (f Finding) String() string {
+	level := "error"
+	if f.Warning {
+		level = "warning"
+	}
+	return fmt.Sprintf("%v: %v: %v", level, f.Path, f.Message)
+}
+
+// RepositoryReport is the result of validating a template repository.
+type RepositoryReport struct {
+	URI      string    `json:"uri"`
+	Findings []Finding `json:"findings"`
+}
+
+// Errors returns the number of findings which are errors.
+func (r RepositoryReport) Errors() (n int) {
+	for _, f := range r.Findings {
+		if !f.Warning {
+			n++
+		}
+	}
+	return
+}
+
+// Warnings returns the number of findings which are warnings.
+func (r RepositoryReport) Warnings() int {
+	return len(r.Findings) - r.Errors()
+}
+
+// Valid returns true if the report contains no errors.
+func (r RepositoryReport) Valid() bool {
+	return r.Errors() == 0
+}
+
+func (r *RepositoryReport) errorf(p, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{Path: p, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *RepositoryReport) warnf(p, format string, args ...any) {
+	r.Findings = append(r.Findings, Finding{Path: p, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// ValidateRepository checks the template repository at the given URI (as
+// accepted by NewRepository) for problems which would otherwise only surface
+// when creating or building a function from one of its templates:
+// manifests which do not conform to the schema, invalid builder image
+// references, health endpoints and invocation hints, templates whose source
+// does not match their invocation hint, and templates whose method signature
+// has no corresponding scaffolding.
+//
+// An error is returned only if the repository can not be read at all.  All
+// other problems are returned as findings of the report.
+func ValidateRepository(uri string) (report RepositoryReport, err error) {
+	report = RepositoryReport{URI: uri, Findings: []Finding{}}
+
+	fs, err := filesystemFromURI(uri)
+	if err != nil {
+		return report, fmt.Errorf("failed to get repository from URI (%q): %w", uri, err)
+	}
+	if fs == nil {
+		return report, fmt.Errorf("repository not found at %q", uri)
+	}
+
+	// Repository manifest
+	repoConfig := repositoryConfig{funcDefaults: funcDefaults{Invoke: DefaultInvocationFormat}}
+	manifest := struct {
+		repositoryConfig `yaml:",inline"`
+		SchemaVersion    string `yaml:"schema_version,omitempty"`
+	}{}
+	if decodeManifest(&report, fs, repositoryManifest, &manifest) {
+		validateDefaults(&report, repositoryManifest, manifest.funcDefaults)
+	}
+	if c, err := applyRepositoryManifest(fs, repoConfig); err == nil {
+		repoConfig = c
+	}
+	if repoConfig.TemplatesPath == "" {
+		repoConfig.TemplatesPath = DefaultTemplatesPath
+	}
+	if err := checkDir(fs, repoConfig.TemplatesPath); err != nil {
+		report.errorf(repositoryManifest, "templates path is invalid. %v", err)
+		return report, nil
+	}
+
+	// Runtimes
+	fis, err := fs.ReadDir(repoConfig.TemplatesPath)
+	if err != nil {
+		return
+	}
+	runtimes := 0
+	for _, fi := range fis {
+		if !fi.IsDir() || strings.HasPrefix(fi.Name(), ".") || fi.Name() == "certs" {
+			continue
+		}
+		runtimes++
+		validateRuntime(&report, fs, repoConfig, fi.Name())
+	}
+	if runtimes == 0 {
+		report.errorf(repoConfig.TemplatesPath, "no runtimes found")
+	}
+	return report, nil
+}
+
+// validateRuntime checks the manifest, templates and scaffolding of a runtime.
+func validateRuntime(report *RepositoryReport, fs filesystem.Filesystem, repoConfig repositoryConfig, runtime string) {
+	runtimePath := path.Join(repoConfig.TemplatesPath, runtime)
+
+	manifestPath := path.Join(runtimePath, runtimeManifest)
+	var manifest runtimeConfig
+	if decodeManifest(report, fs, manifestPath, &manifest) {
+		validateDefaults(report, manifestPath, manifest)
+	}
+	rtConfig, err := applyRuntimeManifest(fs, runtime, repoConfig)
+	if err != nil {
+		rtConfig = repoConfig.funcDefaults
+	}
+
+	// Scaffolding provided by the runtime, by signature
+	scaffoldingPath := path.Join(runtimePath, "scaffolding")
+	scaffolds := map[string]bool{}
+	if fis, err := fs.ReadDir(scaffoldingPath); err == nil {
+		for _, fi := range fis {
+			if !fi.IsDir() {
+				continue
+			}
+			if !isSignature(fi.Name()) {
+				report.warnf(path.Join(scaffoldingPath, fi.Name()), "unrecognized scaffolding signature")
+				continue
+			}
+			scaffolds[fi.Name()] = true
+		}
+	}
+
+	fis, err := fs.ReadDir(runtimePath)
+	if err != nil {
+		report.errorf(runtimePath, "unable to read runtime. %v", err)
+		return
+	}
+	templates, unscaffolded := 0, false
+	for _, fi := range fis {
+		if !fi.IsDir() || strings.HasPrefix(fi.Name(), ".") || fi.Name() == "scaffolding" {
+			continue
+		}
+		templates++
+		templatePath := path.Join(runtimePath, fi.Name())
+
+		manifestPath := path.Join(templatePath, templateManifest)
+		var manifest templateConfig
+		if decodeManifest(report, fs, manifestPath, &manifest) {
+			validateDefaults(report, manifestPath, manifest)
+		}
+		t, err := applyTemplateManifest(fs, repoConfig.TemplatesPath, template{runtime: runtime, name: fi.Name(), config: rtConfig})
+		if err != nil {
+			t.config = rtConfig
+		}
+
+		if !validateSignature(report, fs, templatePath, runtime, t.config.Invoke, scaffolds) {
+			unscaffolded = true
+		}
+	}
+	if templates == 0 {
+		report.warnf(runtimePath, "runtime contains no templates")
+	}
+	if unscaffolded && len(scaffolds) == 0 {
+		report.warnf(runtimePath, "runtime provides no scaffolding. Functions of this runtime can not be built by the host builder")
+	}
+}
+
+// validateSignature detects the invocation hint and method signature of the
+// template's source, checking them against the template's invocation hint and
+// the runtime's scaffolding.  Returned is false if the template's signature
+// is known and the runtime provides no scaffolding for it.
+func validateSignature(report *RepositoryReport, fs filesystem.Filesystem, templatePath, runtime, invoke string, scaffolds map[string]bool) bool {
+	dir, err := os.MkdirTemp("", "func-validate")
+	if err != nil {
+		report.warnf(templatePath, "unable to detect signature. %v", err)
+		return true
+	}
+	defer os.RemoveAll(dir)
+	if err = filesystem.CopyFromFS(templatePath, dir, fs); err != nil {
+		report.warnf(templatePath, "unable to detect signature. %v", err)
+		return true
+	}
+
+	detected, err := scaffolding.DetectInvoke(dir, runtime)
+	if errors.As(err, &scaffolding.ErrRuntimeNotRecognized{}) {
+		if len(scaffolds) > 0 {
+			report.warnf(templatePath, "unable to verify scaffolding coverage for the unrecognized runtime %q", runtime)
+		}
+		return true
+	}
+	if err == nil && detected != invoke {
+		report.warnf(templatePath, "invocation hint is %q but the source appears to expect %q", invoke, detected)
+	}
+
+	s, err := scaffolding.DetectSignature(dir, runtime, invoke)
+	if errors.As(err, &scaffolding.ErrDetectorNotImplemented{}) {
+		return true
+	} else if err != nil {
+		report.warnf(templatePath, "unable to detect signature. %v", err)
+		return true
+	}
+	if scaffolds[s.String()] {
+		return true
+	}
+	if len(scaffolds) > 0 {
+		report.errorf(templatePath, "no scaffolding for the %v signature", s)
+	}
+	return false
+}
+
+// validateDefaults checks the values defined by a manifest at path.
+func validateDefaults(report *RepositoryReport, p string, d funcDefaults) {
+	for builder, image := range d.BuilderImages {
+		if !contains(knownBuilderImageKeys, builder) {
+			report.warnf(p, "builder image defined for unknown builder %q. Known builders are %v", builder, knownBuilderImageKeys)
+		}
+		if _, err := name.ParseReference(image); err != nil {
+			report.errorf(p, "invalid %v builder image %q. %v", builder, image, err)
+		}
+	}
+	for _, bp := range d.Buildpacks {
+		// Buildpacks may also be referenced by URN or URL
+		ref := strings.TrimPrefix(bp, "docker://")
+		if strings.HasPrefix(ref, "urn:") || strings.Contains(ref, "://") {
+			continue
+		}
+		if _, err := name.ParseReference(ref); err != nil {
+			report.errorf(p, "invalid buildpack %q. %v", bp, err)
+		}
+	}
+	for _, err := range validateHealthEndpoints(d.HealthEndpoints) {
+		report.errorf(p, "%v", err)
+	}
+	if d.Invoke != "" && d.Invoke != "http" && d.Invoke != "cloudevent" {
+		report.errorf(p, "invalid invocation hint %q. Expected \"http\" or \"cloudevent\"", d.Invoke)
+	}
+	for _, e := range append(append([]Env{}, d.BuildEnvs...), d.RunEnvs...) {
+		if e.Name == nil || *e.Name == "" {
+			report.errorf(p, "environment variable without a name")
+		}
+	}
+}
+
+// decodeManifest at path p into v, disallowing unknown fields.  Returned is
+// true if the manifest exists and is valid.
+func decodeManifest(report *RepositoryReport, fs filesystem.Filesystem, p string, v any) bool {
+	file, err := fs.Open(p)
+	if os.IsNotExist(err) {
+		return false
+	} else if err != nil {
+		report.errorf(p, "unable to open manifest. %v", err)
+		return false
+	}
+	defer file.Close()
+	decoder := yaml.NewDecoder(file)
+	decoder.SetStrict(true)
+	if err = decoder.Decode(v); err != nil {
+		report.errorf(p, "invalid manifest. %v", err)
+		return false
+	}
+	return true
+}
+
+// isSignature returns true if the given name is that of a scaffolding
+// signature.
+func isSignature(s string) bool {
+	for _, sig := range []scaffolding.Signature{
+		scaffolding.InstancedHTTP, scaffolding.InstancedCloudevents,
+		scaffolding.StaticHTTP, scaffolding.StaticCloudevents} {
+		if sig.String() == s {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
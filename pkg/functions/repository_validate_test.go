@@ -0,0 +1,88 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestValidateRepository_Embedded ensures that the embedded default
+// repository is valid.
+func TestValidateRepository_Embedded(t *testing.T) {
+	report, err := fn.ValidateRepository("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range report.Findings {
+		t.Log(f)
+	}
+	if !report.Valid() {
+		t.Fatalf("expected the embedded repository to be valid, got %v errors", report.Errors())
+	}
+}
+
+// TestValidateRepository_Findings ensures that the problems of a repository
+// are reported.
+func TestValidateRepository_Findings(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("manifest.yaml", "name: custom\nunknownField: true\n")
+	write("go/manifest.yaml", "builderImages:\n  pack: 'not a valid image!'\nhealthEndpoints:\n  liveness: health\n")
+	write("go/events/manifest.yaml", "invoke: event\n")
+	write("go/events/handle.go", "package function\n\nfunc Handle() {}\n")
+	write("go/http/handle.go", "package function\n\ntype F struct{}\n\nfunc New() *F { return &F{} }\n")
+	write("go/scaffolding/static-http/main.go", "package main\n")
+	write("empty/README.md", "")
+
+	report, err := fn.ValidateRepository("file://" + filepath.ToSlash(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		Path    string
+		Message string
+		Warning bool
+	}{
+		{"manifest.yaml", "field unknownField not found", false},
+		{"go/manifest.yaml", "invalid pack builder image", false},
+		{"go/manifest.yaml", "liveness probe path \"health\" must be an absolute path", false},
+		{"go/events/manifest.yaml", "invalid invocation hint \"event\"", false},
+		{"go/http", "no scaffolding for the instanced-http signature", false},
+		{"empty", "runtime contains no templates", true},
+	}
+	for _, e := range expected {
+		found := false
+		for _, f := range report.Findings {
+			if f.Path == e.Path && f.Warning == e.Warning && strings.Contains(f.Message, e.Message) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected finding %q for %v not found", e.Message, e.Path)
+		}
+	}
+	if report.Valid() {
+		t.Fatal("expected the repository to be invalid")
+	}
+	if t.Failed() {
+		for _, f := range report.Findings {
+			t.Log(f)
+		}
+	}
+}
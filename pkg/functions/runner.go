@@ -146,6 +146,21 @@ func runGo(ctx context.Context, job *Job) (err error) {
 	//   A Cmd with a non-empty Dir field and nil Env now implicitly sets the PWD environment variable for the subprocess to match Dir.
 	//   The new method Cmd.Environ reports the environment that would be used to run the command, including the implicitly set PWD variable.
 	// cmd.Env = append(cmd.Environ(), "PORT="+job.Port) // requires go 1.19
+	//
+	// Function envs, including those of its env files, with references to
+	// local environment variables and files interpolated, are set first such
+	// that PORT and PWD can not be overridden.
+	runEnvs, err := job.Function.RunEnvs()
+	if err != nil {
+		return
+	}
+	envs, err := InterpolateIn(job.Function.Root, runEnvs)
+	if err != nil {
+		return
+	}
+	for k, v := range envs {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
 	cmd.Env = append(cmd.Env, "PORT="+job.Port, "PWD="+cmd.Dir)
 
 	// Running asynchronously allows for the client Run method to return
@@ -55,7 +55,18 @@ func (t template) Fullname() string {
 // Write the template source files
 // (all source code except manifest.yaml and scaffolding)
 func (t template) Write(ctx context.Context, f *Function) error {
+	t.apply(f)
 
+	mask := func(p string) bool {
+		_, f := path.Split(p)
+		return f == templateManifest
+	}
+
+	return filesystem.CopyFromFS(".", f.Root, filesystem.NewMaskingFS(mask, t.fs)) // copy everything but manifest.yaml
+}
+
+// apply the template's defaults to the function without writing any files.
+func (t template) apply(f *Function) {
 	// Apply fields from the template onto the function itself (Denormalize).
 	// The template is already the denormalized view of repo->runtime->template
 	// so it's values are treated as defaults.
@@ -73,20 +84,16 @@ func (t template) Write(ctx context.Context, f *Function) error {
 	if len(f.Run.Envs) == 0 {
 		f.Run.Envs = t.config.RunEnvs
 	}
-	if f.Deploy.HealthEndpoints.Liveness == "" {
+	if f.Deploy.HealthEndpoints.Liveness.IsZero() {
 		f.Deploy.HealthEndpoints.Liveness = t.config.HealthEndpoints.Liveness
 	}
-	if f.Deploy.HealthEndpoints.Readiness == "" {
+	if f.Deploy.HealthEndpoints.Readiness.IsZero() {
 		f.Deploy.HealthEndpoints.Readiness = t.config.HealthEndpoints.Readiness
 	}
+	if f.Deploy.HealthEndpoints.Startup.IsZero() {
+		f.Deploy.HealthEndpoints.Startup = t.config.HealthEndpoints.Startup
+	}
 	if f.Invoke == "" && t.config.Invoke != "http" {
 		f.Invoke = t.config.Invoke
 	}
-
-	mask := func(p string) bool {
-		_, f := path.Split(p)
-		return f == templateManifest
-	}
-
-	return filesystem.CopyFromFS(".", f.Root, filesystem.NewMaskingFS(mask, t.fs)) // copy everything but manifest.yaml
 }
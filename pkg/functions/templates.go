@@ -105,3 +105,20 @@ func (t *Templates) Write(f *Function) error {
 
 	return template.Write(context.TODO(), f)
 }
+
+// Apply the defaults of a function's template (builders, buildpacks, health
+// endpoints etc.) to the function without writing the template's source
+// files.  Used when adopting existing source code as a function.
+func (t *Templates) Apply(f *Function) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	tpl, err := t.Get(f.Runtime, f.Template)
+	if err != nil {
+		return err
+	}
+	if tpl, ok := tpl.(template); ok {
+		tpl.apply(f)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Workspace of the functions located under a common root directory, such as
+// those of a monorepo.
+type Workspace struct {
+	// Root directory under which the functions were discovered.
+	Root string
+
+	// Functions of the workspace, in the order of the plan: each function
+	// follows the functions it depends on.
+	Functions []Function
+
+	// deps are the indices in Functions of the dependencies of each function.
+	deps [][]int
+}
+
+// NewWorkspace discovers the initialized functions under the given root and
+// plans their order from their dependencies (see BuildSpec.DependsOn).  Hidden
+// directories and node_modules are not searched, nor are the directories of
+// functions.  Errors if a dependency is not a function of the workspace, or if
+// dependencies form a cycle.
+func NewWorkspace(root string) (w Workspace, err error) {
+	if root, err = filepath.Abs(root); err != nil {
+		return
+	}
+	w.Root = root
+
+	var discovered []Function
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+		initialized, err := hasInitializedFunction(path)
+		if err != nil {
+			return fmt.Errorf("cannot load the function at %q: %w", path, err)
+		}
+		if !initialized {
+			return nil
+		}
+		f, err := NewFunction(path)
+		if err != nil {
+			return err
+		}
+		discovered = append(discovered, f)
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return
+	}
+
+	// Dependencies, by index of the discovered functions
+	index := make(map[string]int, len(discovered))
+	for i, f := range discovered {
+		index[f.Root] = i
+	}
+	deps := make([][]int, len(discovered))
+	for i, f := range discovered {
+		for _, d := range f.Build.DependsOn {
+			j, ok := index[filepath.Join(f.Root, d)]
+			if !ok {
+				return w, fmt.Errorf("function %q depends on %q, which is not a function of the workspace", w.Path(f), d)
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	// Plan: the discovered functions in order, each once its dependencies are
+	// (depth first), detecting cycles.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	var (
+		state = make([]int, len(discovered))
+		order = make([]int, 0, len(discovered))
+		visit func(i int, path []string) error
+	)
+	visit = func(i int, path []string) error {
+		path = append(path, w.Path(discovered[i]))
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("the dependencies of the functions form a cycle: %v", strings.Join(path, " -> "))
+		}
+		state[i] = visiting
+		for _, j := range deps[i] {
+			if err := visit(j, path); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		order = append(order, i)
+		return nil
+	}
+	for i := range discovered {
+		if err = visit(i, nil); err != nil {
+			return
+		}
+	}
+
+	position := make([]int, len(discovered))
+	for p, i := range order {
+		position[i] = p
+	}
+	w.Functions = make([]Function, len(order))
+	w.deps = make([][]int, len(order))
+	for p, i := range order {
+		w.Functions[p] = discovered[i]
+		for _, j := range deps[i] {
+			w.deps[p] = append(w.deps[p], position[j])
+		}
+	}
+	return
+}
+
+// Dependencies returns the functions of the workspace the function at the
+// given position of the plan depends on.
+func (w Workspace) Dependencies(i int) (ff []Function) {
+	for _, j := range w.deps[i] {
+		ff = append(ff, w.Functions[j])
+	}
+	return
+}
+
+// Path of the function relative to the root of the workspace.
+func (w Workspace) Path(f Function) string {
+	if rel, err := filepath.Rel(w.Root, f.Root); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return f.Root
+}
+
+// WorkspaceStatus of a function once a workspace task was run.
+type WorkspaceStatus string
+
+const (
+	// WorkspaceSucceeded indicates the task of the function succeeded.
+	WorkspaceSucceeded WorkspaceStatus = "succeeded"
+	// WorkspaceUnchanged indicates the function was skipped, being unchanged.
+	WorkspaceUnchanged WorkspaceStatus = "unchanged"
+	// WorkspaceFailed indicates the task of the function failed.
+	WorkspaceFailed WorkspaceStatus = "failed"
+	// WorkspaceBlocked indicates the task was not run, as that of a function
+	// it depends on failed.
+	WorkspaceBlocked WorkspaceStatus = "blocked"
+)
+
+// WorkspaceTask is run for each function of a workspace, such as building it.
+// Indicated is whether any function it depends on changed, as it was not
+// skipped.  Returned is the function as updated by the task, and whether it
+// was skipped, being unchanged.
+type WorkspaceTask func(ctx context.Context, f Function, dependenciesChanged bool) (updated Function, skipped bool, err error)
+
+// WorkspaceResult of the task of a function.
+type WorkspaceResult struct {
+	Function Function
+	Status   WorkspaceStatus
+	Duration time.Duration
+	Err      error
+}
+
+// Run the task for each function of the workspace, running at most the given
+// number in parallel.  The task of a function is run once those of the
+// functions it depends on succeeded; it is not if any failed.  Returned are
+// the results in the order of the plan.
+func (w Workspace) Run(ctx context.Context, parallel int, task WorkspaceTask) []WorkspaceResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+	var (
+		results = make([]WorkspaceResult, len(w.Functions))
+		done    = make([]chan struct{}, len(w.Functions))
+		slots   = make(chan struct{}, parallel)
+		wg      sync.WaitGroup
+	)
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	for i, f := range w.Functions {
+		wg.Add(1)
+		go func(i int, f Function) {
+			defer wg.Done()
+			defer close(done[i])
+			results[i].Function = f
+
+			changed := false
+			for _, j := range w.deps[i] {
+				<-done[j]
+				switch results[j].Status {
+				case WorkspaceFailed, WorkspaceBlocked:
+					results[i].Status = WorkspaceBlocked
+					results[i].Err = fmt.Errorf("dependency %q did not succeed", w.Path(w.Functions[j]))
+					return
+				case WorkspaceSucceeded:
+					changed = true
+				}
+			}
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i].Status, results[i].Err = WorkspaceFailed, ctx.Err()
+				return
+			}
+
+			start := time.Now()
+			updated, skipped, err := task(ctx, f, changed)
+			results[i].Duration = time.Since(start)
+			switch {
+			case err != nil:
+				results[i].Status, results[i].Err = WorkspaceFailed, err
+			case skipped:
+				results[i].Status = WorkspaceUnchanged
+			default:
+				results[i].Function, results[i].Status = updated, WorkspaceSucceeded
+			}
+		}(i, f)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,174 @@
+//go:build !integration
+// +build !integration
+
+package functions_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// initWorkspace initializes a function at each of the given paths relative to
+// a new workspace, depending on the given functions.
+func initWorkspace(t *testing.T, functions map[string][]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, deps := range functions {
+		f := fn.Function{Runtime: "go", Root: filepath.Join(root, p), Build: fn.BuildSpec{DependsOn: deps}}
+		if err := os.MkdirAll(f.Root, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fn.New().Init(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func workspacePaths(w fn.Workspace) (pp []string) {
+	for _, f := range w.Functions {
+		pp = append(pp, w.Path(f))
+	}
+	return
+}
+
+// TestWorkspace_Plan ensures functions are discovered, including in nested
+// directories but not hidden ones, and are planned after their dependencies.
+func TestWorkspace_Plan(t *testing.T) {
+	root := initWorkspace(t, map[string][]string{
+		"api":           {"../services/users", "../services/orders"},
+		"services/auth": nil,
+		"services/orders": {
+			"../users",
+		},
+		"services/users": {"../auth"},
+		".hidden/fn":     nil,
+	})
+	// a directory of a function is not searched for others
+	if err := os.MkdirAll(filepath.Join(root, "api", "fixtures"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: filepath.Join(root, "api", "fixtures")}); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := fn.NewWorkspace(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "services/auth,services/users,services/orders,api"
+	if got := strings.Join(workspacePaths(w), ","); got != want {
+		t.Fatalf("expected plan %v, got %v", want, got)
+	}
+	if deps := w.Dependencies(3); len(deps) != 2 || w.Path(deps[0]) != "services/users" {
+		t.Fatalf("unexpected dependencies of api: %v", deps)
+	}
+}
+
+// TestWorkspace_InvalidDependencies ensures dependencies which are not
+// functions of the workspace, or which form a cycle, are errors.
+func TestWorkspace_InvalidDependencies(t *testing.T) {
+	root := initWorkspace(t, map[string][]string{"a": {"../missing"}})
+	if _, err := fn.NewWorkspace(root); err == nil || !strings.Contains(err.Error(), "not a function of the workspace") {
+		t.Fatalf("expected an unknown dependency error, got %v", err)
+	}
+
+	root = initWorkspace(t, map[string][]string{"a": {"../b"}, "b": {"../c"}, "c": {"../a"}})
+	if _, err := fn.NewWorkspace(root); err == nil || !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+}
+
+// TestWorkspace_Run ensures tasks are run after those of their dependencies,
+// bounded in parallel, that dependents of changed functions are told so, and
+// that dependents of failed functions are blocked.
+func TestWorkspace_Run(t *testing.T) {
+	root := initWorkspace(t, map[string][]string{
+		"a": nil,
+		"b": nil,
+		"c": nil,
+		"d": {"../a"},
+		"e": {"../b"},
+		"f": {"../c"},
+		"g": {"../f"},
+	})
+	w, err := fn.NewWorkspace(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu                  sync.Mutex
+		finished            = map[string]bool{}
+		running, maxRunning int32
+	)
+	results := w.Run(context.Background(), 2, func(ctx context.Context, f fn.Function, dependenciesChanged bool) (fn.Function, bool, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		p := w.Path(f)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, d := range f.Build.DependsOn {
+			if !finished[filepath.Base(d)] {
+				t.Errorf("%v run before its dependency %v", p, d)
+			}
+		}
+		finished[p] = true
+		switch p {
+		case "a": // unchanged
+			return f, true, nil
+		case "c":
+			return f, false, errors.New("build failed")
+		case "d":
+			if dependenciesChanged {
+				t.Errorf("expected the dependencies of d unchanged")
+			}
+		case "e":
+			if !dependenciesChanged {
+				t.Errorf("expected the dependencies of e changed")
+			}
+		}
+		return f, false, nil
+	})
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 tasks in parallel, got %v", maxRunning)
+	}
+	want := map[string]fn.WorkspaceStatus{
+		"a": fn.WorkspaceUnchanged,
+		"b": fn.WorkspaceSucceeded,
+		"c": fn.WorkspaceFailed,
+		"d": fn.WorkspaceSucceeded,
+		"e": fn.WorkspaceSucceeded,
+		"f": fn.WorkspaceBlocked,
+		"g": fn.WorkspaceBlocked,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %v results, got %v", len(want), len(results))
+	}
+	for _, r := range results {
+		if p := w.Path(r.Function); r.Status != want[p] {
+			t.Errorf("expected %v %v, got %v (%v)", p, want[p], r.Status, r.Err)
+		}
+	}
+	if finished["f"] || finished["g"] {
+		t.Error("expected the dependents of a failed function not to run")
+	}
+}
@@ -0,0 +1,21 @@
+// Package bitbucket manages the webhooks of repositories on Bitbucket Cloud
+// and on Bitbucket Server (Data Center).
+package bitbucket
+
+import (
+	"net/http"
+)
+
+const webhookDescription = "Pipelines as Code"
+
+// authorize requests with the user and the token (an app password), or with
+// the token alone (an access token) if there is no user.
+func authorize(user, token string) func(*http.Request) {
+	return func(req *http.Request) {
+		if user != "" {
+			req.SetBasicAuth(user, token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package bitbucket_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"knative.dev/func/pkg/git/bitbucket"
+)
+
+// hooksServer stands in for the hooks API of a repository at the prefix,
+// identifying hooks by the idKey with ids generated by newID.
+type hooksServer struct {
+	prefix string
+	idKey  string
+	newID  func(n int) any
+
+	mu    sync.Mutex
+	n     int
+	hooks map[string]map[string]any
+}
+
+func (s *hooksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, token, ok := r.BasicAuth(); !ok || user != "alice" || token != "app-password" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == s.prefix:
+		hooks := []map[string]any{}
+		for _, h := range s.hooks {
+			hooks = append(hooks, h)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"values": hooks})
+	case r.Method == http.MethodPost && r.URL.Path == s.prefix:
+		var h map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.n++
+		h[s.idKey] = s.newID(s.n)
+		s.hooks[fmt.Sprint(h[s.idKey])] = h
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(h)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, s.prefix+"/"):
+		id, _ := url.PathUnescape(strings.TrimPrefix(r.URL.Path, s.prefix+"/"))
+		if _, ok := s.hooks[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(s.hooks, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type client interface {
+	CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) (string, error)
+	DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error
+}
+
+// TestClients_WebHook ensures a webhook is created once on Bitbucket Cloud and
+// Server, and deleted by its ID.
+func TestClients_WebHook(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		server    *hooksServer
+		newClient func(url string) client
+		secret    func(hook map[string]any) any
+	}{
+		{
+			name: "cloud",
+			server: &hooksServer{
+				prefix: "/2.0/repositories/foo/bar/hooks",
+				idKey:  "uuid",
+				newID:  func(n int) any { return fmt.Sprintf("{%08d-uuid}", n) },
+			},
+			newClient: func(url string) client {
+				return bitbucket.CloudClient{BaseURL: url + "/2.0", User: "alice", PersonalAccessToken: "app-password"}
+			},
+			secret: func(hook map[string]any) any { return hook["secret"] },
+		},
+		{
+			name: "server",
+			server: &hooksServer{
+				prefix: "/rest/api/1.0/projects/foo/repos/bar/webhooks",
+				idKey:  "id",
+				newID:  func(n int) any { return n },
+			},
+			newClient: func(url string) client {
+				return bitbucket.ServerClient{BaseURL: url, User: "alice", PersonalAccessToken: "app-password"}
+			},
+			secret: func(hook map[string]any) any { return hook["configuration"].(map[string]any)["secret"] },
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.server
+			s.hooks = map[string]map[string]any{}
+			srv := httptest.NewServer(s)
+			defer srv.Close()
+
+			ctx := context.Background()
+			c := tt.newClient(srv.URL)
+
+			id, err := c.CreateWebHook(ctx, "foo", "bar", "https://pac.example.com", "webhook-secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			hook, ok := s.hooks[id]
+			if !ok {
+				t.Fatalf("webhook %q not created", id)
+			}
+			if hook["url"] != "https://pac.example.com" || tt.secret(hook) != "webhook-secret" {
+				t.Errorf("unexpected webhook: %v", hook)
+			}
+
+			again, err := c.CreateWebHook(ctx, "foo", "bar", "https://pac.example.com", "webhook-secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != id || len(s.hooks) != 1 {
+				t.Errorf("expected webhook %q to be reused, got %q (%d webhooks)", id, again, len(s.hooks))
+			}
+
+			if err = c.DeleteWebHook(ctx, "foo", "bar", id); err != nil {
+				t.Fatal(err)
+			}
+			if len(s.hooks) != 0 {
+				t.Errorf("webhook not deleted")
+			}
+			if err = c.DeleteWebHook(ctx, "foo", "bar", id); err != nil {
+				t.Fatalf("unexpected error deleting a deleted webhook: %v", err)
+			}
+		})
+	}
+}
+
+// TestCloudClient_AccessToken ensures the token is sent as a bearer token if
+// there is no user.
+func TestCloudClient_AccessToken(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := bitbucket.CloudClient{BaseURL: srv.URL, PersonalAccessToken: "repo-token"}
+	if err := c.DeleteWebHook(context.Background(), "foo", "bar", "{uuid}"); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer repo-token" {
+		t.Errorf("unexpected authorization %q", auth)
+	}
+}
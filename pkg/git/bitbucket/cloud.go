@@ -0,0 +1,84 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"knative.dev/func/pkg/git/internal/rest"
+)
+
+// DefaultCloudURL is the URL of the API of Bitbucket Cloud.
+const DefaultCloudURL = "https://api.bitbucket.org/2.0"
+
+// CloudClient manages the webhooks of repositories on Bitbucket Cloud, where
+// the owner of a repository is its workspace.
+type CloudClient struct {
+	// BaseURL of the API, DefaultCloudURL if empty.
+	BaseURL             string
+	User                string
+	PersonalAccessToken string
+}
+
+type cloudHook struct {
+	UUID        string   `json:"uuid,omitempty"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"`
+	Events      []string `json:"events"`
+}
+
+func (c CloudClient) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) (string, error) {
+	// The hooks are listed a page at a time, following the URL of the next.
+	for next := c.hooksURL(repoOwner, repoName) + "?pagelen=100"; next != ""; {
+		var hooks struct {
+			Values []cloudHook `json:"values"`
+			Next   string      `json:"next"`
+		}
+		if _, err := rest.Do(ctx, http.MethodGet, next, authorize(c.User, c.PersonalAccessToken), nil, &hooks); err != nil {
+			return "", fmt.Errorf("cannot list bitbucket webhooks: %w", err)
+		}
+		for _, h := range hooks.Values {
+			if h.URL == payloadURL {
+				return h.UUID, nil
+			}
+		}
+		next = hooks.Next
+	}
+
+	created := cloudHook{
+		Description: webhookDescription,
+		URL:         payloadURL,
+		Active:      true,
+		Secret:      webhookSecret,
+		Events: []string{
+			"repo:push",
+			"pullrequest:created",
+			"pullrequest:updated",
+			"pullrequest:comment_created",
+		},
+	}
+	if _, err := rest.Do(ctx, http.MethodPost, c.hooksURL(repoOwner, repoName), authorize(c.User, c.PersonalAccessToken), created, &created); err != nil {
+		return "", fmt.Errorf("cannot create bitbucket webhook: %w", err)
+	}
+	return created.UUID, nil
+}
+
+func (c CloudClient) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	_, err := rest.Do(ctx, http.MethodDelete, c.hooksURL(repoOwner, repoName)+"/"+url.PathEscape(id), authorize(c.User, c.PersonalAccessToken), nil, nil)
+	if err != nil && !rest.IsNotFound(err) {
+		return fmt.Errorf("cannot delete bitbucket webhook: %w", err)
+	}
+	return nil
+}
+
+func (c CloudClient) hooksURL(repoOwner, repoName string) string {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultCloudURL
+	}
+	return fmt.Sprintf("%s/repositories/%s/%s/hooks", strings.TrimSuffix(base, "/"), url.PathEscape(repoOwner), url.PathEscape(repoName))
+}
@@ -0,0 +1,83 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"knative.dev/func/pkg/git/internal/rest"
+)
+
+// ServerClient manages the webhooks of repositories on Bitbucket Server (or
+// Data Center), where the owner of a repository is its project.
+type ServerClient struct {
+	// BaseURL of the instance, eg. https://bitbucket.example.com
+	BaseURL             string
+	User                string
+	PersonalAccessToken string
+}
+
+type serverHook struct {
+	ID            int               `json:"id,omitempty"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Active        bool              `json:"active"`
+	Events        []string          `json:"events"`
+	Configuration map[string]string `json:"configuration,omitempty"`
+}
+
+func (c ServerClient) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) (string, error) {
+	// The hooks are listed a page at a time, from the start of the next.
+	for start, last := 0, false; !last; {
+		var hooks struct {
+			Values        []serverHook `json:"values"`
+			IsLastPage    bool         `json:"isLastPage"`
+			NextPageStart int          `json:"nextPageStart"`
+		}
+		listURL := fmt.Sprintf("%s?limit=100&start=%d", c.hooksURL(repoOwner, repoName), start)
+		if _, err := rest.Do(ctx, http.MethodGet, listURL, authorize(c.User, c.PersonalAccessToken), nil, &hooks); err != nil {
+			return "", fmt.Errorf("cannot list bitbucket server webhooks: %w", err)
+		}
+		for _, h := range hooks.Values {
+			if h.URL == payloadURL {
+				return strconv.Itoa(h.ID), nil
+			}
+		}
+		start, last = hooks.NextPageStart, hooks.IsLastPage || hooks.NextPageStart <= start
+	}
+
+	created := serverHook{
+		Name:   webhookDescription,
+		URL:    payloadURL,
+		Active: true,
+		Events: []string{
+			"repo:refs_changed",
+			"pr:opened",
+			"pr:from_ref_updated",
+			"pr:comment:added",
+		},
+		Configuration: map[string]string{"secret": webhookSecret},
+	}
+	if _, err := rest.Do(ctx, http.MethodPost, c.hooksURL(repoOwner, repoName), authorize(c.User, c.PersonalAccessToken), created, &created); err != nil {
+		return "", fmt.Errorf("cannot create bitbucket server webhook: %w", err)
+	}
+	return strconv.Itoa(created.ID), nil
+}
+
+func (c ServerClient) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return fmt.Errorf("invalid webhook id %q: %w", id, err)
+	}
+	_, err := rest.Do(ctx, http.MethodDelete, c.hooksURL(repoOwner, repoName)+"/"+id, authorize(c.User, c.PersonalAccessToken), nil, nil)
+	if err != nil && !rest.IsNotFound(err) {
+		return fmt.Errorf("cannot delete bitbucket server webhook: %w", err)
+	}
+	return nil
+}
+
+func (c ServerClient) hooksURL(repoOwner, repoName string) string {
+	return fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/webhooks", strings.TrimSuffix(c.BaseURL, "/"), url.PathEscape(repoOwner), url.PathEscape(repoName))
+}
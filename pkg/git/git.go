@@ -9,19 +9,23 @@ import (
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 
+	"knative.dev/func/pkg/git/bitbucket"
+	"knative.dev/func/pkg/git/gitea"
 	"knative.dev/func/pkg/git/github"
 	"knative.dev/func/pkg/git/gitlab"
 )
 
 const (
-	GitHubProvider    = "github"
-	GitLabProvider    = "gitlab"
-	BitBucketProvider = "bitbucket-cloud"
+	GitHubProvider          = "github"
+	GitLabProvider          = "gitlab"
+	GiteaProvider           = "gitea"
+	BitBucketProvider       = "bitbucket-cloud"
+	BitBucketServerProvider = "bitbucket-server"
 )
 
 type SupportedProviders []string
 
-var SupportedProvidersList = SupportedProviders{GitHubProvider, GitLabProvider}
+var SupportedProvidersList = SupportedProviders{GitHubProvider, GitLabProvider, GiteaProvider, BitBucketProvider, BitBucketServerProvider}
 
 func (sp SupportedProviders) PrettyString() string {
 	var b strings.Builder
@@ -37,16 +41,39 @@ func (sp SupportedProviders) PrettyString() string {
 	return b.String()
 }
 
+// Supported returns whether the provider is one of the supported providers.
+func (sp SupportedProviders) Supported(provider string) bool {
+	for _, v := range sp {
+		if v == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// GitProviderName detects the provider from the host of the url.  Providers
+// hosted elsewhere (eg. GitHub Enterprise, or a self-hosted Gitea) are only
+// detected if their host name contains the name of the provider, and need to
+// be selected explicitly otherwise.
 func GitProviderName(url string) (string, error) {
+	host := url
+	if u, err := parseURL(url); err == nil {
+		host = u.Host
+	}
+	host = strings.ToLower(host)
 	switch {
-	case strings.Contains(url, "github"):
+	case strings.Contains(host, "github"):
 		return GitHubProvider, nil
-	case strings.Contains(url, "gitlab"):
+	case strings.Contains(host, "gitlab"):
 		return GitLabProvider, nil
-	case strings.Contains(url, "bitbucket-cloud"):
-		//return BitBucketProvider, nil
+	case strings.Contains(host, "gitea"), strings.Contains(host, "forgejo"), strings.Contains(host, "codeberg"):
+		return GiteaProvider, nil
+	case host == "bitbucket.org":
+		return BitBucketProvider, nil
+	case strings.Contains(host, "bitbucket"):
+		return BitBucketServerProvider, nil
 	}
-	return "", fmt.Errorf("runtime for url %q is not supported, please use one of supported runtimes: %s", url, SupportedProvidersList.PrettyString())
+	return "", fmt.Errorf("provider for url %q cannot be detected, please use one of supported providers: %s", url, SupportedProvidersList.PrettyString())
 }
 
 // RepoOwnerAndNameFromUrl for input url returns repo owner and repo name
@@ -71,42 +98,138 @@ func RepoOwnerAndNameFromUrl(url string) (string, string, error) {
 	return repoOwner, repoName, nil
 }
 
-func CreateWebHook(ctx context.Context, gitRepoURL, webHookTarget, webHookSecret, personalAccessToken string) error {
-	providerName, err := GitProviderName(gitRepoURL)
+// ProviderURL returns the URL of the instance of the provider hosting the
+// repository, as expected by Pipelines as Code, or an empty string for the
+// public instances of GitHub, GitLab and Bitbucket Cloud.
+func ProviderURL(provider, gitRepoURL string) (string, error) {
+	u, err := parseURL(gitRepoURL)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case provider == GitHubProvider && u.Host == "github.com",
+		provider == GitLabProvider && u.Host == "gitlab.com",
+		provider == BitBucketProvider:
+		return "", nil
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// Remote is a repository on a Git provider, with the credentials used to
+// manage its webhooks.
+type Remote struct {
+	// Provider of the repository, detected from its URL if empty.
+	Provider string
+	// URL of the repository.
+	URL string
+	// User of the credentials, required by the Bitbucket providers unless
+	// the token is an access token of the repository.
+	User string
+	// PersonalAccessToken permitted to manage the webhooks of the repository.
+	PersonalAccessToken string
+}
+
+// CreateWebHook creates a webhook on the repository which sends its events to
+// the target, unless one sending them there already exists.  Returns the
+// ID of the webhook, with which it may be deleted.
+func CreateWebHook(ctx context.Context, r Remote, webHookTarget, webHookSecret string) (string, error) {
+	cli, repoOwner, repoName, err := newProviderClient(r)
+	if err != nil {
+		return "", err
+	}
+	id, err := cli.CreateWebHook(ctx, repoOwner, repoName, webHookTarget, webHookSecret)
+	if err != nil {
+		return "", fmt.Errorf("cannot create web hook: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteWebHook deletes the webhook of the repository with the given ID.
+// Deleting a webhook which no longer exists is not an error.
+func DeleteWebHook(ctx context.Context, r Remote, id string) error {
+	cli, repoOwner, repoName, err := newProviderClient(r)
 	if err != nil {
 		return err
 	}
+	if err = cli.DeleteWebHook(ctx, repoOwner, repoName, id); err != nil {
+		return fmt.Errorf("cannot delete web hook: %w", err)
+	}
+	return nil
+}
 
-	u, err := url.Parse(gitRepoURL)
+func newProviderClient(r Remote) (cli providerClient, repoOwner, repoName string, err error) {
+	providerName := r.Provider
+	if providerName == "" {
+		if providerName, err = GitProviderName(r.URL); err != nil {
+			return
+		}
+	}
+
+	u, err := parseURL(r.URL)
 	if err != nil {
-		return fmt.Errorf("cannot parse git repo url: %w", err)
+		return nil, "", "", fmt.Errorf("cannot parse git repo url: %w", err)
+	}
+	baseURL := u.Scheme + "://" + u.Host
+
+	repoURL := r.URL
+	if providerName == BitBucketServerProvider {
+		// clone urls of Bitbucket Server are of the form /scm/<project>/<repo>
+		repoURL = baseURL + strings.TrimPrefix(u.Path, "/scm")
+	}
+	if repoOwner, repoName, err = RepoOwnerAndNameFromUrl(repoURL); err != nil {
+		return
 	}
 
-	var cli providerClient
 	switch providerName {
 	case GitHubProvider:
-		cli = github.Client{
-			PersonalAccessToken: personalAccessToken,
+		c := github.Client{PersonalAccessToken: r.PersonalAccessToken}
+		if u.Host != "github.com" { // GitHub Enterprise
+			c.BaseURL = baseURL + "/api/v3/"
 		}
+		cli = c
 	case GitLabProvider:
 		cli = gitlab.Client{
-			BaseURL:             u.Scheme + "://" + u.Host,
-			PersonalAccessToken: personalAccessToken,
+			BaseURL:             baseURL,
+			PersonalAccessToken: r.PersonalAccessToken,
+		}
+	case GiteaProvider:
+		cli = gitea.Client{
+			BaseURL:             baseURL,
+			PersonalAccessToken: r.PersonalAccessToken,
 		}
+	case BitBucketProvider:
+		cli = bitbucket.CloudClient{
+			User:                r.User,
+			PersonalAccessToken: r.PersonalAccessToken,
+		}
+	case BitBucketServerProvider:
+		cli = bitbucket.ServerClient{
+			BaseURL:             baseURL,
+			User:                r.User,
+			PersonalAccessToken: r.PersonalAccessToken,
+		}
+	default:
+		err = fmt.Errorf("provider %q is not supported, please use one of supported providers: %s", providerName, SupportedProvidersList.PrettyString())
 	}
+	return
+}
 
-	repoOwner, repoName, err := RepoOwnerAndNameFromUrl(gitRepoURL)
-	if err != nil {
-		return err
+// parseURL of a repository, which may lack a scheme.
+func parseURL(gitRepoURL string) (*url.URL, error) {
+	if gitRepoURL != "" && !strings.Contains(gitRepoURL, "://") {
+		gitRepoURL = "https://" + gitRepoURL
 	}
-
-	err = cli.CreateWebHook(ctx, repoOwner, repoName, webHookTarget, webHookSecret)
+	u, err := url.Parse(gitRepoURL)
 	if err != nil {
-		return fmt.Errorf("cannot create web hook: %w", err)
+		return nil, err
 	}
-	return nil
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in url %q", gitRepoURL)
+	}
+	return u, nil
 }
 
 type providerClient interface {
-	CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) error
+	CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) (id string, err error)
+	DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error
 }
@@ -1,6 +1,13 @@
 package git
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
 
 func TestGetRepoOwnerFromGHURL(t *testing.T) {
 	tests := []struct {
@@ -74,10 +81,34 @@ func TestGitProviderName(t *testing.T) {
 			wantErr:      false,
 		},
 		{
-			name: "Bitbucket Cloud - not supported",
-			url:  "https://bitbucket.com/foo/bar",
-			//wantProvider: BitBucketProvider,
-			wantErr: true,
+			name:         "Bitbucket Cloud",
+			url:          "https://bitbucket.org/foo/bar",
+			wantProvider: BitBucketProvider,
+			wantErr:      false,
+		},
+		{
+			name:         "Bitbucket Server",
+			url:          "https://bitbucket.example.com/scm/foo/bar.git",
+			wantProvider: BitBucketServerProvider,
+			wantErr:      false,
+		},
+		{
+			name:         "Gitea",
+			url:          "https://gitea.example.com/foo/bar",
+			wantProvider: GiteaProvider,
+			wantErr:      false,
+		},
+		{
+			name:         "Forgejo on Codeberg",
+			url:          "https://codeberg.org/foo/bar",
+			wantProvider: GiteaProvider,
+			wantErr:      false,
+		},
+		{
+			name:         "Provider in the path - not detected",
+			url:          "https://git.example.com/github/bar",
+			wantProvider: "",
+			wantErr:      true,
 		},
 		{
 			name:         "Foo provider - not supported",
@@ -105,3 +136,102 @@ func TestGitProviderName(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderURL(t *testing.T) {
+	tests := []struct {
+		provider string
+		url      string
+		want     string
+	}{
+		{GitHubProvider, "https://github.com/foo/bar", ""},
+		{GitHubProvider, "https://github.example.com/foo/bar", "https://github.example.com"},
+		{GitLabProvider, "https://gitlab.com/foo/bar", ""},
+		{GitLabProvider, "http://gitlab.example.com:8080/foo/bar", "http://gitlab.example.com:8080"},
+		{GiteaProvider, "https://codeberg.org/foo/bar", "https://codeberg.org"},
+		{BitBucketProvider, "https://bitbucket.org/foo/bar", ""},
+		{BitBucketServerProvider, "https://bitbucket.example.com/scm/foo/bar.git", "https://bitbucket.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := ProviderURL(tt.provider, tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ProviderURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWebHook ensures webhooks are created and deleted through the API of the
+// selected provider, at the host of the repository.
+func TestWebHook(t *testing.T) {
+	tests := []struct {
+		provider string
+		repoPath string
+		hooks    string // path of the hooks of the repository
+	}{
+		{GitHubProvider, "/foo/bar", "/api/v3/repos/foo/bar/hooks"},
+		{GitLabProvider, "/foo/bar", "/api/v4/projects/foo/bar/hooks"},
+		{GiteaProvider, "/foo/bar.git", "/api/v1/repos/foo/bar/hooks"},
+		{BitBucketServerProvider, "/scm/foo/bar.git", "/rest/api/1.0/projects/foo/repos/bar/webhooks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var created, deleted bool
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == tt.hooks:
+					if tt.provider == BitBucketServerProvider {
+						_, _ = w.Write([]byte(`{"values":[]}`))
+					} else {
+						_, _ = w.Write([]byte(`[]`))
+					}
+				case r.Method == http.MethodPost && r.URL.Path == tt.hooks:
+					created = true
+					w.WriteHeader(http.StatusCreated)
+					_ = json.NewEncoder(w).Encode(map[string]any{"id": 42})
+				case r.Method == http.MethodDelete && r.URL.Path == tt.hooks+"/42":
+					deleted = true
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					t.Errorf("unexpected request %v %v", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+
+			remote := Remote{
+				Provider:            tt.provider,
+				URL:                 srv.URL + tt.repoPath,
+				User:                "alice",
+				PersonalAccessToken: "token",
+			}
+			id, err := CreateWebHook(context.Background(), remote, "https://pac.example.com", "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !created || id != "42" {
+				t.Fatalf("expected webhook 42 to be created, got %q", id)
+			}
+			if err = DeleteWebHook(context.Background(), remote, id); err != nil {
+				t.Fatal(err)
+			}
+			if !deleted {
+				t.Fatal("expected webhook to be deleted")
+			}
+		})
+	}
+}
+
+// TestWebHook_UnsupportedProvider ensures an explicitly selected provider is
+// validated.
+func TestWebHook_UnsupportedProvider(t *testing.T) {
+	remote := Remote{Provider: "sourcehut", URL: "https://git.sr.ht/foo/bar"}
+	_, err := CreateWebHook(context.Background(), remote, "https://pac.example.com", "secret")
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("expected an unsupported provider error, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Package gitea manages the webhooks of repositories on Gitea, and on
+// Forgejo, which retains the API of Gitea.
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"knative.dev/func/pkg/git/internal/rest"
+)
+
+type Client struct {
+	// BaseURL of the Gitea instance, eg. https://gitea.example.com
+	BaseURL             string
+	PersonalAccessToken string
+}
+
+type hook struct {
+	ID     int64             `json:"id,omitempty"`
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+	Events []string          `json:"events"`
+	Active bool              `json:"active"`
+}
+
+func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) (string, error) {
+	// The hooks are listed a page at a time, following the Link to the next.
+	for next := c.url(c.hooksPath(repoOwner, repoName) + "?limit=50"); next != ""; {
+		var hooks []hook
+		header, err := rest.Do(ctx, http.MethodGet, next, c.authorize, nil, &hooks)
+		if err != nil {
+			return "", fmt.Errorf("cannot list gitea webhooks: %w", err)
+		}
+		for _, h := range hooks {
+			if h.Config["url"] == payloadURL {
+				return strconv.FormatInt(h.ID, 10), nil
+			}
+		}
+		next = rest.NextLink(header)
+	}
+
+	created := hook{
+		Type: "gitea",
+		Config: map[string]string{
+			"url":          payloadURL,
+			"content_type": "json",
+			"secret":       webhookSecret,
+		},
+		Events: []string{"push", "pull_request", "issue_comment"},
+		Active: true,
+	}
+	if _, err := rest.Do(ctx, http.MethodPost, c.url(c.hooksPath(repoOwner, repoName)), c.authorize, created, &created); err != nil {
+		return "", fmt.Errorf("cannot create gitea webhook: %w", err)
+	}
+	return strconv.FormatInt(created.ID, 10), nil
+}
+
+func (c Client) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return fmt.Errorf("invalid webhook id %q: %w", id, err)
+	}
+	_, err := rest.Do(ctx, http.MethodDelete, c.url(c.hooksPath(repoOwner, repoName)+"/"+id), c.authorize, nil, nil)
+	if err != nil && !rest.IsNotFound(err) {
+		return fmt.Errorf("cannot delete gitea webhook: %w", err)
+	}
+	return nil
+}
+
+func (c Client) hooksPath(repoOwner, repoName string) string {
+	return fmt.Sprintf("/api/v1/repos/%s/%s/hooks", url.PathEscape(repoOwner), url.PathEscape(repoName))
+}
+
+// url of the given path of the API.
+func (c Client) url(path string) string {
+	return strings.TrimSuffix(c.BaseURL, "/") + path
+}
+
+func (c Client) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "token "+c.PersonalAccessToken)
+}
@@ -0,0 +1,166 @@
+package gitea_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"knative.dev/func/pkg/git/gitea"
+)
+
+// hooksServer stands in for the hooks API of the repository foo/bar.
+type hooksServer struct {
+	mu     sync.Mutex
+	nextID int
+	hooks  map[string]map[string]any
+}
+
+func (s *hooksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "token secret-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	const prefix = "/api/v1/repos/foo/bar/hooks"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == prefix:
+		// paginated by ID, with a Link to the next page
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		hooks := []map[string]any{}
+		for id := 1; id <= s.nextID; id++ {
+			if h, ok := s.hooks[strconv.Itoa(id)]; ok {
+				hooks = append(hooks, h)
+			}
+		}
+		if limit > 0 && len(hooks) > page*limit {
+			next := *r.URL
+			next.Scheme, next.Host = "http", r.Host
+			next.RawQuery = url.Values{"limit": {strconv.Itoa(limit)}, "page": {strconv.Itoa(page + 1)}}.Encode()
+			w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+			hooks = hooks[:page*limit]
+		}
+		if limit > 0 {
+			hooks = hooks[min((page-1)*limit, len(hooks)):]
+		}
+		_ = json.NewEncoder(w).Encode(hooks)
+	case r.Method == http.MethodPost && r.URL.Path == prefix:
+		var h map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.nextID++
+		h["id"] = s.nextID
+		s.hooks[strconv.Itoa(s.nextID)] = h
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(h)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, prefix+"/"):
+		id := strings.TrimPrefix(r.URL.Path, prefix+"/")
+		if _, ok := s.hooks[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(s.hooks, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// TestClient_WebHook ensures a webhook is created once, with the secret, and
+// deleted by its ID.
+func TestClient_WebHook(t *testing.T) {
+	s := &hooksServer{hooks: map[string]map[string]any{}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := gitea.Client{BaseURL: srv.URL, PersonalAccessToken: "secret-token"}
+
+	id, err := c.CreateWebHook(ctx, "foo", "bar", "https://pac.example.com", "webhook-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook, ok := s.hooks[id]
+	if !ok {
+		t.Fatalf("webhook %q not created", id)
+	}
+	config := hook["config"].(map[string]any)
+	if config["url"] != "https://pac.example.com" || config["secret"] != "webhook-secret" {
+		t.Errorf("unexpected webhook config: %v", config)
+	}
+
+	// the existing webhook is reused
+	again, err := c.CreateWebHook(ctx, "foo", "bar", "https://pac.example.com", "webhook-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != id || len(s.hooks) != 1 {
+		t.Errorf("expected webhook %q to be reused, got %q (%d webhooks)", id, again, len(s.hooks))
+	}
+
+	if err = c.DeleteWebHook(ctx, "foo", "bar", id); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.hooks) != 0 {
+		t.Errorf("webhook not deleted")
+	}
+
+	// deleting a webhook which no longer exists is not an error
+	if err = c.DeleteWebHook(ctx, "foo", "bar", id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestClient_WebHookPaginated ensures that an existing webhook is found
+// beyond the first page of the webhooks of the repository.
+func TestClient_WebHookPaginated(t *testing.T) {
+	s := &hooksServer{hooks: map[string]map[string]any{}}
+	for i := 0; i < 60; i++ {
+		s.nextID++
+		s.hooks[strconv.Itoa(s.nextID)] = map[string]any{
+			"id":     s.nextID,
+			"config": map[string]any{"url": fmt.Sprintf("https://other%d.example.com", i)},
+		}
+	}
+	s.nextID++
+	s.hooks[strconv.Itoa(s.nextID)] = map[string]any{
+		"id":     s.nextID,
+		"config": map[string]any{"url": "https://pac.example.com"},
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	c := gitea.Client{BaseURL: srv.URL, PersonalAccessToken: "secret-token"}
+	id, err := c.CreateWebHook(context.Background(), "foo", "bar", "https://pac.example.com", "webhook-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "61" || len(s.hooks) != 61 {
+		t.Errorf("expected webhook 61 to be reused, got %q (%d webhooks)", id, len(s.hooks))
+	}
+}
+
+// TestClient_Unauthorized ensures errors of the API are returned.
+func TestClient_Unauthorized(t *testing.T) {
+	srv := httptest.NewServer(&hooksServer{hooks: map[string]map[string]any{}})
+	defer srv.Close()
+
+	c := gitea.Client{BaseURL: srv.URL, PersonalAccessToken: "invalid"}
+	if _, err := c.CreateWebHook(context.Background(), "foo", "bar", "https://pac.example.com", ""); err == nil {
+		t.Fatal("expected an error with an invalid token")
+	}
+}
@@ -5,16 +5,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/google/go-github/v49/github"
 	"golang.org/x/oauth2"
 )
 
 type Client struct {
+	// BaseURL of the API of GitHub Enterprise, empty for github.com
+	BaseURL             string
 	PersonalAccessToken string
 }
 
-func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) error {
+func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) (string, error) {
 	hook := &github.Hook{
 		Name:   github.String("web"),
 		Active: github.Bool(true),
@@ -31,26 +34,55 @@ func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadU
 		},
 	}
 
-	ghClient, err := newGHClientByToken(ctx, c.PersonalAccessToken, "")
+	ghClient, err := newGHClientByToken(ctx, c.PersonalAccessToken, c.BaseURL)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	_, res, err := ghClient.Repositories.CreateHook(ctx, repoOwner, repoName, hook)
+	// GitHub refuses to create a second hook with the same URL
+	hooks, _, err := ghClient.Repositories.ListHooks(ctx, repoOwner, repoName, &github.ListOptions{PerPage: 100})
 	if err != nil {
-		return err
+		return "", err
+	}
+	for _, h := range hooks {
+		if h.Config["url"] == payloadURL {
+			return strconv.FormatInt(h.GetID(), 10), nil
+		}
+	}
+
+	created, res, err := ghClient.Repositories.CreateHook(ctx, repoOwner, repoName, hook)
+	if err != nil {
+		return "", err
 	}
 
 	if res.Response.StatusCode != http.StatusCreated {
 		payload, err := io.ReadAll(res.Body)
 		if err != nil {
-			return fmt.Errorf("failed to read response body: %w", err)
+			return "", fmt.Errorf("failed to read response body: %w", err)
 		}
 
-		return fmt.Errorf("failed to create webhook on repository %v/%v, status code: %v, error : %v",
+		return "", fmt.Errorf("failed to create webhook on repository %v/%v, status code: %v, error : %v",
 			repoOwner, repoName, res.Response.StatusCode, payload)
 	}
 
+	return strconv.FormatInt(created.GetID(), 10), nil
+}
+
+func (c Client) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	hookID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook id %q: %w", id, err)
+	}
+
+	ghClient, err := newGHClientByToken(ctx, c.PersonalAccessToken, c.BaseURL)
+	if err != nil {
+		return err
+	}
+
+	res, err := ghClient.Repositories.DeleteHook(ctx, repoOwner, repoName, hookID)
+	if err != nil && (res == nil || res.StatusCode != http.StatusNotFound) {
+		return err
+	}
 	return nil
 }
 
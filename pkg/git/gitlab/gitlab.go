@@ -3,6 +3,8 @@ package gitlab
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
 
 	"github.com/xanzy/go-gitlab"
 )
@@ -12,25 +14,63 @@ type Client struct {
 	PersonalAccessToken string
 }
 
-func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) error {
+func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) (string, error) {
 	t := true
 	f := false
-	glabCli, err := gitlab.NewClient(c.PersonalAccessToken,
-		gitlab.WithBaseURL(c.BaseURL),
-		gitlab.WithRequestOptions(gitlab.WithContext(ctx)))
+	glabCli, err := c.newClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// GitLab doesn't name WebHooks, so a hook with the same URL is looked up
+	// instead of creating a duplicate.
+	hooks, _, err := glabCli.Projects.ListProjectHooks(repoOwner+"/"+repoName, &gitlab.ListProjectHooksOptions{PerPage: 100})
 	if err != nil {
-		return fmt.Errorf("cannot create GitLab client: %w", err)
+		return "", fmt.Errorf("cannot list gitlab webhooks: %w", err)
 	}
+	for _, h := range hooks {
+		if h.URL == payloadURL {
+			return strconv.Itoa(h.ID), nil
+		}
+	}
+
 	webhook := &gitlab.AddProjectHookOptions{
 		EnableSSLVerification: &f,
 		PushEvents:            &t,
+		MergeRequestsEvents:   &t,
+		NoteEvents:            &t,
 		Token:                 &webhookSecret,
 		URL:                   &payloadURL,
 	}
-	// TODO check if the WebHook already exists. GitLab doesn't name WebHooks so there is never 403.
-	_, _, err = glabCli.Projects.AddProjectHook(repoOwner+"/"+repoName, webhook)
+	hook, _, err := glabCli.Projects.AddProjectHook(repoOwner+"/"+repoName, webhook)
+	if err != nil {
+		return "", fmt.Errorf("cannot create gitlab webhook: %w", err)
+	}
+	return strconv.Itoa(hook.ID), nil
+}
+
+func (c Client) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	hookID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook id %q: %w", id, err)
+	}
+	glabCli, err := c.newClient(ctx)
 	if err != nil {
-		return fmt.Errorf("cannot create gitlab webhook: %w", err)
+		return err
+	}
+	res, err := glabCli.Projects.DeleteProjectHook(repoOwner+"/"+repoName, hookID)
+	if err != nil && (res == nil || res.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("cannot delete gitlab webhook: %w", err)
 	}
 	return nil
 }
+
+func (c Client) newClient(ctx context.Context) (*gitlab.Client, error) {
+	glabCli, err := gitlab.NewClient(c.PersonalAccessToken,
+		gitlab.WithBaseURL(c.BaseURL),
+		gitlab.WithRequestOptions(gitlab.WithContext(ctx)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create GitLab client: %w", err)
+	}
+	return glabCli, nil
+}
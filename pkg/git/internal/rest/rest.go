@@ -0,0 +1,87 @@
+// Package rest sends the requests of the JSON APIs of the git providers
+// whose webhooks are managed.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StatusError is returned for unsuccessful responses of the API.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound returns true if the API responded that the resource does not
+// exist.
+func IsNotFound(err error) bool {
+	var se StatusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusNotFound
+}
+
+// Do sends the request, authorized by authorize, with the body encoded from
+// in and the response decoded into out if they are not nil.  Returned are
+// the headers of the response, such as the Link to its next page.
+func Do(ctx context.Context, method, url string, authorize func(*http.Request), in, out any) (http.Header, error) {
+	var body io.Reader
+	if in != nil {
+		bb, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(bb)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	authorize(req)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		bb, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return res.Header, StatusError{StatusCode: res.StatusCode, Body: strings.TrimSpace(string(bb))}
+	}
+	if out == nil {
+		return res.Header, nil
+	}
+	return res.Header, json.NewDecoder(res.Body).Decode(out)
+}
+
+// NextLink returns the URL of the next page of a paginated response from its
+// Link header (RFC 8288), or an empty string if it is the last page.
+func NextLink(h http.Header) string {
+	for _, v := range h.Values("Link") {
+		for _, link := range strings.Split(v, ",") {
+			target, params, ok := strings.Cut(link, ";")
+			if !ok {
+				continue
+			}
+			for _, p := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+				if name == "rel" && strings.Trim(value, `"`) == "next" {
+					return strings.Trim(strings.TrimSpace(target), "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
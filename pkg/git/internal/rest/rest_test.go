@@ -0,0 +1,27 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestNextLink ensures the URL of the next page is taken from the Link header.
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		Link     string
+		Expected string
+	}{
+		{"", ""},
+		{`<https://gitea.example.com/api/v1/repos/foo/bar/hooks?limit=50&page=2>; rel="next", <https://gitea.example.com/api/v1/repos/foo/bar/hooks?limit=50&page=3>; rel="last"`, "https://gitea.example.com/api/v1/repos/foo/bar/hooks?limit=50&page=2"},
+		{`<https://gitea.example.com/api/v1/repos/foo/bar/hooks?limit=50&page=1>; rel="first", <https://gitea.example.com/api/v1/repos/foo/bar/hooks?limit=50&page=2>; rel="prev"`, ""},
+	}
+	for _, test := range tests {
+		h := http.Header{}
+		if test.Link != "" {
+			h.Set("Link", test.Link)
+		}
+		if next := NextLink(h); next != test.Expected {
+			t.Errorf("expected next %q of %q, got %q", test.Expected, test.Link, next)
+		}
+	}
+}
@@ -4,6 +4,7 @@
 package http_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -12,7 +13,7 @@ import (
 )
 
 func TestRoundTripper(t *testing.T) {
-	if !k8s.IsOpenShift() {
+	if !k8s.IsOpenShift(context.Background()) {
 		t.Skip("The cluster in not an instance of OpenShift.")
 		return
 	}
@@ -56,7 +56,7 @@ func WithInsecureSkipVerify(insecureSkipVerify bool) Option {
 // This is useful for accessing cluster internal services (pushing a CloudEvent into Knative broker).
 func NewRoundTripper(opts ...Option) RoundTripCloser {
 	o := options{
-		inClusterDialer:    k8s.NewLazyInitInClusterDialer(k8s.GetClientConfig()),
+		inClusterDialer:    k8s.NewLazyInitInClusterDialer(nil),
 		insecureSkipVerify: false,
 	}
 	for _, option := range opts {
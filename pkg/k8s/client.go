@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/client-go/dynamic"
@@ -9,21 +10,38 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-func NewClientAndResolvedNamespace(ns string) (*kubernetes.Clientset, string, error) {
+// KubeContextKey is the key of the context value naming the kubeconfig
+// context the clients are created for.
+type KubeContextKey struct{}
+
+// WithKubeContext returns a copy of ctx selecting the named kubeconfig
+// context.  The zero value selects the kubeconfig's current context.
+func WithKubeContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, KubeContextKey{}, name)
+}
+
+// KubeContext returns the name of the kubeconfig context selected by ctx,
+// or the zero value if the current context is to be used.
+func KubeContext(ctx context.Context) string {
+	name, _ := ctx.Value(KubeContextKey{}).(string)
+	return name
+}
+
+func NewClientAndResolvedNamespace(ctx context.Context, ns string) (*kubernetes.Clientset, string, error) {
 	var err error
 	if ns == "" {
-		ns, err = GetDefaultNamespace()
+		ns, err = GetDefaultNamespace(ctx)
 		if err != nil {
 			return nil, ns, err
 		}
 	}
 
-	client, err := NewKubernetesClientset()
+	client, err := NewKubernetesClientset(ctx)
 	return client, ns, err
 }
 
-func NewKubernetesClientset() (*kubernetes.Clientset, error) {
-	restConfig, err := GetClientConfig().ClientConfig()
+func NewKubernetesClientset(ctx context.Context) (*kubernetes.Clientset, error) {
+	restConfig, err := GetClientConfig(ctx).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new kubernetes client: %w", err)
 	}
@@ -31,8 +49,8 @@ func NewKubernetesClientset() (*kubernetes.Clientset, error) {
 	return kubernetes.NewForConfig(restConfig)
 }
 
-func NewDynamicClient() (dynamic.Interface, error) {
-	restConfig, err := GetClientConfig().ClientConfig()
+func NewDynamicClient(ctx context.Context) (dynamic.Interface, error) {
+	restConfig, err := GetClientConfig(ctx).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new kubernetes client: %w", err)
 	}
@@ -41,13 +59,15 @@ func NewDynamicClient() (dynamic.Interface, error) {
 }
 
 // GetDefaultNamespace returns default namespace
-func GetDefaultNamespace() (namespace string, err error) {
-	namespace, _, err = GetClientConfig().Namespace()
+func GetDefaultNamespace(ctx context.Context) (namespace string, err error) {
+	namespace, _, err = GetClientConfig(ctx).Namespace()
 	return
 }
 
-func GetClientConfig() clientcmd.ClientConfig {
+// GetClientConfig returns the client config of the kubeconfig context
+// selected by ctx.
+func GetClientConfig(ctx context.Context) clientcmd.ClientConfig {
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		clientcmd.NewDefaultClientConfigLoadingRules(),
-		&clientcmd.ConfigOverrides{})
+		&clientcmd.ConfigOverrides{CurrentContext: KubeContext(ctx)})
 }
@@ -7,13 +7,14 @@ import (
 	"syscall"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclientcmd "k8s.io/client-go/tools/clientcmd"
 )
 
 func GetConfigMap(ctx context.Context, name, namespaceOverride string) (*corev1.ConfigMap, error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +55,7 @@ func ListConfigMapsNamesIfConnected(ctx context.Context, namespaceOverride strin
 }
 
 func listConfigMapsNames(ctx context.Context, namespaceOverride string) (names []string, err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -70,3 +71,39 @@ func listConfigMapsNames(ctx context.Context, namespaceOverride string) (names [
 
 	return
 }
+
+func DeleteConfigMaps(ctx context.Context, namespaceOverride string, listOptions metav1.ListOptions) (err error) {
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
+	if err != nil {
+		return
+	}
+
+	return client.CoreV1().ConfigMaps(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOptions)
+}
+
+func EnsureConfigMapExist(ctx context.Context, configMap corev1.ConfigMap, namespaceOverride string) (err error) {
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
+	if err != nil {
+		return
+	}
+
+	// Check whether ConfigMap with specified name exist
+	configMapNotFound := false
+	existing, err := GetConfigMap(ctx, configMap.Name, namespace)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return
+		}
+		configMapNotFound = true
+	}
+
+	if configMapNotFound {
+		_, err = client.CoreV1().ConfigMaps(namespace).Create(ctx, &configMap, metav1.CreateOptions{})
+	} else if !equality.Semantic.DeepEqual(existing.Data, configMap.Data) ||
+		!equality.Semantic.DeepDerivative(configMap.Labels, existing.Labels) {
+		configMap.ResourceVersion = existing.ResourceVersion
+		_, err = client.CoreV1().ConfigMaps(namespace).Update(ctx, &configMap, metav1.UpdateOptions{})
+	}
+
+	return
+}
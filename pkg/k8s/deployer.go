@@ -0,0 +1,657 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/func/pkg/deployers"
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+const (
+	// FunctionContainerName is the name of the function's container.
+	FunctionContainerName = "user-container"
+
+	// DefaultDeployTimeout is the time a deployed function may take to become
+	// available unless the function defines a start timeout.
+	DefaultDeployTimeout = 120 * time.Second
+
+	// FunctionPort on which the function listens.
+	FunctionPort = 8080
+
+	// defaultTargetUtilization is the CPU utilization (percent) at which the
+	// function is scaled unless it defines its own.
+	defaultTargetUtilization = 80
+)
+
+// httpRouteResource is the Gateway API HTTPRoute, which is accessed with a
+// dynamic client such that the Gateway API need not be installed.
+var httpRouteResource = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+
+type DeployDecorator interface {
+	UpdateAnnotations(fn.Function, map[string]string) map[string]string
+	UpdateLabels(fn.Function, map[string]string) map[string]string
+}
+
+type DeployerOpt func(*Deployer)
+
+// Deployer of functions as a plain Kubernetes Deployment and Service, for
+// clusters without Knative Serving.  A HorizontalPodAutoscaler is created
+// when the function defines a maximum scale, and an Ingress or HTTPRoute
+// when it is exposed (see fn.Expose).
+type Deployer struct {
+	// verbose logging enablement flag.
+	verbose bool
+
+	decorator DeployDecorator
+
+	// secretsKeyFile is the key used to decrypt the secrets managed with
+	// the function.
+	secretsKeyFile string
+}
+
+func NewDeployer(opts ...DeployerOpt) *Deployer {
+	d := &Deployer{}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+func WithDeployerVerbose(verbose bool) DeployerOpt {
+	return func(d *Deployer) {
+		d.verbose = verbose
+	}
+}
+
+func WithDeployerDecorator(decorator DeployDecorator) DeployerOpt {
+	return func(d *Deployer) {
+		d.decorator = decorator
+	}
+}
+
+// WithDeployerSecretsKey sets the path to the key used to decrypt the
+// secrets managed with the function (see fn.ResourcesDir).
+func WithDeployerSecretsKey(keyFile string) DeployerOpt {
+	return func(d *Deployer) {
+		d.secretsKeyFile = keyFile
+	}
+}
+
+func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
+	// The target namespace is that requested, or that to which the function
+	// was last deployed, or the current context's.
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if namespace == "" {
+		namespace, _ = GetDefaultNamespace(ctx)
+	}
+	if namespace == "" {
+		return fn.DeploymentResult{}, fmt.Errorf("deployer requires either a target namespace or that the function be already deployed.")
+	}
+
+	client, err := NewKubernetesClientset(ctx)
+	if err != nil {
+		return fn.DeploymentResult{}, err
+	}
+	dynamicClient, err := NewDynamicClient(ctx)
+	if err != nil {
+		return fn.DeploymentResult{}, err
+	}
+
+	referencedSecrets := sets.New[string]()
+	referencedConfigMaps := sets.New[string]()
+	referencedPVCs := sets.New[string]()
+
+	deployment, err := generateDeployment(f, namespace, d.decorator, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+	if err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("kubernetes deployer failed to generate the Deployment: %v", err)
+	}
+
+	if err = EnsureFunctionResources(ctx, f, namespace, d.secretsKeyFile); err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("kubernetes deployer failed to create the function's resources: %v", err)
+	}
+	if err = CheckResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName); err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("kubernetes deployer failed to generate the Deployment: %v", err)
+	}
+
+	// Deployment
+	status := fn.Deployed
+	deployments := client.AppsV1().Deployments(namespace)
+	previous, err := deployments.Get(ctx, f.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		deployment, err = deployments.Create(ctx, deployment, metav1.CreateOptions{})
+	} else if err == nil {
+		if !owned(previous) {
+			return fn.DeploymentResult{}, errNotOwned("Deployment", previous)
+		}
+		status = fn.Updated
+		deployment.ResourceVersion = previous.ResourceVersion
+		if scaled(f) {
+			// The autoscaler owns the number of replicas
+			deployment.Spec.Replicas = previous.Spec.Replicas
+		}
+		deployment, err = deployments.Update(ctx, deployment, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("kubernetes deployer failed to deploy the Deployment: %v", err)
+	}
+
+	// Service, autoscaler and routes
+	if err = applyService(ctx, client, generateService(f, namespace, d.decorator)); err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("kubernetes deployer failed to deploy the Service: %v", err)
+	}
+	if err = applyHorizontalPodAutoscaler(ctx, client, f, namespace); err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("kubernetes deployer failed to deploy the HorizontalPodAutoscaler: %v", err)
+	}
+	if err = applyExpose(ctx, client, dynamicClient, f, namespace, d.decorator); err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("kubernetes deployer failed to expose the function: %v", err)
+	}
+
+	if d.verbose {
+		fmt.Fprintln(os.Stderr, "Waiting for Deployment to become available")
+	}
+	timeout := DefaultDeployTimeout
+	if f.Run.StartTimeout > 0 {
+		timeout = f.Run.StartTimeout
+	}
+	if err = waitForDeployment(ctx, client, deployment, timeout); err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("kubernetes deployer failed to wait for the Deployment to become available: %v", err)
+	}
+
+	url := functionURL(ctx, client, dynamicClient, f.Name, namespace)
+	if d.verbose {
+		fmt.Fprintf(os.Stderr, "Function deployed in namespace %q and exposed at URL:\n%s\n", namespace, url)
+	}
+	return fn.DeploymentResult{
+		Status:    status,
+		URL:       url,
+		Namespace: namespace,
+	}, nil
+}
+
+// owned returns whether the existing object is that of a function deployed
+// by this deployer.  Objects of the same name which are not are neither
+// updated nor removed (see errNotOwned).
+func owned(previous metav1.Object) bool {
+	return previous.GetLabels()[fnlabels.FunctionPlatformKey] == deployers.Kubernetes
+}
+
+// errNotOwned is returned when an object of the function would replace an
+// existing one which is not owned.
+func errNotOwned(kind string, previous metav1.Object) error {
+	return fmt.Errorf("kubernetes deployer found an existing %v %q in namespace %q which is not that of a function", kind, previous.GetName(), previous.GetNamespace())
+}
+
+// selectorLabels identify the pods of the function.  Unlike its other labels
+// they may not change, and distinguish them from those of the function
+// deployed to another platform.
+func selectorLabels(name string) map[string]string {
+	return map[string]string{
+		fnlabels.FunctionNameKey:     name,
+		fnlabels.FunctionPlatformKey: deployers.Kubernetes,
+	}
+}
+
+// generateLabels of the function's resources; its own labels plus those
+// identifying it, as decorated.
+func generateLabels(f fn.Function, decorator DeployDecorator) (map[string]string, error) {
+	ll, err := f.LabelsMap()
+	if err != nil {
+		return nil, err
+	}
+	if decorator != nil {
+		ll = decorator.UpdateLabels(f, ll)
+	}
+	for k, v := range selectorLabels(f.Name) {
+		ll[k] = v
+	}
+	return ll, nil
+}
+
+// generateAnnotations of the function's resources, as decorated.
+func generateAnnotations(f fn.Function, decorator DeployDecorator) map[string]string {
+	aa := make(map[string]string, len(f.Deploy.Annotations))
+	for k, v := range f.Deploy.Annotations {
+		aa[k] = v
+	}
+	if decorator != nil {
+		aa = decorator.UpdateAnnotations(f, aa)
+	}
+	return aa
+}
+
+// scaled returns true if the function is scaled by an autoscaler, which is
+// the case when it defines a maximum scale.
+func scaled(f fn.Function) bool {
+	return f.Deploy.Options.Scale != nil && f.Deploy.Options.Scale.Max != nil
+}
+
+// minScale of the function, which is at least one as a Deployment does not
+// scale to zero.
+func minScale(f fn.Function) int32 {
+	if s := f.Deploy.Options.Scale; s != nil && s.Min != nil && *s.Min > 1 {
+		return int32(*s.Min)
+	}
+	return 1
+}
+
+// defaultProbePorts sets the port of HTTP probes which do not define one to
+// the function's port. Unlike Knative, a Deployment does not fill it in.
+func defaultProbePorts(c *corev1.Container) {
+	for _, p := range []*corev1.Probe{c.LivenessProbe, c.ReadinessProbe, c.StartupProbe} {
+		if p != nil && p.HTTPGet != nil && p.HTTPGet.Port == (intstr.IntOrString{}) {
+			p.HTTPGet.Port = intstr.FromString("http")
+		}
+	}
+}
+
+func generateDeployment(f fn.Function, namespace string, decorator DeployDecorator, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string]) (*appsv1.Deployment, error) {
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
+	container := corev1.Container{
+		Name:  FunctionContainerName,
+		Image: f.Deploy.Image,
+		Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: FunctionPort}},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsNonRoot:             &runAsNonRoot,
+			AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+	}
+	SetHealthEndpoints(f, &container)
+	defaultProbePorts(&container)
+	if err := SetResources(&container, f.Deploy.Options.Resources); err != nil {
+		return nil, err
+	}
+
+	runEnvs, err := f.RunEnvs()
+	if err != nil {
+		return nil, err
+	}
+	container.Env, container.EnvFrom, err = ProcessEnvs(f.Root, runEnvs, referencedSecrets, referencedConfigMaps)
+	if err != nil {
+		return nil, err
+	}
+	volumes, volumeMounts, err := ProcessVolumes(f.Run.Volumes, referencedSecrets, referencedConfigMaps, referencedPVCs)
+	if err != nil {
+		return nil, err
+	}
+	container.VolumeMounts = volumeMounts
+
+	sidecars, initContainers, err := ProcessContainers(f, volumeMounts, referencedSecrets, referencedConfigMaps)
+	if err != nil {
+		return nil, err
+	}
+	// Unlike Knative, any container may declare its ports
+	for i, c := range f.Deploy.Sidecars {
+		for _, p := range c.Ports {
+			sidecars[i].Ports = append(sidecars[i].Ports, corev1.ContainerPort{ContainerPort: p})
+		}
+	}
+
+	labels, err := generateLabels(f, decorator)
+	if err != nil {
+		return nil, err
+	}
+	annotations := generateAnnotations(f, decorator)
+	replicas := minScale(f)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        f.Name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: selectorLabels(f.Name)},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers:         append([]corev1.Container{container}, sidecars...),
+					InitContainers:     initContainers,
+					ServiceAccountName: f.Deploy.ServiceAccountName,
+					Volumes:            volumes,
+				},
+			},
+		},
+	}
+	if f.Run.StartTimeout > 0 {
+		seconds := int32(f.Run.StartTimeout.Seconds())
+		deployment.Spec.ProgressDeadlineSeconds = &seconds
+	}
+	SetScheduling(&deployment.Spec.Template.Spec, f.Deploy.Scheduling)
+	return deployment, nil
+}
+
+func generateService(f fn.Function, namespace string, decorator DeployDecorator) *corev1.Service {
+	labels, _ := generateLabels(f, decorator) // validated when generating the Deployment
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.Name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selectorLabels(f.Name),
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Port:       80,
+				TargetPort: intstr.FromString("http"),
+			}},
+		},
+	}
+}
+
+func applyService(ctx context.Context, client *kubernetes.Clientset, service *corev1.Service) error {
+	services := client.CoreV1().Services(service.Namespace)
+	previous, err := services.Get(ctx, service.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = services.Create(ctx, service, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	if !owned(previous) {
+		return errNotOwned("Service", previous)
+	}
+	service.ResourceVersion = previous.ResourceVersion
+	service.Spec.ClusterIP = previous.Spec.ClusterIP
+	service.Spec.ClusterIPs = previous.Spec.ClusterIPs
+	_, err = services.Update(ctx, service, metav1.UpdateOptions{})
+	return err
+}
+
+// generateHorizontalPodAutoscaler of the function, scaling between its minimum
+// and maximum scale on CPU utilization.  The function's scale utilization, if
+// defined, is the target CPU utilization.  Returns nil if the function is not
+// scaled.
+func generateHorizontalPodAutoscaler(f fn.Function, namespace string) *autoscalingv2.HorizontalPodAutoscaler {
+	if !scaled(f) {
+		return nil
+	}
+	minReplicas := minScale(f)
+	maxReplicas := int32(*f.Deploy.Options.Scale.Max)
+	if maxReplicas < minReplicas {
+		maxReplicas = minReplicas
+	}
+	utilization := int32(defaultTargetUtilization)
+	if u := f.Deploy.Options.Scale.Utilization; u != nil {
+		utilization = int32(*u)
+	}
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.Name,
+			Namespace: namespace,
+			Labels:    selectorLabels(f.Name),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       f.Name,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: maxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: &utilization,
+					},
+				},
+			}},
+		},
+	}
+}
+
+// applyHorizontalPodAutoscaler creates or updates the function's autoscaler,
+// or removes it if the function is no longer scaled.
+func applyHorizontalPodAutoscaler(ctx context.Context, client *kubernetes.Clientset, f fn.Function, namespace string) error {
+	hpas := client.AutoscalingV2().HorizontalPodAutoscalers(namespace)
+	hpa := generateHorizontalPodAutoscaler(f, namespace)
+	previous, err := hpas.Get(ctx, f.Name, metav1.GetOptions{})
+	if hpa == nil && errors.IsForbidden(err) {
+		return nil // none may have been created
+	} else if errors.IsNotFound(err) {
+		if hpa == nil {
+			return nil
+		}
+		_, err = hpas.Create(ctx, hpa, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	if !owned(previous) {
+		if hpa == nil {
+			return nil // not the function's to remove
+		}
+		return errNotOwned("HorizontalPodAutoscaler", previous)
+	}
+	if hpa == nil {
+		return hpas.Delete(ctx, f.Name, metav1.DeleteOptions{})
+	}
+	hpa.ResourceVersion = previous.ResourceVersion
+	_, err = hpas.Update(ctx, hpa, metav1.UpdateOptions{})
+	return err
+}
+
+// generateIngress exposing the function at its host.  Returns nil if the
+// function is not exposed, or is exposed by a Gateway.
+func generateIngress(f fn.Function, namespace string, decorator DeployDecorator) *networkingv1.Ingress {
+	e := f.Deploy.Expose
+	if e.IsZero() || e.Gateway != "" {
+		return nil
+	}
+	pathType := networkingv1.PathTypePrefix
+	labels, _ := generateLabels(f, decorator)
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.Name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: e.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: f.Name,
+									Port: networkingv1.ServiceBackendPort{Name: "http"},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if e.IngressClassName != "" {
+		className := e.IngressClassName
+		ingress.Spec.IngressClassName = &className
+	}
+	if e.TLSSecret != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{e.Host}, SecretName: e.TLSSecret}}
+	}
+	return ingress
+}
+
+// generateHTTPRoute attaching the function to its Gateway.  Returns nil if
+// the function is not exposed by a Gateway.
+func generateHTTPRoute(f fn.Function, namespace string, decorator DeployDecorator) *unstructured.Unstructured {
+	e := f.Deploy.Expose
+	if e.Gateway == "" {
+		return nil
+	}
+	gatewayNamespace, gatewayName := e.GatewayRef()
+	parentRef := map[string]interface{}{"name": gatewayName}
+	if gatewayNamespace != "" {
+		parentRef["namespace"] = gatewayNamespace
+	}
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules": []interface{}{map[string]interface{}{
+			"backendRefs": []interface{}{map[string]interface{}{
+				"name": f.Name,
+				"port": int64(80),
+			}},
+		}},
+	}
+	if e.Host != "" {
+		spec["hostnames"] = []interface{}{e.Host}
+	}
+	labels, _ := generateLabels(f, decorator)
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": httpRouteResource.GroupVersion().String(),
+		"kind":       "HTTPRoute",
+		"spec":       spec,
+	}}
+	route.SetName(f.Name)
+	route.SetNamespace(namespace)
+	route.SetLabels(labels)
+	return route
+}
+
+// applyExpose creates or updates the function's Ingress or HTTPRoute, removing
+// either if no longer used.
+func applyExpose(ctx context.Context, client *kubernetes.Clientset, dynamicClient dynamic.Interface, f fn.Function, namespace string, decorator DeployDecorator) error {
+	ingresses := client.NetworkingV1().Ingresses(namespace)
+	ingress := generateIngress(f, namespace, decorator)
+	previous, err := ingresses.Get(ctx, f.Name, metav1.GetOptions{})
+	switch {
+	case (errors.IsNotFound(err) || errors.IsForbidden(err)) && ingress == nil:
+		err = nil
+	case errors.IsNotFound(err):
+		_, err = ingresses.Create(ctx, ingress, metav1.CreateOptions{})
+	case err != nil:
+	case !owned(previous) && ingress == nil:
+		// not the function's to remove
+	case !owned(previous):
+		err = errNotOwned("Ingress", previous)
+	case ingress == nil:
+		err = ingresses.Delete(ctx, f.Name, metav1.DeleteOptions{})
+	default:
+		ingress.ResourceVersion = previous.ResourceVersion
+		_, err = ingresses.Update(ctx, ingress, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("unable to apply the Ingress. %w", err)
+	}
+
+	routes := dynamicClient.Resource(httpRouteResource).Namespace(namespace)
+	route := generateHTTPRoute(f, namespace, decorator)
+	previousRoute, err := routes.Get(ctx, f.Name, metav1.GetOptions{})
+	switch {
+	case (errors.IsNotFound(err) || errors.IsForbidden(err)) && route == nil:
+		// Also the case when the Gateway API is not installed
+		err = nil
+	case errors.IsNotFound(err):
+		_, err = routes.Create(ctx, route, metav1.CreateOptions{})
+	case err != nil:
+	case !owned(previousRoute) && route == nil:
+		// not the function's to remove
+	case !owned(previousRoute):
+		err = errNotOwned("HTTPRoute", previousRoute)
+	case route == nil:
+		err = routes.Delete(ctx, f.Name, metav1.DeleteOptions{})
+	default:
+		route.SetResourceVersion(previousRoute.GetResourceVersion())
+		_, err = routes.Update(ctx, route, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("unable to apply the HTTPRoute. %w", err)
+	}
+	return nil
+}
+
+// waitForDeployment to be rolled out and available, failing early if its
+// image can not be pulled or its progress deadline is exceeded.
+func waitForDeployment(ctx context.Context, client *kubernetes.Clientset, deployment *appsv1.Deployment, timeout time.Duration) error {
+	deployments := client.AppsV1().Deployments(deployment.Namespace)
+	pods := client.CoreV1().Pods(deployment.Namespace)
+	selector := metav1.FormatLabelSelector(deployment.Spec.Selector)
+	return wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		d, err := deployments.Get(ctx, deployment.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, c := range d.Status.Conditions {
+			if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse {
+				return false, fmt.Errorf("%v: %v", c.Reason, c.Message)
+			}
+		}
+		if d.Status.ObservedGeneration >= d.Generation && d.Spec.Replicas != nil &&
+			d.Status.UpdatedReplicas == *d.Spec.Replicas && d.Status.Replicas == d.Status.UpdatedReplicas &&
+			d.Status.AvailableReplicas == d.Status.UpdatedReplicas {
+			return true, nil
+		}
+
+		list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: selector, FieldSelector: "status.phase=Pending"})
+		if err != nil {
+			return false, err
+		}
+		for _, pod := range list.Items {
+			for _, c := range pod.Status.ContainerStatuses {
+				if c.Name == FunctionContainerName && c.State.Waiting != nil &&
+					(c.State.Waiting.Reason == "ImagePullBackOff" || c.State.Waiting.Reason == "ErrImagePull") {
+					return false, fmt.Errorf("your function image is unreachable. It is possible that your docker registry is private. If so, make sure you have set up pull secrets https://kubernetes.io/docs/tasks/configure-pod-container/pull-image-private-registry")
+				}
+			}
+		}
+		return false, nil
+	})
+}
+
+// functionURL returns the URL at which the function is exposed, or its
+// cluster-local URL if it is not.
+func functionURL(ctx context.Context, client *kubernetes.Clientset, dynamicClient dynamic.Interface, name, namespace string) string {
+	if ingress, err := client.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+		if len(ingress.Spec.Rules) > 0 && ingress.Spec.Rules[0].Host != "" {
+			if len(ingress.Spec.TLS) > 0 {
+				return "https://" + ingress.Spec.Rules[0].Host
+			}
+			return "http://" + ingress.Spec.Rules[0].Host
+		}
+	}
+	if route, err := dynamicClient.Resource(httpRouteResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+		hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		if len(hostnames) > 0 {
+			return "http://" + hostnames[0]
+		}
+	}
+	return fmt.Sprintf("http://%v.%v.svc", name, namespace)
+}
@@ -0,0 +1,151 @@
+//go:build !integration
+// +build !integration
+
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// Test_generateDeployment ensures the function's Deployment runs its image
+// with its envs, probes, sidecars and scheduling, selecting its pods by name
+// and platform.
+func Test_generateDeployment(t *testing.T) {
+	name, value := "GREETING", "hello"
+	min := int64(2)
+	f := fn.Function{
+		Name:    "testing",
+		Runtime: "go",
+		Run: fn.RunSpec{
+			Envs:         []fn.Env{{Name: &name, Value: &value}},
+			StartTimeout: 3 * time.Minute,
+		},
+		Deploy: fn.DeploySpec{
+			Image:    "example.com/alice/testing",
+			Options:  fn.Options{Scale: &fn.ScaleOptions{Min: &min}},
+			Sidecars: []fn.Container{{Name: "proxy", Image: "example.com/proxy", Ports: []int32{8443}}},
+			Scheduling: fn.Scheduling{
+				NodeSelector: map[string]string{"node-pool": "spot"},
+			},
+		},
+	}
+	secrets, configMaps, pvcs := sets.New[string](), sets.New[string](), sets.New[string]()
+	deployment, err := generateDeployment(f, "ns", nil, &secrets, &configMaps, &pvcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *deployment.Spec.Replicas != 2 || *deployment.Spec.ProgressDeadlineSeconds != 180 {
+		t.Fatalf("unexpected replicas %v or progress deadline %v", *deployment.Spec.Replicas, *deployment.Spec.ProgressDeadlineSeconds)
+	}
+	selector := deployment.Spec.Selector.MatchLabels
+	if len(selector) != 2 || selector[fnlabels.FunctionNameKey] != "testing" || selector[fnlabels.FunctionPlatformKey] != "kubernetes" {
+		t.Fatalf("unexpected selector %v", selector)
+	}
+	if labels := deployment.Spec.Template.Labels; labels[fnlabels.FunctionRuntimeKey] != "go" || labels[fnlabels.FunctionPlatformKey] != "kubernetes" {
+		t.Fatalf("unexpected pod labels %v", labels)
+	}
+
+	spec := deployment.Spec.Template.Spec
+	if len(spec.Containers) != 2 || spec.NodeSelector["node-pool"] != "spot" {
+		t.Fatalf("unexpected pod spec %+v", spec)
+	}
+	c := spec.Containers[0]
+	if c.Name != FunctionContainerName || c.Image != f.Deploy.Image || c.Ports[0].ContainerPort != 8080 || c.ReadinessProbe.HTTPGet.Path != DefaultReadinessEndpoint {
+		t.Fatalf("unexpected function container %+v", c)
+	}
+	for _, p := range []*corev1.Probe{c.LivenessProbe, c.ReadinessProbe} {
+		if p.HTTPGet.Port != intstr.FromString("http") {
+			t.Fatalf("expected probes on the http port, got %v", p.HTTPGet.Port)
+		}
+	}
+	var found bool
+	for _, e := range c.Env {
+		found = found || (e.Name == name && e.Value == value)
+	}
+	if !found {
+		t.Fatalf("expected env %v, got %v", name, c.Env)
+	}
+	if proxy := spec.Containers[1]; proxy.Name != "proxy" || len(proxy.Ports) != 1 || proxy.Ports[0].ContainerPort != 8443 {
+		t.Fatalf("unexpected sidecar %+v", proxy)
+	}
+}
+
+// Test_generateHorizontalPodAutoscaler ensures an autoscaler is generated
+// only when the function defines a maximum scale.
+func Test_generateHorizontalPodAutoscaler(t *testing.T) {
+	f := fn.Function{Name: "testing"}
+	if hpa := generateHorizontalPodAutoscaler(f, "ns"); hpa != nil {
+		t.Fatalf("expected no autoscaler, got %+v", hpa)
+	}
+
+	max, utilization := int64(5), float64(60)
+	f.Deploy.Options.Scale = &fn.ScaleOptions{Max: &max, Utilization: &utilization}
+	hpa := generateHorizontalPodAutoscaler(f, "ns")
+	if hpa == nil || *hpa.Spec.MinReplicas != 1 || hpa.Spec.MaxReplicas != 5 || hpa.Spec.ScaleTargetRef.Name != "testing" {
+		t.Fatalf("unexpected autoscaler %+v", hpa)
+	}
+	if target := hpa.Spec.Metrics[0].Resource.Target.AverageUtilization; *target != 60 {
+		t.Fatalf("expected a target utilization of 60, got %v", *target)
+	}
+}
+
+// Test_generateExpose ensures the function is exposed by an Ingress, or by
+// an HTTPRoute when a Gateway is named.
+func Test_generateExpose(t *testing.T) {
+	f := fn.Function{Name: "testing"}
+	if generateIngress(f, "ns", nil) != nil || generateHTTPRoute(f, "ns", nil) != nil {
+		t.Fatal("expected an unexposed function to have neither an Ingress nor HTTPRoute")
+	}
+
+	f.Deploy.Expose = fn.Expose{Host: "www.example.com", IngressClassName: "nginx", TLSSecret: "www-tls"}
+	ingress := generateIngress(f, "ns", nil)
+	if ingress == nil || ingress.Spec.Rules[0].Host != "www.example.com" || *ingress.Spec.IngressClassName != "nginx" ||
+		ingress.Spec.TLS[0].SecretName != "www-tls" || ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name != "testing" {
+		t.Fatalf("unexpected Ingress %+v", ingress)
+	}
+	if generateHTTPRoute(f, "ns", nil) != nil {
+		t.Fatal("expected no HTTPRoute without a Gateway")
+	}
+
+	f.Deploy.Expose = fn.Expose{Host: "www.example.com", Gateway: "infra/public"}
+	if generateIngress(f, "ns", nil) != nil {
+		t.Fatal("expected no Ingress with a Gateway")
+	}
+	route := generateHTTPRoute(f, "ns", nil)
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	parent := parentRefs[0].(map[string]interface{})
+	if route.GetName() != "testing" || parent["name"] != "public" || parent["namespace"] != "infra" || hostnames[0] != "www.example.com" {
+		t.Fatalf("unexpected HTTPRoute %v", route.Object)
+	}
+}
+
+// Test_owned ensures that only the objects generated for a function by the
+// kubernetes deployer are owned, such that others of the same name are
+// neither updated nor removed.
+func Test_owned(t *testing.T) {
+	f := fn.Function{Name: "testing", Deploy: fn.DeploySpec{Expose: fn.Expose{Gateway: "gateway"}}}
+	if service := generateService(f, "ns", nil); !owned(service) {
+		t.Fatal("expected the function's Service to be owned")
+	}
+	if route := generateHTTPRoute(f, "ns", nil); !owned(route) {
+		t.Fatal("expected the function's HTTPRoute to be owned")
+	}
+
+	other := &unstructured.Unstructured{}
+	other.SetName("testing")
+	other.SetLabels(map[string]string{fnlabels.FunctionNameKey: "testing"}) // e.g. deployed to knative
+	if owned(other) {
+		t.Fatal("expected an object of another platform not to be owned")
+	}
+}
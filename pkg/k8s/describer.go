@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/func/pkg/deployers"
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// Describer of functions deployed by the kubernetes Deployer.
+type Describer struct {
+	verbose bool
+}
+
+func NewDescriber(verbose bool) *Describer {
+	return &Describer{verbose: verbose}
+}
+
+// Describe a function by name.  Returns fn.ErrFunctionNotFound if there is no
+// such function deployed by the kubernetes Deployer.
+func (d *Describer) Describe(ctx context.Context, name, namespace string) (description fn.Instance, err error) {
+	if namespace == "" {
+		err = fmt.Errorf("function namespace is required when describing %q", name)
+		return
+	}
+
+	client, err := NewKubernetesClientset(ctx)
+	if err != nil {
+		return
+	}
+	dynamicClient, err := NewDynamicClient(ctx)
+	if err != nil {
+		return
+	}
+
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		err = fn.ErrFunctionNotFound
+		return
+	} else if err != nil {
+		return
+	}
+	if deployment.Labels[fnlabels.FunctionPlatformKey] != deployers.Kubernetes {
+		err = fn.ErrFunctionNotFound
+		return
+	}
+
+	route := functionURL(ctx, client, dynamicClient, name, namespace)
+	description.Name = name
+	description.Namespace = namespace
+	description.Route = route
+	description.Routes = []string{route}
+	for _, c := range deployment.Spec.Template.Spec.Containers {
+		if c.Name == FunctionContainerName {
+			description.Image = c.Image
+		}
+	}
+	description.Subscriptions = []fn.Subscription{}
+	return
+}
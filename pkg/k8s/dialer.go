@@ -282,7 +282,7 @@ func (c *contextDialer) startDialerPod(ctx context.Context) (err error) {
 			Annotations: nil,
 		},
 		Spec: coreV1.PodSpec{
-			SecurityContext: defaultPodSecurityContext(),
+			SecurityContext: defaultPodSecurityContext(ctx),
 			Containers: []coreV1.Container{
 				{
 					Name:            c.podName,
@@ -546,6 +546,9 @@ func newConn() (*io.PipeReader, *io.PipeWriter, *conn) {
 	return pr1, pw0, rwc
 }
 
+// NewLazyInitInClusterDialer returns a dialer which starts the dialer pod upon
+// the first dial.  If clientConfig is nil, that of the kubeconfig context
+// selected by the context of the first dial is used.
 func NewLazyInitInClusterDialer(clientConfig clientcmd.ClientConfig) *lazyInitInClusterDialer {
 	return &lazyInitInClusterDialer{
 		clientConfig: clientConfig,
@@ -561,6 +564,9 @@ type lazyInitInClusterDialer struct {
 
 func (l *lazyInitInClusterDialer) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
 	l.o.Do(func() {
+		if l.clientConfig == nil {
+			l.clientConfig = GetClientConfig(ctx)
+		}
 		l.contextDialer, l.initErr = NewInClusterDialer(ctx, l.clientConfig)
 	})
 	if l.initErr != nil {
@@ -29,7 +29,7 @@ func TestDialInClusterService(t *testing.T) {
 	var err error
 	var ctx = context.Background()
 
-	clientConfig := k8s.GetClientConfig()
+	clientConfig := k8s.GetClientConfig(ctx)
 
 	rc, err := clientConfig.ClientConfig()
 	if err != nil {
@@ -185,7 +185,7 @@ func TestDialInClusterService(t *testing.T) {
 func TestDialUnreachable(t *testing.T) {
 	var ctx = context.Background()
 
-	dialer, err := k8s.NewInClusterDialer(ctx, k8s.GetClientConfig())
+	dialer, err := k8s.NewInClusterDialer(ctx, k8s.GetClientConfig(ctx))
 	if err != nil {
 		t.Fatal(err)
 	}
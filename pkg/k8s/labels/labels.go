@@ -6,6 +6,18 @@ const (
 	FunctionRuntimeKey = "function.knative.dev/runtime"
 	FunctionNameKey    = "function.knative.dev/name"
 
+	// FunctionPlatformKey is set on the resources of functions deployed to
+	// platforms other than Knative, such as "kubernetes".
+	FunctionPlatformKey = "function.knative.dev/platform"
+
+	// FunctionNamespaceKey is set on functions deployed to platforms without
+	// namespaces of their own, such as "docker".
+	FunctionNamespaceKey = "function.knative.dev/namespace"
+
+	// FunctionResourceKey is set on the Secrets and ConfigMaps managed with
+	// a function, such that those removed from it are pruned upon deploy.
+	FunctionResourceKey = "function.knative.dev/resource"
+
 	// --- handle usage of deprecated labels
 	DeprecatedFunctionKey        = "boson.dev/function"
 	DeprecatedFunctionRuntimeKey = "boson.dev/runtime"
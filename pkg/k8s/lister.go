@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"knative.dev/func/pkg/deployers"
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// Lister of functions deployed by the kubernetes Deployer.
+type Lister struct {
+	verbose bool
+}
+
+func NewLister(verbose bool) *Lister {
+	return &Lister{verbose: verbose}
+}
+
+// List functions, optionally specifying a namespace.
+func (l *Lister) List(ctx context.Context, namespace string) (items []fn.ListItem, err error) {
+	client, err := NewKubernetesClientset(ctx)
+	if err != nil {
+		return
+	}
+	dynamicClient, err := NewDynamicClient(ctx)
+	if err != nil {
+		return
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{
+		fnlabels.FunctionKey:         fnlabels.FunctionValue,
+		fnlabels.FunctionPlatformKey: deployers.Kubernetes,
+	})
+	lst, err := client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return
+	}
+
+	for _, deployment := range lst.Items {
+		items = append(items, fn.ListItem{
+			Name:      deployment.Name,
+			Namespace: deployment.Namespace,
+			Runtime:   deployment.Labels[fnlabels.FunctionRuntimeKey],
+			URL:       functionURL(ctx, client, dynamicClient, deployment.Name, deployment.Namespace),
+			Ready:     string(deploymentReady(deployment)),
+		})
+	}
+	return
+}
+
+// deploymentReady returns the status of the deployment's Available condition.
+func deploymentReady(deployment appsv1.Deployment) corev1.ConditionStatus {
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
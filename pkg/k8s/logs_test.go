@@ -19,7 +19,7 @@ func TestGetPodLogs(t *testing.T) {
 	var err error
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 	t.Cleanup(cancel)
-	cliSet, err := k8s.NewKubernetesClientset()
+	cliSet, err := k8s.NewKubernetesClientset(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
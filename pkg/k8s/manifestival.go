@@ -1,12 +1,14 @@
 package k8s
 
 import (
+	"context"
+
 	mfc "github.com/manifestival/client-go-client"
 	"github.com/manifestival/manifestival"
 )
 
-func GetManifestivalClient() (manifestival.Client, error) {
-	config, err := GetClientConfig().ClientConfig()
+func GetManifestivalClient(ctx context.Context) (manifestival.Client, error) {
+	config, err := GetClientConfig(ctx).ClientConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +25,7 @@ const (
 )
 
 func GetOpenShiftServiceCA(ctx context.Context) (*x509.Certificate, error) {
-	client, ns, err := NewClientAndResolvedNamespace("")
+	client, ns, err := NewClientAndResolvedNamespace(ctx, "")
 	if err != nil {
 		return nil, err
 	}
@@ -85,8 +85,8 @@ func GetOpenShiftServiceCA(ctx context.Context) (*x509.Certificate, error) {
 	}
 }
 
-func GetDefaultOpenShiftRegistry() string {
-	ns, _ := GetDefaultNamespace()
+func GetDefaultOpenShiftRegistry(ctx context.Context) string {
+	ns, _ := GetDefaultNamespace(ctx)
 	if ns == "" {
 		ns = "default"
 	}
@@ -94,8 +94,8 @@ func GetDefaultOpenShiftRegistry() string {
 	return openShiftRegistryHostPort + "/" + ns
 }
 
-func GetOpenShiftDockerCredentialLoaders() []creds.CredentialsCallback {
-	conf := GetClientConfig()
+func GetOpenShiftDockerCredentialLoaders(ctx context.Context) []creds.CredentialsCallback {
+	conf := GetClientConfig(ctx)
 
 	rawConf, err := conf.RawConfig()
 	if err != nil {
@@ -127,14 +127,14 @@ func GetOpenShiftDockerCredentialLoaders() []creds.CredentialsCallback {
 var isOpenShift bool
 var checkOpenShiftOnce sync.Once
 
-func IsOpenShift() bool {
+func IsOpenShift(ctx context.Context) bool {
 	checkOpenShiftOnce.Do(func() {
 		isOpenShift = false
-		client, err := NewKubernetesClientset()
+		client, err := NewKubernetesClientset(ctx)
 		if err != nil {
 			return
 		}
-		_, err = client.CoreV1().Services("openshift-image-registry").Get(context.TODO(), "image-registry", metav1.GetOptions{})
+		_, err = client.CoreV1().Services("openshift-image-registry").Get(ctx, "image-registry", metav1.GetOptions{})
 		if err == nil || k8sErrors.IsForbidden(err) {
 			isOpenShift = true
 			return
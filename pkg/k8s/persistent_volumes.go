@@ -3,6 +3,7 @@ package k8s
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -21,10 +22,12 @@ import (
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	k8sclientcmd "k8s.io/client-go/tools/clientcmd"
+
+	"knative.dev/func/pkg/filesync"
 )
 
 func GetPersistentVolumeClaim(ctx context.Context, name, namespaceOverride string) (*corev1.PersistentVolumeClaim, error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +36,7 @@ func GetPersistentVolumeClaim(ctx context.Context, name, namespaceOverride strin
 }
 
 func CreatePersistentVolumeClaim(ctx context.Context, name, namespaceOverride string, labels map[string]string, annotations map[string]string, accessMode corev1.PersistentVolumeAccessMode, resourceRequest resource.Quantity) (err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -59,7 +62,7 @@ func CreatePersistentVolumeClaim(ctx context.Context, name, namespaceOverride st
 }
 
 func DeletePersistentVolumeClaims(ctx context.Context, namespaceOverride string, listOptions metav1.ListOptions) (err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -67,19 +70,73 @@ func DeletePersistentVolumeClaims(ctx context.Context, namespaceOverride string,
 	return client.CoreV1().PersistentVolumeClaims(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOptions)
 }
 
+// DeletePersistentVolumeClaim of the given name.
+func DeletePersistentVolumeClaim(ctx context.Context, name, namespaceOverride string) (err error) {
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
+	if err != nil {
+		return
+	}
+
+	return client.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
 var TarImage = "ghcr.io/knative/func-utils:v2"
 
 // UploadToVolume uploads files (passed in form of tar stream) into volume.
 func UploadToVolume(ctx context.Context, content io.Reader, claimName, namespace string) error {
-	return runWithVolumeMounted(ctx, TarImage, []string{"sh", "-c", "umask 0000 && exec tar -xmf -"}, content, claimName, namespace)
+	return runWithVolumeMounted(ctx, TarImage, []string{"sh", "-c", "umask 0000 && exec tar -xmf -"}, content, nil, claimName, namespace)
+}
+
+// SyncToVolume synchronizes files into the volume incrementally.  The changes
+// function is given the manifest of the files of the volume as of their last
+// sync, and returns the stream of changes to apply (see filesync.NewStream).
+func SyncToVolume(ctx context.Context, changes func(filesync.Manifest) (io.Reader, error), claimName, namespace string) error {
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		err := runWithVolumeMounted(ctx, TarImage, []string{"func-util", "sync"}, stdinR, stdoutW, claimName, namespace)
+		_ = stdinR.CloseWithError(err)
+		_ = stdoutW.CloseWithError(err)
+		done <- err
+	}()
+
+	err := func() error {
+		// request the manifest of the last sync
+		if _, err := io.WriteString(stdinW, "\n"); err != nil {
+			return err
+		}
+		var remote filesync.Manifest
+		if err := json.NewDecoder(stdoutR).Decode(&remote); err != nil {
+			return fmt.Errorf("cannot read the manifest of the volume: %w", err)
+		}
+		go func() { _, _ = io.Copy(io.Discard, stdoutR) }()
+
+		content, err := changes(remote)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(stdinW, content); err != nil {
+			return fmt.Errorf("cannot send the changes: %w", err)
+		}
+		return stdinW.Close()
+	}()
+	if err != nil {
+		_ = stdinW.CloseWithError(err)
+		<-done
+		return err
+	}
+	return <-done
 }
 
 // Runs a pod with given image, command and stdin
 // while having the volume mounted and working directory set to it.
-func runWithVolumeMounted(ctx context.Context, podImage string, podCommand []string, podInput io.Reader, claimName, namespace string) error {
+// The pod's stdout is written to podOutput if not nil.
+func runWithVolumeMounted(ctx context.Context, podImage string, podCommand []string, podInput io.Reader, podOutput io.Writer, claimName, namespace string) error {
 	var err error
 
-	cliConf := GetClientConfig()
+	cliConf := GetClientConfig(ctx)
 	restConf, err := cliConf.ClientConfig()
 	if err != nil {
 		return fmt.Errorf("cannot get client config: %w", err)
@@ -97,7 +154,7 @@ func runWithVolumeMounted(ctx context.Context, podImage string, podCommand []str
 	}
 
 	if namespace == "" {
-		namespace, err = GetDefaultNamespace()
+		namespace, err = GetDefaultNamespace(ctx)
 		if err != nil {
 			return fmt.Errorf("cannot get namespace: %w", err)
 		}
@@ -120,7 +177,7 @@ func runWithVolumeMounted(ctx context.Context, podImage string, podCommand []str
 			Annotations: nil,
 		},
 		Spec: corev1.PodSpec{
-			SecurityContext: defaultPodSecurityContext(),
+			SecurityContext: defaultPodSecurityContext(ctx),
 			Containers: []corev1.Container{
 				{
 					Name:       podName,
@@ -200,7 +257,11 @@ func runWithVolumeMounted(ctx context.Context, podImage string, podCommand []str
 	}()
 
 	var outBuff tsBuff
-	err = attach(ctx, client.CoreV1().RESTClient(), restConf, podName, namespace, podInput, &outBuff, &outBuff)
+	stdout := podOutput
+	if stdout == nil {
+		stdout = &outBuff
+	}
+	err = attach(ctx, client.CoreV1().RESTClient(), restConf, podName, namespace, podInput, stdout, &outBuff)
 	if err != nil {
 		return fmt.Errorf("cannot attach stdio to the pod: %w", err)
 	}
@@ -265,7 +326,7 @@ func ListPersistentVolumeClaimsNamesIfConnected(ctx context.Context, namespaceOv
 }
 
 func listPersistentVolumeClaimsNames(ctx context.Context, namespaceOverride string) (names []string, err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -25,7 +25,7 @@ func TestUploadToVolume(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
 	t.Cleanup(cancel)
 
-	cliSet, testingNS, err := k8s.NewClientAndResolvedNamespace("")
+	cliSet, testingNS, err := k8s.NewClientAndResolvedNamespace(ctx, "")
 	if err != nil {
 		t.Fatal(err)
 	}
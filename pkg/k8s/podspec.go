@@ -0,0 +1,591 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Default health endpoints of a function, used for probes which do not define
+// their own handler.
+const (
+	DefaultLivenessEndpoint  = "/health/liveness"
+	DefaultReadinessEndpoint = "/health/readiness"
+)
+
+// probeFor returns the probe defined by p, which uses an HTTP GET request to
+// the given default path if p defines no handler of its own.
+func probeFor(p fn.Probe, path string) *corev1.Probe {
+	probe := &corev1.Probe{
+		InitialDelaySeconds: p.InitialDelaySeconds,
+		PeriodSeconds:       p.PeriodSeconds,
+		TimeoutSeconds:      p.TimeoutSeconds,
+		FailureThreshold:    p.FailureThreshold,
+	}
+	switch {
+	case len(p.Exec) > 0:
+		probe.Exec = &corev1.ExecAction{Command: p.Exec}
+	case p.GRPC != nil:
+		probe.GRPC = &corev1.GRPCAction{Port: p.GRPC.Port}
+		if p.GRPC.Service != "" {
+			probe.GRPC.Service = &p.GRPC.Service
+		}
+	default:
+		if p.Path != "" {
+			path = p.Path
+		}
+		probe.HTTPGet = &corev1.HTTPGetAction{Path: path}
+		if p.Port != 0 {
+			probe.HTTPGet.Port = intstr.FromInt32(p.Port)
+		}
+		names := make([]string, 0, len(p.Headers))
+		for k := range p.Headers {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			probe.HTTPGet.HTTPHeaders = append(probe.HTTPGet.HTTPHeaders, corev1.HTTPHeader{Name: k, Value: p.Headers[k]})
+		}
+	}
+	return probe
+}
+
+// SetHealthEndpoints sets the liveness, readiness and startup probes of the
+// function's container.
+func SetHealthEndpoints(f fn.Function, c *corev1.Container) *corev1.Container {
+	// The defaults are overridden by any settings in func.yaml.
+	c.LivenessProbe = probeFor(f.Deploy.HealthEndpoints.Liveness, DefaultLivenessEndpoint)
+	c.ReadinessProbe = probeFor(f.Deploy.HealthEndpoints.Readiness, DefaultReadinessEndpoint)
+
+	// A startup probe is only set if specified, defaulting to the readiness
+	// endpoint.
+	c.StartupProbe = nil
+	if !f.Deploy.HealthEndpoints.Startup.IsZero() {
+		c.StartupProbe = probeFor(f.Deploy.HealthEndpoints.Startup, DefaultReadinessEndpoint)
+	}
+	return c
+}
+
+// SetResources sets the resource requests and limits of the function's
+// container, clearing any which are no longer defined.
+func SetResources(c *corev1.Container, resources *fn.ResourcesOptions) error {
+	c.Resources.Requests = nil
+	c.Resources.Limits = nil
+	if resources == nil {
+		return nil
+	}
+
+	var err error
+	if r := resources.Requests; r != nil {
+		if c.Resources.Requests, err = resourceList(r.CPU, r.Memory); err != nil {
+			return err
+		}
+	}
+	if l := resources.Limits; l != nil {
+		if c.Resources.Limits, err = resourceList(l.CPU, l.Memory); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resourceList(cpu, memory *string) (corev1.ResourceList, error) {
+	list := corev1.ResourceList{}
+	if cpu != nil {
+		value, err := resource.ParseQuantity(*cpu)
+		if err != nil {
+			return nil, err
+		}
+		list[corev1.ResourceCPU] = value
+	}
+	if memory != nil {
+		value, err := resource.ParseQuantity(*memory)
+		if err != nil {
+			return nil, err
+		}
+		list[corev1.ResourceMemory] = value
+	}
+	return list, nil
+}
+
+// ProcessEnvs generates array of EnvVars and EnvFromSources from a function config
+// envs:
+//   - name: EXAMPLE1                            # ENV directly from a value
+//     value: value1
+//   - name: EXAMPLE2                            # ENV from the local ENV var
+//     value: {{ env:MY_ENV }}
+//   - name: EXAMPLE3
+//     value: {{ secret:example-secret:key }}    # ENV from a key in Secret
+//   - value: {{ secret:example-secret }}        # all ENVs from Secret
+//   - name: EXAMPLE4
+//     value: {{ configMap:configMapName:key }}  # ENV from a key in ConfigMap
+//   - value: {{ configMap:configMapName }}      # all key-pair values from ConfigMap are set as ENV
+//   - name: EXAMPLE5
+//     value: {{ file:path/to/file }}            # ENV from the contents of a local file, relative to root
+func ProcessEnvs(root string, envs []fn.Env, referencedSecrets, referencedConfigMaps *sets.Set[string]) ([]corev1.EnvVar, []corev1.EnvFromSource, error) {
+
+	envs = withOpenAddress(envs) // prepends ADDRESS=0.0.0.0 if not extant
+
+	envVars, envFrom, err := ProcessContainerEnvs(root, envs, referencedSecrets, referencedConfigMaps)
+	if err != nil {
+		return nil, nil, err
+	}
+	envVars = append([]corev1.EnvVar{{Name: "BUILT", Value: time.Now().Format("20060102T150405")}}, envVars...)
+	return envVars, envFrom, nil
+}
+
+// ProcessContainerEnvs generates the EnvVars and EnvFromSources of any
+// container from envs which may be set in the same ways as those of the
+// function (see ProcessEnvs).
+func ProcessContainerEnvs(root string, envs []fn.Env, referencedSecrets, referencedConfigMaps *sets.Set[string]) ([]corev1.EnvVar, []corev1.EnvFromSource, error) {
+	envVars := []corev1.EnvVar{}
+	envFrom := []corev1.EnvFromSource{}
+
+	for _, env := range envs {
+		if env.Name == nil && env.Value != nil {
+			// all key-pair values from secret/configMap are set as ENV, eg. {{ secret:secretName }} or {{ configMap:configMapName }}
+			if strings.HasPrefix(*env.Value, "{{") {
+				envFromSource, err := createEnvFromSource(*env.Value, referencedSecrets, referencedConfigMaps)
+				if err != nil {
+					return nil, nil, err
+				}
+				envFrom = append(envFrom, *envFromSource)
+				continue
+			}
+		} else if env.Name != nil && env.Value != nil {
+			if strings.HasPrefix(*env.Value, "{{") {
+				if fileValue, ok, err := fn.EnvFileValue(root, *env.Value); ok {
+					// ENV from the contents of a local file, eg. FOO={{ file:path }}
+					if err != nil {
+						return nil, nil, err
+					}
+					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: fileValue})
+					continue
+				}
+				slices := strings.Split(strings.Trim(*env.Value, "{} "), ":")
+				if len(slices) == 3 {
+					// ENV from a key in secret/configMap, eg. FOO={{ secret:secretName:key }} FOO={{ configMap:configMapName.key }}
+					valueFrom, err := createEnvVarSource(slices, referencedSecrets, referencedConfigMaps)
+					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, ValueFrom: valueFrom})
+					if err != nil {
+						return nil, nil, err
+					}
+					continue
+				} else if len(slices) == 2 {
+					// ENV from the local ENV var, eg. FOO={{ env:LOCAL_ENV }}
+					localValue, err := processLocalEnvValue(*env.Value)
+					if err != nil {
+						return nil, nil, err
+					}
+					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: localValue})
+					continue
+				}
+			} else {
+				// a standard ENV with key and value, eg. FOO=bar
+				envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: *env.Value})
+				continue
+			}
+		}
+		return nil, nil, fmt.Errorf("unsupported env source entry \"%v\"", env)
+	}
+
+	return envVars, envFrom, nil
+}
+
+// ProcessContainers generates the sidecars and init containers of the
+// function.  Their volume mounts reference the function's volumes by the path
+// at which each is mounted in the function, and are resolved using the
+// function's generated volumeMounts.
+//
+// Container ports are not declared, as platforms differ in which containers
+// may declare them.  Containers in a pod share its network, so sidecars may
+// listen on any port.
+func ProcessContainers(f fn.Function, volumeMounts []corev1.VolumeMount, referencedSecrets, referencedConfigMaps *sets.Set[string]) (sidecars, initContainers []corev1.Container, err error) {
+	volumeNames := make(map[string]string, len(volumeMounts))
+	for _, m := range volumeMounts {
+		volumeNames[m.MountPath] = m.Name
+	}
+	newContainer := func(c fn.Container) (corev1.Container, error) {
+		container := corev1.Container{
+			Name:    c.Name,
+			Image:   c.Image,
+			Command: c.Command,
+			Args:    c.Args,
+		}
+		env, envFrom, err := ProcessContainerEnvs(f.Root, c.Envs, referencedSecrets, referencedConfigMaps)
+		if err != nil {
+			return container, fmt.Errorf("container %q: %w", c.Name, err)
+		}
+		if len(env) > 0 {
+			container.Env = env
+		}
+		if len(envFrom) > 0 {
+			container.EnvFrom = envFrom
+		}
+		for _, m := range c.VolumeMounts {
+			name, ok := volumeNames[m.Volume]
+			if !ok {
+				return container, fmt.Errorf("container %q mounts volume %q, which is not a volume of the function", c.Name, m.Volume)
+			}
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      name,
+				MountPath: m.MountPath(),
+				ReadOnly:  m.ReadOnly,
+			})
+		}
+		return container, nil
+	}
+	for _, c := range f.Deploy.Sidecars {
+		container, err := newContainer(c)
+		if err != nil {
+			return nil, nil, err
+		}
+		sidecars = append(sidecars, container)
+	}
+	for _, c := range f.Deploy.InitContainers {
+		container, err := newContainer(c)
+		if err != nil {
+			return nil, nil, err
+		}
+		initContainers = append(initContainers, container)
+	}
+	return
+}
+
+// withOpenAddresss prepends ADDRESS=0.0.0.0 to the envs if not present.
+//
+// This is combined with the value of PORT at runtime to determine the full
+// Listener address on which a Function will listen tcp requests.
+//
+// Runtimes should, by default, only listen on the loopback interface by
+// default, as they may be `func run` locally, for security purposes.
+// This environment vriable instructs the runtimes to listen on all interfaces
+// by default when actually being deployed, since they will need to actually
+// listen for client requests and for health readiness/liveness probes.
+//
+// Should a user wish to securely open their function to only receive requests
+// on a specific interface, such as a WireGuar-encrypted mesh network which
+// presents as a specific interface, that can be achieved by setting the
+// ADDRESS value as an environment variable on their function to the interface
+// on which to listen.
+//
+// NOTE this env is currently only respected by scaffolded Go functions, because
+// they are the only ones which support being `func run` locally.  Other
+// runtimes will respect the value as they are updated to support scaffolding.
+func withOpenAddress(ee []fn.Env) []fn.Env {
+	// TODO: this is unnecessarily complex due to both key and value of the
+	// envs slice being being pointers.  There is an outstanding tech-debt item
+	// to remove pointers from Function Envs, Volumes, Labels, and Options.
+	var found bool
+	for _, e := range ee {
+		if e.Name != nil && *e.Name == "ADDRESS" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		k := "ADDRESS"
+		v := "0.0.0.0"
+		ee = append(ee, fn.Env{Name: &k, Value: &v})
+	}
+	return ee
+}
+
+func createEnvFromSource(value string, referencedSecrets, referencedConfigMaps *sets.Set[string]) (*corev1.EnvFromSource, error) {
+	slices := strings.Split(strings.Trim(value, "{} "), ":")
+	if len(slices) != 2 {
+		return nil, fmt.Errorf("env requires a value in form \"resourceType:name\" where \"resourceType\" can be one of \"configMap\" or \"secret\"; got %q", slices)
+	}
+
+	envVarSource := corev1.EnvFromSource{}
+
+	typeString := strings.TrimSpace(slices[0])
+	sourceName := strings.TrimSpace(slices[1])
+
+	var sourceType string
+
+	switch typeString {
+	case "configMap":
+		sourceType = "ConfigMap"
+		envVarSource.ConfigMapRef = &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: sourceName,
+			}}
+
+		if !referencedConfigMaps.Has(sourceName) {
+			referencedConfigMaps.Insert(sourceName)
+		}
+	case "secret":
+		sourceType = "Secret"
+		envVarSource.SecretRef = &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: sourceName,
+			}}
+		if !referencedSecrets.Has(sourceName) {
+			referencedSecrets.Insert(sourceName)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported env source type %q; supported source types are \"configMap\" or \"secret\"", slices[0])
+	}
+
+	if len(sourceName) == 0 {
+		return nil, fmt.Errorf("the name of %s cannot be an empty string", sourceType)
+	}
+
+	return &envVarSource, nil
+}
+
+func createEnvVarSource(slices []string, referencedSecrets, referencedConfigMaps *sets.Set[string]) (*corev1.EnvVarSource, error) {
+
+	if len(slices) != 3 {
+		return nil, fmt.Errorf("env requires a value in form \"resourceType:name:key\" where \"resourceType\" can be one of \"configMap\" or \"secret\"; got %q", slices)
+	}
+
+	envVarSource := corev1.EnvVarSource{}
+
+	typeString := strings.TrimSpace(slices[0])
+	sourceName := strings.TrimSpace(slices[1])
+	sourceKey := strings.TrimSpace(slices[2])
+
+	var sourceType string
+
+	switch typeString {
+	case "configMap":
+		sourceType = "ConfigMap"
+		envVarSource.ConfigMapKeyRef = &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: sourceName,
+			},
+			Key: sourceKey}
+
+		if !referencedConfigMaps.Has(sourceName) {
+			referencedConfigMaps.Insert(sourceName)
+		}
+	case "secret":
+		sourceType = "Secret"
+		envVarSource.SecretKeyRef = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: sourceName,
+			},
+			Key: sourceKey}
+
+		if !referencedSecrets.Has(sourceName) {
+			referencedSecrets.Insert(sourceName)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported env source type %q; supported source types are \"configMap\" or \"secret\"", slices[0])
+	}
+
+	if len(sourceName) == 0 {
+		return nil, fmt.Errorf("the name of %s cannot be an empty string", sourceType)
+	}
+
+	if len(sourceKey) == 0 {
+		return nil, fmt.Errorf("the key referenced by resource %s %q cannot be an empty string", sourceType, sourceName)
+	}
+
+	return &envVarSource, nil
+}
+
+var evRegex = regexp.MustCompile(`^{{\s*(\w+)\s*:(\w+)\s*}}$`)
+
+const (
+	ctxIdx = 1
+	valIdx = 2
+)
+
+func processLocalEnvValue(val string) (string, error) {
+	match := evRegex.FindStringSubmatch(val)
+	if len(match) > valIdx {
+		if match[ctxIdx] != "env" {
+			return "", fmt.Errorf("allowed env value entry is \"{{ env:LOCAL_VALUE }}\"; got: %q", match[ctxIdx])
+		}
+		if v, ok := os.LookupEnv(match[valIdx]); ok {
+			return v, nil
+		} else {
+			return "", fmt.Errorf("required local environment variable %q is not set", match[valIdx])
+		}
+	} else {
+		return val, nil
+	}
+}
+
+// ProcessVolumes generates Volumes and VolumeMounts from a function config
+// volumes:
+//   - secret: example-secret                              # mount Secret as Volume
+//     path: /etc/secret-volume
+//   - configMap: example-configMap                        # mount ConfigMap as Volume
+//     path: /etc/configMap-volume
+//   - persistentVolumeClaim: { claimName: example-pvc }   # mount PersistentVolumeClaim as Volume
+//     path: /etc/secret-volume
+//   - emptyDir: {}                                         # mount EmptyDir as Volume
+//     path: /etc/configMap-volume
+func ProcessVolumes(volumes []fn.Volume, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string]) ([]corev1.Volume, []corev1.VolumeMount, error) {
+
+	createdVolumes := sets.NewString()
+	usedPaths := sets.NewString()
+
+	newVolumes := []corev1.Volume{}
+	newVolumeMounts := []corev1.VolumeMount{}
+
+	for _, vol := range volumes {
+
+		volumeName := ""
+
+		if vol.Secret != nil {
+			volumeName = "secret-" + *vol.Secret
+
+			if !createdVolumes.Has(volumeName) {
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: *vol.Secret,
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+
+				if !referencedSecrets.Has(*vol.Secret) {
+					referencedSecrets.Insert(*vol.Secret)
+				}
+			}
+		} else if vol.ConfigMap != nil {
+			volumeName = "config-map-" + *vol.ConfigMap
+
+			if !createdVolumes.Has(volumeName) {
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: *vol.ConfigMap,
+							},
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+
+				if !referencedConfigMaps.Has(*vol.ConfigMap) {
+					referencedConfigMaps.Insert(*vol.ConfigMap)
+				}
+			}
+		} else if vol.PersistentVolumeClaim != nil {
+			volumeName = "pvc-" + *vol.PersistentVolumeClaim.ClaimName
+
+			if !createdVolumes.Has(volumeName) {
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: *vol.PersistentVolumeClaim.ClaimName,
+							ReadOnly:  vol.PersistentVolumeClaim.ReadOnly,
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+
+				if !referencedPVCs.Has(*vol.PersistentVolumeClaim.ClaimName) {
+					referencedPVCs.Insert(*vol.PersistentVolumeClaim.ClaimName)
+				}
+			}
+		} else if vol.EmptyDir != nil {
+			volumeName = "empty-dir-" + rand.String(7)
+
+			if !createdVolumes.Has(volumeName) {
+
+				var sizeLimit *resource.Quantity
+				if vol.EmptyDir.SizeLimit != nil {
+					sl, err := resource.ParseQuantity(*vol.EmptyDir.SizeLimit)
+					if err != nil {
+						return nil, nil, fmt.Errorf("invalid quantity for sizeLimit: %s. Error: %s", *vol.EmptyDir.SizeLimit, err)
+					}
+					sizeLimit = &sl
+				}
+
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{
+							Medium:    corev1.StorageMedium(vol.EmptyDir.Medium),
+							SizeLimit: sizeLimit,
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+			}
+		}
+
+		if volumeName != "" {
+			if !usedPaths.Has(*vol.Path) {
+				newVolumeMounts = append(newVolumeMounts, corev1.VolumeMount{
+					Name:      volumeName,
+					MountPath: *vol.Path,
+				})
+				usedPaths.Insert(*vol.Path)
+			} else {
+				return nil, nil, fmt.Errorf("mount path %s is defined multiple times", *vol.Path)
+			}
+		}
+	}
+
+	return newVolumes, newVolumeMounts, nil
+}
+
+// CheckResourcesArePresent returns error if Secrets or ConfigMaps
+// referenced in input sets are not deployed on the cluster in the specified namespace
+func CheckResourcesArePresent(ctx context.Context, namespace string, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string], referencedServiceAccount string) error {
+
+	errMsg := ""
+	for s := range *referencedSecrets {
+		_, err := GetSecret(ctx, s, namespace)
+		if err != nil {
+			if errors.IsForbidden(err) {
+				errMsg += " Ensure that the service account has the necessary permissions to access the secret.\n"
+			} else {
+				errMsg += fmt.Sprintf("  referenced Secret \"%s\" is not present in namespace \"%s\"\n", s, namespace)
+			}
+		}
+	}
+
+	for cm := range *referencedConfigMaps {
+		_, err := GetConfigMap(ctx, cm, namespace)
+		if err != nil {
+			errMsg += fmt.Sprintf("  referenced ConfigMap \"%s\" is not present in namespace \"%s\"\n", cm, namespace)
+		}
+	}
+
+	for pvc := range *referencedPVCs {
+		_, err := GetPersistentVolumeClaim(ctx, pvc, namespace)
+		if err != nil {
+			errMsg += fmt.Sprintf("  referenced PersistentVolumeClaim \"%s\" is not present in namespace \"%s\"\n", pvc, namespace)
+		}
+	}
+
+	// check if referenced ServiceAccount is present in the namespace if it is not default
+	if referencedServiceAccount != "" && referencedServiceAccount != "default" {
+		err := GetServiceAccount(ctx, referencedServiceAccount, namespace)
+		if err != nil {
+			errMsg += fmt.Sprintf("  referenced ServiceAccount \"%s\" is not present in namespace \"%s\"\n", referencedServiceAccount, namespace)
+		}
+	}
+
+	if errMsg != "" {
+		return fmt.Errorf("error(s) while validating resources:\n%s", errMsg)
+	}
+
+	return nil
+}
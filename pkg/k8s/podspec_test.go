@@ -0,0 +1,161 @@
+//go:build !integration
+// +build !integration
+
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+func Test_setHealthEndpoints(t *testing.T) {
+	f := fn.Function{
+		Name: "testing",
+		Deploy: fn.DeploySpec{
+			HealthEndpoints: fn.HealthEndpoints{
+				Liveness:  fn.Probe{Path: "/lively"},
+				Readiness: fn.Probe{Path: "/readyAsIllEverBe"},
+			},
+		},
+	}
+	c := corev1.Container{}
+	SetHealthEndpoints(f, &c)
+	got := c.LivenessProbe.HTTPGet.Path
+	if got != "/lively" {
+		t.Errorf("expected \"/lively\" but got %v", got)
+	}
+	got = c.ReadinessProbe.HTTPGet.Path
+	if got != "/readyAsIllEverBe" {
+		t.Errorf("expected \"readyAsIllEverBe\" but got %v", got)
+	}
+}
+
+func Test_setHealthEndpointDefaults(t *testing.T) {
+	f := fn.Function{
+		Name: "testing",
+	}
+	c := corev1.Container{}
+	SetHealthEndpoints(f, &c)
+	got := c.LivenessProbe.HTTPGet.Path
+	if got != DefaultLivenessEndpoint {
+		t.Errorf("expected \"%v\" but got %v", DefaultLivenessEndpoint, got)
+	}
+	got = c.ReadinessProbe.HTTPGet.Path
+	if got != DefaultReadinessEndpoint {
+		t.Errorf("expected \"%v\" but got %v", DefaultReadinessEndpoint, got)
+	}
+}
+
+func Test_setHealthEndpointsProbes(t *testing.T) {
+	f := fn.Function{
+		Name: "testing",
+		Deploy: fn.DeploySpec{
+			HealthEndpoints: fn.HealthEndpoints{
+				Liveness:  fn.Probe{Exec: []string{"/bin/check"}, PeriodSeconds: 5},
+				Readiness: fn.Probe{Port: 8081, Headers: map[string]string{"X-Probe": "true"}, FailureThreshold: 6},
+				Startup:   fn.Probe{GRPC: &fn.GRPCProbe{Port: 9090, Service: "f"}, InitialDelaySeconds: 2},
+			},
+		},
+	}
+	c := corev1.Container{}
+	SetHealthEndpoints(f, &c)
+
+	if c.LivenessProbe.Exec == nil || c.LivenessProbe.Exec.Command[0] != "/bin/check" || c.LivenessProbe.PeriodSeconds != 5 {
+		t.Errorf("unexpected liveness probe %+v", c.LivenessProbe)
+	}
+	r := c.ReadinessProbe
+	if r.HTTPGet.Path != DefaultReadinessEndpoint || r.HTTPGet.Port.IntValue() != 8081 || r.FailureThreshold != 6 ||
+		len(r.HTTPGet.HTTPHeaders) != 1 || r.HTTPGet.HTTPHeaders[0].Name != "X-Probe" {
+		t.Errorf("unexpected readiness probe %+v", r)
+	}
+	s := c.StartupProbe
+	if s == nil || s.GRPC == nil || s.GRPC.Port != 9090 || *s.GRPC.Service != "f" || s.InitialDelaySeconds != 2 {
+		t.Errorf("unexpected startup probe %+v", s)
+	}
+
+	// The startup probe is removed when no longer defined
+	f.Deploy.HealthEndpoints.Startup = fn.Probe{}
+	SetHealthEndpoints(f, &c)
+	if c.StartupProbe != nil {
+		t.Errorf("expected no startup probe, got %+v", c.StartupProbe)
+	}
+}
+
+func Test_processValue(t *testing.T) {
+	testEnvVarOld, testEnvVarOldExists := os.LookupEnv("TEST_KNATIVE_DEPLOYER")
+	os.Setenv("TEST_KNATIVE_DEPLOYER", "VALUE_FOR_TEST_KNATIVE_DEPLOYER")
+	defer func() {
+		if testEnvVarOldExists {
+			os.Setenv("TEST_KNATIVE_DEPLOYER", testEnvVarOld)
+		} else {
+			os.Unsetenv("TEST_KNATIVE_DEPLOYER")
+		}
+	}()
+
+	unsetVarOld, unsetVarOldExists := os.LookupEnv("UNSET_VAR")
+	os.Unsetenv("UNSET_VAR")
+	defer func() {
+		if unsetVarOldExists {
+			os.Setenv("UNSET_VAR", unsetVarOld)
+		}
+	}()
+
+	tests := []struct {
+		name    string
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{name: "simple value", arg: "A_VALUE", want: "A_VALUE", wantErr: false},
+		{name: "using envvar value", arg: "{{ env:TEST_KNATIVE_DEPLOYER }}", want: "VALUE_FOR_TEST_KNATIVE_DEPLOYER", wantErr: false},
+		{name: "bad context", arg: "{{secret:S}}", want: "", wantErr: true},
+		{name: "unset envvar", arg: "{{env:SOME_UNSET_VAR}}", want: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := processLocalEnvValue(test.arg)
+			if (err != nil) != test.wantErr {
+				t.Errorf("processValue() error = %v, wantErr %v", err, test.wantErr)
+				return
+			}
+			if got != test.want {
+				t.Errorf("processValue() got = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func Test_processEnvs_File(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "token"), []byte("s3cr3t"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	name := "TOKEN"
+	value := "{{ file:token }}"
+	secrets, configMaps := sets.New[string](), sets.New[string]()
+
+	envVars, _, err := ProcessEnvs(root, []fn.Env{{Name: &name, Value: &value}}, &secrets, &configMaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, e := range envVars {
+		if e.Name == name {
+			found = e.Value == "s3cr3t"
+		}
+	}
+	if !found {
+		t.Fatalf("expected TOKEN from file, got %v", envVars)
+	}
+
+	value = "{{ file:missing }}"
+	if _, _, err = ProcessEnvs(root, []fn.Env{{Name: &name, Value: &value}}, &secrets, &configMaps); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,98 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Remover of functions deployed by the kubernetes Deployer.
+type Remover struct {
+	verbose bool
+}
+
+func NewRemover(verbose bool) *Remover {
+	return &Remover{verbose: verbose}
+}
+
+// Remove the function's Deployment, Service, autoscaler and routes, leaving
+// any objects of the same name which are not the function's.  Returns
+// fn.ErrFunctionNotFound if there is no such function deployed by the
+// kubernetes Deployer.
+func (r *Remover) Remove(ctx context.Context, name, ns string) (err error) {
+	if ns == "" {
+		fmt.Fprintf(os.Stderr, "no namespace defined when trying to delete a function in kubernetes remover\n")
+		return fn.ErrNamespaceRequired
+	}
+
+	client, err := NewKubernetesClientset(ctx)
+	if err != nil {
+		return
+	}
+	dynamicClient, err := NewDynamicClient(ctx)
+	if err != nil {
+		return
+	}
+
+	deployment, err := client.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return fn.ErrFunctionNotFound
+	} else if err != nil {
+		return
+	}
+	if !owned(deployment) {
+		return fn.ErrFunctionNotFound
+	}
+
+	for _, r := range []struct {
+		kind   string
+		get    func() (metav1.Object, error)
+		remove func() error
+	}{
+		{"Deployment",
+			func() (metav1.Object, error) { return deployment, nil },
+			func() error { return client.AppsV1().Deployments(ns).Delete(ctx, name, metav1.DeleteOptions{}) }},
+		{"Service",
+			func() (metav1.Object, error) { return client.CoreV1().Services(ns).Get(ctx, name, metav1.GetOptions{}) },
+			func() error { return client.CoreV1().Services(ns).Delete(ctx, name, metav1.DeleteOptions{}) }},
+		{"HorizontalPodAutoscaler",
+			func() (metav1.Object, error) {
+				return client.AutoscalingV2().HorizontalPodAutoscalers(ns).Get(ctx, name, metav1.GetOptions{})
+			},
+			func() error {
+				return client.AutoscalingV2().HorizontalPodAutoscalers(ns).Delete(ctx, name, metav1.DeleteOptions{})
+			}},
+		{"Ingress",
+			func() (metav1.Object, error) {
+				return client.NetworkingV1().Ingresses(ns).Get(ctx, name, metav1.GetOptions{})
+			},
+			func() error { return client.NetworkingV1().Ingresses(ns).Delete(ctx, name, metav1.DeleteOptions{}) }},
+		{"HTTPRoute",
+			func() (metav1.Object, error) {
+				return dynamicClient.Resource(httpRouteResource).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+			},
+			func() error {
+				return dynamicClient.Resource(httpRouteResource).Namespace(ns).Delete(ctx, name, metav1.DeleteOptions{})
+			}},
+	} {
+		// Objects of the same name which are not the function's are left
+		var o metav1.Object
+		if o, err = r.get(); errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("kubernetes remover failed to get the %v: %v", r.kind, err)
+		}
+		if !owned(o) {
+			continue
+		}
+		if err = r.remove(); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("kubernetes remover failed to delete the %v: %v", r.kind, err)
+		}
+	}
+	return nil
+}
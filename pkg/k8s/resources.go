@@ -0,0 +1,122 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+
+	"knative.dev/func/pkg/crypt"
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// EnsureFunctionResources creates or updates the Secrets and ConfigMaps
+// managed with the function (see fn.ResourcesDir) in the given namespace.
+// They are labeled as belonging to the function, such that they are removed
+// along with its other resources by 'delete --all', and such that those since
+// removed from the function are pruned.  The values of Secrets are decrypted
+// using the key at keyFile.
+//
+// The key, like the resources themselves, is only held by the client, so an
+// empty keyFile leaves all of them as they are: this is the case when
+// deploying on cluster, the client having applied them before the pipeline
+// ran, or when configuring Pipelines as Code.  Pruning there would delete
+// every one, as the sources on cluster do not include them.
+func EnsureFunctionResources(ctx context.Context, f fn.Function, namespace, keyFile string) error {
+	if keyFile == "" {
+		return nil
+	}
+	labels := map[string]string{
+		fnlabels.FunctionNameKey:     f.Name,
+		fnlabels.FunctionResourceKey: fnlabels.FunctionValue,
+	}
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	listOptions := metav1.ListOptions{LabelSelector: k8slabels.SelectorFromSet(labels).String()}
+
+	configMaps, err := f.Resources(fn.ConfigMapResource)
+	if err != nil {
+		return err
+	}
+	for _, r := range configMaps {
+		cm := corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: r.Name, Labels: labels},
+			Data:       r.Data,
+		}
+		if err = EnsureConfigMapExist(ctx, cm, namespace); err != nil {
+			return fmt.Errorf("unable to create ConfigMap %q. %w", r.Name, err)
+		}
+	}
+	// Users of functions without ConfigMaps may not be permitted to list
+	// them, in which case none were created to be pruned.
+	cms, err := client.CoreV1().ConfigMaps(namespace).List(ctx, listOptions)
+	if k8serrors.IsForbidden(err) && len(configMaps) == 0 {
+		cms, err = &corev1.ConfigMapList{}, nil
+	} else if err != nil {
+		return fmt.Errorf("unable to list the function's ConfigMaps. %w", err)
+	}
+	for _, cm := range staleResources(configMaps, cms.Items, func(cm corev1.ConfigMap) string { return cm.Name }) {
+		if err = client.CoreV1().ConfigMaps(namespace).Delete(ctx, cm, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("unable to delete ConfigMap %q. %w", cm, err)
+		}
+	}
+
+	secrets, err := f.Resources(fn.SecretResource)
+	if err != nil {
+		return err
+	}
+	if len(secrets) > 0 {
+		key, err := crypt.LoadKey(keyFile, false)
+		if err != nil {
+			return fmt.Errorf("unable to decrypt the function's secrets. %w", err)
+		}
+		for _, r := range secrets {
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: r.Name, Labels: labels},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       make(map[string][]byte, len(r.Data)),
+			}
+			for k, v := range r.Data {
+				if secret.Data[k], err = key.Decrypt(v); err != nil {
+					return fmt.Errorf("unable to decrypt key %q of secret %q. %w", k, r.Name, err)
+				}
+			}
+			if err = EnsureSecretExist(ctx, secret, namespace); err != nil {
+				return fmt.Errorf("unable to create Secret %q. %w", r.Name, err)
+			}
+		}
+	}
+	ss, err := client.CoreV1().Secrets(namespace).List(ctx, listOptions)
+	if k8serrors.IsForbidden(err) && len(secrets) == 0 {
+		ss, err = &corev1.SecretList{}, nil
+	} else if err != nil {
+		return fmt.Errorf("unable to list the function's Secrets. %w", err)
+	}
+	for _, s := range staleResources(secrets, ss.Items, func(s corev1.Secret) string { return s.Name }) {
+		if err = client.CoreV1().Secrets(namespace).Delete(ctx, s, metav1.DeleteOptions{}); err != nil {
+			return fmt.Errorf("unable to delete Secret %q. %w", s, err)
+		}
+	}
+	return nil
+}
+
+// staleResources returns the names of the existing objects which are not
+// among the given resources.
+func staleResources[T any](resources []fn.Resource, existing []T, name func(T) string) (stale []string) {
+	wanted := make(map[string]bool, len(resources))
+	for _, r := range resources {
+		wanted[r.Name] = true
+	}
+	for _, o := range existing {
+		if !wanted[name(o)] {
+			stale = append(stale, name(o))
+		}
+	}
+	return
+}
@@ -0,0 +1,73 @@
+//go:build !integration
+// +build !integration
+
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestStaleResources ensures that the objects of a function's resources
+// which have since been removed from it are those pruned.
+func TestStaleResources(t *testing.T) {
+	resources := []fn.Resource{{Name: "db"}, {Name: "api"}}
+	existing := []corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "db"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cache"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "api"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "queue"}},
+	}
+	stale := staleResources(resources, existing, func(s corev1.Secret) string { return s.Name })
+	if !reflect.DeepEqual(stale, []string{"cache", "queue"}) {
+		t.Fatalf("unexpected stale resources %v", stale)
+	}
+	if stale = staleResources(nil, existing[:1], func(s corev1.Secret) string { return s.Name }); !reflect.DeepEqual(stale, []string{"db"}) {
+		t.Fatalf("expected all resources of a function without any to be stale, got %v", stale)
+	}
+}
+
+// TestEnsureFunctionResources_OnCluster ensures that without the key, as when
+// deploying on cluster, the resources are left as they are rather than pruned.
+func TestEnsureFunctionResources_OnCluster(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/non/existent/kubeconfig")
+	f := fn.Function{Root: t.TempDir(), Name: "f"}
+	if err := f.WriteResource(fn.ConfigMapResource, fn.Resource{Name: "settings", Data: map[string]string{"a": "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureFunctionResources(context.Background(), f, "ns", ""); err != nil {
+		t.Fatalf("expected no cluster access without the key, got %v", err)
+	}
+}
+
+// TestSecretUpToDate ensures an existing Secret with the data of a function's
+// secret is updated if it lacks the function's labels, such that it is then
+// pruned and deleted with the function.
+func TestSecretUpToDate(t *testing.T) {
+	labels := map[string]string{"function.knative.dev/name": "f", "function.knative.dev/resource": "true"}
+	wanted := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: labels},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	existing := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	if secretUpToDate(existing, wanted) {
+		t.Fatal("expected a Secret without the function's labels to be updated")
+	}
+	existing.Labels = map[string]string{"function.knative.dev/name": "f", "function.knative.dev/resource": "true", "other": "label"}
+	if !secretUpToDate(existing, wanted) {
+		t.Fatal("expected a Secret with the function's data and labels not to be updated")
+	}
+	existing.Data["password"] = []byte("changed")
+	if secretUpToDate(existing, wanted) {
+		t.Fatal("expected a Secret with other data to be updated")
+	}
+}
@@ -0,0 +1,139 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// SetScheduling sets the scheduling constraints of the pod spec to those of
+// the function, clearing any which are no longer defined.
+func SetScheduling(spec *corev1.PodSpec, s fn.Scheduling) {
+	spec.NodeSelector = nil
+	if len(s.NodeSelector) > 0 {
+		spec.NodeSelector = make(map[string]string, len(s.NodeSelector))
+		for k, v := range s.NodeSelector {
+			spec.NodeSelector[k] = v
+		}
+	}
+
+	spec.Tolerations = nil
+	for _, t := range s.Tolerations {
+		spec.Tolerations = append(spec.Tolerations, corev1.Toleration{
+			Key:               t.Key,
+			Operator:          corev1.TolerationOperator(t.Operator),
+			Value:             t.Value,
+			Effect:            corev1.TaintEffect(t.Effect),
+			TolerationSeconds: t.TolerationSeconds,
+		})
+	}
+
+	spec.Affinity = nil
+	if a := s.Affinity; a != nil {
+		spec.Affinity = &corev1.Affinity{
+			PodAffinity:     podAffinity(a.PodAffinity),
+			PodAntiAffinity: podAntiAffinity(a.PodAntiAffinity),
+		}
+		if n := a.NodeAffinity; n != nil {
+			spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+			if n.Required != nil {
+				spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+				for _, term := range n.Required.NodeSelectorTerms {
+					spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = append(
+						spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, nodeSelectorTerm(term))
+				}
+			}
+			for _, p := range n.Preferred {
+				spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+					spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+					corev1.PreferredSchedulingTerm{Weight: p.Weight, Preference: nodeSelectorTerm(p.Preference)})
+			}
+		}
+	}
+
+	spec.TopologySpreadConstraints = nil
+	for _, c := range s.TopologySpreadConstraints {
+		spec.TopologySpreadConstraints = append(spec.TopologySpreadConstraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           c.MaxSkew,
+			TopologyKey:       c.TopologyKey,
+			WhenUnsatisfiable: corev1.UnsatisfiableConstraintAction(c.WhenUnsatisfiable),
+			LabelSelector:     labelSelector(c.LabelSelector),
+			MinDomains:        c.MinDomains,
+		})
+	}
+
+	spec.PriorityClassName = s.PriorityClassName
+
+	spec.RuntimeClassName = nil
+	if s.RuntimeClassName != "" {
+		name := s.RuntimeClassName
+		spec.RuntimeClassName = &name
+	}
+}
+
+func nodeSelectorTerm(t fn.NodeSelectorTerm) (term corev1.NodeSelectorTerm) {
+	for _, r := range t.MatchExpressions {
+		term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      r.Key,
+			Operator: corev1.NodeSelectorOperator(r.Operator),
+			Values:   r.Values,
+		})
+	}
+	return
+}
+
+func podAffinityTerms(p *fn.PodAffinity) (required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm) {
+	for _, t := range p.Required {
+		required = append(required, podAffinityTerm(t))
+	}
+	for _, w := range p.Preferred {
+		preferred = append(preferred, corev1.WeightedPodAffinityTerm{Weight: w.Weight, PodAffinityTerm: podAffinityTerm(w.PodAffinityTerm)})
+	}
+	return
+}
+
+func podAffinity(p *fn.PodAffinity) *corev1.PodAffinity {
+	if p == nil {
+		return nil
+	}
+	required, preferred := podAffinityTerms(p)
+	return &corev1.PodAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution:  required,
+		PreferredDuringSchedulingIgnoredDuringExecution: preferred,
+	}
+}
+
+func podAntiAffinity(p *fn.PodAffinity) *corev1.PodAntiAffinity {
+	if p == nil {
+		return nil
+	}
+	required, preferred := podAffinityTerms(p)
+	return &corev1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution:  required,
+		PreferredDuringSchedulingIgnoredDuringExecution: preferred,
+	}
+}
+
+func podAffinityTerm(t fn.PodAffinityTerm) corev1.PodAffinityTerm {
+	return corev1.PodAffinityTerm{
+		LabelSelector: labelSelector(t.LabelSelector),
+		Namespaces:    t.Namespaces,
+		TopologyKey:   t.TopologyKey,
+	}
+}
+
+func labelSelector(s *fn.LabelSelector) *metav1.LabelSelector {
+	if s == nil {
+		return nil
+	}
+	selector := &metav1.LabelSelector{MatchLabels: s.MatchLabels}
+	for _, r := range s.MatchExpressions {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      r.Key,
+			Operator: metav1.LabelSelectorOperator(r.Operator),
+			Values:   r.Values,
+		})
+	}
+	return selector
+}
@@ -0,0 +1,67 @@
+package k8s_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// TestSetScheduling ensures the function's scheduling constraints are set on
+// the pod spec, and that those no longer defined are cleared.
+func TestSetScheduling(t *testing.T) {
+	spec := &corev1.PodSpec{}
+	k8s.SetScheduling(spec, fn.Scheduling{
+		NodeSelector: map[string]string{"node-pool": "spot"},
+		Tolerations:  []fn.Toleration{{Key: "spot", Operator: "Exists", Effect: "NoSchedule"}},
+		Affinity: &fn.Affinity{
+			NodeAffinity: &fn.NodeAffinity{
+				Required: &fn.NodeSelector{NodeSelectorTerms: []fn.NodeSelectorTerm{{
+					MatchExpressions: []fn.SelectorRequirement{{Key: "kubernetes.io/arch", Operator: "In", Values: []string{"arm64"}}},
+				}}},
+			},
+			PodAntiAffinity: &fn.PodAffinity{
+				Preferred: []fn.WeightedPodAffinityTerm{{Weight: 50, PodAffinityTerm: fn.PodAffinityTerm{
+					LabelSelector: &fn.LabelSelector{MatchLabels: map[string]string{"function.knative.dev/name": "testing"}},
+					TopologyKey:   "kubernetes.io/hostname",
+				}}},
+			},
+		},
+		TopologySpreadConstraints: []fn.TopologySpreadConstraint{{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: "ScheduleAnyway"}},
+		PriorityClassName:         "high",
+		RuntimeClassName:          "gvisor",
+	})
+
+	if spec.NodeSelector["node-pool"] != "spot" {
+		t.Fatalf("unexpected node selector %v", spec.NodeSelector)
+	}
+	if len(spec.Tolerations) != 1 || spec.Tolerations[0].Operator != corev1.TolerationOpExists || spec.Tolerations[0].Effect != corev1.TaintEffectNoSchedule {
+		t.Fatalf("unexpected tolerations %+v", spec.Tolerations)
+	}
+	if spec.Affinity == nil || spec.Affinity.PodAffinity != nil {
+		t.Fatalf("unexpected affinity %+v", spec.Affinity)
+	}
+	terms := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 || terms[0].MatchExpressions[0].Operator != corev1.NodeSelectorOpIn || terms[0].MatchExpressions[0].Values[0] != "arm64" {
+		t.Fatalf("unexpected node affinity terms %+v", terms)
+	}
+	preferred := spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	if len(preferred) != 1 || preferred[0].Weight != 50 || preferred[0].PodAffinityTerm.LabelSelector.MatchLabels["function.knative.dev/name"] != "testing" {
+		t.Fatalf("unexpected pod anti-affinity %+v", preferred)
+	}
+	if len(spec.TopologySpreadConstraints) != 1 || spec.TopologySpreadConstraints[0].WhenUnsatisfiable != corev1.ScheduleAnyway {
+		t.Fatalf("unexpected topology spread constraints %+v", spec.TopologySpreadConstraints)
+	}
+	if spec.PriorityClassName != "high" || spec.RuntimeClassName == nil || *spec.RuntimeClassName != "gvisor" {
+		t.Fatalf("unexpected classes %q %v", spec.PriorityClassName, spec.RuntimeClassName)
+	}
+
+	// Constraints removed from the function are cleared
+	k8s.SetScheduling(spec, fn.Scheduling{})
+	if spec.NodeSelector != nil || spec.Tolerations != nil || spec.Affinity != nil ||
+		spec.TopologySpreadConstraints != nil || spec.PriorityClassName != "" || spec.RuntimeClassName != nil {
+		t.Fatalf("expected scheduling to be cleared, got %+v", spec)
+	}
+}
@@ -16,7 +16,7 @@ import (
 )
 
 func GetSecret(ctx context.Context, name, namespaceOverride string) (*corev1.Secret, error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +57,7 @@ func ListSecretsNamesIfConnected(ctx context.Context, namespaceOverride string)
 }
 
 func listSecretsNames(ctx context.Context, namespaceOverride string) (names []string, err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -75,7 +75,7 @@ func listSecretsNames(ctx context.Context, namespaceOverride string) (names []st
 }
 
 func DeleteSecrets(ctx context.Context, namespaceOverride string, listOptions metav1.ListOptions) (err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -105,7 +105,7 @@ func EnsureDockerRegistrySecretExist(ctx context.Context, name, namespaceOverrid
 }
 
 func EnsureSecretExist(ctx context.Context, secret corev1.Secret, namespaceOverride string) (err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -120,19 +120,24 @@ func EnsureSecretExist(ctx context.Context, secret corev1.Secret, namespaceOverr
 		secretNotFound = true
 	}
 
-	// TODO we should also compare labels and annotations
-	if secretNotFound || !equality.Semantic.DeepDerivative(existingSecret.Data, secret.Data) {
-		// Decide whether create or update
-		if secretNotFound {
-			_, err = client.CoreV1().Secrets(namespace).Create(ctx, &secret, metav1.CreateOptions{})
-		} else {
-			_, err = client.CoreV1().Secrets(namespace).Update(ctx, &secret, metav1.UpdateOptions{})
-		}
+	if secretNotFound {
+		_, err = client.CoreV1().Secrets(namespace).Create(ctx, &secret, metav1.CreateOptions{})
+	} else if !secretUpToDate(*existingSecret, secret) {
+		secret.ResourceVersion = existingSecret.ResourceVersion
+		_, err = client.CoreV1().Secrets(namespace).Update(ctx, &secret, metav1.UpdateOptions{})
 	}
 
 	return
 }
 
+// secretUpToDate returns true if the existing Secret has the data and labels
+// of the wanted one.  Labels of the existing Secret which are not wanted are
+// ignored.
+func secretUpToDate(existing, wanted corev1.Secret) bool {
+	return equality.Semantic.DeepDerivative(existing.Data, wanted.Data) &&
+		equality.Semantic.DeepDerivative(wanted.Labels, existing.Labels)
+}
+
 // --- Helper methods for DockerConfigJson type of Secret
 // Taken from (and converted to private):
 // https://github.com/kubernetes/kubectl/blob/10c4667470db41ce138b9aae4e9590dbd7f1930d/pkg/cmd/create/create_secret_docker.go#L290
@@ -1,6 +1,8 @@
 package k8s
 
 import (
+	"context"
+
 	"github.com/Masterminds/semver"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -8,9 +10,9 @@ import (
 
 var oneTwentyFour = semver.MustParse("1.24")
 
-func defaultPodSecurityContext() *corev1.PodSecurityContext {
+func defaultPodSecurityContext(ctx context.Context) *corev1.PodSecurityContext {
 	// change ownership of the mounted volume to the first non-root user uid=1000
-	if IsOpenShift() {
+	if IsOpenShift(ctx) {
 		return nil
 	}
 	runAsUser := int64(1001)
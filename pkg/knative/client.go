@@ -1,6 +1,7 @@
 package knative
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -17,9 +18,9 @@ const (
 	DefaultErrorWindowTimeout = 2 * time.Second
 )
 
-func NewServingClient(namespace string) (clientservingv1.KnServingClient, error) {
+func NewServingClient(ctx context.Context, namespace string) (clientservingv1.KnServingClient, error) {
 
-	restConfig, err := k8s.GetClientConfig().ClientConfig()
+	restConfig, err := k8s.GetClientConfig(ctx).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new serving client: %v", err)
 	}
@@ -34,9 +35,9 @@ func NewServingClient(namespace string) (clientservingv1.KnServingClient, error)
 	return client, nil
 }
 
-func NewEventingClient(namespace string) (clienteventingv1.KnEventingClient, error) {
+func NewEventingClient(ctx context.Context, namespace string) (clienteventingv1.KnEventingClient, error) {
 
-	restConfig, err := k8s.GetClientConfig().ClientConfig()
+	restConfig, err := k8s.GetClientConfig(ctx).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new serving client: %v", err)
 	}
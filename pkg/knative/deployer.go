@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"regexp"
 	"strings"
 	"time"
 
@@ -15,23 +14,22 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/client/pkg/flags"
 	servingclientlib "knative.dev/client/pkg/serving"
 	clientservingv1 "knative.dev/client/pkg/serving/v1"
 	"knative.dev/client/pkg/wait"
 	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
 )
 
-const LIVENESS_ENDPOINT = "/health/liveness"
-const READINESS_ENDPOINT = "/health/readiness"
+const LIVENESS_ENDPOINT = k8s.DefaultLivenessEndpoint
+const READINESS_ENDPOINT = k8s.DefaultReadinessEndpoint
 
 type DeployDecorator interface {
 	UpdateAnnotations(fn.Function, map[string]string) map[string]string
@@ -45,14 +43,18 @@ type Deployer struct {
 	verbose bool
 
 	decorator DeployDecorator
+
+	// secretsKeyFile is the key used to decrypt the secrets managed with
+	// the function.
+	secretsKeyFile string
 }
 
 // ActiveNamespace attempts to read the Kubernetes active namespace.
 // Missing configs or not having an active Kubernetes configuration are
 // equivalent to having no default namespace (empty string).
-func ActiveNamespace() string {
+func ActiveNamespace(ctx context.Context) string {
 	// Get client config, if it exists, and from that the namespace
-	ns, _, err := k8s.GetClientConfig().Namespace()
+	ns, _, err := k8s.GetClientConfig(ctx).Namespace()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: unable to get active namespace: %v\n", err)
 	}
@@ -81,6 +83,14 @@ func WithDeployerDecorator(decorator DeployDecorator) DeployerOpt {
 	}
 }
 
+// WithDeployerSecretsKey sets the path to the key used to decrypt the
+// secrets managed with the function (see fn.ResourcesDir).
+func WithDeployerSecretsKey(keyFile string) DeployerOpt {
+	return func(d *Deployer) {
+		d.secretsKeyFile = keyFile
+	}
+}
+
 // Checks the status of the "user-container" for the ImagePullBackOff reason meaning that
 // the container image is not reachable probably because a private registry is being used.
 func (d *Deployer) isImageInPrivateRegistry(ctx context.Context, client clientservingv1.KnServingClient, f fn.Function) bool {
@@ -88,7 +98,7 @@ func (d *Deployer) isImageInPrivateRegistry(ctx context.Context, client clientse
 	if err != nil {
 		return false
 	}
-	k8sClient, err := k8s.NewKubernetesClientset()
+	k8sClient, err := k8s.NewKubernetesClientset(ctx)
 	if err != nil {
 		return false
 	}
@@ -111,7 +121,7 @@ func (d *Deployer) isImageInPrivateRegistry(ctx context.Context, client clientse
 	return false
 }
 
-func onClusterFix(f fn.Function) fn.Function {
+func onClusterFix(ctx context.Context, f fn.Function) fn.Function {
 	// This only exists because of a bootstapping problem with On-Cluster
 	// builds:  It appears that, when sending a function to be built on-cluster
 	// the target namespace is not being transmitted in the pipeline
@@ -120,13 +130,13 @@ func onClusterFix(f fn.Function) fn.Function {
 	// earlier versions of this logic relied entirely on the current
 	// kubernetes context.
 	if f.Namespace == "" && f.Deploy.Namespace == "" {
-		f.Namespace, _ = k8s.GetDefaultNamespace()
+		f.Namespace, _ = k8s.GetDefaultNamespace(ctx)
 	}
 	return f
 }
 
 func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
-	f = onClusterFix(f)
+	f = onClusterFix(ctx, f)
 	// Choosing f.Namespace vs f.Deploy.Namespace:
 	// This is minimal logic currently required of all deployer impls.
 	// If f.Namespace is defined, this is the (possibly new) target
@@ -145,14 +155,27 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 	}
 
 	// Clients
-	client, err := NewServingClient(namespace)
+	client, err := NewServingClient(ctx, namespace)
+	if err != nil {
+		return fn.DeploymentResult{}, err
+	}
+	eventingClient, err := NewEventingClient(ctx, namespace)
+	if err != nil {
+		return fn.DeploymentResult{}, err
+	}
+	domainMappingClient, err := NewDomainMappingClient(ctx, namespace)
 	if err != nil {
 		return fn.DeploymentResult{}, err
 	}
-	eventingClient, err := NewEventingClient(namespace)
+	networkPolicyClient, err := NewNetworkPolicyClient(ctx, namespace)
 	if err != nil {
 		return fn.DeploymentResult{}, err
 	}
+	dynamicClient, err := k8s.NewDynamicClient(ctx)
+	if err != nil {
+		return fn.DeploymentResult{}, err
+	}
+	authorizationPolicyClient := dynamicClient.Resource(authorizationPolicyResource).Namespace(istioRootNamespace)
 
 	var outBuff SynchronizedBuffer
 	var out io.Writer = &outBuff
@@ -179,7 +202,13 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 				return fn.DeploymentResult{}, err
 			}
 
-			err = checkResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
+			err = k8s.EnsureFunctionResources(ctx, f, namespace, d.secretsKeyFile)
+			if err != nil {
+				err = fmt.Errorf("knative deployer failed to create the function's resources: %v", err)
+				return fn.DeploymentResult{}, err
+			}
+
+			err = k8s.CheckResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
 			if err != nil {
 				err = fmt.Errorf("knative deployer failed to generate the Knative Service: %v", err)
 				return fn.DeploymentResult{}, err
@@ -252,6 +281,16 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 				return fn.DeploymentResult{}, err
 			}
 
+			err = reconcileDomainMappings(ctx, domainMappingClient, f, namespace)
+			if err != nil {
+				return fn.DeploymentResult{}, err
+			}
+
+			err = reconcileAllowList(ctx, networkPolicyClient, authorizationPolicyClient, f, namespace, serviceAccountOfService())
+			if err != nil {
+				return fn.DeploymentResult{}, err
+			}
+
 			if d.verbose {
 				fmt.Printf("Function deployed in namespace %q and exposed at URL:\n%s\n", namespace, route.Status.URL.String())
 			}
@@ -271,23 +310,38 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 		referencedConfigMaps := sets.New[string]()
 		referencedPVCs := sets.New[string]()
 
-		newEnv, newEnvFrom, err := processEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+		runEnvs, err := f.RunEnvs()
+		if err != nil {
+			return fn.DeploymentResult{}, err
+		}
+		newEnv, newEnvFrom, err := k8s.ProcessEnvs(f.Root, runEnvs, &referencedSecrets, &referencedConfigMaps)
+		if err != nil {
+			return fn.DeploymentResult{}, err
+		}
+
+		newVolumes, newVolumeMounts, err := k8s.ProcessVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
 		if err != nil {
 			return fn.DeploymentResult{}, err
 		}
 
-		newVolumes, newVolumeMounts, err := processVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+		sidecars, initContainers, err := k8s.ProcessContainers(f, newVolumeMounts, &referencedSecrets, &referencedConfigMaps)
 		if err != nil {
 			return fn.DeploymentResult{}, err
 		}
 
-		err = checkResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
+		err = k8s.EnsureFunctionResources(ctx, f, namespace, d.secretsKeyFile)
+		if err != nil {
+			err = fmt.Errorf("knative deployer failed to update the function's resources: %v", err)
+			return fn.DeploymentResult{}, err
+		}
+
+		err = k8s.CheckResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
 		if err != nil {
 			err = fmt.Errorf("knative deployer failed to update the Knative Service: %v", err)
 			return fn.DeploymentResult{}, err
 		}
 
-		_, err = client.UpdateServiceWithRetry(ctx, f.Name, updateService(f, previousService, newEnv, newEnvFrom, newVolumes, newVolumeMounts, d.decorator), 3)
+		_, err = client.UpdateServiceWithRetry(ctx, f.Name, updateService(f, previousService, newEnv, newEnvFrom, newVolumes, newVolumeMounts, sidecars, initContainers, d.decorator), 3)
 		if err != nil {
 			err = fmt.Errorf("knative deployer failed to update the Knative Service: %v", err)
 			return fn.DeploymentResult{}, err
@@ -316,6 +370,16 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 			return fn.DeploymentResult{}, err
 		}
 
+		err = reconcileDomainMappings(ctx, domainMappingClient, f, namespace)
+		if err != nil {
+			return fn.DeploymentResult{}, err
+		}
+
+		err = reconcileAllowList(ctx, networkPolicyClient, authorizationPolicyClient, f, namespace, serviceAccountOfService())
+		if err != nil {
+			return fn.DeploymentResult{}, err
+		}
+
 		return fn.DeploymentResult{
 			Status:    fn.Updated,
 			URL:       route.Status.URL.String(),
@@ -375,29 +439,12 @@ func createTriggers(ctx context.Context, f fn.Function, client clientservingv1.K
 	return nil
 }
 
-func probeFor(url string) *corev1.Probe {
-	return &corev1.Probe{
-		ProbeHandler: corev1.ProbeHandler{
-			HTTPGet: &corev1.HTTPGetAction{
-				Path: url,
-			},
-		},
-	}
-}
-
-func setHealthEndpoints(f fn.Function, c *corev1.Container) *corev1.Container {
-	// Set the defaults
-	c.LivenessProbe = probeFor(LIVENESS_ENDPOINT)
-	c.ReadinessProbe = probeFor(READINESS_ENDPOINT)
-
-	// If specified in func.yaml, the provided values override the defaults
-	if f.Deploy.HealthEndpoints.Liveness != "" {
-		c.LivenessProbe = probeFor(f.Deploy.HealthEndpoints.Liveness)
-	}
-	if f.Deploy.HealthEndpoints.Readiness != "" {
-		c.ReadinessProbe = probeFor(f.Deploy.HealthEndpoints.Readiness)
+// setProgressDeadline sets the revision's progress deadline, the time it may
+// take to become ready, from the function's start timeout if defined.
+func setProgressDeadline(f fn.Function, revisionAnnotations map[string]string) {
+	if f.Run.StartTimeout > 0 {
+		revisionAnnotations[serving.ProgressDeadlineAnnotationKey] = f.Run.StartTimeout.String()
 	}
-	return c
 }
 
 func generateNewService(f fn.Function, decorator DeployDecorator) (*v1.Service, error) {
@@ -419,25 +466,34 @@ func generateNewService(f fn.Function, decorator DeployDecorator) (*v1.Service,
 			SeccompProfile:           &seccompProfile,
 		},
 	}
-	setHealthEndpoints(f, &container)
+	k8s.SetHealthEndpoints(f, &container)
 
 	referencedSecrets := sets.New[string]()
 	referencedConfigMaps := sets.New[string]()
 	referencedPVC := sets.New[string]()
 
-	newEnv, newEnvFrom, err := processEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+	runEnvs, err := f.RunEnvs()
+	if err != nil {
+		return nil, err
+	}
+	newEnv, newEnvFrom, err := k8s.ProcessEnvs(f.Root, runEnvs, &referencedSecrets, &referencedConfigMaps)
 	if err != nil {
 		return nil, err
 	}
 	container.Env = newEnv
 	container.EnvFrom = newEnvFrom
 
-	newVolumes, newVolumeMounts, err := processVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVC)
+	newVolumes, newVolumeMounts, err := k8s.ProcessVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVC)
 	if err != nil {
 		return nil, err
 	}
 	container.VolumeMounts = newVolumeMounts
 
+	sidecars, initContainers, err := k8s.ProcessContainers(f, newVolumeMounts, &referencedSecrets, &referencedConfigMaps)
+	if err != nil {
+		return nil, err
+	}
+
 	labels, err := generateServiceLabels(f, decorator)
 	if err != nil {
 		return nil, err
@@ -451,6 +507,7 @@ func generateNewService(f fn.Function, decorator DeployDecorator) (*v1.Service,
 	for k, v := range annotations {
 		revisionAnnotations[k] = v
 	}
+	setProgressDeadline(f, revisionAnnotations)
 
 	service := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -479,6 +536,10 @@ func generateNewService(f fn.Function, decorator DeployDecorator) (*v1.Service,
 		},
 	}
 
+	setContainers(&service.Spec.Template, nil, sidecars, initContainers)
+	k8s.SetScheduling(&service.Spec.Template.Spec.PodSpec, f.Deploy.Scheduling)
+	setVisibility(service, f.Deploy.Visibility)
+
 	err = setServiceOptions(&service.Spec.Template, f.Deploy.Options)
 	if err != nil {
 		return service, err
@@ -555,7 +616,7 @@ func daprAnnotations(appid string) map[string]string {
 	return aa
 }
 
-func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.EnvVar, newEnvFrom []corev1.EnvFromSource, newVolumes []corev1.Volume, newVolumeMounts []corev1.VolumeMount, decorator DeployDecorator) func(service *v1.Service) (*v1.Service, error) {
+func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.EnvVar, newEnvFrom []corev1.EnvFromSource, newVolumes []corev1.Volume, newVolumeMounts []corev1.VolumeMount, sidecars, initContainers []corev1.Container, decorator DeployDecorator) func(service *v1.Service) (*v1.Service, error) {
 	return func(service *v1.Service) (*v1.Service, error) {
 		// Removing the name so the k8s server can fill it in with generated name,
 		// this prevents conflicts in Revision name when updating the KService from multiple places.
@@ -569,7 +630,9 @@ func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.E
 		for k, v := range annotations {
 			revisionAnnotations[k] = v
 		}
+		setProgressDeadline(f, revisionAnnotations)
 
+		previousAnnotations := service.Spec.Template.ObjectMeta.Annotations
 		service.ObjectMeta.Annotations = annotations
 		service.Spec.Template.ObjectMeta.Annotations = revisionAnnotations
 
@@ -586,7 +649,7 @@ func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.E
 		// config. At runtime this configuration file could be consulted. I don't
 		// know what this would mean for developers using the func library directly.
 		cp := &service.Spec.Template.Spec.Containers[0]
-		setHealthEndpoints(f, cp)
+		k8s.SetHealthEndpoints(f, cp)
 
 		err := setServiceOptions(&service.Spec.Template, f.Deploy.Options)
 		if err != nil {
@@ -600,6 +663,7 @@ func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.E
 
 		service.ObjectMeta.Labels = labels
 		service.Spec.Template.ObjectMeta.Labels = labels
+		setVisibility(service, f.Deploy.Visibility)
 
 		err = flags.UpdateImage(&service.Spec.Template.Spec.PodSpec, f.Deploy.Image)
 		if err != nil {
@@ -609,397 +673,56 @@ func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.E
 		cp.Env = newEnv
 		cp.EnvFrom = newEnvFrom
 		cp.VolumeMounts = newVolumeMounts
+		setContainers(&service.Spec.ConfigurationSpec.Template, previousAnnotations, sidecars, initContainers)
+		k8s.SetScheduling(&service.Spec.ConfigurationSpec.Template.Spec.PodSpec, f.Deploy.Scheduling)
 		service.Spec.ConfigurationSpec.Template.Spec.Volumes = newVolumes
 		service.Spec.ConfigurationSpec.Template.Spec.PodSpec.ServiceAccountName = f.Deploy.ServiceAccountName
 		return service, nil
 	}
 }
 
-// processEnvs generates array of EnvVars and EnvFromSources from a function config
-// envs:
-//   - name: EXAMPLE1                            # ENV directly from a value
-//     value: value1
-//   - name: EXAMPLE2                            # ENV from the local ENV var
-//     value: {{ env:MY_ENV }}
-//   - name: EXAMPLE3
-//     value: {{ secret:example-secret:key }}    # ENV from a key in Secret
-//   - value: {{ secret:example-secret }}        # all ENVs from Secret
-//   - name: EXAMPLE4
-//     value: {{ configMap:configMapName:key }}  # ENV from a key in ConfigMap
-//   - value: {{ configMap:configMapName }}      # all key-pair values from ConfigMap are set as ENV
-func processEnvs(envs []fn.Env, referencedSecrets, referencedConfigMaps *sets.Set[string]) ([]corev1.EnvVar, []corev1.EnvFromSource, error) {
-
-	envs = withOpenAddress(envs) // prepends ADDRESS=0.0.0.0 if not extant
-
-	envVars := []corev1.EnvVar{{Name: "BUILT", Value: time.Now().Format("20060102T150405")}}
-	envFrom := []corev1.EnvFromSource{}
-
-	for _, env := range envs {
-		if env.Name == nil && env.Value != nil {
-			// all key-pair values from secret/configMap are set as ENV, eg. {{ secret:secretName }} or {{ configMap:configMapName }}
-			if strings.HasPrefix(*env.Value, "{{") {
-				envFromSource, err := createEnvFromSource(*env.Value, referencedSecrets, referencedConfigMaps)
-				if err != nil {
-					return nil, nil, err
-				}
-				envFrom = append(envFrom, *envFromSource)
-				continue
-			}
-		} else if env.Name != nil && env.Value != nil {
-			if strings.HasPrefix(*env.Value, "{{") {
-				slices := strings.Split(strings.Trim(*env.Value, "{} "), ":")
-				if len(slices) == 3 {
-					// ENV from a key in secret/configMap, eg. FOO={{ secret:secretName:key }} FOO={{ configMap:configMapName.key }}
-					valueFrom, err := createEnvVarSource(slices, referencedSecrets, referencedConfigMaps)
-					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, ValueFrom: valueFrom})
-					if err != nil {
-						return nil, nil, err
-					}
-					continue
-				} else if len(slices) == 2 {
-					// ENV from the local ENV var, eg. FOO={{ env:LOCAL_ENV }}
-					localValue, err := processLocalEnvValue(*env.Value)
-					if err != nil {
-						return nil, nil, err
-					}
-					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: localValue})
-					continue
-				}
-			} else {
-				// a standard ENV with key and value, eg. FOO=bar
-				envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: *env.Value})
-				continue
-			}
-		}
-		return nil, nil, fmt.Errorf("unsupported env source entry \"%v\"", env)
-	}
-
-	return envVars, envFrom, nil
-}
-
-// withOpenAddresss prepends ADDRESS=0.0.0.0 to the envs if not present.
-//
-// This is combined with the value of PORT at runtime to determine the full
-// Listener address on which a Function will listen tcp requests.
-//
-// Runtimes should, by default, only listen on the loopback interface by
-// default, as they may be `func run` locally, for security purposes.
-// This environment vriable instructs the runtimes to listen on all interfaces
-// by default when actually being deployed, since they will need to actually
-// listen for client requests and for health readiness/liveness probes.
-//
-// Should a user wish to securely open their function to only receive requests
-// on a specific interface, such as a WireGuar-encrypted mesh network which
-// presents as a specific interface, that can be achieved by setting the
-// ADDRESS value as an environment variable on their function to the interface
-// on which to listen.
-//
-// NOTE this env is currently only respected by scaffolded Go functions, because
-// they are the only ones which support being `func run` locally.  Other
-// runtimes will respect the value as they are updated to support scaffolding.
-func withOpenAddress(ee []fn.Env) []fn.Env {
-	// TODO: this is unnecessarily complex due to both key and value of the
-	// envs slice being being pointers.  There is an outstanding tech-debt item
-	// to remove pointers from Function Envs, Volumes, Labels, and Options.
-	var found bool
-	for _, e := range ee {
-		if e.Name != nil && *e.Name == "ADDRESS" {
-			found = true
-			break
-		}
-	}
-	if !found {
-		k := "ADDRESS"
-		v := "0.0.0.0"
-		ee = append(ee, fn.Env{Name: &k, Value: &v})
-	}
-	return ee
-}
-
-func createEnvFromSource(value string, referencedSecrets, referencedConfigMaps *sets.Set[string]) (*corev1.EnvFromSource, error) {
-	slices := strings.Split(strings.Trim(value, "{} "), ":")
-	if len(slices) != 2 {
-		return nil, fmt.Errorf("env requires a value in form \"resourceType:name\" where \"resourceType\" can be one of \"configMap\" or \"secret\"; got %q", slices)
-	}
-
-	envVarSource := corev1.EnvFromSource{}
-
-	typeString := strings.TrimSpace(slices[0])
-	sourceName := strings.TrimSpace(slices[1])
-
-	var sourceType string
-
-	switch typeString {
-	case "configMap":
-		sourceType = "ConfigMap"
-		envVarSource.ConfigMapRef = &corev1.ConfigMapEnvSource{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: sourceName,
-			}}
-
-		if !referencedConfigMaps.Has(sourceName) {
-			referencedConfigMaps.Insert(sourceName)
-		}
-	case "secret":
-		sourceType = "Secret"
-		envVarSource.SecretRef = &corev1.SecretEnvSource{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: sourceName,
-			}}
-		if !referencedSecrets.Has(sourceName) {
-			referencedSecrets.Insert(sourceName)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported env source type %q; supported source types are \"configMap\" or \"secret\"", slices[0])
-	}
-
-	if len(sourceName) == 0 {
-		return nil, fmt.Errorf("the name of %s cannot be an empty string", sourceType)
-	}
-
-	return &envVarSource, nil
-}
-
-func createEnvVarSource(slices []string, referencedSecrets, referencedConfigMaps *sets.Set[string]) (*corev1.EnvVarSource, error) {
-
-	if len(slices) != 3 {
-		return nil, fmt.Errorf("env requires a value in form \"resourceType:name:key\" where \"resourceType\" can be one of \"configMap\" or \"secret\"; got %q", slices)
-	}
-
-	envVarSource := corev1.EnvVarSource{}
-
-	typeString := strings.TrimSpace(slices[0])
-	sourceName := strings.TrimSpace(slices[1])
-	sourceKey := strings.TrimSpace(slices[2])
-
-	var sourceType string
-
-	switch typeString {
-	case "configMap":
-		sourceType = "ConfigMap"
-		envVarSource.ConfigMapKeyRef = &corev1.ConfigMapKeySelector{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: sourceName,
-			},
-			Key: sourceKey}
-
-		if !referencedConfigMaps.Has(sourceName) {
-			referencedConfigMaps.Insert(sourceName)
-		}
-	case "secret":
-		sourceType = "Secret"
-		envVarSource.SecretKeyRef = &corev1.SecretKeySelector{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: sourceName,
-			},
-			Key: sourceKey}
-
-		if !referencedSecrets.Has(sourceName) {
-			referencedSecrets.Insert(sourceName)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported env source type %q; supported source types are \"configMap\" or \"secret\"", slices[0])
-	}
-
-	if len(sourceName) == 0 {
-		return nil, fmt.Errorf("the name of %s cannot be an empty string", sourceType)
-	}
-
-	if len(sourceKey) == 0 {
-		return nil, fmt.Errorf("the key referenced by resource %s %q cannot be an empty string", sourceType, sourceName)
-	}
-
-	return &envVarSource, nil
-}
-
-var evRegex = regexp.MustCompile(`^{{\s*(\w+)\s*:(\w+)\s*}}$`)
-
-const (
-	ctxIdx = 1
-	valIdx = 2
-)
-
-func processLocalEnvValue(val string) (string, error) {
-	match := evRegex.FindStringSubmatch(val)
-	if len(match) > valIdx {
-		if match[ctxIdx] != "env" {
-			return "", fmt.Errorf("allowed env value entry is \"{{ env:LOCAL_VALUE }}\"; got: %q", match[ctxIdx])
-		}
-		if v, ok := os.LookupEnv(match[valIdx]); ok {
-			return v, nil
-		} else {
-			return "", fmt.Errorf("required local environment variable %q is not set", match[valIdx])
-		}
-	} else {
-		return val, nil
-	}
-}
-
-// / processVolumes generates Volumes and VolumeMounts from a function config
-// volumes:
-//   - secret: example-secret                              # mount Secret as Volume
-//     path: /etc/secret-volume
-//   - configMap: example-configMap                        # mount ConfigMap as Volume
-//     path: /etc/configMap-volume
-//   - persistentVolumeClaim: { claimName: example-pvc }   # mount PersistentVolumeClaim as Volume
-//     path: /etc/secret-volume
-//   - emptyDir: {}                                         # mount EmptyDir as Volume
-//     path: /etc/configMap-volume
-func processVolumes(volumes []fn.Volume, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string]) ([]corev1.Volume, []corev1.VolumeMount, error) {
-
-	createdVolumes := sets.NewString()
-	usedPaths := sets.NewString()
-
-	newVolumes := []corev1.Volume{}
-	newVolumeMounts := []corev1.VolumeMount{}
-
-	for _, vol := range volumes {
-
-		volumeName := ""
-
-		if vol.Secret != nil {
-			volumeName = "secret-" + *vol.Secret
-
-			if !createdVolumes.Has(volumeName) {
-				newVolumes = append(newVolumes, corev1.Volume{
-					Name: volumeName,
-					VolumeSource: corev1.VolumeSource{
-						Secret: &corev1.SecretVolumeSource{
-							SecretName: *vol.Secret,
-						},
-					},
-				})
-				createdVolumes.Insert(volumeName)
-
-				if !referencedSecrets.Has(*vol.Secret) {
-					referencedSecrets.Insert(*vol.Secret)
-				}
-			}
-		} else if vol.ConfigMap != nil {
-			volumeName = "config-map-" + *vol.ConfigMap
-
-			if !createdVolumes.Has(volumeName) {
-				newVolumes = append(newVolumes, corev1.Volume{
-					Name: volumeName,
-					VolumeSource: corev1.VolumeSource{
-						ConfigMap: &corev1.ConfigMapVolumeSource{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: *vol.ConfigMap,
-							},
-						},
-					},
-				})
-				createdVolumes.Insert(volumeName)
-
-				if !referencedConfigMaps.Has(*vol.ConfigMap) {
-					referencedConfigMaps.Insert(*vol.ConfigMap)
-				}
-			}
-		} else if vol.PersistentVolumeClaim != nil {
-			volumeName = "pvc-" + *vol.PersistentVolumeClaim.ClaimName
-
-			if !createdVolumes.Has(volumeName) {
-				newVolumes = append(newVolumes, corev1.Volume{
-					Name: volumeName,
-					VolumeSource: corev1.VolumeSource{
-						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-							ClaimName: *vol.PersistentVolumeClaim.ClaimName,
-							ReadOnly:  vol.PersistentVolumeClaim.ReadOnly,
-						},
-					},
-				})
-				createdVolumes.Insert(volumeName)
-
-				if !referencedPVCs.Has(*vol.PersistentVolumeClaim.ClaimName) {
-					referencedPVCs.Insert(*vol.PersistentVolumeClaim.ClaimName)
-				}
-			}
-		} else if vol.EmptyDir != nil {
-			volumeName = "empty-dir-" + rand.String(7)
-
-			if !createdVolumes.Has(volumeName) {
-
-				var sizeLimit *resource.Quantity
-				if vol.EmptyDir.SizeLimit != nil {
-					sl, err := resource.ParseQuantity(*vol.EmptyDir.SizeLimit)
-					if err != nil {
-						return nil, nil, fmt.Errorf("invalid quantity for sizeLimit: %s. Error: %s", *vol.EmptyDir.SizeLimit, err)
-					}
-					sizeLimit = &sl
-				}
-
-				newVolumes = append(newVolumes, corev1.Volume{
-					Name: volumeName,
-					VolumeSource: corev1.VolumeSource{
-						EmptyDir: &corev1.EmptyDirVolumeSource{
-							Medium:    corev1.StorageMedium(vol.EmptyDir.Medium),
-							SizeLimit: sizeLimit,
-						},
-					},
-				})
-				createdVolumes.Insert(volumeName)
-			}
-		}
+// initContainersAnnotation of the revision template lists the names of the
+// function's init containers, such that those added by others are retained
+// when the function is updated.
+const initContainersAnnotation = "function.knative.dev/init-containers"
 
-		if volumeName != "" {
-			if !usedPaths.Has(*vol.Path) {
-				newVolumeMounts = append(newVolumeMounts, corev1.VolumeMount{
-					Name:      volumeName,
-					MountPath: *vol.Path,
-				})
-				usedPaths.Insert(*vol.Path)
-			} else {
-				return nil, nil, fmt.Errorf("mount path %s is defined multiple times", *vol.Path)
-			}
-		}
+// setContainers sets the function's sidecars and init containers on the
+// revision template, following the function's container.  The function's
+// init containers replace those it had per the previous revision annotations,
+// and follow any others.  When there are sidecars, the function's container
+// is marked as that which serves requests by declaring the port on which it
+// listens, unless it declares one already.
+func setContainers(template *v1.RevisionTemplateSpec, previousAnnotations map[string]string, sidecars, initContainers []corev1.Container) {
+	podSpec := &template.Spec.PodSpec
+	podSpec.Containers = append(podSpec.Containers[:1], sidecars...)
+	if len(sidecars) > 0 && len(podSpec.Containers[0].Ports) == 0 {
+		podSpec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: k8s.FunctionPort}}
 	}
 
-	return newVolumes, newVolumeMounts, nil
-}
-
-// checkResourcesArePresent returns error if Secrets or ConfigMaps
-// referenced in input sets are not deployed on the cluster in the specified namespace
-func checkResourcesArePresent(ctx context.Context, namespace string, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string], referencedServiceAccount string) error {
-
-	errMsg := ""
-	for s := range *referencedSecrets {
-		_, err := k8s.GetSecret(ctx, s, namespace)
-		if err != nil {
-			if errors.IsForbidden(err) {
-				errMsg += " Ensure that the service account has the necessary permissions to access the secret.\n"
-			} else {
-				errMsg += fmt.Sprintf("  referenced Secret \"%s\" is not present in namespace \"%s\"\n", s, namespace)
-			}
+	previous := sets.New(strings.Split(previousAnnotations[initContainersAnnotation], ",")...)
+	retained := []corev1.Container{}
+	for _, c := range podSpec.InitContainers {
+		if !previous.Has(c.Name) {
+			retained = append(retained, c)
 		}
 	}
-
-	for cm := range *referencedConfigMaps {
-		_, err := k8s.GetConfigMap(ctx, cm, namespace)
-		if err != nil {
-			errMsg += fmt.Sprintf("  referenced ConfigMap \"%s\" is not present in namespace \"%s\"\n", cm, namespace)
-		}
+	podSpec.InitContainers = append(retained, initContainers...)
+	if len(podSpec.InitContainers) == 0 {
+		podSpec.InitContainers = nil
 	}
 
-	for pvc := range *referencedPVCs {
-		_, err := k8s.GetPersistentVolumeClaim(ctx, pvc, namespace)
-		if err != nil {
-			errMsg += fmt.Sprintf("  referenced PersistentVolumeClaim \"%s\" is not present in namespace \"%s\"\n", pvc, namespace)
-		}
+	names := make([]string, len(initContainers))
+	for i, c := range initContainers {
+		names[i] = c.Name
 	}
-
-	// check if referenced ServiceAccount is present in the namespace if it is not default
-	if referencedServiceAccount != "" && referencedServiceAccount != "default" {
-		err := k8s.GetServiceAccount(ctx, referencedServiceAccount, namespace)
-		if err != nil {
-			errMsg += fmt.Sprintf("  referenced ServiceAccount \"%s\" is not present in namespace \"%s\"\n", referencedServiceAccount, namespace)
-		}
+	if len(names) == 0 {
+		delete(template.Annotations, initContainersAnnotation)
+		return
 	}
-
-	if errMsg != "" {
-		return fmt.Errorf("error(s) while validating resources:\n%s", errMsg)
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
 	}
-
-	return nil
+	template.Annotations[initContainersAnnotation] = strings.Join(names, ",")
 }
 
 // setServiceOptions sets annotations on Service Revision Template or in the Service Spec
@@ -1043,54 +766,12 @@ func setServiceOptions(template *v1.RevisionTemplateSpec, options fn.Options) er
 	}
 
 	// in the container always set Requests/Limits & Concurrency values based on the contents of config
-	template.Spec.PodSpec.Containers[0].Resources.Requests = nil
-	template.Spec.PodSpec.Containers[0].Resources.Limits = nil
 	template.Spec.ContainerConcurrency = nil
-
-	if options.Resources != nil {
-		if options.Resources.Requests != nil {
-			template.Spec.PodSpec.Containers[0].Resources.Requests = corev1.ResourceList{}
-
-			if options.Resources.Requests.CPU != nil {
-				value, err := resource.ParseQuantity(*options.Resources.Requests.CPU)
-				if err != nil {
-					return err
-				}
-				template.Spec.PodSpec.Containers[0].Resources.Requests[corev1.ResourceCPU] = value
-			}
-
-			if options.Resources.Requests.Memory != nil {
-				value, err := resource.ParseQuantity(*options.Resources.Requests.Memory)
-				if err != nil {
-					return err
-				}
-				template.Spec.PodSpec.Containers[0].Resources.Requests[corev1.ResourceMemory] = value
-			}
-		}
-
-		if options.Resources.Limits != nil {
-			template.Spec.PodSpec.Containers[0].Resources.Limits = corev1.ResourceList{}
-
-			if options.Resources.Limits.CPU != nil {
-				value, err := resource.ParseQuantity(*options.Resources.Limits.CPU)
-				if err != nil {
-					return err
-				}
-				template.Spec.PodSpec.Containers[0].Resources.Limits[corev1.ResourceCPU] = value
-			}
-
-			if options.Resources.Limits.Memory != nil {
-				value, err := resource.ParseQuantity(*options.Resources.Limits.Memory)
-				if err != nil {
-					return err
-				}
-				template.Spec.PodSpec.Containers[0].Resources.Limits[corev1.ResourceMemory] = value
-			}
-
-			if options.Resources.Limits.Concurrency != nil {
-				template.Spec.ContainerConcurrency = options.Resources.Limits.Concurrency
-			}
-		}
+	if options.Resources != nil && options.Resources.Limits != nil {
+		template.Spec.ContainerConcurrency = options.Resources.Limits.Concurrency
+	}
+	if err := k8s.SetResources(&template.Spec.PodSpec.Containers[0], options.Resources); err != nil {
+		return err
 	}
 
 	return servingclientlib.UpdateRevisionTemplateAnnotations(template, toUpdate, toRemove)
@@ -4,92 +4,114 @@
 package knative
 
 import (
-	"os"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
 	fn "knative.dev/func/pkg/functions"
 )
 
-func Test_setHealthEndpoints(t *testing.T) {
+func Test_setProgressDeadline(t *testing.T) {
+	aa := map[string]string{}
+	setProgressDeadline(fn.Function{}, aa)
+	if len(aa) != 0 {
+		t.Fatalf("expected no progress deadline, got %v", aa)
+	}
+	setProgressDeadline(fn.Function{Run: fn.RunSpec{StartTimeout: 5 * time.Minute}}, aa)
+	if aa["serving.knative.dev/progress-deadline"] != "5m0s" {
+		t.Fatalf("unexpected progress deadline %v", aa)
+	}
+}
+
+func Test_generateNewService_Containers(t *testing.T) {
+	secret, path := "credentials", "/etc/credentials"
+	token, tokenValue := "TOKEN", "{{ secret:credentials:token }}"
 	f := fn.Function{
 		Name: "testing",
+		Run:  fn.RunSpec{Volumes: []fn.Volume{{Secret: &secret, Path: &path}}},
 		Deploy: fn.DeploySpec{
-			HealthEndpoints: fn.HealthEndpoints{
-				Liveness:  "/lively",
-				Readiness: "/readyAsIllEverBe",
-			},
+			Image: "example.com/alice/testing",
+			Sidecars: []fn.Container{{
+				Name:         "auth-proxy",
+				Image:        "example.com/proxy",
+				Args:         []string{"--upstream=http://localhost:8080"},
+				Envs:         []fn.Env{{Name: &token, Value: &tokenValue}},
+				VolumeMounts: []fn.VolumeMount{{Volume: path, Path: "/creds", ReadOnly: true}},
+			}},
+			InitContainers: []fn.Container{{Name: "migrate", Image: "example.com/migrate"}},
 		},
 	}
-	c := corev1.Container{}
-	setHealthEndpoints(f, &c)
-	got := c.LivenessProbe.HTTPGet.Path
-	if got != "/lively" {
-		t.Errorf("expected \"/lively\" but got %v", got)
+	service, err := generateNewService(f, nil)
+	if err != nil {
+		t.Fatal(err)
 	}
-	got = c.ReadinessProbe.HTTPGet.Path
-	if got != "/readyAsIllEverBe" {
-		t.Errorf("expected \"readyAsIllEverBe\" but got %v", got)
+	spec := service.Spec.Template.Spec.PodSpec
+	if len(spec.Containers) != 2 || len(spec.InitContainers) != 1 {
+		t.Fatalf("expected 2 containers and 1 init container, got %v and %v", len(spec.Containers), len(spec.InitContainers))
 	}
-}
 
-func Test_setHealthEndpointDefaults(t *testing.T) {
-	f := fn.Function{
-		Name: "testing",
+	// The function remains the serving container, and is the only one with a port
+	if spec.Containers[0].Image != f.Deploy.Image || len(spec.Containers[0].Ports) != 1 || spec.Containers[0].Ports[0].ContainerPort != 8080 {
+		t.Fatalf("unexpected function container %+v", spec.Containers[0])
 	}
-	c := corev1.Container{}
-	setHealthEndpoints(f, &c)
-	got := c.LivenessProbe.HTTPGet.Path
-	if got != LIVENESS_ENDPOINT {
-		t.Errorf("expected \"%v\" but got %v", LIVENESS_ENDPOINT, got)
+	proxy := spec.Containers[1]
+	if proxy.Name != "auth-proxy" || len(proxy.Ports) != 0 || proxy.Args[0] != "--upstream=http://localhost:8080" {
+		t.Fatalf("unexpected sidecar %+v", proxy)
 	}
-	got = c.ReadinessProbe.HTTPGet.Path
-	if got != READINESS_ENDPOINT {
-		t.Errorf("expected \"%v\" but got %v", READINESS_ENDPOINT, got)
+	if len(proxy.VolumeMounts) != 1 || proxy.VolumeMounts[0].Name != "secret-credentials" ||
+		proxy.VolumeMounts[0].MountPath != "/creds" || !proxy.VolumeMounts[0].ReadOnly {
+		t.Fatalf("unexpected sidecar volume mounts %+v", proxy.VolumeMounts)
+	}
+	if len(proxy.Env) != 1 || proxy.Env[0].ValueFrom == nil || proxy.Env[0].ValueFrom.SecretKeyRef.Key != "token" {
+		t.Fatalf("unexpected sidecar envs %+v", proxy.Env)
+	}
+	if spec.InitContainers[0].Name != "migrate" {
+		t.Fatalf("unexpected init container %+v", spec.InitContainers[0])
 	}
-}
 
-func Test_processValue(t *testing.T) {
-	testEnvVarOld, testEnvVarOldExists := os.LookupEnv("TEST_KNATIVE_DEPLOYER")
-	os.Setenv("TEST_KNATIVE_DEPLOYER", "VALUE_FOR_TEST_KNATIVE_DEPLOYER")
-	defer func() {
-		if testEnvVarOldExists {
-			os.Setenv("TEST_KNATIVE_DEPLOYER", testEnvVarOld)
-		} else {
-			os.Unsetenv("TEST_KNATIVE_DEPLOYER")
-		}
-	}()
+	// Without sidecars the function's container declares no port
+	f.Deploy.Sidecars = nil
+	if service, err = generateNewService(f, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c := service.Spec.Template.Spec.Containers; len(c) != 1 || c[0].Ports != nil {
+		t.Fatalf("unexpected containers %+v", c)
+	}
+}
 
-	unsetVarOld, unsetVarOldExists := os.LookupEnv("UNSET_VAR")
-	os.Unsetenv("UNSET_VAR")
-	defer func() {
-		if unsetVarOldExists {
-			os.Setenv("UNSET_VAR", unsetVarOld)
-		}
-	}()
+// Test_updateService_InitContainers ensures that updating a function replaces
+// its own init containers, retaining those added by others, and that the port
+// declared by the function's container is retained.
+func Test_updateService_InitContainers(t *testing.T) {
+	f := fn.Function{
+		Name: "testing",
+		Deploy: fn.DeploySpec{
+			Image:          "example.com/alice/testing",
+			Sidecars:       []fn.Container{{Name: "auth-proxy", Image: "example.com/proxy"}},
+			InitContainers: []fn.Container{{Name: "migrate", Image: "example.com/migrate"}},
+		},
+	}
+	service, err := generateNewService(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := &service.Spec.Template.Spec.PodSpec
+	spec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: 9090}}
+	spec.InitContainers = append([]corev1.Container{{Name: "istio-init"}}, spec.InitContainers...)
 
-	tests := []struct {
-		name    string
-		arg     string
-		want    string
-		wantErr bool
-	}{
-		{name: "simple value", arg: "A_VALUE", want: "A_VALUE", wantErr: false},
-		{name: "using envvar value", arg: "{{ env:TEST_KNATIVE_DEPLOYER }}", want: "VALUE_FOR_TEST_KNATIVE_DEPLOYER", wantErr: false},
-		{name: "bad context", arg: "{{secret:S}}", want: "", wantErr: true},
-		{name: "unset envvar", arg: "{{env:SOME_UNSET_VAR}}", want: "", wantErr: true},
-	}
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			got, err := processLocalEnvValue(test.arg)
-			if (err != nil) != test.wantErr {
-				t.Errorf("processValue() error = %v, wantErr %v", err, test.wantErr)
-				return
-			}
-			if got != test.want {
-				t.Errorf("processValue() got = %v, want %v", got, test.want)
-			}
-		})
+	seed := []corev1.Container{{Name: "seed", Image: "example.com/seed"}}
+	if service, err = updateService(f, service, nil, nil, nil, nil, spec.Containers[1:], seed, nil)(service); err != nil {
+		t.Fatal(err)
+	}
+	spec = &service.Spec.Template.Spec.PodSpec
+	if len(spec.InitContainers) != 2 || spec.InitContainers[0].Name != "istio-init" || spec.InitContainers[1].Name != "seed" {
+		t.Fatalf("unexpected init containers %+v", spec.InitContainers)
+	}
+	if ports := spec.Containers[0].Ports; len(ports) != 1 || ports[0].ContainerPort != 9090 {
+		t.Fatalf("unexpected function container ports %+v", ports)
+	}
+	if a := service.Spec.Template.Annotations[initContainersAnnotation]; a != "seed" {
+		t.Fatalf("unexpected init containers annotation %q", a)
 	}
 }
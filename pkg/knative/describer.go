@@ -32,12 +32,12 @@ func (d *Describer) Describe(ctx context.Context, name, namespace string) (descr
 		return
 	}
 
-	servingClient, err := NewServingClient(namespace)
+	servingClient, err := NewServingClient(ctx, namespace)
 	if err != nil {
 		return
 	}
 
-	eventingClient, err := NewEventingClient(namespace)
+	eventingClient, err := NewEventingClient(ctx, namespace)
 	if err != nil {
 		return
 	}
@@ -62,6 +62,16 @@ func (d *Describer) Describe(ctx context.Context, name, namespace string) (descr
 		primaryRouteURL = routes.Items[0].Status.URL.String()
 	}
 
+	domainMappingClient, err := NewDomainMappingClient(ctx, namespace)
+	if err != nil {
+		return
+	}
+	domainURLs, err := domainMappingURLs(ctx, domainMappingClient, name)
+	if err != nil {
+		return
+	}
+	routeURLs = append(routeURLs, domainURLs...)
+
 	description.Name = name
 	description.Namespace = namespace
 	description.Route = primaryRouteURL
@@ -0,0 +1,140 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	servingv1beta1 "knative.dev/serving/pkg/apis/serving/v1beta1"
+	servingv1beta1client "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1beta1"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+func NewDomainMappingClient(ctx context.Context, namespace string) (servingv1beta1client.DomainMappingInterface, error) {
+	restConfig, err := k8s.GetClientConfig(ctx).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new domain mapping client: %v", err)
+	}
+
+	client, err := servingv1beta1client.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new domain mapping client: %v", err)
+	}
+
+	return client.DomainMappings(namespace), nil
+}
+
+// domainMappingSelector selects the DomainMappings of the named function.
+func domainMappingSelector(name string) string {
+	return labels.SelectorFromSet(labels.Set{
+		fnlabels.FunctionKey:     fnlabels.FunctionValue,
+		fnlabels.FunctionNameKey: name,
+	}).String()
+}
+
+// generateDomainMapping of the host to the function's Knative Service.
+func generateDomainMapping(f fn.Function, d fn.Domain, namespace string) *servingv1beta1.DomainMapping {
+	dm := &servingv1beta1.DomainMapping{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Host,
+			Namespace: namespace,
+			Labels: map[string]string{
+				fnlabels.FunctionKey:     fnlabels.FunctionValue,
+				fnlabels.FunctionNameKey: f.Name,
+			},
+		},
+		Spec: servingv1beta1.DomainMappingSpec{
+			Ref: duckv1.KReference{
+				APIVersion: "serving.knative.dev/v1",
+				Kind:       "Service",
+				Name:       f.Name,
+				Namespace:  namespace,
+			},
+		},
+	}
+	if d.TLSSecret != "" {
+		dm.Spec.TLS = &servingv1beta1.SecretTLS{SecretName: d.TLSSecret}
+	}
+	return dm
+}
+
+// reconcileDomainMappings creates or updates a DomainMapping for each of the
+// function's domains, and deletes those of domains it no longer defines.
+// A host already mapped to another Service is an error.
+func reconcileDomainMappings(ctx context.Context, client servingv1beta1client.DomainMappingInterface, f fn.Function, namespace string) error {
+	wanted := make(map[string]bool, len(f.Deploy.Domains))
+	for _, d := range f.Deploy.Domains {
+		wanted[d.Host] = true
+		dm := generateDomainMapping(f, d, namespace)
+
+		existing, err := client.Get(ctx, d.Host, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			if _, err = client.Create(ctx, dm, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("knative deployer failed to create the DomainMapping of %q: %v", d.Host, err)
+			}
+			continue
+		} else if err != nil {
+			return fmt.Errorf("knative deployer failed to get the DomainMapping of %q: %v", d.Host, err)
+		}
+
+		if existing.Spec.Ref.Kind != dm.Spec.Ref.Kind || existing.Spec.Ref.Name != f.Name {
+			return fmt.Errorf("domain %q is already mapped to %v %q", d.Host, existing.Spec.Ref.Kind, existing.Spec.Ref.Name)
+		}
+		existing.Labels = dm.Labels
+		existing.Spec = dm.Spec
+		if _, err = client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("knative deployer failed to update the DomainMapping of %q: %v", d.Host, err)
+		}
+	}
+
+	// An unlisted DomainMapping is only an error when there are domains to
+	// map, as the API is not installed on clusters without the feature, and
+	// users who do not map domains may not be permitted to list them.
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: domainMappingSelector(f.Name)})
+	if (errors.IsNotFound(err) || errors.IsForbidden(err)) && len(wanted) == 0 {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("knative deployer failed to list the DomainMappings: %v", err)
+	}
+	for _, dm := range list.Items {
+		if wanted[dm.Name] {
+			continue
+		}
+		if err = client.Delete(ctx, dm.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("knative deployer failed to delete the DomainMapping of %q: %v", dm.Name, err)
+		}
+	}
+	return nil
+}
+
+// removeDomainMappings of the named function.
+func removeDomainMappings(ctx context.Context, client servingv1beta1client.DomainMappingInterface, name string) error {
+	err := client.DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: domainMappingSelector(name)})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("knative remover failed to delete the DomainMappings: %v", err)
+	}
+	return nil
+}
+
+// domainMappingURLs of the named function, which are empty until they are
+// ready.
+func domainMappingURLs(ctx context.Context, client servingv1beta1client.DomainMappingInterface, name string) (urls []string, err error) {
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: domainMappingSelector(name)})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return
+	}
+	for _, dm := range list.Items {
+		if dm.Status.URL != nil {
+			urls = append(urls, dm.Status.URL.String())
+		}
+	}
+	return
+}
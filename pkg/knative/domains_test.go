@@ -0,0 +1,91 @@
+//go:build !integration
+// +build !integration
+
+package knative
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	servingv1beta1 "knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/client/clientset/versioned/fake"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_reconcileDomainMappings ensures a DomainMapping is created or updated
+// for each of the function's domains, those of removed domains are deleted,
+// and hosts mapped to other Services are not taken over.
+func Test_reconcileDomainMappings(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&servingv1beta1.DomainMapping{
+		ObjectMeta: metav1.ObjectMeta{Name: "other.example.com", Namespace: "ns"},
+		Spec:       servingv1beta1.DomainMappingSpec{Ref: duckv1.KReference{Kind: "Service", Name: "other"}},
+	}).ServingV1beta1().DomainMappings("ns")
+
+	f := fn.Function{Name: "myfunc", Deploy: fn.DeploySpec{Domains: []fn.Domain{
+		{Host: "www.example.com", TLSSecret: "www-tls"},
+		{Host: "api.example.com"},
+	}}}
+	if err := reconcileDomainMappings(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	dm, err := client.Get(ctx, "www.example.com", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dm.Spec.Ref.Name != "myfunc" || dm.Spec.TLS == nil || dm.Spec.TLS.SecretName != "www-tls" {
+		t.Fatalf("unexpected DomainMapping spec %+v", dm.Spec)
+	}
+
+	// Removing a domain and its TLS secret updates and deletes the mappings.
+	f.Deploy.Domains = []fn.Domain{{Host: "www.example.com"}}
+	if err = reconcileDomainMappings(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: domainMappingSelector("myfunc")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "www.example.com" || list.Items[0].Spec.TLS != nil {
+		t.Fatalf("unexpected DomainMappings %+v", list.Items)
+	}
+
+	// A host mapped to another Service is an error.
+	f.Deploy.Domains = []fn.Domain{{Host: "other.example.com"}}
+	if err = reconcileDomainMappings(ctx, client, f, "ns"); err == nil {
+		t.Fatal("expected an error mapping a host of another Service")
+	}
+
+	if err = removeDomainMappings(ctx, client, "myfunc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Get(ctx, "other.example.com", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the other Service's DomainMapping to remain: %v", err)
+	}
+}
+
+// Test_reconcileDomainMappings_Forbidden ensures a function without domains
+// is deployed by users who may not list DomainMappings.
+func Test_reconcileDomainMappings_Forbidden(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "domainmappings", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "domainmappings"}, "", nil)
+	})
+	client := clientset.ServingV1beta1().DomainMappings("ns")
+
+	f := fn.Function{Name: "myfunc"}
+	if err := reconcileDomainMappings(context.Background(), client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Domains = []fn.Domain{{Host: "www.example.com"}}
+	if err := reconcileDomainMappings(context.Background(), client, f, "ns"); err == nil {
+		t.Fatal("expected an error pruning the DomainMappings of a function with domains")
+	}
+}
@@ -21,7 +21,7 @@ func NewLister(verbose bool) *Lister {
 
 // List functions, optionally specifying a namespace.
 func (l *Lister) List(ctx context.Context, namespace string) (items []fn.ListItem, err error) {
-	client, err := NewServingClient(namespace)
+	client, err := NewServingClient(ctx, namespace)
 	if err != nil {
 		return
 	}
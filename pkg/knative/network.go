@@ -0,0 +1,392 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	networkingv1client "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// visibilityLabelKey of a Knative Service, by which it is served only
+// within the cluster when set to cluster-local.
+const visibilityLabelKey = "networking.knative.dev/visibility"
+
+// namespaceNameKey is the label set by Kubernetes on every namespace with its
+// name.
+const namespaceNameKey = "kubernetes.io/metadata.name"
+
+// defaultIngressNamespaces of Knative's activator and of the gateways of its
+// networking layers (Istio, Kourier and Contour), from which ingress to a
+// function with an allow list is permitted unless it defines its own.
+// Requests routed by Knative reach the function from these rather than from
+// the client, so the client is only identified by the mesh, if the allow list
+// is enforced by Istio (see generateAuthorizationPolicy).
+var defaultIngressNamespaces = []string{
+	"knative-serving",
+	istioRootNamespace,
+	"kourier-system",
+	"contour-external",
+	"contour-internal",
+}
+
+// ingressNamespaces of the function, from which requests routed by Knative
+// reach it.
+func ingressNamespaces(f fn.Function) []string {
+	if len(f.Deploy.IngressNamespaces) > 0 {
+		return f.Deploy.IngressNamespaces
+	}
+	return defaultIngressNamespaces
+}
+
+// meshEnforced returns true if the function's allow list is enforced by
+// Istio as well as by a NetworkPolicy.
+func meshEnforced(f fn.Function) bool {
+	return len(f.Deploy.AllowFrom) > 0 && f.Deploy.AllowFromPolicy == fn.AllowFromIstio
+}
+
+// authorizationPolicyResource is the Istio AuthorizationPolicy, which is
+// accessed with a dynamic client such that Istio need not be installed
+// unless a function's allow list is enforced by it.
+var authorizationPolicyResource = schema.GroupVersionResource{Group: "security.istio.io", Version: "v1", Resource: "authorizationpolicies"}
+
+// istioRootNamespace in which Istio applies policies to all workloads of the
+// mesh, including the gateways and the activator through which requests
+// for functions are routed.
+const istioRootNamespace = "istio-system"
+
+func NewNetworkPolicyClient(ctx context.Context, namespace string) (networkingv1client.NetworkPolicyInterface, error) {
+	client, err := k8s.NewKubernetesClientset(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new network policy client: %v", err)
+	}
+	return client.NetworkingV1().NetworkPolicies(namespace), nil
+}
+
+// setVisibility of the Service from that of the function.  Functions which
+// do not define a visibility keep any set with labels.  The label is set on
+// the Service only, as it is not meaningful on its revisions.
+func setVisibility(service *v1.Service, visibility string) {
+	labels := make(map[string]string, len(service.Labels)+1)
+	for k, v := range service.Labels {
+		labels[k] = v
+	}
+	switch visibility {
+	case fn.VisibilityClusterLocal:
+		labels[visibilityLabelKey] = serving.VisibilityClusterLocal
+	case fn.VisibilityPublic:
+		delete(labels, visibilityLabelKey)
+	}
+	service.Labels = labels
+}
+
+// generateNetworkPolicy which restricts ingress to the pods of the function
+// to its allow list and its ingress namespaces.
+func generateNetworkPolicy(f fn.Function, namespace string) *networkingv1.NetworkPolicy {
+	var from []networkingv1.NetworkPolicyPeer
+	for _, a := range f.Deploy.AllowFrom {
+		peer := networkingv1.NetworkPolicyPeer{}
+		if a.Namespace != "" {
+			peer.NamespaceSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{namespaceNameKey: a.Namespace},
+			}
+		}
+		if a.Function != "" {
+			peer.PodSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{serving.ServiceLabelKey: a.Function},
+			}
+		}
+		from = append(from, peer)
+	}
+	from = append(from, networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      namespaceNameKey,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   ingressNamespaces(f),
+			}},
+		},
+	})
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.Name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				fnlabels.FunctionKey:     fnlabels.FunctionValue,
+				fnlabels.FunctionNameKey: f.Name,
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{serving.ServiceLabelKey: f.Name},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: from}},
+		},
+	}
+}
+
+// reconcileNetworkPolicy creates or updates the NetworkPolicy of a function
+// with an allow list, or deletes it if the function has none.  A policy of
+// the same name which does not belong to the function is left untouched.
+func reconcileNetworkPolicy(ctx context.Context, client networkingv1client.NetworkPolicyInterface, f fn.Function, namespace string) error {
+	np := generateNetworkPolicy(f, namespace)
+	existing, err := client.Get(ctx, f.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if len(f.Deploy.AllowFrom) == 0 {
+			return nil
+		}
+		if _, err = client.Create(ctx, np, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("knative deployer failed to create the NetworkPolicy: %v", err)
+		}
+		return nil
+	} else if err != nil {
+		if len(f.Deploy.AllowFrom) == 0 && errors.IsForbidden(err) {
+			return nil
+		}
+		return fmt.Errorf("knative deployer failed to get the NetworkPolicy: %v", err)
+	}
+
+	if existing.Labels[fnlabels.FunctionNameKey] != f.Name {
+		if len(f.Deploy.AllowFrom) == 0 {
+			return nil
+		}
+		return fmt.Errorf("NetworkPolicy %q already exists and does not belong to the function", f.Name)
+	}
+	if len(f.Deploy.AllowFrom) == 0 {
+		if err = client.Delete(ctx, f.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("knative deployer failed to delete the NetworkPolicy: %v", err)
+		}
+		return nil
+	}
+	existing.Labels = np.Labels
+	existing.Spec = np.Spec
+	if _, err = client.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("knative deployer failed to update the NetworkPolicy: %v", err)
+	}
+	return nil
+}
+
+// removeNetworkPolicy of the named function, if any.  A policy of the same
+// name which does not belong to the function is left untouched.
+func removeNetworkPolicy(ctx context.Context, client networkingv1client.NetworkPolicyInterface, name string) error {
+	existing, err := client.Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) || errors.IsForbidden(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("knative remover failed to get the NetworkPolicy: %v", err)
+	}
+	if existing.Labels[fnlabels.FunctionNameKey] != name {
+		return nil
+	}
+	if err = client.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("knative remover failed to delete the NetworkPolicy: %v", err)
+	}
+	return nil
+}
+
+// reconcileAllowList of the function: the AuthorizationPolicy by which the
+// mesh authorizes its clients, if enforced by Istio, and the NetworkPolicy
+// which restricts connections to its pods.  The former is reconciled first,
+// such that a function whose allow list can not be enforced is not made
+// unreachable.
+func reconcileAllowList(ctx context.Context, networkPolicies networkingv1client.NetworkPolicyInterface, authorizationPolicies dynamic.ResourceInterface, f fn.Function, namespace string, serviceAccount serviceAccountGetter) error {
+	var principals []string
+	if meshEnforced(f) {
+		var err error
+		if principals, err = allowedPrincipals(ctx, f, namespace, serviceAccount, os.Stderr); err != nil {
+			return err
+		}
+	} else if len(f.Deploy.AllowFrom) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: the allow list of function %q only restricts direct connections to its pods; requests routed by Knative through its activator and gateways are allowed from any client unless allowFromPolicy is %q\n", f.Name, fn.AllowFromIstio)
+	}
+	if err := reconcileAuthorizationPolicy(ctx, authorizationPolicies, f, namespace, principals); err != nil {
+		return err
+	}
+	return reconcileNetworkPolicy(ctx, networkPolicies, f, namespace)
+}
+
+// serviceAccountGetter returns the name of the ServiceAccount of the named
+// function.
+type serviceAccountGetter func(ctx context.Context, namespace, name string) (string, error)
+
+// serviceAccountOfService returns a serviceAccountGetter which reads the
+// ServiceAccount of a function from its Knative Service.
+func serviceAccountOfService() serviceAccountGetter {
+	return func(ctx context.Context, namespace, name string) (string, error) {
+		client, err := NewServingClient(ctx, namespace)
+		if err != nil {
+			return "", err
+		}
+		service, err := client.GetService(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if sa := service.Spec.Template.Spec.ServiceAccountName; sa != "" {
+			return sa, nil
+		}
+		return "default", nil
+	}
+}
+
+// allowedPrincipals are the mesh identities of the functions of the allow
+// list, which are those of their ServiceAccounts.  A function which is not
+// deployed is not allowed, and warned of, until this function is deployed
+// again.
+func allowedPrincipals(ctx context.Context, f fn.Function, namespace string, serviceAccount serviceAccountGetter, w io.Writer) ([]string, error) {
+	var principals []string
+	for _, a := range f.Deploy.AllowFrom {
+		if a.Function == "" {
+			continue
+		}
+		ns := a.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		sa, err := serviceAccount(ctx, ns, a.Function)
+		if errors.IsNotFound(err) {
+			fmt.Fprintf(w, "Warning: function %q of the allow list is not deployed in namespace %q, and is not allowed until %q is deployed again\n", a.Function, ns, f.Name)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to read the ServiceAccount of function %q of the allow list: %w", a.Function, err)
+		}
+		principals = append(principals, fmt.Sprintf("*/ns/%s/sa/%s", ns, sa))
+	}
+	return principals, nil
+}
+
+// authorizationPolicyName of the function, unique within the root namespace.
+func authorizationPolicyName(name, namespace string) string {
+	return name + "." + namespace
+}
+
+// generateAuthorizationPolicy which denies requests for the function's hosts
+// from clients other than those of its allow list, in the Istio root
+// namespace such that it applies to the gateways and the activator, where
+// the client is identified, as well as to the function itself.  Clients are
+// allowed by namespace, or by the principals of the functions of the allow
+// list.  Clients outside the mesh have no identity, so are denied.
+func generateAuthorizationPolicy(f fn.Function, namespace string, principals []string) *unstructured.Unstructured {
+	notNamespaces := []any{}
+	for _, a := range f.Deploy.AllowFrom {
+		if a.Function == "" {
+			notNamespaces = append(notNamespaces, a.Namespace)
+		}
+	}
+	for _, ns := range ingressNamespaces(f) {
+		notNamespaces = append(notNamespaces, ns)
+	}
+	source := map[string]any{"notNamespaces": notNamespaces}
+	if len(principals) > 0 {
+		notPrincipals := make([]any, len(principals))
+		for i, p := range principals {
+			notPrincipals[i] = p
+		}
+		source["notPrincipals"] = notPrincipals
+	}
+
+	// The hosts of the function's routes, with or without a port, such as
+	// f.ns, f.ns.svc.cluster.local and f.ns.example.com, and its domains.
+	host := f.Name + "." + namespace
+	hosts := []any{host, host + ":*", host + ".*"}
+	for _, d := range f.Deploy.Domains {
+		hosts = append(hosts, d.Host, d.Host+":*")
+	}
+
+	policy := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "security.istio.io/v1",
+		"kind":       "AuthorizationPolicy",
+		"spec": map[string]any{
+			"action": "DENY",
+			"rules": []any{map[string]any{
+				"from": []any{map[string]any{"source": source}},
+				"to":   []any{map[string]any{"operation": map[string]any{"hosts": hosts}}},
+			}},
+		},
+	}}
+	policy.SetName(authorizationPolicyName(f.Name, namespace))
+	policy.SetNamespace(istioRootNamespace)
+	policy.SetLabels(map[string]string{
+		fnlabels.FunctionKey:          fnlabels.FunctionValue,
+		fnlabels.FunctionNameKey:      f.Name,
+		fnlabels.FunctionNamespaceKey: namespace,
+	})
+	return policy
+}
+
+// ownsAuthorizationPolicy returns whether the policy is that of the function.
+func ownsAuthorizationPolicy(policy *unstructured.Unstructured, name, namespace string) bool {
+	l := policy.GetLabels()
+	return l[fnlabels.FunctionNameKey] == name && l[fnlabels.FunctionNamespaceKey] == namespace
+}
+
+// reconcileAuthorizationPolicy creates or updates the AuthorizationPolicy of
+// a function whose allow list is enforced by Istio, or deletes it otherwise.
+// A policy of the same name which does not belong to the function is left
+// untouched.
+func reconcileAuthorizationPolicy(ctx context.Context, client dynamic.ResourceInterface, f fn.Function, namespace string, principals []string) error {
+	name := authorizationPolicyName(f.Name, namespace)
+	policy := generateAuthorizationPolicy(f, namespace, principals)
+	existing, err := client.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case !meshEnforced(f) && (errors.IsNotFound(err) || errors.IsForbidden(err)):
+		// Also the case when Istio is not installed
+		return nil
+	case errors.IsNotFound(err):
+		if _, err = client.Create(ctx, policy, metav1.CreateOptions{}); errors.IsNotFound(err) {
+			return fmt.Errorf("allowFromPolicy %q requires Istio, which is not installed: %v", fn.AllowFromIstio, err)
+		} else if err != nil {
+			return fmt.Errorf("knative deployer failed to create the AuthorizationPolicy: %v", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("knative deployer failed to get the AuthorizationPolicy: %v", err)
+	case !ownsAuthorizationPolicy(existing, f.Name, namespace):
+		if !meshEnforced(f) {
+			return nil
+		}
+		return fmt.Errorf("AuthorizationPolicy %q already exists and does not belong to the function", name)
+	case !meshEnforced(f):
+		if err = client.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("knative deployer failed to delete the AuthorizationPolicy: %v", err)
+		}
+		return nil
+	}
+	policy.SetResourceVersion(existing.GetResourceVersion())
+	if _, err = client.Update(ctx, policy, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("knative deployer failed to update the AuthorizationPolicy: %v", err)
+	}
+	return nil
+}
+
+// removeAuthorizationPolicy of the named function, if any.  A policy of the
+// same name which does not belong to the function is left untouched.
+func removeAuthorizationPolicy(ctx context.Context, client dynamic.ResourceInterface, name, namespace string) error {
+	existing, err := client.Get(ctx, authorizationPolicyName(name, namespace), metav1.GetOptions{})
+	if errors.IsNotFound(err) || errors.IsForbidden(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("knative remover failed to get the AuthorizationPolicy: %v", err)
+	}
+	if !ownsAuthorizationPolicy(existing, name, namespace) {
+		return nil
+	}
+	if err = client.Delete(ctx, existing.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("knative remover failed to delete the AuthorizationPolicy: %v", err)
+	}
+	return nil
+}
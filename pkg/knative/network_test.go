@@ -0,0 +1,281 @@
+//go:build !integration
+// +build !integration
+
+package knative
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_generateNewService_Visibility ensures cluster-local functions are
+// labeled as such on the Service only.
+func Test_generateNewService_Visibility(t *testing.T) {
+	f := fn.Function{Name: "myfunc", Deploy: fn.DeploySpec{Visibility: fn.VisibilityClusterLocal}}
+	service, err := generateNewService(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.Labels[visibilityLabelKey] != "cluster-local" {
+		t.Fatalf("expected the Service to be cluster-local, got labels %v", service.Labels)
+	}
+	if _, ok := service.Spec.Template.Labels[visibilityLabelKey]; ok {
+		t.Fatalf("expected no visibility label on the revision, got %v", service.Spec.Template.Labels)
+	}
+
+	// An explicitly public function removes a visibility label.
+	f.Deploy.Visibility = fn.VisibilityPublic
+	setVisibility(service, f.Deploy.Visibility)
+	if _, ok := service.Labels[visibilityLabelKey]; ok {
+		t.Fatalf("expected the Service to be public, got labels %v", service.Labels)
+	}
+}
+
+// Test_reconcileNetworkPolicy ensures a NetworkPolicy is created and updated
+// from the function's allow list, deleted when the list is emptied, and that
+// a policy of another owner is not modified.
+func Test_reconcileNetworkPolicy(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns"},
+	}).NetworkingV1().NetworkPolicies("ns")
+
+	// No allow list creates no policy.
+	f := fn.Function{Name: "myfunc"}
+	if err := reconcileNetworkPolicy(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := client.List(ctx, metav1.ListOptions{}); len(list.Items) != 1 {
+		t.Fatalf("expected no NetworkPolicy to be created, got %v", list.Items)
+	}
+
+	f.Deploy.AllowFrom = []fn.AllowFrom{{Namespace: "frontend"}, {Function: "gateway"}}
+	if err := reconcileNetworkPolicy(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	np, err := client.Get(ctx, "myfunc", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if np.Spec.PodSelector.MatchLabels["serving.knative.dev/service"] != "myfunc" {
+		t.Fatalf("unexpected pod selector %v", np.Spec.PodSelector)
+	}
+	// The allowed sources, followed by the ingress namespaces.
+	from := np.Spec.Ingress[0].From
+	if len(from) != 3 {
+		t.Fatalf("expected 3 peers, got %+v", from)
+	}
+	if got := fmt.Sprint(from[2].NamespaceSelector.MatchExpressions[0].Values); got != "[knative-serving istio-system kourier-system contour-external contour-internal]" {
+		t.Fatalf("unexpected ingress namespaces %v", got)
+	}
+	if from[0].NamespaceSelector.MatchLabels[namespaceNameKey] != "frontend" || from[0].PodSelector != nil {
+		t.Fatalf("unexpected namespace peer %+v", from[0])
+	}
+	if from[1].PodSelector.MatchLabels["serving.knative.dev/service"] != "gateway" || from[1].NamespaceSelector != nil {
+		t.Fatalf("unexpected function peer %+v", from[1])
+	}
+
+	// Updating the allow list updates the policy.
+	f.Deploy.AllowFrom = []fn.AllowFrom{{Namespace: "other", Function: "client"}}
+	if err = reconcileNetworkPolicy(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	if np, err = client.Get(ctx, "myfunc", metav1.GetOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if peer := np.Spec.Ingress[0].From[0]; peer.NamespaceSelector == nil || peer.PodSelector == nil {
+		t.Fatalf("expected a peer selecting both namespace and function, got %+v", peer)
+	}
+
+	// Emptying the allow list deletes the policy.
+	f.Deploy.AllowFrom = nil
+	if err = reconcileNetworkPolicy(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Get(ctx, "myfunc", metav1.GetOptions{}); err == nil {
+		t.Fatal("expected the NetworkPolicy to be deleted")
+	}
+
+	// A policy of the same name not belonging to the function is an error.
+	f = fn.Function{Name: "other", Deploy: fn.DeploySpec{AllowFrom: []fn.AllowFrom{{Namespace: "frontend"}}}}
+	if err = reconcileNetworkPolicy(ctx, client, f, "ns"); err == nil {
+		t.Fatal("expected an error reconciling a NetworkPolicy of another owner")
+	}
+}
+
+// Test_removeNetworkPolicy ensures the NetworkPolicy of a function is
+// deleted, and that a policy of another owner or none at all is not an error.
+func Test_removeNetworkPolicy(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns"},
+	}).NetworkingV1().NetworkPolicies("ns")
+
+	f := fn.Function{Name: "myfunc", Deploy: fn.DeploySpec{AllowFrom: []fn.AllowFrom{{Namespace: "frontend"}}}}
+	if err := reconcileNetworkPolicy(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeNetworkPolicy(ctx, client, "myfunc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(ctx, "myfunc", metav1.GetOptions{}); err == nil {
+		t.Fatal("expected the NetworkPolicy to be deleted")
+	}
+	if err := removeNetworkPolicy(ctx, client, "myfunc"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := removeNetworkPolicy(ctx, client, "other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(ctx, "other", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the NetworkPolicy of another owner to be kept: %v", err)
+	}
+}
+
+// Test_reconcileAuthorizationPolicy ensures an AuthorizationPolicy denying
+// clients other than those of the allow list is created in the Istio root
+// namespace only if the allow list is enforced by Istio, updated, and deleted
+// when the list is emptied, no longer enforced by Istio, or the function is
+// removed.
+func Test_reconcileAuthorizationPolicy(t *testing.T) {
+	ctx := context.Background()
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{authorizationPolicyResource: "AuthorizationPolicyList"}).
+		Resource(authorizationPolicyResource).Namespace(istioRootNamespace)
+
+	// No allow list creates no policy.
+	f := fn.Function{Name: "myfunc"}
+	if err := reconcileAuthorizationPolicy(ctx, client, f, "ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := client.List(ctx, metav1.ListOptions{}); len(list.Items) != 0 {
+		t.Fatalf("expected no AuthorizationPolicy to be created, got %v", list.Items)
+	}
+
+	// An allow list enforced by a NetworkPolicy only creates no policy.
+	f.Deploy.AllowFrom = []fn.AllowFrom{{Namespace: "frontend"}, {Function: "gateway"}}
+	f.Deploy.Domains = []fn.Domain{{Host: "www.example.com"}}
+	if err := reconcileAuthorizationPolicy(ctx, client, f, "ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := client.List(ctx, metav1.ListOptions{}); len(list.Items) != 0 {
+		t.Fatalf("expected no AuthorizationPolicy to be created, got %v", list.Items)
+	}
+
+	f.Deploy.AllowFromPolicy = fn.AllowFromIstio
+	f.Deploy.IngressNamespaces = []string{"knative-serving", "istio-system"}
+	if err := reconcileAuthorizationPolicy(ctx, client, f, "ns", []string{"*/ns/ns/sa/gateway"}); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := client.Get(ctx, "myfunc.ns", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action, _, _ := unstructured.NestedString(policy.Object, "spec", "action"); action != "DENY" {
+		t.Fatalf("expected a DENY policy, got %q", action)
+	}
+	rule := policy.Object["spec"].(map[string]any)["rules"].([]any)[0].(map[string]any)
+	source := rule["from"].([]any)[0].(map[string]any)["source"].(map[string]any)
+	if got := fmt.Sprint(source["notNamespaces"]); got != "[frontend knative-serving istio-system]" {
+		t.Fatalf("unexpected namespaces allowed %v", got)
+	}
+	if got := fmt.Sprint(source["notPrincipals"]); got != "[*/ns/ns/sa/gateway]" {
+		t.Fatalf("unexpected principals allowed %v", got)
+	}
+	hosts := rule["to"].([]any)[0].(map[string]any)["operation"].(map[string]any)["hosts"]
+	if got := fmt.Sprint(hosts); got != "[myfunc.ns myfunc.ns:* myfunc.ns.* www.example.com www.example.com:*]" {
+		t.Fatalf("unexpected hosts %v", got)
+	}
+
+	// Updating the allow list updates the policy.
+	f.Deploy.AllowFrom = []fn.AllowFrom{{Namespace: "other"}}
+	if err = reconcileAuthorizationPolicy(ctx, client, f, "ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	if policy, err = client.Get(ctx, "myfunc.ns", metav1.GetOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	rule = policy.Object["spec"].(map[string]any)["rules"].([]any)[0].(map[string]any)
+	source = rule["from"].([]any)[0].(map[string]any)["source"].(map[string]any)
+	if _, ok := source["notPrincipals"]; ok || fmt.Sprint(source["notNamespaces"]) != "[other knative-serving istio-system]" {
+		t.Fatalf("expected the policy to be updated, got %v", source)
+	}
+
+	// Emptying the allow list deletes the policy, as does removing the function.
+	f.Deploy.AllowFrom = nil
+	if err = reconcileAuthorizationPolicy(ctx, client, f, "ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Get(ctx, "myfunc.ns", metav1.GetOptions{}); err == nil {
+		t.Fatal("expected the AuthorizationPolicy to be deleted")
+	}
+	f.Deploy.AllowFrom = []fn.AllowFrom{{Namespace: "frontend"}}
+	if err = reconcileAuthorizationPolicy(ctx, client, f, "ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	// Switching to a NetworkPolicy only deletes the policy.
+	f.Deploy.AllowFromPolicy = fn.AllowFromNetworkPolicy
+	if err = reconcileAuthorizationPolicy(ctx, client, f, "ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Get(ctx, "myfunc.ns", metav1.GetOptions{}); err == nil {
+		t.Fatal("expected the AuthorizationPolicy to be deleted")
+	}
+	f.Deploy.AllowFromPolicy = fn.AllowFromIstio
+	if err = reconcileAuthorizationPolicy(ctx, client, f, "ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = removeAuthorizationPolicy(ctx, client, "myfunc", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if err = removeAuthorizationPolicy(ctx, client, "myfunc", "ns"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Get(ctx, "myfunc.ns", metav1.GetOptions{}); err == nil {
+		t.Fatal("expected the AuthorizationPolicy to be removed")
+	}
+}
+
+// Test_allowedPrincipals ensures the functions of the allow list are allowed
+// by the identities of their ServiceAccounts, and that functions which are
+// not deployed are warned of.
+func Test_allowedPrincipals(t *testing.T) {
+	serviceAccount := func(_ context.Context, namespace, name string) (string, error) {
+		if name == "missing" {
+			return "", apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, name)
+		}
+		return name + "-sa", nil
+	}
+	f := fn.Function{Name: "myfunc", Deploy: fn.DeploySpec{AllowFrom: []fn.AllowFrom{
+		{Namespace: "frontend"},
+		{Function: "gateway"},
+		{Namespace: "billing", Function: "invoicer"},
+		{Function: "missing"},
+	}}}
+	var out bytes.Buffer
+	principals, err := allowedPrincipals(context.Background(), f, "ns", serviceAccount, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(principals); got != "[*/ns/ns/sa/gateway-sa */ns/billing/sa/invoicer-sa]" {
+		t.Fatalf("unexpected principals %v", got)
+	}
+	if !strings.Contains(out.String(), `function "missing"`) {
+		t.Fatalf("expected a warning of the missing function, got %q", out.String())
+	}
+}
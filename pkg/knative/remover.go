@@ -8,6 +8,7 @@ import (
 
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
 )
 
 const RemoveTimeout = 120 * time.Second
@@ -28,7 +29,7 @@ func (remover *Remover) Remove(ctx context.Context, name, ns string) (err error)
 		return fn.ErrNamespaceRequired
 	}
 
-	client, err := NewServingClient(ns)
+	client, err := NewServingClient(ctx, ns)
 	if err != nil {
 		return
 	}
@@ -39,7 +40,29 @@ func (remover *Remover) Remove(ctx context.Context, name, ns string) (err error)
 			return fn.ErrFunctionNotFound
 		}
 		err = fmt.Errorf("knative remover failed to delete the service: %v", err)
+		return
+	}
+
+	domainMappingClient, err := NewDomainMappingClient(ctx, ns)
+	if err != nil {
+		return
+	}
+	if err = removeDomainMappings(ctx, domainMappingClient, name); err != nil {
+		return
+	}
+
+	networkPolicyClient, err := NewNetworkPolicyClient(ctx, ns)
+	if err != nil {
+		return
+	}
+	if err = removeNetworkPolicy(ctx, networkPolicyClient, name); err != nil {
+		return
 	}
 
-	return
+	dynamicClient, err := k8s.NewDynamicClient(ctx)
+	if err != nil {
+		return
+	}
+	authorizationPolicyClient := dynamicClient.Resource(authorizationPolicyResource).Namespace(istioRootNamespace)
+	return removeAuthorizationPolicy(ctx, authorizationPolicyClient, name, ns)
 }
@@ -1,18 +1,17 @@
 package mock
 
+import fn "knative.dev/func/pkg/functions"
+
 type DNSProvider struct {
-	ProvideInvoked   bool
-	NameRequested    string
-	AddressRequested string
+	ProvideInvoked bool
+	ProvideFn      func(fn.Function) error
 }
 
 func NewDNSProvider() *DNSProvider {
-	return &DNSProvider{}
+	return &DNSProvider{ProvideFn: func(fn.Function) error { return nil }}
 }
 
-func (d *DNSProvider) Provide(name, address string) string {
+func (d *DNSProvider) Provide(f fn.Function) error {
 	d.ProvideInvoked = true
-	d.NameRequested = name
-	d.AddressRequested = address
-	return name
+	return d.ProvideFn(f)
 }
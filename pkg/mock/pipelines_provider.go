@@ -3,6 +3,7 @@ package mock
 import (
 	"context"
 	"errors"
+	"io"
 
 	fn "knative.dev/func/pkg/functions"
 )
@@ -16,6 +17,19 @@ type PipelinesProvider struct {
 	ConfigurePACFn      func(fn.Function) error
 	RemovePACInvoked    bool
 	RemovePACFn         func(fn.Function) error
+	LogsInvoked         bool
+	LogsFn              func(fn.Function, string, io.Writer) error
+	RunsInvoked         bool
+	RunsFn              func(fn.Function) ([]fn.PipelineRun, error)
+	DescribeRunInvoked  bool
+	DescribeRunFn       func(fn.Function, string) (fn.PipelineRun, error)
+	CancelRunInvoked    bool
+	CancelRunFn         func(fn.Function, string) error
+	RerunInvoked        bool
+	RerunFn             func(fn.Function, string) (string, error)
+	ClearCacheInvoked   bool
+	ClearCacheFn        func(fn.Function) error
+	ClearSharedCache    bool
 }
 
 func NewPipelinesProvider() *PipelinesProvider {
@@ -51,6 +65,12 @@ func NewPipelinesProvider() *PipelinesProvider {
 		RemoveFn:       func(fn.Function) error { return nil },
 		ConfigurePACFn: func(fn.Function) error { return nil },
 		RemovePACFn:    func(fn.Function) error { return nil },
+		LogsFn:         func(fn.Function, string, io.Writer) error { return nil },
+		RunsFn:         func(fn.Function) ([]fn.PipelineRun, error) { return nil, nil },
+		DescribeRunFn:  func(_ fn.Function, run string) (fn.PipelineRun, error) { return fn.PipelineRun{Name: run}, nil },
+		CancelRunFn:    func(fn.Function, string) error { return nil },
+		RerunFn:        func(_ fn.Function, run string) (string, error) { return run + "-rerun", nil },
+		ClearCacheFn:   func(fn.Function) error { return nil },
 	}
 }
 
@@ -73,3 +93,34 @@ func (p *PipelinesProvider) RemovePAC(ctx context.Context, f fn.Function, metada
 	p.RemovePACInvoked = true
 	return p.RemovePACFn(f)
 }
+
+func (p *PipelinesProvider) Logs(ctx context.Context, f fn.Function, run string, w io.Writer) error {
+	p.LogsInvoked = true
+	return p.LogsFn(f, run, w)
+}
+
+func (p *PipelinesProvider) Runs(ctx context.Context, f fn.Function) ([]fn.PipelineRun, error) {
+	p.RunsInvoked = true
+	return p.RunsFn(f)
+}
+
+func (p *PipelinesProvider) DescribeRun(ctx context.Context, f fn.Function, run string) (fn.PipelineRun, error) {
+	p.DescribeRunInvoked = true
+	return p.DescribeRunFn(f, run)
+}
+
+func (p *PipelinesProvider) CancelRun(ctx context.Context, f fn.Function, run string) error {
+	p.CancelRunInvoked = true
+	return p.CancelRunFn(f, run)
+}
+
+func (p *PipelinesProvider) Rerun(ctx context.Context, f fn.Function, run string) (string, error) {
+	p.RerunInvoked = true
+	return p.RerunFn(f, run)
+}
+
+func (p *PipelinesProvider) ClearCache(ctx context.Context, f fn.Function) error {
+	p.ClearCacheInvoked = true
+	p.ClearSharedCache, _ = ctx.Value(fn.ClearSharedCacheKey{}).(bool)
+	return p.ClearCacheFn(f)
+}
@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type Preflighter struct {
+	PreflightInvoked bool
+	PreflightFn      func(fn.Function) (fn.PreflightReport, error)
+}
+
+func NewPreflighter() *Preflighter {
+	return &Preflighter{PreflightFn: func(fn.Function) (fn.PreflightReport, error) { return fn.PreflightReport{}, nil }}
+}
+
+func (p *Preflighter) Preflight(_ context.Context, f fn.Function) (fn.PreflightReport, error) {
+	p.PreflightInvoked = true
+	return p.PreflightFn(f)
+}
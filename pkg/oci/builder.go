@@ -21,6 +21,7 @@ var path = filepath.Join
 var defaultIgnored = []string{ // TODO: implement and use .funcignore
 	".git",
 	".func",
+	fn.ResourcesDir,
 	".funcignore",
 	".gitignore",
 }
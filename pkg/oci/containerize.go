@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	slashpath "path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,6 +19,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/pkg/errors"
+
+	fn "knative.dev/func/pkg/functions"
 )
 
 // languageLayerBuilder builds the layer for the given language whuch may
@@ -33,7 +36,7 @@ var languageLayerBuilders = map[string]languageLayerBuilder{
 }
 
 func layerBuilderNotImplemented(cfg *buildConfig, _ v1.Platform) (d v1.Descriptor, l v1.Layer, err error) {
-	err = fmt.Errorf("%v functions are not yet supported by the host builder", cfg.f.Runtime)
+	err = ErrRuntimeNotSupported{cfg.f.Runtime}
 	return
 }
 
@@ -524,6 +527,28 @@ func newConfigEnvs(cfg *buildConfig) []string {
 	return append(envs, cfg.f.Run.Envs.Slice()...)
 }
 
+// newBuildEnvs returns the function's build envs, with references to local
+// environment variables and files interpolated, in a stable order.  These are
+// the environment of the build itself, and are not built into the container
+// (see newConfigEnvs), as values read from files or from the local
+// environment would otherwise be published with the image.
+func newBuildEnvs(f fn.Function) ([]string, error) {
+	buildEnvs, err := fn.InterpolateIn(f.Root, f.Build.BuildEnvs)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(buildEnvs))
+	for k := range buildEnvs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	envs := make([]string, 0, len(names))
+	for _, k := range names {
+		envs = append(envs, k+"="+buildEnvs[k])
+	}
+	return envs, nil
+}
+
 func newImageIndex(cfg *buildConfig, imageDescs []v1.Descriptor) (index v1.IndexManifest, err error) {
 	index = v1.IndexManifest{
 		SchemaVersion: 2,
@@ -58,7 +58,10 @@ func goBuild(cfg *buildConfig, p v1.Platform) (binPath string, err error) {
 	if err != nil {
 		return
 	}
-	envs := goBuildEnvs(cfg.f, p)
+	envs, err := goBuildEnvs(cfg.f, p)
+	if err != nil {
+		return
+	}
 	if cfg.verbose {
 		fmt.Printf("%v %v\n", gobin, strings.Join(args, " "))
 	} else {
@@ -108,7 +111,10 @@ func goBuildCmd(p v1.Platform, cfg *buildConfig) (gobin string, args []string, o
 	return gobin, args, outpath, nil
 }
 
-func goBuildEnvs(f fn.Function, p v1.Platform) (envs []string) {
+// goBuildEnvs returns the environment of the build for the given platform: the
+// local environment and the function's build envs, which take precedence,
+// except for those variables pegged to the platform.
+func goBuildEnvs(f fn.Function, p v1.Platform) (envs []string, err error) {
 	pegged := []string{
 		"CGO_ENABLED=0",
 		"GOOS=" + p.OS,
@@ -130,13 +136,18 @@ func goBuildEnvs(f fn.Function, p v1.Platform) (envs []string) {
 		return false
 	}
 
+	buildEnvs, err := newBuildEnvs(f)
+	if err != nil {
+		return
+	}
+
 	envs = append(envs, pegged...)
-	for _, env := range os.Environ() {
+	for _, env := range append(os.Environ(), buildEnvs...) {
 		if !isPegged(env) {
 			envs = append(envs, env)
 		}
 	}
-	return envs
+	return
 }
 
 func newExecTarball(source, target string, verbose bool) error {
@@ -1,11 +1,18 @@
 package oci
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	fn "knative.dev/func/pkg/functions"
 )
 
 // Test_validatedLinkTaarget ensures that the function disallows
@@ -64,3 +71,45 @@ func Test_validatedLinkTarget(t *testing.T) {
 	}
 
 }
+
+// Test_goBuildEnvs ensures that the function's build envs, interpolated, are
+// the environment of the build (except for those pegged to the platform),
+// but are not built into the container.
+func Test_goBuildEnvs(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "token"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		token = "TOKEN"
+		file  = "{{ file:token }}"
+		goos  = "GOOS"
+		plan9 = "plan9"
+	)
+	f := fn.Function{Root: root, Runtime: "go"}
+	f.Build.BuildEnvs = []fn.Env{{Name: &token, Value: &file}, {Name: &goos, Value: &plan9}}
+
+	envs, err := goBuildEnvs(f, v1.Platform{OS: "linux", Architecture: "amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(envs, "TOKEN=secret") {
+		t.Errorf("expected the interpolated build env, got %v", envs)
+	}
+	if slices.Contains(envs, "GOOS=plan9") || !slices.Contains(envs, "GOOS=linux") {
+		t.Errorf("expected GOOS pegged to the platform, got %v", envs)
+	}
+
+	cfg := &buildConfig{ctx: context.Background(), f: f, t: time.Now()}
+	for _, env := range newConfigEnvs(cfg) {
+		if env == "TOKEN=secret" {
+			t.Fatalf("expected the build envs not to be built into the container")
+		}
+	}
+
+	missing := "{{ file:missing }}"
+	f.Build.BuildEnvs = []fn.Env{{Name: &token, Value: &missing}}
+	if _, err = goBuildEnvs(f, v1.Platform{OS: "linux", Architecture: "amd64"}); err == nil {
+		t.Fatal("expected an error for a build env referencing a missing file")
+	}
+}
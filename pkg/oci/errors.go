@@ -18,3 +18,13 @@ type ErrBuildInProgress struct {
 func (e ErrBuildInProgress) Error() string {
 	return fmt.Sprintf("a build for this function is associated with an active PID appears to be already in progress %v", e.Dir)
 }
+
+// ErrRuntimeNotSupported indicates functions of the given runtime can not
+// (yet) be built by the host builder.
+type ErrRuntimeNotSupported struct {
+	Runtime string
+}
+
+func (e ErrRuntimeNotSupported) Error() string {
+	return fmt.Sprintf("%v functions are not yet supported by the host builder", e.Runtime)
+}
@@ -0,0 +1,353 @@
+package job
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/builders/s2i"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/pipelines/tekton"
+)
+
+var (
+	// GitImage clones the function's Git repository.
+	GitImage = "docker.io/alpine/git:2.45.2"
+	// GoImage provides the Go toolchain used by the host builder.
+	GoImage = "docker.io/library/golang:1.23"
+	// BuildahImage builds and pushes the images of the s2i builder.
+	BuildahImage = "quay.io/buildah/stable:v1.31.0"
+)
+
+const (
+	workspacePath    = "/workspace" // the PVC, with the sources in ./source
+	resultsPath      = "/results"
+	dockerConfigPath = "/docker-config"
+	toolsPath        = "/tools"
+	genSourcePath    = "/gen-source"
+	scriptsPath      = "/scripts"
+	cachePath        = "/cache" // the cache PVC
+	digestFile       = resultsPath + "/digest"
+
+	defaultS2iImageScriptsUrl = "image:///usr/libexec/s2i"
+	quarkusS2iImageScriptsUrl = "image:///usr/local/s2i"
+)
+
+func getJobGenerateName(f fn.Function) string {
+	return fmt.Sprintf("%s-build-", f.Name)
+}
+
+func getJobSecretName(f fn.Function) string {
+	return fmt.Sprintf("%s-build-secret", f.Name)
+}
+
+func getJobPvcName(f fn.Function) string {
+	return fmt.Sprintf("%s-build-pvc", f.Name)
+}
+
+// newJob returns the Job which builds the function with the host or s2i
+// builder, pushes its image, and deploys it.  Each step is a container of the
+// Job's pod, run in order: fetching the sources from Git if set (else they
+// are expected on the PVC already), the stages before build, scaffolding,
+// building, the stages before deploy and deploying.  The TLS certificate of
+// the registry is not verified by the host builder if registryInsecure.
+func newJob(f fn.Function, image, registry string, labels map[string]string, registryInsecure bool) (*batchv1.Job, error) {
+	sourcePath := path.Join(workspacePath, "source")
+	if f.Build.Git.URL != "" && f.Build.Git.ContextDir != "" {
+		sourcePath = path.Join(sourcePath, f.Build.Git.ContextDir)
+	}
+
+	var buildEnvs []string
+	for _, e := range f.Build.BuildEnvs {
+		buildEnvs = append(buildEnvs, e.KeyValuePair())
+	}
+	if f.Build.Builder == builders.S2I {
+		buildEnvs = append(buildEnvs, tekton.S2ICacheEnvs(f)...)
+	}
+
+	preBuild, preDeploy, err := stageSteps(f, sourcePath, image)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []corev1.Container
+	if f.Build.Git.URL != "" {
+		steps = append(steps, fetchSourcesStep(f))
+	}
+	steps = append(steps, preBuild...)
+	switch f.Build.Builder {
+	case builders.Host:
+		steps = append(steps, hostBuildSteps(sourcePath, image, registry, buildEnvs, registryInsecure)...)
+	case builders.S2I:
+		builderImage, err := s2i.BuilderImage(f, builders.S2I)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, scaffoldStep(sourcePath))
+		steps = append(steps, s2iBuildSteps(f, sourcePath, image, registry, builderImage, buildEnvs)...)
+	default:
+		return nil, fmt.Errorf("the %q builder is not supported by the job remote builder, use %q or %q", f.Build.Builder, builders.Host, builders.S2I)
+	}
+	steps = append(steps, preDeploy...)
+	names := map[string]bool{"deploy": true}
+	for _, step := range steps {
+		if names[step.Name] {
+			return nil, fmt.Errorf("the stage %q has the name of a step of the job remote builder", step.Name)
+		}
+		names[step.Name] = true
+	}
+	deploy := corev1.Container{
+		Name:    "deploy",
+		Image:   tekton.DeployerImage,
+		Command: []string{"/func-util", "deploy", sourcePath, image},
+		Env: []corev1.EnvVar{
+			{Name: "FUNC_DIGEST_FILE", Value: digestFile},
+			{Name: "FUNC_ENVIRONMENT", Value: f.Environment},
+		},
+	}
+
+	emptyDir := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	podSpec := corev1.PodSpec{
+		RestartPolicy:  corev1.RestartPolicyNever,
+		InitContainers: steps,
+		Containers:     []corev1.Container{deploy},
+		Volumes: []corev1.Volume{
+			{Name: "workspace", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: getJobPvcName(f)},
+			}},
+			{Name: "cache", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: tekton.GetCachePvcName(f)},
+			}},
+			{Name: "docker-config", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: getJobSecretName(f)},
+			}},
+			{Name: "results", VolumeSource: emptyDir},
+			{Name: "tools", VolumeSource: emptyDir},
+			{Name: "gen-source", VolumeSource: emptyDir},
+			{Name: "var-lib-containers", VolumeSource: emptyDir},
+			{Name: "scripts", VolumeSource: emptyDir},
+		},
+	}
+	// every step has the workspace, caches, results and credentials mounted
+	common := []corev1.VolumeMount{
+		{Name: "workspace", MountPath: workspacePath},
+		{Name: "cache", MountPath: cachePath, SubPath: "cache"},
+		{Name: "results", MountPath: resultsPath},
+		{Name: "docker-config", MountPath: dockerConfigPath, ReadOnly: true},
+	}
+	for _, cc := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range cc {
+			cc[i].VolumeMounts = append(append([]corev1.VolumeMount{}, common...), cc[i].VolumeMounts...)
+			cc[i].Env = append(cc[i].Env, corev1.EnvVar{Name: "DOCKER_CONFIG", Value: dockerConfigPath})
+		}
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: getJobGenerateName(f),
+			Labels:       labels,
+			Annotations:  f.Deploy.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](0),
+			// the pod is not labelled as the function, not to be mistaken
+			// for one of its instances
+			Template: corev1.PodTemplateSpec{Spec: podSpec},
+		},
+	}, nil
+}
+
+// fetchSourcesStep clones the revision of the function's Git repository.
+func fetchSourcesStep(f fn.Function) corev1.Container {
+	script := `set -e
+rm -rf ` + workspacePath + `/source
+mkdir -p ` + workspacePath + `/source
+cd ` + workspacePath + `/source
+git init -q
+git remote add origin "$GIT_URL"
+git fetch -q --depth 1 origin "${GIT_REVISION:-HEAD}"
+git checkout -q FETCH_HEAD
+git log -1 --format="Cloned $GIT_URL at %H"
+`
+	return corev1.Container{
+		Name:    "fetch-sources",
+		Image:   GitImage,
+		Command: []string{"sh", "-c", script},
+		Env: []corev1.EnvVar{
+			{Name: "GIT_URL", Value: f.Build.Git.URL},
+			{Name: "GIT_REVISION", Value: f.Build.Git.Revision},
+		},
+	}
+}
+
+// stageSteps return the steps of the function's stages which run before build
+// and before deploy.  The steps of a pod run one at a time, so the stages are
+// ordered such that each runs after those it names in runAfter, otherwise in
+// the order they are defined.  Each script is written to the scripts volume
+// and run in the function's source directory, as Tekton does, so the image of
+// a stage must provide sh.
+func stageSteps(f fn.Function, sourcePath, image string) (preBuild, preDeploy []corev1.Container, err error) {
+	for _, s := range orderStages(f.Build.Stages) {
+		env, envFrom, err := k8s.ProcessContainerEnvs(f.Root, s.Envs, &sets.Set[string]{}, &sets.Set[string]{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("stage %q: %w", s.Name, err)
+		}
+		script := s.Script
+		if !strings.HasPrefix(script, "#!") {
+			script = "#!/bin/sh\nset -e\n" + script
+		}
+		run := fmt.Sprintf(`printf '%%s' "$FUNC_STAGE_SCRIPT" > %[1]s && chmod +x %[1]s && exec %[1]s`, path.Join(scriptsPath, s.Name))
+		if s.BeforeDeploy() {
+			run = `export FUNC_IMAGE="$FUNC_IMAGE@$(cat ` + digestFile + `)" && ` + run
+			env = append(env, corev1.EnvVar{Name: "FUNC_IMAGE", Value: image})
+		}
+		step := corev1.Container{
+			Name:         s.Name,
+			Image:        s.Image,
+			WorkingDir:   sourcePath,
+			Command:      []string{"sh", "-c", run},
+			Env:          append(env, corev1.EnvVar{Name: "FUNC_STAGE_SCRIPT", Value: script}),
+			EnvFrom:      envFrom,
+			VolumeMounts: []corev1.VolumeMount{{Name: "scripts", MountPath: scriptsPath}},
+		}
+		if s.BeforeDeploy() {
+			preDeploy = append(preDeploy, step)
+		} else {
+			preBuild = append(preBuild, step)
+		}
+	}
+	return
+}
+
+// orderStages returns the stages such that each follows those it runs after,
+// otherwise in the order they are defined.  Stages are validated to run
+// after existing stages, without cycles.
+func orderStages(stages []fn.Stage) []fn.Stage {
+	byName := make(map[string]fn.Stage, len(stages))
+	for _, s := range stages {
+		byName[s.Name] = s
+	}
+	var (
+		ordered []fn.Stage
+		visited = map[string]bool{}
+		visit   func(s fn.Stage)
+	)
+	visit = func(s fn.Stage) {
+		if visited[s.Name] {
+			return
+		}
+		visited[s.Name] = true
+		for _, after := range s.RunAfter {
+			if other, ok := byName[after]; ok {
+				visit(other)
+			}
+		}
+		ordered = append(ordered, s)
+	}
+	for _, s := range stages {
+		visit(s)
+	}
+	return ordered
+}
+
+// scaffoldStep writes the scaffolding of the function (for s2i).
+func scaffoldStep(sourcePath string) corev1.Container {
+	return corev1.Container{
+		Name:    "scaffold",
+		Image:   tekton.DeployerImage,
+		Command: []string{"/func-util", "scaffold", sourcePath},
+	}
+}
+
+// hostBuildSteps copy the Go toolchain, then build and push the function with
+// the host builder, as the func-build-host task of the Tekton pipelines.
+// The Go caches are kept on the cache PVC.
+func hostBuildSteps(sourcePath, image, registry string, buildEnvs []string, insecure bool) []corev1.Container {
+	tools := []corev1.VolumeMount{{Name: "tools", MountPath: toolsPath}}
+	return []corev1.Container{
+		{
+			Name:         "prepare",
+			Image:        GoImage,
+			Command:      []string{"sh", "-c", "cp -R /usr/local/go " + toolsPath + "/go && cp -RL /etc/ssl/certs " + toolsPath + "/certs"},
+			VolumeMounts: tools,
+		},
+		{
+			Name:       "build",
+			Image:      tekton.DeployerImage,
+			WorkingDir: sourcePath,
+			Command: append([]string{"/func-util", "host-build",
+				"--image", image,
+				"--registry", registry,
+				"--digest-file", digestFile,
+				"--insecure=" + strconv.FormatBool(insecure),
+			}, buildEnvs...),
+			Env: []corev1.EnvVar{
+				{Name: "FUNC_GO_PATH", Value: toolsPath + "/go/bin/go"},
+				{Name: "GOCACHE", Value: cachePath + "/go-build"},
+				{Name: "GOMODCACHE", Value: cachePath + "/go-mod"},
+				{Name: "GOPATH", Value: toolsPath + "/gopath"},
+				{Name: "GOFLAGS", Value: "-buildvcs=false"},
+				{Name: "HOME", Value: toolsPath + "/home"},
+				{Name: "SSL_CERT_DIR", Value: toolsPath + "/certs"},
+			},
+			VolumeMounts: tools,
+		},
+	}
+}
+
+// s2iBuildSteps generate the Dockerfile of the function's s2i build, then
+// build and push it with buildah, as the func-s2i task of the Tekton
+// pipelines.  The artifacts cache is kept on the cache PVC.
+func s2iBuildSteps(f fn.Function, sourcePath, image, registry, builderImage string, buildEnvs []string) []corev1.Container {
+	scriptsURL := defaultS2iImageScriptsUrl
+	if f.Runtime == "quarkus" {
+		scriptsURL = quarkusS2iImageScriptsUrl
+	}
+	genSource := corev1.VolumeMount{Name: "gen-source", MountPath: genSourcePath}
+	script := strings.Join([]string{
+		"set -e",
+		`ARTIFACTS_CACHE_PATH="` + cachePath + `/mvn-artifacts"`,
+		`mkdir -p "${ARTIFACTS_CACHE_PATH}"`,
+		`buildah bud --storage-driver=vfs --layers -v "${ARTIFACTS_CACHE_PATH}:/tmp/artifacts/:rw,z,U" -f ` + genSourcePath + `/Dockerfile.gen -t "$IMAGE" .`,
+		`buildah push --storage-driver=vfs --digestfile ` + digestFile + ` "$IMAGE" "docker://$IMAGE"`,
+	}, "\n")
+	return []corev1.Container{
+		{
+			Name:       "generate",
+			Image:      tekton.DeployerImage,
+			WorkingDir: sourcePath,
+			Command: append([]string{"/func-util", "s2i-generate",
+				"--target", genSourcePath,
+				"--path-context", ".",
+				"--builder-image", builderImage,
+				"--registry", registry,
+				"--image-script-url", scriptsURL,
+				"--log-level", "0",
+			}, buildEnvs...),
+			VolumeMounts: []corev1.VolumeMount{genSource},
+		},
+		{
+			Name:       "build",
+			Image:      BuildahImage,
+			WorkingDir: genSourcePath,
+			Command:    []string{"sh", "-c", script},
+			Env:        []corev1.EnvVar{{Name: "IMAGE", Value: image}},
+			VolumeMounts: []corev1.VolumeMount{
+				genSource,
+				{Name: "var-lib-containers", MountPath: "/var/lib/containers"},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SETFCAP"}},
+			},
+		},
+	}
+}
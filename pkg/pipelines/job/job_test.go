@@ -0,0 +1,222 @@
+//go:build !integration
+// +build !integration
+
+package job
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func stepNames(job *batchv1.Job) []string {
+	var names []string
+	for _, c := range job.Spec.Template.Spec.InitContainers {
+		names = append(names, c.Name)
+	}
+	for _, c := range job.Spec.Template.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestNewJob(t *testing.T) {
+	tests := []struct {
+		name      string
+		f         fn.Function
+		wantSteps []string
+		wantErr   bool
+	}{
+		{
+			name:      "host",
+			f:         fn.Function{Name: "myfunc", Runtime: "go", Build: fn.BuildSpec{Builder: builders.Host}},
+			wantSteps: []string{"prepare", "build", "deploy"},
+		},
+		{
+			name:      "s2i",
+			f:         fn.Function{Name: "myfunc", Runtime: "node", Build: fn.BuildSpec{Builder: builders.S2I}},
+			wantSteps: []string{"scaffold", "generate", "build", "deploy"},
+		},
+		{
+			name: "git",
+			f: fn.Function{Name: "myfunc", Runtime: "go", Build: fn.BuildSpec{
+				Builder: builders.Host,
+				Git:     fn.Git{URL: "https://example.com/alice/myfunc.git", ContextDir: "fn"},
+			}},
+			wantSteps: []string{"fetch-sources", "prepare", "build", "deploy"},
+		},
+		{
+			name: "stages",
+			f: fn.Function{Name: "myfunc", Runtime: "go", Build: fn.BuildSpec{
+				Builder: builders.Host,
+				Stages: []fn.Stage{
+					{Name: "unit", Image: "golang", Script: "go test ./...", RunAfter: []string{"lint"}},
+					{Name: "lint", Image: "golangci/golangci-lint", Script: "golangci-lint run"},
+					{Name: "scan", Before: fn.StageBeforeDeploy, Image: "aquasec/trivy", Script: "trivy image $FUNC_IMAGE"},
+				},
+			}},
+			wantSteps: []string{"lint", "unit", "prepare", "build", "scan", "deploy"},
+		},
+		{
+			name:      "environment",
+			f:         fn.Function{Name: "myfunc", Runtime: "go", Environment: "staging", Build: fn.BuildSpec{Builder: builders.Host}},
+			wantSteps: []string{"prepare", "build", "deploy"},
+		},
+		{
+			name: "stages may not have the name of a step",
+			f: fn.Function{Name: "myfunc", Runtime: "go", Build: fn.BuildSpec{
+				Builder: builders.Host,
+				Stages:  []fn.Stage{{Name: "prepare", Image: "alpine", Script: "true"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "pack is not supported",
+			f:       fn.Function{Name: "myfunc", Runtime: "go", Build: fn.BuildSpec{Builder: builders.Pack}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{"function.knative.dev/name": "myfunc"}
+			job, err := newJob(tt.f, "example.com/alice/myfunc:latest", "example.com/alice", labels, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := stepNames(job); strings.Join(got, ",") != strings.Join(tt.wantSteps, ",") {
+				t.Fatalf("expected steps %v, got %v", tt.wantSteps, got)
+			}
+			if job.Labels["function.knative.dev/name"] != "myfunc" {
+				t.Errorf("expected the job to be labelled, got %v", job.Labels)
+			}
+			if len(job.Spec.Template.Labels) != 0 {
+				t.Errorf("expected the pod not to be labelled as the function, got %v", job.Spec.Template.Labels)
+			}
+			deploy := job.Spec.Template.Spec.Containers[0]
+			wantSource := "/workspace/source"
+			if tt.f.Build.Git.ContextDir != "" {
+				wantSource += "/" + tt.f.Build.Git.ContextDir
+			}
+			if deploy.Command[2] != wantSource || deploy.Command[3] != "example.com/alice/myfunc:latest" {
+				t.Errorf("unexpected deploy command %v", deploy.Command)
+			}
+			for _, e := range deploy.Env {
+				if e.Name == "FUNC_ENVIRONMENT" && e.Value != tt.f.Environment {
+					t.Errorf("expected the environment %q deployed to, got %q", tt.f.Environment, e.Value)
+				}
+			}
+			for _, c := range append(job.Spec.Template.Spec.InitContainers, deploy) {
+				if len(c.VolumeMounts) < 2 || c.VolumeMounts[0].MountPath != workspacePath || c.VolumeMounts[1].MountPath != cachePath {
+					t.Errorf("expected the workspace and cache mounted in step %q", c.Name)
+				}
+			}
+		})
+	}
+}
+
+// TestNewJob_RegistryInsecure ensures that the host builder is told whether
+// to verify the TLS certificate of the registry.
+func TestNewJob_RegistryInsecure(t *testing.T) {
+	f := fn.Function{Name: "myfunc", Runtime: "go", Build: fn.BuildSpec{Builder: builders.Host}}
+	for _, insecure := range []bool{true, false} {
+		job, err := newJob(f, "example.com/alice/myfunc:latest", "example.com/alice", nil, insecure)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var build []string
+		for _, c := range job.Spec.Template.Spec.InitContainers {
+			if c.Name == "build" {
+				build = c.Command
+			}
+		}
+		want := fmt.Sprintf("--insecure=%v", insecure)
+		if !slices.Contains(build, want) {
+			t.Errorf("expected %v in the build command, got %v", want, build)
+		}
+	}
+}
+
+func newFakeJob(t *testing.T, succeeded bool) *fake.Clientset {
+	t.Helper()
+	terminated := func(code int32) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: code}}
+	}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "myfunc-build-abc", Namespace: "ns"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "myfunc-build-abc-xyz", Namespace: "ns", Labels: map[string]string{"job-name": job.Name}},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "prepare"}, {Name: "build"}},
+			Containers:     []corev1.Container{{Name: "deploy"}},
+		},
+	}
+	if succeeded {
+		job.Status.Succeeded = 1
+		pod.Status.Phase = corev1.PodSucceeded
+		pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+			{Name: "prepare", State: terminated(0)},
+			{Name: "build", State: terminated(0)},
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "deploy", State: terminated(0)}}
+	} else {
+		job.Status.Failed = 1
+		pod.Status.Phase = corev1.PodFailed
+		pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+			{Name: "prepare", State: terminated(0)},
+			{Name: "build", State: terminated(2)},
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+			{Name: "deploy", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+		}
+	}
+	return fake.NewSimpleClientset(job, pod)
+}
+
+func TestFollowJob(t *testing.T) {
+	defer func(d time.Duration) { pollInterval = d }(pollInterval)
+	pollInterval = time.Millisecond
+
+	client := newFakeJob(t, true)
+	var out bytes.Buffer
+	if err := followJob(context.Background(), client, "ns", "myfunc-build-abc", &out, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"Running build step: prepare", "[build] fake logs", "Running build step: deploy"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q in the output:\n%s", s, out.String())
+		}
+	}
+}
+
+func TestFollowJob_Failed(t *testing.T) {
+	defer func(d time.Duration) { pollInterval = d }(pollInterval)
+	pollInterval = time.Millisecond
+
+	client := newFakeJob(t, false)
+	var out bytes.Buffer
+	err := followJob(context.Background(), client, "ns", "myfunc-build-abc", &out, true)
+	if err == nil || !strings.Contains(err.Error(), `step "build" failed with exit code 2`) {
+		t.Fatalf("expected the failed step in the error, got %v", err)
+	}
+	if strings.Contains(out.String(), "deploy") {
+		t.Errorf("expected the deploy step not to run:\n%s", out.String())
+	}
+	// when quiet, the logs of the failed step are part of the error only
+	if strings.Contains(out.String(), "fake logs") || !strings.Contains(err.Error(), "fake logs") {
+		t.Errorf("expected the logs in the error only, got %v and:\n%s", err, out.String())
+	}
+}
@@ -0,0 +1,462 @@
+package job
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+	"knative.dev/func/pkg/pipelines/tekton"
+)
+
+// ErrNotSupported is returned for the operations which need Tekton, such as
+// Pipelines as Code and the history of pipeline runs.
+var ErrNotSupported = errors.New("not supported by the job remote builder")
+
+// pollInterval of the status of the build Job and of its pod.
+var pollInterval = time.Second
+
+// failedJobTTL is how long a failed build Job is kept for inspection.
+const failedJobTTL = 24 * time.Hour
+
+type Opt func(*PipelinesProvider)
+
+// PipelinesProvider builds and deploys functions on cluster with a plain
+// Kubernetes Job, for clusters without Tekton.
+type PipelinesProvider struct {
+	verbose             bool
+	quiet               bool
+	credentialsProvider docker.CredentialsProvider
+	decorator           tekton.PipelineDecorator
+	secretsKeyFile      string
+	registryInsecure    bool
+}
+
+var _ fn.PipelinesProvider = (*PipelinesProvider)(nil)
+
+func WithCredentialsProvider(credentialsProvider docker.CredentialsProvider) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.credentialsProvider = credentialsProvider
+	}
+}
+
+func WithVerbose(verbose bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.verbose = verbose
+	}
+}
+
+// WithQuiet disables the streaming of the logs of the build, such that only
+// the name of each step is printed as it starts.
+func WithQuiet(quiet bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.quiet = quiet
+	}
+}
+
+func WithPipelineDecorator(decorator tekton.PipelineDecorator) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.decorator = decorator
+	}
+}
+
+// WithSecretsKey sets the path to the key used to decrypt the secrets managed
+// with the function, which are created by the client before the pipeline
+// runs such that the key never leaves it.
+func WithSecretsKey(keyFile string) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.secretsKeyFile = keyFile
+	}
+}
+
+// WithRegistryInsecure skips the verification of the TLS certificate of the
+// registry to which the host builder pushes the function's image.
+func WithRegistryInsecure(insecure bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.registryInsecure = insecure
+	}
+}
+
+func NewPipelinesProvider(opts ...Opt) *PipelinesProvider {
+	pp := &PipelinesProvider{}
+	for _, opt := range opts {
+		opt(pp)
+	}
+	return pp
+}
+
+// Run a remote build by uploading the sources of the function to a PVC,
+// unless it is built from Git, and running a Job which builds, pushes and
+// deploys it.  The logs of the Job are streamed to stderr, and the Job is
+// deleted once it succeeds.
+// Returned is the final url, and the input Function with the final results of
+// the run populated (f.Deploy.Image and f.Deploy.Namespace) or an error.
+func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn.Function, error) {
+	var err error
+
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if namespace == "" {
+		return "", f, fn.ErrNamespaceRequired
+	}
+	f.Deploy.Namespace = namespace
+
+	image := f.Image
+	if image == "" {
+		image, err = f.ImageName()
+		if err != nil {
+			return "", f, err
+		}
+	}
+	f.Deploy.Image = image
+
+	labels, err := f.LabelsMap()
+	if err != nil {
+		return "", f, err
+	}
+	if pp.decorator != nil {
+		labels = pp.decorator.UpdateLabels(f, labels)
+	}
+
+	registry, err := docker.GetRegistry(image)
+	if err != nil {
+		return "", f, fmt.Errorf("problem in resolving image registry name: %v", err)
+	}
+	if registry == name.DefaultRegistry {
+		registry = authn.DefaultAuthKey
+	}
+	if f.Registry == "" {
+		f.Registry = registry
+	}
+
+	// the Job is generated first, so unsupported builders fail early
+	job, err := newJob(f, image, f.Registry, labels, pp.registryInsecure)
+	if err != nil {
+		return "", f, err
+	}
+	job.Spec.TTLSecondsAfterFinished = ptr.To(int32(failedJobTTL.Seconds()))
+
+	pvcs := tekton.DefaultPersistentVolumeClaimSize
+	if f.Build.PVCSize != "" {
+		if pvcs, err = resource.ParseQuantity(f.Build.PVCSize); err != nil {
+			return "", f, fmt.Errorf("PVC size value could not be parsed. %w", err)
+		}
+	}
+	err = k8s.CreatePersistentVolumeClaim(ctx, getJobPvcName(f), namespace, labels, f.Deploy.Annotations, corev1.ReadWriteOnce, pvcs)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return "", f, fmt.Errorf("problem creating persistent volume claim: %v", err)
+	}
+
+	err = tekton.CreateCachePersistentVolumeClaim(ctx, f, namespace, labels)
+	if err != nil {
+		return "", f, err
+	}
+
+	if f.Build.Git.URL == "" {
+		// Sync the sources to the PVC if Git is not set up.
+		err = tekton.SyncSources(ctx, f, getJobPvcName(f), namespace)
+		if err != nil {
+			return "", f, fmt.Errorf("cannot upload sources to the PVC: %w", err)
+		}
+	}
+
+	creds, err := pp.credentialsProvider(ctx, image)
+	if err != nil {
+		return "", f, err
+	}
+	err = k8s.EnsureDockerRegistrySecretExist(ctx, getJobSecretName(f), namespace, labels, f.Deploy.Annotations, creds.Username, creds.Password, registry)
+	if err != nil {
+		return "", f, fmt.Errorf("problem in creating secret: %v", err)
+	}
+	err = k8s.EnsureFunctionResources(ctx, f, namespace, pp.secretsKeyFile)
+	if err != nil {
+		return "", f, fmt.Errorf("problem in creating the function's resources: %v", err)
+	}
+
+	client, err := k8s.NewKubernetesClientset(ctx)
+	if err != nil {
+		return "", f, err
+	}
+	jobs := client.BatchV1().Jobs(namespace)
+	job, err = jobs.Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return "", f, fmt.Errorf("problem in creating build job: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Running build job %q\n", job.Name)
+
+	// the pod of the Job is deleted with it
+	deleteJob := func() {
+		_ = jobs.Delete(context.Background(), job.Name, metav1.DeleteOptions{
+			PropagationPolicy: ptr.To(metav1.DeletePropagationBackground),
+		})
+	}
+	err = followJob(ctx, client, namespace, job.Name, os.Stderr, pp.quiet)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled) {
+			deleteJob()
+			return "", f, fmt.Errorf("build job cancelled: %w", context.Canceled)
+		}
+		return "", f, fmt.Errorf("function build job %q has failed: %w", job.Name, err)
+	}
+	deleteJob()
+
+	url, err := tekton.ReportDeployed(ctx, f, namespace, pp.verbose)
+	return url, f, err
+}
+
+// Remove the Jobs, secrets and PVCs of the function's remote builds, and the
+// ConfigMaps created for the function, as does the Tekton provider.
+func (pp *PipelinesProvider) Remove(ctx context.Context, f fn.Function) error {
+	if f.Deploy.Namespace == "" {
+		return fn.ErrNamespaceRequired
+	}
+	namespace := f.Deploy.Namespace
+
+	l := k8slabels.SelectorFromSet(k8slabels.Set(map[string]string{fnlabels.FunctionNameKey: f.Name}))
+	listOptions := metav1.ListOptions{
+		LabelSelector: l.String(),
+	}
+
+	deleteFunctions := []func(context.Context, string, metav1.ListOptions) error{
+		deleteJobs,
+		k8s.DeleteSecrets,
+		k8s.DeleteConfigMaps,
+		k8s.DeletePersistentVolumeClaims,
+	}
+	var errs []error
+	for _, df := range deleteFunctions {
+		err := df(ctx, namespace, listOptions)
+		if err != nil && !k8serrors.IsNotFound(err) && !k8serrors.IsForbidden(err) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error deleting resources: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func deleteJobs(ctx context.Context, namespace string, listOptions metav1.ListOptions) error {
+	client, err := k8s.NewKubernetesClientset(ctx)
+	if err != nil {
+		return err
+	}
+	return client.BatchV1().Jobs(namespace).DeleteCollection(ctx, metav1.DeleteOptions{
+		PropagationPolicy: ptr.To(metav1.DeletePropagationBackground),
+	}, listOptions)
+}
+
+// ClearCache of the function's remote builds, held by the same PVC as with
+// Tekton.
+func (pp *PipelinesProvider) ClearCache(ctx context.Context, f fn.Function) error {
+	return tekton.ClearCache(ctx, f)
+}
+
+func (pp *PipelinesProvider) ConfigurePAC(context.Context, fn.Function, any) error {
+	return fmt.Errorf("pipelines as code is %w", ErrNotSupported)
+}
+
+func (pp *PipelinesProvider) RemovePAC(context.Context, fn.Function, any) error {
+	return fmt.Errorf("pipelines as code is %w", ErrNotSupported)
+}
+
+func (pp *PipelinesProvider) Logs(context.Context, fn.Function, string, io.Writer) error {
+	return fmt.Errorf("the logs of past runs are %w", ErrNotSupported)
+}
+
+func (pp *PipelinesProvider) Runs(context.Context, fn.Function) ([]fn.PipelineRun, error) {
+	return nil, fmt.Errorf("the history of runs is %w", ErrNotSupported)
+}
+
+func (pp *PipelinesProvider) DescribeRun(context.Context, fn.Function, string) (fn.PipelineRun, error) {
+	return fn.PipelineRun{}, fmt.Errorf("the history of runs is %w", ErrNotSupported)
+}
+
+func (pp *PipelinesProvider) CancelRun(context.Context, fn.Function, string) error {
+	return fmt.Errorf("cancelling runs is %w", ErrNotSupported)
+}
+
+func (pp *PipelinesProvider) Rerun(context.Context, fn.Function, string) (string, error) {
+	return "", fmt.Errorf("re-running runs is %w", ErrNotSupported)
+}
+
+// followJob follows the steps of the build Job until it completes, printing
+// the name of each step as it starts and, unless quiet, streaming its logs.
+// Returned is an error describing the failed step if the Job failed.
+func followJob(ctx context.Context, client kubernetes.Interface, namespace, name string, w io.Writer, quiet bool) error {
+	pod, err := waitForJobPod(ctx, client, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	steps := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, step := range steps {
+		started, err := waitForStep(ctx, client, namespace, pod.Name, step.Name)
+		if err != nil {
+			return err
+		}
+		if !started {
+			// the pod failed, the remaining steps will not run
+			break
+		}
+		fmt.Fprintf(w, "Running build step: %s\n", step.Name)
+		if quiet {
+			continue
+		}
+		if err = streamLogs(ctx, client, namespace, pod.Name, step.Name, w); err != nil {
+			return err
+		}
+	}
+
+	return waitForJob(ctx, client, namespace, name, pod.Name, quiet)
+}
+
+// waitForJobPod returns the pod created for the Job.
+func waitForJobPod(ctx context.Context, client kubernetes.Interface, namespace, name string) (*corev1.Pod, error) {
+	var pod *corev1.Pod
+	err := wait.PollUntilContextCancel(ctx, pollInterval, true, func(ctx context.Context) (bool, error) {
+		pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + name})
+		if err != nil {
+			return false, err
+		}
+		if len(pods.Items) == 0 {
+			return false, nil
+		}
+		pod = &pods.Items[0]
+		return true, nil
+	})
+	return pod, err
+}
+
+// waitForStep waits until the step (container) of the pod starts, returning
+// false if the pod failed before it could.
+func waitForStep(ctx context.Context, client kubernetes.Interface, namespace, pod, step string) (bool, error) {
+	var started bool
+	err := wait.PollUntilContextCancel(ctx, pollInterval, true, func(ctx context.Context) (bool, error) {
+		p, err := client.CoreV1().Pods(namespace).Get(ctx, pod, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if s := stepStatus(p, step); s != nil {
+			if s.State.Running != nil || s.State.Terminated != nil {
+				started = true
+				return true, nil
+			}
+			if s.State.Waiting != nil {
+				switch s.State.Waiting.Reason {
+				case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError":
+					return false, fmt.Errorf("build step %q cannot start: %s: %s", step, s.State.Waiting.Reason, s.State.Waiting.Message)
+				}
+			}
+		}
+		return p.Status.Phase == corev1.PodFailed, nil
+	})
+	return started, err
+}
+
+// waitForJob waits until the Job completes, returning an error describing the
+// failed step if it failed.
+func waitForJob(ctx context.Context, client kubernetes.Interface, namespace, name, pod string, quiet bool) error {
+	var failed bool
+	err := wait.PollUntilContextCancel(ctx, pollInterval, true, func(ctx context.Context) (bool, error) {
+		job, err := client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if job.Status.Succeeded > 0 {
+			return true, nil
+		}
+		for _, c := range job.Status.Conditions {
+			if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+				failed = true
+				return true, nil
+			}
+		}
+		failed = job.Status.Failed > 0
+		return failed, nil
+	})
+	if err != nil || !failed {
+		return err
+	}
+
+	p, err := client.CoreV1().Pods(namespace).Get(ctx, pod, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot get the pod of the job: %w", err)
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...)
+	for _, s := range statuses {
+		t := s.State.Terminated
+		if t == nil || t.ExitCode == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("step %q failed with exit code %d", s.Name, t.ExitCode)
+		if quiet {
+			// the logs of the step were not streamed
+			msg += ":\n\n" + logsTail(ctx, client, namespace, pod, s.Name)
+		}
+		return errors.New(msg)
+	}
+	return errors.New("job failed")
+}
+
+func stepStatus(p *corev1.Pod, step string) *corev1.ContainerStatus {
+	for _, ss := range [][]corev1.ContainerStatus{p.Status.InitContainerStatuses, p.Status.ContainerStatuses} {
+		for i := range ss {
+			if ss[i].Name == step {
+				return &ss[i]
+			}
+		}
+	}
+	return nil
+}
+
+// streamLogs of the step, until it terminates, prefixing each line with the
+// name of the step.
+func streamLogs(ctx context.Context, client kubernetes.Interface, namespace, pod, step string, w io.Writer) error {
+	r, err := client.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: step, Follow: true}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot stream the logs of step %q: %w", step, err)
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fmt.Fprintf(w, "[%s] %s\n", step, scanner.Text())
+	}
+	if err = scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("cannot stream the logs of step %q: %w", step, err)
+	}
+	return ctx.Err()
+}
+
+// logsTail returns the last lines of the logs of the step.
+func logsTail(ctx context.Context, client kubernetes.Interface, namespace, pod, step string) string {
+	b, err := client.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: step, TailLines: ptr.To[int64](50)}).DoRaw(ctx)
+	if err != nil {
+		return fmt.Sprintf("cannot get the logs: %v", err)
+	}
+	return strings.TrimSpace(string(b))
+}
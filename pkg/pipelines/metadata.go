@@ -10,6 +10,7 @@ type PacMetadata struct {
 
 	GitProvider string
 
+	GitUser             string // user of the PersonalAccessToken (Bitbucket)
 	PersonalAccessToken string
 	WebhookSecret       string
 
@@ -0,0 +1,17 @@
+package pipelines
+
+// Remote builders, which run the builds and deployments of functions on
+// cluster (deploy --remote).
+const (
+	// RemoteBuilderTekton runs Tekton pipelines.
+	RemoteBuilderTekton = "tekton"
+	// RemoteBuilderJob runs a Kubernetes Job, on clusters without Tekton.
+	RemoteBuilderJob = "job"
+
+	DefaultRemoteBuilder = RemoteBuilderTekton
+)
+
+// RemoteBuilders returns the names of the known remote builders.
+func RemoteBuilders() []string {
+	return []string{RemoteBuilderTekton, RemoteBuilderJob}
+}
@@ -0,0 +1,198 @@
+package tekton
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/filesync"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// DefaultCachePersistentVolumeClaimSize to allocate for the build caches.
+var DefaultCachePersistentVolumeClaimSize = resource.MustParse("1Gi")
+
+// sharedCachePvcName is the name of the build cache PVC shared by the
+// functions of a namespace.
+const sharedCachePvcName = "func-build-cache"
+
+// sourceCacheSubPath is the directory of the pipeline PVC holding the build
+// caches when they can not be kept on a PVC of their own.
+const sourceCacheSubPath = "cache"
+
+// tektonNamespaces in which the feature flags of Tekton Pipelines are looked
+// up: upstream and OpenShift Pipelines.
+var tektonNamespaces = []string{"tekton-pipelines", "openshift-pipelines"}
+
+// s2iArtifactsPath is where the cache is mounted in s2i builds.
+const s2iArtifactsPath = "/tmp/artifacts"
+
+// GetCachePvcName returns the name of the PVC holding the build caches of the
+// function: its own, or that shared by the functions of the namespace.
+func GetCachePvcName(f fn.Function) string {
+	if f.Build.SharedCache {
+		return sharedCachePvcName
+	}
+	return fmt.Sprintf("%s-build-cache", f.Name)
+}
+
+// getCacheSubPath returns the directory of the cache PVC used by the build.
+// The language caches (Go modules, npm, Maven) are content addressed, so are
+// shared, but the buildpacks cache is kept per function.
+func getCacheSubPath(f fn.Function) string {
+	if f.Build.SharedCache && f.Build.Builder == builders.Pack {
+		return path.Join("cache", "buildpacks", f.Name)
+	}
+	return "cache"
+}
+
+// separateCacheVolume returns true if the build caches may be kept on a PVC
+// other than that of the sources.  Tekton's default affinity assistant, which
+// coschedules the workspaces of a PipelineRun, rejects TaskRuns which mount
+// more than one PVC, so the caches are kept in a directory of the pipeline
+// PVC unless the cluster's feature flags select another mode.  The same is
+// assumed if the flags can not be read.
+func separateCacheVolume(ctx context.Context) bool {
+	for _, ns := range tektonNamespaces {
+		cm, err := getConfigMap(ctx, "feature-flags", ns)
+		if err != nil {
+			continue
+		}
+		if cm.Data["disable-affinity-assistant"] == "true" {
+			return true
+		}
+		coschedule := cm.Data["coschedule"]
+		return coschedule != "" && coschedule != "workspaces"
+	}
+	return false
+}
+
+// cacheWorkspace returns the PVC and its directory mounted as the cache
+// workspace of the build: the cache PVC if separate, otherwise the pipeline
+// PVC.
+func cacheWorkspace(f fn.Function, separate bool) (claimName, subPath string) {
+	if !separate {
+		return getPipelinePvcName(f), sourceCacheSubPath
+	}
+	return GetCachePvcName(f), getCacheSubPath(f)
+}
+
+// S2ICacheEnvs returns the build environment variables which direct the
+// package managers of s2i builds to the cache, mounted at /tmp/artifacts.
+func S2ICacheEnvs(f fn.Function) []string {
+	switch f.Runtime {
+	case "go":
+		return []string{"GOMODCACHE=" + s2iArtifactsPath + "/go-mod"}
+	case "node", "typescript":
+		return []string{"npm_config_cache=" + s2iArtifactsPath + "/npm"}
+	case "quarkus", "springboot":
+		return []string{"MAVEN_LOCAL_REPO=" + s2iArtifactsPath + "/m2"}
+	}
+	return nil
+}
+
+// getCacheAccessMode returns the access mode of the cache PVC: ReadWriteOnce
+// unless set otherwise.  A ReadWriteOnce PVC shared by the functions of the
+// namespace is mounted by the builds of one node at a time, such that builds
+// on other nodes wait for it.
+func getCacheAccessMode(f fn.Function) corev1.PersistentVolumeAccessMode {
+	if f.Build.CacheAccessMode != "" {
+		return corev1.PersistentVolumeAccessMode(f.Build.CacheAccessMode)
+	}
+	return corev1.ReadWriteOnce
+}
+
+// CreateCachePersistentVolumeClaim creates the PVC holding the build caches
+// of the function, if it does not exist yet.  The PVC shared by the functions
+// of the namespace is not labelled as the function's, not to be deleted with
+// it.
+func CreateCachePersistentVolumeClaim(ctx context.Context, f fn.Function, namespace string, labels map[string]string) error {
+	var err error
+	pvcs := DefaultCachePersistentVolumeClaimSize
+	if f.Build.CachePVCSize != "" {
+		if pvcs, err = resource.ParseQuantity(f.Build.CachePVCSize); err != nil {
+			return fmt.Errorf("cache PVC size value could not be parsed. %w", err)
+		}
+	}
+	annotations := f.Deploy.Annotations
+	if f.Build.SharedCache {
+		labels = map[string]string{fnlabels.FunctionKey: fnlabels.FunctionValue}
+		annotations = nil
+	}
+	err = createPersistentVolumeClaim(ctx, GetCachePvcName(f), namespace, labels, annotations, getCacheAccessMode(f), pvcs)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("problem creating cache persistent volume claim: %v", err)
+	}
+	return nil
+}
+
+// ClearCache deletes the PVC holding the build caches of the function, which
+// is created anew by its next remote build, and the caches kept on the
+// pipeline PVC, if any.  A PVC shared by the functions of the namespace is
+// only deleted if requested with fn.ClearSharedCacheKey; otherwise just the
+// function's own caches on it are deleted, if it has any.
+func ClearCache(ctx context.Context, f fn.Function) error {
+	namespace, err := pipelineNamespace(f)
+	if err != nil {
+		return err
+	}
+	shared, _ := ctx.Value(fn.ClearSharedCacheKey{}).(bool)
+	if !f.Build.SharedCache || shared {
+		err = deletePersistentVolumeClaim(ctx, GetCachePvcName(f), namespace)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("problem deleting cache persistent volume claim: %v", err)
+		}
+	} else if subPath := getCacheSubPath(f); subPath != sourceCacheSubPath {
+		// The caches of other builders are common to all the functions.
+		if err = clearVolumePath(ctx, GetCachePvcName(f), namespace, subPath); err != nil {
+			return err
+		}
+	}
+	// The sources are kept, as is the manifest of their last sync.
+	return clearVolumePath(ctx, getPipelinePvcName(f), namespace, sourceCacheSubPath)
+}
+
+// clearVolumePath deletes the directory at subPath of the PVC, keeping its
+// other contents.  Nothing is done if the PVC does not exist.
+func clearVolumePath(ctx context.Context, claimName, namespace, subPath string) error {
+	_, err := getPersistentVolumeClaim(ctx, claimName, namespace)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("problem getting persistent volume claim: %v", err)
+	}
+	clear := func(remote filesync.Manifest) (io.Reader, error) {
+		var empty bytes.Buffer
+		if err := tar.NewWriter(&empty).Close(); err != nil {
+			return nil, err
+		}
+		return filesync.NewStream(filesync.Header{Manifest: remote, Deleted: []string{subPath}}, &empty)
+	}
+	if err = syncToVolume(ctx, clear, claimName, namespace); err != nil {
+		return fmt.Errorf("problem clearing the caches of the persistent volume claim: %v", err)
+	}
+	return nil
+}
+
+// ClearCache of the function's remote builds.
+func (pp *PipelinesProvider) ClearCache(ctx context.Context, f fn.Function) error {
+	return ClearCache(ctx, f)
+}
+
+// allows simple mocking in unit tests
+var (
+	deletePersistentVolumeClaim = k8s.DeletePersistentVolumeClaim
+	getPersistentVolumeClaim    = k8s.GetPersistentVolumeClaim
+	getConfigMap                = k8s.GetConfigMap
+	syncToVolume                = k8s.SyncToVolume
+)
@@ -0,0 +1,277 @@
+//go:build !integration
+// +build !integration
+
+package tekton
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/filesync"
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+func TestGetCachePvcName(t *testing.T) {
+	f := fn.Function{Name: "myfunc", Build: fn.BuildSpec{Builder: builders.Pack}}
+	if name, sub := GetCachePvcName(f), getCacheSubPath(f); name != "myfunc-build-cache" || sub != "cache" {
+		t.Fatalf("unexpected cache of the function: %q %q", name, sub)
+	}
+
+	// the buildpacks cache of a shared cache is kept per function
+	f.Build.SharedCache = true
+	if name, sub := GetCachePvcName(f), getCacheSubPath(f); name != "func-build-cache" || sub != "cache/buildpacks/myfunc" {
+		t.Fatalf("unexpected shared cache: %q %q", name, sub)
+	}
+	f.Build.Builder = builders.S2I
+	if sub := getCacheSubPath(f); sub != "cache" {
+		t.Fatalf("unexpected shared s2i cache: %q", sub)
+	}
+}
+
+func TestGetCacheAccessMode(t *testing.T) {
+	f := fn.Function{Name: "myfunc", Build: fn.BuildSpec{SharedCache: true}}
+	if mode := getCacheAccessMode(f); mode != corev1.ReadWriteOnce {
+		t.Fatalf("expected a ReadWriteOnce cache by default, got %q", mode)
+	}
+	f.Build.CacheAccessMode = fn.CacheAccessModeReadWriteMany
+	if mode := getCacheAccessMode(f); mode != corev1.ReadWriteMany {
+		t.Fatalf("expected a ReadWriteMany cache, got %q", mode)
+	}
+}
+
+func TestGetBuildEnvs(t *testing.T) {
+	tests := []struct {
+		name string
+		f    fn.Function
+		want []string
+	}{
+		{
+			name: "placeholder if none",
+			f:    fn.Function{Runtime: "python", Build: fn.BuildSpec{Builder: builders.Pack}},
+			want: []string{"="},
+		},
+		{
+			name: "npm cache of s2i",
+			f:    fn.Function{Runtime: "node", Build: fn.BuildSpec{Builder: builders.S2I}},
+			want: []string{"npm_config_cache=/tmp/artifacts/npm"},
+		},
+		{
+			name: "Maven repository of s2i after the user's",
+			f: fn.Function{Runtime: "quarkus", Build: fn.BuildSpec{
+				Builder:   builders.S2I,
+				BuildEnvs: []fn.Env{{Name: ptr.To("FOO"), Value: ptr.To("bar")}},
+			}},
+			want: []string{"FOO=bar", "MAVEN_LOCAL_REPO=/tmp/artifacts/m2"},
+		},
+		{
+			name: "no cache variables for the host builder",
+			f:    fn.Function{Runtime: "go", Build: fn.BuildSpec{Builder: builders.Host}},
+			want: []string{"="},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBuildEnvs(tt.f); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCreateCachePersistentVolumeClaim(t *testing.T) {
+	old := createPersistentVolumeClaim
+	defer func() { createPersistentVolumeClaim = old }()
+
+	var (
+		gotName   string
+		gotLabels map[string]string
+		gotSize   resource.Quantity
+	)
+	createPersistentVolumeClaim = func(ctx context.Context, name, namespaceOverride string, labels map[string]string, annotations map[string]string, accessMode corev1.PersistentVolumeAccessMode, resourceRequest resource.Quantity) error {
+		gotName, gotLabels, gotSize = name, labels, resourceRequest
+		return nil
+	}
+
+	labels := map[string]string{fnlabels.FunctionNameKey: "myfunc"}
+	f := fn.Function{Name: "myfunc", Build: fn.BuildSpec{CachePVCSize: "2Gi"}}
+	if err := CreateCachePersistentVolumeClaim(context.Background(), f, "ns", labels); err != nil {
+		t.Fatal(err)
+	}
+	if gotName != "myfunc-build-cache" || gotLabels[fnlabels.FunctionNameKey] != "myfunc" || gotSize.String() != "2Gi" {
+		t.Fatalf("unexpected cache PVC %q %v %v", gotName, gotLabels, gotSize.String())
+	}
+
+	// the shared cache is not deleted with the function
+	f.Build.SharedCache = true
+	f.Build.CachePVCSize = ""
+	if err := CreateCachePersistentVolumeClaim(context.Background(), f, "ns", labels); err != nil {
+		t.Fatal(err)
+	}
+	if gotName != "func-build-cache" || gotLabels[fnlabels.FunctionNameKey] != "" || gotSize.Cmp(DefaultCachePersistentVolumeClaimSize) != 0 {
+		t.Fatalf("unexpected shared cache PVC %q %v %v", gotName, gotLabels, gotSize.String())
+	}
+
+	f.Build.CachePVCSize = "lots"
+	if err := CreateCachePersistentVolumeClaim(context.Background(), f, "ns", labels); err == nil {
+		t.Fatal("expected an error for an invalid size")
+	}
+}
+
+func TestSeparateCacheVolume(t *testing.T) {
+	old := getConfigMap
+	defer func() { getConfigMap = old }()
+
+	tests := []struct {
+		name  string
+		flags map[string]string // nil if the ConfigMap can not be read
+		want  bool
+	}{
+		{name: "flags not readable", flags: nil, want: false},
+		{name: "default coschedule", flags: map[string]string{}, want: false},
+		{name: "coschedule workspaces", flags: map[string]string{"coschedule": "workspaces"}, want: false},
+		{name: "coschedule pipelineruns", flags: map[string]string{"coschedule": "pipelineruns"}, want: true},
+		{name: "coschedule disabled", flags: map[string]string{"coschedule": "disabled"}, want: true},
+		{name: "affinity assistant disabled", flags: map[string]string{"disable-affinity-assistant": "true"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getConfigMap = func(ctx context.Context, name, namespaceOverride string) (*corev1.ConfigMap, error) {
+				if tt.flags == nil || namespaceOverride != "tekton-pipelines" {
+					return nil, apiErrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+				}
+				return &corev1.ConfigMap{Data: tt.flags}, nil
+			}
+			if got := separateCacheVolume(context.Background()); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCacheWorkspace(t *testing.T) {
+	f := fn.Function{Name: "myfunc", Build: fn.BuildSpec{Builder: builders.Pack, SharedCache: true}}
+	if claim, sub := cacheWorkspace(f, true); claim != "func-build-cache" || sub != "cache/buildpacks/myfunc" {
+		t.Fatalf("unexpected separate cache workspace: %q %q", claim, sub)
+	}
+	// a single PVC is mounted by the build if the caches are not separate
+	if claim, sub := cacheWorkspace(f, false); claim != getPipelinePvcName(f) || sub != "cache" {
+		t.Fatalf("unexpected cache workspace on the pipeline PVC: %q %q", claim, sub)
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	oldDelete, oldGet, oldSync := deletePersistentVolumeClaim, getPersistentVolumeClaim, syncToVolume
+	defer func() {
+		deletePersistentVolumeClaim, getPersistentVolumeClaim, syncToVolume = oldDelete, oldGet, oldSync
+	}()
+
+	var deleted, namespace string
+	deletePersistentVolumeClaim = func(ctx context.Context, name, namespaceOverride string) error {
+		deleted, namespace = name, namespaceOverride
+		return apiErrors.NewNotFound(schema.GroupResource{Resource: "persistentvolumeclaims"}, name)
+	}
+	getPersistentVolumeClaim = func(ctx context.Context, name, namespaceOverride string) (*corev1.PersistentVolumeClaim, error) {
+		return &corev1.PersistentVolumeClaim{}, nil
+	}
+	var synced string
+	var header filesync.Header
+	syncToVolume = func(ctx context.Context, changes func(filesync.Manifest) (io.Reader, error), claimName, namespace string) error {
+		synced = claimName
+		r, err := changes(filesync.Manifest{"handle.go": "digest"})
+		if err != nil {
+			return err
+		}
+		return json.NewDecoder(r).Decode(&header)
+	}
+
+	f := fn.Function{Name: "myfunc", Deploy: fn.DeploySpec{Namespace: "ns"}}
+	if err := NewPipelinesProvider().ClearCache(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != "myfunc-build-cache" || namespace != "ns" {
+		t.Fatalf("unexpected PVC deleted: %q in %q", deleted, namespace)
+	}
+	// the caches on the pipeline PVC are deleted, keeping the sources
+	if synced != getPipelinePvcName(f) || len(header.Deleted) != 1 || header.Deleted[0] != "cache" || header.Manifest["handle.go"] != "digest" {
+		t.Fatalf("unexpected clearing of the pipeline PVC %q: %+v", synced, header)
+	}
+
+	// of a shared cache, only the function's own caches are cleared
+	deleted = ""
+	var syncedAll []string
+	var deletedAll []string
+	syncToVolume = func(ctx context.Context, changes func(filesync.Manifest) (io.Reader, error), claimName, namespace string) error {
+		r, err := changes(filesync.Manifest{})
+		if err != nil {
+			return err
+		}
+		var h filesync.Header
+		if err := json.NewDecoder(r).Decode(&h); err != nil {
+			return err
+		}
+		syncedAll = append(syncedAll, claimName)
+		deletedAll = append(deletedAll, h.Deleted...)
+		return nil
+	}
+	shared := f
+	shared.Build = fn.BuildSpec{Builder: builders.Pack, SharedCache: true}
+	if err := ClearCache(context.Background(), shared); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != "" {
+		t.Fatalf("unexpected deletion of the shared PVC %q", deleted)
+	}
+	if !slices.Equal(syncedAll, []string{"func-build-cache", getPipelinePvcName(shared)}) ||
+		!slices.Equal(deletedAll, []string{"cache/buildpacks/myfunc", "cache"}) {
+		t.Fatalf("unexpected clearing of PVCs %v: %v", syncedAll, deletedAll)
+	}
+
+	// the caches of other builders are common to the functions sharing them
+	syncedAll, deletedAll = nil, nil
+	shared.Build.Builder = builders.S2I
+	if err := ClearCache(context.Background(), shared); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != "" || !slices.Equal(syncedAll, []string{getPipelinePvcName(shared)}) {
+		t.Fatalf("unexpected clearing of PVCs %v, deleted %q", syncedAll, deleted)
+	}
+
+	// the shared PVC is deleted as a whole if requested
+	syncedAll = nil
+	ctx := context.WithValue(context.Background(), fn.ClearSharedCacheKey{}, true)
+	if err := ClearCache(ctx, shared); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != "func-build-cache" || !slices.Equal(syncedAll, []string{getPipelinePvcName(shared)}) {
+		t.Fatalf("unexpected clearing of PVCs %v, deleted %q", syncedAll, deleted)
+	}
+
+	// nothing is cleared if there are no PVCs
+	syncedAll = nil
+	getPersistentVolumeClaim = func(ctx context.Context, name, namespaceOverride string) (*corev1.PersistentVolumeClaim, error) {
+		return nil, apiErrors.NewNotFound(schema.GroupResource{Resource: "persistentvolumeclaims"}, name)
+	}
+	shared.Build.Builder = builders.Pack
+	if err := ClearCache(context.Background(), shared); err != nil {
+		t.Fatal(err)
+	}
+	if len(syncedAll) != 0 {
+		t.Fatalf("unexpected clearing of PVCs %v", syncedAll)
+	}
+
+	if err := ClearCache(context.Background(), fn.Function{Name: "myfunc"}); err == nil {
+		t.Fatal("expected an error without a namespace")
+	}
+}
@@ -0,0 +1,359 @@
+package tekton
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// CI providers for which workflows are generated (see GenerateCI).
+const (
+	CIProviderGitHub = "github"
+	CIProviderGitLab = "gitlab"
+	CIProviderTekton = "tekton"
+)
+
+// CIProviders returns the names of the supported CI providers.
+func CIProviders() []string {
+	return []string{CIProviderGitHub, CIProviderGitLab, CIProviderTekton}
+}
+
+// FuncBinaryURL from which the generated CI workflows install func.
+var FuncBinaryURL = "https://github.com/knative/func/releases/latest/download/func_linux_amd64"
+
+// ErrCIFileExists is returned when generating a CI workflow which exists
+// already, unless overwriting is requested.
+type ErrCIFileExists struct {
+	Path string
+}
+
+func (e ErrCIFileExists) Error() string {
+	return fmt.Sprintf("the CI workflow %q exists already", e.Path)
+}
+
+// ErrCIIncludeRequired is returned when a GitLab CI job was written, but could
+// not be included from the project's existing pipeline, the form of whose
+// include is not understood.  The job is to be included by the user.
+type ErrCIIncludeRequired struct {
+	// Pipeline is the path of the project's pipeline, .gitlab-ci.yml.
+	Pipeline string
+	// Job is the path of the job to include.
+	Job string
+}
+
+func (e ErrCIIncludeRequired) Error() string {
+	return fmt.Sprintf("the CI job %q could not be included from %q, add '- local: %v' to its include", e.Job, e.Pipeline, e.Job)
+}
+
+// GenerateCI writes the workflow of the given CI provider which builds, pushes
+// and deploys the function, into the Git repository containing it.  Returned
+// are the paths of the files written, relative to the repository.
+// For Tekton, these are the Pipelines as Code resources in the .tekton
+// directory of the function, as written by 'func config git set'.
+func GenerateCI(f fn.Function, provider string, overwrite bool) ([]string, error) {
+	switch f.Build.Builder {
+	case builders.Pack, builders.S2I, builders.Host:
+	default:
+		return nil, builders.ErrBuilderNotSupported{Builder: f.Build.Builder}
+	}
+
+	repoRoot, contextDir := ciRepository(f)
+	data := templateData{
+		FunctionName:  f.Name,
+		ContextDir:    contextDir,
+		FunctionImage: f.Image,
+		Registry:      f.Registry,
+		Builder:       f.Build.Builder,
+		Runtime:       f.Runtime,
+
+		PipelinesTargetBranch: f.Build.Git.Revision,
+
+		FuncBinaryURL: FuncBinaryURL,
+		CIImage:       ciImage(f),
+	}
+	if data.PipelinesTargetBranch == "" {
+		data.PipelinesTargetBranch = defaultPipelinesTargetBranch
+	}
+	data.DeployArgs = ciDeployArgs(data)
+
+	switch provider {
+	case CIProviderGitHub:
+		p := filepath.Join(".github", "workflows", f.Name+".yaml")
+		if err := writeCIFile(repoRoot, p, githubWorkflowTemplate, data, overwrite); err != nil {
+			return nil, err
+		}
+		return []string{p}, nil
+	case CIProviderGitLab:
+		p := filepath.Join(".gitlab", "ci", f.Name+".yml")
+		if err := writeCIFile(repoRoot, p, gitlabJobTemplate, data, overwrite); err != nil {
+			return nil, err
+		}
+		paths := []string{p}
+		// The job is included from the project's pipeline, such that the
+		// jobs of several functions coexist.
+		written, err := includeGitLabJob(filepath.Join(repoRoot, gitlabPipeline), filepath.ToSlash(p))
+		if err != nil {
+			return paths, err
+		}
+		if written {
+			paths = append(paths, gitlabPipeline)
+		}
+		return paths, nil
+	case CIProviderTekton:
+		paths := []string{
+			filepath.Join(resourcesDirectory, pipelineFileNamePAC),
+			filepath.Join(resourcesDirectory, pipelineRunFilenamePAC),
+		}
+		for _, p := range paths {
+			if _, err := os.Stat(filepath.Join(f.Root, p)); err == nil {
+				if !overwrite {
+					return nil, ErrCIFileExists{Path: p}
+				}
+				// removed, not to be prompted for
+				if err = os.Remove(filepath.Join(f.Root, p)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		labels, err := f.LabelsMap()
+		if err != nil {
+			return nil, err
+		}
+		if err = createPipelineTemplatePAC(f, labels); err != nil {
+			return nil, err
+		}
+		if err = createPipelineRunTemplatePAC(f, labels); err != nil {
+			return nil, err
+		}
+		return paths, nil
+	}
+	return nil, fmt.Errorf("unsupported CI provider %q, supported providers are %s", provider, strings.Join(CIProviders(), ", "))
+}
+
+// gitlabPipeline is the path of a GitLab project's pipeline.
+const gitlabPipeline = ".gitlab-ci.yml"
+
+// includeGitLabJob includes the job at the path p, relative to the
+// repository, from the project's pipeline at main.  The pipeline is created
+// if it does not exist.  Otherwise the job is added to its top-level include,
+// or an include is appended if there is none.  Returned is whether the
+// pipeline was written: it is not if the job is included already.  An
+// ErrCIIncludeRequired is returned if the include is of another form than a
+// block sequence, which is left to the user.
+func includeGitLabJob(main, p string) (bool, error) {
+	entry := "- local: " + p
+	bb, err := os.ReadFile(main)
+	if errors.Is(err, os.ErrNotExist) {
+		bb = nil
+	} else if err != nil {
+		return false, fmt.Errorf("cannot read the GitLab CI pipeline: %w", err)
+	}
+	src := string(bb)
+	lines := strings.Split(src, "\n")
+	for _, l := range lines {
+		if strings.Contains(l, "local: "+p) || strings.Contains(l, "local: '"+p+"'") || strings.Contains(l, `local: "`+p+`"`) {
+			return false, nil
+		}
+	}
+
+	switch i := slices.IndexFunc(lines, func(l string) bool { return strings.HasPrefix(l, "include:") }); {
+	case i < 0:
+		if src != "" && !strings.HasSuffix(src, "\n") {
+			src += "\n"
+		}
+		if src != "" {
+			src += "\n"
+		}
+		src += "include:\n  " + entry + "\n"
+	case strings.TrimSpace(strings.TrimPrefix(lines[i], "include:")) == "" &&
+		i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "- "):
+		// a block sequence, to which the job is prepended with the
+		// indentation of its first item
+		indent := lines[i+1][:len(lines[i+1])-len(strings.TrimLeft(lines[i+1], " "))]
+		lines = slices.Insert(lines, i+1, indent+entry)
+		src = strings.Join(lines, "\n")
+	default:
+		return false, ErrCIIncludeRequired{Pipeline: gitlabPipeline, Job: p}
+	}
+	if err = os.WriteFile(main, []byte(src), 0644); err != nil {
+		return false, fmt.Errorf("cannot write the GitLab CI pipeline: %w", err)
+	}
+	return true, nil
+}
+
+// ciRepository returns the root of the Git repository containing the
+// function, and the directory of the function within it: that configured as
+// the context directory of its Git builds, or else derived from the location
+// of the repository.  A function outside of a repository is its root.
+func ciRepository(f fn.Function) (root, contextDir string) {
+	root, contextDir = f.Root, f.Build.Git.ContextDir
+	for dir := f.Root; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			root = dir
+			if rel, err := filepath.Rel(dir, f.Root); err == nil && contextDir == "" {
+				contextDir = filepath.ToSlash(rel)
+			}
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	if contextDir == "" {
+		contextDir = "."
+	}
+	return
+}
+
+// ciImage returns the image in which the GitLab job runs: one with the
+// toolchain of the function for the host builder, which needs no container
+// engine, else Docker.
+func ciImage(f fn.Function) string {
+	if f.Build.Builder != builders.Host {
+		return ""
+	}
+	if f.Runtime == "go" {
+		return hostBuildGoImage
+	}
+	return "docker.io/library/python:3.12"
+}
+
+// ciDeployArgs returns the arguments of the deploy command of CI workflows.
+func ciDeployArgs(data templateData) string {
+	args := []string{"--build=true", "--push=true", "--builder=" + data.Builder}
+	if data.FunctionImage != "" {
+		args = append(args, "--image="+data.FunctionImage)
+	} else if data.Registry != "" {
+		args = append(args, "--registry="+data.Registry)
+	}
+	return strings.Join(args, " ")
+}
+
+// writeCIFile renders the template to the path relative to the repository.
+// The templates are delimited by [[ ]], as the CI providers' own expressions
+// use braces.
+func writeCIFile(repoRoot, p, fileTemplate string, data templateData, overwrite bool) error {
+	tmpl, err := template.New(p).Delims("[[", "]]").Parse(fileTemplate)
+	if err != nil {
+		return fmt.Errorf("error parsing CI template: %v", err)
+	}
+
+	filePath := filepath.Join(repoRoot, p)
+	if _, err = os.Stat(filePath); err == nil && !overwrite {
+		return ErrCIFileExists{Path: p}
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return fmt.Errorf("error creating CI workflow path: %v", err)
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating CI workflow: %v", err)
+	}
+	defer file.Close()
+
+	return tmpl.Execute(file, data)
+}
+
+const (
+	// githubWorkflowTemplate of a GitHub Actions workflow
+	githubWorkflowTemplate = `# Builds, pushes and deploys the function [[.FunctionName]] on each push to the
+# [[.PipelinesTargetBranch]] branch.  Generated by "func ci generate".
+#
+# Secrets of the repository used:
+#   KUBECONFIG          the kubeconfig of the cluster to deploy to
+#   REGISTRY_USERNAME   the user of the container registry
+#   REGISTRY_PASSWORD   the password or token of the container registry
+name: Deploy [[.FunctionName]]
+
+on:
+  push:
+    branches:
+      - [[.PipelinesTargetBranch]]
+[[- if ne .ContextDir "."]]
+    paths:
+      - "[[.ContextDir]]/**"
+[[- end]]
+  workflow_dispatch: {}
+
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Checkout
+        uses: actions/checkout@v4
+[[- if eq .Builder "host"]]
+      - name: Set up the toolchain
+[[- if eq .Runtime "go"]]
+        uses: actions/setup-go@v5
+        with:
+          go-version: stable
+[[- else]]
+        uses: actions/setup-python@v5
+        with:
+          python-version: "3.12"
+[[- end]]
+[[- end]]
+      - name: Install func
+        run: |
+          curl -sSLf -o "$RUNNER_TEMP/func" [[.FuncBinaryURL]]
+          chmod +x "$RUNNER_TEMP/func"
+          echo "$RUNNER_TEMP" >> "$GITHUB_PATH"
+      - name: Configure the cluster credentials
+        run: |
+          mkdir -p ~/.kube
+          echo "$KUBECONFIG_DATA" > ~/.kube/config
+        env:
+          KUBECONFIG_DATA: ${{ secrets.KUBECONFIG }}
+      - name: Build, push and deploy
+        working-directory: [[.ContextDir]]
+        run: func deploy [[.DeployArgs]]
+        env:
+          FUNC_USERNAME: ${{ secrets.REGISTRY_USERNAME }}
+          FUNC_PASSWORD: ${{ secrets.REGISTRY_PASSWORD }}
+[[- if eq .Builder "host"]]
+          FUNC_ENABLE_HOST_BUILDER: "true"
+[[- end]]
+`
+
+	// gitlabJobTemplate of a GitLab CI job
+	gitlabJobTemplate = `# Builds, pushes and deploys the function [[.FunctionName]] on each push to the
+# [[.PipelinesTargetBranch]] branch.  Generated by "func ci generate".
+#
+# CI/CD variables of the project used:
+#   KUBECONFIG          (of type File) the kubeconfig of the cluster to deploy to
+#   REGISTRY_USERNAME   the user of the container registry
+#   REGISTRY_PASSWORD   the password or token of the container registry
+deploy-[[.FunctionName]]:
+[[- if eq .Builder "host"]]
+  image: [[.CIImage]]
+  variables:
+    FUNC_ENABLE_HOST_BUILDER: "true"
+[[- else]]
+  image: docker.io/library/docker:27
+  services:
+    - docker.io/library/docker:27-dind
+  variables:
+    DOCKER_HOST: tcp://docker:2375
+    DOCKER_TLS_CERTDIR: ""
+[[- end]]
+  rules:
+    - if: $CI_COMMIT_BRANCH == "[[.PipelinesTargetBranch]]"
+[[- if ne .ContextDir "."]]
+      changes:
+        - [[.ContextDir]]/**/*
+[[- end]]
+  script:
+    - wget -qO /usr/local/bin/func [[.FuncBinaryURL]]
+    - chmod +x /usr/local/bin/func
+    - cd [[.ContextDir]]
+    - FUNC_USERNAME="$REGISTRY_USERNAME" FUNC_PASSWORD="$REGISTRY_PASSWORD" func deploy [[.DeployArgs]]
+`
+)
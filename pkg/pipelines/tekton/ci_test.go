@@ -0,0 +1,196 @@
+//go:build !integration
+// +build !integration
+
+package tekton
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// newCIRepository returns a function located in the fn directory of a Git
+// repository.
+func newCIRepository(t *testing.T, builder string) fn.Function {
+	t.Helper()
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(repo, "fn")
+	if err := os.Mkdir(root, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	return fn.Function{
+		Name:     "myfunc",
+		Root:     root,
+		Runtime:  "go",
+		Registry: "example.com/alice",
+		Build:    fn.BuildSpec{Builder: builder},
+	}
+}
+
+func readCIFile(t *testing.T, f fn.Function, p string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(f.Root), p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestGenerateCI_GitHub(t *testing.T) {
+	f := newCIRepository(t, builders.Host)
+	paths, err := GenerateCI(f, CIProviderGitHub, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != filepath.Join(".github", "workflows", "myfunc.yaml") {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+	workflow := readCIFile(t, f, paths[0])
+	for _, s := range []string{
+		`- "fn/**"`,
+		"- main",
+		"working-directory: fn",
+		"func deploy --build=true --push=true --builder=host --registry=example.com/alice",
+		"actions/setup-go",
+		`FUNC_ENABLE_HOST_BUILDER: "true"`,
+		"${{ secrets.KUBECONFIG }}",
+		"${{ secrets.REGISTRY_PASSWORD }}",
+	} {
+		if !strings.Contains(workflow, s) {
+			t.Errorf("expected %q in the workflow:\n%s", s, workflow)
+		}
+	}
+
+	// an existing workflow is kept unless overwriting
+	if _, err = GenerateCI(f, CIProviderGitHub, false); !errors.As(err, &ErrCIFileExists{}) {
+		t.Fatalf("expected ErrCIFileExists, got %v", err)
+	}
+	f.Build.Builder = builders.Pack
+	f.Build.Git.Revision = "release"
+	if _, err = GenerateCI(f, CIProviderGitHub, true); err != nil {
+		t.Fatal(err)
+	}
+	workflow = readCIFile(t, f, paths[0])
+	if !strings.Contains(workflow, "--builder=pack") || !strings.Contains(workflow, "- release") || strings.Contains(workflow, "setup-go") {
+		t.Fatalf("expected the workflow to be overwritten:\n%s", workflow)
+	}
+}
+
+func TestGenerateCI_GitLab(t *testing.T) {
+	f := newCIRepository(t, builders.S2I)
+	f.Image = "example.com/alice/myfunc:latest"
+	paths, err := GenerateCI(f, CIProviderGitLab, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[1] != ".gitlab-ci.yml" {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+	job := readCIFile(t, f, paths[0])
+	for _, s := range []string{
+		"deploy-myfunc:",
+		"docker:27-dind",
+		"- fn/**/*",
+		"- cd fn",
+		"func deploy --build=true --push=true --builder=s2i --image=example.com/alice/myfunc:latest",
+	} {
+		if !strings.Contains(job, s) {
+			t.Errorf("expected %q in the job:\n%s", s, job)
+		}
+	}
+	if main := readCIFile(t, f, ".gitlab-ci.yml"); !strings.Contains(main, "- local: .gitlab/ci/myfunc.yml") {
+		t.Fatalf("expected the job to be included:\n%s", main)
+	}
+
+	// the jobs of other functions are added to the include of the pipeline
+	g := f
+	g.Name = "otherfunc"
+	if paths, err = GenerateCI(g, CIProviderGitLab, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("expected the project's pipeline to be written, got %v", paths)
+	}
+	if main := readCIFile(t, f, ".gitlab-ci.yml"); main != "include:\n  - local: .gitlab/ci/otherfunc.yml\n  - local: .gitlab/ci/myfunc.yml\n" {
+		t.Fatalf("expected both jobs to be included:\n%s", main)
+	}
+
+	// but only once
+	if paths, err = GenerateCI(g, CIProviderGitLab, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("expected the project's pipeline not to be written again, got %v", paths)
+	}
+}
+
+// Test_includeGitLabJob ensures the job is included from existing pipelines
+// of various forms, or that the user is asked to include it.
+func Test_includeGitLabJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline string
+		expected string
+		err      bool
+	}{
+		{"without include", "stages:\n  - test\n", "stages:\n  - test\n\ninclude:\n  - local: .gitlab/ci/f.yml\n", false},
+		{"block sequence", "include:\n- local: a.yml\nstages: [test]\n", "include:\n- local: .gitlab/ci/f.yml\n- local: a.yml\nstages: [test]\n", false},
+		{"scalar", "include: a.yml\n", "include: a.yml\n", true},
+		{"flow sequence", "include: [a.yml]\n", "include: [a.yml]\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			main := filepath.Join(t.TempDir(), ".gitlab-ci.yml")
+			if err := os.WriteFile(main, []byte(tt.pipeline), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := includeGitLabJob(main, ".gitlab/ci/f.yml")
+			if tt.err != errors.As(err, &ErrCIIncludeRequired{}) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			bb, err := os.ReadFile(main)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(bb) != tt.expected {
+				t.Fatalf("expected pipeline:\n%s\ngot:\n%s", tt.expected, bb)
+			}
+		})
+	}
+}
+
+func TestGenerateCI_Unsupported(t *testing.T) {
+	f := newCIRepository(t, builders.Pack)
+	if _, err := GenerateCI(f, "jenkins", false); err == nil {
+		t.Fatal("expected an error for an unsupported provider")
+	}
+	f.Build.Builder = "kaniko"
+	if _, err := GenerateCI(f, CIProviderGitHub, false); !errors.As(err, &builders.ErrBuilderNotSupported{}) {
+		t.Fatalf("expected ErrBuilderNotSupported, got %v", err)
+	}
+}
+
+func TestCIRepository(t *testing.T) {
+	f := newCIRepository(t, builders.Pack)
+	if root, contextDir := ciRepository(f); root != filepath.Dir(f.Root) || contextDir != "fn" {
+		t.Fatalf("unexpected repository %q %q", root, contextDir)
+	}
+	f.Build.Git.ContextDir = "functions/fn"
+	if _, contextDir := ciRepository(f); contextDir != "functions/fn" {
+		t.Fatalf("expected the configured context directory, got %q", contextDir)
+	}
+
+	// outside of a repository, the function is its root
+	f = fn.Function{Root: t.TempDir()}
+	if root, contextDir := ciRepository(f); root != f.Root || contextDir != "." {
+		t.Fatalf("unexpected repository %q %q", root, contextDir)
+	}
+}
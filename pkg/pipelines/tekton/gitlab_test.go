@@ -583,7 +583,7 @@ func generateSSHKeys(t *testing.T) string {
 func usingNamespace(t *testing.T) string {
 
 	name := "gitlab-test-" + strings.ToLower(random.AlphaString(5))
-	k8sClient, err := k8s.NewKubernetesClientset()
+	k8sClient, err := k8s.NewKubernetesClientset(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -607,7 +607,7 @@ func usingNamespace(t *testing.T) string {
 
 func awaitBuildCompletion(t *testing.T, name, ns string) <-chan struct{} {
 
-	clis, err := tekton.NewTektonClients()
+	clis, err := tekton.NewTektonClients(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
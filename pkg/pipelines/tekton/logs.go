@@ -0,0 +1,206 @@
+package tekton
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/cli/pkg/cli"
+	"github.com/tektoncd/cli/pkg/pipelinerun"
+	"github.com/tektoncd/cli/pkg/taskrun"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// logsPollInterval is the interval at which TaskRuns and their Pods are
+// polled while waiting for a step to start.
+var logsPollInterval = time.Second
+
+// Logs writes the logs of the named PipelineRun of the function, or of its
+// newest PipelineRun if none is named, to w.  The logs of a PipelineRun which
+// has not yet completed are followed until it does.
+func (pp *PipelinesProvider) Logs(ctx context.Context, f fn.Function, run string, w io.Writer) error {
+	namespace, err := pipelineNamespace(f)
+	if err != nil {
+		return err
+	}
+
+	if run == "" {
+		client, err := NewTektonClient(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		pr, err := findNewestPipelineRunWithRetry(ctx, f, namespace, client)
+		if err != nil {
+			return err
+		}
+		run = pr.Name
+	}
+
+	clients, err := NewTektonClients(ctx)
+	if err != nil {
+		return err
+	}
+	return streamPipelineRunLogs(ctx, clients, run, namespace, w)
+}
+
+// streamPipelineRunLogs writes the logs of each step of the PipelineRun to w
+// as they are produced, each line prefixed by the names of its task and step.
+// The logs of a completed PipelineRun are written task by task.
+func streamPipelineRunLogs(ctx context.Context, clients *cli.Clients, name, namespace string, w io.Writer) error {
+	pr, err := clients.Tekton.TektonV1().PipelineRuns(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("problem in retrieving pipeline run %q: %w", name, err)
+	}
+	lw := &logWriter{w: w}
+
+	if !pr.IsDone() {
+		return followPipelineRunLogs(ctx, clients, pr, namespace, lw)
+	}
+	for _, ref := range pr.Status.ChildReferences {
+		if ref.Kind != "TaskRun" {
+			continue
+		}
+		if err = streamTaskRunLogs(ctx, clients, ref.Name, ref.PipelineTaskName, namespace, lw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followPipelineRunLogs streams the logs of each TaskRun of the running
+// PipelineRun as it starts, until the PipelineRun completes.
+func followPipelineRunLogs(ctx context.Context, clients *cli.Clients, pr *v1.PipelineRun, namespace string, lw *logWriter) (err error) {
+	trChannel := pipelinerun.NewTracker(pr.Name, namespace, clients).Monitor([]string{})
+	wg := sync.WaitGroup{}
+out:
+	for {
+		var trs []taskrun.Run
+		var ok bool
+
+		select {
+		case trs, ok = <-trChannel:
+			if !ok {
+				break out
+			}
+		case <-ctx.Done():
+			err = ctx.Err()
+			break out
+		}
+
+		wg.Add(len(trs))
+		for _, run := range trs {
+			go func(tr taskrun.Run) {
+				defer wg.Done()
+				if err := streamTaskRunLogs(ctx, clients, tr.Name, tr.Task, namespace, lw); err != nil && ctx.Err() == nil {
+					lw.printf("[%s] unable to stream logs: %v\n", tr.Task, err)
+				}
+			}(run)
+		}
+	}
+	wg.Wait()
+	return
+}
+
+// streamTaskRunLogs writes the logs of each step of the TaskRun in turn,
+// waiting for each to start.
+func streamTaskRunLogs(ctx context.Context, clients *cli.Clients, name, task, namespace string, lw *logWriter) error {
+	var podName string
+	err := poll(ctx, func() (bool, error) {
+		tr, err := clients.Tekton.TektonV1().TaskRuns(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		podName = tr.Status.PodName
+		return podName != "" || tr.IsDone(), nil
+	})
+	if err != nil || podName == "" {
+		return err // the TaskRun completed without a Pod, such as when skipped
+	}
+
+	pod, err := clients.Kube.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, c := range pod.Spec.Containers {
+		if !strings.HasPrefix(c.Name, "step-") {
+			continue
+		}
+		if err = waitForContainer(ctx, clients, podName, c.Name, namespace); err != nil {
+			return err
+		}
+		stream, err := clients.Kube.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{Container: c.Name, Follow: true}).Stream(ctx)
+		if err != nil {
+			return err
+		}
+		err = lw.copy(task, strings.TrimPrefix(c.Name, "step-"), stream)
+		stream.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForContainer of the Pod to have started, or the Pod to have completed.
+func waitForContainer(ctx context.Context, clients *cli.Clients, podName, container, namespace string) error {
+	return poll(ctx, func() (bool, error) {
+		pod, err := clients.Kube.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return true, nil
+		}
+		for _, s := range pod.Status.ContainerStatuses {
+			if s.Name == container {
+				return s.State.Running != nil || s.State.Terminated != nil, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// poll the condition until it is met, errors or the context is done.
+func poll(ctx context.Context, condition func() (bool, error)) error {
+	for {
+		done, err := condition()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(logsPollInterval):
+		}
+	}
+}
+
+// logWriter writes the lines of logs prefixed by their task and step,
+// serializing the writes of concurrently streamed logs.
+type logWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *logWriter) copy(task, step string, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lw.printf("[%s : %s] %s\n", task, step, scanner.Text())
+	}
+	return scanner.Err()
+}
+
+func (lw *logWriter) printf(format string, a ...any) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	fmt.Fprintf(lw.w, format, a...)
+}
@@ -0,0 +1,80 @@
+//go:build !integration
+// +build !integration
+
+package tekton
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/tektoncd/cli/pkg/cli"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// TestStreamPipelineRunLogs ensures the logs of each step of each task of a
+// completed PipelineRun are written in order, prefixed by task and step.
+func TestStreamPipelineRunLogs(t *testing.T) {
+	pr := &v1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "f-run", Namespace: "ns"},
+		Status: v1.PipelineRunStatus{
+			Status: duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}}},
+			PipelineRunStatusFields: v1.PipelineRunStatusFields{ChildReferences: []v1.ChildStatusReference{
+				{TypeMeta: taskRunType, Name: "f-run-build", PipelineTaskName: "build"},
+				{TypeMeta: taskRunType, Name: "f-run-deploy", PipelineTaskName: "deploy"},
+				{TypeMeta: taskRunType, Name: "f-run-skipped", PipelineTaskName: "skipped"},
+			}},
+		},
+	}
+	taskRun := func(name, pod string) *v1.TaskRun {
+		return &v1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Status: v1.TaskRunStatus{
+				Status:              duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}}},
+				TaskRunStatusFields: v1.TaskRunStatusFields{PodName: pod},
+			},
+		}
+	}
+	pod := func(name string, containers ...string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+		for _, c := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+		}
+		return p
+	}
+
+	clients := &cli.Clients{
+		Tekton: tektonfake.NewSimpleClientset(pr,
+			taskRun("f-run-build", "f-run-build-pod"),
+			taskRun("f-run-deploy", "f-run-deploy-pod"),
+			taskRun("f-run-skipped", "")),
+		Kube: fake.NewSimpleClientset(
+			pod("f-run-build-pod", "prepare", "step-prepare", "step-build"),
+			pod("f-run-deploy-pod", "step-func-deploy")),
+	}
+
+	var out bytes.Buffer
+	if err := streamPipelineRunLogs(context.Background(), clients, "f-run", "ns", &out); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fake clientset returns "fake logs" as the logs of every container
+	expected := "[build : prepare] fake logs\n" +
+		"[build : build] fake logs\n" +
+		"[deploy : func-deploy] fake logs\n"
+	if out.String() != expected {
+		t.Fatalf("expected logs:\n%v\ngot:\n%v", expected, out.String())
+	}
+}
+
+var taskRunType = runtime.TypeMeta{APIVersion: "tekton.dev/v1", Kind: "TaskRun"}
@@ -1,6 +1,7 @@
 package pac
 
 import (
+	"context"
 	"fmt"
 
 	pacv1alpha1 "github.com/openshift-pipelines/pipelines-as-code/pkg/generated/clientset/versioned/typed/pipelinesascode/v1alpha1"
@@ -9,16 +10,16 @@ import (
 )
 
 // NewTektonPacClientAndResolvedNamespace returns PipelinesascodeV1alpha1Client,namespace,error
-func NewTektonPacClientAndResolvedNamespace(namespace string) (*pacv1alpha1.PipelinesascodeV1alpha1Client, string, error) {
+func NewTektonPacClientAndResolvedNamespace(ctx context.Context, namespace string) (*pacv1alpha1.PipelinesascodeV1alpha1Client, string, error) {
 	var err error
 	if namespace == "" {
-		namespace, err = k8s.GetDefaultNamespace()
+		namespace, err = k8s.GetDefaultNamespace(ctx)
 		if err != nil {
 			return nil, "", err
 		}
 	}
 
-	restConfig, err := k8s.GetClientConfig().ClientConfig()
+	restConfig, err := k8s.GetClientConfig(ctx).ClientConfig()
 	if err != nil {
 		return nil, namespace, fmt.Errorf("failed to create new tekton pac client: %w", err)
 	}
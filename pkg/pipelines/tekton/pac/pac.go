@@ -26,12 +26,12 @@ const (
 func DetectPACInstallation(ctx context.Context) (bool, string, error) {
 	var installed bool
 
-	clientPac, cns, err := NewTektonPacClientAndResolvedNamespace("")
+	clientPac, cns, err := NewTektonPacClientAndResolvedNamespace(ctx, "")
 	if err != nil {
 		return false, "", err
 	}
 
-	clientK8s, _, err := k8s.NewClientAndResolvedNamespace("")
+	clientK8s, _, err := k8s.NewClientAndResolvedNamespace(ctx, "")
 	if err != nil {
 		return false, "", err
 	}
@@ -73,7 +73,7 @@ func DetectPACOpenShiftRoute(ctx context.Context, targetNamespace string) (strin
 		Group: openShiftRouteGroup, Version: openShiftRouteVersion, Resource: openShiftRouteResource,
 	}
 
-	client, err := k8s.NewDynamicClient()
+	client, err := k8s.NewDynamicClient(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -106,7 +106,7 @@ func DetectPACOpenShiftRoute(ctx context.Context, targetNamespace string) (strin
 // GetPACInfo returns the controller url that PAC controller is running
 // Taken and slightly modified from https://github.com/openshift-pipelines/pipelines-as-code/blob/0d63e6239f4a7f1fc90decde1e0a154ed56ed0e7/pkg/cli/info/configmap.go
 func GetPACInfo(ctx context.Context, namespace string) (string, error) {
-	client, namespace, err := k8s.NewClientAndResolvedNamespace(namespace)
+	client, namespace, err := k8s.NewClientAndResolvedNamespace(ctx, namespace)
 	if err != nil {
 		return "", err
 	}
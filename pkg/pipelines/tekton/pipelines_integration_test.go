@@ -146,7 +146,7 @@ func TestRemote_Default(t *testing.T) {
 
 func setupNS(t *testing.T) string {
 	name := "pipeline-integration-test-" + strings.ToLower(random.AlphaString(5))
-	cliSet, err := k8s.NewKubernetesClientset()
+	cliSet, err := k8s.NewKubernetesClientset(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
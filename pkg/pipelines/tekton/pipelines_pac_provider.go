@@ -3,7 +3,6 @@ package tekton
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -42,13 +41,20 @@ func (pp *PipelinesProvider) ConfigurePAC(ctx context.Context, f fn.Function, me
 		return err
 	}
 
+	remoteOrCluster := data.ConfigureClusterResources || data.ConfigureRemoteResources
+	if remoteOrCluster && data.GitProvider == "" && f.Build.Git.URL != "" {
+		if data.GitProvider, err = git.GitProviderName(f.Build.Git.URL); err != nil {
+			return err
+		}
+	}
+
 	if data.ConfigureLocalResources {
 		if err := pp.createLocalPACResources(ctx, f); err != nil {
 			return err
 		}
 	}
 
-	if data.ConfigureClusterResources || data.ConfigureRemoteResources {
+	if remoteOrCluster {
 		if data.WebhookSecret == "" {
 			data.WebhookSecret = random.AlphaString(10)
 
@@ -83,7 +89,8 @@ func (pp *PipelinesProvider) ConfigurePAC(ctx context.Context, f fn.Function, me
 }
 
 // RemovePAC tries to remove all local and remote resources that were created for PAC.
-// Resources on the remote GitHub repo are not removed, we would need to store webhook id somewhere locally.
+// The webhook on the remote Git repository is removed if its ID was retained
+// in the function's local metadata when it was created.
 func (pp *PipelinesProvider) RemovePAC(ctx context.Context, f fn.Function, metadata any) error {
 	data, ok := metadata.(pipelines.PacMetadata)
 	if !ok {
@@ -92,6 +99,13 @@ func (pp *PipelinesProvider) RemovePAC(ctx context.Context, f fn.Function, metad
 
 	compoundErrMsg := ""
 
+	// before the cluster resources, which may hold the access token
+	if data.ConfigureRemoteResources && f.Local.Webhook != nil {
+		if err := pp.removeRemotePACResources(ctx, f, data); err != nil {
+			compoundErrMsg += err.Error()
+		}
+	}
+
 	if data.ConfigureLocalResources {
 		errMsg := deleteAllPipelineTemplates(f)
 		compoundErrMsg += errMsg
@@ -209,6 +223,18 @@ func (pp *PipelinesProvider) createClusterPACResources(ctx context.Context, f fn
 	}
 	fmt.Printf(" ✅ Webhook with payload validation secret %q is present on the cluster in repository %q\n", metadata.WebhookSecret, getPipelineRepositoryName(f))
 
+	// The pipelines triggered by Pipelines as Code can not decrypt the
+	// function's secrets, so its resources are applied now.
+	err = k8s.EnsureFunctionResources(ctx, f, namespace, pp.secretsKeyFile)
+	if err != nil {
+		return fmt.Errorf("problem in creating the function's resources: %v", err)
+	}
+	secrets, _ := f.Resources(fn.SecretResource)
+	configMaps, _ := f.Resources(fn.ConfigMapResource)
+	if len(secrets)+len(configMaps) > 0 {
+		fmt.Printf(" ✅ Secrets and ConfigMaps of the function are present on the cluster\n")
+	}
+
 	return nil
 }
 
@@ -248,15 +274,53 @@ func (pp *PipelinesProvider) createRemotePACResources(ctx context.Context, f fn.
 		}
 	}
 
-	if err := git.CreateWebHook(ctx, f.Build.Git.URL, controllerURL, metadata.WebhookSecret, metadata.PersonalAccessToken); err != nil {
-		// Error: POST https://api.github.com/repos/foobar/test-function/hooks: 422 Validation Failed [{Resource:Hook Field: Code:custom Message:Hook already exists on this repository}]
-		if !strings.Contains(err.Error(), "Hook already exists") {
-			return err
+	remote := git.Remote{
+		Provider:            metadata.GitProvider,
+		URL:                 f.Build.Git.URL,
+		User:                metadata.GitUser,
+		PersonalAccessToken: metadata.PersonalAccessToken,
+	}
+	id, err := git.CreateWebHook(ctx, remote, controllerURL, metadata.WebhookSecret)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(" ✅ Webhook is present on repository %v\n", f.Build.Git.URL)
+
+	// retain the webhook, such that it can be removed with the other resources
+	f.Local.Webhook = &fn.Webhook{
+		Provider: metadata.GitProvider,
+		URL:      f.Build.Git.URL,
+		ID:       id,
+		User:     metadata.GitUser,
+	}
+	return f.Write()
+}
+
+// removeRemotePACResources deletes the webhook retained in the function's local
+// metadata from the remote git repository.  The access token is read from the
+// cluster if it is not provided.
+func (pp *PipelinesProvider) removeRemotePACResources(ctx context.Context, f fn.Function, metadata pipelines.PacMetadata) error {
+	hook := f.Local.Webhook
+	token := metadata.PersonalAccessToken
+	if token == "" {
+		secret, err := k8s.GetSecret(ctx, getPipelineSecretName(f), f.Deploy.Namespace)
+		if err != nil {
+			return fmt.Errorf("cannot read the access token to delete the webhook on repository %v, please provide it: %w", hook.URL, err)
 		}
-		fmt.Printf(" ✅ Webhook already exists on repository %v\n", f.Build.Git.URL)
-	} else {
-		fmt.Printf(" ✅ Webhook is created on repository %v\n", f.Build.Git.URL)
+		token = string(secret.Data["provider.token"])
 	}
 
-	return nil
+	remote := git.Remote{
+		Provider:            hook.Provider,
+		URL:                 hook.URL,
+		User:                hook.User,
+		PersonalAccessToken: token,
+	}
+	if err := git.DeleteWebHook(ctx, remote, hook.ID); err != nil {
+		return err
+	}
+	fmt.Printf(" ✅ Webhook is removed from repository %v\n", hook.URL)
+
+	f.Local.Webhook = nil
+	return f.Write()
 }
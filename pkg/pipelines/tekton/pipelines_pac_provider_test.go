@@ -2,11 +2,15 @@ package tekton
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"testing"
 
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/git"
+	"knative.dev/func/pkg/pipelines"
 	. "knative.dev/func/pkg/testing"
 )
 
@@ -95,3 +99,45 @@ func Test_deleteAllPipelineTemplates(t *testing.T) {
 		t.Errorf("directory with pipeline resources shouldn't exist on path = %s", fp)
 	}
 }
+
+// Test_RemovePAC_Webhook ensures the webhook retained in the function's local
+// metadata is deleted from the repository, and is then forgotten.
+func Test_RemovePAC_Webhook(t *testing.T) {
+	var deleted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && r.Header.Get("Authorization") == "token secret-token" {
+			deleted = r.URL.Path
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Local.Webhook = &fn.Webhook{Provider: git.GiteaProvider, URL: srv.URL + "/foo/bar", ID: "7"}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	pp := NewPipelinesProvider()
+	err = pp.RemovePAC(context.Background(), f, pipelines.PacMetadata{
+		PersonalAccessToken:      "secret-token",
+		ConfigureRemoteResources: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != "/api/v1/repos/foo/bar/hooks/7" {
+		t.Fatalf("expected the webhook to be deleted, got %q", deleted)
+	}
+
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Local.Webhook != nil {
+		t.Fatalf("expected the webhook to be forgotten, got %+v", f.Local.Webhook)
+	}
+}
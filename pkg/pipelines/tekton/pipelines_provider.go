@@ -29,7 +29,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8slabels "k8s.io/apimachinery/pkg/labels"
 
+	"knative.dev/func/pkg/deployers"
 	"knative.dev/func/pkg/docker"
+	"knative.dev/func/pkg/filesync"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
 	fnlabels "knative.dev/func/pkg/k8s/labels"
@@ -53,9 +55,12 @@ type pacURLCallback = func() (string, error)
 
 type PipelinesProvider struct {
 	verbose             bool
+	quiet               bool
 	getPacURL           pacURLCallback
 	credentialsProvider docker.CredentialsProvider
 	decorator           PipelineDecorator
+	secretsKeyFile      string
+	registryInsecure    bool
 }
 
 func WithCredentialsProvider(credentialsProvider docker.CredentialsProvider) Opt {
@@ -70,6 +75,14 @@ func WithVerbose(verbose bool) Opt {
 	}
 }
 
+// WithQuiet disables the streaming of the logs of running pipelines, such
+// that only the name of each task is printed as it starts.
+func WithQuiet(quiet bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.quiet = quiet
+	}
+}
+
 func WithPipelineDecorator(decorator PipelineDecorator) Opt {
 	return func(pp *PipelinesProvider) {
 		pp.decorator = decorator
@@ -82,6 +95,23 @@ func WithPacURLCallback(getPacURL pacURLCallback) Opt {
 	}
 }
 
+// WithSecretsKey sets the path to the key used to decrypt the secrets managed
+// with the function, which are created by the client before the pipeline
+// runs such that the key never leaves it.
+func WithSecretsKey(keyFile string) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.secretsKeyFile = keyFile
+	}
+}
+
+// WithRegistryInsecure skips the verification of the TLS certificate of the
+// registry to which the host builder pushes the function's image.
+func WithRegistryInsecure(insecure bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.registryInsecure = insecure
+	}
+}
+
 func NewPipelinesProvider(opts ...Opt) *PipelinesProvider {
 	pp := &PipelinesProvider{
 		getPacURL: func() (string, error) {
@@ -138,7 +168,7 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 	f.Deploy.Image = image
 
 	// Client for the given namespace
-	client, err := NewTektonClient(namespace)
+	client, err := NewTektonClient(ctx, namespace)
 	if err != nil {
 		return "", f, err
 	}
@@ -156,18 +186,25 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 	if err != nil {
 		return "", f, err
 	}
+	separateCache := separateCacheVolume(ctx)
+	if separateCache {
+		err = CreateCachePersistentVolumeClaim(ctx, f, namespace, labels)
+		if err != nil {
+			return "", f, err
+		}
+	} else if f.Build.SharedCache {
+		fmt.Fprintf(os.Stderr, "Warning: the build cache is not shared, as the affinity assistant of Tekton allows a single PVC per build\n")
+	}
 
 	if f.Build.Git.URL == "" {
-		// Use direct upload to PVC if Git is not set up.
-		content := sourcesAsTarStream(f)
-		defer content.Close()
-		err = k8s.UploadToVolume(ctx, content, getPipelinePvcName(f), namespace)
+		// Sync the sources to the PVC if Git is not set up.
+		err = SyncSources(ctx, f, getPipelinePvcName(f), namespace)
 		if err != nil {
 			return "", f, fmt.Errorf("cannot upload sources to the PVC: %w", err)
 		}
 	}
 
-	err = createAndApplyPipelineTemplate(f, namespace, labels)
+	err = createAndApplyPipelineTemplate(ctx, f, namespace, labels)
 	if err != nil {
 		if !k8serrors.IsAlreadyExists(err) {
 			if k8serrors.IsNotFound(err) {
@@ -204,7 +241,12 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		return "", f, fmt.Errorf("problem in creating secret: %v", err)
 	}
 
-	err = createAndApplyPipelineRunTemplate(f, namespace, labels)
+	err = k8s.EnsureFunctionResources(ctx, f, namespace, pp.secretsKeyFile)
+	if err != nil {
+		return "", f, fmt.Errorf("problem in creating the function's resources: %v", err)
+	}
+
+	err = createAndApplyPipelineRunTemplate(ctx, f, namespace, labels, separateCache, pp.registryInsecure)
 	if err != nil {
 		return "", f, fmt.Errorf("problem in creating pipeline run: %v", err)
 	}
@@ -222,8 +264,8 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		if !errors.Is(err, context.Canceled) {
 			return "", f, fmt.Errorf("problem in watching started pipeline run: %v", err)
 		}
-		// TODO replace deletion with pipeline-run cancellation
-		_ = client.PipelineRuns(namespace).Delete(context.TODO(), newestPipelineRun.Name, metav1.DeleteOptions{})
+		// The context is done, so the run is cancelled in the background.
+		_ = cancelPipelineRun(context.Background(), client, newestPipelineRun.Name, namespace)
 		return "", f, fmt.Errorf("pipeline run cancelled: %w", context.Canceled)
 	}
 
@@ -233,18 +275,38 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 	}
 
 	if newestPipelineRun.Status.GetCondition(apis.ConditionSucceeded).Status == corev1.ConditionFalse {
-		message := getFailedPipelineRunLog(ctx, client, newestPipelineRun, namespace)
+		// The logs of the failed step were already streamed unless quiet.
+		message := newestPipelineRun.Status.GetCondition(apis.ConditionSucceeded).Message
+		if pp.quiet {
+			message = getFailedPipelineRunLog(ctx, client, newestPipelineRun, namespace)
+		}
 		return "", f, fmt.Errorf("function pipeline run has failed with message: \n\n%s", message)
 	}
 
-	kClient, err := knative.NewServingClient(namespace)
+	url, err := ReportDeployed(ctx, f, namespace, pp.verbose)
+	return url, f, err
+}
+
+// ReportDeployed prints, and returns, the URL at which the function deployed
+// on cluster to the given namespace is exposed.
+func ReportDeployed(ctx context.Context, f fn.Function, namespace string, verbose bool) (string, error) {
+	if f.Deploy.Platform == deployers.Kubernetes {
+		instance, err := k8s.NewDescriber(verbose).Describe(ctx, f.Name, namespace)
+		if err != nil {
+			return "", fmt.Errorf("problem in retrieving status of deployed function: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Function deployed in namespace %q and exposed at URL: \n   %s\n", namespace, instance.Route)
+		return instance.Route, nil
+	}
+
+	kClient, err := knative.NewServingClient(ctx, namespace)
 	if err != nil {
-		return "", f, fmt.Errorf("problem in retrieving status of deployed function: %v", err)
+		return "", fmt.Errorf("problem in retrieving status of deployed function: %v", err)
 	}
 
 	ksvc, err := kClient.GetService(ctx, f.Name)
 	if err != nil {
-		return "", f, fmt.Errorf("problem in retrieving status of deployed function: %v", err)
+		return "", fmt.Errorf("problem in retrieving status of deployed function: %v", err)
 	}
 
 	if ksvc.Generation == 1 {
@@ -257,21 +319,87 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		fmt.Fprintf(os.Stderr, "Warning: Final ksvc namespace %q does not match expected %q", ksvc.Namespace, namespace)
 	}
 
-	return ksvc.Status.URL.String(), f, nil
+	return ksvc.Status.URL.String(), nil
+}
+
+// SyncSources of the function to the "source" directory of the PVC, sending
+// only the files which changed since the last sync, and deleting those which
+// were removed.
+func SyncSources(ctx context.Context, f fn.Function, claimName, namespace string) error {
+	local, err := filesync.NewManifest(f.Root, sourcesIgnored(f.Root))
+	if err != nil {
+		return fmt.Errorf("cannot read the sources: %w", err)
+	}
+
+	var content io.ReadCloser
+	defer func() {
+		if content != nil {
+			_ = content.Close()
+		}
+	}()
+	changes := func(remote filesync.Manifest) (io.Reader, error) {
+		changed, deleted := local.Diff(remote)
+		if len(remote) == 0 {
+			// Not synced before, or sources uploaded in full by an older
+			// version, which are replaced.
+			deleted = []string{""}
+		}
+		header := filesync.Header{Manifest: local}
+		for _, p := range deleted {
+			header.Deleted = append(header.Deleted, path.Join("source", p))
+		}
+
+		content = sourcesAsTarStream(f, unchanged(changed))
+		return filesync.NewStream(header, content)
+	}
+	return k8s.SyncToVolume(ctx, changes, claimName, namespace)
+}
+
+// unchanged returns a matcher of the paths of the function's sources which
+// are not among the changed paths.  The directories containing changed paths
+// are not matched either, as the contents of those matched are not walked.
+// Directories which exist already are left as they are when extracted.
+func unchanged(changed []string) func(string) bool {
+	include := make(map[string]bool, len(changed))
+	for _, p := range changed {
+		for ; p != "." && !include[p]; p = path.Dir(p) {
+			include[p] = true
+		}
+	}
+	return func(p string) bool { return !include[strings.TrimSuffix(filepath.ToSlash(p), "/")] }
 }
 
-// Creates tar stream with the function sources as they were in "./source" directory.
-func sourcesAsTarStream(f fn.Function) *io.PipeReader {
-	ignored := func(p string) bool { return strings.HasPrefix(p, ".git") }
-	if gi, err := gitignore.CompileIgnoreFile(filepath.Join(f.Root, ".gitignore")); err == nil {
-		ignored = func(p string) bool {
-			if strings.HasPrefix(p, ".git") {
+// sourcesIgnored returns a matcher of the paths of the function's sources
+// which are not uploaded: those of Git, .func and the managed resources, and
+// those matched by either its .funcignore, as with the local builders, or its
+// .gitignore.  Both are applied, as a .funcignore is written on create which
+// defines no patterns.
+func sourcesIgnored(root string) func(string) bool {
+	var matchers []*gitignore.GitIgnore
+	for _, file := range []string{".funcignore", ".gitignore"} {
+		if gi, err := gitignore.CompileIgnoreFile(filepath.Join(root, file)); err == nil {
+			matchers = append(matchers, gi)
+		}
+	}
+	return func(p string) bool {
+		if strings.HasPrefix(p, ".git") ||
+			p == fn.RunDataDir || strings.HasPrefix(p, fn.RunDataDir+string(os.PathSeparator)) ||
+			p == fn.ResourcesDir || strings.HasPrefix(p, fn.ResourcesDir+string(os.PathSeparator)) {
+			return true
+		}
+		for _, gi := range matchers {
+			if gi.MatchesPath(p) {
 				return true
 			}
-			return gi.MatchesPath(p)
 		}
+		return false
 	}
+}
 
+// Creates tar stream with the function sources as they were in "./source"
+// directory, less those ignored.  Directories are ignored if matched with
+// or without a trailing slash, and their contents are not walked.
+func sourcesAsTarStream(f fn.Function, ignored func(string) bool) *io.PipeReader {
 	pr, pw := io.Pipe()
 
 	const nobodyID = 65534
@@ -307,6 +435,9 @@ func sourcesAsTarStream(f fn.Function) *io.PipeReader {
 				return nil
 			}
 
+			if fi.IsDir() && (ignored(relp) || ignored(relp+"/")) {
+				return filepath.SkipDir
+			}
 			if ignored(relp) {
 				return nil
 			}
@@ -400,6 +531,7 @@ func (pp *PipelinesProvider) removeClusterResources(ctx context.Context, f fn.Fu
 		deletePipelines,
 		deletePipelineRuns,
 		k8s.DeleteSecrets,
+		k8s.DeleteConfigMaps,
 		k8s.DeletePersistentVolumeClaims,
 		deletePACRepositories,
 	}
@@ -436,8 +568,9 @@ func (pp *PipelinesProvider) removeClusterResources(ctx context.Context, f fn.Fu
 	return nil
 }
 
-// watchPipelineRunProgress watches the progress of the input PipelineRun
-// and prints detailed description of the currently executed Tekton Task.
+// watchPipelineRunProgress watches the progress of the input PipelineRun,
+// streaming the logs of its steps or, if quiet, printing a detailed
+// description of the currently executed Tekton Task.
 func (pp *PipelinesProvider) watchPipelineRunProgress(ctx context.Context, pr *v1.PipelineRun, namespace string) error {
 	taskProgressMsg := map[string]string{
 		"fetch-sources": "Fetching git repository with the function source code",
@@ -445,11 +578,15 @@ func (pp *PipelinesProvider) watchPipelineRunProgress(ctx context.Context, pr *v
 		"deploy":        "Deploying function to the cluster",
 	}
 
-	clients, err := NewTektonClients()
+	clients, err := NewTektonClients(ctx)
 	if err != nil {
 		return err
 	}
 
+	if !pp.quiet {
+		return followPipelineRunLogs(ctx, clients, pr, namespace, &logWriter{w: os.Stderr})
+	}
+
 	prTracker := pipelinerun.NewTracker(pr.Name, namespace, clients)
 	trChannel := prTracker.Monitor([]string{})
 	ctxDone := ctx.Done()
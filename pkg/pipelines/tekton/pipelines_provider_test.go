@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"knative.dev/func/pkg/filesync"
 	fn "knative.dev/func/pkg/functions"
 )
 
@@ -28,7 +29,7 @@ func TestSourcesAsTarStream(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	rc := sourcesAsTarStream(fn.Function{Root: root})
+	rc := sourcesAsTarStream(fn.Function{Root: root}, sourcesIgnored(root))
 	t.Cleanup(func() { _ = rc.Close() })
 
 	var helloTxtContent []byte
@@ -73,6 +74,38 @@ func TestSourcesAsTarStream(t *testing.T) {
 	}
 }
 
+// TestSourcesAsTarStream_Changed ensures only the changed files are streamed,
+// including those within directories which did not change.
+func TestSourcesAsTarStream_Changed(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"main.go", "dir/changed.go", "dir/unchanged.go"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), []byte("package main"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rc := sourcesAsTarStream(fn.Function{Root: root}, unchanged([]string{"dir/changed.go"}))
+	t.Cleanup(func() { _ = rc.Close() })
+
+	var names []string
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	if strings.Join(names, ",") != "source/,source/dir,source/dir/changed.go" {
+		t.Fatalf("unexpected entries: %v", names)
+	}
+}
+
 func Test_createPipelinePersistentVolumeClaim(t *testing.T) {
 	type mockType func(ctx context.Context, name, namespaceOverride string, labels map[string]string, annotations map[string]string, accessMode corev1.PersistentVolumeAccessMode, resourceRequest resource.Quantity) (err error)
 
@@ -145,3 +178,63 @@ func Test_createPipelinePersistentVolumeClaim(t *testing.T) {
 		})
 	}
 }
+
+// TestSourcesIgnored ensures the paths matched by either the .funcignore or
+// the .gitignore of the function are ignored, and .git and .func always are.
+func TestSourcesIgnored(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".funcignore"), []byte("vendor/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("*.txt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ignored := sourcesIgnored(root)
+	for p, expected := range map[string]bool{
+		filepath.Join("vendor", "a", "a.go"):                  true,
+		filepath.Join(".git", "HEAD"):                         true,
+		filepath.Join(".func", "built"):                       true,
+		filepath.Join(".funcresources", "secrets", "db.yaml"): true,
+		".gitignore": true,
+		"hello.txt":  true,
+		"main.go":    false,
+	} {
+		if ignored(p) != expected {
+			t.Errorf("expected %v ignored to be %v", p, expected)
+		}
+	}
+}
+
+// TestSourcesIgnored_Scaffolded ensures the build outputs of a created
+// function, listed in the .gitignore of its template, are ignored alongside
+// the .funcignore written on create.
+func TestSourcesIgnored_Scaffolded(t *testing.T) {
+	root := t.TempDir()
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "rust", Registry: "example.com/alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".funcignore")); err != nil {
+		t.Fatalf("expected a .funcignore to be written on create: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "target", "debug"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "target", "debug", "function"), []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := filesync.NewManifest(root, sourcesIgnored(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p := range m {
+		if strings.HasPrefix(p, "target") {
+			t.Errorf("expected the target directory to be ignored, found %q", p)
+		}
+	}
+	if _, ok := m["src/lib.rs"]; !ok {
+		t.Errorf("expected the sources not to be ignored, got %v", m)
+	}
+}
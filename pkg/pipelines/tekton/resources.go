@@ -17,7 +17,7 @@ func deletePipelines(ctx context.Context, namespace string, listOptions metav1.L
 	if namespace == "" {
 		return errors.New("delete pipeline: namespace required")
 	}
-	client, err := NewTektonClient(namespace)
+	client, err := NewTektonClient(ctx, namespace)
 	if err != nil {
 		return
 	}
@@ -29,7 +29,7 @@ func deletePipelineRuns(ctx context.Context, namespace string, listOptions metav
 	if namespace == "" {
 		return errors.New("delete pipeline run: namespace required")
 	}
-	client, err := NewTektonClient(namespace)
+	client, err := NewTektonClient(ctx, namespace)
 	if err != nil {
 		return
 	}
@@ -41,8 +41,11 @@ func deletePipelineRuns(ctx context.Context, namespace string, listOptions metav
 // with the Pack strategy if it can be calculated (the Function has a defined
 // language runtime.  Errors are checked elsewhere, so at this level they
 // manifest as an inability to get a builder image = empty string.
+// The host builder uses no builder image.
 func getBuilderImage(f fn.Function) (name string) {
-	if f.Build.Builder == builders.S2I {
+	if f.Build.Builder == builders.Host {
+		return
+	} else if f.Build.Builder == builders.S2I {
 		name, _ = s2i.BuilderImage(f, builders.S2I)
 	} else {
 		name, _ = buildpacks.BuilderImage(f, builders.Pack)
@@ -11,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/git"
 	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/pipelines"
 	"knative.dev/func/pkg/pipelines/tekton/pac"
@@ -42,11 +43,18 @@ func ensurePACSecretExists(ctx context.Context, f fn.Function, namespace string,
 
 // ensurePACRepositoryExists checks that up-to-date Repository CR is present on the cluster
 func ensurePACRepositoryExists(ctx context.Context, f fn.Function, namespace string, metadata pipelines.PacMetadata, labels map[string]string) error {
-	client, namespace, err := pac.NewTektonPacClientAndResolvedNamespace(namespace)
+	client, namespace, err := pac.NewTektonPacClientAndResolvedNamespace(ctx, namespace)
 	if err != nil {
 		return err
 	}
 
+	var providerURL string
+	if f.Build.Git.URL != "" {
+		if providerURL, err = git.ProviderURL(metadata.GitProvider, f.Build.Git.URL); err != nil {
+			return err
+		}
+	}
+
 	repoName := getPipelineRepositoryName(f)
 	repo := v1alpha1.Repository{
 		ObjectMeta: metav1.ObjectMeta{
@@ -58,6 +66,8 @@ func ensurePACRepositoryExists(ctx context.Context, f fn.Function, namespace str
 			URL: f.Build.Git.URL,
 			GitProvider: &v1alpha1.GitProvider{
 				Type: metadata.GitProvider,
+				URL:  providerURL,
+				User: metadata.GitUser,
 				Secret: &v1alpha1.Secret{
 					Name: getPipelineSecretName(f),
 				},
@@ -95,7 +105,7 @@ func ensurePACRepositoryExists(ctx context.Context, f fn.Function, namespace str
 
 // deletePACRepositories deletes all Repository resources present on the cluster that match input list options
 func deletePACRepositories(ctx context.Context, namespaceOverride string, listOptions metav1.ListOptions) error {
-	client, namespace, err := pac.NewTektonPacClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := pac.NewTektonPacClientAndResolvedNamespace(ctx, namespaceOverride)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,297 @@
+package tekton
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	pipelineClient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/typed/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+
+	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+const (
+	// pacPrefix of the labels and annotations of Pipelines as Code.
+	pacPrefix = "pipelinesascode.tekton.dev/"
+	// pacShaKey annotates a Pipelines as Code run with the commit it built.
+	pacShaKey = pacPrefix + "sha"
+	// kubectlLastAppliedKey annotates resources applied by kubectl.
+	kubectlLastAppliedKey = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// pipelineNamespace of the function's pipeline runs.
+func pipelineNamespace(f fn.Function) (string, error) {
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if namespace == "" {
+		return "", fn.ErrNamespaceRequired
+	}
+	return namespace, nil
+}
+
+// runsSelector selects the pipeline runs of the function, and their task
+// runs, by the labels identifying the function.  Labels defined by the user
+// are not used, as they may have changed since a run.
+func runsSelector(f fn.Function) (string, error) {
+	labels, err := f.LabelsMap()
+	if err != nil {
+		return "", err
+	}
+	return k8slabels.SelectorFromSet(k8slabels.Set{
+		fnlabels.FunctionKey:     labels[fnlabels.FunctionKey],
+		fnlabels.FunctionNameKey: labels[fnlabels.FunctionNameKey],
+	}).String(), nil
+}
+
+// Runs of the function's pipeline, most recent first.
+func (pp *PipelinesProvider) Runs(ctx context.Context, f fn.Function) ([]fn.PipelineRun, error) {
+	namespace, err := pipelineNamespace(f)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewTektonClient(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return listPipelineRuns(ctx, client, f, namespace)
+}
+
+// DescribeRun returns the details of the named run of the function's
+// pipeline, including its tasks.
+func (pp *PipelinesProvider) DescribeRun(ctx context.Context, f fn.Function, run string) (fn.PipelineRun, error) {
+	namespace, err := pipelineNamespace(f)
+	if err != nil {
+		return fn.PipelineRun{}, err
+	}
+	client, err := NewTektonClient(ctx, namespace)
+	if err != nil {
+		return fn.PipelineRun{}, err
+	}
+	return describePipelineRun(ctx, client, f, run, namespace)
+}
+
+// CancelRun cancels the named run of the function's pipeline.
+func (pp *PipelinesProvider) CancelRun(ctx context.Context, f fn.Function, run string) error {
+	namespace, err := pipelineNamespace(f)
+	if err != nil {
+		return err
+	}
+	client, err := NewTektonClient(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	pr, err := getFunctionPipelineRun(ctx, client, f, run, namespace)
+	if err != nil {
+		return err
+	}
+	if pr.IsDone() {
+		return fmt.Errorf("pipeline run %q has already completed", run)
+	}
+	return cancelPipelineRun(ctx, client, run, namespace)
+}
+
+// Rerun starts a new run of the function's pipeline with the spec of the
+// named run, returning the name of the new run.
+func (pp *PipelinesProvider) Rerun(ctx context.Context, f fn.Function, run string) (string, error) {
+	namespace, err := pipelineNamespace(f)
+	if err != nil {
+		return "", err
+	}
+	client, err := NewTektonClient(ctx, namespace)
+	if err != nil {
+		return "", err
+	}
+	return rerunPipelineRun(ctx, client, f, run, namespace)
+}
+
+// listPipelineRuns of the function, with the tasks of each.
+func listPipelineRuns(ctx context.Context, client pipelineClient.TektonV1Interface, f fn.Function, namespace string) ([]fn.PipelineRun, error) {
+	selector, err := runsSelector(f)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := client.PipelineRuns(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("problem in listing pipeline runs: %w", err)
+	}
+	trs, err := client.TaskRuns(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("problem in listing task runs: %w", err)
+	}
+	taskRuns := map[string][]v1.TaskRun{}
+	for _, tr := range trs.Items {
+		run := tr.Labels[pipeline.PipelineRunLabelKey]
+		taskRuns[run] = append(taskRuns[run], tr)
+	}
+
+	sort.SliceStable(prs.Items, func(i, j int) bool {
+		return prs.Items[j].CreationTimestamp.Before(&prs.Items[i].CreationTimestamp)
+	})
+	runs := make([]fn.PipelineRun, 0, len(prs.Items))
+	for i := range prs.Items {
+		runs = append(runs, toPipelineRun(&prs.Items[i], taskRuns[prs.Items[i].Name]))
+	}
+	return runs, nil
+}
+
+// describePipelineRun of the function with its tasks.
+func describePipelineRun(ctx context.Context, client pipelineClient.TektonV1Interface, f fn.Function, run, namespace string) (fn.PipelineRun, error) {
+	pr, err := getFunctionPipelineRun(ctx, client, f, run, namespace)
+	if err != nil {
+		return fn.PipelineRun{}, err
+	}
+	trs, err := client.TaskRuns(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: k8slabels.SelectorFromSet(k8slabels.Set{pipeline.PipelineRunLabelKey: run}).String(),
+	})
+	if err != nil {
+		return fn.PipelineRun{}, fmt.Errorf("problem in listing task runs: %w", err)
+	}
+	return toPipelineRun(pr, trs.Items), nil
+}
+
+// getFunctionPipelineRun returns the named pipeline run, ensuring it is a
+// run of the function.
+func getFunctionPipelineRun(ctx context.Context, client pipelineClient.TektonV1Interface, f fn.Function, run, namespace string) (*v1.PipelineRun, error) {
+	pr, err := client.PipelineRuns(namespace).Get(ctx, run, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("problem in retrieving pipeline run %q: %w", run, err)
+	}
+	if pr.Labels[fnlabels.FunctionNameKey] != f.Name {
+		return nil, fmt.Errorf("pipeline run %q is not a run of function %q", run, f.Name)
+	}
+	return pr, nil
+}
+
+// cancelPipelineRun by setting its status to cancelled, such that Tekton
+// stops its tasks while retaining the run and its logs.
+func cancelPipelineRun(ctx context.Context, client pipelineClient.TektonV1Interface, run, namespace string) error {
+	patch := fmt.Sprintf(`{"spec":{"status":%q}}`, v1.PipelineRunSpecStatusCancelled)
+	_, err := client.PipelineRuns(namespace).Patch(ctx, run, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("problem in cancelling pipeline run %q: %w", run, err)
+	}
+	return nil
+}
+
+// rerunPipelineRun creates a new pipeline run with the spec, labels and
+// annotations of the named run.  Those of Pipelines as Code are not copied,
+// as the new run is not of a Git event.
+func rerunPipelineRun(ctx context.Context, client pipelineClient.TektonV1Interface, f fn.Function, run, namespace string) (string, error) {
+	pr, err := getFunctionPipelineRun(ctx, client, f, run, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	generateName := pr.GenerateName
+	if generateName == "" {
+		generateName = pr.Name + "-"
+	}
+	rerun := &v1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Labels:       copyMetadata(pr.Labels),
+			Annotations:  copyMetadata(pr.Annotations),
+		},
+		Spec: *pr.Spec.DeepCopy(),
+	}
+	rerun.Spec.Status = ""
+
+	rerun, err = client.PipelineRuns(namespace).Create(ctx, rerun, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("problem in creating pipeline run: %w", err)
+	}
+	return rerun.Name, nil
+}
+
+// copyMetadata copies labels or annotations, less those managed by Tekton,
+// Pipelines as Code and kubectl.
+func copyMetadata(m map[string]string) map[string]string {
+	c := map[string]string{}
+	for k, v := range m {
+		if strings.HasPrefix(k, pipeline.GroupName+"/") || strings.HasPrefix(k, pacPrefix) || k == kubectlLastAppliedKey {
+			continue
+		}
+		c[k] = v
+	}
+	return c
+}
+
+// toPipelineRun converts the pipeline run and its task runs.
+func toPipelineRun(pr *v1.PipelineRun, trs []v1.TaskRun) fn.PipelineRun {
+	run := fn.PipelineRun{
+		Name:      pr.Name,
+		Namespace: pr.Namespace,
+		Commit:    pr.Annotations[pacShaKey],
+	}
+	run.Status, run.Message = conditionStatus(pr.Status.GetCondition(apis.ConditionSucceeded))
+	if pr.Status.StartTime != nil {
+		run.StartTime = pr.Status.StartTime.Time
+	}
+	if pr.Status.CompletionTime != nil {
+		run.CompletionTime = pr.Status.CompletionTime.Time
+	}
+
+	sort.SliceStable(trs, func(i, j int) bool {
+		return startTime(trs[i]).Before(startTime(trs[j]))
+	})
+	for _, tr := range trs {
+		task := fn.PipelineTaskRun{
+			Name:      tr.Labels[pipeline.PipelineTaskLabelKey],
+			StartTime: startTime(tr),
+		}
+		if task.Name == "" {
+			task.Name = tr.Name
+		}
+		task.Status, task.Message = conditionStatus(tr.Status.GetCondition(apis.ConditionSucceeded))
+		if tr.Status.CompletionTime != nil {
+			task.CompletionTime = tr.Status.CompletionTime.Time
+		}
+		run.Tasks = append(run.Tasks, task)
+
+		for _, r := range tr.Status.Results {
+			switch {
+			case r.Name == "IMAGE_DIGEST":
+				run.ImageDigest = r.Value.StringVal
+			case r.Name == "commit" && run.Commit == "":
+				run.Commit = r.Value.StringVal
+			}
+		}
+	}
+	return run
+}
+
+func startTime(tr v1.TaskRun) (t time.Time) {
+	if tr.Status.StartTime != nil {
+		t = tr.Status.StartTime.Time
+	}
+	return
+}
+
+// conditionStatus returns the status and message of a run's succeeded
+// condition, preferring its reason, such as Cancelled, as the status.
+func conditionStatus(c *apis.Condition) (status, message string) {
+	if c == nil {
+		return "Pending", ""
+	}
+	if c.Reason != "" {
+		return c.Reason, c.Message
+	}
+	switch {
+	case c.IsTrue():
+		return "Succeeded", c.Message
+	case c.IsFalse():
+		return "Failed", c.Message
+	}
+	return "Running", c.Message
+}
@@ -0,0 +1,188 @@
+//go:build !integration
+// +build !integration
+
+package tekton
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	tektonfake "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+var runsEpoch = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testPipelineRun(name, function string, created time.Duration, condition *apis.Condition) *v1.PipelineRun {
+	pr := &v1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			GenerateName:      function + "-pipeline-run-",
+			Namespace:         "ns",
+			CreationTimestamp: metav1.NewTime(runsEpoch.Add(created)),
+			Labels: map[string]string{
+				"function.knative.dev":      "true",
+				"function.knative.dev/name": function,
+				"tekton.dev/pipeline":       function + "-pipeline",
+			},
+		},
+		Spec: v1.PipelineRunSpec{
+			PipelineRef: &v1.PipelineRef{Name: function + "-pipeline"},
+		},
+		Status: v1.PipelineRunStatus{
+			PipelineRunStatusFields: v1.PipelineRunStatusFields{
+				StartTime: &metav1.Time{Time: runsEpoch.Add(created)},
+			},
+		},
+	}
+	if condition != nil {
+		pr.Status.Conditions = duckv1.Conditions{*condition}
+		pr.Status.CompletionTime = &metav1.Time{Time: runsEpoch.Add(created + 2*time.Minute)}
+	}
+	return pr
+}
+
+func testTaskRun(name, run, task string, started time.Duration, results ...v1.TaskRunResult) *v1.TaskRun {
+	return &v1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			Labels: map[string]string{
+				"function.knative.dev":      "true",
+				"function.knative.dev/name": "f",
+				"tekton.dev/pipelineRun":    run,
+				"tekton.dev/pipelineTask":   task,
+			},
+		},
+		Status: v1.TaskRunStatus{
+			Status: duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue, Reason: "Succeeded"}}},
+			TaskRunStatusFields: v1.TaskRunStatusFields{
+				StartTime:      &metav1.Time{Time: runsEpoch.Add(started)},
+				CompletionTime: &metav1.Time{Time: runsEpoch.Add(started + time.Minute)},
+				Results:        results,
+			},
+		},
+	}
+}
+
+func stringResult(name, value string) v1.TaskRunResult {
+	return v1.TaskRunResult{Name: name, Value: *v1.NewStructuredValues(value)}
+}
+
+// TestListPipelineRuns ensures the runs of only the function are listed, most
+// recent first, with the commit and image digest from the results of their
+// tasks.
+func TestListPipelineRuns(t *testing.T) {
+	succeeded := &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue, Reason: "Succeeded"}
+	client := tektonfake.NewSimpleClientset(
+		testPipelineRun("f-run-old", "f", 0, succeeded),
+		testPipelineRun("f-run-new", "f", time.Hour, nil),
+		testPipelineRun("g-run", "g", 2*time.Hour, succeeded),
+		testTaskRun("f-run-old-fetch", "f-run-old", "fetch-sources", 0, stringResult("commit", "0123456789abcdef")),
+		testTaskRun("f-run-old-build", "f-run-old", "build", time.Minute, stringResult("IMAGE_DIGEST", "sha256:abc")),
+	).TektonV1()
+
+	runs, err := listPipelineRuns(context.Background(), client, fn.Function{Name: "f"}, "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %v", len(runs))
+	}
+	if runs[0].Name != "f-run-new" || runs[0].Status != "Pending" {
+		t.Fatalf("unexpected newest run: %+v", runs[0])
+	}
+	old := runs[1]
+	if old.Name != "f-run-old" || old.Status != "Succeeded" {
+		t.Fatalf("unexpected oldest run: %+v", old)
+	}
+	if old.Duration() != 2*time.Minute {
+		t.Fatalf("expected duration of 2m, got %v", old.Duration())
+	}
+	if old.Commit != "0123456789abcdef" || old.ImageDigest != "sha256:abc" {
+		t.Fatalf("unexpected commit %q or image digest %q", old.Commit, old.ImageDigest)
+	}
+	if len(old.Tasks) != 2 || old.Tasks[0].Name != "fetch-sources" || old.Tasks[1].Name != "build" {
+		t.Fatalf("unexpected tasks: %+v", old.Tasks)
+	}
+}
+
+// TestDescribePipelineRun_OtherFunction ensures the runs of other functions
+// are not described.
+func TestDescribePipelineRun_OtherFunction(t *testing.T) {
+	client := tektonfake.NewSimpleClientset(testPipelineRun("g-run", "g", 0, nil)).TektonV1()
+
+	if _, err := describePipelineRun(context.Background(), client, fn.Function{Name: "f"}, "g-run", "ns"); err == nil {
+		t.Fatal("expected an error describing the run of another function")
+	}
+}
+
+// TestCancelPipelineRun ensures a run is cancelled by its spec status rather
+// than deleted.
+func TestCancelPipelineRun(t *testing.T) {
+	tekton := tektonfake.NewSimpleClientset(testPipelineRun("f-run", "f", 0, nil))
+
+	if err := cancelPipelineRun(context.Background(), tekton.TektonV1(), "f-run", "ns"); err != nil {
+		t.Fatal(err)
+	}
+	pr, err := tekton.TektonV1().PipelineRuns("ns").Get(context.Background(), "f-run", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Spec.Status != v1.PipelineRunSpecStatusCancelled {
+		t.Fatalf("expected spec status %q, got %q", v1.PipelineRunSpecStatusCancelled, pr.Spec.Status)
+	}
+}
+
+// TestRerunPipelineRun ensures a rerun is created with the spec of the run,
+// less its status, and without the metadata of Tekton and Pipelines as Code.
+func TestRerunPipelineRun(t *testing.T) {
+	pr := testPipelineRun("f-run", "f", 0, nil)
+	pr.Spec.Status = v1.PipelineRunSpecStatusCancelled
+	pr.Labels["pipelinesascode.tekton.dev/event-type"] = "push"
+	pr.Annotations = map[string]string{
+		"pipelinesascode.tekton.dev/sha": "0123456789abcdef",
+		"user":                           "annotation",
+	}
+	tekton := tektonfake.NewSimpleClientset(pr)
+
+	if _, err := rerunPipelineRun(context.Background(), tekton.TektonV1(), fn.Function{Name: "f"}, "f-run", "ns"); err != nil {
+		t.Fatal(err)
+	}
+
+	var rerun *v1.PipelineRun
+	for _, a := range tekton.Actions() {
+		if create, ok := a.(k8stesting.CreateAction); ok {
+			rerun = create.GetObject().(*v1.PipelineRun)
+		}
+	}
+	if rerun == nil {
+		t.Fatal("no pipeline run was created")
+	}
+	if rerun.GenerateName != "f-pipeline-run-" {
+		t.Fatalf("unexpected generate name %q", rerun.GenerateName)
+	}
+	if rerun.Spec.Status != "" || rerun.Spec.PipelineRef.Name != "f-pipeline" {
+		t.Fatalf("unexpected spec: %+v", rerun.Spec)
+	}
+	for k := range rerun.Labels {
+		if strings.HasPrefix(k, "tekton.dev/") || strings.HasPrefix(k, "pipelinesascode.tekton.dev/") {
+			t.Fatalf("unexpected label %q", k)
+		}
+	}
+	if rerun.Labels["function.knative.dev/name"] != "f" {
+		t.Fatalf("expected the function's labels, got %v", rerun.Labels)
+	}
+	if _, ok := rerun.Annotations["pipelinesascode.tekton.dev/sha"]; ok || rerun.Annotations["user"] != "annotation" {
+		t.Fatalf("unexpected annotations %v", rerun.Annotations)
+	}
+}
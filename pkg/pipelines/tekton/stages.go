@@ -0,0 +1,180 @@
+package tekton
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// setStages of the function in the data of its pipeline template, such that
+// stages before build run after the sources are fetched, and scaffold (or
+// build, for builders without scaffolding) after them, as both use the same
+// workspace.  Stages before deploy run after build, and deploy after them.
+func (d *templateData) setStages(f fn.Function) (err error) {
+	fetchSources := d.RunAfterFetchSources != ""
+
+	var preBuildRunAfter []string
+	if fetchSources {
+		preBuildRunAfter = []string{"fetch-sources"}
+	}
+	deployRunAfter := []string{"build"}
+
+	var preBuild, preDeploy []v1beta1.PipelineTask
+	for _, s := range f.Build.Stages {
+		task, err := getStageTask(f, s, fetchSources)
+		if err != nil {
+			return err
+		}
+		if s.BeforeDeploy() {
+			preDeploy = append(preDeploy, task)
+			deployRunAfter = append(deployRunAfter, s.Name)
+		} else {
+			preBuild = append(preBuild, task)
+			preBuildRunAfter = append(preBuildRunAfter, s.Name)
+		}
+	}
+
+	buildRunAfter := preBuildRunAfter
+	if f.Build.Builder != builders.Host { // which is not scaffolded
+		d.ScaffoldRunAfter = runAfterYAML(preBuildRunAfter)
+		buildRunAfter = []string{"scaffold"}
+	}
+
+	if d.PreBuildStages, err = pipelineTasksYAML(preBuild); err != nil {
+		return
+	}
+	if d.PreDeployStages, err = pipelineTasksYAML(preDeploy); err != nil {
+		return
+	}
+	d.BuildRunAfter = runAfterYAML(buildRunAfter)
+	d.DeployRunAfter = runAfterYAML(deployRunAfter)
+	return
+}
+
+// getStageTask returns the pipeline task of the stage, with its task spec
+// embedded.  The script runs in the function's source directory.
+func getStageTask(f fn.Function, s fn.Stage, fetchSources bool) (task v1beta1.PipelineTask, err error) {
+	env, envFrom, err := k8s.ProcessContainerEnvs(f.Root, s.Envs, &sets.Set[string]{}, &sets.Set[string]{})
+	if err != nil {
+		return task, fmt.Errorf("stage %q: %w", s.Name, err)
+	}
+
+	task = v1beta1.PipelineTask{
+		Name: s.Name,
+		Params: v1beta1.Params{
+			{Name: "contextDir", Value: *v1beta1.NewStructuredValues("$(params.contextDir)")},
+		},
+		Workspaces: []v1beta1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: "source-workspace"},
+		},
+	}
+	spec := v1beta1.TaskSpec{
+		Params: v1beta1.ParamSpecs{
+			{Name: "contextDir", Type: v1beta1.ParamTypeString},
+		},
+		Workspaces: []v1beta1.WorkspaceDeclaration{
+			{Name: "source", Description: "Directory where function source is located."},
+		},
+	}
+	step := v1beta1.Step{
+		Name:       s.Name,
+		Image:      s.Image,
+		WorkingDir: "$(workspaces.source.path)/$(params.contextDir)",
+		Script:     s.Script,
+		Env:        env,
+		EnvFrom:    envFrom,
+	}
+
+	if s.BeforeDeploy() {
+		task.RunAfter = append(task.RunAfter, "build")
+		task.Params = append(task.Params, v1beta1.Param{
+			Name: "image", Value: *v1beta1.NewStructuredValues("$(params.imageName)@$(tasks.build.results.IMAGE_DIGEST)"),
+		})
+		task.Workspaces = append(task.Workspaces, v1beta1.WorkspacePipelineTaskBinding{
+			Name: "dockerconfig", Workspace: "dockerconfig-workspace",
+		})
+		spec.Params = append(spec.Params, v1beta1.ParamSpec{Name: "image", Type: v1beta1.ParamTypeString})
+		spec.Workspaces = append(spec.Workspaces, v1beta1.WorkspaceDeclaration{
+			Name: "dockerconfig", Description: "Directory containing image registry credentials stored in config.json file.", Optional: true,
+		})
+		step.Env = append(step.Env,
+			corev1.EnvVar{Name: "FUNC_IMAGE", Value: "$(params.image)"},
+			corev1.EnvVar{Name: "DOCKER_CONFIG", Value: "$(workspaces.dockerconfig.path)"})
+	} else if fetchSources {
+		task.RunAfter = append(task.RunAfter, "fetch-sources")
+	}
+	task.RunAfter = append(task.RunAfter, s.RunAfter...)
+
+	spec.Steps = []v1beta1.Step{step}
+	task.TaskSpec = &v1beta1.EmbeddedTask{TaskSpec: spec}
+	return
+}
+
+// pipelineTasksYAML renders the tasks as items of the tasks of a pipeline
+// template.
+func pipelineTasksYAML(tasks []v1beta1.PipelineTask) (string, error) {
+	if len(tasks) == 0 {
+		return "", nil
+	}
+	// The Tekton types are serialized by their JSON tags, and the JSON
+	// converted to block style YAML, retaining the order of the fields, less
+	// those which are empty.
+	bb, err := json.Marshal(tasks)
+	if err != nil {
+		return "", err
+	}
+	var node yaml.Node
+	if err = yaml.Unmarshal(bb, &node); err != nil {
+		return "", err
+	}
+	tidy(&node)
+
+	var buff bytes.Buffer
+	enc := yaml.NewEncoder(&buff)
+	enc.SetIndent(2)
+	if err = enc.Encode(&node); err != nil {
+		return "", err
+	}
+	if err = enc.Close(); err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(buff.String(), "\n"), "\n", "\n    "), nil
+}
+
+// tidy the node converted from JSON: in block style, without null or empty
+// mapping values.
+func tidy(n *yaml.Node) {
+	n.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	if n.Kind == yaml.MappingNode {
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v := n.Content[i+1]
+			if v.Tag == "!!null" || (v.Kind == yaml.MappingNode && len(v.Content) == 0) {
+				continue
+			}
+			content = append(content, n.Content[i], v)
+		}
+		n.Content = content
+	}
+	for _, c := range n.Content {
+		tidy(c)
+	}
+}
+
+// runAfterYAML renders the names of the tasks after which a task runs.
+func runAfterYAML(tasks []string) string {
+	if len(tasks) == 0 {
+		return ""
+	}
+	return "runAfter:\n        - " + strings.Join(tasks, "\n        - ")
+}
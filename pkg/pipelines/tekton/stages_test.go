@@ -0,0 +1,110 @@
+//go:build !integration
+// +build !integration
+
+package tekton
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/utils/ptr"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestPipelineTemplate_Stages ensures the stages of the function are tasks of
+// its pipeline, ordered between the fetching of its sources, its build and
+// its deployment, for each builder.
+func TestPipelineTemplate_Stages(t *testing.T) {
+	stages := []fn.Stage{
+		{Name: "lint", Image: "golangci/golangci-lint", Script: "golangci-lint run"},
+		{Name: "test", Image: "golang", Script: "go test ./...\ngo vet ./...", RunAfter: []string{"lint"},
+			Envs: fn.Envs{{Name: ptr.To("CGO_ENABLED"), Value: ptr.To("0")}}},
+		{Name: "scan", Before: fn.StageBeforeDeploy, Image: "aquasec/trivy", Script: "trivy image $FUNC_IMAGE"},
+	}
+
+	for _, tt := range []struct {
+		builder        string
+		scaffoldsAfter []string
+		buildsAfter    []string
+	}{
+		{builders.Pack, []string{"fetch-sources", "lint", "test"}, []string{"scaffold"}},
+		{builders.S2I, []string{"fetch-sources", "lint", "test"}, []string{"scaffold"}},
+		{builders.Host, nil, []string{"fetch-sources", "lint", "test"}},
+	} {
+		t.Run(tt.builder, func(t *testing.T) {
+			f := fn.Function{Root: t.TempDir(), Name: "f", Runtime: "go"}
+			f.Build.Builder = tt.builder
+			f.Build.Stages = stages
+			if err := createPipelineTemplatePAC(f, map[string]string{}); err != nil {
+				t.Fatal(err)
+			}
+
+			bb, err := os.ReadFile(filepath.Join(f.Root, resourcesDirectory, pipelineFileNamePAC))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var pipeline struct {
+				Spec struct {
+					Tasks []struct {
+						Name     string   `yaml:"name"`
+						RunAfter []string `yaml:"runAfter"`
+						TaskSpec struct {
+							Steps []struct {
+								Image  string `yaml:"image"`
+								Script string `yaml:"script"`
+								Env    []struct {
+									Name  string `yaml:"name"`
+									Value string `yaml:"value"`
+								} `yaml:"env"`
+							} `yaml:"steps"`
+						} `yaml:"taskSpec"`
+					} `yaml:"tasks"`
+				} `yaml:"spec"`
+			}
+			if err = yaml.Unmarshal(bb, &pipeline); err != nil {
+				t.Fatalf("invalid pipeline: %v\n%s", err, bb)
+			}
+
+			runAfter := map[string][]string{}
+			var names []string
+			for _, task := range pipeline.Spec.Tasks {
+				names = append(names, task.Name)
+				runAfter[task.Name] = task.RunAfter
+
+				switch task.Name {
+				case "test":
+					step := task.TaskSpec.Steps[0]
+					if step.Image != "golang" || !strings.Contains(step.Script, "go vet") {
+						t.Errorf("unexpected test step: %+v", step)
+					}
+					if len(step.Env) != 1 || step.Env[0].Name != "CGO_ENABLED" || step.Env[0].Value != "0" {
+						t.Errorf("unexpected test envs: %+v", step.Env)
+					}
+				case "scan":
+					if env := task.TaskSpec.Steps[0].Env; len(env) == 0 || env[0].Name != "FUNC_IMAGE" {
+						t.Errorf("expected the image in the scan envs, got %+v", env)
+					}
+				}
+			}
+
+			for name, expected := range map[string][]string{
+				"lint":     {"fetch-sources"},
+				"test":     {"fetch-sources", "lint"},
+				"scaffold": tt.scaffoldsAfter,
+				"build":    tt.buildsAfter,
+				"scan":     {"build"},
+				"deploy":   {"build", "scan"},
+			} {
+				if !reflect.DeepEqual(runAfter[name], expected) {
+					t.Errorf("expected %v to run after %v, got %v (tasks %v)", name, expected, runAfter[name], names)
+				}
+			}
+		})
+	}
+}
@@ -7,6 +7,9 @@ import (
 
 var DeployerImage = "ghcr.io/knative/func-utils:v2"
 
+// hostBuildGoImage provides the Go toolchain used by the host builder task.
+var hostBuildGoImage = "docker.io/library/golang:1.23"
+
 func getBuildpackTask() string {
 	return `apiVersion: tekton.dev/v1
 kind: Task
@@ -374,6 +377,111 @@ spec:
 `, DeployerImage)
 }
 
+func getHostBuildTask() string {
+	return fmt.Sprintf(`apiVersion: tekton.dev/v1
+kind: Task
+metadata:
+  name: func-build-host
+  labels:
+    app.kubernetes.io/version: "0.1"
+  annotations:
+    tekton.dev/pipelines.minVersion: "0.17.0"
+    tekton.dev/categories: Image Build
+    tekton.dev/tags: image-build
+    tekton.dev/platforms: "linux/amd64"
+spec:
+  description: >-
+    The Knative Functions Host Build task builds source into a multi-architecture
+    container image and pushes it to a registry, using the daemonless host (OCI) builder.
+
+    The language toolchain is taken from the GO_IMAGE, and no container engine is
+    required.
+  params:
+    - name: IMAGE
+      description: Reference of the image the host builder will produce.
+    - name: REGISTRY
+      description: The registry associated with the function image.
+      default: ""
+    - name: PATH_CONTEXT
+      description: The location of the function within the "source" workspace.
+      default: .
+    - name: ENV_VARS
+      type: array
+      description: Environment variables to set during _build-time_.
+      default: []
+    - name: INSECURE
+      description: Skip the verification of the TLS certificate of the registry.
+      default: "false"
+    - name: GO_IMAGE
+      description: The image providing the Go toolchain at /usr/local/go.
+      default: %s
+  workspaces:
+    - name: source
+    - name: cache
+      description: Directory where the Go build and module caches are stored.
+      optional: true
+    - name: dockerconfig
+      description: >-
+        An optional workspace that allows providing a .docker/config.json file
+        for the host builder to access the container registry.
+        The file should be placed at the root of the Workspace with name config.json.
+      optional: true
+  results:
+    - name: IMAGE_DIGEST
+      description: Digest of the image just built.
+  steps:
+    - name: prepare
+      image: $(params.GO_IMAGE)
+      script: |
+        #!/usr/bin/env bash
+        set -e
+        cp -R /usr/local/go /tools/go
+        cp -RL /etc/ssl/certs /tools/certs
+      volumeMounts:
+        - mountPath: /tools
+          name: tools
+    - name: build
+      image: %s
+      workingDir: $(workspaces.source.path)
+      command:
+        - /func-util
+        - host-build
+        - "--path-context"
+        - $(params.PATH_CONTEXT)
+        - "--image"
+        - $(params.IMAGE)
+        - "--registry"
+        - $(params.REGISTRY)
+        - "--digest-file"
+        - $(results.IMAGE_DIGEST.path)
+        - "--insecure=$(params.INSECURE)"
+        - $(params.ENV_VARS[*])
+      env:
+        - name: FUNC_GO_PATH
+          value: /tools/go/bin/go
+        - name: GOCACHE
+          value: $(workspaces.cache.path)/go-build
+        - name: GOMODCACHE
+          value: $(workspaces.cache.path)/go-mod
+        - name: GOPATH
+          value: /tools/gopath
+        - name: GOFLAGS
+          value: -buildvcs=false
+        - name: HOME
+          value: /tekton/home
+        - name: SSL_CERT_DIR
+          value: /tools/certs
+        - name: DOCKER_CONFIG
+          value: $(workspaces.dockerconfig.path)
+      volumeMounts:
+        - mountPath: /tools
+          name: tools
+  volumes:
+    - emptyDir: {}
+      name: tools
+`, hostBuildGoImage, DeployerImage)
+}
+
 func getDeployTask() string {
 	return fmt.Sprintf(`apiVersion: tekton.dev/v1
 kind: Task
@@ -396,6 +504,9 @@ spec:
     - name: image
       description: Container image to be deployed
       default: ""
+    - name: environment
+      description: Named environment of the function to deploy to
+      default: ""
   workspaces:
     - name: source
       description: The workspace containing the function project
@@ -403,6 +514,9 @@ spec:
     - name: func-deploy
       image: "%s"
       command: ["deploy", "$(params.path)", "$(params.image)"]
+      env:
+        - name: FUNC_ENVIRONMENT
+          value: $(params.environment)
 `, DeployerImage)
 }
 
@@ -435,7 +549,7 @@ spec:
 
 // GetClusterTasks returns multi-document yaml containing tekton tasks used by func.
 func GetClusterTasks() string {
-	tasks := getBuildpackTask() + "\n---\n" + getS2ITask() + "\n---\n" + getDeployTask() + "\n---\n" + getScaffoldTask()
+	tasks := getBuildpackTask() + "\n---\n" + getS2ITask() + "\n---\n" + getHostBuildTask() + "\n---\n" + getDeployTask() + "\n---\n" + getScaffoldTask()
 	tasks = strings.Replace(tasks, "kind: Task", "kind: ClusterTask", -1)
 	tasks = strings.ReplaceAll(tasks, "apiVersion: tekton.dev/v1", "apiVersion: tekton.dev/v1beta1")
 	return tasks
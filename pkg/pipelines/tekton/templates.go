@@ -2,6 +2,7 @@ package tekton
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -81,11 +82,18 @@ type templateData struct {
 	Registry      string
 	BuilderImage  string
 	BuildEnvs     []string
+	Environment   string
+
+	// RegistryInsecure skips the verification of the registry's TLS
+	// certificate when pushing the image built by the host builder.
+	RegistryInsecure bool
 
 	PipelineName    string
 	PipelineRunName string
 	PvcName         string
 	SecretName      string
+	CachePvcName    string
+	CacheSubPath    string
 
 	// The branch or tag we are targeting with Pipelines (ie: main, refs/tags/*)
 	PipelinesTargetBranch string
@@ -98,16 +106,32 @@ type templateData struct {
 	GitCloneTaskRef       string
 	FuncBuildpacksTaskRef string
 	FuncS2iTaskRef        string
+	FuncHostBuildTaskRef  string
 	FuncDeployTaskRef     string
 	FuncScaffoldTaskRef   string
 
 	// Reference for build task - whether it should run after fetch-sources task or not
 	RunAfterFetchSources string
 
+	// User defined stages, which precede the build and deploy tasks, and
+	// the references of those tasks to the tasks they run after
+	PreBuildStages   string
+	PreDeployStages  string
+	ScaffoldRunAfter string
+	BuildRunAfter    string
+	DeployRunAfter   string
+
 	PipelineYamlURL string
 
 	// S2I related properties
 	S2iImageScriptsUrl string
+
+	// CI workflows related properties (see GenerateCI)
+	Builder       string
+	Runtime       string
+	DeployArgs    string
+	FuncBinaryURL string
+	CIImage       string
 }
 
 // createPipelineTemplatePAC creates a Pipeline template used for PAC on-cluster build
@@ -128,6 +152,7 @@ func createPipelineTemplatePAC(f fn.Function, labels map[string]string) error {
 	}{
 		{getBuildpackTask(), &data.FuncBuildpacksTaskRef},
 		{getS2ITask(), &data.FuncS2iTaskRef},
+		{getHostBuildTask(), &data.FuncHostBuildTaskRef},
 		{getDeployTask(), &data.FuncDeployTaskRef},
 		{getScaffoldTask(), &data.FuncScaffoldTaskRef},
 	} {
@@ -137,12 +162,17 @@ func createPipelineTemplatePAC(f fn.Function, labels map[string]string) error {
 		}
 		*val.field = ts
 	}
+	if err := data.setStages(f); err != nil {
+		return err
+	}
 
 	var template string
 	if f.Build.Builder == builders.Pack {
 		template = packPipelineTemplate
 	} else if f.Build.Builder == builders.S2I {
 		template = s2iPipelineTemplate
+	} else if f.Build.Builder == builders.Host {
+		template = hostPipelineTemplate
 	} else {
 		return builders.ErrBuilderNotSupported{Builder: f.Build.Builder}
 	}
@@ -165,14 +195,7 @@ func createPipelineRunTemplatePAC(f fn.Function, labels map[string]string) error
 		pipelinesTargetBranch = defaultPipelinesTargetBranch
 	}
 
-	buildEnvs := []string{}
-	if len(f.Build.BuildEnvs) == 0 {
-		buildEnvs = []string{"="}
-	} else {
-		for i := range f.Build.BuildEnvs {
-			buildEnvs = append(buildEnvs, f.Build.BuildEnvs[i].KeyValuePair())
-		}
-	}
+	buildEnvs := getBuildEnvs(f)
 
 	s2iImageScriptsUrl := defaultS2iImageScriptsUrl
 	if f.Runtime == "quarkus" {
@@ -184,6 +207,10 @@ func createPipelineRunTemplatePAC(f fn.Function, labels map[string]string) error
 		image = f.Image
 	}
 
+	// The cluster on which the PipelineRun is run is not known, so the
+	// caches are kept on the pipeline PVC, as any Tekton allows.
+	cacheClaim, cacheSubPath := cacheWorkspace(f, false)
+
 	data := templateData{
 		FunctionName:  f.Name,
 		Annotations:   f.Deploy.Annotations,
@@ -193,11 +220,14 @@ func createPipelineRunTemplatePAC(f fn.Function, labels map[string]string) error
 		Registry:      f.Registry,
 		BuilderImage:  getBuilderImage(f),
 		BuildEnvs:     buildEnvs,
+		Environment:   f.Environment,
 
 		PipelineName:    getPipelineName(f),
 		PipelineRunName: fmt.Sprintf("%s-run", getPipelineName(f)),
 		PvcName:         getPipelinePvcName(f),
 		SecretName:      getPipelineSecretName(f),
+		CachePvcName:    cacheClaim,
+		CacheSubPath:    cacheSubPath,
 
 		PipelinesTargetBranch: pipelinesTargetBranch,
 
@@ -216,6 +246,8 @@ func createPipelineRunTemplatePAC(f fn.Function, labels map[string]string) error
 		template = packRunTemplatePAC
 	} else if f.Build.Builder == builders.S2I {
 		template = s2iRunTemplatePAC
+	} else if f.Build.Builder == builders.Host {
+		template = hostRunTemplatePAC
 	} else {
 		return builders.ErrBuilderNotSupported{Builder: f.Build.Builder}
 	}
@@ -303,7 +335,7 @@ func getTaskSpec(taskYaml string) (string, error) {
 
 // createAndApplyPipelineTemplate creates and applies Pipeline template for a standard on-cluster build
 // all resources are created on the fly, if there's a Pipeline defined in the project directory, it is used instead
-func createAndApplyPipelineTemplate(f fn.Function, namespace string, labels map[string]string) error {
+func createAndApplyPipelineTemplate(ctx context.Context, f fn.Function, namespace string, labels map[string]string) error {
 	// If Git is set up create fetch task and reference it from build task,
 	// otherwise sources have been already uploaded to workspace PVC.
 	gitCloneTaskRef := ""
@@ -328,6 +360,7 @@ func createAndApplyPipelineTemplate(f fn.Function, namespace string, labels map[
 	}{
 		{getBuildpackTask(), &data.FuncBuildpacksTaskRef},
 		{getS2ITask(), &data.FuncS2iTaskRef},
+		{getHostBuildTask(), &data.FuncHostBuildTaskRef},
 		{getDeployTask(), &data.FuncDeployTaskRef},
 		{getScaffoldTask(), &data.FuncScaffoldTaskRef},
 	} {
@@ -337,22 +370,29 @@ func createAndApplyPipelineTemplate(f fn.Function, namespace string, labels map[
 		}
 		*val.field = ts
 	}
+	if err := data.setStages(f); err != nil {
+		return err
+	}
 
 	var template string
 	if f.Build.Builder == builders.Pack {
 		template = packPipelineTemplate
 	} else if f.Build.Builder == builders.S2I {
 		template = s2iPipelineTemplate
+	} else if f.Build.Builder == builders.Host {
+		template = hostPipelineTemplate
 	} else {
 		return builders.ErrBuilderNotSupported{Builder: f.Build.Builder}
 	}
 
-	return createAndApplyResource(f.Root, pipelineFileName, template, "pipeline", getPipelineName(f), namespace, data)
+	return createAndApplyResource(ctx, f.Root, pipelineFileName, template, "pipeline", getPipelineName(f), namespace, data)
 }
 
 // createAndApplyPipelineRunTemplate creates and applies PipelineRun template for a standard on-cluster build
 // all resources are created on the fly, if there's a PipelineRun defined in the project directory, it is used instead
-func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels map[string]string) error {
+// The build caches are kept on their own PVC if separateCache, otherwise on the pipeline PVC.
+// The TLS certificate of the registry is not verified by the host builder if registryInsecure.
+func createAndApplyPipelineRunTemplate(ctx context.Context, f fn.Function, namespace string, labels map[string]string, separateCache, registryInsecure bool) error {
 	contextDir := f.Build.Git.ContextDir
 	if contextDir == "" && f.Build.Builder == builders.S2I {
 		// TODO(lkingland): could instead update S2I to interpret empty string
@@ -365,20 +405,15 @@ func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels m
 		pipelinesTargetBranch = defaultPipelinesTargetBranch
 	}
 
-	buildEnvs := []string{}
-	if len(f.Build.BuildEnvs) == 0 {
-		buildEnvs = []string{"="}
-	} else {
-		for i := range f.Build.BuildEnvs {
-			buildEnvs = append(buildEnvs, f.Build.BuildEnvs[i].KeyValuePair())
-		}
-	}
+	buildEnvs := getBuildEnvs(f)
 
 	s2iImageScriptsUrl := defaultS2iImageScriptsUrl
 	if f.Runtime == "quarkus" {
 		s2iImageScriptsUrl = quarkusS2iImageScriptsUrl
 	}
 
+	cacheClaim, cacheSubPath := cacheWorkspace(f, separateCache)
+
 	data := templateData{
 		FunctionName:  f.Name,
 		Annotations:   f.Deploy.Annotations,
@@ -388,14 +423,19 @@ func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels m
 		Registry:      f.Registry,
 		BuilderImage:  getBuilderImage(f),
 		BuildEnvs:     buildEnvs,
+		Environment:   f.Environment,
 
 		PipelineName:    getPipelineName(f),
 		PipelineRunName: getPipelineRunGenerateName(f),
 		PvcName:         getPipelinePvcName(f),
 		SecretName:      getPipelineSecretName(f),
+		CachePvcName:    cacheClaim,
+		CacheSubPath:    cacheSubPath,
 
 		S2iImageScriptsUrl: s2iImageScriptsUrl,
 
+		RegistryInsecure: registryInsecure,
+
 		RepoUrl:  f.Build.Git.URL,
 		Revision: pipelinesTargetBranch,
 	}
@@ -405,11 +445,13 @@ func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels m
 		template = packRunTemplate
 	} else if f.Build.Builder == builders.S2I {
 		template = s2iRunTemplate
+	} else if f.Build.Builder == builders.Host {
+		template = hostRunTemplate
 	} else {
 		return builders.ErrBuilderNotSupported{Builder: f.Build.Builder}
 	}
 
-	return createAndApplyResource(f.Root, pipelineFileName, template, "pipelinerun", getPipelineRunGenerateName(f), namespace, data)
+	return createAndApplyResource(ctx, f.Root, pipelineFileName, template, "pipelinerun", getPipelineRunGenerateName(f), namespace, data)
 }
 
 // allows simple mocking in unit tests
@@ -417,7 +459,7 @@ var manifestivalClient = k8s.GetManifestivalClient
 
 // createAndApplyResource tries to create and apply a resource to the k8s cluster from the input template and data,
 // if there's the same resource already created in the project directory, it is used instead
-func createAndApplyResource(projectRoot, fileName, fileTemplate, kind, resourceName, namespace string, data interface{}) error {
+func createAndApplyResource(ctx context.Context, projectRoot, fileName, fileTemplate, kind, resourceName, namespace string, data interface{}) error {
 	var source manifestival.Source
 
 	filePath := path.Join(projectRoot, resourcesDirectory, fileName)
@@ -437,7 +479,7 @@ func createAndApplyResource(projectRoot, fileName, fileTemplate, kind, resourceN
 		source = manifestival.Reader(&buf)
 	}
 
-	client, err := manifestivalClient()
+	client, err := manifestivalClient(ctx)
 	if err != nil {
 		return fmt.Errorf("error generating template: %v", err)
 	}
@@ -476,3 +518,20 @@ func createAndApplyResource(projectRoot, fileName, fileTemplate, kind, resourceN
 
 	return m.Apply()
 }
+
+// getBuildEnvs returns the build environment variables of the function, with
+// those directing s2i builds to the cache.  The array parameter of the build
+// tasks may not be empty, so holds a placeholder if there are none.
+func getBuildEnvs(f fn.Function) []string {
+	buildEnvs := []string{}
+	for i := range f.Build.BuildEnvs {
+		buildEnvs = append(buildEnvs, f.Build.BuildEnvs[i].KeyValuePair())
+	}
+	if f.Build.Builder == builders.S2I {
+		buildEnvs = append(buildEnvs, S2ICacheEnvs(f)...)
+	}
+	if len(buildEnvs) == 0 {
+		buildEnvs = []string{"="}
+	}
+	return buildEnvs
+}
@@ -0,0 +1,214 @@
+package tekton
+
+const (
+	// hostPipelineTemplate contains the host builder template used for both Tekton standard and PAC Pipeline
+	hostPipelineTemplate = `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  labels:
+    {{range $key, $value := .Labels -}}
+     "{{$key}}": "{{$value}}"
+    {{end}}
+  annotations:
+    {{range $key, $value := .Annotations -}}
+     "{{$key}}": "{{$value}}"
+    {{end}}
+  name: {{.PipelineName}}
+spec:
+  params:
+    - default: ''
+      description: Git repository that hosts the function project
+      name: gitRepository
+      type: string
+    - description: Git revision to build
+      name: gitRevision
+      type: string
+    - default: ''
+      description: Path where the function project is
+      name: contextDir
+      type: string
+    - description: Function image name
+      name: imageName
+      type: string
+    - description: The registry associated with the function image
+      name: registry
+      type: string
+    - description: Environment variables to set during build time
+      name: buildEnvs
+      type: array
+    - default: 'false'
+      description: Skip the verification of the TLS certificate of the registry
+      name: registryInsecure
+      type: string
+    - default: ''
+      description: Named environment of the function to deploy to
+      name: environment
+      type: string
+  tasks:
+    {{.GitCloneTaskRef}}
+    {{.PreBuildStages}}
+    - name: build
+      params:
+        - name: IMAGE
+          value: $(params.imageName)
+        - name: REGISTRY
+          value: $(params.registry)
+        - name: PATH_CONTEXT
+          value: $(params.contextDir)
+        - name: ENV_VARS
+          value:
+            - '$(params.buildEnvs[*])'
+        - name: INSECURE
+          value: $(params.registryInsecure)
+      {{.BuildRunAfter}}
+      {{.FuncHostBuildTaskRef}}
+      workspaces:
+        - name: source
+          workspace: source-workspace
+        - name: cache
+          workspace: cache-workspace
+        - name: dockerconfig
+          workspace: dockerconfig-workspace
+    {{.PreDeployStages}}
+    - name: deploy
+      params:
+        - name: path
+          value: $(workspaces.source.path)/$(params.contextDir)
+        - name: image
+          value: $(params.imageName)@$(tasks.build.results.IMAGE_DIGEST)
+        - name: environment
+          value: $(params.environment)
+      {{.DeployRunAfter}}
+      {{.FuncDeployTaskRef}}
+      workspaces:
+        - name: source
+          workspace: source-workspace
+  workspaces:
+    - description: Directory where function source is located.
+      name: source-workspace
+    - description: Directory where build cache is stored.
+      name: cache-workspace
+    - description: Directory containing image registry credentials stored in config.json file.
+      name: dockerconfig-workspace
+      optional: true
+`
+	// hostRunTemplate contains the host builder template used for Tekton standard PipelineRun
+	hostRunTemplate = `
+apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  labels:
+    {{range $key, $value := .Labels -}}
+     "{{$key}}": "{{$value}}"
+    {{end}}
+    tekton.dev/pipeline: {{.PipelineName}}
+  annotations:
+    # User defined Annotations
+    {{range $key, $value := .Annotations -}}
+     "{{$key}}": "{{$value}}"
+    {{end}}
+  generateName: {{.PipelineRunName}}
+spec:
+  params:
+    - name: gitRepository
+      value: {{.RepoUrl}}
+    - name: gitRevision
+      value: {{.Revision}}
+    - name: contextDir
+      value: {{.ContextDir}}
+    - name: imageName
+      value: {{.FunctionImage}}
+    - name: registry
+      value: {{.Registry}}
+    - name: buildEnvs
+      value:
+        {{range .BuildEnvs -}}
+           - {{.}}
+        {{end}}
+    - name: environment
+      value: "{{.Environment}}"
+    - name: registryInsecure
+      value: "{{.RegistryInsecure}}"
+  pipelineRef:
+   name: {{.PipelineName}}
+  workspaces:
+    - name: source-workspace
+      persistentVolumeClaim:
+        claimName: {{.PvcName}}
+      subPath: source
+    - name: cache-workspace
+      persistentVolumeClaim:
+        claimName: {{.CachePvcName}}
+      subPath: {{.CacheSubPath}}
+    - name: dockerconfig-workspace
+      secret:
+        secretName: {{.SecretName}}
+`
+	// hostRunTemplatePAC contains the host builder template used for the Tekton PAC PipelineRun
+	hostRunTemplatePAC = `
+apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  labels:
+    {{range $key, $value := .Labels -}}
+     "{{$key}}": "{{$value}}"
+    {{end}}
+    tekton.dev/pipeline: {{.PipelineName}}
+  annotations:
+    # The event we are targeting as seen from the webhook payload
+    # this can be an array too, i.e: [pull_request, push]
+    pipelinesascode.tekton.dev/on-event: "[push]"
+
+    # The branch or tag we are targeting (ie: main, refs/tags/*)
+    pipelinesascode.tekton.dev/on-target-branch: "[{{.PipelinesTargetBranch}}]"
+
+    # Fetch the git-clone task from hub
+    pipelinesascode.tekton.dev/task: {{.GitCloneTaskRef}}
+
+    # Fetch the pipelie definition from the .tekton directory
+    pipelinesascode.tekton.dev/pipeline: {{.PipelineYamlURL}}
+
+    # How many runs we want to keep attached to this event
+    pipelinesascode.tekton.dev/max-keep-runs: "5"
+
+    # User defined Annotations
+    {{range $key, $value := .Annotations -}}
+     "{{$key}}": "{{$value}}"
+    {{end}}
+  generateName: {{.PipelineRunName}}
+spec:
+  params:
+    - name: gitRepository
+      value: {{.RepoUrl}}
+    - name: gitRevision
+      value: {{.Revision}}
+    - name: contextDir
+      value: {{.ContextDir}}
+    - name: imageName
+      value: {{.FunctionImage}}
+    - name: registry
+      value: {{.Registry}}
+    - name: buildEnvs
+      value:
+        {{range .BuildEnvs -}}
+           - {{.}}
+        {{end}}
+    - name: environment
+      value: "{{.Environment}}"
+  pipelineRef:
+   name: {{.PipelineName}}
+  workspaces:
+    - name: source-workspace
+      persistentVolumeClaim:
+        claimName: {{.PvcName}}
+      subPath: source
+    - name: cache-workspace
+      persistentVolumeClaim:
+        claimName: {{.CachePvcName}}
+      subPath: {{.CacheSubPath}}
+    - name: dockerconfig-workspace
+      secret:
+        secretName: {{.SecretName}}
+`
+)
@@ -3,6 +3,7 @@
 package tekton
 
 import (
+	"context"
 	"testing"
 
 	"github.com/manifestival/manifestival"
@@ -19,7 +20,7 @@ func Test_createAndApplyPipelineTemplate(t *testing.T) {
 			old := manifestivalClient
 			defer func() { manifestivalClient = old }()
 
-			manifestivalClient = func() (manifestival.Client, error) {
+			manifestivalClient = func(context.Context) (manifestival.Client, error) {
 				return fake.New(), nil
 			}
 
@@ -36,7 +37,7 @@ func Test_createAndApplyPipelineTemplate(t *testing.T) {
 			f.Image = "docker.io/alice/" + f.Name
 			f.Registry = TestRegistry
 
-			if err := createAndApplyPipelineTemplate(f, tt.namespace, tt.labels); (err != nil) != tt.wantErr {
+			if err := createAndApplyPipelineTemplate(context.Background(), f, tt.namespace, tt.labels); (err != nil) != tt.wantErr {
 				t.Errorf("createAndApplyPipelineTemplate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
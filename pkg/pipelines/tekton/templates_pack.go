@@ -40,8 +40,13 @@ spec:
     - description: Environment variables to set during build time
       name: buildEnvs
       type: array
+    - default: ''
+      description: Named environment of the function to deploy to
+      name: environment
+      type: string
   tasks:
     {{.GitCloneTaskRef}}
+    {{.PreBuildStages}}
     - name: scaffold
       params:
         - name: path
@@ -49,7 +54,7 @@ spec:
       workspaces:
         - name: source
           workspace: source-workspace
-      {{.RunAfterFetchSources}}
+      {{.ScaffoldRunAfter}}
       {{.FuncScaffoldTaskRef}}
     - name: build
       params:
@@ -64,8 +69,7 @@ spec:
         - name: ENV_VARS
           value:
             - '$(params.buildEnvs[*])'
-      runAfter:
-        - scaffold
+      {{.BuildRunAfter}}
       {{.FuncBuildpacksTaskRef}}
       workspaces:
         - name: source
@@ -74,14 +78,16 @@ spec:
           workspace: cache-workspace
         - name: dockerconfig
           workspace: dockerconfig-workspace
+    {{.PreDeployStages}}
     - name: deploy
       params:
         - name: path
           value: $(workspaces.source.path)/$(params.contextDir)
         - name: image
           value: $(params.imageName)@$(tasks.build.results.IMAGE_DIGEST)
-      runAfter:
-        - build
+        - name: environment
+          value: $(params.environment)
+      {{.DeployRunAfter}}
       {{.FuncDeployTaskRef}}
       workspaces:
         - name: source
@@ -131,6 +137,8 @@ spec:
         {{range .BuildEnvs -}}
            - {{.}}
         {{end}}
+    - name: environment
+      value: "{{.Environment}}"
   pipelineRef:
    name: {{.PipelineName}}
   workspaces:
@@ -140,8 +148,8 @@ spec:
       subPath: source
     - name: cache-workspace
       persistentVolumeClaim:
-        claimName: {{.PvcName}}
-      subPath: cache
+        claimName: {{.CachePvcName}}
+      subPath: {{.CacheSubPath}}
     - name: dockerconfig-workspace
       secret:
         secretName: {{.SecretName}}
@@ -194,6 +202,8 @@ spec:
         {{range .BuildEnvs -}}
            - {{.}}
         {{end}}
+    - name: environment
+      value: "{{.Environment}}"
   pipelineRef:
    name: {{.PipelineName}}
   workspaces:
@@ -203,8 +213,8 @@ spec:
       subPath: source
     - name: cache-workspace
       persistentVolumeClaim:
-        claimName: {{.PvcName}}
-      subPath: cache
+        claimName: {{.CachePvcName}}
+      subPath: {{.CacheSubPath}}
     - name: dockerconfig-workspace
       secret:
         secretName: {{.SecretName}}
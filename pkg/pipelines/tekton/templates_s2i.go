@@ -44,8 +44,13 @@ spec:
       name: s2iImageScriptsUrl
       type: string
       default: 'image:///usr/libexec/s2i'
+    - default: ''
+      description: Named environment of the function to deploy to
+      name: environment
+      type: string
   tasks:
     {{.GitCloneTaskRef}}
+    {{.PreBuildStages}}
     - name: scaffold
       params:
         - name: path
@@ -53,7 +58,7 @@ spec:
       workspaces:
         - name: source
           workspace: source-workspace
-      {{.RunAfterFetchSources}}
+      {{.ScaffoldRunAfter}}
       {{.FuncScaffoldTaskRef}}
     - name: build
       params:
@@ -70,8 +75,7 @@ spec:
             - '$(params.buildEnvs[*])'
         - name: S2I_IMAGE_SCRIPTS_URL
           value: $(params.s2iImageScriptsUrl)
-      runAfter:
-        - scaffold
+      {{.BuildRunAfter}}
       {{.FuncS2iTaskRef}}
       workspaces:
         - name: source
@@ -80,14 +84,16 @@ spec:
           workspace: cache-workspace
         - name: dockerconfig
           workspace: dockerconfig-workspace
+    {{.PreDeployStages}}
     - name: deploy
       params:
         - name: path
           value: $(workspaces.source.path)/$(params.contextDir)
         - name: image
           value: $(params.imageName)@$(tasks.build.results.IMAGE_DIGEST)
-      runAfter:
-        - build
+        - name: environment
+          value: $(params.environment)
+      {{.DeployRunAfter}}
       {{.FuncDeployTaskRef}}
       workspaces:
         - name: source
@@ -136,6 +142,8 @@ spec:
         {{range .BuildEnvs -}}
            - {{.}}
         {{end}}
+    - name: environment
+      value: "{{.Environment}}"
     - name: s2iImageScriptsUrl
       value: {{.S2iImageScriptsUrl}}
   pipelineRef:
@@ -147,8 +155,8 @@ spec:
       subPath: source
     - name: cache-workspace
       persistentVolumeClaim:
-        claimName: {{.PvcName}}
-      subPath: cache
+        claimName: {{.CachePvcName}}
+      subPath: {{.CacheSubPath}}
     - name: dockerconfig-workspace
       secret:
         secretName: {{.SecretName}}
@@ -204,6 +212,8 @@ spec:
         {{range .BuildEnvs -}}
            - {{.}}
         {{end}}
+    - name: environment
+      value: "{{.Environment}}"
     - name: s2iImageScriptsUrl
       value: {{.S2iImageScriptsUrl}}
   pipelineRef:
@@ -215,8 +225,8 @@ spec:
       subPath: source
     - name: cache-workspace
       persistentVolumeClaim:
-        claimName: {{.PvcName}}
-      subPath: cache
+        claimName: {{.CachePvcName}}
+      subPath: {{.CacheSubPath}}
     - name: dockerconfig-workspace
       secret:
         secretName: {{.SecretName}}
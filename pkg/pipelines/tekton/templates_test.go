@@ -1,11 +1,16 @@
 package tekton
 
 import (
+	"bytes"
+	"context"
 	"path/filepath"
+	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/manifestival/manifestival"
 	"github.com/manifestival/manifestival/fake"
+	"gopkg.in/yaml.v3"
 
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
@@ -38,6 +43,12 @@ func Test_createPipelineTemplatePAC(t *testing.T) {
 			builder: builders.S2I,
 			wantErr: false,
 		},
+		{
+			name:    "correct - host builder",
+			root:    "testdata/testCreatePipelineTemplateHost",
+			builder: builders.Host,
+			wantErr: false,
+		},
 		{
 			name:    "incorrect - foo builder",
 			root:    "testdata/testCreatePipelineTemplateFoo",
@@ -100,6 +111,12 @@ func Test_createPipelineRunTemplatePAC(t *testing.T) {
 			builder: builders.S2I,
 			wantErr: false,
 		},
+		{
+			name:    "correct - host builder",
+			root:    "testdata/testCreatePipelineRunTemplateHost",
+			builder: builders.Host,
+			wantErr: false,
+		},
 		{
 			name:    "incorrect - foo builder",
 			root:    "testdata/testCreatePipelineRunTemplateFoo",
@@ -265,6 +282,14 @@ var testData = []struct {
 		namespace: "test-ns",
 		wantErr:   false,
 	},
+	{
+		name:      "correct - host & go",
+		root:      "testdata/testCreatePipelineHostGo",
+		runtime:   "go",
+		builder:   builders.Host,
+		namespace: "test-ns",
+		wantErr:   false,
+	},
 }
 
 func Test_createAndApplyPipelineRunTemplate(t *testing.T) {
@@ -274,7 +299,7 @@ func Test_createAndApplyPipelineRunTemplate(t *testing.T) {
 			old := manifestivalClient
 			defer func() { manifestivalClient = old }()
 
-			manifestivalClient = func() (manifestival.Client, error) {
+			manifestivalClient = func(context.Context) (manifestival.Client, error) {
 				return fake.New(), nil
 			}
 
@@ -291,9 +316,72 @@ func Test_createAndApplyPipelineRunTemplate(t *testing.T) {
 			f.Image = "docker.io/alice/" + f.Name
 			f.Registry = TestRegistry
 
-			if err := createAndApplyPipelineRunTemplate(f, tt.namespace, tt.labels); (err != nil) != tt.wantErr {
+			if err := createAndApplyPipelineRunTemplate(context.Background(), f, tt.namespace, tt.labels, true, false); (err != nil) != tt.wantErr {
 				t.Errorf("createAndApplyPipelineRunTemplate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+// Test_runTemplatesEnvironment ensures that each PipelineRun passes the
+// function's applied environment to its Pipeline, such that it is deployed
+// to that environment.
+func Test_runTemplatesEnvironment(t *testing.T) {
+	templates := map[string]string{
+		"pack":     packRunTemplate,
+		"pack-pac": packRunTemplatePAC,
+		"s2i":      s2iRunTemplate,
+		"s2i-pac":  s2iRunTemplatePAC,
+		"host":     hostRunTemplate,
+		"host-pac": hostRunTemplatePAC,
+	}
+	for name, tpl := range templates {
+		t.Run(name, func(t *testing.T) {
+			params := runParams(t, tpl, templateData{Environment: "staging"})
+			if params["environment"] != "staging" {
+				t.Fatalf("expected environment 'staging', got %v", params["environment"])
+			}
+		})
+	}
+}
+
+// Test_hostTemplatesRegistryInsecure ensures that the host builder is told to
+// skip the verification of the registry's TLS certificate when requested.
+func Test_hostTemplatesRegistryInsecure(t *testing.T) {
+	params := runParams(t, hostRunTemplate, templateData{RegistryInsecure: true})
+	if params["registryInsecure"] != "true" {
+		t.Fatalf("expected registryInsecure 'true', got %v", params["registryInsecure"])
+	}
+	if !strings.Contains(hostPipelineTemplate, "- name: INSECURE\n          value: $(params.registryInsecure)") {
+		t.Fatal("expected the pipeline to pass registryInsecure to the build task")
+	}
+	if !strings.Contains(getHostBuildTask(), `"--insecure=$(params.INSECURE)"`) {
+		t.Fatal("expected the build task to pass INSECURE to the host builder")
+	}
+}
+
+// runParams renders the PipelineRun template with the given data, returning
+// the values of its params by name.
+func runParams(t *testing.T, tpl string, data templateData) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := template.Must(template.New("run").Parse(tpl)).Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	var run struct {
+		Spec struct {
+			Params []struct {
+				Name  string `yaml:"name"`
+				Value any    `yaml:"value"`
+			} `yaml:"params"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(buf.Bytes(), &run); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]any{}
+	for _, p := range run.Spec.Params {
+		params[p.Name] = p.Value
+	}
+	return params
+}
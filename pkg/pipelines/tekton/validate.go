@@ -36,6 +36,13 @@ func validatePipeline(f fn.Function) error {
 	} else if f.Build.Builder == builders.S2I {
 		_, err := s2i.BuilderImage(f, builders.S2I)
 		return err
+	} else if f.Build.Builder == builders.Host {
+		if f.Runtime == "" {
+			return ErrRuntimeRequired
+		}
+		if f.Runtime != "go" {
+			return ErrRuntimeNotSupported{Runtime: f.Runtime}
+		}
 	} else {
 		return builders.ErrUnknownBuilder{Name: f.Build.Builder}
 	}
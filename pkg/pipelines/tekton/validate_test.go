@@ -104,6 +104,21 @@ func Test_validatePipeline(t *testing.T) {
 			function: fn.Function{Build: fn.BuildSpec{Builder: builders.S2I}, Runtime: "rust"},
 			wantErr:  true,
 		},
+		{
+			name:     "Without runtime - host builder",
+			function: fn.Function{Build: fn.BuildSpec{Builder: builders.Host}},
+			wantErr:  true,
+		},
+		{
+			name:     "Supported runtime - Go - host builder",
+			function: fn.Function{Build: fn.BuildSpec{Builder: builders.Host}, Runtime: "go"},
+			wantErr:  false,
+		},
+		{
+			name:     "Unsupported runtime - Node - host builder",
+			function: fn.Function{Build: fn.BuildSpec{Builder: builders.Host}, Runtime: "node"},
+			wantErr:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,536 @@
+/*
+Package preflight checks, before any changes are made, that a function can
+be deployed: that the cluster is reachable and has the required APIs
+installed, that the target namespace exists and the user has the
+permissions needed to deploy to it, that the function's image can be pushed
+and pulled, and that the resources it references exist.
+*/
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/func/pkg/deployers"
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/pipelines"
+)
+
+// Names of the checks, in the order in which they are reported.
+const (
+	CheckCluster     = "Kubernetes cluster"
+	CheckServing     = "Knative Serving"
+	CheckEventing    = "Knative Eventing"
+	CheckTekton      = "Tekton Pipelines"
+	CheckNamespace   = "Namespace"
+	CheckPermissions = "Permissions"
+	CheckResources   = "Referenced resources"
+	CheckAllowList   = "Allow list"
+	CheckRegistry    = "Registry credentials"
+	CheckPull        = "Image pull"
+)
+
+// Checker runs the preflight checks of a function.
+type Checker struct {
+	credentialsProvider docker.CredentialsProvider
+	transport           http.RoundTripper
+	newClient           func(context.Context) (kubernetes.Interface, error)
+}
+
+var _ fn.Preflighter = (*Checker)(nil)
+
+type Opt func(*Checker)
+
+// NewChecker creates a checker of functions deployed to the cluster of the
+// kubeconfig context selected by the context of the check.
+func NewChecker(opts ...Opt) *Checker {
+	c := &Checker{
+		transport: http.DefaultTransport,
+		newClient: func(ctx context.Context) (kubernetes.Interface, error) { return k8s.NewKubernetesClientset(ctx) },
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// WithCredentialsProvider with which the push credentials of the function's
+// registry are found.  The provider should not prompt for credentials.  If
+// not set, registry credentials are not checked.
+func WithCredentialsProvider(cp docker.CredentialsProvider) Opt {
+	return func(c *Checker) {
+		c.credentialsProvider = cp
+	}
+}
+
+// WithTransport used to connect to registries.
+func WithTransport(t http.RoundTripper) Opt {
+	return func(c *Checker) {
+		c.transport = t
+	}
+}
+
+// Preflight the function.  Checks are made in order, and those depending on
+// a failed check are skipped.  The checks made are those of the scope set on
+// the context with fn.PreflightScopeKey, or all of them if none is set.
+func (c *Checker) Preflight(ctx context.Context, f fn.Function) (report fn.PreflightReport, err error) {
+	platform := f.Deploy.Platform
+	if platform == "" {
+		platform = deployers.Default
+	}
+
+	scope, _ := ctx.Value(fn.PreflightScopeKey{}).(fn.PreflightScope)
+	if scope == fn.PreflightImage {
+		c.checkImage(ctx, f, platform, &report)
+		return
+	}
+	client, namespace, ok := c.checkCluster(ctx, f, platform, &report)
+	c.checkRegistry(ctx, f, platform, &report)
+	if ok && scope != fn.PreflightBeforeBuild {
+		c.checkPull(ctx, client, f, namespace, &report)
+	}
+	return
+}
+
+// checkImage adds only the check that the function's image can be pulled,
+// for use once it has been pushed, the other checks having been made.
+func (c *Checker) checkImage(ctx context.Context, f fn.Function, platform string, report *fn.PreflightReport) {
+	if platform == deployers.Docker && !f.Local.Remote {
+		return
+	}
+	client, _, err := c.connect(ctx)
+	if err != nil {
+		report.Add(CheckCluster, fn.PreflightFail, "%v", err)
+		return
+	}
+	c.checkPull(ctx, client, f, targetNamespace(ctx, f), report)
+}
+
+// checkCluster adds the checks of the cluster and namespace, returning a
+// client and the namespace if the namespace was found.
+func (c *Checker) checkCluster(ctx context.Context, f fn.Function, platform string, report *fn.PreflightReport) (kubernetes.Interface, string, bool) {
+	if platform == deployers.Docker && !f.Local.Remote {
+		report.Add(CheckCluster, fn.PreflightSkip, "not used by the %v platform", platform)
+		return nil, "", false
+	}
+
+	client, version, err := c.connect(ctx)
+	if err != nil {
+		report.Add(CheckCluster, fn.PreflightFail, "%v", err)
+		return nil, "", false
+	}
+	report.Add(CheckCluster, fn.PreflightPass, "%v", version)
+
+	checkAPIs(ctx, client, f, platform, report)
+
+	namespace := targetNamespace(ctx, f)
+	if !checkNamespace(ctx, client, namespace, report) {
+		return nil, "", false
+	}
+	checkPermissions(ctx, client, f, platform, namespace, report)
+	checkResources(ctx, client, f, namespace, report)
+	checkAllowList(f, platform, report)
+	return client, namespace, true
+}
+
+// targetNamespace to which the function is deployed: that requested, else that
+// of its current deployment, else the default of the kubeconfig.
+func targetNamespace(ctx context.Context, f fn.Function) string {
+	if f.Namespace != "" {
+		return f.Namespace
+	}
+	if f.Deploy.Namespace != "" {
+		return f.Deploy.Namespace
+	}
+	namespace, _ := k8s.GetDefaultNamespace(ctx)
+	return namespace
+}
+
+// connect to the cluster, returning a client and a description of the
+// cluster reached.
+func (c *Checker) connect(ctx context.Context) (kubernetes.Interface, string, error) {
+	client, err := c.newClient(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to load the kubeconfig: %w", err)
+	}
+	info, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return nil, "", fmt.Errorf("unreachable: %w", err)
+	}
+	description := "reachable, Kubernetes " + info.GitVersion
+	if raw, err := k8s.GetClientConfig(ctx).RawConfig(); err == nil {
+		name := raw.CurrentContext
+		if k8s.KubeContext(ctx) != "" {
+			name = k8s.KubeContext(ctx)
+		}
+		if name != "" {
+			description = fmt.Sprintf("context %q %v", name, description)
+		}
+	}
+	return client, description, nil
+}
+
+// api is a group version of which the listed resources are required.
+type api struct {
+	groupVersion string
+	resources    []string
+}
+
+// missing returns the resources of the API not served by the cluster.  An
+// error is returned if the API could not be discovered for another reason
+// than it not being installed.
+func (a api) missing(client kubernetes.Interface) ([]string, error) {
+	list, err := client.Discovery().ServerResourcesForGroupVersion(a.groupVersion)
+	if apiErrors.IsNotFound(err) {
+		return a.resources, nil
+	} else if err != nil {
+		return nil, err
+	}
+	served := sets.New[string]()
+	for _, r := range list.APIResources {
+		served.Insert(r.Name)
+	}
+	var missing []string
+	for _, r := range a.resources {
+		if !served.Has(r) {
+			missing = append(missing, r)
+		}
+	}
+	return missing, nil
+}
+
+// checkAPI adds a check that the APIs are installed, with the given version
+// in the message if so.
+func checkAPI(client kubernetes.Interface, name, version string, apis []api, report *fn.PreflightReport) {
+	var missing []string
+	for _, a := range apis {
+		m, err := a.missing(client)
+		if err != nil {
+			report.Add(name, fn.PreflightWarn, "unable to discover %v: %v", a.groupVersion, err)
+			return
+		}
+		for _, r := range m {
+			missing = append(missing, r+"."+strings.Split(a.groupVersion, "/")[0])
+		}
+	}
+	if len(missing) > 0 {
+		report.Add(name, fn.PreflightFail, "not installed, missing %v", strings.Join(missing, ", "))
+		return
+	}
+	if version == "" {
+		report.Add(name, fn.PreflightPass, "installed")
+		return
+	}
+	report.Add(name, fn.PreflightPass, "installed (%v)", version)
+}
+
+// checkAPIs adds checks of the APIs required by the function and platform.
+func checkAPIs(ctx context.Context, client kubernetes.Interface, f fn.Function, platform string, report *fn.PreflightReport) {
+	if platform == deployers.Knative {
+		serving := []api{{"serving.knative.dev/v1", []string{"services", "routes"}}}
+		if len(f.Deploy.Domains) > 0 {
+			serving = append(serving, api{"serving.knative.dev/v1beta1", []string{"domainmappings"}})
+		}
+		if len(f.Deploy.AllowFrom) > 0 && f.Deploy.AllowFromPolicy == fn.AllowFromIstio {
+			serving = append(serving, api{"security.istio.io/v1", []string{"authorizationpolicies"}})
+		}
+		checkAPI(client, CheckServing, releaseVersion(ctx, client, "services.serving.knative.dev"), serving, report)
+	} else {
+		report.Add(CheckServing, fn.PreflightSkip, "not used by the %v platform", platform)
+	}
+
+	if len(f.Deploy.Subscriptions) > 0 {
+		eventing := []api{{"eventing.knative.dev/v1", []string{"triggers"}}}
+		checkAPI(client, CheckEventing, releaseVersion(ctx, client, "triggers.eventing.knative.dev"), eventing, report)
+	} else {
+		report.Add(CheckEventing, fn.PreflightSkip, "no subscriptions")
+	}
+
+	switch {
+	case !f.Local.Remote:
+		report.Add(CheckTekton, fn.PreflightSkip, "not deploying remotely")
+	case f.Local.RemoteBuilder == pipelines.RemoteBuilderJob:
+		report.Add(CheckTekton, fn.PreflightSkip, "not used by the %v remote builder", pipelines.RemoteBuilderJob)
+	default:
+		tekton := []api{{"tekton.dev/v1", []string{"pipelines", "pipelineruns"}}}
+		checkAPI(client, CheckTekton, releaseVersion(ctx, client, "pipelines.tekton.dev"), tekton, report)
+	}
+}
+
+// releaseVersion of the project installing the named CRD, read from its
+// version label.  Empty if the CRD can not be read, as is the case for
+// users without cluster-wide permissions.
+func releaseVersion(ctx context.Context, client kubernetes.Interface, crd string) string {
+	rest := client.Discovery().RESTClient()
+	if rest == nil {
+		return ""
+	}
+	var meta metav1.PartialObjectMetadata
+	err := rest.Get().AbsPath("/apis/apiextensions.k8s.io/v1/customresourcedefinitions", crd).Do(ctx).Into(&meta)
+	if err != nil {
+		return ""
+	}
+	for _, key := range []string{"app.kubernetes.io/version", "serving.knative.dev/release", "eventing.knative.dev/release", "pipeline.tekton.dev/release"} {
+		if v := meta.Labels[key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// checkNamespace adds a check that the namespace exists, returning false if
+// it does not such that checks within it are skipped.
+func checkNamespace(ctx context.Context, client kubernetes.Interface, namespace string, report *fn.PreflightReport) bool {
+	if namespace == "" {
+		report.Add(CheckNamespace, fn.PreflightFail, "no namespace is set and none is defined by the kubeconfig context")
+		return false
+	}
+	_, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		report.Add(CheckNamespace, fn.PreflightPass, "%q exists", namespace)
+	case apiErrors.IsNotFound(err):
+		report.Add(CheckNamespace, fn.PreflightFail, "%q does not exist", namespace)
+		return false
+	case apiErrors.IsForbidden(err):
+		report.Add(CheckNamespace, fn.PreflightWarn, "unable to verify %q exists: not permitted to get namespaces", namespace)
+	default:
+		report.Add(CheckNamespace, fn.PreflightWarn, "unable to verify %q exists: %v", namespace, err)
+	}
+	return true
+}
+
+// permission to perform a verb on a resource of an API group.
+type permission struct {
+	verb, group, resource string
+}
+
+func (p permission) String() string {
+	if p.group == "" {
+		return p.verb + " " + p.resource
+	}
+	return p.verb + " " + p.resource + "." + p.group
+}
+
+// requiredPermissions to deploy the function to the platform: the verbs the
+// deployer calls on each resource.  Resources of the function which are
+// pruned, as they may since have been removed from it, are also listed and
+// deleted.
+func requiredPermissions(f fn.Function, platform string) (pp []permission) {
+	allow := func(group, resource string, verbs ...string) {
+		for _, verb := range verbs {
+			pp = append(pp, permission{verb, group, resource})
+		}
+	}
+	manage := func(group, resource string) {
+		allow(group, resource, "get", "create", "update")
+	}
+	switch platform {
+	case deployers.Knative:
+		manage("serving.knative.dev", "services")
+		allow("serving.knative.dev", "routes", "get")
+		if len(f.Deploy.Subscriptions) > 0 {
+			allow("eventing.knative.dev", "triggers", "create")
+		}
+		if len(f.Deploy.Domains) > 0 {
+			manage("serving.knative.dev", "domainmappings")
+			allow("serving.knative.dev", "domainmappings", "list", "delete")
+		}
+		if len(f.Deploy.AllowFrom) > 0 {
+			manage("networking.k8s.io", "networkpolicies")
+		}
+	case deployers.Kubernetes:
+		manage("apps", "deployments")
+		allow("", "pods", "list")
+		manage("", "services")
+		if s := f.Deploy.Options.Scale; s != nil && s.Max != nil {
+			manage("autoscaling", "horizontalpodautoscalers")
+		}
+		if e := f.Deploy.Expose; e.Gateway != "" {
+			manage("gateway.networking.k8s.io", "httproutes")
+		} else if !e.IsZero() {
+			manage("networking.k8s.io", "ingresses")
+		}
+	}
+	if resources, err := f.Resources(fn.ConfigMapResource); err == nil && len(resources) > 0 {
+		manage("", "configmaps")
+		allow("", "configmaps", "list", "delete")
+	}
+	if resources, err := f.Resources(fn.SecretResource); err == nil && len(resources) > 0 {
+		manage("", "secrets")
+		allow("", "secrets", "list", "delete")
+	}
+	switch {
+	case !f.Local.Remote:
+	case f.Local.RemoteBuilder == pipelines.RemoteBuilderJob:
+		pp = append(pp,
+			permission{"create", "batch", "jobs"},
+			permission{"list", "", "pods"},
+			permission{"create", "", "persistentvolumeclaims"},
+			permission{"create", "", "secrets"})
+	default:
+		pp = append(pp,
+			permission{"create", "tekton.dev", "pipelines"},
+			permission{"create", "tekton.dev", "pipelineruns"},
+			permission{"create", "", "persistentvolumeclaims"},
+			permission{"create", "", "secrets"})
+	}
+	return
+}
+
+// istioRootNamespace in which the AuthorizationPolicy of a function whose
+// allow list is enforced by Istio is managed.
+const istioRootNamespace = "istio-system"
+
+// requiredMeshPermissions to deploy the function to the platform, in the
+// Istio root namespace.
+func requiredMeshPermissions(f fn.Function, platform string) (pp []permission) {
+	if platform == deployers.Knative && len(f.Deploy.AllowFrom) > 0 && f.Deploy.AllowFromPolicy == fn.AllowFromIstio {
+		for _, verb := range []string{"get", "create", "update"} {
+			pp = append(pp, permission{verb, "security.istio.io", "authorizationpolicies"})
+		}
+	}
+	return
+}
+
+// checkPermissions adds a check that the user may perform each verb
+// required to deploy the function, using SelfSubjectAccessReviews.
+func checkPermissions(ctx context.Context, client kubernetes.Interface, f fn.Function, platform, namespace string, report *fn.PreflightReport) {
+	var denied []string
+	required := []struct {
+		namespace   string
+		permissions []permission
+	}{
+		{namespace, requiredPermissions(f, platform)},
+		{istioRootNamespace, requiredMeshPermissions(f, platform)},
+	}
+	for _, r := range required {
+		var deniedIn []string
+		for _, p := range r.permissions {
+			review := &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Namespace: r.namespace,
+						Verb:      p.verb,
+						Group:     p.group,
+						Resource:  p.resource,
+					},
+				},
+			}
+			review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+			if err != nil {
+				report.Add(CheckPermissions, fn.PreflightWarn, "unable to review permissions: %v", err)
+				return
+			}
+			if !review.Status.Allowed {
+				deniedIn = append(deniedIn, p.String())
+			}
+		}
+		if len(deniedIn) > 0 {
+			denied = append(denied, fmt.Sprintf("in %q to %v", r.namespace, strings.Join(deniedIn, ", ")))
+		}
+	}
+	if len(denied) > 0 {
+		report.Add(CheckPermissions, fn.PreflightFail, "not permitted %v", strings.Join(denied, "; "))
+		return
+	}
+	report.Add(CheckPermissions, fn.PreflightPass, "permitted to deploy to %q", namespace)
+}
+
+// checkAllowList adds a check of how the function's allow list is enforced.
+// A NetworkPolicy alone does not restrict requests routed by Knative, which
+// reach the function from its activator and gateways, so is warned of.
+func checkAllowList(f fn.Function, platform string, report *fn.PreflightReport) {
+	switch {
+	case len(f.Deploy.AllowFrom) == 0:
+		report.Add(CheckAllowList, fn.PreflightSkip, "no allow list")
+	case platform != deployers.Knative:
+		report.Add(CheckAllowList, fn.PreflightSkip, "not supported by the %v platform", platform)
+	case f.Deploy.AllowFromPolicy == fn.AllowFromIstio:
+		report.Add(CheckAllowList, fn.PreflightPass, "enforced by Istio and a NetworkPolicy")
+	default:
+		report.Add(CheckAllowList, fn.PreflightWarn, "only direct connections to the pods are restricted, requests routed by Knative are allowed from any client unless allowFromPolicy is %q", fn.AllowFromIstio)
+	}
+}
+
+// checkResources adds a check that the Secrets, ConfigMaps,
+// PersistentVolumeClaims and ServiceAccount referenced by the function
+// exist, other than those managed with the function which are created when
+// it is deployed.
+func checkResources(ctx context.Context, client kubernetes.Interface, f fn.Function, namespace string, report *fn.PreflightReport) {
+	var (
+		secrets    = sets.New[string]()
+		configMaps = sets.New[string]()
+		pvcs       = sets.New[string]()
+	)
+	envs, err := f.RunEnvs()
+	if err == nil {
+		_, _, err = k8s.ProcessEnvs(f.Root, envs, &secrets, &configMaps)
+	}
+	if err == nil {
+		var mounts []corev1.VolumeMount
+		_, mounts, err = k8s.ProcessVolumes(f.Run.Volumes, &secrets, &configMaps, &pvcs)
+		if err == nil {
+			_, _, err = k8s.ProcessContainers(f, mounts, &secrets, &configMaps)
+		}
+	}
+	if err != nil {
+		report.Add(CheckResources, fn.PreflightFail, "%v", err)
+		return
+	}
+	for kind, managed := range map[fn.ResourceKind]sets.Set[string]{fn.SecretResource: secrets, fn.ConfigMapResource: configMaps} {
+		resources, _ := f.Resources(kind)
+		for _, r := range resources {
+			managed.Delete(r.Name)
+		}
+	}
+
+	var missing, unverified []string
+	check := func(kind, name string, err error) {
+		if apiErrors.IsNotFound(err) {
+			missing = append(missing, kind+" "+name)
+		} else if err != nil {
+			unverified = append(unverified, kind+" "+name)
+		}
+	}
+	for _, name := range sets.List(secrets) {
+		_, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		check("Secret", name, err)
+	}
+	for _, name := range sets.List(configMaps) {
+		_, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		check("ConfigMap", name, err)
+	}
+	for _, name := range sets.List(pvcs) {
+		_, err := client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		check("PersistentVolumeClaim", name, err)
+	}
+	if sa := f.Deploy.ServiceAccountName; sa != "" && sa != "default" {
+		_, err := client.CoreV1().ServiceAccounts(namespace).Get(ctx, sa, metav1.GetOptions{})
+		check("ServiceAccount", sa, err)
+	}
+
+	switch {
+	case len(missing) > 0:
+		sort.Strings(missing)
+		report.Add(CheckResources, fn.PreflightFail, "not found in %q: %v", namespace, strings.Join(missing, ", "))
+	case len(unverified) > 0:
+		report.Add(CheckResources, fn.PreflightWarn, "unable to verify %v", strings.Join(unverified, ", "))
+	case secrets.Len()+configMaps.Len()+pvcs.Len() == 0 && (f.Deploy.ServiceAccountName == "" || f.Deploy.ServiceAccountName == "default"):
+		report.Add(CheckResources, fn.PreflightSkip, "none referenced")
+	default:
+		report.Add(CheckResources, fn.PreflightPass, "all present in %q", namespace)
+	}
+}
@@ -0,0 +1,348 @@
+//go:build !integration
+// +build !integration
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// newFakeClient of a cluster with the given API resources and objects,
+// which permits all but the denied resources.
+func newFakeClient(resources []*metav1.APIResourceList, denied map[string]bool, objects ...runtime.Object) *fake.Clientset {
+	client := fake.NewSimpleClientset(objects...)
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = resources
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = !denied[review.Spec.ResourceAttributes.Resource]
+		return true, review, nil
+	})
+	return client
+}
+
+// status of the named check in the report.
+func status(t *testing.T, report fn.PreflightReport, name string) fn.PreflightStatus {
+	t.Helper()
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	t.Fatalf("check %q not found in report:\n%v", name, report)
+	return ""
+}
+
+var knativeServing = &metav1.APIResourceList{
+	GroupVersion: "serving.knative.dev/v1",
+	APIResources: []metav1.APIResource{{Name: "services"}, {Name: "routes"}},
+}
+
+// TestPreflight_Pass ensures a function which can be deployed passes all
+// applicable checks, and inapplicable checks are skipped.
+func TestPreflight_Pass(t *testing.T) {
+	client := newFakeClient([]*metav1.APIResourceList{knativeServing}, nil,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "ns"}})
+	checker := NewChecker()
+	checker.newClient = func(context.Context) (kubernetes.Interface, error) { return client, nil }
+
+	f := fn.Function{Name: "f", Namespace: "ns",
+		Run: fn.RunSpec{Envs: []fn.Env{{Value: ptr("{{ secret:creds }}")}}}}
+	report, err := checker.Preflight(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Failed() {
+		t.Fatalf("unexpected failure:\n%v", report)
+	}
+	for name, expected := range map[string]fn.PreflightStatus{
+		CheckCluster:     fn.PreflightPass,
+		CheckServing:     fn.PreflightPass,
+		CheckEventing:    fn.PreflightSkip,
+		CheckTekton:      fn.PreflightSkip,
+		CheckNamespace:   fn.PreflightPass,
+		CheckPermissions: fn.PreflightPass,
+		CheckResources:   fn.PreflightPass,
+		CheckAllowList:   fn.PreflightSkip,
+		CheckRegistry:    fn.PreflightSkip,
+		CheckPull:        fn.PreflightSkip,
+	} {
+		if s := status(t, report, name); s != expected {
+			t.Errorf("expected check %q to %v, got %v", name, expected, s)
+		}
+	}
+}
+
+// TestPreflight_Scope ensures that only the check of the image is made once
+// it is pushed, and that all others are made before it is built.
+func TestPreflight_Scope(t *testing.T) {
+	client := newFakeClient([]*metav1.APIResourceList{knativeServing}, nil,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}})
+	checker := NewChecker()
+	checker.newClient = func(context.Context) (kubernetes.Interface, error) { return client, nil }
+	f := fn.Function{Name: "f", Namespace: "ns"}
+
+	ctx := context.WithValue(context.Background(), fn.PreflightScopeKey{}, fn.PreflightBeforeBuild)
+	report, err := checker.Preflight(ctx, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range report.Checks {
+		if c.Name == CheckPull {
+			t.Fatalf("unexpected check %q before the function is built:\n%v", c.Name, report)
+		}
+	}
+	if status(t, report, CheckNamespace) != fn.PreflightPass {
+		t.Fatalf("expected the namespace to be checked:\n%v", report)
+	}
+
+	ctx = context.WithValue(context.Background(), fn.PreflightScopeKey{}, fn.PreflightImage)
+	if report, err = checker.Preflight(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Checks) != 1 || status(t, report, CheckPull) != fn.PreflightSkip {
+		t.Fatalf("expected only the image pull to be checked:\n%v", report)
+	}
+}
+
+// TestPreflight_Fail ensures missing APIs, permissions and resources are
+// reported as failures.
+func TestPreflight_Fail(t *testing.T) {
+	client := newFakeClient([]*metav1.APIResourceList{knativeServing}, map[string]bool{"services": true},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}})
+	checker := NewChecker()
+	checker.newClient = func(context.Context) (kubernetes.Interface, error) { return client, nil }
+
+	f := fn.Function{Name: "f", Namespace: "ns",
+		Local: fn.Local{Remote: true},
+		Run:   fn.RunSpec{Volumes: []fn.Volume{{ConfigMap: ptr("settings"), Path: ptr("/etc/settings")}}},
+		Deploy: fn.DeploySpec{
+			ServiceAccountName: "builder",
+			Subscriptions:      []fn.KnativeSubscription{{Source: "default"}},
+		}}
+	report, err := checker.Preflight(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Failed() {
+		t.Fatalf("expected a failure:\n%v", report)
+	}
+	for _, name := range []string{CheckEventing, CheckTekton, CheckPermissions, CheckResources} {
+		if s := status(t, report, name); s != fn.PreflightFail {
+			t.Errorf("expected check %q to fail, got %v", name, s)
+		}
+	}
+}
+
+// TestPreflight_RemoteBuilderJob ensures Tekton is not required of functions
+// deployed remotely by the job remote builder, but permissions to run the
+// Job are.
+func TestPreflight_RemoteBuilderJob(t *testing.T) {
+	client := newFakeClient([]*metav1.APIResourceList{knativeServing}, map[string]bool{"jobs": true},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}})
+	checker := NewChecker()
+	checker.newClient = func(context.Context) (kubernetes.Interface, error) { return client, nil }
+
+	f := fn.Function{Name: "f", Namespace: "ns", Local: fn.Local{Remote: true, RemoteBuilder: "job"}}
+	report, err := checker.Preflight(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := status(t, report, CheckTekton); s != fn.PreflightSkip {
+		t.Errorf("expected the Tekton check to be skipped, got %v", s)
+	}
+	if s := status(t, report, CheckPermissions); s != fn.PreflightFail {
+		t.Errorf("expected the permission to create jobs to be required, got %v", s)
+	}
+}
+
+// TestPreflight_AllowFromIstio ensures an allow list enforced by Istio
+// requires its API and the permission to manage AuthorizationPolicies in the
+// Istio root namespace, and that one enforced by a NetworkPolicy does not,
+// but is warned of.
+func TestPreflight_AllowFromIstio(t *testing.T) {
+	istio := &metav1.APIResourceList{
+		GroupVersion: "security.istio.io/v1",
+		APIResources: []metav1.APIResource{{Name: "authorizationpolicies"}},
+	}
+	var reviewed []string
+	client := newFakeClient([]*metav1.APIResourceList{knativeServing, istio}, map[string]bool{"authorizationpolicies": true},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns"}})
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		a := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview).Spec.ResourceAttributes
+		reviewed = append(reviewed, a.Namespace+"/"+a.Resource)
+		return false, nil, nil
+	})
+	checker := NewChecker()
+	checker.newClient = func(context.Context) (kubernetes.Interface, error) { return client, nil }
+
+	f := fn.Function{Name: "f", Namespace: "ns", Deploy: fn.DeploySpec{AllowFrom: []fn.AllowFrom{{Namespace: "frontend"}}}}
+	report, err := checker.Preflight(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := status(t, report, CheckPermissions); s != fn.PreflightPass {
+		t.Fatalf("expected a NetworkPolicy not to require Istio permissions, got %v:\n%v", s, report)
+	}
+	if s := status(t, report, CheckAllowList); s != fn.PreflightWarn {
+		t.Fatalf("expected a warning that routed requests are not restricted by a NetworkPolicy, got %v", s)
+	}
+
+	reviewed = nil
+	f.Deploy.AllowFromPolicy = fn.AllowFromIstio
+	if report, err = checker.Preflight(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if s := status(t, report, CheckPermissions); s != fn.PreflightFail {
+		t.Fatalf("expected the permission to manage AuthorizationPolicies to be required, got %v", s)
+	}
+	if s := status(t, report, CheckAllowList); s != fn.PreflightPass {
+		t.Fatalf("expected an allow list enforced by Istio to pass, got %v", s)
+	}
+	var found bool
+	for _, r := range reviewed {
+		found = found || r == "istio-system/authorizationpolicies"
+	}
+	if !found {
+		t.Fatalf("expected AuthorizationPolicies to be reviewed in istio-system, got %v", reviewed)
+	}
+}
+
+// TestRequiredPermissions ensures the permissions required are those of the
+// calls made by the deployer of the platform for the function.
+func TestRequiredPermissions(t *testing.T) {
+	root := t.TempDir()
+	resources := fn.Function{Root: root}
+	if err := resources.WriteResource(fn.ConfigMapResource, fn.Resource{Name: "settings"}); err != nil {
+		t.Fatal(err)
+	}
+	max := int64(3)
+
+	tests := []struct {
+		name     string
+		f        fn.Function
+		platform string
+		required []string
+		excluded []string
+	}{
+		{
+			name:     "knative",
+			platform: "knative",
+			required: []string{"create services.serving.knative.dev", "get routes.serving.knative.dev"},
+			excluded: []string{"list domainmappings.serving.knative.dev", "list configmaps", "create triggers.eventing.knative.dev"},
+		},
+		{
+			name: "knative with domains and subscriptions",
+			f: fn.Function{Deploy: fn.DeploySpec{
+				Domains:       []fn.Domain{{Host: "www.example.com"}},
+				Subscriptions: []fn.KnativeSubscription{{Source: "default"}},
+			}},
+			platform: "knative",
+			required: []string{"create domainmappings.serving.knative.dev", "list domainmappings.serving.knative.dev",
+				"delete domainmappings.serving.knative.dev", "create triggers.eventing.knative.dev"},
+		},
+		{
+			name:     "managed resources",
+			f:        resources,
+			platform: "knative",
+			required: []string{"create configmaps", "list configmaps", "delete configmaps"},
+			excluded: []string{"list secrets"},
+		},
+		{
+			name:     "kubernetes",
+			platform: "kubernetes",
+			required: []string{"create deployments.apps", "list pods", "update services"},
+			excluded: []string{"get ingresses.networking.k8s.io", "get httproutes.gateway.networking.k8s.io",
+				"get horizontalpodautoscalers.autoscaling"},
+		},
+		{
+			name: "kubernetes exposed by an ingress",
+			f: fn.Function{Deploy: fn.DeploySpec{
+				Expose:  fn.Expose{Host: "www.example.com"},
+				Options: fn.Options{Scale: &fn.ScaleOptions{Max: &max}},
+			}},
+			platform: "kubernetes",
+			required: []string{"create ingresses.networking.k8s.io", "create horizontalpodautoscalers.autoscaling"},
+			excluded: []string{"get httproutes.gateway.networking.k8s.io"},
+		},
+		{
+			name:     "kubernetes exposed by a gateway",
+			f:        fn.Function{Deploy: fn.DeploySpec{Expose: fn.Expose{Gateway: "gw"}}},
+			platform: "kubernetes",
+			required: []string{"update httproutes.gateway.networking.k8s.io"},
+			excluded: []string{"create ingresses.networking.k8s.io"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			permissions := map[string]bool{}
+			for _, p := range requiredPermissions(tt.f, tt.platform) {
+				permissions[p.String()] = true
+			}
+			for _, p := range tt.required {
+				if !permissions[p] {
+					t.Errorf("expected %q to be required, got %v", p, permissions)
+				}
+			}
+			for _, p := range tt.excluded {
+				if permissions[p] {
+					t.Errorf("unexpected required %q", p)
+				}
+			}
+		})
+	}
+}
+
+// TestPreflight_NamespaceNotFound ensures checks within a namespace which
+// does not exist are not made.
+func TestPreflight_NamespaceNotFound(t *testing.T) {
+	client := newFakeClient([]*metav1.APIResourceList{knativeServing}, nil)
+	checker := NewChecker()
+	checker.newClient = func(context.Context) (kubernetes.Interface, error) { return client, nil }
+
+	report, err := checker.Preflight(context.Background(), fn.Function{Name: "f", Namespace: "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status(t, report, CheckNamespace) != fn.PreflightFail {
+		t.Fatalf("expected the namespace check to fail:\n%v", report)
+	}
+	for _, c := range report.Checks {
+		if c.Name == CheckPermissions || c.Name == CheckResources || c.Name == CheckPull {
+			t.Fatalf("unexpected check %q within a missing namespace", c.Name)
+		}
+	}
+}
+
+// TestPreflight_Unreachable ensures an unreachable cluster fails the first
+// check without the others being made.
+func TestPreflight_Unreachable(t *testing.T) {
+	checker := NewChecker()
+	checker.newClient = func(context.Context) (kubernetes.Interface, error) { return nil, errors.New("no configuration") }
+
+	report, err := checker.Preflight(context.Background(), fn.Function{Name: "f", Namespace: "ns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status(t, report, CheckCluster) != fn.PreflightFail {
+		t.Fatalf("expected the cluster check to fail:\n%v", report)
+	}
+	if len(report.Checks) != 2 { // the cluster and registry
+		t.Fatalf("expected only the cluster and registry to be checked:\n%v", report)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,195 @@
+package preflight
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/func/pkg/deployers"
+	"knative.dev/func/pkg/docker/creds"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// image of the function to which it is pushed, or empty if it can not be
+// determined.
+func image(f fn.Function) string {
+	if f.Deploy.Image != "" {
+		return f.Deploy.Image
+	}
+	if f.Build.Image != "" {
+		return f.Build.Image
+	}
+	image, _ := f.ImageName()
+	return image
+}
+
+// checkRegistry adds a check that the credentials found for the function's
+// registry permit pushing its image.  It is skipped when the image is not
+// pushed, as with --push=false, or deployed by the local container engine.
+func (c *Checker) checkRegistry(ctx context.Context, f fn.Function, platform string, report *fn.PreflightReport) {
+	if push, ok := ctx.Value(fn.PreflightPushKey{}).(bool); ok && !push {
+		report.Add(CheckRegistry, fn.PreflightSkip, "the image is not pushed")
+		return
+	}
+	if platform == deployers.Docker && !f.Local.Remote {
+		report.Add(CheckRegistry, fn.PreflightSkip, "not required by the %v platform", platform)
+		return
+	}
+	img := image(f)
+	if img == "" {
+		report.Add(CheckRegistry, fn.PreflightSkip, "no image or registry is set")
+		return
+	}
+	if c.credentialsProvider == nil {
+		report.Add(CheckRegistry, fn.PreflightSkip, "no credentials provider")
+		return
+	}
+	ref, err := name.ParseReference(img)
+	if err != nil {
+		report.Add(CheckRegistry, fn.PreflightFail, "invalid image %q: %v", img, err)
+		return
+	}
+	registry := ref.Context().RegistryStr()
+
+	credentials, err := c.credentialsProvider(ctx, img)
+	if errors.Is(err, creds.ErrCredentialsNotFound) {
+		report.Add(CheckRegistry, fn.PreflightFail, "no credentials permitting a push to %v were found", ref.Context())
+		return
+	} else if err != nil {
+		report.Add(CheckRegistry, fn.PreflightFail, "unable to get the credentials of %v: %v", registry, err)
+		return
+	}
+	err = creds.CheckAuth(ctx, img, credentials, c.transport)
+	if errors.Is(err, creds.ErrUnauthorized) {
+		report.Add(CheckRegistry, fn.PreflightFail, "not authorized to push to %v", ref.Context())
+		return
+	} else if err != nil {
+		report.Add(CheckRegistry, fn.PreflightFail, "unable to reach %v: %v", registry, err)
+		return
+	}
+	report.Add(CheckRegistry, fn.PreflightPass, "permitted to push to %v", ref.Context())
+}
+
+// checkPull adds a check that the function's deployed image can be pulled
+// with the image pull secrets of its service account.  The image is
+// requested from this machine, so a registry reachable only from within the
+// cluster can not be verified.
+func (c *Checker) checkPull(ctx context.Context, client kubernetes.Interface, f fn.Function, namespace string, report *fn.PreflightReport) {
+	if f.Deploy.Image == "" {
+		report.Add(CheckPull, fn.PreflightSkip, "the function has not been built")
+		return
+	}
+	ref, err := name.ParseReference(f.Deploy.Image)
+	if err != nil {
+		report.Add(CheckPull, fn.PreflightFail, "invalid image %q: %v", f.Deploy.Image, err)
+		return
+	}
+
+	serviceAccount := f.Deploy.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = "default"
+	}
+	auth, err := pullAuthenticator(ctx, client, namespace, serviceAccount, ref.Context().RegistryStr())
+	if err != nil {
+		report.Add(CheckPull, fn.PreflightWarn, "unable to read the image pull secrets of ServiceAccount %q: %v", serviceAccount, err)
+		return
+	}
+
+	_, err = remote.Head(ref, remote.WithAuth(auth), remote.WithContext(ctx), remote.WithTransport(c.transport))
+	var terr *transport.Error
+	switch {
+	case err == nil:
+		report.Add(CheckPull, fn.PreflightPass, "%v can be pulled", ref)
+	case errors.As(err, &terr) && (terr.StatusCode == http.StatusUnauthorized || terr.StatusCode == http.StatusForbidden):
+		report.Add(CheckPull, fn.PreflightFail, "not authorized to pull %v with the image pull secrets of ServiceAccount %q", ref, serviceAccount)
+	case errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound:
+		report.Add(CheckPull, fn.PreflightFail, "%v not found", ref)
+	default:
+		report.Add(CheckPull, fn.PreflightWarn, "unable to reach the registry from this machine: %v", err)
+	}
+}
+
+// pullAuthenticator for the registry from the image pull secrets of the
+// service account, or anonymous if it has none for the registry.
+func pullAuthenticator(ctx context.Context, client kubernetes.Interface, namespace, serviceAccount, registry string) (authn.Authenticator, error) {
+	sa, err := client.CoreV1().ServiceAccounts(namespace).Get(ctx, serviceAccount, metav1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		return authn.Anonymous, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, ref := range sa.ImagePullSecrets {
+		secret, err := client.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if apiErrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if auth, ok := dockerConfigAuth(secret, registry); ok {
+			return auth, nil
+		}
+	}
+	return authn.Anonymous, nil
+}
+
+// dockerConfigEntry is the credentials of a registry in a docker config.
+type dockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// dockerConfigAuth returns the credentials of the registry from a Secret of
+// type kubernetes.io/dockerconfigjson or kubernetes.io/dockercfg.
+func dockerConfigAuth(secret *corev1.Secret, registry string) (authn.Authenticator, bool) {
+	var entries map[string]dockerConfigEntry
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		var config struct {
+			Auths map[string]dockerConfigEntry `json:"auths"`
+		}
+		if json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config) != nil {
+			return nil, false
+		}
+		entries = config.Auths
+	case corev1.SecretTypeDockercfg:
+		if json.Unmarshal(secret.Data[corev1.DockerConfigKey], &entries) != nil {
+			return nil, false
+		}
+	}
+	for key, e := range entries {
+		if registryHost(key) != registry {
+			continue
+		}
+		if e.Username == "" && e.Auth != "" {
+			if decoded, err := base64.StdEncoding.DecodeString(e.Auth); err == nil {
+				e.Username, e.Password, _ = strings.Cut(string(decoded), ":")
+			}
+		}
+		return &authn.Basic{Username: e.Username, Password: e.Password}, true
+	}
+	return nil, false
+}
+
+// registryHost of a docker config key, which may be a URL such as
+// https://index.docker.io/v1/, normalized as by name.Registry.
+func registryHost(key string) string {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	key, _, _ = strings.Cut(key, "/")
+	if r, err := name.NewRegistry(key); err == nil {
+		return r.RegistryStr()
+	}
+	return key
+}
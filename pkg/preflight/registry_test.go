@@ -0,0 +1,149 @@
+//go:build !integration
+// +build !integration
+
+package preflight
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"knative.dev/func/pkg/deployers"
+	"knative.dev/func/pkg/docker"
+	"knative.dev/func/pkg/docker/creds"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestCheckRegistry ensures push credentials are found and checked.
+func TestCheckRegistry(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	image := strings.TrimPrefix(server.URL, "http://") + "/alice/f:latest"
+	f := fn.Function{Deploy: fn.DeploySpec{Image: image}}
+
+	// Credentials found (the test registry permits anonymous pushes)
+	checker := NewChecker(WithCredentialsProvider(func(context.Context, string) (docker.Credentials, error) {
+		return docker.Credentials{}, nil
+	}))
+	var report fn.PreflightReport
+	checker.checkRegistry(context.Background(), f, deployers.Knative, &report)
+	if status(t, report, CheckRegistry) != fn.PreflightPass {
+		t.Fatalf("expected the registry check to pass:\n%v", report)
+	}
+
+	// No credentials found
+	checker = NewChecker(WithCredentialsProvider(func(context.Context, string) (docker.Credentials, error) {
+		return docker.Credentials{}, creds.ErrCredentialsNotFound
+	}))
+	report = fn.PreflightReport{}
+	checker.checkRegistry(context.Background(), f, deployers.Knative, &report)
+	if status(t, report, CheckRegistry) != fn.PreflightFail {
+		t.Fatalf("expected the registry check to fail:\n%v", report)
+	}
+
+	// Nothing is pushed with --push=false
+	report = fn.PreflightReport{}
+	checker.checkRegistry(context.WithValue(context.Background(), fn.PreflightPushKey{}, false), f, deployers.Knative, &report)
+	if status(t, report, CheckRegistry) != fn.PreflightSkip {
+		t.Fatalf("expected the registry check to be skipped without a push:\n%v", report)
+	}
+
+	// Nor to deploy to the local container engine
+	report = fn.PreflightReport{}
+	checker.checkRegistry(context.Background(), f, deployers.Docker, &report)
+	if status(t, report, CheckRegistry) != fn.PreflightSkip {
+		t.Fatalf("expected the registry check to be skipped on the docker platform:\n%v", report)
+	}
+}
+
+// TestCheckPull ensures a deployed image is checked to exist.
+func TestCheckPull(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(host + "/alice/f:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset()
+	checker := NewChecker()
+
+	var report fn.PreflightReport
+	checker.checkPull(context.Background(), client, fn.Function{Deploy: fn.DeploySpec{Image: ref.String()}}, "ns", &report)
+	if status(t, report, CheckPull) != fn.PreflightPass {
+		t.Fatalf("expected the pull check to pass:\n%v", report)
+	}
+
+	report = fn.PreflightReport{}
+	checker.checkPull(context.Background(), client, fn.Function{Deploy: fn.DeploySpec{Image: host + "/alice/missing:latest"}}, "ns", &report)
+	if status(t, report, CheckPull) != fn.PreflightFail {
+		t.Fatalf("expected the pull check of a missing image to fail:\n%v", report)
+	}
+}
+
+// Test_pullAuthenticator ensures the credentials of the registry are read
+// from the image pull secrets of the service account.
+func Test_pullAuthenticator(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("bob:secret"))
+	client := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "ns"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "missing"}, {Name: "hub"}, {Name: "ghcr"}},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "hub", Namespace: "ns"},
+			Type:       corev1.SecretTypeDockercfg,
+			Data:       map[string][]byte{corev1.DockerConfigKey: []byte(`{"https://index.docker.io/v1/":{"auth":"` + auth + `"}}`)},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ghcr", Namespace: "ns"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"ghcr.io":{"username":"alice","password":"token"}}}`)},
+		})
+
+	tests := []struct {
+		registry string
+		username string
+	}{
+		{"index.docker.io", "bob"},
+		{"ghcr.io", "alice"},
+		{"quay.io", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.registry, func(t *testing.T) {
+			a, err := pullAuthenticator(context.Background(), client, "ns", "default", tt.registry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config, err := a.Authorization()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.username == "" && a != authn.Anonymous {
+				t.Fatalf("expected anonymous access, got %+v", config)
+			} else if config.Username != tt.username {
+				t.Fatalf("expected username %q, got %q", tt.username, config.Username)
+			}
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package scaffolding
 
 import (
-	"errors"
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +19,13 @@ type detector interface {
 	Detect(dir string) (static, instanced bool, err error)
 }
 
+// invokeDetector is implemented by detectors which can further determine
+// the invocation hint ("http" or "cloudevent") expected by a function's
+// source, for example by the presence of a CloudEvents SDK import.
+type invokeDetector interface {
+	DetectInvoke(dir string) (invoke string, err error)
+}
+
 // newDetector returns a deector instance for the given runtime.
 func newDetector(runtime string) (detector, error) {
 	switch runtime {
@@ -24,15 +34,15 @@ func newDetector(runtime string) (detector, error) {
 	case "python":
 		return &pythonDetector{}, nil
 	case "rust":
-		return nil, ErrDetectorNotImplemented{runtime}
+		return &rustDetector{}, nil
 	case "node":
-		return nil, ErrDetectorNotImplemented{runtime}
+		return &nodeDetector{sources: []string{"index.js"}}, nil
 	case "typescript":
-		return nil, ErrDetectorNotImplemented{runtime}
-	case "quarkus":
-		return nil, ErrDetectorNotImplemented{runtime}
-	case "java":
-		return nil, ErrDetectorNotImplemented{runtime}
+		return &nodeDetector{sources: []string{filepath.Join("src", "index.ts"), "index.ts"}}, nil
+	case "quarkus", "java":
+		return &javaDetector{}, nil
+	case "springboot":
+		return &springbootDetector{}, nil
 	default:
 		return nil, ErrRuntimeNotRecognized{runtime}
 	}
@@ -62,6 +72,29 @@ func (d goDetector) Detect(dir string) (static, instanced bool, err error) {
 	return
 }
 
+func (d goDetector) DetectInvoke(dir string) (invoke string, err error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("invocation detector encountered an error when scanning the function's source code. %w", err)
+	}
+	for _, file := range files {
+		filename := filepath.Join(dir, file.Name())
+		if file.IsDir() || !strings.HasSuffix(filename, ".go") {
+			continue
+		}
+		astFile, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range astFile.Imports {
+			if path, _ := strconv.Unquote(imp.Path.Value); strings.HasPrefix(path, "github.com/cloudevents/sdk-go") {
+				return "cloudevent", nil
+			}
+		}
+	}
+	return "http", nil
+}
+
 func (d goDetector) hasFunctionDeclaration(filename, function string) bool {
 	astFile, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.SkipObjectResolution)
 	if err != nil {
@@ -82,8 +115,398 @@ func (d goDetector) hasFunctionDeclaration(filename, function string) bool {
 
 type pythonDetector struct{}
 
-func (d pythonDetector) Detect(dir string) (bool, bool, error) {
-	return false, false, errors.New("the Python method signature detector is not yet available")
+var (
+	// Module-level (unindented) function definitions.  The static signature
+	// may be named either "handle" or, in earlier templates, "main".  Events
+	// are expected by those importing the CloudEvents SDK or, in earlier
+	// templates, decorated as a parliament @event.
+	pythonInstanced = regexp.MustCompile(`^def\s+new\s*\(`)
+	pythonStatic    = regexp.MustCompile(`^(async\s+)?def\s+(handle|main)\s*\(`)
+	pythonEvents    = regexp.MustCompile(`^(\s*(from|import)\s+cloudevents\b|@event\b)`)
+)
+
+func (d pythonDetector) Detect(dir string) (static, instanced bool, err error) {
+	err = d.scan(dir, func(line string) {
+		if pythonInstanced.MatchString(line) {
+			instanced = true
+		}
+		if pythonStatic.MatchString(line) {
+			static = true
+		}
+	})
+	return
+}
+
+func (d pythonDetector) DetectInvoke(dir string) (invoke string, err error) {
+	invoke = "http"
+	err = d.scan(dir, func(line string) {
+		if pythonEvents.MatchString(line) {
+			invoke = "cloudevent"
+		}
+	})
+	return
+}
+
+// scan each line of the python source files in dir, and in its "function"
+// subdirectory (the module layout used by more recent templates).
+func (d pythonDetector) scan(dir string, each func(line string)) error {
+	for _, path := range []string{dir, filepath.Join(dir, "function")} {
+		files, err := os.ReadDir(path)
+		if os.IsNotExist(err) && path != dir {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("signature detector encountered an error when scanning the function's source code. %w", err)
+		}
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".py") || strings.HasPrefix(file.Name(), "test_") {
+				continue
+			}
+			f, err := os.Open(filepath.Join(path, file.Name()))
+			if err != nil {
+				return fmt.Errorf("signature detector encountered an error when reading the function's source code. %w", err)
+			}
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				each(scanner.Text())
+			}
+			f.Close()
+		}
+	}
+	return nil
+}
+
+// NODE & TYPESCRIPT
+
+// nodeDetector detects the signature of a Node.js or TypeScript function
+// by the members exported by its main module: a "handle" function for the
+// static signature, or a "new" factory (returning an object with a "handle"
+// method) for the instanced signature.
+type nodeDetector struct {
+	// sources are the candidate main modules relative to the function root,
+	// in order of precedence.  The "main" of a package.json takes precedence.
+	sources []string
+}
+
+var (
+	nodeExportsObject = regexp.MustCompile(`module\.exports\s*=\s*\{([^}]*)\}`)
+	nodeExportsValue  = regexp.MustCompile(`module\.exports\s*=\s*([A-Za-z_$][\w$]*)\s*;?\s*$`)
+	nodeExportsMember = regexp.MustCompile(`(?:module\.)?exports\.([A-Za-z_$][\w$]*)\s*=`)
+	nodeExportList    = regexp.MustCompile(`export\s*\{([^}]*)\}`)
+	nodeExportDecl    = regexp.MustCompile(`export\s+(?:async\s+)?(?:function\*?|const|let|var|class)\s+([A-Za-z_$][\w$]*)`)
+	nodeEvents        = regexp.MustCompile(`(?:require\(\s*|from\s+)['"]cloudevents['"]`)
+)
+
+func (d nodeDetector) Detect(dir string) (static, instanced bool, err error) {
+	src, err := d.read(dir)
+	if err != nil {
+		return
+	}
+	for _, name := range nodeExports(src) {
+		switch name {
+		case "handle":
+			static = true
+		case "new":
+			instanced = true
+		}
+	}
+	return
+}
+
+func (d nodeDetector) DetectInvoke(dir string) (string, error) {
+	src, err := d.read(dir)
+	if err != nil {
+		return "", err
+	}
+	if nodeEvents.MatchString(src) {
+		return "cloudevent", nil
+	}
+	return "http", nil
+}
+
+// read the source of the function's main module with comments removed.
+func (d nodeDetector) read(dir string) (string, error) {
+	sources := d.sources
+	if main := packageMain(dir); main != "" {
+		sources = append([]string{main}, sources...)
+	}
+	for _, source := range sources {
+		bb, err := os.ReadFile(filepath.Join(dir, source))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", fmt.Errorf("signature detector encountered an error when reading the function's source code. %w", err)
+		}
+		return stripComments(string(bb)), nil
+	}
+	return "", fmt.Errorf("signature detector was unable to find the function's main module (%v)", strings.Join(sources, ", "))
 }
 
-// TODO: Other Detectors
+// packageMain returns the "main" module declared by the package.json in dir,
+// if any.  Compiled output (such as ./build/index.js) is not considered, as
+// it may not yet exist.
+func packageMain(dir string) string {
+	bb, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Main string `json:"main"`
+	}
+	if err = json.Unmarshal(bb, &pkg); err != nil || strings.HasPrefix(filepath.Clean(pkg.Main), "build") {
+		return ""
+	}
+	return pkg.Main
+}
+
+// nodeExports returns the names of members exported by the given CommonJS
+// or ES module source.
+func nodeExports(src string) (names []string) {
+	for _, m := range nodeExportsObject.FindAllStringSubmatch(src, -1) {
+		for _, entry := range strings.Split(m[1], ",") {
+			// { handle }, { handle: fn }, { handle() {...} } or { async handle() }
+			entry = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(entry), "async "))
+			if i := strings.IndexAny(entry, ":( "); i >= 0 {
+				entry = entry[:i]
+			}
+			names = append(names, entry)
+		}
+	}
+	for _, line := range strings.Split(src, "\n") {
+		if m := nodeExportsValue.FindStringSubmatch(line); m != nil && m[1] == "handle" {
+			names = append(names, "handle") // the handler itself is the module
+		}
+	}
+	for _, m := range nodeExportsMember.FindAllStringSubmatch(src, -1) {
+		names = append(names, m[1])
+	}
+	for _, m := range nodeExportList.FindAllStringSubmatch(src, -1) {
+		for _, entry := range strings.Split(m[1], ",") {
+			// { handle } or { create as new }
+			fields := strings.Fields(entry)
+			if len(fields) > 0 {
+				names = append(names, fields[len(fields)-1])
+			}
+		}
+	}
+	for _, m := range nodeExportDecl.FindAllStringSubmatch(src, -1) {
+		names = append(names, m[1])
+	}
+	return
+}
+
+// RUST
+
+// rustDetector detects the signature of a Rust function, a library crate
+// named "function", by its source in ./src: a public handler module with a
+// "handle" (or "index") function and a public config module for the static
+// signature, or an implementation of the Handler trait for the instanced
+// signature.  The crate's binary, main.rs, is not considered.
+type rustDetector struct{}
+
+var (
+	rustStatic    = regexp.MustCompile(`(?m)^pub\s+(?:async\s+)?fn\s+(?:handle|index)\s*[<(]`)
+	rustInstanced = regexp.MustCompile(`(?m)^impl(?:\s*<[^>]*>)?\s+(?:[\w]+::)*Handler(?:\s*<[^>]*>)?\s+for\s+`)
+	rustEvents    = regexp.MustCompile(`\bcloudevents::`)
+	rustModules   = regexp.MustCompile(`(?m)^pub\s+mod\s+(\w+)\s*;`)
+)
+
+func (d rustDetector) Detect(dir string) (static, instanced bool, err error) {
+	bb, err := os.ReadFile(filepath.Join(dir, "src", "lib.rs"))
+	if err != nil {
+		return false, false, fmt.Errorf("signature detector expects the function to be a library crate (src/lib.rs). %w", err)
+	}
+	modules := map[string]bool{}
+	for _, m := range rustModules.FindAllStringSubmatch(stripComments(string(bb)), -1) {
+		modules[m[1]] = true
+	}
+	err = d.scan(dir, func(name, src string) {
+		if name == "handler.rs" && modules["handler"] && modules["config"] && rustStatic.MatchString(src) {
+			static = true
+		}
+		if rustInstanced.MatchString(src) {
+			instanced = true
+		}
+	})
+	return
+}
+
+func (d rustDetector) DetectInvoke(dir string) (invoke string, err error) {
+	invoke = "http"
+	err = d.scan(dir, func(_, src string) {
+		if rustEvents.MatchString(src) {
+			invoke = "cloudevent"
+		}
+	})
+	return
+}
+
+// scan the source (with comments removed) of each file in ./src other than
+// the binary's main.rs.
+func (d rustDetector) scan(dir string, each func(name, src string)) error {
+	files, err := os.ReadDir(filepath.Join(dir, "src"))
+	if err != nil {
+		return fmt.Errorf("signature detector encountered an error when scanning the function's source code. %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".rs") || file.Name() == "main.rs" {
+			continue
+		}
+		bb, err := os.ReadFile(filepath.Join(dir, "src", file.Name()))
+		if err != nil {
+			return fmt.Errorf("signature detector encountered an error when reading the function's source code. %w", err)
+		}
+		each(file.Name(), stripComments(string(bb)))
+	}
+	return nil
+}
+
+// JAVA & QUARKUS
+
+// javaDetector detects the signature of a Java function by its methods
+// annotated with @Funq: static methods for the static signature, or instance
+// methods (of a bean instantiated by the framework) for the instanced
+// signature.  Methods accepting or returning a CloudEvent indicate the
+// cloudevent invocation.  Funqy serves the annotated methods itself, so the
+// scaffolding of each signature links the function source to be built as-is.
+type javaDetector struct{}
+
+var (
+	javaFunction = regexp.MustCompile(`@Funq\b(?:\s*\([^)]*\))?([^;{]*?)\(([^)]*)\)`)
+	javaStatic   = regexp.MustCompile(`\bstatic\b`)
+)
+
+func (d javaDetector) Detect(dir string) (static, instanced bool, err error) {
+	err = d.scan(dir, func(decl, _ string) {
+		if javaStatic.MatchString(decl) {
+			static = true
+		} else {
+			instanced = true
+		}
+	})
+	return
+}
+
+func (d javaDetector) DetectInvoke(dir string) (invoke string, err error) {
+	invoke = "http"
+	err = d.scan(dir, func(decl, params string) {
+		if strings.Contains(decl, "CloudEvent") || strings.Contains(params, "CloudEvent") {
+			invoke = "cloudevent"
+		}
+	})
+	return
+}
+
+// scan the declarations of annotated methods in src/main/java.  The callback
+// receives the declaration preceding the method's parameter list, and the
+// parameter list itself.
+func (d javaDetector) scan(dir string, each func(decl, params string)) error {
+	root := filepath.Join(dir, "src", "main", "java")
+	if _, err := os.Stat(root); err != nil {
+		return fmt.Errorf("signature detector encountered an error when scanning the function's source code. %w", err)
+	}
+	return filepath.WalkDir(root, func(path string, de os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() || !strings.HasSuffix(path, ".java") {
+			return nil
+		}
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("signature detector encountered an error when reading the function's source code. %w", err)
+		}
+		for _, m := range javaFunction.FindAllStringSubmatch(stripComments(string(bb)), -1) {
+			each(m[1], m[2])
+		}
+		return nil
+	})
+}
+
+// SPRING BOOT
+
+// springbootDetector detects the invocation of a Spring Cloud Function by its
+// use of the CloudEvents support of the framework.  Functions are beans
+// instantiated by the framework rather than static or instanced methods, so
+// their signature is not detected: the runtime is built without scaffolding.
+type springbootDetector struct{}
+
+var springbootCloudEvent = regexp.MustCompile(`\bCloudEvent`)
+
+func (d springbootDetector) Detect(dir string) (static, instanced bool, err error) {
+	return false, false, ErrDetectorNotImplemented{"springboot"}
+}
+
+func (d springbootDetector) DetectInvoke(dir string) (invoke string, err error) {
+	invoke = "http"
+	root := filepath.Join(dir, "src", "main", "java")
+	if _, err = os.Stat(root); err != nil {
+		return invoke, fmt.Errorf("signature detector encountered an error when scanning the function's source code. %w", err)
+	}
+	err = filepath.WalkDir(root, func(path string, de os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() || !strings.HasSuffix(path, ".java") {
+			return nil
+		}
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("signature detector encountered an error when reading the function's source code. %w", err)
+		}
+		if springbootCloudEvent.MatchString(stripComments(string(bb))) {
+			invoke = "cloudevent"
+		}
+		return nil
+	})
+	return
+}
+
+// stripComments removes block and line comments from C-like source code.
+// Comment markers within string literals, such as the slashes of a URL, are
+// not comments.  This is otherwise intentionally naive, as detectors need only
+// determine which signature is expected of the source, not whether it is
+// valid.
+func stripComments(src string) string {
+	var b strings.Builder
+	for i := 0; i < len(src); i++ {
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return b.String()
+			}
+			i += end - 1 // the newline is kept
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+		case src[i] == '"' || src[i] == '\'' || src[i] == '`':
+			end := literalEnd(src, i)
+			b.WriteString(src[i:end])
+			i = end - 1
+		default:
+			b.WriteByte(src[i])
+		}
+	}
+	return b.String()
+}
+
+// literalEnd returns the index following the string literal opened by the
+// quote at start.  Only backtick literals span lines: a quote not closed on
+// its line, such as that of a Rust lifetime, does not open a literal.
+func literalEnd(src string, start int) int {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch {
+		case src[i] == '\\' && quote != '`':
+			i++
+		case src[i] == quote:
+			return i + 1
+		case src[i] == '\n' && quote != '`':
+			return start + 1
+		}
+	}
+	return start + 1
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "knative.dev/func/pkg/testing"
@@ -143,3 +144,257 @@ func (f *MyFunction) Handle() {}
 		})
 	}
 }
+
+// TestDetector_Python ensures that the python detector identifies the
+// signature expected of a function's source from its module-level function
+// definitions.
+func TestDetector_Python(t *testing.T) {
+	tests := []struct {
+		Name string
+		Sig  Signature
+		Err  bool
+		Src  string
+	}{
+		{"Instanced HTTP", InstancedHTTP, false, "def new():\n    return Function()\n"},
+		{"Static HTTP", StaticHTTP, false, "async def handle(scope, receive, send):\n    pass\n"},
+		{"Static legacy main", StaticHTTP, false, "def main(context):\n    return 'ok'\n"},
+		{"Nested ignored", StaticHTTP, false, "class F:\n    def new(self):\n        pass\n\ndef handle():\n    pass\n"},
+		{"Both", UnknownSignature, true, "def new():\n    pass\n\ndef handle():\n    pass\n"},
+		{"None", UnknownSignature, true, "x = 1\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+			if err := os.WriteFile(filepath.Join(root, "func.py"), []byte(test.Src), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			s, err := detectSignature(root, "python", "")
+			if (err != nil) != test.Err {
+				t.Fatalf("unexpected error state. %v", err)
+			}
+			if s != test.Sig {
+				t.Fatalf("Expected signature '%v', got '%v'", test.Sig, s)
+			}
+		})
+	}
+}
+
+// TestDetectInvoke ensures that the invocation hint is detected from the
+// use of a CloudEvents SDK.
+func TestDetectInvoke(t *testing.T) {
+	tests := []struct {
+		Runtime  string
+		File     string
+		Src      string
+		Expected string
+	}{
+		{"go", "f.go", "package f\nfunc Handle() {}\n", "http"},
+		{"go", "f.go", "package f\nimport \"github.com/cloudevents/sdk-go/v2/event\"\nfunc Handle(e event.Event) {}\n", "cloudevent"},
+		{"python", "func.py", "def main(context):\n    pass\n", "http"},
+		{"python", "func.py", "from cloudevents.http import CloudEvent\ndef main(context):\n    pass\n", "cloudevent"},
+		{"python", "func.py", "from parliament import Context, event\n\n@event\ndef main(context):\n    pass\n", "cloudevent"},
+		{"node", "index.js", "module.exports = { handle };\n", "http"},
+		{"node", "index.js", "const { CloudEvent } = require('cloudevents');\nmodule.exports = { handle };\n", "cloudevent"},
+		{"rust", "src/handler.rs", "pub async fn index() {}\n", "http"},
+		{"rust", "src/handler.rs", "use cloudevents::Event;\npub async fn handle(e: Event) {}\n", "cloudevent"},
+		{"quarkus", "src/main/java/functions/Function.java", "class F {\n  @Funq\n  public Output function(Input i) { return null; }\n}\n", "http"},
+		{"quarkus", "src/main/java/functions/Function.java", "class F {\n  @Funq\n  public CloudEvent<Output> function(CloudEvent<Input> i) { return null; }\n}\n", "cloudevent"},
+		{"springboot", "src/main/java/functions/F.java", "class F {\n  @Bean\n  public Function<Message<String>, String> echo() { return null; }\n}\n", "http"},
+		{"springboot", "src/main/java/functions/F.java", "import static org.springframework.cloud.function.cloudevent.CloudEventMessageUtils.*;\nclass F {}\n", "cloudevent"},
+	}
+	for _, test := range tests {
+		t.Run(test.Runtime+"-"+test.Expected, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+			writeSource(t, root, test.File, test.Src)
+			invoke, err := DetectInvoke(root, test.Runtime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if invoke != test.Expected {
+				t.Fatalf("expected invoke %q, got %q", test.Expected, invoke)
+			}
+		})
+	}
+
+	if _, err := DetectInvoke("", "other"); !errors.As(err, &ErrRuntimeNotRecognized{}) {
+		t.Fatalf("expected ErrRuntimeNotRecognized, got %v", err)
+	}
+}
+
+// TestDetector_Node ensures that the node and typescript detectors identify
+// the signature expected of a function from the exports of its main module.
+func TestDetector_Node(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Runtime string
+		File    string
+		Sig     Signature
+		Err     bool
+		Src     string
+	}{
+		{"Static object", "node", "index.js", StaticHTTP, false, "const handle = async () => {};\nmodule.exports = { handle };\n"},
+		{"Static property", "node", "index.js", StaticHTTP, false, "exports.handle = async function () {};\n"},
+		{"Static default", "node", "index.js", StaticHTTP, false, "module.exports = handle;\n"},
+		{"Instanced object", "node", "index.js", InstancedHTTP, false, "module.exports = { new: () => ({ handle }) };\n"},
+		{"Commented ignored", "node", "index.js", StaticHTTP, false, "// module.exports = { new };\nmodule.exports = { handle };\n"},
+		{"URL in string", "node", "index.js", StaticHTTP, false, "const url = 'http://example.com'; module.exports = { handle };\n"},
+		{"Commented after string", "node", "index.js", StaticHTTP, false, "const s = \"a\\\"//\"; // module.exports = { new };\nmodule.exports = { handle };\n"},
+		{"None", "node", "index.js", UnknownSignature, true, "const x = 1;\n"},
+		{"Static export", "typescript", "src/index.ts", StaticHTTP, false, "export const handle = async (): Promise<string> => 'ok';\n"},
+		{"Static export list", "typescript", "src/index.ts", StaticHTTP, false, "const handle = () => {};\nexport { handle };\n"},
+		{"Instanced export", "typescript", "src/index.ts", InstancedHTTP, false, "function create() {}\nexport { create as new };\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.Runtime+"-"+test.Name, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+			writeSource(t, root, test.File, test.Src)
+			s, err := detectSignature(root, test.Runtime, "")
+			if (err != nil) != test.Err {
+				t.Fatalf("unexpected error state. %v", err)
+			}
+			if s != test.Sig {
+				t.Fatalf("Expected signature '%v', got '%v'", test.Sig, s)
+			}
+		})
+	}
+}
+
+// TestDetector_Rust ensures that the rust detector identifies the signature
+// expected of a function from the public handler and config modules of its
+// library, or from its implementation of the Handler trait.
+func TestDetector_Rust(t *testing.T) {
+	tests := []struct {
+		Name string
+		Sig  Signature
+		Err  bool
+		Lib  string
+		Src  string
+	}{
+		{"Static", StaticHTTP, false, "pub mod config;\npub mod handler;\n", "pub async fn index(req: HttpRequest) -> HttpResponse {\n    todo!()\n}\n"},
+		{"Static handle", StaticHTTP, false, "pub mod config;\npub mod handler;\n", "pub fn handle() {}\n"},
+		{"Static without config", UnknownSignature, true, "pub mod handler;\n", "pub fn handle() {}\n"},
+		{"Private handler", UnknownSignature, true, "pub mod config;\nmod handler;\n", "pub fn handle() {}\n"},
+		{"Instanced", InstancedHTTP, false, "", "pub struct Function;\n\nimpl function::Handler for Function {\n}\n"},
+		{"Commented ignored", InstancedHTTP, false, "// pub mod config;\n// pub mod handler;\n", "// pub fn handle() {}\nimpl Handler for Function {}\n"},
+		{"Lifetime before comment", StaticHTTP, false, "pub mod config;\npub mod handler;\n", "fn f<'a>(s: &'a str) {} // pub fn new() {}\npub fn handle() {}\n"},
+		{"None", UnknownSignature, true, "pub mod config;\npub mod handler;\n", "fn helper() {}\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+			writeSource(t, root, "src/lib.rs", test.Lib)
+			writeSource(t, root, "src/handler.rs", test.Src)
+			// main.rs is not considered part of the function
+			writeSource(t, root, "src/main.rs", "pub fn handle() {}\nimpl Handler for Main {}\n")
+			s, err := detectSignature(root, "rust", "")
+			if (err != nil) != test.Err {
+				t.Fatalf("unexpected error state. %v", err)
+			}
+			if s != test.Sig {
+				t.Fatalf("Expected signature '%v', got '%v'", test.Sig, s)
+			}
+		})
+	}
+
+	// A binary crate is not a function
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+	writeSource(t, root, "src/handler.rs", "pub fn handle() {}\n")
+	if _, err := detectSignature(root, "rust", ""); err == nil {
+		t.Fatal("expected an error detecting the signature of a binary crate")
+	}
+}
+
+// TestDetector_Java ensures that the java detector identifies the signature
+// expected of a function from its annotated methods.
+func TestDetector_Java(t *testing.T) {
+	tests := []struct {
+		Name string
+		Sig  Signature
+		Err  bool
+		Src  string
+	}{
+		{"Instanced", InstancedHTTP, false, "public class F {\n    @Funq\n    public Output function(Input input) { return null; }\n}\n"},
+		{"Static", StaticHTTP, false, "public class F {\n    @Funq\n    public static Output function(Input input) { return null; }\n}\n"},
+		{"None", UnknownSignature, true, "public class F {\n    public Output function(Input input) { return null; }\n}\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+			writeSource(t, root, "src/main/java/functions/F.java", test.Src)
+			s, err := detectSignature(root, "java", "")
+			if (err != nil) != test.Err {
+				t.Fatalf("unexpected error state. %v", err)
+			}
+			if s != test.Sig {
+				t.Fatalf("Expected signature '%v', got '%v'", test.Sig, s)
+			}
+		})
+	}
+}
+
+// TestDetector_Templates ensures that the signature detected of each of the
+// embedded templates, including the hidden examples of the instanced
+// signature, is one for which scaffolding is provided.
+func TestDetector_Templates(t *testing.T) {
+	tests := []struct {
+		Runtime  string
+		Template string
+		Sig      Signature
+	}{
+		{"go", "http", StaticHTTP},
+		{"go", ".instanced-http", InstancedHTTP},
+		{"node", "http", StaticHTTP},
+		{"node", ".instanced-http", InstancedHTTP},
+		{"typescript", "http", StaticHTTP},
+		{"typescript", ".instanced-http", InstancedHTTP},
+		{"rust", "http", StaticHTTP},
+		{"rust", ".instanced-http", InstancedHTTP},
+		{"quarkus", "http", InstancedHTTP},
+	}
+	for _, test := range tests {
+		for _, template := range []string{test.Template, strings.Replace(test.Template, "http", "cloudevents", 1)} {
+			t.Run(test.Runtime+"-"+template, func(t *testing.T) {
+				src := filepath.Join("..", "..", "templates", test.Runtime, template)
+				invoke, err := DetectInvoke(src, test.Runtime)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected := test.Sig
+				if strings.HasSuffix(template, "cloudevents") {
+					if invoke != "cloudevent" {
+						t.Fatalf("expected cloudevent invocation, got %q", invoke)
+					}
+					expected = toSignature(expected == InstancedHTTP, "cloudevent")
+				}
+				s, err := detectSignature(src, test.Runtime, invoke)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s != expected {
+					t.Fatalf("Expected signature '%v', got '%v'", expected, s)
+				}
+				if _, err := os.Stat(filepath.Join(src, "..", "scaffolding", s.String())); err != nil {
+					t.Fatalf("no scaffolding for %v: %v", s, err)
+				}
+			})
+		}
+	}
+}
+
+// writeSource to the given path relative to root, creating parent directories.
+func writeSource(t *testing.T, root, path, src string) {
+	t.Helper()
+	path = filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(src), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,61 @@
+package scaffolding
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrRuntimeNotDetected is returned when the language runtime of existing
+// source code can not be determined.
+type ErrRuntimeNotDetected struct {
+	Dir string
+}
+
+func (e ErrRuntimeNotDetected) Error() string {
+	return fmt.Sprintf("unable to detect the language runtime of the source in %v.  Please specify it explicitly", e.Dir)
+}
+
+// DetectRuntime returns the language runtime of the existing source code in
+// dir based on the presence of its project manifest:
+//
+//	go.mod            go
+//	package.json      node, or typescript if there is a tsconfig.json or
+//	                  typescript is a dependency
+//	pyproject.toml    python
+//	requirements.txt  python
+//	Cargo.toml        rust
+//	pom.xml           quarkus or springboot, depending on its dependencies
+func DetectRuntime(dir string) (string, error) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+	contains := func(name, substr string) bool {
+		bb, err := os.ReadFile(filepath.Join(dir, name))
+		return err == nil && bytes.Contains(bb, []byte(substr))
+	}
+
+	switch {
+	case exists("go.mod"):
+		return "go", nil
+	case exists("package.json"):
+		if exists("tsconfig.json") || contains("package.json", `"typescript"`) {
+			return "typescript", nil
+		}
+		return "node", nil
+	case exists("pyproject.toml"), exists("requirements.txt"):
+		return "python", nil
+	case exists("Cargo.toml"):
+		return "rust", nil
+	case exists("pom.xml"):
+		if contains("pom.xml", "quarkus") {
+			return "quarkus", nil
+		}
+		if contains("pom.xml", "spring-boot") || contains("pom.xml", "spring-cloud-function") {
+			return "springboot", nil
+		}
+	}
+	return "", ErrRuntimeNotDetected{dir}
+}
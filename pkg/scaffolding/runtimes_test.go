@@ -0,0 +1,59 @@
+//go:build !integration
+// +build !integration
+
+package scaffolding
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestDetectRuntime ensures that the runtime of existing source is detected
+// from its project manifest.
+func TestDetectRuntime(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Files    map[string]string
+		Expected string
+	}{
+		{"go", map[string]string{"go.mod": "module f"}, "go"},
+		{"node", map[string]string{"package.json": `{"name":"f"}`}, "node"},
+		{"typescript dependency", map[string]string{"package.json": `{"devDependencies":{"typescript":"^5"}}`}, "typescript"},
+		{"typescript config", map[string]string{"package.json": `{}`, "tsconfig.json": `{}`}, "typescript"},
+		{"python pyproject", map[string]string{"pyproject.toml": ""}, "python"},
+		{"python requirements", map[string]string{"requirements.txt": ""}, "python"},
+		{"rust", map[string]string{"Cargo.toml": ""}, "rust"},
+		{"quarkus", map[string]string{"pom.xml": "<artifactId>quarkus-funqy-http</artifactId>"}, "quarkus"},
+		{"springboot", map[string]string{"pom.xml": "<artifactId>spring-boot-starter-parent</artifactId>"}, "springboot"},
+		{"unknown java", map[string]string{"pom.xml": "<project/>"}, ""},
+		{"empty", map[string]string{}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			root, cleanup := Mktemp(t)
+			defer cleanup()
+			for name, content := range test.Files {
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), os.ModePerm); err != nil {
+					t.Fatal(err)
+				}
+			}
+			runtime, err := DetectRuntime(root)
+			if test.Expected == "" {
+				if !errors.As(err, &ErrRuntimeNotDetected{}) {
+					t.Fatalf("expected ErrRuntimeNotDetected, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if runtime != test.Expected {
+				t.Fatalf("expected runtime %q, got %q", test.Expected, runtime)
+			}
+		})
+	}
+}
@@ -70,6 +70,27 @@ func Write(out, src, runtime, invoke string, fs filesystem.Filesystem) (err erro
 	return
 }
 
+// DetectSignature returns the Signature of the source code at src assuming
+// the given runtime and invocation hint (default "http").
+func DetectSignature(src, runtime, invoke string) (Signature, error) {
+	return detectSignature(src, runtime, invoke)
+}
+
+// DetectInvoke returns the invocation hint ("http" or "cloudevent") expected
+// by the source code at src for the given runtime.  Runtimes whose detectors
+// can not distinguish invocation return ErrDetectorNotImplemented.
+func DetectInvoke(src, runtime string) (string, error) {
+	d, err := newDetector(runtime)
+	if err != nil {
+		return "", err
+	}
+	i, ok := d.(invokeDetector)
+	if !ok {
+		return "", ErrDetectorNotImplemented{runtime}
+	}
+	return i.DetectInvoke(src)
+}
+
 // detectSignature returns the Signature of the source code at the given
 // location assuming a provided runtime and invocation hint.
 func detectSignature(src, runtime, invoke string) (s Signature, err error) {
@@ -5,7 +5,9 @@ package scaffolding
 
 import (
 	"errors"
+	"flag"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -24,11 +26,11 @@ func TestWrite_RuntimeErrors(t *testing.T) {
 	}{
 		{"go", nil},
 		{"python", nil},
-		{"rust", &ErrDetectorNotImplemented{}},
-		{"node", &ErrDetectorNotImplemented{}},
-		{"typescript", &ErrDetectorNotImplemented{}},
-		{"quarkus", &ErrDetectorNotImplemented{}},
-		{"java", &ErrDetectorNotImplemented{}},
+		{"rust", nil},
+		{"node", nil},
+		{"typescript", nil},
+		{"quarkus", nil},
+		{"java", nil},
 		{"other", &ErrRuntimeNotRecognized{}},
 	}
 	for _, test := range tests {
@@ -167,3 +169,43 @@ func TestNewScaffoldingError(t *testing.T) {
 	t.Logf("ok: %v", err)
 
 }
+
+var enableTestCargo = flag.Bool("enable-test-cargo", false, "Enable tests requiring cargo and access to crates.io.")
+
+// TestWrite_RustTemplates ensures that the scaffolding written for each of
+// the embedded rust templates, including the examples of the instanced
+// signature, builds against it.
+func TestWrite_RustTemplates(t *testing.T) {
+	// Skip this test unless explicitly enabled, as it fetches and compiles
+	// the crates of the templates.
+	if !*enableTestCargo {
+		t.Skip("Skipping test building the rust scaffolding.  Enable with --enable-test-cargo")
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewOsFilesystem(filepath.Join(cwd, "..", "..", "templates"))
+
+	for _, template := range []string{"http", "cloudevents", ".instanced-http", ".instanced-cloudevents"} {
+		t.Run(template, func(t *testing.T) {
+			src := t.TempDir()
+			if err := filesystem.CopyFromFS("rust/"+template, src, fs); err != nil {
+				t.Fatal(err)
+			}
+			invoke, err := DetectInvoke(src, "rust")
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := t.TempDir()
+			if err := Write(out, src, "rust", invoke, fs); err != nil {
+				t.Fatal(err)
+			}
+			cmd := exec.Command("cargo", "build", "--quiet")
+			cmd.Dir = out
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("the scaffolding does not build: %v\n%s", err, output)
+			}
+		})
+	}
+}
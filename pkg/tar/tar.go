@@ -12,9 +12,8 @@ import (
 	"strings"
 )
 
+// Extract the tar stream into the directory, purging its prior contents.
 func Extract(input io.Reader, destDir string) error {
-	var err error
-
 	des, err := os.ReadDir(destDir)
 	if err != nil {
 		return fmt.Errorf("cannot read dest dir: %w", err)
@@ -25,6 +24,18 @@ func Extract(input io.Reader, destDir string) error {
 			return fmt.Errorf("cannot purge dest dir: %w", err)
 		}
 	}
+	return extract(input, destDir, false)
+}
+
+// ExtractOver extracts the tar stream into the directory, retaining its prior
+// contents but for the files and links of the stream, which are replaced.
+// The stream may be empty, such as of a sync which only deletes files.
+func ExtractOver(input io.Reader, destDir string) error {
+	return extract(input, destDir, true)
+}
+
+func extract(input io.Reader, destDir string, replace bool) error {
+	var err error
 
 	r := tar.NewReader(input)
 
@@ -34,7 +45,7 @@ func Extract(input io.Reader, destDir string) error {
 		hdr, err = r.Next()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
-				if first {
+				if first && !replace {
 					// mimic tar output on empty input
 					return fmt.Errorf("does not look like a tar")
 				}
@@ -72,6 +83,13 @@ func Extract(input io.Reader, destDir string) error {
 			return fmt.Errorf("cannot ensure parent: %w", err)
 		}
 
+		if replace && hdr.Typeflag != tar.TypeDir {
+			err = os.RemoveAll(destPath)
+			if err != nil {
+				return fmt.Errorf("cannot replace entry: %w", err)
+			}
+		}
+
 		switch {
 		case hdr.Typeflag == tar.TypeReg:
 			err = writeRegularFile(destPath, os.FileMode(hdr.Mode&0777), r)
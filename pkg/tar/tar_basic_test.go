@@ -66,6 +66,30 @@ func TestExtract(t *testing.T) {
 	}
 }
 
+func TestExtractOver(t *testing.T) {
+	d := t.TempDir()
+	if err := tarutil.Extract(tarballV1(t), d); err != nil {
+		t.Fatal(err)
+	}
+	if err := tarutil.ExtractOver(tarballV2(t), d); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"dir/a.txt": aTxt2, // replaced
+		"dir/b.txt": bTxt2, // link replaced
+		"dir/data1": bTxt1, // retained
+	} {
+		bs, err := os.ReadFile(filepath.Join(d, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bs) != expected {
+			t.Errorf("unexpected data of %v: %s", name, bs)
+		}
+	}
+}
+
 func tarballV1(t *testing.T) io.Reader {
 	t.Helper()
 
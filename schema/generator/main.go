@@ -33,6 +33,13 @@ func generateFuncYamlSchema() error {
 
 	js := r.Reflect(&fn.Function{})
 
+	// Probes may also be written in shorthand as just their HTTP path.
+	if probe, ok := js.Definitions["Probe"]; ok {
+		js.Definitions["Probe"] = &jsonschema.Type{
+			OneOf: []*jsonschema.Type{{Type: "string"}, probe},
+		}
+	}
+
 	schema, err := js.MarshalJSON()
 	if err != nil {
 		return err
@@ -0,0 +1,49 @@
+const { CloudEvent } = require('cloudevents');
+
+/**
+ * An example of a function implemented as an instance which responds to
+ * CloudEvents.  The module exports a "new" factory, which is called once each
+ * time a new instance of the function service is created, and which returns
+ * an object implementing at least a "handle" method (and optionally any of
+ * the methods described in the comments below).
+ *
+ * It can be invoked with 'func invoke'
+ */
+class MyFunction {
+  /**
+   * Handle a CloudEvent using your function instance.  This example echoes
+   * the incoming event data.
+   *
+   * @param {Context} context a context object.
+   * @param {CloudEvent} event the CloudEvent
+   * See: https://github.com/knative/func/blob/main/docs/function-developers/nodejs.md#the-context-object
+   */
+  async handle(context, event) {
+    context.log.info("event", event);
+    return new CloudEvent({
+      source: 'event.handler',
+      type: 'echo',
+      data: event.data
+    });
+  }
+
+  // init is called when the function instance is started.
+  // init() {
+  //   console.log("Function started");
+  // }
+
+  // shutdown is called when the function instance is stopped.
+  // shutdown() {
+  //   console.log("Function stopped");
+  // }
+
+  // liveness and readiness report the health of the function instance.
+  // liveness() {
+  //   return "ok";
+  // }
+  // readiness() {
+  //   return "ok";
+  // }
+}
+
+module.exports = { new: () => new MyFunction() };
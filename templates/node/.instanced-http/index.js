@@ -0,0 +1,42 @@
+/**
+ * An example of a function implemented as an instance.  The module exports a
+ * "new" factory, which is called once each time a new instance of the
+ * function service is created, and which returns an object implementing at
+ * least a "handle" method (and optionally any of the methods described in the
+ * comments below).
+ *
+ * It can be invoked with 'func invoke'
+ */
+class MyFunction {
+  /**
+   * Handle an HTTP request using your function instance.
+   *
+   * @param {Context} context a context object.
+   * @param {object} body the request body if any
+   * See: https://github.com/knative/func/blob/main/docs/function-developers/nodejs.md#the-context-object
+   */
+  async handle(context, body) {
+    context.log.info("Request received");
+    return { body: "Request received\n" };
+  }
+
+  // init is called when the function instance is started.
+  // init() {
+  //   console.log("Function started");
+  // }
+
+  // shutdown is called when the function instance is stopped.
+  // shutdown() {
+  //   console.log("Function stopped");
+  // }
+
+  // liveness and readiness report the health of the function instance.
+  // liveness() {
+  //   return "ok";
+  // }
+  // readiness() {
+  //   return "ok";
+  // }
+}
+
+module.exports = { new: () => new MyFunction() };
@@ -0,0 +1,8 @@
+const { start } = require('faas-js-runtime');
+const f = require('./f');
+
+// The function's handle is invoked with each HTTP request.
+start(f.handle).catch((err) => {
+  console.error(err);
+  process.exit(1);
+});
@@ -0,0 +1,38 @@
+//! An example of a function implemented as an instance which responds to
+//! CloudEvents.
+//!
+//! The crate must export a `Function` type implementing the `Handler` trait
+//! below, and a `new` function which constructs an instance of it.  The
+//! instance is created once each time a new instance of the function service
+//! is started, and is shared by the events it handles.
+
+use actix_web::error::ErrorInternalServerError;
+use cloudevents::{Event, EventBuilder, EventBuilderV10};
+use log::info;
+use std::future::Future;
+
+/// Handler is implemented by the function to handle each CloudEvent.
+pub trait Handler {
+    fn handle(&self, event: Event) -> impl Future<Output = Result<Event, actix_web::Error>>;
+}
+
+/// Function is your function's implementation.
+pub struct Function {}
+
+/// new constructs an instance of your function.
+pub fn new() -> Function {
+    Function {}
+}
+
+impl Handler for Function {
+    // Handle an event using your function instance.  This example echoes
+    // the received event data.
+    async fn handle(&self, event: Event) -> Result<Event, actix_web::Error> {
+        info!("event: {}", event);
+        EventBuilderV10::from(event)
+            .source("func://handler")
+            .ty("func.example")
+            .build()
+            .map_err(ErrorInternalServerError)
+    }
+}
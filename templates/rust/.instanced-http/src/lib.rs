@@ -0,0 +1,31 @@
+//! An example of a function implemented as an instance.
+//!
+//! The crate must export a `Function` type implementing the `Handler` trait
+//! below, and a `new` function which constructs an instance of it.  The
+//! instance is created once each time a new instance of the function service
+//! is started, and is shared by the requests it handles.
+
+use actix_web::{web, HttpRequest, HttpResponse};
+use log::info;
+use std::future::Future;
+
+/// Handler is implemented by the function to handle each HTTP request.
+pub trait Handler {
+    fn handle(&self, req: HttpRequest, body: web::Bytes) -> impl Future<Output = HttpResponse>;
+}
+
+/// Function is your function's implementation.
+pub struct Function {}
+
+/// new constructs an instance of your function.
+pub fn new() -> Function {
+    Function {}
+}
+
+impl Handler for Function {
+    // Handle a request using your function instance.
+    async fn handle(&self, req: HttpRequest, _body: web::Bytes) -> HttpResponse {
+        info!("{:#?}", req);
+        HttpResponse::Ok().body("Request received\n")
+    }
+}
@@ -0,0 +1,6 @@
+//! The function as a library, exposing its handler and configuration such
+//! that it may also be served by the scaffolding used when building on the
+//! host.
+
+pub mod config;
+pub mod handler;
@@ -0,0 +1,25 @@
+use actix_web::{web, App, HttpResponse, HttpServer};
+use cloudevents::Event;
+use function::Handler;
+use std::env;
+
+// Each CloudEvent received is handled by the function instance.
+async fn handle(f: web::Data<function::Function>, event: Event) -> Result<Event, actix_web::Error> {
+    f.handle(event).await
+}
+
+#[actix_web::main]
+async fn main() -> std::io::Result<()> {
+    env_logger::init();
+    let port: u16 = env::var("PORT").ok().and_then(|p| p.parse().ok()).unwrap_or(8080);
+    let f = web::Data::new(function::new());
+    HttpServer::new(move || {
+        App::new()
+            .app_data(f.clone())
+            .route("/health/{_:(readiness|liveness)}", web::get().to(HttpResponse::Ok))
+            .route("/", web::post().to(handle))
+    })
+    .bind(("0.0.0.0", port))?
+    .run()
+    .await
+}
@@ -0,0 +1,24 @@
+use actix_web::{web, App, HttpRequest, HttpResponse, HttpServer};
+use function::Handler;
+use std::env;
+
+// Each HTTP request is handled by the function instance.
+async fn handle(f: web::Data<function::Function>, req: HttpRequest, body: web::Bytes) -> HttpResponse {
+    f.handle(req, body).await
+}
+
+#[actix_web::main]
+async fn main() -> std::io::Result<()> {
+    env_logger::init();
+    let port: u16 = env::var("PORT").ok().and_then(|p| p.parse().ok()).unwrap_or(8080);
+    let f = web::Data::new(function::new());
+    HttpServer::new(move || {
+        App::new()
+            .app_data(f.clone())
+            .route("/health/{_:(readiness|liveness)}", web::get().to(HttpResponse::Ok))
+            .default_service(web::to(handle))
+    })
+    .bind(("0.0.0.0", port))?
+    .run()
+    .await
+}
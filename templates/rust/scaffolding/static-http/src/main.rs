@@ -0,0 +1,19 @@
+use actix_web::{web, App, HttpResponse, HttpServer};
+use std::env;
+
+// Each HTTP request is handled by the function's handler::index, with the
+// resources registered by its config::configure.
+#[actix_web::main]
+async fn main() -> std::io::Result<()> {
+    env_logger::init();
+    let port: u16 = env::var("PORT").ok().and_then(|p| p.parse().ok()).unwrap_or(8080);
+    HttpServer::new(|| {
+        App::new()
+            .configure(function::config::configure)
+            .route("/health/{_:(readiness|liveness)}", web::get().to(HttpResponse::Ok))
+            .default_service(web::to(function::handler::index))
+    })
+    .bind(("0.0.0.0", port))?
+    .run()
+    .await
+}
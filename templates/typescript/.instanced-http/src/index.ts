@@ -0,0 +1,46 @@
+import { Context, StructuredReturn } from 'faas-js-runtime';
+
+/**
+ * MyFunction is an example of a function implemented as an instance.  An
+ * instance is created by the exported "new" factory each time a new instance
+ * of the function service is started, and must implement at least a "handle"
+ * method (and optionally any of the methods described in the comments below).
+ *
+ * It can be invoked with `func invoke`
+ */
+class MyFunction {
+  /**
+   * Handle an HTTP request using your function instance.
+   *
+   * @param {Context} context a context object.
+   * @param {string} body the request body if any
+   * See: https://github.com/knative/func/blob/main/docs/guides/nodejs.md#the-context-object
+   */
+  async handle(context: Context, body?: string): Promise<StructuredReturn> {
+    context.log.info(`Request received: ${body ?? ''}`);
+    return { body: 'Request received\n' };
+  }
+
+  // init is called when the function instance is started.
+  // init(): void {
+  //   console.log('Function started');
+  // }
+
+  // shutdown is called when the function instance is stopped.
+  // shutdown(): void {
+  //   console.log('Function stopped');
+  // }
+
+  // liveness and readiness report the health of the function instance.
+  // liveness(): string {
+  //   return 'ok';
+  // }
+  // readiness(): string {
+  //   return 'ok';
+  // }
+}
+
+// create a new instance of the function.  It is exported as "new".
+const create = (): MyFunction => new MyFunction();
+
+export { create as new };
@@ -0,0 +1,9 @@
+const { start } = require('faas-js-runtime');
+const f = require('./f/build');
+
+// The function instance's handle is invoked with each CloudEvent received.
+// Its optional init, shutdown, liveness and readiness methods are also used.
+start(f.new()).catch((err) => {
+  console.error(err);
+  process.exit(1);
+});
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"os"
 	"strings"
 
@@ -18,8 +19,8 @@ func init() {
 	// Setup test Registry.
 	testRegistry = os.Getenv("E2E_REGISTRY_URL")
 	if testRegistry == "" || testRegistry == "default" {
-		if k8s.IsOpenShift() {
-			testRegistry = k8s.GetDefaultOpenShiftRegistry()
+		if k8s.IsOpenShift(context.Background()) {
+			testRegistry = k8s.GetDefaultOpenShiftRegistry(context.Background())
 		} else {
 			testRegistry = DefaultRegistry
 		}
@@ -49,7 +49,7 @@ func (g *GitTestServerKnativeProvider) Init(T *testing.T) {
 
 	g.t = T
 	if g.PodName == "" {
-		config, err := k8s.GetClientConfig().ClientConfig()
+		config, err := k8s.GetClientConfig(context.Background()).ClientConfig()
 		if err != nil {
 			T.Fatal(err.Error())
 		}
@@ -59,7 +59,7 @@ func (g *GitTestServerKnativeProvider) Init(T *testing.T) {
 		}
 		ctx := context.Background()
 
-		namespace, _, _ := k8s.GetClientConfig().Namespace()
+		namespace, _, _ := k8s.GetClientConfig(context.Background()).Namespace()
 		podList, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 			LabelSelector: "serving.knative.dev/service=func-git",
 		})
@@ -93,7 +93,7 @@ func (g *GitTestServerKnativeProvider) CreateRepository(repoName string) *GitRem
 	if !strings.Contains(cmdResult.Out, "created") {
 		g.t.Fatal("unable to create git bare repository " + repoName)
 	}
-	namespace, _, _ := k8s.GetClientConfig().Namespace()
+	namespace, _, _ := k8s.GetClientConfig(context.Background()).Namespace()
 	gitRepo := &GitRemoteRepo{
 		RepoName:         repoName,
 		ExternalCloneURL: g.ServiceUrl + "/" + repoName + ".git",
@@ -15,7 +15,7 @@ import (
 // RetrieveKnativeServiceResource wraps the logic to query knative serving resources in current namespace
 func RetrieveKnativeServiceResource(t *testing.T, serviceName string) *unstructured.Unstructured {
 	// create k8s dynamic client
-	config, err := k8s.GetClientConfig().ClientConfig()
+	config, err := k8s.GetClientConfig(context.Background()).ClientConfig()
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -29,7 +29,7 @@ func RetrieveKnativeServiceResource(t *testing.T, serviceName string) *unstructu
 		Version:  "v1",
 		Resource: "services",
 	}
-	namespace, _, _ := k8s.GetClientConfig().Namespace()
+	namespace, _, _ := k8s.GetClientConfig(context.Background()).Namespace()
 	resource, err := dynClient.Resource(knativeServiceResource).Namespace(namespace).Get(context.Background(), serviceName, metav1.GetOptions{})
 	if err != nil {
 		t.Fatal(err)
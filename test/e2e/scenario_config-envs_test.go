@@ -24,7 +24,7 @@ import (
 // setupConfigEnvsTest add to cluster config maps and secrets used by the test
 func setupConfigEnvsTest(t *testing.T) {
 
-	config, err := k8s.GetClientConfig().ClientConfig()
+	config, err := k8s.GetClientConfig(context.Background()).ClientConfig()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -32,7 +32,7 @@ func setupConfigEnvsTest(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	namespace, _, _ := k8s.GetClientConfig().Namespace()
+	namespace, _, _ := k8s.GetClientConfig(context.Background()).Namespace()
 
 	// Add Config Map
 	configMap := corev1.ConfigMap{
@@ -65,9 +65,9 @@ func setupConfigEnvsTest(t *testing.T) {
 // tearDownConfigEnvsTest removes cluster config maps and secrets used by the test
 func tearDownConfigEnvsTest() {
 
-	config, _ := k8s.GetClientConfig().ClientConfig()
+	config, _ := k8s.GetClientConfig(context.Background()).ClientConfig()
 	clientset, _ := kubernetes.NewForConfig(config)
-	namespace, _, _ := k8s.GetClientConfig().Namespace()
+	namespace, _, _ := k8s.GetClientConfig(context.Background()).Namespace()
 
 	_ = clientset.CoreV1().ConfigMaps(namespace).Delete(context.Background(), "test-cm", metav1.DeleteOptions{})
 	_ = clientset.CoreV1().Secrets(namespace).Delete(context.Background(), "test-secret", metav1.DeleteOptions{})
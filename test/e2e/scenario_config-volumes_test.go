@@ -30,7 +30,7 @@ import (
 // during tests
 func setupConfigVolumesTest(t *testing.T) {
 
-	config, err := k8s.GetClientConfig().ClientConfig()
+	config, err := k8s.GetClientConfig(context.Background()).ClientConfig()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -38,7 +38,7 @@ func setupConfigVolumesTest(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	namespace, _, _ := k8s.GetClientConfig().Namespace()
+	namespace, _, _ := k8s.GetClientConfig(context.Background()).Namespace()
 
 	// Add Config Map
 	configMap := corev1.ConfigMap{
@@ -71,9 +71,9 @@ func setupConfigVolumesTest(t *testing.T) {
 // tearDownConfigVolumesTest removes cluster config maps and secrets used by the test
 func tearDownConfigVolumesTest() {
 
-	config, _ := k8s.GetClientConfig().ClientConfig()
+	config, _ := k8s.GetClientConfig(context.Background()).ClientConfig()
 	clientset, _ := kubernetes.NewForConfig(config)
-	namespace, _, _ := k8s.GetClientConfig().Namespace()
+	namespace, _, _ := k8s.GetClientConfig(context.Background()).Namespace()
 
 	_ = clientset.CoreV1().ConfigMaps(namespace).Delete(context.Background(), "test-cm-volume", metav1.DeleteOptions{})
 	_ = clientset.CoreV1().Secrets(namespace).Delete(context.Background(), "test-secret-volume", metav1.DeleteOptions{})
@@ -180,7 +180,7 @@ func TestConfigVolumes(t *testing.T) {
 // https://knative.dev/docs/serving/configuration/feature-flags/#kubernetes-emptydir-volume
 // https://knative.dev/docs/serving/configuration/feature-flags/#kubernetes-persistentvolumeclaim-pvc
 func enableKnativeVolumeExtension(t *testing.T) {
-	config, _ := k8s.GetClientConfig().ClientConfig()
+	config, _ := k8s.GetClientConfig(context.Background()).ClientConfig()
 	client, _ := kubernetes.NewForConfig(config)
 	namespace := "knative-serving"
 
@@ -208,9 +208,9 @@ func enableKnativeVolumeExtension(t *testing.T) {
 
 // setupTestPvc adds a test Persistent Volume Claim used by PVC test
 func setupTestPvc(t *testing.T, pvcName string) {
-	config, _ := k8s.GetClientConfig().ClientConfig()
+	config, _ := k8s.GetClientConfig(context.Background()).ClientConfig()
 	client, _ := kubernetes.NewForConfig(config)
-	namespace, _, _ := k8s.GetClientConfig().Namespace()
+	namespace, _, _ := k8s.GetClientConfig(context.Background()).Namespace()
 
 	// Add Testing PVC
 	pvc := &corev1.PersistentVolumeClaim{